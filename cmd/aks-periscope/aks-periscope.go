@@ -2,9 +2,16 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"os"
 	"runtime"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,7 +20,10 @@ import (
 	"github.com/Azure/aks-periscope/pkg/exporter"
 	"github.com/Azure/aks-periscope/pkg/interfaces"
 	"github.com/Azure/aks-periscope/pkg/utils"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 	restclient "k8s.io/client-go/rest"
+	metrics "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
 func main() {
@@ -27,6 +37,10 @@ func main() {
 		log.Fatalf("failed to get file paths: %v", err)
 	}
 
+	// Overrides COLLECTOR_LIST for every run, e.g. to run selected collectors while troubleshooting.
+	collectorList := flag.String("collector-list", "", "space-separated collector names and special values to use instead of COLLECTOR_LIST")
+	flag.Parse()
+
 	fileSystem := utils.NewFileSystem()
 
 	// Create a watcher for the Run ID file that checks its content every 10 seconds
@@ -43,7 +57,7 @@ func main() {
 		for {
 			runId := <-runIdChan
 			log.Printf("Starting Periscope run %s", runId)
-			err := run(osIdentifier, knownFilePaths, fileSystem)
+			err := run(osIdentifier, knownFilePaths, fileSystem, *collectorList)
 			if err != nil {
 				errChan <- err
 			}
@@ -59,18 +73,90 @@ func main() {
 	log.Fatalf("Error running Periscope: %v", err)
 }
 
-func run(osIdentifier utils.OSIdentifier, knownFilePaths *utils.KnownFilePaths, fileSystem interfaces.FileSystemAccessor) error {
-	runtimeInfo, err := utils.GetRuntimeInfo(fileSystem, knownFilePaths)
+func run(osIdentifier utils.OSIdentifier, knownFilePaths *utils.KnownFilePaths, fileSystem interfaces.FileSystemAccessor, collectorListOverride string) error {
+	config, err := restclient.InClusterConfig()
+	if err != nil {
+		return fmt.Errorf("cannot load kubeconfig: %w", err)
+	}
+
+	// The overrides are read before the API rate limit is configured, since it may be one of them.
+	overridesClientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("cannot create clientset: %w", err)
+	}
+
+	// Values of the overrides ConfigMap take precedence over the mounted config, and the -collector-list flag over both.
+	overrides, err := utils.GetConfigOverrides(fileSystem, knownFilePaths, overridesClientset)
+	if err != nil {
+		return fmt.Errorf("cannot read %s: %w", utils.ConfigOverridesName, err)
+	}
+
+	runtimeInfo, err := utils.GetRuntimeInfo(overrides.GetFileSystem(fileSystem, knownFilePaths), knownFilePaths)
 	if err != nil {
 		log.Fatalf("Failed to get runtime information: %v", err)
 	}
+	overrides.Apply(runtimeInfo)
 
-	config, err := restclient.InClusterConfig()
+	if len(strings.TrimSpace(collectorListOverride)) > 0 {
+		runtimeInfo.CollectorList = strings.Fields(collectorListOverride)
+		runtimeInfo.DisabledCollectors = nil
+	}
+
+	// Nothing is collected on other nodes, so node-scoped data comes only from the target node, and cluster-scoped
+	// data is collected once.
+	if !runtimeInfo.IsTargetNode() {
+		log.Printf("Skipping collection: %s is %s, not this node (%s)", utils.TargetNodeKey, runtimeInfo.TargetNode, runtimeInfo.HostNodeName)
+		return nil
+	}
+
+	// All API clients, including those created by collectors from this config, share one rate limiter.
+	utils.ApplyAPIRateLimit(config, runtimeInfo)
+
+	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		return fmt.Errorf("cannot load kubeconfig: %w", err)
+		return fmt.Errorf("cannot create clientset: %w", err)
 	}
 
-	exp := exporter.NewAzureBlobExporter(runtimeInfo, knownFilePaths, runtimeInfo.RunId)
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("cannot create dynamic client: %w", err)
+	}
+
+	metricsClient, err := metrics.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("cannot create metrics client: %w", err)
+	}
+
+	// Names are anonymized in everything exported, including the node name in the paths of exported files. Collectors
+	// still use the real node name.
+	var anonymizer *utils.Anonymizer
+	exportRuntimeInfo := runtimeInfo
+	if runtimeInfo.AnonymizeNames {
+		anonymizer, err = createAnonymizer(runtimeInfo, clientset)
+		if err != nil {
+			return fmt.Errorf("cannot create anonymizer: %w", err)
+		}
+		anonymized := *runtimeInfo
+		anonymized.HostNodeName = anonymizer.Anonymize(runtimeInfo.HostNodeName)
+		exportRuntimeInfo = &anonymized
+	}
+
+	// The manifest records what was uploaded, so it wraps the target exporters directly, inside any archiving.
+	manifestExporter := exporter.NewManifestExporter(createExporter(exportRuntimeInfo, knownFilePaths), exportRuntimeInfo)
+	var exp interfaces.Exporter = manifestExporter
+	if len(runtimeInfo.ExportEncryptionKeyFile) > 0 {
+		// Encryption is outside the manifest, so that it records the checksums of the encrypted files as uploaded.
+		encryptingExporter := exporter.NewEncryptingExporter(exp, runtimeInfo.ExportEncryptionKeyFile)
+		if err := encryptingExporter.ExportWrappedKey(); err != nil {
+			return fmt.Errorf("cannot export encryption key: %w", err)
+		}
+		exp = encryptingExporter
+	}
+	var archiveExporter *exporter.ArchiveExporter
+	if runtimeInfo.ExportArchive {
+		archiveExporter = exporter.NewArchiveExporter(exp, exporter.GetArchiveFormat(osIdentifier))
+		exp = archiveExporter
+	}
 
 	// Copies self-signed cert information to container if application is running on Azure Stack Cloud.
 	// We need the cert in order to communicate with the storage account.
@@ -83,53 +169,382 @@ func run(osIdentifier utils.OSIdentifier, knownFilePaths *utils.KnownFilePaths,
 	dnsCollector := collector.NewDNSCollector(osIdentifier, knownFilePaths, fileSystem)
 	kubeletCmdCollector := collector.NewKubeletCmdCollector(osIdentifier, runtimeInfo)
 	networkOutboundCollector := collector.NewNetworkOutboundCollector()
-	collectors := []interfaces.Collector{
-		dnsCollector,
-		kubeletCmdCollector,
-		networkOutboundCollector,
-		collector.NewHelmCollector(config, runtimeInfo),
-		collector.NewIPTablesCollector(osIdentifier, runtimeInfo),
-		collector.NewKubeObjectsCollector(config, runtimeInfo),
-		collector.NewNodeLogsCollector(runtimeInfo, fileSystem),
-		collector.NewOsmCollector(config, runtimeInfo),
-		collector.NewPDBCollector(config, runtimeInfo),
-		collector.NewPodsContainerLogsCollector(config, runtimeInfo),
-		collector.NewSmiCollector(config, runtimeInfo),
-		collector.NewSystemLogsCollector(osIdentifier, runtimeInfo),
-		collector.NewSystemPerfCollector(config, runtimeInfo),
-		collector.NewWindowsLogsCollector(osIdentifier, runtimeInfo, knownFilePaths, fileSystem, 10*time.Second, 20*time.Minute),
+	registry := collector.NewRegistry()
+	registry.Register("dns", func() interfaces.Collector {
+		return dnsCollector
+	})
+	registry.Register("kubeletcmd", func() interfaces.Collector {
+		return kubeletCmdCollector
+	})
+	registry.Register("networkoutbound", func() interfaces.Collector {
+		return networkOutboundCollector
+	})
+	registry.Register("admissionrejections", func() interfaces.Collector {
+		return collector.NewAdmissionRejectionCollector(clientset, runtimeInfo)
+	})
+	registry.Register("antiaffinityviolations", func() interfaces.Collector {
+		return collector.NewAntiAffinityViolationCollector(clientset, runtimeInfo)
+	})
+	registry.Register("autoscaler", func() interfaces.Collector {
+		return collector.NewAutoscalerCollector(clientset, dynamicClient, runtimeInfo)
+	})
+	registry.Register("azurecni", func() interfaces.Collector {
+		return collector.NewAzureCNICollector(knownFilePaths, fileSystem)
+	})
+	registry.Register("certificates", func() interfaces.Collector {
+		return collector.NewCertificateCollector(osIdentifier, knownFilePaths, fileSystem, clientset, utils.FetchServingCertificates)
+	})
+	registry.Register("cgroup", func() interfaces.Collector {
+		return collector.NewCgroupCollector(osIdentifier, knownFilePaths, fileSystem)
+	})
+	registry.Register("cilium", func() interfaces.Collector {
+		return collector.NewCiliumCollector(osIdentifier, clientset, utils.NewPodCommandRunner(config, clientset), runtimeInfo)
+	})
+	registry.Register("clockskew", func() interfaces.Collector {
+		return collector.NewClockSkewCollector(osIdentifier, utils.NewAPIServerClockReader(config), utils.NewNTPClockReader(runtimeInfo.ClockSkewNTPServer), utils.RunCommandOnHost, runtimeInfo)
+	})
+	registry.Register("cloudprovider", func() interfaces.Collector {
+		return collector.NewCloudProviderCollector(clientset, runtimeInfo)
+	})
+	registry.Register("containerdlogs", func() interfaces.Collector {
+		return collector.NewContainerdLogsCollector(osIdentifier, utils.RunCommandOnHost, runtimeInfo)
+	})
+	registry.Register("coredns", func() interfaces.Collector {
+		return collector.NewCoreDNSCollector(clientset, utils.NewPodMetricsScraper(clientset), runtimeInfo)
+	})
+	registry.Register("crashloop", func() interfaces.Collector {
+		return collector.NewCrashLoopCollector(clientset, runtimeInfo)
+	})
+	registry.Register("crosszonetraffic", func() interfaces.Collector {
+		return collector.NewCrossZoneTrafficCollector(clientset, runtimeInfo)
+	})
+	registry.Register("daemonsetcoverage", func() interfaces.Collector {
+		return collector.NewDaemonSetCoverageCollector(clientset, runtimeInfo)
+	})
+	registry.Register("deprecation", func() interfaces.Collector {
+		return collector.NewDeprecationCollector(config, runtimeInfo)
+	})
+	registry.Register("dmesg", func() interfaces.Collector {
+		return collector.NewDmesgCollector(osIdentifier, knownFilePaths, fileSystem, utils.RunCommandOnHost, runtimeInfo)
+	})
+	registry.Register("dnsfailuresummary", func() interfaces.Collector {
+		return collector.NewDNSFailureSummaryCollector(osIdentifier, collector.NewCoreDNSQueryLogSource(clientset))
+	})
+	registry.Register("ephemeralstorage", func() interfaces.Collector {
+		return collector.NewEphemeralStorageCollector(osIdentifier, knownFilePaths, fileSystem, clientset, runtimeInfo)
+	})
+	registry.Register("etcdlatency", func() interfaces.Collector {
+		return collector.NewEtcdLatencyCollector(utils.NewAPIServerMetricsScraper(clientset))
+	})
+	registry.Register("eventtimeline", func() interfaces.Collector {
+		return collector.NewEventTimelineCollector(clientset, runtimeInfo)
+	})
+	registry.Register("fdusage", func() interfaces.Collector {
+		return collector.NewFDUsageCollector(osIdentifier, knownFilePaths, fileSystem, clientset, runtimeInfo)
+	})
+	registry.Register("gitops", func() interfaces.Collector {
+		return collector.NewGitOpsCollector(dynamicClient, runtimeInfo)
+	})
+	registry.Register("gpu", func() interfaces.Collector {
+		return collector.NewGPUCollector(osIdentifier, knownFilePaths, fileSystem, utils.RunCommandOnHost, clientset, runtimeInfo)
+	})
+	registry.Register("helm", func() interfaces.Collector {
+		return collector.NewHelmCollector(config, runtimeInfo)
+	})
+	registry.Register("helmreleases", func() interfaces.Collector {
+		return collector.NewHelmReleaseCollector(clientset, runtimeInfo)
+	})
+	registry.Register("hugepages", func() interfaces.Collector {
+		return collector.NewHugePagesCollector(osIdentifier, knownFilePaths, fileSystem, clientset, runtimeInfo)
+	})
+	registry.Register("imds", func() interfaces.Collector {
+		return collector.NewIMDSCollector(runtimeInfo, utils.IMDSEndpoint, utils.NewIMDSClient(5*time.Second))
+	})
+	registry.Register("iptables", func() interfaces.Collector {
+		return collector.NewIPTablesCollector(osIdentifier, runtimeInfo)
+	})
+	registry.Register("imagepull", func() interfaces.Collector {
+		return collector.NewImagePullCollector(osIdentifier, clientset, utils.RunCommandOnHost, runtimeInfo)
+	})
+	registry.Register("initcontainers", func() interfaces.Collector {
+		return collector.NewInitContainerCollector(clientset, runtimeInfo)
+	})
+	registry.Register("kubeobjects", func() interfaces.Collector {
+		return collector.NewKubeObjectsCollector(config, runtimeInfo)
+	})
+	registry.Register("kubeletlimits", func() interfaces.Collector {
+		return collector.NewKubeletLimitsCollector(osIdentifier, knownFilePaths, fileSystem, utils.RunCommandOnHost)
+	})
+	registry.Register("mountfailures", func() interfaces.Collector {
+		return collector.NewMountFailureCollector(clientset, runtimeInfo)
+	})
+	registry.Register("mountfreshness", func() interfaces.Collector {
+		return collector.NewMountFreshnessCollector(osIdentifier, knownFilePaths, fileSystem, clientset, runtimeInfo)
+	})
+	registry.Register("mtu", func() interfaces.Collector {
+		return collector.NewMTUCollector(osIdentifier, utils.RunCommandOnHost, runtimeInfo)
+	})
+	registry.Register("networkinterface", func() interfaces.Collector {
+		return collector.NewNetworkInterfaceCollector(osIdentifier, utils.RunCommandOnHost)
+	})
+	registry.Register("networkpolicy", func() interfaces.Collector {
+		return collector.NewNetworkPolicyCollector(osIdentifier, clientset, dynamicClient, utils.RunCommandOnHost, runtimeInfo)
+	})
+	registry.Register("nodeconditions", func() interfaces.Collector {
+		return collector.NewNodeConditionsCollector(clientset, runtimeInfo)
+	})
+	registry.Register("nodeconnectivity", func() interfaces.Collector {
+		return collector.NewNodeConnectivityCollector(clientset, utils.ProbeTCPConnection, runtimeInfo)
+	})
+	registry.Register("nodelocaldns", func() interfaces.Collector {
+		return collector.NewNodeLocalDNSCollector(osIdentifier, clientset, utils.RunCommandOnHost, utils.NewPodMetricsScraper(clientset), runtimeInfo)
+	})
+	registry.Register("nodelogs", func() interfaces.Collector {
+		return collector.NewNodeLogsCollector(runtimeInfo, knownFilePaths, fileSystem, utils.StreamCommandOnHost)
+	})
+	registry.Register("osm", func() interfaces.Collector {
+		return collector.NewOsmCollector(config, runtimeInfo)
+	})
+	registry.Register("poddisruptionbudget", func() interfaces.Collector {
+		return collector.NewPDBCollector(clientset, runtimeInfo)
+	})
+	registry.Register("poddnsconfig", func() interfaces.Collector {
+		return collector.NewPodDNSConfigCollector(osIdentifier, knownFilePaths, fileSystem, clientset, runtimeInfo)
+	})
+	registry.Register("podhealth", func() interfaces.Collector {
+		return collector.NewPodHealthCollector(clientset, runtimeInfo)
+	})
+	registry.Register("podscontainerlogs", func() interfaces.Collector {
+		return collector.NewPodsContainerLogsCollector(clientset, runtimeInfo)
+	})
+	registry.Register("podstartup", func() interfaces.Collector {
+		return collector.NewPodStartupCollector(clientset, runtimeInfo)
+	})
+	registry.Register("pvcprovisioning", func() interfaces.Collector {
+		return collector.NewPVCProvisioningCollector(clientset, runtimeInfo)
+	})
+	registry.Register("qos", func() interfaces.Collector {
+		return collector.NewQoSCollector(clientset, metricsClient, runtimeInfo)
+	})
+	registry.Register("qosdistribution", func() interfaces.Collector {
+		return collector.NewQoSDistributionCollector(clientset, runtimeInfo)
+	})
+	registry.Register("rbacsecurity", func() interfaces.Collector {
+		return collector.NewRBACSecurityCollector(clientset, runtimeInfo)
+	})
+	registry.Register("routevalidation", func() interfaces.Collector {
+		return collector.NewRouteValidationCollector(osIdentifier, config.Host, utils.RunCommandOnHost)
+	})
+	registry.Register("scheduledevents", func() interfaces.Collector {
+		return collector.NewScheduledEventsCollector(runtimeInfo, utils.IMDSEndpoint, utils.NewIMDSClient(5*time.Second))
+	})
+	registry.Register("securityprofiles", func() interfaces.Collector {
+		return collector.NewSecurityProfileCollector(osIdentifier, knownFilePaths, fileSystem, clientset, runtimeInfo)
+	})
+	registry.Register("selfdiagnostics", func() interfaces.Collector {
+		return collector.NewSelfDiagnosticsCollector(runtimeInfo)
+	})
+	registry.Register("serviceendpoints", func() interfaces.Collector {
+		return collector.NewServiceEndpointCollector(clientset, runtimeInfo)
+	})
+	registry.Register("smi", func() interfaces.Collector {
+		return collector.NewSmiCollector(config, runtimeInfo)
+	})
+	registry.Register("socketstats", func() interfaces.Collector {
+		return collector.NewSocketStatsCollector(osIdentifier, clientset, utils.RunCommandOnHost, runtimeInfo)
+	})
+	registry.Register("storagestate", func() interfaces.Collector {
+		return collector.NewStorageStateCollector(clientset, runtimeInfo)
+	})
+	registry.Register("sysctl", func() interfaces.Collector {
+		return collector.NewSysctlCollector(osIdentifier, utils.RunCommandOnHost, runtimeInfo)
+	})
+	registry.Register("systemcomponentlogs", func() interfaces.Collector {
+		return collector.NewSystemComponentLogsCollector(clientset, runtimeInfo)
+	})
+	registry.Register("systemlogs", func() interfaces.Collector {
+		return collector.NewSystemLogsCollector(osIdentifier, runtimeInfo)
+	})
+	registry.Register("systemd", func() interfaces.Collector {
+		return collector.NewSystemdCollector(osIdentifier, knownFilePaths, fileSystem, utils.RunCommandOnHost, runtimeInfo)
+	})
+	registry.Register("systemperf", func() interfaces.Collector {
+		return collector.NewSystemPerfCollector(metricsClient, runtimeInfo)
+	})
+	registry.Register("versionskew", func() interfaces.Collector {
+		return collector.NewVersionSkewCollector(clientset, runtimeInfo)
+	})
+	registry.Register("volumeoperations", func() interfaces.Collector {
+		return collector.NewVolumeOperationCollector(clientset, runtimeInfo)
+	})
+	registry.Register("webhooks", func() interfaces.Collector {
+		return collector.NewWebhookCollector(clientset, dynamicClient, runtimeInfo, utils.ProbeTLSEndpoint)
+	})
+	registry.Register("windowshns", func() interfaces.Collector {
+		return collector.NewWindowsHNSCollector(osIdentifier, runtimeInfo, knownFilePaths, fileSystem, 10*time.Second, 20*time.Minute)
+	})
+
+	registry.Register("windowslogs", func() interfaces.Collector {
+		return collector.NewWindowsLogsCollector(osIdentifier, runtimeInfo, knownFilePaths, fileSystem, 10*time.Second, 20*time.Minute)
+	})
+	registry.Register("workloadinventory", func() interfaces.Collector {
+		return collector.NewWorkloadInventoryCollector(clientset, runtimeInfo)
+	})
+
+	// A typo in COLLECTOR_LIST would otherwise silently exclude a collector, or the behavior it enables.
+	if err := registry.ValidateCollectorList(runtimeInfo.CollectorList); err != nil {
+		return err
+	}
+
+	for _, name := range runtimeInfo.DisabledCollectors {
+		if !utils.Contains(registry.Names(), name) {
+			return fmt.Errorf("invalid %s annotation for collector '%s': expected a collector name: %s", utils.ConfigOverridesName, name, strings.Join(registry.Names(), ", "))
+		}
 	}
 
+	selectedCollectors := []string{}
+	for _, name := range registry.Selected(runtimeInfo.CollectorList) {
+		if !utils.Contains(runtimeInfo.DisabledCollectors, name) {
+			selectedCollectors = append(selectedCollectors, name)
+		}
+	}
+	collectors, err := registry.Create(selectedCollectors)
+	if err != nil {
+		return fmt.Errorf("cannot create collectors: %w", err)
+	}
+
+	// Stagger the start of collection across nodes, so that the API server isn't hit by every node at once.
+	if jitter := utils.GetStartJitter(runtimeInfo.CollectorStartJitter); jitter > 0 {
+		log.Printf("Delaying collection by %s", jitter.Round(time.Millisecond))
+		time.Sleep(jitter)
+	}
+
+	// Excluded keys are dropped before anything else reads the data, so they are never exported.
+	excluder := utils.NewKeyExcluder(runtimeInfo.ExcludeKeys)
+
+	// Secrets are redacted before any size limit is applied, so that a truncated secret can't escape redaction.
+	var redactor *utils.Redactor
+	if runtimeInfo.RedactSecrets {
+		redactor = utils.NewRedactor(runtimeInfo.RedactPatterns)
+	}
+
+	// Streamed data is exported as it is produced, so it can only be streamed if it doesn't need to be read as a
+	// whole, by redaction, anonymization or an archive of each collector's output.
+	streamExporter, canStream := exp.(interfaces.StreamExporter)
+	canStream = canStream && redactor == nil && anonymizer == nil && !runtimeInfo.HTTPExportArchive
+
 	collectorGrp := new(sync.WaitGroup)
 
+	// Limits the number of collectors running at once, if configured.
+	var semaphore chan struct{}
+	if runtimeInfo.CollectorConcurrency > 0 {
+		semaphore = make(chan struct{}, runtimeInfo.CollectorConcurrency)
+	}
+
+	statusRecorder := exporter.NewCollectorStatusRecorder()
+	for _, name := range registry.Names() {
+		if utils.Contains(runtimeInfo.DisabledCollectors, name) {
+			statusRecorder.RecordSkipped(name, fmt.Errorf("disabled by annotation of %s configmap", utils.ConfigOverridesName))
+		} else if !utils.Contains(selectedCollectors, name) {
+			statusRecorder.RecordSkipped(name, errors.New("not included because other collectors are named in COLLECTOR_LIST variable"))
+		}
+	}
+
 	dataProducers := []interfaces.DataProducer{}
+	streamedProducers := []interfaces.DataProducer{}
+	completedCollectors := []interfaces.Collector{}
+	dataProducersLock := new(sync.Mutex)
+	expectedProducers := []string{}
+
+	// Collectors start together, but those with dependencies wait for them to complete.
+	dependencyTracker := collector.NewDependencyTracker(selectedCollectors)
+
+	// Periodically logs the collectors which are still running, so that a long run isn't mistaken for a hung one.
+	progress := utils.NewCollectorProgress()
+	heartbeatDone := make(chan struct{})
+	go progress.LogHeartbeats(runtimeInfo.CollectorHeartbeat, heartbeatDone)
+
 	for _, c := range collectors {
 		if err := c.CheckSupported(); err != nil {
 			// Log the reason why this collector is not supported, and skip to the next
 			log.Printf("Skipping unsupported collector %s: %v", c.GetName(), err)
+			statusRecorder.RecordSkipped(c.GetName(), err)
+			dependencyTracker.Complete(c.GetName(), nil)
 			continue
 		}
 
-		dataProducers = append(dataProducers, c)
+		statusRecorder.RecordSupported(c.GetName())
+		expectedProducers = append(expectedProducers, c.GetName())
 		collectorGrp.Add(1)
 		go func(c interfaces.Collector) {
 			defer collectorGrp.Done()
 
+			// Releases dependents if the collector doesn't complete successfully.
+			defer dependencyTracker.Complete(c.GetName(), nil)
+
+			// Dependencies are awaited before taking a slot, so that waiting collectors can't hold every slot.
+			dependencyTracker.SetDependencyData(c, registry.Dependencies(c.GetName()))
+
+			if semaphore != nil {
+				semaphore <- struct{}{}
+				defer func() { <-semaphore }()
+			}
+
+			collect := c.Collect
+			if streamingCollector, ok := c.(interfaces.StreamingCollector); ok && canStream {
+				streamed := utils.NewStreamedDataProducer(c.GetName())
+				dataProducersLock.Lock()
+				streamedProducers = append(streamedProducers, streamed)
+				dataProducersLock.Unlock()
+
+				collect = func() error {
+					return streamingCollector.CollectStreams(func(key string, reader io.Reader) error {
+						if excluder.IsExcluded(c.GetName(), key) {
+							return nil
+						}
+
+						log.Printf("Collector: %s, export stream %s", c.GetName(), key)
+						reader = streamed.CountReader(key, utils.NewSizeLimitedReader(reader, runtimeInfo.CollectorMaxBytes))
+						return streamExporter.ExportStream(key, reader)
+					})
+				}
+			}
+
 			log.Printf("Collector: %s, collect data", c.GetName())
-			err := c.Collect()
+			progress.Start(c.GetName())
+			err := collectWithTimeout(collect, runtimeInfo.CollectorTimeout)
+			progress.Complete(c.GetName())
+			if errors.Is(err, errCollectorTimeout) {
+				// The collector may still be writing its data, so it's not safe to include it.
+				log.Printf("Collector: %s, collect data timed out after %s", c.GetName(), runtimeInfo.CollectorTimeout)
+				statusRecorder.RecordCollected(c.GetName(), fmt.Errorf("%w after %s", err, runtimeInfo.CollectorTimeout))
+				return
+			}
+
+			statusRecorder.RecordCollected(c.GetName(), err)
+
+			producer := utils.NewSizeLimitedDataProducer(utils.NewAnonymizingDataProducer(utils.NewRedactingDataProducer(utils.NewExcludingDataProducer(c, excluder), redactor), anonymizer), runtimeInfo.CollectorMaxBytes)
+			dataProducersLock.Lock()
+			dataProducers = append(dataProducers, producer)
+			completedCollectors = append(completedCollectors, c)
+			dataProducersLock.Unlock()
+
 			if err != nil {
 				log.Printf("Collector: %s, collect data failed: %v", c.GetName(), err)
 				return
 			}
 
+			dependencyTracker.Complete(c.GetName(), c)
+
 			log.Printf("Collector: %s, export data", c.GetName())
-			if err = exp.Export(c); err != nil {
+			if err = exp.Export(producer); err != nil {
 				log.Printf("Collector: %s, export data failed: %v", c.GetName(), err)
 			}
 		}(c)
 	}
 
 	collectorGrp.Wait()
+	close(heartbeatDone)
 
 	diagnosers := []interfaces.Diagnoser{
 		diagnoser.NewNetworkConfigDiagnoser(runtimeInfo, dnsCollector, kubeletCmdCollector),
@@ -139,35 +554,177 @@ func run(osIdentifier utils.OSIdentifier, knownFilePaths *utils.KnownFilePaths,
 	diagnoserGrp := new(sync.WaitGroup)
 
 	for _, d := range diagnosers {
-		dataProducers = append(dataProducers, d)
+		expectedProducers = append(expectedProducers, d.GetName())
+		producer := utils.NewAnonymizingDataProducer(utils.NewRedactingDataProducer(utils.NewExcludingDataProducer(d, excluder), redactor), anonymizer)
+		dataProducers = append(dataProducers, producer)
 		diagnoserGrp.Add(1)
-		go func(d interfaces.Diagnoser) {
+		go func(d interfaces.Diagnoser, producer interfaces.DataProducer) {
 			defer diagnoserGrp.Done()
 
 			log.Printf("Diagnoser: %s, diagnose data", d.GetName())
 			err := d.Diagnose()
+			statusRecorder.RecordCollected(d.GetName(), err)
 			if err != nil {
 				log.Printf("Diagnoser: %s, diagnose data failed: %v", d.GetName(), err)
 				return
 			}
 
 			log.Printf("Diagnoser: %s, export data", d.GetName())
-			if err = exp.Export(d); err != nil {
+			if err = exp.Export(producer); err != nil {
 				log.Printf("Diagnoser: %s, export data failed: %v", d.GetName(), err)
 			}
-		}(d)
+		}(d, producer)
 	}
 
 	diagnoserGrp.Wait()
 
-	zip, err := exporter.Zip(dataProducers)
+	if runtimeInfo.ValidateCompleteness {
+		// Streamed data isn't retained, so it is only included in the completeness report, and not the zip archive.
+		report := exporter.ValidateCompleteness(expectedProducers, append(dataProducers, streamedProducers...))
+		if !report.Complete {
+			log.Printf("Warning: no output from %s", strings.Join(report.MissingOutput, ", "))
+		}
+
+		data, err := json.Marshal(report)
+		if err != nil {
+			log.Printf("Could not marshal completeness report: %v", err)
+		} else if err := exp.ExportReader("completeness.json", bytes.NewReader(data)); err != nil {
+			log.Printf("Could not export completeness report: %v", err)
+		}
+	}
+
+	// Distinguishes collectors that were skipped as unsupported from those that failed or produced nothing.
+	statusData, err := json.Marshal(statusRecorder.GetStatuses())
 	if err != nil {
-		log.Printf("Could not zip data: %v", err)
+		log.Printf("Could not marshal collector status: %v", err)
 	} else {
-		if err := exp.ExportReader(runtimeInfo.HostNodeName+".zip", bytes.NewReader(zip.Bytes())); err != nil {
-			log.Printf("Could not export zip archive: %v", err)
+		if anonymizer != nil {
+			// Collector errors often name the objects they failed on.
+			statusData = []byte(anonymizer.Anonymize(string(statusData)))
+		}
+		if err := exp.ExportReader("collector-status.json", bytes.NewReader(statusData)); err != nil {
+			log.Printf("Could not export collector status: %v", err)
+		}
+	}
+
+	// In archive mode, the node's archive holds the same data as its zip archive, so it replaces it.
+	if archiveExporter != nil {
+		if err := archiveExporter.ExportNodeArchive(exportRuntimeInfo.HostNodeName); err != nil {
+			log.Printf("Could not export node archive: %v", err)
+		}
+	} else if err := exportZip(exp, exportRuntimeInfo.HostNodeName+".zip", dataProducers); err != nil {
+		log.Printf("Could not export zip archive: %v", err)
+	}
+
+	// The original names can only be recovered from encrypted output, since the map would otherwise reveal them.
+	if anonymizer != nil && len(runtimeInfo.ExportEncryptionKeyFile) > 0 {
+		mapping, err := json.Marshal(anonymizer.GetMapping())
+		if err != nil {
+			log.Printf("Could not marshal anonymization map: %v", err)
+		} else if err := exp.ExportReader("anonymization-map.json", bytes.NewReader(mapping)); err != nil {
+			log.Printf("Could not export anonymization map: %v", err)
+		}
+	}
+
+	if err := manifestExporter.ExportManifest(); err != nil {
+		log.Printf("Could not export manifest: %v", err)
+	}
+
+	// All exports are complete, so the temporary files of any file-backed data are no longer needed.
+	// Collectors that timed out may still be writing their data, so they are left alone.
+	for _, c := range completedCollectors {
+		if err := utils.RemoveFileBackedData(c); err != nil {
+			log.Printf("Collector: %s, remove temporary data failed: %v", c.GetName(), err)
 		}
 	}
 
 	return nil
 }
+
+// createAnonymizer creates an Anonymizer for the names of the nodes, namespaces and pods of the cluster. Every node
+// must anonymize names the same way, so the salt is read from the configured file, or is the run ID.
+func createAnonymizer(runtimeInfo *utils.RuntimeInfo, clientset kubernetes.Interface) (*utils.Anonymizer, error) {
+	salt := []byte(runtimeInfo.RunId)
+	if len(runtimeInfo.AnonymizeSaltFile) > 0 {
+		content, err := os.ReadFile(runtimeInfo.AnonymizeSaltFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read %s: %w", utils.AnonymizeSaltFileKey, err)
+		}
+		salt = bytes.TrimSpace(content)
+	}
+
+	anonymizer := utils.NewAnonymizer(salt)
+	if err := utils.AddClusterNames(context.Background(), clientset, anonymizer); err != nil {
+		return nil, err
+	}
+
+	return anonymizer, nil
+}
+
+// createExporter creates an exporter for each of the configured export targets.
+func createExporter(runtimeInfo *utils.RuntimeInfo, knownFilePaths *utils.KnownFilePaths) interfaces.Exporter {
+	exporters := []interfaces.Exporter{}
+	for _, target := range runtimeInfo.ExportTargets {
+		switch strings.ToLower(target) {
+		case utils.ExportTargetAzureBlob:
+			exporters = append(exporters, exporter.NewAzureBlobExporter(runtimeInfo, knownFilePaths, runtimeInfo.ExportRunId))
+		case utils.ExportTargetGCS:
+			exporters = append(exporters, exporter.NewGCSExporter(runtimeInfo, runtimeInfo.GCSExportBucket, runtimeInfo.ExportRunId))
+		case utils.ExportTargetHTTP:
+			exporters = append(exporters, exporter.NewHTTPExporter(runtimeInfo, time.Now()))
+		case utils.ExportTargetLocal:
+			exporters = append(exporters, exporter.NewLocalExporter(runtimeInfo, runtimeInfo.LocalExportPath))
+		case utils.ExportTargetPVC:
+			exporters = append(exporters, exporter.NewPVCExporter(runtimeInfo, runtimeInfo.PVCPath))
+		}
+	}
+
+	if len(exporters) == 1 {
+		return exporters[0]
+	}
+
+	return exporter.NewMultiExporter(exporters...)
+}
+
+// exportZip zips the data of all the producers into a temporary file, so that file-backed data (e.g. journals) isn't
+// read back into memory, and exports it under the given name.
+func exportZip(exp interfaces.Exporter, name string, producers []interfaces.DataProducer) error {
+	file, err := os.CreateTemp("", "periscope-zip-*")
+	if err != nil {
+		return fmt.Errorf("create zip file: %w", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	if err := exporter.Zip(file, producers); err != nil {
+		return fmt.Errorf("zip data: %w", err)
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("rewind zip file: %w", err)
+	}
+
+	return exp.ExportReader(name, file)
+}
+
+var errCollectorTimeout = errors.New("collector timed out")
+
+// collectWithTimeout runs the collect function, returning errCollectorTimeout if it doesn't complete within the
+// timeout. A zero timeout means no limit.
+func collectWithTimeout(collect func() error, timeout time.Duration) error {
+	if timeout <= 0 {
+		return collect()
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		result <- collect()
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-time.After(timeout):
+		return errCollectorTimeout
+	}
+}
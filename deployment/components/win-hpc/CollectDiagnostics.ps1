@@ -3,6 +3,8 @@ $ErrorActionPreference = "Stop"
 $runIdPath = Join-Path $env:CONTAINER_SANDBOX_MOUNT_POINT "config\run_id"
 $outputFolder = "\k\periscope-diagnostic-output"
 $logsPath = "${outputFolder}\logs"
+$hnsPath = "${outputFolder}\hns"
+$hnsLogPath = "C:\Windows\System32\LogFiles\hns\hns.log"
 
 # Ensure the output directory exists
 New-Item -ItemType Directory $outputFolder -Force
@@ -23,6 +25,22 @@ while ($true) {
         Remove-Item -Path "${outputFolder}\*" -Force -Recurse
         Expand-Archive -Path $logsZipFileInfo.FullName -Force -DestinationPath $logsPath
 
+        # Export the HNS state, which is only accessible from the host. Any failure here is logged rather than
+        # stopping the run, so that the logs above are still available.
+        New-Item -ItemType Directory $hnsPath -Force
+        try {
+            Import-Module "C:\k\debug\hns.psm1" -DisableNameChecking
+            Get-HnsNetwork | ConvertTo-Json -Depth 20 | Out-File "${hnsPath}\networks.json"
+            Get-HnsEndpoint | ConvertTo-Json -Depth 20 | Out-File "${hnsPath}\endpoints.json"
+            Get-HnsPolicyList | ConvertTo-Json -Depth 20 | Out-File "${hnsPath}\policies.json"
+        }
+        catch {
+            Write-Host "Error exporting HNS state: $_"
+        }
+        if (Test-Path $hnsLogPath) {
+            Copy-Item -Path $hnsLogPath -Destination "${hnsPath}\hns.log"
+        }
+
         # Create an empty file to notify any watchers that log collection is completed for this run,
         # and update previous-run tracker to avoid repeated re-runs.
         New-Item "${outputFolder}\${runId}"
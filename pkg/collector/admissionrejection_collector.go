@@ -0,0 +1,210 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Admission plugins which can reject the creation of a pod.
+const (
+	admissionSourceWebhook     = "webhook"
+	admissionSourcePodSecurity = "PodSecurity"
+	admissionSourcePolicy      = "ValidatingAdmissionPolicy"
+	admissionSourceQuota       = "ResourceQuota"
+)
+
+// admissionRejectionPatterns match the messages of pod creation failures rejected by each admission plugin, capturing
+// the name of the webhook, PodSecurity level, policy or quota which rejected it.
+var admissionRejectionPatterns = []struct {
+	source string
+	regex  *regexp.Regexp
+}{
+	{admissionSourceWebhook, regexp.MustCompile(`admission webhook "([^"]+)" denied the request`)},
+	// A webhook with a Fail failure policy also rejects pods while it is unreachable.
+	{admissionSourceWebhook, regexp.MustCompile(`failed calling webhook "([^"]+)"`)},
+	{admissionSourcePodSecurity, regexp.MustCompile(`violates PodSecurity "([^"]+)"`)},
+	{admissionSourcePolicy, regexp.MustCompile(`ValidatingAdmissionPolicy '([^']+)'`)},
+	{admissionSourceQuota, regexp.MustCompile(`exceeded quota: ([^,]+)`)},
+}
+
+type AdmissionRejection struct {
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Source    string `json:"source"`
+	// Admitter is the name of the webhook, PodSecurity level, policy or quota which rejected the pods.
+	Admitter      string    `json:"admitter"`
+	Message       string    `json:"message"`
+	Count         int32     `json:"count"`
+	LastTimestamp time.Time `json:"lastTimestamp"`
+}
+
+// AdmissionRejectionCollector defines an Admission Rejection Collector struct
+type AdmissionRejectionCollector struct {
+	data        map[string]string
+	clientset   kubernetes.Interface
+	runtimeInfo *utils.RuntimeInfo
+}
+
+// NewAdmissionRejectionCollector is a constructor
+func NewAdmissionRejectionCollector(clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *AdmissionRejectionCollector {
+	return &AdmissionRejectionCollector{
+		data:        make(map[string]string),
+		clientset:   clientset,
+		runtimeInfo: runtimeInfo,
+	}
+}
+
+func (collector *AdmissionRejectionCollector) GetName() string {
+	return "admissionrejections"
+}
+
+func (collector *AdmissionRejectionCollector) CheckSupported() error {
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method. Pods rejected at admission are never created, so they don't appear in the
+// pod list: the rejections are only reported on the workload controllers which tried to create them, in their
+// FailedCreate events and (for ReplicaSets and Deployments) their ReplicaFailure condition.
+func (collector *AdmissionRejectionCollector) Collect() error {
+	ctx := context.Background()
+
+	rejections := map[string]*AdmissionRejection{}
+
+	// ReplicaSets are reported as the Deployment which owns them, since that is the workload users manage.
+	replicaSetOwners := map[string]string{}
+	err := utils.ListAllPages(metav1.ListOptions{}, func(listOptions metav1.ListOptions) (string, error) {
+		replicaSetList, err := collector.clientset.AppsV1().ReplicaSets(metav1.NamespaceAll).List(ctx, listOptions)
+		if err != nil {
+			return "", err
+		}
+
+		for _, replicaSet := range replicaSetList.Items {
+			kind, name := "ReplicaSet", replicaSet.Name
+			if owner := metav1.GetControllerOf(&replicaSet); owner != nil && owner.Kind == "Deployment" {
+				kind, name = owner.Kind, owner.Name
+				replicaSetOwners[replicaSet.Namespace+"/"+replicaSet.Name] = name
+			}
+
+			for _, condition := range replicaSet.Status.Conditions {
+				if condition.Type == appsv1.ReplicaSetReplicaFailure && condition.Status == corev1.ConditionTrue {
+					addAdmissionRejection(rejections, replicaSet.Namespace, kind, name, condition.Message, 0, condition.LastTransitionTime.Time)
+				}
+			}
+		}
+
+		return replicaSetList.Continue, nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list replica sets: %w", err)
+	}
+
+	err = utils.ListAllPages(metav1.ListOptions{FieldSelector: "reason=FailedCreate"}, func(listOptions metav1.ListOptions) (string, error) {
+		eventList, err := collector.clientset.CoreV1().Events(metav1.NamespaceAll).List(ctx, listOptions)
+		if err != nil {
+			return "", err
+		}
+
+		for _, event := range eventList.Items {
+			if event.Reason != "FailedCreate" {
+				continue
+			}
+
+			kind, name := event.InvolvedObject.Kind, event.InvolvedObject.Name
+			if deployment, ok := replicaSetOwners[event.InvolvedObject.Namespace+"/"+name]; ok && kind == "ReplicaSet" {
+				kind, name = "Deployment", deployment
+			}
+
+			addAdmissionRejection(rejections, event.InvolvedObject.Namespace, kind, name, event.Message, event.Count, getEventLastTimestamp(&event))
+		}
+
+		return eventList.Continue, nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list FailedCreate events: %w", err)
+	}
+
+	result := []AdmissionRejection{}
+	for _, rejection := range rejections {
+		result = append(result, *rejection)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Source+"/"+a.Admitter < b.Source+"/"+b.Admitter
+	})
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshall admission rejections to json: %w", err)
+	}
+
+	if err := validateOutput(collector.runtimeInfo, collector.GetName(), "admission-rejections", data); err != nil {
+		return err
+	}
+
+	collector.data["admission-rejections"] = string(data)
+
+	return nil
+}
+
+// addAdmissionRejection records a pod creation failure if it was rejected at admission, merging it with the earlier
+// failures of the same workload rejected by the same admitter and keeping the most recent message.
+func addAdmissionRejection(rejections map[string]*AdmissionRejection, namespace, kind, name, message string, count int32, timestamp time.Time) {
+	source, admitter, ok := getAdmissionRejecter(message)
+	if !ok {
+		return
+	}
+
+	key := strings.Join([]string{namespace, kind, name, source, admitter}, "/")
+	rejection, ok := rejections[key]
+	if !ok {
+		rejection = &AdmissionRejection{Namespace: namespace, Kind: kind, Name: name, Source: source, Admitter: admitter}
+		rejections[key] = rejection
+	}
+
+	rejection.Count += count
+	if rejection.Message == "" || timestamp.After(rejection.LastTimestamp) {
+		rejection.Message = message
+		rejection.LastTimestamp = timestamp
+	}
+}
+
+func getAdmissionRejecter(message string) (string, string, bool) {
+	for _, pattern := range admissionRejectionPatterns {
+		if match := pattern.regex.FindStringSubmatch(message); match != nil {
+			return pattern.source, strings.TrimSpace(match[1]), true
+		}
+	}
+
+	return "", "", false
+}
+
+func (collector *AdmissionRejectionCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
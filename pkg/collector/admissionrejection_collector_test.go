@@ -0,0 +1,186 @@
+package collector
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAdmissionRejectionCollectorGetName(t *testing.T) {
+	const expectedName = "admissionrejections"
+
+	c := NewAdmissionRejectionCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestAdmissionRejectionCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewAdmissionRejectionCollector(nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestAdmissionRejectionCollectorCollect(t *testing.T) {
+	earlier := time.Date(2023, 6, 15, 12, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Minute)
+	controller := true
+
+	webhookMessage := `Error creating: admission webhook "validation.gatekeeper.sh" denied the request: [psp-privileged] privileged container is not allowed`
+	podSecurityMessage := `Error creating: pods "agent-x7k2p" is forbidden: violates PodSecurity "restricted:latest": hostNetwork=true`
+	newEvent := func(name, kind, objectName, message string, count int32, timestamp time.Time) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: kind, Namespace: "default", Name: objectName},
+			Reason:         "FailedCreate",
+			Message:        message,
+			Count:          count,
+			LastTimestamp:  metav1.NewTime(timestamp),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		objects []runtime.Object
+		want    []AdmissionRejection
+	}{
+		{
+			name:    "no rejections",
+			objects: []runtime.Object{},
+			want:    []AdmissionRejection{},
+		},
+		{
+			name: "rejected workloads",
+			objects: []runtime.Object{
+				&appsv1.ReplicaSet{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            "web-5d8f7",
+						Namespace:       "default",
+						OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web", Controller: &controller}},
+					},
+					Status: appsv1.ReplicaSetStatus{Conditions: []appsv1.ReplicaSetCondition{
+						{Type: appsv1.ReplicaSetReplicaFailure, Status: corev1.ConditionTrue, Message: webhookMessage, LastTransitionTime: metav1.NewTime(earlier)},
+					}},
+				},
+				newEvent("web-5d8f7.1", "ReplicaSet", "web-5d8f7", webhookMessage, 4, later),
+				newEvent("agent.1", "DaemonSet", "agent", podSecurityMessage, 2, later),
+				newEvent("job.1", "Job", "migrate", `Error creating: pods "migrate-abc" is forbidden: error looking up service account default/migrate`, 1, later),
+			},
+			want: []AdmissionRejection{
+				{Namespace: "default", Kind: "DaemonSet", Name: "agent", Source: admissionSourcePodSecurity, Admitter: "restricted:latest", Message: podSecurityMessage, Count: 2, LastTimestamp: later},
+				{Namespace: "default", Kind: "Deployment", Name: "web", Source: admissionSourceWebhook, Admitter: "validation.gatekeeper.sh", Message: webhookMessage, Count: 4, LastTimestamp: later},
+			},
+		},
+	}
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList:          []string{},
+		OutputSchemaValidation: utils.OutputSchemaValidationFail,
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewAdmissionRejectionCollector(fake.NewSimpleClientset(tt.objects...), runtimeInfo)
+			if err := c.Collect(); err != nil {
+				t.Fatalf("Collect() error = %v", err)
+			}
+
+			testDataValue(t, c.GetData()["admission-rejections"], func(raw string) {
+				var result []AdmissionRejection
+				if err := json.Unmarshal([]byte(raw), &result); err != nil {
+					t.Fatalf("unmarshal admission rejections: %v", err)
+				}
+				for i := range result {
+					result[i].LastTimestamp = result[i].LastTimestamp.UTC()
+				}
+				if !reflect.DeepEqual(result, tt.want) {
+					t.Errorf("unexpected admission rejections:\nexpected %+v\nfound    %+v", tt.want, result)
+				}
+			})
+		})
+	}
+}
+
+func TestGetAdmissionRejecter(t *testing.T) {
+	tests := []struct {
+		message      string
+		wantSource   string
+		wantAdmitter string
+		wantOk       bool
+	}{
+		{
+			message:      `Error creating: admission webhook "policy.kyverno.svc" denied the request: blocked`,
+			wantSource:   admissionSourceWebhook,
+			wantAdmitter: "policy.kyverno.svc",
+			wantOk:       true,
+		},
+		{
+			message:      `Error creating: pods "p" is forbidden: violates PodSecurity "baseline:v1.27": privileged`,
+			wantSource:   admissionSourcePodSecurity,
+			wantAdmitter: "baseline:v1.27",
+			wantOk:       true,
+		},
+		{
+			message:      `Error creating: pods "p" is forbidden: ValidatingAdmissionPolicy 'no-latest' with binding 'no-latest-binding' denied request: tag`,
+			wantSource:   admissionSourcePolicy,
+			wantAdmitter: "no-latest",
+			wantOk:       true,
+		},
+		{
+			message:      `Error creating: pods "p" is forbidden: exceeded quota: compute, requested: cpu=1, used: cpu=4, limited: cpu=4`,
+			wantSource:   admissionSourceQuota,
+			wantAdmitter: "compute",
+			wantOk:       true,
+		},
+		{
+			message:      `Error creating: Internal error occurred: failed calling webhook "mutate.example.com": context deadline exceeded`,
+			wantSource:   admissionSourceWebhook,
+			wantAdmitter: "mutate.example.com",
+			wantOk:       true,
+		},
+		{
+			message: `Error creating: pods "p" is forbidden: error looking up service account default/p: serviceaccount "p" not found`,
+			wantOk:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		source, admitter, ok := getAdmissionRejecter(tt.message)
+		if source != tt.wantSource || admitter != tt.wantAdmitter || ok != tt.wantOk {
+			t.Errorf("%s: expected (%s, %s, %v), found (%s, %s, %v)", tt.message, tt.wantSource, tt.wantAdmitter, tt.wantOk, source, admitter, ok)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+type AntiAffinityViolation struct {
+	Kind                    string         `json:"kind"`
+	Namespace               string         `json:"namespace"`
+	Name                    string         `json:"name"`
+	AntiAffinity            string         `json:"antiAffinity"`
+	Replicas                int            `json:"replicas"`
+	PodsPerNode             map[string]int `json:"podsPerNode"`
+	CoLocatedNodes          []string       `json:"coLocatedNodes"`
+	SingleNodeConcentration bool           `json:"singleNodeConcentration"`
+}
+
+type antiAffinityWorkload struct {
+	kind         string
+	namespace    string
+	name         string
+	antiAffinity string
+	podsPerNode  map[string]int
+}
+
+// AntiAffinityViolationCollector defines an Anti-Affinity Violation Collector struct
+type AntiAffinityViolationCollector struct {
+	data        map[string]string
+	clientset   kubernetes.Interface
+	runtimeInfo *utils.RuntimeInfo
+}
+
+// NewAntiAffinityViolationCollector is a constructor
+func NewAntiAffinityViolationCollector(clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *AntiAffinityViolationCollector {
+	return &AntiAffinityViolationCollector{
+		data:        make(map[string]string),
+		clientset:   clientset,
+		runtimeInfo: runtimeInfo,
+	}
+}
+
+func (collector *AntiAffinityViolationCollector) GetName() string {
+	return "antiaffinityviolations"
+}
+
+func (collector *AntiAffinityViolationCollector) CheckSupported() error {
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *AntiAffinityViolationCollector) Collect() error {
+	podList, err := collector.clientset.CoreV1().Pods(metav1.NamespaceAll).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list pods: %w", err)
+	}
+
+	workloads := map[string]*antiAffinityWorkload{}
+	for _, pod := range podList.Items {
+		// Only scheduled replicas which are still expected to be running can co-locate.
+		if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+
+		antiAffinity := getSelfAntiAffinity(&pod)
+		if antiAffinity == "" {
+			continue
+		}
+
+		kind, name := getPodWorkload(&pod)
+		if kind == "" {
+			continue
+		}
+
+		key := fmt.Sprintf("%s/%s/%s", kind, pod.Namespace, name)
+		workload, ok := workloads[key]
+		if !ok {
+			workload = &antiAffinityWorkload{
+				kind:         kind,
+				namespace:    pod.Namespace,
+				name:         name,
+				antiAffinity: antiAffinity,
+				podsPerNode:  map[string]int{},
+			}
+			workloads[key] = workload
+		}
+		workload.podsPerNode[pod.Spec.NodeName]++
+	}
+
+	result := []AntiAffinityViolation{}
+	for _, workload := range workloads {
+		violation := AntiAffinityViolation{
+			Kind:           workload.kind,
+			Namespace:      workload.namespace,
+			Name:           workload.name,
+			AntiAffinity:   workload.antiAffinity,
+			PodsPerNode:    workload.podsPerNode,
+			CoLocatedNodes: []string{},
+		}
+
+		for node, count := range workload.podsPerNode {
+			violation.Replicas += count
+			if count > 1 {
+				violation.CoLocatedNodes = append(violation.CoLocatedNodes, node)
+			}
+		}
+
+		if len(violation.CoLocatedNodes) == 0 {
+			continue
+		}
+
+		sort.Strings(violation.CoLocatedNodes)
+		violation.SingleNodeConcentration = len(workload.podsPerNode) == 1
+		result = append(result, violation)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return fmt.Sprintf("%s/%s/%s", result[i].Kind, result[i].Namespace, result[i].Name) < fmt.Sprintf("%s/%s/%s", result[j].Kind, result[j].Namespace, result[j].Name)
+	})
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshall anti-affinity violations to json: %w", err)
+	}
+
+	collector.data["antiaffinityviolations"] = string(data)
+
+	return nil
+}
+
+// getSelfAntiAffinity returns "required" or "preferred" if the pod has a hostname-scoped anti-affinity term that
+// selects its own labels (i.e. one intended to spread its replicas across nodes), or an empty string otherwise.
+func getSelfAntiAffinity(pod *corev1.Pod) string {
+	if pod.Spec.Affinity == nil || pod.Spec.Affinity.PodAntiAffinity == nil {
+		return ""
+	}
+
+	antiAffinity := pod.Spec.Affinity.PodAntiAffinity
+	for _, term := range antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+		if isSelfHostnameTerm(pod, &term) {
+			return "required"
+		}
+	}
+	for _, weightedTerm := range antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+		if isSelfHostnameTerm(pod, &weightedTerm.PodAffinityTerm) {
+			return "preferred"
+		}
+	}
+
+	return ""
+}
+
+func isSelfHostnameTerm(pod *corev1.Pod, term *corev1.PodAffinityTerm) bool {
+	if term.TopologyKey != corev1.LabelHostname || term.LabelSelector == nil {
+		return false
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(term.LabelSelector)
+	if err != nil {
+		return false
+	}
+
+	return selector.Matches(labels.Set(pod.Labels))
+}
+
+// getPodWorkload returns the kind and name of the workload controlling the pod. Pods owned by a ReplicaSet are
+// attributed to their Deployment using the pod template hash, which avoids needing access to ReplicaSets.
+func getPodWorkload(pod *corev1.Pod) (string, string) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return "", ""
+	}
+
+	if owner.Kind == "ReplicaSet" {
+		hash, ok := pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey]
+		if ok && strings.HasSuffix(owner.Name, "-"+hash) {
+			return "Deployment", strings.TrimSuffix(owner.Name, "-"+hash)
+		}
+	}
+
+	return owner.Kind, owner.Name
+}
+
+func (collector *AntiAffinityViolationCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
@@ -0,0 +1,152 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAntiAffinityViolationCollectorGetName(t *testing.T) {
+	const expectedName = "antiaffinityviolations"
+
+	c := NewAntiAffinityViolationCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestAntiAffinityViolationCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewAntiAffinityViolationCollector(nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestAntiAffinityViolationCollectorCollect(t *testing.T) {
+	isController := true
+	newPod := func(name, app, ownerKind, ownerName, hash, nodeName string, antiAffinity *corev1.PodAntiAffinity) *corev1.Pod {
+		podLabels := map[string]string{"app": app}
+		if hash != "" {
+			podLabels["pod-template-hash"] = hash
+		}
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       "default",
+				Labels:          podLabels,
+				OwnerReferences: []metav1.OwnerReference{{Kind: ownerKind, Name: ownerName, Controller: &isController}},
+			},
+			Spec: corev1.PodSpec{
+				NodeName: nodeName,
+				Affinity: &corev1.Affinity{PodAntiAffinity: antiAffinity},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	preferred := func(app string) *corev1.PodAntiAffinity {
+		return &corev1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{{
+				Weight: 100,
+				PodAffinityTerm: corev1.PodAffinityTerm{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": app}},
+					TopologyKey:   corev1.LabelHostname,
+				},
+			}},
+		}
+	}
+	required := func(app string) *corev1.PodAntiAffinity {
+		return &corev1.PodAntiAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": app}},
+				TopologyKey:   corev1.LabelHostname,
+			}},
+		}
+	}
+
+	objects := []runtime.Object{
+		// Deployment with two of three replicas sharing node1
+		newPod("web-5d4f8-a", "web", "ReplicaSet", "web-5d4f8", "5d4f8", "node1", preferred("web")),
+		newPod("web-5d4f8-b", "web", "ReplicaSet", "web-5d4f8", "5d4f8", "node1", preferred("web")),
+		newPod("web-5d4f8-c", "web", "ReplicaSet", "web-5d4f8", "5d4f8", "node2", preferred("web")),
+		// Deployment with all replicas on a single node
+		newPod("cache-7c9b-a", "cache", "ReplicaSet", "cache-7c9b", "7c9b", "node2", preferred("cache")),
+		newPod("cache-7c9b-b", "cache", "ReplicaSet", "cache-7c9b", "7c9b", "node2", preferred("cache")),
+		// StatefulSet spread across nodes
+		newPod("db-0", "db", "StatefulSet", "db", "", "node1", required("db")),
+		newPod("db-1", "db", "StatefulSet", "db", "", "node2", required("db")),
+		// Co-located Deployment without anti-affinity
+		newPod("batch-6b7c-a", "batch", "ReplicaSet", "batch-6b7c", "6b7c", "node1", nil),
+		newPod("batch-6b7c-b", "batch", "ReplicaSet", "batch-6b7c", "6b7c", "node1", nil),
+		// Co-located Deployment whose anti-affinity targets other pods
+		newPod("api-8f9a-a", "api", "ReplicaSet", "api-8f9a", "8f9a", "node1", preferred("web")),
+		newPod("api-8f9a-b", "api", "ReplicaSet", "api-8f9a", "8f9a", "node1", preferred("web")),
+	}
+
+	clientset := fake.NewSimpleClientset(objects...)
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+	}
+
+	c := NewAntiAffinityViolationCollector(clientset, runtimeInfo)
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	testDataValue(t, c.GetData()["antiaffinityviolations"], func(raw string) {
+		var result []AntiAffinityViolation
+		if err := json.Unmarshal([]byte(raw), &result); err != nil {
+			t.Fatalf("unmarshal GetData(): %v", err)
+		}
+
+		if len(result) != 2 {
+			t.Fatalf("expected 2 violations, found %s", raw)
+		}
+
+		cache := result[0]
+		if cache.Kind != "Deployment" || cache.Name != "cache" || cache.AntiAffinity != "preferred" || cache.Replicas != 2 {
+			t.Errorf("unexpected violation: %+v", cache)
+		}
+		if !cache.SingleNodeConcentration || fmt.Sprint(cache.CoLocatedNodes) != "[node2]" {
+			t.Errorf("expected single node concentration on node2: %+v", cache)
+		}
+
+		web := result[1]
+		if web.Kind != "Deployment" || web.Name != "web" || web.Replicas != 3 || web.PodsPerNode["node1"] != 2 {
+			t.Errorf("unexpected violation: %+v", web)
+		}
+		if web.SingleNodeConcentration || fmt.Sprint(web.CoLocatedNodes) != "[node1]" {
+			t.Errorf("expected co-location on node1 only: %+v", web)
+		}
+	})
+}
@@ -0,0 +1,335 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// The number of most recent events included for each autoscaler.
+const autoscalerMaxEvents = 10
+
+const vpaCRDName = "verticalpodautoscalers.autoscaling.k8s.io"
+
+// The cluster autoscaler writes a human-readable summary of its state to this ConfigMap. See:
+// https://github.com/kubernetes/autoscaler/blob/master/cluster-autoscaler/FAQ.md#how-can-i-check-what-is-going-on-in-ca-
+const (
+	clusterAutoscalerStatusConfigMap       = "cluster-autoscaler-status"
+	clusterAutoscalerLastUpdatedAnnotation = "cluster-autoscaler.kubernetes.io/last-updated"
+)
+
+type HPAInfo struct {
+	Namespace       string                       `json:"namespace"`
+	Name            string                       `json:"name"`
+	ScaleTargetRef  string                       `json:"scaleTargetRef"`
+	MinReplicas     *int32                       `json:"minReplicas,omitempty"`
+	MaxReplicas     int32                        `json:"maxReplicas"`
+	CurrentReplicas int32                        `json:"currentReplicas"`
+	DesiredReplicas int32                        `json:"desiredReplicas"`
+	LastScaleTime   *time.Time                   `json:"lastScaleTime,omitempty"`
+	TargetMetrics   []autoscalingv2.MetricSpec   `json:"targetMetrics"`
+	CurrentMetrics  []autoscalingv2.MetricStatus `json:"currentMetrics"`
+	Conditions      []AutoscalerCondition        `json:"conditions"`
+	Events          []AutoscalerEvent            `json:"events"`
+}
+
+type VPAInfo struct {
+	Namespace                string                `json:"namespace"`
+	Name                     string                `json:"name"`
+	TargetRef                string                `json:"targetRef"`
+	UpdateMode               string                `json:"updateMode,omitempty"`
+	ContainerRecommendations []interface{}         `json:"containerRecommendations"`
+	Conditions               []AutoscalerCondition `json:"conditions"`
+	Events                   []AutoscalerEvent     `json:"events"`
+}
+
+type AutoscalerCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type AutoscalerEvent struct {
+	Type          string    `json:"type"`
+	Reason        string    `json:"reason"`
+	Message       string    `json:"message"`
+	Count         int32     `json:"count"`
+	LastTimestamp time.Time `json:"lastTimestamp"`
+}
+
+type ClusterAutoscalerStatus struct {
+	LastUpdated string `json:"lastUpdated,omitempty"`
+	Status      string `json:"status"`
+}
+
+// AutoscalerCollector defines an Autoscaler Collector struct
+type AutoscalerCollector struct {
+	data          map[string]string
+	clientset     kubernetes.Interface
+	dynamicClient dynamic.Interface
+	runtimeInfo   *utils.RuntimeInfo
+}
+
+// NewAutoscalerCollector is a constructor
+func NewAutoscalerCollector(clientset kubernetes.Interface, dynamicClient dynamic.Interface, runtimeInfo *utils.RuntimeInfo) *AutoscalerCollector {
+	return &AutoscalerCollector{
+		data:          make(map[string]string),
+		clientset:     clientset,
+		dynamicClient: dynamicClient,
+		runtimeInfo:   runtimeInfo,
+	}
+}
+
+func (collector *AutoscalerCollector) GetName() string {
+	return "autoscaler"
+}
+
+func (collector *AutoscalerCollector) CheckSupported() error {
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *AutoscalerCollector) Collect() error {
+	ctx := context.Background()
+
+	if err := collector.collectHPAs(ctx); err != nil {
+		return err
+	}
+
+	if err := collector.collectVPAs(ctx); err != nil {
+		return err
+	}
+
+	return collector.collectClusterAutoscalerStatus(ctx)
+}
+
+func (collector *AutoscalerCollector) collectHPAs(ctx context.Context) error {
+	hpaList, err := collector.clientset.AutoscalingV2().HorizontalPodAutoscalers(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list horizontal pod autoscalers: %w", err)
+	}
+
+	events, err := collector.getAutoscalerEvents(ctx, "HorizontalPodAutoscaler")
+	if err != nil {
+		return err
+	}
+
+	result := []HPAInfo{}
+	for _, hpa := range hpaList.Items {
+		info := HPAInfo{
+			Namespace:       hpa.Namespace,
+			Name:            hpa.Name,
+			ScaleTargetRef:  hpa.Spec.ScaleTargetRef.Kind + "/" + hpa.Spec.ScaleTargetRef.Name,
+			MinReplicas:     hpa.Spec.MinReplicas,
+			MaxReplicas:     hpa.Spec.MaxReplicas,
+			CurrentReplicas: hpa.Status.CurrentReplicas,
+			DesiredReplicas: hpa.Status.DesiredReplicas,
+			TargetMetrics:   hpa.Spec.Metrics,
+			CurrentMetrics:  hpa.Status.CurrentMetrics,
+			Conditions:      []AutoscalerCondition{},
+			Events:          []AutoscalerEvent{},
+		}
+		if hpaEvents, ok := events[hpa.Namespace+"/"+hpa.Name]; ok {
+			info.Events = hpaEvents
+		}
+		if hpa.Status.LastScaleTime != nil {
+			info.LastScaleTime = &hpa.Status.LastScaleTime.Time
+		}
+
+		// The AbleToScale, ScalingActive and ScalingLimited conditions explain why the HPA is not scaling.
+		for _, condition := range hpa.Status.Conditions {
+			info.Conditions = append(info.Conditions, AutoscalerCondition{
+				Type:    string(condition.Type),
+				Status:  string(condition.Status),
+				Reason:  condition.Reason,
+				Message: condition.Message,
+			})
+		}
+
+		result = append(result, info)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Namespace+"/"+result[i].Name < result[j].Namespace+"/"+result[j].Name
+	})
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshall horizontal pod autoscalers to json: %w", err)
+	}
+
+	collector.data["hpa"] = string(data)
+
+	return nil
+}
+
+// collectVPAs reads VerticalPodAutoscalers, which are only available when the VPA CRDs are installed (e.g. by the
+// AKS vertical pod autoscaler add-on).
+func (collector *AutoscalerCollector) collectVPAs(ctx context.Context) error {
+	crd, err := collector.dynamicClient.Resource(crdGVR).Get(ctx, vpaCRDName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to get CRD %s: %w", vpaCRDName, err)
+	}
+
+	gvr, err := utils.GetStorageGVRFromCRD(crd)
+	if err != nil {
+		return fmt.Errorf("unable to get resource version for %s: %w", vpaCRDName, err)
+	}
+
+	vpaList, err := collector.dynamicClient.Resource(*gvr).Namespace(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list vertical pod autoscalers: %w", err)
+	}
+
+	events, err := collector.getAutoscalerEvents(ctx, "VerticalPodAutoscaler")
+	if err != nil {
+		return err
+	}
+
+	result := []VPAInfo{}
+	for i := range vpaList.Items {
+		info := getVPAInfo(&vpaList.Items[i])
+		if vpaEvents, ok := events[info.Namespace+"/"+info.Name]; ok {
+			info.Events = vpaEvents
+		}
+		result = append(result, info)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Namespace+"/"+result[i].Name < result[j].Namespace+"/"+result[j].Name
+	})
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshall vertical pod autoscalers to json: %w", err)
+	}
+
+	collector.data["vpa"] = string(data)
+
+	return nil
+}
+
+// getVPAInfo reads the target, update mode, recommendations and conditions of a VerticalPodAutoscaler. See:
+// https://github.com/kubernetes/autoscaler/blob/master/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1/types.go
+func getVPAInfo(vpa *unstructured.Unstructured) VPAInfo {
+	info := VPAInfo{
+		Namespace:                vpa.GetNamespace(),
+		Name:                     vpa.GetName(),
+		ContainerRecommendations: []interface{}{},
+		Conditions:               []AutoscalerCondition{},
+		Events:                   []AutoscalerEvent{},
+	}
+
+	kind, _, _ := unstructured.NestedString(vpa.Object, "spec", "targetRef", "kind")
+	name, _, _ := unstructured.NestedString(vpa.Object, "spec", "targetRef", "name")
+	info.TargetRef = kind + "/" + name
+	info.UpdateMode, _, _ = unstructured.NestedString(vpa.Object, "spec", "updatePolicy", "updateMode")
+
+	if recommendations, found, _ := unstructured.NestedSlice(vpa.Object, "status", "recommendation", "containerRecommendations"); found {
+		info.ContainerRecommendations = recommendations
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(vpa.Object, "status", "conditions")
+	for _, item := range conditions {
+		condition, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		var result AutoscalerCondition
+		result.Type, _, _ = unstructured.NestedString(condition, "type")
+		result.Status, _, _ = unstructured.NestedString(condition, "status")
+		result.Reason, _, _ = unstructured.NestedString(condition, "reason")
+		result.Message, _, _ = unstructured.NestedString(condition, "message")
+		info.Conditions = append(info.Conditions, result)
+	}
+
+	return info
+}
+
+// getAutoscalerEvents returns the most recent events of each autoscaler of the given kind, by namespace/name.
+func (collector *AutoscalerCollector) getAutoscalerEvents(ctx context.Context, kind string) (map[string][]AutoscalerEvent, error) {
+	eventList, err := collector.clientset.CoreV1().Events(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: "involvedObject.kind=" + kind,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list %s events: %w", kind, err)
+	}
+
+	result := map[string][]AutoscalerEvent{}
+	for i := range eventList.Items {
+		event := &eventList.Items[i]
+		if event.InvolvedObject.Kind != kind {
+			continue
+		}
+
+		key := event.InvolvedObject.Namespace + "/" + event.InvolvedObject.Name
+		result[key] = append(result[key], AutoscalerEvent{
+			Type:          event.Type,
+			Reason:        event.Reason,
+			Message:       event.Message,
+			Count:         event.Count,
+			LastTimestamp: getEventLastTimestamp(event),
+		})
+	}
+
+	for key, events := range result {
+		sort.Slice(events, func(i, j int) bool {
+			return events[i].LastTimestamp.After(events[j].LastTimestamp)
+		})
+		if len(events) > autoscalerMaxEvents {
+			result[key] = events[:autoscalerMaxEvents]
+		}
+	}
+
+	return result, nil
+}
+
+func (collector *AutoscalerCollector) collectClusterAutoscalerStatus(ctx context.Context) error {
+	configMap, err := collector.clientset.CoreV1().ConfigMaps(metav1.NamespaceSystem).Get(ctx, clusterAutoscalerStatusConfigMap, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		// The cluster autoscaler is not enabled.
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to get %s ConfigMap: %w", clusterAutoscalerStatusConfigMap, err)
+	}
+
+	status := ClusterAutoscalerStatus{
+		LastUpdated: configMap.Annotations[clusterAutoscalerLastUpdatedAnnotation],
+		Status:      configMap.Data["status"],
+	}
+
+	data, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("marshall cluster autoscaler status to json: %w", err)
+	}
+
+	collector.data["cluster-autoscaler-status"] = string(data)
+
+	return nil
+}
+
+func (collector *AutoscalerCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
@@ -0,0 +1,236 @@
+package collector
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAutoscalerCollectorGetName(t *testing.T) {
+	const expectedName = "autoscaler"
+
+	c := NewAutoscalerCollector(nil, nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestAutoscalerCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewAutoscalerCollector(nil, nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestAutoscalerCollectorCollect(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	minReplicas := int32(2)
+	cpuUtilization := int32(80)
+
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app"},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "web"},
+			MinReplicas:    &minReplicas,
+			MaxReplicas:    10,
+			Metrics: []autoscalingv2.MetricSpec{{
+				Type: autoscalingv2.ResourceMetricSourceType,
+				Resource: &autoscalingv2.ResourceMetricSource{
+					Name:   corev1.ResourceCPU,
+					Target: autoscalingv2.MetricTarget{Type: autoscalingv2.UtilizationMetricType, AverageUtilization: &cpuUtilization},
+				},
+			}},
+		},
+		Status: autoscalingv2.HorizontalPodAutoscalerStatus{
+			CurrentReplicas: 2,
+			DesiredReplicas: 2,
+			Conditions: []autoscalingv2.HorizontalPodAutoscalerCondition{{
+				Type:    autoscalingv2.ScalingActive,
+				Status:  corev1.ConditionFalse,
+				Reason:  "FailedGetResourceMetric",
+				Message: "the HPA was unable to compute the replica count: failed to get cpu utilization",
+			}},
+		},
+	}
+
+	newEvent := func(name, kind, objectName, reason string, lastTimestamp time.Time) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "app"},
+			InvolvedObject: corev1.ObjectReference{Kind: kind, Namespace: "app", Name: objectName},
+			Type:           corev1.EventTypeWarning,
+			Reason:         reason,
+			LastTimestamp:  metav1.NewTime(lastTimestamp),
+		}
+	}
+
+	statusConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "cluster-autoscaler-status",
+			Namespace:   "kube-system",
+			Annotations: map[string]string{"cluster-autoscaler.kubernetes.io/last-updated": "2023-06-01 11:59:50.123 +0000 UTC"},
+		},
+		Data: map[string]string{"status": "Cluster-autoscaler status at 2023-06-01 11:59:50: Health: Healthy"},
+	}
+
+	vpaCRD := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": "verticalpodautoscalers.autoscaling.k8s.io"},
+		"spec": map[string]interface{}{
+			"versions": []interface{}{
+				map[string]interface{}{"name": "v1", "storage": true},
+			},
+		},
+	}}
+	vpa := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "autoscaling.k8s.io/v1",
+		"kind":       "VerticalPodAutoscaler",
+		"metadata":   map[string]interface{}{"name": "api", "namespace": "app"},
+		"spec": map[string]interface{}{
+			"targetRef":    map[string]interface{}{"kind": "Deployment", "name": "api"},
+			"updatePolicy": map[string]interface{}{"updateMode": "Auto"},
+		},
+		"status": map[string]interface{}{
+			"recommendation": map[string]interface{}{
+				"containerRecommendations": []interface{}{
+					map[string]interface{}{"containerName": "api", "target": map[string]interface{}{"cpu": "250m", "memory": "256Mi"}},
+				},
+			},
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "RecommendationProvided", "status": "True"},
+			},
+		},
+	}}
+
+	crdGVR := schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+	vpaGVR := schema.GroupVersionResource{Group: "autoscaling.k8s.io", Version: "v1", Resource: "verticalpodautoscalers"}
+	listKinds := map[schema.GroupVersionResource]string{
+		crdGVR: "CustomResourceDefinitionList",
+		vpaGVR: "VerticalPodAutoscalerList",
+	}
+
+	tests := []struct {
+		name           string
+		objects        []runtime.Object
+		dynamicObjects []runtime.Object
+		wantKeys       []string
+	}{
+		{
+			name:           "HPA only",
+			objects:        []runtime.Object{hpa},
+			dynamicObjects: []runtime.Object{},
+			wantKeys:       []string{"hpa"},
+		},
+		{
+			name: "HPA, VPA and cluster autoscaler",
+			objects: []runtime.Object{
+				hpa,
+				statusConfigMap,
+				newEvent("web.1", "HorizontalPodAutoscaler", "web", "FailedGetResourceMetric", now.Add(-time.Minute)),
+				newEvent("web.2", "HorizontalPodAutoscaler", "web", "SuccessfulRescale", now),
+				newEvent("web-pod.1", "Pod", "web", "BackOff", now),
+			},
+			dynamicObjects: []runtime.Object{vpaCRD, vpa},
+			wantKeys:       []string{"hpa", "vpa", "cluster-autoscaler-status"},
+		},
+	}
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(tt.objects...)
+			dynamicClient := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, tt.dynamicObjects...)
+
+			c := NewAutoscalerCollector(clientset, dynamicClient, runtimeInfo)
+			if err := c.Collect(); err != nil {
+				t.Fatalf("Collect() error = %v", err)
+			}
+
+			if len(c.data) != len(tt.wantKeys) {
+				t.Errorf("expected keys %v, found %d keys", tt.wantKeys, len(c.data))
+			}
+			for _, key := range tt.wantKeys {
+				if _, ok := c.data[key]; !ok {
+					t.Errorf("missing key %s", key)
+				}
+			}
+
+			var hpas []HPAInfo
+			if err := json.Unmarshal([]byte(c.data["hpa"]), &hpas); err != nil {
+				t.Fatalf("unable to unmarshal hpa: %v", err)
+			}
+			if len(hpas) != 1 || hpas[0].ScaleTargetRef != "Deployment/web" || hpas[0].MaxReplicas != 10 || len(hpas[0].TargetMetrics) != 1 {
+				t.Fatalf("unexpected HPAs %s", c.data["hpa"])
+			}
+			if len(hpas[0].Conditions) != 1 || hpas[0].Conditions[0].Reason != "FailedGetResourceMetric" {
+				t.Errorf("unexpected HPA conditions %+v", hpas[0].Conditions)
+			}
+
+			vpaData, ok := c.data["vpa"]
+			if !ok {
+				if len(hpas[0].Events) != 0 {
+					t.Errorf("unexpected HPA events %+v", hpas[0].Events)
+				}
+				return
+			}
+
+			// Events are most recent first, and only those of the autoscaler are included.
+			if len(hpas[0].Events) != 2 || hpas[0].Events[0].Reason != "SuccessfulRescale" {
+				t.Errorf("unexpected HPA events %+v", hpas[0].Events)
+			}
+
+			var vpas []VPAInfo
+			if err := json.Unmarshal([]byte(vpaData), &vpas); err != nil {
+				t.Fatalf("unable to unmarshal vpa: %v", err)
+			}
+			if len(vpas) != 1 || vpas[0].TargetRef != "Deployment/api" || vpas[0].UpdateMode != "Auto" || len(vpas[0].ContainerRecommendations) != 1 || len(vpas[0].Conditions) != 1 {
+				t.Errorf("unexpected VPAs %s", vpaData)
+			}
+
+			var status ClusterAutoscalerStatus
+			if err := json.Unmarshal([]byte(c.data["cluster-autoscaler-status"]), &status); err != nil {
+				t.Fatalf("unable to unmarshal cluster autoscaler status: %v", err)
+			}
+			if status.LastUpdated == "" || status.Status != statusConfigMap.Data["status"] {
+				t.Errorf("unexpected cluster autoscaler status %+v", status)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+type AzureCNIState struct {
+	Pools                  []AzureCNIPool     `json:"pools"`
+	Allocated              int                `json:"allocated"`
+	Available              int                `json:"available"`
+	Exhausted              bool               `json:"exhausted"`
+	Endpoints              []AzureCNIEndpoint `json:"endpoints"`
+	UnavailableDataSources []string           `json:"unavailableDataSources,omitempty"`
+}
+
+type AzureCNIPool struct {
+	Subnet    string `json:"subnet"`
+	Interface string `json:"interface"`
+	Total     int    `json:"total"`
+	Allocated int    `json:"allocated"`
+	Available int    `json:"available"`
+}
+
+type AzureCNIEndpoint struct {
+	PodNamespace string   `json:"podNamespace"`
+	PodName      string   `json:"podName"`
+	ContainerID  string   `json:"containerId"`
+	IPAddresses  []string `json:"ipAddresses"`
+}
+
+// The subset of the IPAM state file (azure-vnet-ipam.json) describing address allocation.
+type azureCNIIPAMFile struct {
+	IPAM struct {
+		AddressSpaces map[string]struct {
+			Pools map[string]struct {
+				IfName    string
+				Addresses map[string]struct {
+					InUse bool
+				}
+			}
+		}
+	}
+}
+
+// The subset of the network state file (azure-vnet.json) describing the endpoints of each pod.
+type azureCNINetworkFile struct {
+	Network struct {
+		ExternalInterfaces map[string]struct {
+			Networks map[string]struct {
+				Endpoints map[string]struct {
+					PODName      string
+					PODNameSpace string
+					ContainerID  string
+					IPAddresses  []struct {
+						IP string
+					}
+				}
+			}
+		}
+	}
+}
+
+// AzureCNICollector defines an Azure CNI Collector struct
+type AzureCNICollector struct {
+	data       map[string]interfaces.DataValue
+	filePaths  *utils.KnownFilePaths
+	fileSystem interfaces.FileSystemAccessor
+}
+
+// NewAzureCNICollector is a constructor
+func NewAzureCNICollector(filePaths *utils.KnownFilePaths, fileSystem interfaces.FileSystemAccessor) *AzureCNICollector {
+	return &AzureCNICollector{
+		data:       make(map[string]interfaces.DataValue),
+		filePaths:  filePaths,
+		fileSystem: fileSystem,
+	}
+}
+
+func (collector *AzureCNICollector) GetName() string {
+	return "azurecni"
+}
+
+func (collector *AzureCNICollector) CheckSupported() error {
+	// The state files are only written by the Azure CNI plugin, so are absent on kubenet and Cilium clusters
+	// (and on Windows nodes, where these paths are not defined).
+	for _, filePath := range []string{collector.filePaths.AzureCNIState, collector.filePaths.AzureCNIIPAMState} {
+		if filePath == "" {
+			continue
+		}
+
+		exists, err := collector.fileSystem.FileExists(filePath)
+		if err != nil {
+			return fmt.Errorf("error checking existence of %s: %w", filePath, err)
+		}
+		if exists {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no Azure CNI state found at %s or %s", collector.filePaths.AzureCNIState, collector.filePaths.AzureCNIIPAMState)
+}
+
+// Collect implements the interface method
+func (collector *AzureCNICollector) Collect() error {
+	state := AzureCNIState{
+		Pools:                  []AzureCNIPool{},
+		Endpoints:              []AzureCNIEndpoint{},
+		UnavailableDataSources: []string{},
+	}
+
+	// With dynamic IP allocation, addresses are managed by CNS rather than in the IPAM file, so either
+	// file may legitimately be absent.
+	ipam := azureCNIIPAMFile{}
+	if err := collector.readJSON(collector.filePaths.AzureCNIIPAMState, &ipam); err != nil {
+		state.UnavailableDataSources = append(state.UnavailableDataSources, err.Error())
+	}
+
+	for _, addressSpace := range ipam.IPAM.AddressSpaces {
+		for subnet, pool := range addressSpace.Pools {
+			p := AzureCNIPool{
+				Subnet:    subnet,
+				Interface: pool.IfName,
+				Total:     len(pool.Addresses),
+			}
+			for _, address := range pool.Addresses {
+				if address.InUse {
+					p.Allocated++
+				}
+			}
+			p.Available = p.Total - p.Allocated
+
+			state.Pools = append(state.Pools, p)
+			state.Allocated += p.Allocated
+			state.Available += p.Available
+		}
+	}
+	state.Exhausted = len(state.Pools) > 0 && state.Available == 0
+
+	network := azureCNINetworkFile{}
+	if err := collector.readJSON(collector.filePaths.AzureCNIState, &network); err != nil {
+		state.UnavailableDataSources = append(state.UnavailableDataSources, err.Error())
+	}
+
+	for _, externalInterface := range network.Network.ExternalInterfaces {
+		for _, nw := range externalInterface.Networks {
+			for _, endpoint := range nw.Endpoints {
+				e := AzureCNIEndpoint{
+					PodNamespace: endpoint.PODNameSpace,
+					PodName:      endpoint.PODName,
+					ContainerID:  endpoint.ContainerID,
+					IPAddresses:  []string{},
+				}
+				for _, address := range endpoint.IPAddresses {
+					e.IPAddresses = append(e.IPAddresses, address.IP)
+				}
+				state.Endpoints = append(state.Endpoints, e)
+			}
+		}
+	}
+
+	sort.Slice(state.Pools, func(i, j int) bool {
+		return state.Pools[i].Subnet < state.Pools[j].Subnet
+	})
+	sort.Slice(state.Endpoints, func(i, j int) bool {
+		a, b := state.Endpoints[i], state.Endpoints[j]
+		return a.PodNamespace+"/"+a.PodName+"/"+a.ContainerID < b.PodNamespace+"/"+b.PodName+"/"+b.ContainerID
+	})
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("marshall Azure CNI state to json: %w", err)
+	}
+
+	collector.data["azure-cni-state"] = utils.NewStringDataValue(string(data))
+
+	if exists, _ := collector.fileSystem.FileExists(collector.filePaths.AzureCNILog); exists {
+		size, err := collector.fileSystem.GetFileSize(collector.filePaths.AzureCNILog)
+		if err != nil {
+			return fmt.Errorf("error getting file size for %s: %w", collector.filePaths.AzureCNILog, err)
+		}
+
+		collector.data["azure-cni-log"] = utils.NewFilePathDataValue(collector.fileSystem, collector.filePaths.AzureCNILog, size)
+	}
+
+	return nil
+}
+
+func (collector *AzureCNICollector) readJSON(filePath string, v interface{}) error {
+	content, err := utils.GetContent(func() (io.ReadCloser, error) { return collector.fileSystem.GetFileReader(filePath) })
+	if err != nil {
+		return fmt.Errorf("unable to read %s: %w", filePath, err)
+	}
+
+	if err := json.Unmarshal([]byte(content), v); err != nil {
+		return fmt.Errorf("unable to parse %s: %w", filePath, err)
+	}
+
+	return nil
+}
+
+func (collector *AzureCNICollector) GetData() map[string]interfaces.DataValue {
+	return collector.data
+}
@@ -0,0 +1,203 @@
+package collector
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+const testAzureCNIIPAMState = `{
+  "IPAM": {
+    "AddressSpaces": {
+      "local": {
+        "Id": "local",
+        "Pools": {
+          "10.240.0.0/16": {
+            "Id": "10.240.0.0/16",
+            "IfName": "eth0",
+            "Gateway": "10.240.0.1",
+            "Addresses": {
+              "10.240.0.5": {"ID": "", "Addr": "10.240.0.5", "InUse": false},
+              "10.240.0.6": {"ID": "abc123", "Addr": "10.240.0.6", "InUse": true},
+              "10.240.0.7": {"ID": "def456", "Addr": "10.240.0.7", "InUse": true}
+            }
+          }
+        }
+      }
+    },
+    "TimeStamp": "2023-06-15T12:00:00Z"
+  }
+}`
+
+const testAzureCNINetworkState = `{
+  "Network": {
+    "ExternalInterfaces": {
+      "eth0": {
+        "Name": "eth0",
+        "Networks": {
+          "azure": {
+            "Id": "azure",
+            "Endpoints": {
+              "abc123-eth0": {
+                "Id": "abc123-eth0",
+                "IPAddresses": [{"IP": "10.240.0.6", "Mask": "//8AAA=="}],
+                "ContainerID": "abc123",
+                "PODName": "coredns-1",
+                "PODNameSpace": "kube-system"
+              },
+              "def456-eth0": {
+                "Id": "def456-eth0",
+                "IPAddresses": [{"IP": "10.240.0.7", "Mask": "//8AAA=="}],
+                "ContainerID": "def456",
+                "PODName": "web-1",
+                "PODNameSpace": "app"
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+func TestAzureCNICollectorGetName(t *testing.T) {
+	const expectedName = "azurecni"
+
+	c := NewAzureCNICollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestAzureCNICollectorCheckSupported(t *testing.T) {
+	filePaths := &utils.KnownFilePaths{
+		AzureCNIState:     "/var/run/azure-vnet.json",
+		AzureCNIIPAMState: "/var/run/azure-vnet-ipam.json",
+	}
+
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "kubenet or cilium",
+			files:   map[string]string{},
+			wantErr: true,
+		},
+		{
+			name:    "azure cni",
+			files:   map[string]string{filePaths.AzureCNIState: "{}", filePaths.AzureCNIIPAMState: "{}"},
+			wantErr: false,
+		},
+		{
+			name:    "azure cni with dynamic ip allocation",
+			files:   map[string]string{filePaths.AzureCNIState: "{}"},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewAzureCNICollector(filePaths, test.NewFakeFileSystem(tt.files))
+			err := c.CheckSupported()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckSupported() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAzureCNICollectorCollect(t *testing.T) {
+	filePaths := &utils.KnownFilePaths{
+		AzureCNIState:     "/var/run/azure-vnet.json",
+		AzureCNIIPAMState: "/var/run/azure-vnet-ipam.json",
+		AzureCNILog:       "/var/log/azure-vnet.log",
+	}
+
+	fs := test.NewFakeFileSystem(map[string]string{
+		filePaths.AzureCNIState:     testAzureCNINetworkState,
+		filePaths.AzureCNIIPAMState: testAzureCNIIPAMState,
+		filePaths.AzureCNILog:       "2023/06/15 12:00:00 [cni-ipam] Failed to allocate address: No available addresses\n",
+	})
+
+	c := NewAzureCNICollector(filePaths, fs)
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	data := c.GetData()
+
+	testDataValue(t, data["azure-cni-state"], func(raw string) {
+		var state AzureCNIState
+		if err := json.Unmarshal([]byte(raw), &state); err != nil {
+			t.Fatalf("unmarshal GetData(): %v", err)
+		}
+
+		expectedPools := []AzureCNIPool{
+			{Subnet: "10.240.0.0/16", Interface: "eth0", Total: 3, Allocated: 2, Available: 1},
+		}
+		if !reflect.DeepEqual(state.Pools, expectedPools) {
+			t.Errorf("expected pools %+v, found %+v", expectedPools, state.Pools)
+		}
+		if state.Allocated != 2 || state.Available != 1 || state.Exhausted {
+			t.Errorf("unexpected totals: %+v", state)
+		}
+
+		expectedEndpoints := []AzureCNIEndpoint{
+			{PodNamespace: "app", PodName: "web-1", ContainerID: "def456", IPAddresses: []string{"10.240.0.7"}},
+			{PodNamespace: "kube-system", PodName: "coredns-1", ContainerID: "abc123", IPAddresses: []string{"10.240.0.6"}},
+		}
+		if !reflect.DeepEqual(state.Endpoints, expectedEndpoints) {
+			t.Errorf("expected endpoints %+v, found %+v", expectedEndpoints, state.Endpoints)
+		}
+		if len(state.UnavailableDataSources) != 0 {
+			t.Errorf("unexpected unavailable data sources %v", state.UnavailableDataSources)
+		}
+	})
+
+	testDataValue(t, data["azure-cni-log"], func(raw string) {
+		if raw != "2023/06/15 12:00:00 [cni-ipam] Failed to allocate address: No available addresses\n" {
+			t.Errorf("unexpected log content %q", raw)
+		}
+	})
+}
+
+func TestAzureCNICollectorCollectExhausted(t *testing.T) {
+	filePaths := &utils.KnownFilePaths{
+		AzureCNIState:     "/var/run/azure-vnet.json",
+		AzureCNIIPAMState: "/var/run/azure-vnet-ipam.json",
+		AzureCNILog:       "/var/log/azure-vnet.log",
+	}
+
+	fs := test.NewFakeFileSystem(map[string]string{
+		filePaths.AzureCNIIPAMState: `{"IPAM":{"AddressSpaces":{"local":{"Pools":{"10.240.0.0/16":{"IfName":"eth0","Addresses":{"10.240.0.6":{"InUse":true}}}}}}}}`,
+	})
+
+	c := NewAzureCNICollector(filePaths, fs)
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	data := c.GetData()
+	if _, ok := data["azure-cni-log"]; ok {
+		t.Errorf("expected no log when the log file is absent")
+	}
+
+	testDataValue(t, data["azure-cni-state"], func(raw string) {
+		var state AzureCNIState
+		if err := json.Unmarshal([]byte(raw), &state); err != nil {
+			t.Fatalf("unmarshal GetData(): %v", err)
+		}
+		if !state.Exhausted || state.Available != 0 {
+			t.Errorf("expected exhausted pool, found %+v", state)
+		}
+		if len(state.UnavailableDataSources) != 1 {
+			t.Errorf("expected the missing network state to be reported, found %v", state.UnavailableDataSources)
+		}
+	})
+}
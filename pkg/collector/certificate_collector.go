@@ -0,0 +1,248 @@
+package collector
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Certificates expiring within this period are flagged.
+const certificateExpiryWarningPeriod = 30 * 24 * time.Hour
+
+type CertificateReport struct {
+	Certificates []CertificateInfo `json:"certificates"`
+	Errors       []string          `json:"errors"`
+}
+
+type CertificateInfo struct {
+	Source          string    `json:"source"`
+	Location        string    `json:"location"`
+	Subject         string    `json:"subject"`
+	Issuer          string    `json:"issuer"`
+	NotBefore       time.Time `json:"notBefore"`
+	NotAfter        time.Time `json:"notAfter"`
+	DaysUntilExpiry int       `json:"daysUntilExpiry"`
+	ExpiringSoon    bool      `json:"expiringSoon"`
+	Expired         bool      `json:"expired"`
+}
+
+// CertificateCollector defines a Certificate Collector struct
+type CertificateCollector struct {
+	data              map[string]string
+	osIdentifier      utils.OSIdentifier
+	filePaths         *utils.KnownFilePaths
+	fileSystem        interfaces.FileSystemAccessor
+	clientset         kubernetes.Interface
+	fetchCertificates utils.ServingCertificateFetcher
+	now               func() time.Time
+}
+
+// NewCertificateCollector is a constructor
+func NewCertificateCollector(osIdentifier utils.OSIdentifier, filePaths *utils.KnownFilePaths, fileSystem interfaces.FileSystemAccessor, clientset kubernetes.Interface, fetchCertificates utils.ServingCertificateFetcher) *CertificateCollector {
+	return &CertificateCollector{
+		data:              make(map[string]string),
+		osIdentifier:      osIdentifier,
+		filePaths:         filePaths,
+		fileSystem:        fileSystem,
+		clientset:         clientset,
+		fetchCertificates: fetchCertificates,
+		now:               time.Now,
+	}
+}
+
+func (collector *CertificateCollector) GetName() string {
+	return "certificates"
+}
+
+func (collector *CertificateCollector) CheckSupported() error {
+	// Webhook certificates can be checked from any node. Certificates on disk are only read on Linux (see Collect).
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *CertificateCollector) Collect() error {
+	report := CertificateReport{
+		Certificates: []CertificateInfo{},
+		Errors:       []string{},
+	}
+
+	// The certificate locations on disk are specific to Linux nodes.
+	if collector.osIdentifier == utils.Linux {
+		for _, directory := range []string{collector.filePaths.KubeletCertificates, collector.filePaths.KubernetesCertificates} {
+			collector.addFileCertificates(&report, directory)
+		}
+	}
+
+	if err := collector.addWebhookCertificates(&report); err != nil {
+		return err
+	}
+
+	sort.SliceStable(report.Certificates, func(i, j int) bool {
+		a, b := report.Certificates[i], report.Certificates[j]
+		return a.Source+a.Location < b.Source+b.Location
+	})
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("marshall certificates to json: %w", err)
+	}
+
+	collector.data["certificates"] = string(data)
+
+	return nil
+}
+
+func (collector *CertificateCollector) addFileCertificates(report *CertificateReport, directory string) {
+	files, err := collector.fileSystem.ListFiles(directory)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("unable to list certificates in %s: %v", directory, err))
+		return
+	}
+
+	for _, file := range files {
+		if !strings.HasSuffix(file, ".crt") && !strings.HasSuffix(file, ".pem") {
+			continue
+		}
+
+		content, err := utils.GetContent(func() (io.ReadCloser, error) { return collector.fileSystem.GetFileReader(file) })
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("unable to read %s: %v", file, err))
+			continue
+		}
+
+		// Files such as kubelet-client-current.pem contain the private key as well, which is skipped.
+		certificates, err := utils.ParsePEMCertificates([]byte(content))
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("unable to parse %s: %v", file, err))
+			continue
+		}
+
+		collector.addCertificates(report, "file", file, certificates)
+	}
+}
+
+func (collector *CertificateCollector) addWebhookCertificates(report *CertificateReport) error {
+	ctx := context.Background()
+
+	validating, err := collector.clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list validating webhook configurations: %w", err)
+	}
+
+	mutating, err := collector.clientset.AdmissionregistrationV1().MutatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list mutating webhook configurations: %w", err)
+	}
+
+	// Webhooks often share a serving endpoint, so each address is only checked once.
+	locationsByAddress := map[string][]string{}
+	addresses := []string{}
+	addWebhook := func(kind, configName, webhookName string, clientConfig *admissionregistrationv1.WebhookClientConfig) {
+		address, err := getWebhookAddress(clientConfig)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s/%s/%s: %v", kind, configName, webhookName, err))
+			return
+		}
+
+		if _, ok := locationsByAddress[address]; !ok {
+			addresses = append(addresses, address)
+		}
+		locationsByAddress[address] = append(locationsByAddress[address], fmt.Sprintf("%s/%s/%s", kind, configName, webhookName))
+	}
+
+	for _, config := range validating.Items {
+		for i := range config.Webhooks {
+			addWebhook("validatingwebhookconfiguration", config.Name, config.Webhooks[i].Name, &config.Webhooks[i].ClientConfig)
+		}
+	}
+	for _, config := range mutating.Items {
+		for i := range config.Webhooks {
+			addWebhook("mutatingwebhookconfiguration", config.Name, config.Webhooks[i].Name, &config.Webhooks[i].ClientConfig)
+		}
+	}
+
+	for _, address := range addresses {
+		location := fmt.Sprintf("%s (%s)", strings.Join(locationsByAddress[address], ", "), address)
+
+		certificates, err := collector.fetchCertificates(address)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", location, err))
+			continue
+		}
+
+		// Only the leaf certificate is served by the webhook itself.
+		if len(certificates) > 0 {
+			collector.addCertificates(report, "webhook", location, certificates[:1])
+		}
+	}
+
+	return nil
+}
+
+func (collector *CertificateCollector) addCertificates(report *CertificateReport, source, location string, certificates []*x509.Certificate) {
+	now := collector.now()
+	for i, certificate := range certificates {
+		certificateLocation := location
+		if i > 0 {
+			certificateLocation = fmt.Sprintf("%s#%d", location, i)
+		}
+
+		untilExpiry := certificate.NotAfter.Sub(now)
+		report.Certificates = append(report.Certificates, CertificateInfo{
+			Source:          source,
+			Location:        certificateLocation,
+			Subject:         certificate.Subject.String(),
+			Issuer:          certificate.Issuer.String(),
+			NotBefore:       certificate.NotBefore,
+			NotAfter:        certificate.NotAfter,
+			DaysUntilExpiry: int(untilExpiry.Hours() / 24),
+			ExpiringSoon:    untilExpiry < certificateExpiryWarningPeriod,
+			Expired:         untilExpiry <= 0,
+		})
+	}
+}
+
+// getWebhookAddress returns the host:port address serving a webhook, either an in-cluster service or a URL.
+func getWebhookAddress(clientConfig *admissionregistrationv1.WebhookClientConfig) (string, error) {
+	if service := clientConfig.Service; service != nil {
+		port := int32(443)
+		if service.Port != nil {
+			port = *service.Port
+		}
+		return net.JoinHostPort(fmt.Sprintf("%s.%s.svc", service.Name, service.Namespace), strconv.Itoa(int(port))), nil
+	}
+
+	if clientConfig.URL == nil {
+		return "", fmt.Errorf("no service or URL configured")
+	}
+
+	webhookURL, err := url.Parse(*clientConfig.URL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %s: %w", *clientConfig.URL, err)
+	}
+
+	port := webhookURL.Port()
+	if port == "" {
+		port = "443"
+	}
+
+	return net.JoinHostPort(webhookURL.Hostname(), port), nil
+}
+
+func (collector *CertificateCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
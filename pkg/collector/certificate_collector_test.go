@@ -0,0 +1,234 @@
+package collector
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestCertificate(t *testing.T, commonName string, notAfter time.Time) (*x509.Certificate, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("error generating key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("error creating certificate: %v", err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("error parsing certificate: %v", err)
+	}
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("error marshalling key: %v", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	return certificate, string(certPEM) + string(keyPEM)
+}
+
+func TestCertificateCollectorGetName(t *testing.T) {
+	const expectedName = "certificates"
+
+	c := NewCertificateCollector("", nil, nil, nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestCertificateCollectorCollect(t *testing.T) {
+	now := time.Date(2023, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	_, clientPEM := newTestCertificate(t, "system:node:node-1", now.Add(200*24*time.Hour))
+	_, servingPEM := newTestCertificate(t, "node-1", now.Add(10*24*time.Hour))
+	webhookCert, _ := newTestCertificate(t, "webhook.app.svc", now.Add(-24*time.Hour))
+
+	filePaths := &utils.KnownFilePaths{
+		KubeletCertificates:    "/var/lib/kubelet/pki",
+		KubernetesCertificates: "/etc/kubernetes/certs",
+	}
+
+	fs := test.NewFakeFileSystem(map[string]string{
+		"/var/lib/kubelet/pki/kubelet-client-current.pem": clientPEM,
+		"/var/lib/kubelet/pki/kubelet.crt":                servingPEM,
+		"/var/lib/kubelet/pki/kubelet.key":                "not a certificate",
+		"/etc/kubernetes/certs/broken.crt":                "-----BEGIN CERTIFICATE-----\nbm90IGEgY2VydA==\n-----END CERTIFICATE-----\n",
+	})
+
+	port := int32(8443)
+	webhookURL := "https://external.example.com/validate"
+	clientset := fake.NewSimpleClientset(
+		&admissionregistrationv1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy"},
+			Webhooks: []admissionregistrationv1.ValidatingWebhook{
+				{Name: "validate.policy.io", ClientConfig: admissionregistrationv1.WebhookClientConfig{Service: &admissionregistrationv1.ServiceReference{Namespace: "app", Name: "webhook", Port: &port}}},
+				{Name: "external.policy.io", ClientConfig: admissionregistrationv1.WebhookClientConfig{URL: &webhookURL}},
+			},
+		},
+		&admissionregistrationv1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "injector"},
+			Webhooks: []admissionregistrationv1.MutatingWebhook{
+				{Name: "mutate.policy.io", ClientConfig: admissionregistrationv1.WebhookClientConfig{Service: &admissionregistrationv1.ServiceReference{Namespace: "app", Name: "webhook", Port: &port}}},
+			},
+		},
+	)
+
+	fetchedAddresses := []string{}
+	fetchCertificates := func(address string) ([]*x509.Certificate, error) {
+		fetchedAddresses = append(fetchedAddresses, address)
+		if address == "webhook.app.svc:8443" {
+			return []*x509.Certificate{webhookCert}, nil
+		}
+		return nil, errors.New("connection refused")
+	}
+
+	tests := []struct {
+		name              string
+		osIdentifier      utils.OSIdentifier
+		expectedLocations map[string]bool
+		expectedErrors    int
+	}{
+		{
+			name:         "linux",
+			osIdentifier: utils.Linux,
+			expectedLocations: map[string]bool{
+				"/var/lib/kubelet/pki/kubelet-client-current.pem": false,
+				"/var/lib/kubelet/pki/kubelet.crt":                true,
+				"validatingwebhookconfiguration/policy/validate.policy.io, mutatingwebhookconfiguration/injector/mutate.policy.io (webhook.app.svc:8443)": true,
+			},
+			expectedErrors: 2,
+		},
+		{
+			name:         "windows",
+			osIdentifier: utils.Windows,
+			expectedLocations: map[string]bool{
+				"validatingwebhookconfiguration/policy/validate.policy.io, mutatingwebhookconfiguration/injector/mutate.policy.io (webhook.app.svc:8443)": true,
+			},
+			expectedErrors: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetchedAddresses = []string{}
+
+			c := NewCertificateCollector(tt.osIdentifier, filePaths, fs, clientset, fetchCertificates)
+			c.now = func() time.Time { return now }
+
+			if err := c.CheckSupported(); err != nil {
+				t.Fatalf("CheckSupported() error = %v", err)
+			}
+			if err := c.Collect(); err != nil {
+				t.Fatalf("Collect() error = %v", err)
+			}
+
+			if !equalStringSlices(fetchedAddresses, []string{"webhook.app.svc:8443", "external.example.com:443"}) {
+				t.Errorf("unexpected fetched addresses %v", fetchedAddresses)
+			}
+
+			testDataValue(t, c.GetData()["certificates"], func(raw string) {
+				var report CertificateReport
+				if err := json.Unmarshal([]byte(raw), &report); err != nil {
+					t.Fatalf("unmarshal GetData(): %v", err)
+				}
+
+				if len(report.Certificates) != len(tt.expectedLocations) {
+					t.Errorf("expected %d certificates, found %+v", len(tt.expectedLocations), report.Certificates)
+				}
+				for _, certificate := range report.Certificates {
+					expiringSoon, ok := tt.expectedLocations[certificate.Location]
+					if !ok {
+						t.Errorf("unexpected certificate location %s", certificate.Location)
+						continue
+					}
+					if certificate.ExpiringSoon != expiringSoon {
+						t.Errorf("expected expiringSoon=%t for %s, found %+v", expiringSoon, certificate.Location, certificate)
+					}
+				}
+
+				if len(report.Errors) != tt.expectedErrors {
+					t.Errorf("expected %d errors, found %v", tt.expectedErrors, report.Errors)
+				}
+				for _, err := range report.Errors {
+					if !strings.Contains(err, "broken.crt") && !strings.Contains(err, "external.example.com:443") {
+						t.Errorf("unexpected error %s", err)
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestCertificateCollectorExpiry(t *testing.T) {
+	now := time.Date(2023, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		notAfter         time.Time
+		wantDays         int
+		wantExpiringSoon bool
+		wantExpired      bool
+	}{
+		{
+			name:     "valid",
+			notAfter: now.Add(90 * 24 * time.Hour),
+			wantDays: 90,
+		},
+		{
+			name:             "expiring within 30 days",
+			notAfter:         now.Add(29*24*time.Hour + time.Hour),
+			wantDays:         29,
+			wantExpiringSoon: true,
+		},
+		{
+			name:             "expired",
+			notAfter:         now.Add(-48 * time.Hour),
+			wantDays:         -2,
+			wantExpiringSoon: true,
+			wantExpired:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			certificate, _ := newTestCertificate(t, "test", tt.notAfter)
+
+			c := NewCertificateCollector(utils.Linux, nil, nil, nil, nil)
+			c.now = func() time.Time { return now }
+
+			report := &CertificateReport{}
+			c.addCertificates(report, "file", "/test.crt", []*x509.Certificate{certificate})
+
+			info := report.Certificates[0]
+			if info.DaysUntilExpiry != tt.wantDays || info.ExpiringSoon != tt.wantExpiringSoon || info.Expired != tt.wantExpired {
+				t.Errorf("unexpected expiry: %+v", info)
+			}
+		})
+	}
+}
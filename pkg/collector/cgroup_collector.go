@@ -0,0 +1,251 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Matches pod cgroup directory names for both cgroup drivers, capturing the pod UID:
+// - systemd: kubepods-burstable-pod1234abcd_5678_....slice (UID dashes are replaced by underscores)
+// - cgroupfs: pod1234abcd-5678-...
+var podCgroupRegex = regexp.MustCompile(`pod([0-9a-fA-F]{8}[-_][0-9a-fA-F]{4}[-_][0-9a-fA-F]{4}[-_][0-9a-fA-F]{4}[-_][0-9a-fA-F]{12})(\.slice)?$`)
+
+// The top-level kubepods cgroup for each cgroup driver.
+var kubepodsCgroups = map[string]string{
+	"systemd":  "kubepods.slice",
+	"cgroupfs": "kubepods",
+}
+
+type CgroupStats struct {
+	Version string           `json:"version"`
+	Driver  string           `json:"driver"`
+	Pods    []PodCgroupStats `json:"pods"`
+}
+
+type PodCgroupStats struct {
+	PodUID        string          `json:"podUID"`
+	QoSClass      string          `json:"qosClass"`
+	CgroupPath    string          `json:"cgroupPath"`
+	CPU           *CgroupCPUStats `json:"cpu,omitempty"`
+	MemoryCurrent *int64          `json:"memoryCurrent,omitempty"`
+	MemoryMax     string          `json:"memoryMax,omitempty"`
+	PidsCurrent   *int64          `json:"pidsCurrent,omitempty"`
+}
+
+type CgroupCPUStats struct {
+	Periods          int64 `json:"periods"`
+	ThrottledPeriods int64 `json:"throttledPeriods"`
+	ThrottledUsec    int64 `json:"throttledUsec"`
+}
+
+// cgroupFileReader applies the content of a cgroup file to the stats for a pod.
+type cgroupFileReader func(stats *PodCgroupStats, content string)
+
+// CgroupCollector defines a Cgroup Collector struct
+type CgroupCollector struct {
+	data         map[string]string
+	osIdentifier utils.OSIdentifier
+	filePaths    *utils.KnownFilePaths
+	fileSystem   interfaces.FileSystemAccessor
+}
+
+// NewCgroupCollector is a constructor
+func NewCgroupCollector(osIdentifier utils.OSIdentifier, filePaths *utils.KnownFilePaths, fileSystem interfaces.FileSystemAccessor) *CgroupCollector {
+	return &CgroupCollector{
+		data:         make(map[string]string),
+		osIdentifier: osIdentifier,
+		filePaths:    filePaths,
+		fileSystem:   fileSystem,
+	}
+}
+
+func (collector *CgroupCollector) GetName() string {
+	return "cgroup"
+}
+
+func (collector *CgroupCollector) CheckSupported() error {
+	// cgroups are a Linux kernel feature.
+	if collector.osIdentifier != utils.Linux {
+		return fmt.Errorf("unsupported OS: %s", collector.osIdentifier)
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *CgroupCollector) Collect() error {
+	root := collector.filePaths.Cgroup
+
+	// The unified (v2) hierarchy has a cgroup.controllers file at its root.
+	isV2, err := collector.fileSystem.FileExists(path.Join(root, "cgroup.controllers"))
+	if err != nil {
+		return fmt.Errorf("error detecting cgroup version: %w", err)
+	}
+
+	stats := &CgroupStats{Version: "v1"}
+	pods := map[string]*PodCgroupStats{}
+	if isV2 {
+		stats.Version = "v2"
+		stats.Driver = collector.readPodCgroups(root, pods, map[string]cgroupFileReader{
+			"cpu.stat":       readCPUStat("throttled_usec", 1),
+			"memory.current": readCgroupInt(func(s *PodCgroupStats, v *int64) { s.MemoryCurrent = v }),
+			"memory.max":     func(s *PodCgroupStats, content string) { s.MemoryMax = strings.TrimSpace(content) },
+			"pids.current":   readCgroupInt(func(s *PodCgroupStats, v *int64) { s.PidsCurrent = v }),
+		})
+	} else {
+		// In v1, each controller has its own hierarchy. The cpu controller is usually mounted along with cpuacct.
+		for _, controller := range []string{"cpu,cpuacct", "cpu"} {
+			if driver := collector.readPodCgroups(path.Join(root, controller), pods, map[string]cgroupFileReader{
+				"cpu.stat": readCPUStat("throttled_time", 1000),
+			}); driver != "" {
+				stats.Driver = driver
+				break
+			}
+		}
+
+		if driver := collector.readPodCgroups(path.Join(root, "memory"), pods, map[string]cgroupFileReader{
+			"memory.usage_in_bytes": readCgroupInt(func(s *PodCgroupStats, v *int64) { s.MemoryCurrent = v }),
+			"memory.limit_in_bytes": func(s *PodCgroupStats, content string) { s.MemoryMax = strings.TrimSpace(content) },
+		}); driver != "" {
+			stats.Driver = driver
+		}
+
+		if driver := collector.readPodCgroups(path.Join(root, "pids"), pods, map[string]cgroupFileReader{
+			"pids.current": readCgroupInt(func(s *PodCgroupStats, v *int64) { s.PidsCurrent = v }),
+		}); driver != "" {
+			stats.Driver = driver
+		}
+	}
+
+	if stats.Driver == "" {
+		return fmt.Errorf("no kubepods cgroup found in %s", root)
+	}
+
+	stats.Pods = []PodCgroupStats{}
+	for _, pod := range pods {
+		stats.Pods = append(stats.Pods, *pod)
+	}
+
+	sort.Slice(stats.Pods, func(i, j int) bool {
+		return stats.Pods[i].PodUID < stats.Pods[j].PodUID
+	})
+
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return fmt.Errorf("marshall cgroup stats to json: %w", err)
+	}
+
+	collector.data["cgroup-stats"] = string(data)
+
+	return nil
+}
+
+// readPodCgroups walks the kubepods hierarchy under the specified root, applying the readers to the matching files in
+// each pod cgroup. It returns the cgroup driver whose layout was found, or an empty string if there was none.
+func (collector *CgroupCollector) readPodCgroups(root string, pods map[string]*PodCgroupStats, readers map[string]cgroupFileReader) string {
+	for _, driver := range []string{"systemd", "cgroupfs"} {
+		files, err := collector.fileSystem.ListFiles(path.Join(root, kubepodsCgroups[driver]))
+		if err != nil || len(files) == 0 {
+			continue
+		}
+
+		for _, file := range files {
+			reader, ok := readers[path.Base(file)]
+			if !ok {
+				continue
+			}
+
+			// Only files directly in the pod cgroup are relevant, not those of its containers.
+			dir := path.Dir(file)
+			matches := podCgroupRegex.FindStringSubmatch(path.Base(dir))
+			if matches == nil {
+				continue
+			}
+
+			content, err := utils.GetContent(func() (io.ReadCloser, error) { return collector.fileSystem.GetFileReader(file) })
+			if err != nil {
+				continue
+			}
+
+			uid := strings.ReplaceAll(matches[1], "_", "-")
+			pod, ok := pods[uid]
+			if !ok {
+				pod = &PodCgroupStats{
+					PodUID:     uid,
+					QoSClass:   getCgroupQOSClass(dir),
+					CgroupPath: strings.TrimPrefix(dir, root),
+				}
+				pods[uid] = pod
+			}
+
+			reader(pod, content)
+		}
+
+		return driver
+	}
+
+	return ""
+}
+
+func getCgroupQOSClass(cgroupPath string) string {
+	switch {
+	case strings.Contains(cgroupPath, "besteffort"):
+		return string(corev1.PodQOSBestEffort)
+	case strings.Contains(cgroupPath, "burstable"):
+		return string(corev1.PodQOSBurstable)
+	default:
+		return string(corev1.PodQOSGuaranteed)
+	}
+}
+
+// readCPUStat reads the throttling counters from cpu.stat. The throttled time is named and scaled differently
+// between cgroup versions (microseconds in v2, nanoseconds in v1).
+func readCPUStat(throttledTimeKey string, throttledTimeDivisor int64) cgroupFileReader {
+	return func(stats *PodCgroupStats, content string) {
+		cpu := &CgroupCPUStats{}
+		for _, line := range strings.Split(content, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) != 2 {
+				continue
+			}
+
+			value, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				continue
+			}
+
+			switch fields[0] {
+			case "nr_periods":
+				cpu.Periods = value
+			case "nr_throttled":
+				cpu.ThrottledPeriods = value
+			case throttledTimeKey:
+				cpu.ThrottledUsec = value / throttledTimeDivisor
+			}
+		}
+		stats.CPU = cpu
+	}
+}
+
+func readCgroupInt(set func(stats *PodCgroupStats, value *int64)) cgroupFileReader {
+	return func(stats *PodCgroupStats, content string) {
+		value, err := strconv.ParseInt(strings.TrimSpace(content), 10, 64)
+		if err == nil {
+			set(stats, &value)
+		}
+	}
+}
+
+func (collector *CgroupCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
@@ -0,0 +1,160 @@
+package collector
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+func TestCgroupCollectorGetName(t *testing.T) {
+	const expectedName = "cgroup"
+
+	c := NewCgroupCollector("", nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestCgroupCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		osIdentifier utils.OSIdentifier
+		wantErr      bool
+	}{
+		{
+			osIdentifier: utils.Windows,
+			wantErr:      true,
+		},
+		{
+			osIdentifier: utils.Linux,
+			wantErr:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		c := NewCgroupCollector(tt.osIdentifier, nil, nil)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("CheckSupported() error = %v, wantErr %v", err, tt.wantErr)
+		}
+	}
+}
+
+func TestCgroupCollectorCollect(t *testing.T) {
+	const firstPodUID = "1b4e28ba-2fa1-11d2-883f-0016d3cca427"
+	const secondPodUID = "6fa459ea-ee8a-3ca4-894e-db77e160355e"
+
+	int64Ptr := func(v int64) *int64 { return &v }
+
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    *CgroupStats
+		wantErr bool
+	}{
+		{
+			name: "v2 with systemd driver",
+			files: map[string]string{
+				"/cgroup/cgroup.controllers":                               "cpuset cpu io memory pids",
+				"/cgroup/kubepods.slice/cpu.stat":                          "nr_periods 1\nnr_throttled 1\nthrottled_usec 1\n",
+				"/cgroup/kubepods.slice/kubepods-burstable.slice/cpu.stat": "nr_periods 2\nnr_throttled 2\nthrottled_usec 2\n",
+				"/cgroup/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod1b4e28ba_2fa1_11d2_883f_0016d3cca427.slice/cpu.stat":       "usage_usec 1000\nnr_periods 500\nnr_throttled 20\nthrottled_usec 123456\n",
+				"/cgroup/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod1b4e28ba_2fa1_11d2_883f_0016d3cca427.slice/memory.current": "104857600\n",
+				"/cgroup/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod1b4e28ba_2fa1_11d2_883f_0016d3cca427.slice/memory.max":     "max\n",
+				"/cgroup/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod1b4e28ba_2fa1_11d2_883f_0016d3cca427.slice/pids.current":   "12\n",
+				// Container cgroups are ignored.
+				"/cgroup/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod1b4e28ba_2fa1_11d2_883f_0016d3cca427.slice/cri-containerd-abc.scope/cpu.stat": "nr_periods 9\nnr_throttled 9\nthrottled_usec 9\n",
+				"/cgroup/kubepods.slice/kubepods-pod6fa459ea_ee8a_3ca4_894e_db77e160355e.slice/memory.current":                                                       "52428800\n",
+				"/cgroup/kubepods.slice/kubepods-pod6fa459ea_ee8a_3ca4_894e_db77e160355e.slice/memory.max":                                                           "268435456\n",
+			},
+			want: &CgroupStats{
+				Version: "v2",
+				Driver:  "systemd",
+				Pods: []PodCgroupStats{
+					{
+						PodUID:        firstPodUID,
+						QoSClass:      "Burstable",
+						CgroupPath:    "/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod1b4e28ba_2fa1_11d2_883f_0016d3cca427.slice",
+						CPU:           &CgroupCPUStats{Periods: 500, ThrottledPeriods: 20, ThrottledUsec: 123456},
+						MemoryCurrent: int64Ptr(104857600),
+						MemoryMax:     "max",
+						PidsCurrent:   int64Ptr(12),
+					},
+					{
+						PodUID:        secondPodUID,
+						QoSClass:      "Guaranteed",
+						CgroupPath:    "/kubepods.slice/kubepods-pod6fa459ea_ee8a_3ca4_894e_db77e160355e.slice",
+						MemoryCurrent: int64Ptr(52428800),
+						MemoryMax:     "268435456",
+					},
+				},
+			},
+		},
+		{
+			name: "v1 with cgroupfs driver",
+			files: map[string]string{
+				"/cgroup/cpu,cpuacct/kubepods/besteffort/pod1b4e28ba-2fa1-11d2-883f-0016d3cca427/cpu.stat":               "nr_periods 100\nnr_throttled 10\nthrottled_time 5000000\n",
+				"/cgroup/memory/kubepods/besteffort/pod1b4e28ba-2fa1-11d2-883f-0016d3cca427/memory.usage_in_bytes":       "4096\n",
+				"/cgroup/memory/kubepods/besteffort/pod1b4e28ba-2fa1-11d2-883f-0016d3cca427/memory.limit_in_bytes":       "9223372036854771712\n",
+				"/cgroup/pids/kubepods/besteffort/pod1b4e28ba-2fa1-11d2-883f-0016d3cca427/pids.current":                  "3\n",
+				"/cgroup/pids/kubepods/besteffort/pod1b4e28ba-2fa1-11d2-883f-0016d3cca427/0123456789abcdef/pids.current": "1\n",
+			},
+			want: &CgroupStats{
+				Version: "v1",
+				Driver:  "cgroupfs",
+				Pods: []PodCgroupStats{
+					{
+						PodUID:        firstPodUID,
+						QoSClass:      "BestEffort",
+						CgroupPath:    "/kubepods/besteffort/pod1b4e28ba-2fa1-11d2-883f-0016d3cca427",
+						CPU:           &CgroupCPUStats{Periods: 100, ThrottledPeriods: 10, ThrottledUsec: 5000},
+						MemoryCurrent: int64Ptr(4096),
+						MemoryMax:     "9223372036854771712",
+						PidsCurrent:   int64Ptr(3),
+					},
+				},
+			},
+		},
+		{
+			name: "no kubepods cgroup",
+			files: map[string]string{
+				"/cgroup/cgroup.controllers":          "cpuset cpu io memory pids",
+				"/cgroup/system.slice/memory.current": "1024\n",
+			},
+			wantErr: true,
+		},
+	}
+
+	filePaths := &utils.KnownFilePaths{Cgroup: "/cgroup"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCgroupCollector(utils.Linux, filePaths, test.NewFakeFileSystem(tt.files))
+			err := c.Collect()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Collect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			data := c.GetData()
+			if len(data) != 1 {
+				t.Fatalf("unexpected data keys: %v", data)
+			}
+
+			testDataValue(t, data["cgroup-stats"], func(raw string) {
+				actual := &CgroupStats{}
+				if err := json.Unmarshal([]byte(raw), actual); err != nil {
+					t.Fatalf("unable to unmarshal cgroup stats: %v", err)
+				}
+				if !reflect.DeepEqual(actual, tt.want) {
+					t.Errorf("unexpected cgroup stats:\nexpected %+v\nfound    %+v", tt.want, actual)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	ciliumName           = "cilium"
+	ciliumPodSelector    = "k8s-app=cilium"
+	ciliumAgentContainer = "cilium-agent"
+	ciliumAgentLogLines  = int64(1000)
+)
+
+// The agent's CLI was renamed to cilium-dbg in Cilium 1.15, and older agents only have cilium.
+var ciliumCLIs = []string{"cilium-dbg", "cilium"}
+
+// ciliumBPFCommands summarize the agent's BPF maps: their sizes and errors, the service load-balancing entries, and
+// the endpoints on the node. The connection tracking and NAT tables are left out, since they can be huge.
+var ciliumBPFCommands = [][]string{
+	{"map", "list"},
+	{"bpf", "lb", "list"},
+	{"bpf", "endpoint", "list"},
+}
+
+// CiliumCollector defines a Cilium Collector struct
+type CiliumCollector struct {
+	data          map[string]string
+	osIdentifier  utils.OSIdentifier
+	clientset     kubernetes.Interface
+	runPodCommand utils.PodCommandRunner
+	runtimeInfo   *utils.RuntimeInfo
+	pod           *corev1.Pod
+}
+
+// NewCiliumCollector is a constructor
+func NewCiliumCollector(osIdentifier utils.OSIdentifier, clientset kubernetes.Interface, runPodCommand utils.PodCommandRunner, runtimeInfo *utils.RuntimeInfo) *CiliumCollector {
+	return &CiliumCollector{
+		data:          make(map[string]string),
+		osIdentifier:  osIdentifier,
+		clientset:     clientset,
+		runPodCommand: runPodCommand,
+		runtimeInfo:   runtimeInfo,
+	}
+}
+
+func (collector *CiliumCollector) GetName() string {
+	return "cilium"
+}
+
+func (collector *CiliumCollector) CheckSupported() error {
+	if collector.osIdentifier != utils.Linux {
+		return fmt.Errorf("unsupported OS: %s", collector.osIdentifier)
+	}
+
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	ctx := context.Background()
+	_, err := collector.clientset.AppsV1().DaemonSets(metav1.NamespaceSystem).Get(ctx, ciliumName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("Cilium is not installed: daemonset %s not found", ciliumName)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to get daemonset %s: %w", ciliumName, err)
+	}
+
+	pods, err := collector.clientset.CoreV1().Pods(metav1.NamespaceSystem).List(ctx, metav1.ListOptions{
+		LabelSelector: ciliumPodSelector,
+		FieldSelector: "spec.nodeName=" + collector.runtimeInfo.HostNodeName,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list %s pods: %w", ciliumName, err)
+	}
+
+	for i := range pods.Items {
+		if pods.Items[i].Spec.NodeName == collector.runtimeInfo.HostNodeName {
+			collector.pod = &pods.Items[i]
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no %s pod found on node %s", ciliumName, collector.runtimeInfo.HostNodeName)
+}
+
+// Collect implements the interface method
+func (collector *CiliumCollector) Collect() error {
+	if collector.pod == nil {
+		if err := collector.CheckSupported(); err != nil {
+			return err
+		}
+	}
+
+	// An agent failing to report its status is itself a useful symptom, so the failure is recorded rather than
+	// failing the collector.
+	status, err := collector.runCilium("status", "--verbose")
+	if err != nil {
+		status = fmt.Sprintf("cilium status --verbose failed: %v\n", err)
+	}
+	collector.data["cilium-status"] = status
+
+	var bpf strings.Builder
+	for _, command := range ciliumBPFCommands {
+		fmt.Fprintf(&bpf, "# cilium %s\n", strings.Join(command, " "))
+		output, err := collector.runCilium(command...)
+		if err != nil {
+			output = fmt.Sprintf("failed: %v\n", err)
+		}
+		bpf.WriteString(output)
+		bpf.WriteString("\n")
+	}
+	collector.data["cilium-bpf"] = bpf.String()
+
+	tailLines := ciliumAgentLogLines
+	podLogOptions := &corev1.PodLogOptions{Container: ciliumAgentContainer, TailLines: &tailLines}
+	logs, err := getPodContainerLogs(collector.pod.Namespace, collector.pod.Name, podLogOptions, collector.clientset)
+	if err != nil {
+		return fmt.Errorf("unable to get %s logs: %w", ciliumAgentContainer, err)
+	}
+	collector.data["cilium-agent-log"] = logs
+
+	endpoints, err := collector.runCilium("endpoint", "list", "-o", "json")
+	if err != nil {
+		return fmt.Errorf("unable to list cilium endpoints: %w", err)
+	}
+	collector.data["cilium-endpoints"] = endpoints
+
+	return nil
+}
+
+// runCilium runs a command of the agent's CLI in the agent container, with whichever name the agent's version has.
+func (collector *CiliumCollector) runCilium(arg ...string) (string, error) {
+	var err error
+	for _, cli := range ciliumCLIs {
+		var output string
+		output, err = collector.runPodCommand(collector.pod.Namespace, collector.pod.Name, ciliumAgentContainer, append([]string{cli}, arg...)...)
+		if err == nil {
+			return output, nil
+		}
+	}
+
+	return "", err
+}
+
+func (collector *CiliumCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
@@ -0,0 +1,154 @@
+package collector
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newCiliumPod(name, nodeName string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: metav1.NamespaceSystem, Labels: map[string]string{"k8s-app": "cilium"}},
+		Spec: corev1.PodSpec{
+			NodeName:   nodeName,
+			Containers: []corev1.Container{{Name: "cilium-agent"}},
+		},
+	}
+}
+
+func TestCiliumCollectorGetName(t *testing.T) {
+	const expectedName = "cilium"
+
+	c := NewCiliumCollector(utils.Linux, nil, nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestCiliumCollectorCheckSupported(t *testing.T) {
+	daemonSet := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "cilium", Namespace: metav1.NamespaceSystem}}
+
+	tests := []struct {
+		name          string
+		osIdentifier  utils.OSIdentifier
+		collectorList []string
+		objects       []runtime.Object
+		wantErr       bool
+	}{
+		{
+			name:          "Windows",
+			osIdentifier:  utils.Windows,
+			collectorList: []string{},
+			objects:       []runtime.Object{daemonSet, newCiliumPod("cilium-1", "node1")},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{"connectedCluster"},
+			objects:       []runtime.Object{daemonSet, newCiliumPod("cilium-1", "node1")},
+			wantErr:       true,
+		},
+		{
+			name:          "not installed",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{},
+			objects:       []runtime.Object{},
+			wantErr:       true,
+		},
+		{
+			name:          "no pod on this node",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{},
+			objects:       []runtime.Object{daemonSet, newCiliumPod("cilium-2", "node2")},
+			wantErr:       true,
+		},
+		{
+			name:          "pod on this node",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{},
+			objects:       []runtime.Object{daemonSet, newCiliumPod("cilium-1", "node1")},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+			HostNodeName:  "node1",
+		}
+		c := NewCiliumCollector(tt.osIdentifier, fake.NewSimpleClientset(tt.objects...), nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCiliumCollectorCollect(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "cilium", Namespace: metav1.NamespaceSystem}},
+		newCiliumPod("cilium-2", "node2"),
+		newCiliumPod("cilium-1", "node1"),
+	)
+
+	// An older agent, which only has the cilium CLI and fails to list its load-balancing entries.
+	execPods := map[string]bool{}
+	runPodCommand := func(namespace, pod, container string, command ...string) (string, error) {
+		execPods[namespace+"/"+pod+"/"+container] = true
+		switch strings.Join(command, " ") {
+		case "cilium status --verbose":
+			return "KVStore: Ok   Disabled\nCluster health: 3/3 reachable\n", nil
+		case "cilium endpoint list -o json":
+			return `[{"id":1234}]`, nil
+		case "cilium map list":
+			return "Name             Num entries   Num errors\ncilium_lb4_services_v2   12   0\n", nil
+		case "cilium bpf endpoint list":
+			return "IP ADDRESS   LOCAL ENDPOINT INFO\n10.0.0.5:0   id=1234\n", nil
+		}
+		return "", errors.New("command terminated with exit code 127")
+	}
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+		HostNodeName:  "node1",
+	}
+
+	c := NewCiliumCollector(utils.Linux, clientset, runPodCommand, runtimeInfo)
+	if err := c.CheckSupported(); err != nil {
+		t.Fatalf("CheckSupported() error = %v", err)
+	}
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(execPods, map[string]bool{"kube-system/cilium-1/cilium-agent": true}) {
+		t.Errorf("unexpected pods exec'd into: %v", execPods)
+	}
+
+	if !strings.Contains(c.data["cilium-status"], "Cluster health: 3/3 reachable") {
+		t.Errorf("unexpected status %s", c.data["cilium-status"])
+	}
+	if c.data["cilium-endpoints"] != `[{"id":1234}]` {
+		t.Errorf("unexpected endpoints %s", c.data["cilium-endpoints"])
+	}
+	if _, ok := c.data["cilium-agent-log"]; !ok {
+		t.Errorf("missing agent log")
+	}
+
+	bpf := c.data["cilium-bpf"]
+	for _, want := range []string{"# cilium map list\n", "cilium_lb4_services_v2", "# cilium bpf lb list\nfailed: command terminated with exit code 127", "id=1234"} {
+		if !strings.Contains(bpf, want) {
+			t.Errorf("expected BPF summary to contain %q, found:\n%s", want, bpf)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+// timedatectl reports whether the system clock is synchronized, as "NTP synchronized" in older versions of systemd.
+var timedatectlSynchronizedRegex = regexp.MustCompile(`(?m)^\s*(?:System clock|NTP) synchronized:\s*(yes|no)\s*$`)
+
+type ClockSkewReport struct {
+	LocalTime   time.Time `json:"localTime"`
+	ThresholdMs int64     `json:"thresholdMs"`
+	// NTPSynchronized is the synchronization status reported by timedatectl, if available.
+	NTPSynchronized *bool             `json:"ntpSynchronized,omitempty"`
+	Skewed          bool              `json:"skewed"`
+	Sources         []ClockSkewSource `json:"sources"`
+}
+
+// ClockSkewSource is the offset of a remote clock from the node's, which is positive if the remote clock is ahead.
+type ClockSkewSource struct {
+	Name          string `json:"name"`
+	OffsetMs      int64  `json:"offsetMs"`
+	UncertaintyMs int64  `json:"uncertaintyMs"`
+	RoundTripMs   int64  `json:"roundTripMs"`
+	Skewed        bool   `json:"skewed"`
+	Error         string `json:"error,omitempty"`
+}
+
+// ClockSkewCollector defines a Clock Skew Collector struct
+type ClockSkewCollector struct {
+	data               map[string]string
+	osIdentifier       utils.OSIdentifier
+	readAPIServerClock utils.ClockReader
+	readNTPClock       utils.ClockReader
+	runCommand         utils.HostCommandRunner
+	runtimeInfo        *utils.RuntimeInfo
+}
+
+// NewClockSkewCollector is a constructor. The NTP server is only read if one is configured.
+func NewClockSkewCollector(osIdentifier utils.OSIdentifier, readAPIServerClock, readNTPClock utils.ClockReader, runCommand utils.HostCommandRunner, runtimeInfo *utils.RuntimeInfo) *ClockSkewCollector {
+	return &ClockSkewCollector{
+		data:               make(map[string]string),
+		osIdentifier:       osIdentifier,
+		readAPIServerClock: readAPIServerClock,
+		readNTPClock:       readNTPClock,
+		runCommand:         runCommand,
+		runtimeInfo:        runtimeInfo,
+	}
+}
+
+func (collector *ClockSkewCollector) GetName() string {
+	return "clockskew"
+}
+
+func (collector *ClockSkewCollector) CheckSupported() error {
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *ClockSkewCollector) Collect() error {
+	report := &ClockSkewReport{
+		LocalTime:   time.Now().UTC(),
+		ThresholdMs: collector.runtimeInfo.ClockSkewThreshold.Milliseconds(),
+		Sources:     []ClockSkewSource{},
+	}
+
+	report.Sources = append(report.Sources, collector.getClockSkew("apiserver", collector.readAPIServerClock))
+	if len(collector.runtimeInfo.ClockSkewNTPServer) > 0 {
+		report.Sources = append(report.Sources, collector.getClockSkew("ntp:"+collector.runtimeInfo.ClockSkewNTPServer, collector.readNTPClock))
+	}
+
+	for _, source := range report.Sources {
+		report.Skewed = report.Skewed || source.Skewed
+	}
+
+	// The node's own view of its synchronization is read with timedatectl and chronyc, which are only on Linux, and
+	// need access to the host which connected clusters don't give.
+	if collector.osIdentifier == utils.Linux && !utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		timedatectl := collector.runHostCommand("timedatectl", "status")
+		collector.data["timedatectl"] = timedatectl
+		if match := timedatectlSynchronizedRegex.FindStringSubmatch(timedatectl); match != nil {
+			synchronized := match[1] == "yes"
+			report.NTPSynchronized = &synchronized
+		}
+
+		collector.data["chronyc-tracking"] = collector.runHostCommand("chronyc", "tracking")
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("marshall clock skew to json: %w", err)
+	}
+
+	collector.data["clock-skew"] = string(data)
+
+	return nil
+}
+
+// getClockSkew reads a remote clock, recording a failure in place of the offset so that the other sources are still
+// reported. The offset is only flagged if it exceeds the threshold by more than the uncertainty of the reading.
+func (collector *ClockSkewCollector) getClockSkew(name string, readClock utils.ClockReader) ClockSkewSource {
+	source := ClockSkewSource{Name: name}
+
+	reading, err := readClock()
+	if err != nil {
+		source.Error = err.Error()
+		return source
+	}
+
+	offset := reading.Offset()
+	source.OffsetMs = offset.Milliseconds()
+	source.UncertaintyMs = reading.Uncertainty.Milliseconds()
+	source.RoundTripMs = reading.RoundTrip.Milliseconds()
+
+	if offset < 0 {
+		offset = -offset
+	}
+	source.Skewed = offset > collector.runtimeInfo.ClockSkewThreshold+reading.Uncertainty
+
+	return source
+}
+
+// runHostCommand returns the output of a command, or the failure in its place, since the tools may not be installed
+// (e.g. chrony is only used by some node images).
+func (collector *ClockSkewCollector) runHostCommand(command string, arg ...string) string {
+	output, err := collector.runCommand(command, arg...)
+	if err != nil {
+		return fmt.Sprintf("%s %s failed: %v\n", command, strings.Join(arg, " "), err)
+	}
+	return output
+}
+
+func (collector *ClockSkewCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
@@ -0,0 +1,142 @@
+package collector
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+func TestClockSkewCollectorGetName(t *testing.T) {
+	const expectedName = "clockskew"
+
+	c := NewClockSkewCollector(utils.Linux, nil, nil, nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestClockSkewCollectorCheckSupported(t *testing.T) {
+	for _, osIdentifier := range []utils.OSIdentifier{utils.Linux, utils.Windows} {
+		c := NewClockSkewCollector(osIdentifier, nil, nil, nil, &utils.RuntimeInfo{})
+		if err := c.CheckSupported(); err != nil {
+			t.Errorf("%s error = %v", osIdentifier, err)
+		}
+	}
+}
+
+func TestClockSkewCollectorCollect(t *testing.T) {
+	newClockReader := func(offset, uncertainty time.Duration) utils.ClockReader {
+		return func() (*utils.ClockReading, error) {
+			now := time.Now()
+			return &utils.ClockReading{RemoteTime: now.Add(offset), LocalTime: now, RoundTrip: 2 * time.Millisecond, Uncertainty: uncertainty}, nil
+		}
+	}
+	failingClockReader := func() (*utils.ClockReading, error) {
+		return nil, errors.New("i/o timeout")
+	}
+
+	runCommand := func(command string, arg ...string) (string, error) {
+		if command == "timedatectl" {
+			return "               Local time: Mon 2024-01-01 00:00:00 UTC\nSystem clock synchronized: no\n              NTP service: active\n", nil
+		}
+		return "", errors.New("exit status 127")
+	}
+
+	tests := []struct {
+		name            string
+		osIdentifier    utils.OSIdentifier
+		apiServerClock  utils.ClockReader
+		ntpClock        utils.ClockReader
+		ntpServer       string
+		wantSources     []ClockSkewSource
+		wantSkewed      bool
+		wantHostOutputs bool
+	}{
+		{
+			name:           "within threshold",
+			osIdentifier:   utils.Linux,
+			apiServerClock: newClockReader(800*time.Millisecond, 500*time.Millisecond),
+			ntpClock:       failingClockReader,
+			wantSources: []ClockSkewSource{
+				{Name: "apiserver", OffsetMs: 800, UncertaintyMs: 500, RoundTripMs: 2},
+			},
+			wantSkewed:      false,
+			wantHostOutputs: true,
+		},
+		{
+			name:           "within uncertainty of threshold",
+			osIdentifier:   utils.Linux,
+			apiServerClock: newClockReader(-1200*time.Millisecond, 500*time.Millisecond),
+			ntpClock:       newClockReader(-1200*time.Millisecond, 0),
+			ntpServer:      "time.windows.com",
+			wantSources: []ClockSkewSource{
+				{Name: "apiserver", OffsetMs: -1200, UncertaintyMs: 500, RoundTripMs: 2},
+				{Name: "ntp:time.windows.com", OffsetMs: -1200, RoundTripMs: 2, Skewed: true},
+			},
+			wantSkewed:      true,
+			wantHostOutputs: true,
+		},
+		{
+			name:           "failed readings",
+			osIdentifier:   utils.Windows,
+			apiServerClock: failingClockReader,
+			ntpClock:       failingClockReader,
+			ntpServer:      "time.windows.com",
+			wantSources: []ClockSkewSource{
+				{Name: "apiserver", Error: "i/o timeout"},
+				{Name: "ntp:time.windows.com", Error: "i/o timeout"},
+			},
+			wantSkewed:      false,
+			wantHostOutputs: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runtimeInfo := &utils.RuntimeInfo{
+				CollectorList:      []string{},
+				ClockSkewThreshold: time.Second,
+				ClockSkewNTPServer: tt.ntpServer,
+			}
+
+			c := NewClockSkewCollector(tt.osIdentifier, tt.apiServerClock, tt.ntpClock, runCommand, runtimeInfo)
+			if err := c.Collect(); err != nil {
+				t.Fatalf("Collect() error = %v", err)
+			}
+
+			var report ClockSkewReport
+			if err := json.Unmarshal([]byte(c.data["clock-skew"]), &report); err != nil {
+				t.Fatalf("unmarshal clock skew: %v", err)
+			}
+
+			if report.ThresholdMs != 1000 || report.Skewed != tt.wantSkewed {
+				t.Errorf("unexpected threshold %d or skewed %t", report.ThresholdMs, report.Skewed)
+			}
+			if len(report.Sources) != len(tt.wantSources) {
+				t.Fatalf("expected sources %+v, found %+v", tt.wantSources, report.Sources)
+			}
+			for i, want := range tt.wantSources {
+				if report.Sources[i] != want {
+					t.Errorf("expected source %+v, found %+v", want, report.Sources[i])
+				}
+			}
+
+			_, hasTimedatectl := c.data["timedatectl"]
+			if hasTimedatectl != tt.wantHostOutputs {
+				t.Errorf("expected timedatectl output: %t, found %t", tt.wantHostOutputs, hasTimedatectl)
+			}
+			if tt.wantHostOutputs {
+				if report.NTPSynchronized == nil || *report.NTPSynchronized {
+					t.Errorf("expected unsynchronized clock to be reported")
+				}
+				if c.data["chronyc-tracking"] != "chronyc tracking failed: exit status 127\n" {
+					t.Errorf("unexpected chronyc output %q", c.data["chronyc-tracking"])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package collector
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	cloudProviderLogLines = int64(5000)
+
+	// The number of most recent matching lines kept as samples for each container, and the length they're cut to.
+	cloudProviderMaxSamples    = 5
+	cloudProviderMaxSampleSize = 500
+)
+
+// cloudProviderSources select the pods in which the Azure cloud provider runs. The out-of-tree provider runs as
+// cloud-controller-manager and cloud-node-manager (whose labels vary between the upstream chart and other
+// deployments), while the in-tree provider runs within kube-controller-manager. Managed clusters run the controller
+// managers on the control plane, where they aren't visible, but cloud-node-manager runs on every node.
+var cloudProviderSources = []struct {
+	component string
+	selector  string
+	nodeLocal bool
+}{
+	{"cloud-controller-manager", "component=cloud-controller-manager", false},
+	{"cloud-controller-manager", "k8s-app=cloud-controller-manager", false},
+	{"cloud-node-manager", "k8s-app=cloud-node-manager", true},
+	{"cloud-node-manager", "k8s-app=cloud-node-manager-windows", true},
+	{"kube-controller-manager", "component=kube-controller-manager", false},
+}
+
+// Signatures of ARM failures in the logs of the cloud provider, whose errors are formatted either by the retry
+// package ("HTTPStatusCode: 429, RawError: ...") or by autorest ("StatusCode=409 -- Original Error: Code=\"...\"").
+var (
+	cloudProviderThrottlingPattern = regexp.MustCompile(`(?i)HTTPStatusCode: 429\b|StatusCode=429\b|TooManyRequests|throttl`)
+	cloudProviderQuotaPattern      = regexp.MustCompile(`(?i)quota`)
+	cloudProviderARMErrorPattern   = regexp.MustCompile(`HTTPStatusCode: [45]\d\d\b|StatusCode=[45]\d\d\b|Code="\w+"|"code":\s*"\w+"`)
+	cloudProviderStatusCodePattern = regexp.MustCompile(`(?:HTTPStatusCode: |StatusCode=)([45]\d\d)\b`)
+	cloudProviderErrorCodePattern  = regexp.MustCompile(`Code="(\w+)"|"code":\s*"(\w+)"`)
+)
+
+type CloudProviderErrors struct {
+	Throttling  int                          `json:"throttling"`
+	Quota       int                          `json:"quota"`
+	ARMErrors   int                          `json:"armErrors"`
+	StatusCodes map[string]int               `json:"statusCodes"`
+	ErrorCodes  map[string]int               `json:"errorCodes"`
+	Containers  []CloudProviderContainerLogs `json:"containers"`
+}
+
+type CloudProviderContainerLogs struct {
+	Component    string         `json:"component"`
+	Pod          string         `json:"pod"`
+	Container    string         `json:"container"`
+	NodeName     string         `json:"nodeName"`
+	LinesScanned int            `json:"linesScanned"`
+	Throttling   int            `json:"throttling"`
+	Quota        int            `json:"quota"`
+	ARMErrors    int            `json:"armErrors"`
+	StatusCodes  map[string]int `json:"statusCodes"`
+	ErrorCodes   map[string]int `json:"errorCodes"`
+	Samples      []string       `json:"samples"`
+}
+
+// CloudProviderCollector defines a Cloud Provider Collector struct
+type CloudProviderCollector struct {
+	data        map[string]string
+	clientset   kubernetes.Interface
+	runtimeInfo *utils.RuntimeInfo
+}
+
+// NewCloudProviderCollector is a constructor
+func NewCloudProviderCollector(clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *CloudProviderCollector {
+	return &CloudProviderCollector{
+		data:        make(map[string]string),
+		clientset:   clientset,
+		runtimeInfo: runtimeInfo,
+	}
+}
+
+func (collector *CloudProviderCollector) GetName() string {
+	return "cloudprovider"
+}
+
+func (collector *CloudProviderCollector) CheckSupported() error {
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *CloudProviderCollector) Collect() error {
+	ctx := context.Background()
+
+	result := &CloudProviderErrors{
+		StatusCodes: map[string]int{},
+		ErrorCodes:  map[string]int{},
+		Containers:  []CloudProviderContainerLogs{},
+	}
+
+	seen := map[string]bool{}
+	for _, source := range cloudProviderSources {
+		listOptions := metav1.ListOptions{LabelSelector: source.selector}
+
+		// Node managers only report on the node they run on.
+		if source.nodeLocal {
+			listOptions.FieldSelector = "spec.nodeName=" + collector.runtimeInfo.HostNodeName
+		}
+
+		podList, err := collector.clientset.CoreV1().Pods(metav1.NamespaceSystem).List(ctx, listOptions)
+		if err != nil {
+			return fmt.Errorf("unable to list %s pods: %w", source.component, err)
+		}
+
+		for _, pod := range podList.Items {
+			if seen[pod.Name] || (source.nodeLocal && pod.Spec.NodeName != collector.runtimeInfo.HostNodeName) {
+				continue
+			}
+			seen[pod.Name] = true
+
+			for _, container := range pod.Spec.Containers {
+				tailLines := cloudProviderLogLines
+				podLogOptions := &corev1.PodLogOptions{Container: container.Name, TailLines: &tailLines}
+				containerLogs, err := getPodContainerLogs(pod.Namespace, pod.Name, podLogOptions, collector.clientset)
+				if err != nil {
+					log.Printf("Unable to get logs for %s/%s container %s: %v", pod.Namespace, pod.Name, container.Name, err)
+					continue
+				}
+
+				logs := summarizeCloudProviderLogs(containerLogs)
+				logs.Component = source.component
+				logs.Pod = pod.Namespace + "/" + pod.Name
+				logs.Container = container.Name
+				logs.NodeName = pod.Spec.NodeName
+
+				result.Throttling += logs.Throttling
+				result.Quota += logs.Quota
+				result.ARMErrors += logs.ARMErrors
+				addCounts(result.StatusCodes, logs.StatusCodes)
+				addCounts(result.ErrorCodes, logs.ErrorCodes)
+				result.Containers = append(result.Containers, logs)
+			}
+		}
+	}
+
+	sort.Slice(result.Containers, func(i, j int) bool {
+		a, b := result.Containers[i], result.Containers[j]
+		if a.Component != b.Component {
+			return a.Component < b.Component
+		}
+		return a.Pod+"/"+a.Container < b.Pod+"/"+b.Container
+	})
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshall cloud provider errors to json: %w", err)
+	}
+
+	collector.data["cloudprovider-errors"] = string(data)
+
+	return nil
+}
+
+// summarizeCloudProviderLogs counts the lines of a container's logs matching each signature. A line may match
+// several, e.g. a 429 response is both throttling and an ARM error.
+func summarizeCloudProviderLogs(logs string) CloudProviderContainerLogs {
+	summary := CloudProviderContainerLogs{
+		StatusCodes: map[string]int{},
+		ErrorCodes:  map[string]int{},
+		Samples:     []string{},
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(logs))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		summary.LinesScanned++
+
+		matched := false
+		if cloudProviderThrottlingPattern.MatchString(line) {
+			summary.Throttling++
+			matched = true
+		}
+		if cloudProviderQuotaPattern.MatchString(line) {
+			summary.Quota++
+			matched = true
+		}
+		if cloudProviderARMErrorPattern.MatchString(line) {
+			summary.ARMErrors++
+			matched = true
+
+			if match := cloudProviderStatusCodePattern.FindStringSubmatch(line); match != nil {
+				summary.StatusCodes[match[1]]++
+			}
+			if match := cloudProviderErrorCodePattern.FindStringSubmatch(line); match != nil {
+				summary.ErrorCodes[match[1]+match[2]]++
+			}
+		}
+
+		if matched {
+			if len(line) > cloudProviderMaxSampleSize {
+				line = line[:cloudProviderMaxSampleSize]
+			}
+			summary.Samples = append(summary.Samples, line)
+			if len(summary.Samples) > cloudProviderMaxSamples {
+				summary.Samples = summary.Samples[1:]
+			}
+		}
+	}
+
+	return summary
+}
+
+func addCounts(total, counts map[string]int) {
+	for key, count := range counts {
+		total[key] += count
+	}
+}
+
+func (collector *CloudProviderCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
@@ -0,0 +1,129 @@
+package collector
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCloudProviderCollectorGetName(t *testing.T) {
+	const expectedName = "cloudprovider"
+
+	c := NewCloudProviderCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestCloudProviderCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewCloudProviderCollector(nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCloudProviderCollectorCollect(t *testing.T) {
+	newPod := func(name, nodeName string, labels map[string]string, containers ...string) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: metav1.NamespaceSystem, Labels: labels},
+			Spec:       corev1.PodSpec{NodeName: nodeName},
+		}
+		for _, container := range containers {
+			pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: container})
+		}
+		return pod
+	}
+
+	clientset := fake.NewSimpleClientset(
+		newPod("cloud-controller-manager-1", "node2", map[string]string{"component": "cloud-controller-manager", "k8s-app": "cloud-controller-manager"}, "cloud-controller-manager"),
+		newPod("cloud-node-manager-1", "node1", map[string]string{"k8s-app": "cloud-node-manager"}, "cloud-node-manager"),
+		newPod("cloud-node-manager-2", "node2", map[string]string{"k8s-app": "cloud-node-manager"}, "cloud-node-manager"),
+		newPod("kube-controller-manager-1", "node3", map[string]string{"component": "kube-controller-manager"}, "kube-controller-manager"),
+		newPod("coredns-1", "node1", map[string]string{"k8s-app": "kube-dns"}, "coredns"),
+	)
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+		HostNodeName:  "node1",
+	}
+
+	c := NewCloudProviderCollector(clientset, runtimeInfo)
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	var result CloudProviderErrors
+	if err := json.Unmarshal([]byte(c.data["cloudprovider-errors"]), &result); err != nil {
+		t.Fatalf("unmarshal cloud provider errors: %v", err)
+	}
+
+	// The controller manager is only reported once, though it matches both of its selectors, and only this node's
+	// node manager is reported.
+	containers := []string{}
+	for _, container := range result.Containers {
+		containers = append(containers, container.Component+" "+container.Pod+"/"+container.Container)
+	}
+	expected := []string{
+		"cloud-controller-manager kube-system/cloud-controller-manager-1/cloud-controller-manager",
+		"cloud-node-manager kube-system/cloud-node-manager-1/cloud-node-manager",
+		"kube-controller-manager kube-system/kube-controller-manager-1/kube-controller-manager",
+	}
+	if !reflect.DeepEqual(containers, expected) {
+		t.Errorf("unexpected containers:\nexpected %v\nfound    %v", expected, containers)
+	}
+}
+
+func TestSummarizeCloudProviderLogs(t *testing.T) {
+	logs := strings.Join([]string{
+		`I0101 00:00:00.000000       1 azure_vmss.go:100] Updating VMSS aks-nodepool1`,
+		`E0101 00:00:01.000000       1 azure_vmss.go:200] VirtualMachineScaleSetVMsClient.List failed: Retriable: true, RetryAfter: 5s, HTTPStatusCode: 429, RawError: {"error":{"code":"TooManyRequests","message":"rate limit"}}`,
+		`E0101 00:00:02.000000       1 azure_loadbalancer.go:300] reconcile failed: StatusCode=409 -- Original Error: Code="OperationNotAllowed" Message="Operation could not be completed as it results in exceeding approved Standard LB quota"`,
+		`E0101 00:00:03.000000       1 azure_controller_common.go:400] attach disk failed: Retriable: false, RetryAfter: 0s, HTTPStatusCode: 409, RawError: Code="AttachDiskWhileBeingDetached"`,
+		`W0101 00:00:04.000000       1 azure_backoff.go:500] client is throttled, retrying after 30s`,
+	}, "\n")
+
+	summary := summarizeCloudProviderLogs(logs)
+
+	if summary.LinesScanned != 5 || summary.Throttling != 2 || summary.Quota != 1 || summary.ARMErrors != 3 {
+		t.Errorf("unexpected counts: %d lines, %d throttling, %d quota, %d ARM errors", summary.LinesScanned, summary.Throttling, summary.Quota, summary.ARMErrors)
+	}
+	if !reflect.DeepEqual(summary.StatusCodes, map[string]int{"429": 1, "409": 2}) {
+		t.Errorf("unexpected status codes %v", summary.StatusCodes)
+	}
+	if !reflect.DeepEqual(summary.ErrorCodes, map[string]int{"TooManyRequests": 1, "OperationNotAllowed": 1, "AttachDiskWhileBeingDetached": 1}) {
+		t.Errorf("unexpected error codes %v", summary.ErrorCodes)
+	}
+	if len(summary.Samples) != 4 || !strings.Contains(summary.Samples[3], "client is throttled") {
+		t.Errorf("unexpected samples %v", summary.Samples)
+	}
+}
@@ -0,0 +1,80 @@
+package collector
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+// How far back to read the containerd journal, in the relative format understood by `journalctl --since`.
+const containerdLogsSince = "-1h"
+
+// containerd writes all of its output to the journal at the same priority, so the level is taken from the
+// logfmt-style entry itself, e.g. `time="..." level=error msg="PullImage \"...\" failed"`.
+var containerdLogLevelRegex = regexp.MustCompile(`\blevel=(warning|error|fatal|panic)\b`)
+
+// ContainerdLogsCollector defines a Containerd Logs Collector struct
+type ContainerdLogsCollector struct {
+	data         map[string]string
+	osIdentifier utils.OSIdentifier
+	runCommand   utils.HostCommandRunner
+	runtimeInfo  *utils.RuntimeInfo
+}
+
+// NewContainerdLogsCollector is a constructor
+func NewContainerdLogsCollector(osIdentifier utils.OSIdentifier, runCommand utils.HostCommandRunner, runtimeInfo *utils.RuntimeInfo) *ContainerdLogsCollector {
+	return &ContainerdLogsCollector{
+		data:         make(map[string]string),
+		osIdentifier: osIdentifier,
+		runCommand:   runCommand,
+		runtimeInfo:  runtimeInfo,
+	}
+}
+
+func (collector *ContainerdLogsCollector) GetName() string {
+	return "containerdlogs"
+}
+
+func (collector *ContainerdLogsCollector) CheckSupported() error {
+	// This uses `journalctl` to read the containerd service logs, which is not available on Windows.
+	if collector.osIdentifier != utils.Linux {
+		return fmt.Errorf("unsupported OS: %s", collector.osIdentifier)
+	}
+
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *ContainerdLogsCollector) Collect() error {
+	output, err := collector.runCommand("journalctl", "-u", "containerd", "--since", containerdLogsSince, "--no-pager", "-o", "short-iso")
+	if err != nil {
+		return fmt.Errorf("error reading containerd logs: %w", err)
+	}
+
+	collector.data["containerd"] = filterContainerdLogs(output)
+
+	return nil
+}
+
+// filterContainerdLogs returns only the journal lines logged by containerd at warning level or above.
+func filterContainerdLogs(output string) string {
+	var sb strings.Builder
+	for _, line := range strings.Split(output, "\n") {
+		if containerdLogLevelRegex.MatchString(line) {
+			sb.WriteString(line)
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+func (collector *ContainerdLogsCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
@@ -0,0 +1,117 @@
+package collector
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+func TestContainerdLogsCollectorGetName(t *testing.T) {
+	const expectedName = "containerdlogs"
+
+	c := NewContainerdLogsCollector("", nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestContainerdLogsCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		osIdentifier  utils.OSIdentifier
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "windows",
+			osIdentifier:  utils.Windows,
+			collectorList: []string{},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewContainerdLogsCollector(tt.osIdentifier, nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestContainerdLogsCollectorCollect(t *testing.T) {
+	const journal = `2023-01-01T00:00:00+0000 aks-nodepool1-12345678-vmss000000 containerd[1234]: time="2023-01-01T00:00:00.000000000Z" level=info msg="PullImage \"myregistry.azurecr.io/app:v2\""
+2023-01-01T00:00:01+0000 aks-nodepool1-12345678-vmss000000 containerd[1234]: time="2023-01-01T00:00:01.000000000Z" level=error msg="PullImage \"myregistry.azurecr.io/app:v2\" failed" error="failed to pull and unpack image \"myregistry.azurecr.io/app:v2\": failed to resolve reference \"myregistry.azurecr.io/app:v2\": unexpected status code 401 Unauthorized"
+2023-01-01T00:00:02+0000 aks-nodepool1-12345678-vmss000000 containerd[1234]: time="2023-01-01T00:00:02.000000000Z" level=warning msg="cleaning up after shim disconnected" id=abc namespace=k8s.io
+2023-01-01T00:00:03+0000 aks-nodepool1-12345678-vmss000000 containerd[1234]: time="2023-01-01T00:00:03.000000000Z" level=info msg="StartContainer for \"abc\" returns successfully"
+`
+
+	tests := []struct {
+		name       string
+		output     string
+		commandErr error
+		wantErr    bool
+		wantData   map[string]*regexp.Regexp
+	}{
+		{
+			name:    "errors and warnings",
+			output:  journal,
+			wantErr: false,
+			wantData: map[string]*regexp.Regexp{
+				"containerd": regexp.MustCompile(`^[^\n]*level=error msg="PullImage [^\n]*401 Unauthorized"\n[^\n]*level=warning msg="cleaning up after shim disconnected"[^\n]*\n$`),
+			},
+		},
+		{
+			name:    "no errors",
+			output:  "-- No entries --\n",
+			wantErr: false,
+			wantData: map[string]*regexp.Regexp{
+				"containerd": regexp.MustCompile(`^$`),
+			},
+		},
+		{
+			name:       "journalctl failure",
+			commandErr: errors.New("journalctl not found"),
+			wantErr:    true,
+			wantData:   map[string]*regexp.Regexp{},
+		},
+	}
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runCommand := func(command string, arg ...string) (string, error) {
+				return tt.output, tt.commandErr
+			}
+
+			c := NewContainerdLogsCollector(utils.Linux, runCommand, runtimeInfo)
+			err := c.Collect()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Collect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			compareCollectorData(t, tt.wantData, c.GetData())
+		})
+	}
+}
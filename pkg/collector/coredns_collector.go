@@ -0,0 +1,226 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	coreDNSConfigMapName       = "coredns"
+	coreDNSCustomConfigMapName = "coredns-custom"
+	coreDNSPodSelector         = "k8s-app=kube-dns"
+
+	// The port of the CoreDNS prometheus plugin, used if the pod doesn't declare a port named "metrics".
+	coreDNSDefaultMetricsPort = 9153
+
+	coreDNSRequestsMetric     = "coredns_dns_requests_total"
+	coreDNSResponsesMetric    = "coredns_dns_responses_total"
+	coreDNSCacheHitsMetric    = "coredns_cache_hits_total"
+	coreDNSCacheMissesMetric  = "coredns_cache_misses_total"
+	coreDNSServFailRcodeLabel = "SERVFAIL"
+)
+
+// CoreDNSMetrics aggregates the metrics of all CoreDNS replicas. The counters accumulate from the start of each
+// replica, so the ratios describe the lifetime of the replicas rather than the current rate.
+type CoreDNSMetrics struct {
+	Pods          []string            `json:"pods"`
+	ScrapeErrors  map[string]string   `json:"scrapeErrors"`
+	Zones         []CoreDNSZoneMetric `json:"zones"`
+	CacheHits     float64             `json:"cacheHits"`
+	CacheMisses   float64             `json:"cacheMisses"`
+	CacheHitRatio float64             `json:"cacheHitRatio"`
+}
+
+type CoreDNSZoneMetric struct {
+	Zone          string  `json:"zone"`
+	Requests      float64 `json:"requests"`
+	Responses     float64 `json:"responses"`
+	ServFail      float64 `json:"servfail"`
+	ServFailRatio float64 `json:"servfailRatio"`
+}
+
+// CoreDNSCollector defines a CoreDNS Collector struct
+type CoreDNSCollector struct {
+	data          map[string]string
+	clientset     kubernetes.Interface
+	scrapeMetrics utils.PodMetricsScraper
+	runtimeInfo   *utils.RuntimeInfo
+}
+
+// NewCoreDNSCollector is a constructor
+func NewCoreDNSCollector(clientset kubernetes.Interface, scrapeMetrics utils.PodMetricsScraper, runtimeInfo *utils.RuntimeInfo) *CoreDNSCollector {
+	return &CoreDNSCollector{
+		data:          make(map[string]string),
+		clientset:     clientset,
+		scrapeMetrics: scrapeMetrics,
+		runtimeInfo:   runtimeInfo,
+	}
+}
+
+func (collector *CoreDNSCollector) GetName() string {
+	return "coredns"
+}
+
+func (collector *CoreDNSCollector) CheckSupported() error {
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *CoreDNSCollector) Collect() error {
+	ctx := context.Background()
+	configMaps := collector.clientset.CoreV1().ConfigMaps(metav1.NamespaceSystem)
+
+	coreDNSConfigMap, err := configMaps.Get(ctx, coreDNSConfigMapName, metav1.GetOptions{})
+	if err == nil {
+		collector.data["corefile"] = coreDNSConfigMap.Data["Corefile"]
+	} else if !apierrors.IsNotFound(err) {
+		return fmt.Errorf("unable to get configmap %s: %w", coreDNSConfigMapName, err)
+	}
+
+	// The custom configuration is optional, and holds any number of server blocks and overrides.
+	customConfigMap, err := configMaps.Get(ctx, coreDNSCustomConfigMapName, metav1.GetOptions{})
+	if err == nil {
+		data, err := json.Marshal(customConfigMap.Data)
+		if err != nil {
+			return fmt.Errorf("marshall %s to json: %w", coreDNSCustomConfigMapName, err)
+		}
+		collector.data["coredns-custom"] = string(data)
+	} else if !apierrors.IsNotFound(err) {
+		return fmt.Errorf("unable to get configmap %s: %w", coreDNSCustomConfigMapName, err)
+	}
+
+	pods, err := collector.clientset.CoreV1().Pods(metav1.NamespaceSystem).List(ctx, metav1.ListOptions{LabelSelector: coreDNSPodSelector})
+	if err != nil {
+		return fmt.Errorf("unable to list CoreDNS pods: %w", err)
+	}
+
+	metrics := collector.getMetrics(pods.Items)
+
+	data, err := json.Marshal(metrics)
+	if err != nil {
+		return fmt.Errorf("marshall CoreDNS metrics to json: %w", err)
+	}
+
+	collector.data["coredns-metrics"] = string(data)
+
+	return nil
+}
+
+// getMetrics scrapes each running CoreDNS pod and aggregates the results. Pods which can't be scraped are reported,
+// rather than failing collection, since the metrics of the other replicas are still useful.
+func (collector *CoreDNSCollector) getMetrics(pods []corev1.Pod) *CoreDNSMetrics {
+	metrics := &CoreDNSMetrics{
+		Pods:         []string{},
+		ScrapeErrors: map[string]string{},
+		Zones:        []CoreDNSZoneMetric{},
+	}
+
+	zones := map[string]*CoreDNSZoneMetric{}
+	getZone := func(metric *dto.Metric) *CoreDNSZoneMetric {
+		zone := getMetricLabel(metric, "zone")
+		if _, ok := zones[zone]; !ok {
+			zones[zone] = &CoreDNSZoneMetric{Zone: zone}
+		}
+		return zones[zone]
+	}
+
+	for _, pod := range pods {
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+
+		text, err := collector.scrapeMetrics(pod.Namespace, pod.Name, getMetricsPort(&pod, coreDNSDefaultMetricsPort))
+		if err != nil {
+			metrics.ScrapeErrors[pod.Name] = err.Error()
+			continue
+		}
+
+		var parser expfmt.TextParser
+		families, err := parser.TextToMetricFamilies(strings.NewReader(text))
+		if err != nil {
+			metrics.ScrapeErrors[pod.Name] = fmt.Sprintf("error parsing metrics: %v", err)
+			continue
+		}
+
+		metrics.Pods = append(metrics.Pods, pod.Name)
+
+		for _, metric := range families[coreDNSRequestsMetric].GetMetric() {
+			getZone(metric).Requests += getMetricValue(metric)
+		}
+		for _, metric := range families[coreDNSResponsesMetric].GetMetric() {
+			zone := getZone(metric)
+			zone.Responses += getMetricValue(metric)
+			if getMetricLabel(metric, "rcode") == coreDNSServFailRcodeLabel {
+				zone.ServFail += getMetricValue(metric)
+			}
+		}
+		for _, metric := range families[coreDNSCacheHitsMetric].GetMetric() {
+			metrics.CacheHits += getMetricValue(metric)
+		}
+		for _, metric := range families[coreDNSCacheMissesMetric].GetMetric() {
+			metrics.CacheMisses += getMetricValue(metric)
+		}
+	}
+
+	sort.Strings(metrics.Pods)
+
+	for _, zone := range zones {
+		if zone.Responses > 0 {
+			zone.ServFailRatio = zone.ServFail / zone.Responses
+		}
+		metrics.Zones = append(metrics.Zones, *zone)
+	}
+	sort.Slice(metrics.Zones, func(i, j int) bool {
+		return metrics.Zones[i].Zone < metrics.Zones[j].Zone
+	})
+
+	if lookups := metrics.CacheHits + metrics.CacheMisses; lookups > 0 {
+		metrics.CacheHitRatio = metrics.CacheHits / lookups
+	}
+
+	return metrics
+}
+
+// getMetricsPort returns the container port named "metrics" of a pod, or the default port if there is none.
+func getMetricsPort(pod *corev1.Pod, defaultPort int) string {
+	for _, container := range pod.Spec.Containers {
+		for _, port := range container.Ports {
+			if port.Name == "metrics" {
+				return strconv.Itoa(int(port.ContainerPort))
+			}
+		}
+	}
+
+	return strconv.Itoa(defaultPort)
+}
+
+func getMetricLabel(metric *dto.Metric, name string) string {
+	for _, label := range metric.Label {
+		if label.GetName() == name {
+			return label.GetValue()
+		}
+	}
+
+	return ""
+}
+
+func (collector *CoreDNSCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
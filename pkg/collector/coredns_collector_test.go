@@ -0,0 +1,143 @@
+package collector
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCoreDNSCollectorGetName(t *testing.T) {
+	const expectedName = "coredns"
+
+	c := NewCoreDNSCollector(nil, nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestCoreDNSCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewCoreDNSCollector(nil, nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCoreDNSCollectorCollect(t *testing.T) {
+	const podMetrics = `# TYPE coredns_dns_requests_total counter
+coredns_dns_requests_total{family="1",proto="udp",server="dns://:53",type="A",zone="."} 80
+coredns_dns_requests_total{family="1",proto="udp",server="dns://:53",type="A",zone="cluster.local."} 20
+# TYPE coredns_dns_responses_total counter
+coredns_dns_responses_total{rcode="NOERROR",server="dns://:53",zone="."} 70
+coredns_dns_responses_total{rcode="SERVFAIL",server="dns://:53",zone="."} 10
+coredns_dns_responses_total{rcode="NOERROR",server="dns://:53",zone="cluster.local."} 20
+# TYPE coredns_cache_hits_total counter
+coredns_cache_hits_total{server="dns://:53",type="success"} 30
+coredns_cache_hits_total{server="dns://:53",type="denial"} 10
+# TYPE coredns_cache_misses_total counter
+coredns_cache_misses_total{server="dns://:53"} 60
+`
+
+	newPod := func(name string, phase corev1.PodPhase, ports []corev1.ContainerPort) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: metav1.NamespaceSystem, Labels: map[string]string{"k8s-app": "kube-dns"}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "coredns", Ports: ports}}},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+
+	clientset := fake.NewSimpleClientset(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: metav1.NamespaceSystem},
+			Data:       map[string]string{"Corefile": ".:53 {\n    forward . /etc/resolv.conf\n}\n"},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "coredns-custom", Namespace: metav1.NamespaceSystem},
+			Data:       map[string]string{"log.override": "log"},
+		},
+		newPod("coredns-1", corev1.PodRunning, []corev1.ContainerPort{{Name: "metrics", ContainerPort: 9154}}),
+		newPod("coredns-2", corev1.PodRunning, nil),
+		newPod("coredns-3", corev1.PodRunning, nil),
+		newPod("coredns-4", corev1.PodPending, nil),
+	)
+
+	scrapedPorts := map[string]string{}
+	scrapeMetrics := func(namespace, pod, port string) (string, error) {
+		scrapedPorts[pod] = port
+		if pod == "coredns-3" {
+			return "", errors.New("connection refused")
+		}
+		return podMetrics, nil
+	}
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+	}
+
+	c := NewCoreDNSCollector(clientset, scrapeMetrics, runtimeInfo)
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	if c.data["corefile"] != ".:53 {\n    forward . /etc/resolv.conf\n}\n" {
+		t.Errorf("unexpected corefile %s", c.data["corefile"])
+	}
+	if c.data["coredns-custom"] != `{"log.override":"log"}` {
+		t.Errorf("unexpected coredns-custom %s", c.data["coredns-custom"])
+	}
+
+	// Pods that aren't running are not scraped, and the declared metrics port is used if there is one.
+	expectedPorts := map[string]string{"coredns-1": "9154", "coredns-2": "9153", "coredns-3": "9153"}
+	if !reflect.DeepEqual(scrapedPorts, expectedPorts) {
+		t.Errorf("unexpected scraped ports %v", scrapedPorts)
+	}
+
+	var metrics CoreDNSMetrics
+	if err := json.Unmarshal([]byte(c.data["coredns-metrics"]), &metrics); err != nil {
+		t.Fatalf("unable to unmarshal metrics: %v", err)
+	}
+
+	expected := CoreDNSMetrics{
+		Pods:         []string{"coredns-1", "coredns-2"},
+		ScrapeErrors: map[string]string{"coredns-3": "connection refused"},
+		Zones: []CoreDNSZoneMetric{
+			{Zone: ".", Requests: 160, Responses: 160, ServFail: 20, ServFailRatio: 0.125},
+			{Zone: "cluster.local.", Requests: 40, Responses: 40},
+		},
+		CacheHits:     80,
+		CacheMisses:   120,
+		CacheHitRatio: 0.4,
+	}
+	if !reflect.DeepEqual(metrics, expected) {
+		t.Errorf("unexpected metrics:\nexpected %+v\nfound    %+v", expected, metrics)
+	}
+}
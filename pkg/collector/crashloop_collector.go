@@ -0,0 +1,207 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// A container is flagged as crash-looping if it restarted more than this many times within the window.
+	crashLoopRestartThreshold = 3
+	crashLoopWindow           = time.Hour
+)
+
+type CrashLoopPodTimeline struct {
+	Namespace  string                   `json:"namespace"`
+	Name       string                   `json:"name"`
+	NodeName   string                   `json:"nodeName,omitempty"`
+	Containers []CrashLoopContainerInfo `json:"containers"`
+}
+
+// CrashLoopContainerInfo describes the restarts of a container. Kubernetes only retains the current and the previous
+// state of a container, so the timeline holds at most two terminations, and the number of restarts within the window
+// is estimated from the restart count and the age of the pod.
+type CrashLoopContainerInfo struct {
+	Name           string                 `json:"name"`
+	Init           bool                   `json:"init,omitempty"`
+	RestartCount   int32                  `json:"restartCount"`
+	RecentRestarts int32                  `json:"recentRestarts"`
+	CrashLooping   bool                   `json:"crashLooping"`
+	WaitingReason  string                 `json:"waitingReason,omitempty"`
+	Terminations   []CrashLoopTermination `json:"terminations"`
+}
+
+type CrashLoopTermination struct {
+	ExitCode   int32      `json:"exitCode"`
+	Signal     int32      `json:"signal,omitempty"`
+	Reason     string     `json:"reason,omitempty"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Current    bool       `json:"current,omitempty"`
+}
+
+// CrashLoopCollector defines a Crash Loop Collector struct
+type CrashLoopCollector struct {
+	data        map[string]string
+	clientset   kubernetes.Interface
+	runtimeInfo *utils.RuntimeInfo
+	now         func() time.Time
+}
+
+// NewCrashLoopCollector is a constructor
+func NewCrashLoopCollector(clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *CrashLoopCollector {
+	return &CrashLoopCollector{
+		data:        make(map[string]string),
+		clientset:   clientset,
+		runtimeInfo: runtimeInfo,
+		now:         time.Now,
+	}
+}
+
+func (collector *CrashLoopCollector) GetName() string {
+	return "crashloop"
+}
+
+func (collector *CrashLoopCollector) CheckSupported() error {
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *CrashLoopCollector) Collect() error {
+	ctx := context.Background()
+	now := collector.now()
+
+	result := []CrashLoopPodTimeline{}
+
+	err := utils.ListAllPages(metav1.ListOptions{}, func(listOptions metav1.ListOptions) (string, error) {
+		podList, err := collector.clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, listOptions)
+		if err != nil {
+			return "", err
+		}
+
+		for _, pod := range podList.Items {
+			var podStart *time.Time
+			if pod.Status.StartTime != nil {
+				podStart = &pod.Status.StartTime.Time
+			}
+
+			containers := getCrashLoopContainers(pod.Status.InitContainerStatuses, true, podStart, now)
+			containers = append(containers, getCrashLoopContainers(pod.Status.ContainerStatuses, false, podStart, now)...)
+			if len(containers) == 0 {
+				continue
+			}
+
+			result = append(result, CrashLoopPodTimeline{
+				Namespace:  pod.Namespace,
+				Name:       pod.Name,
+				NodeName:   pod.Spec.NodeName,
+				Containers: containers,
+			})
+		}
+
+		return podList.Continue, nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list pods: %w", err)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Namespace+"/"+result[i].Name < result[j].Namespace+"/"+result[j].Name
+	})
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshall crash loop timeline to json: %w", err)
+	}
+
+	collector.data["crashloop-timeline"] = string(data)
+
+	return nil
+}
+
+// getCrashLoopContainers returns the containers which have restarted or are terminated with an error, along with
+// their terminations in the order they finished.
+func getCrashLoopContainers(statuses []corev1.ContainerStatus, init bool, podStart *time.Time, now time.Time) []CrashLoopContainerInfo {
+	result := []CrashLoopContainerInfo{}
+	for _, status := range statuses {
+		current := status.State.Terminated
+		if status.RestartCount == 0 && (current == nil || current.ExitCode == 0) {
+			continue
+		}
+
+		info := CrashLoopContainerInfo{
+			Name:         status.Name,
+			Init:         init,
+			RestartCount: status.RestartCount,
+			Terminations: []CrashLoopTermination{},
+		}
+		if status.State.Waiting != nil {
+			info.WaitingReason = status.State.Waiting.Reason
+		}
+
+		if last := status.LastTerminationState.Terminated; last != nil {
+			info.Terminations = append(info.Terminations, getCrashLoopTermination(last, false))
+			info.RecentRestarts = getRecentRestarts(status.RestartCount, last, podStart, now)
+		}
+		if current != nil {
+			info.Terminations = append(info.Terminations, getCrashLoopTermination(current, true))
+		}
+
+		info.CrashLooping = info.RecentRestarts > crashLoopRestartThreshold
+		result = append(result, info)
+	}
+
+	return result
+}
+
+func getCrashLoopTermination(state *corev1.ContainerStateTerminated, current bool) CrashLoopTermination {
+	termination := CrashLoopTermination{
+		ExitCode: state.ExitCode,
+		Signal:   state.Signal,
+		Reason:   state.Reason,
+		Current:  current,
+	}
+	if !state.StartedAt.IsZero() {
+		termination.StartedAt = &state.StartedAt.Time
+	}
+	if !state.FinishedAt.IsZero() {
+		termination.FinishedAt = &state.FinishedAt.Time
+	}
+
+	return termination
+}
+
+// getRecentRestarts estimates how many times a container restarted within the window. If the last termination
+// is older than the window there were none. Otherwise, for a pod started within the window all restarts are
+// recent, and for an older pod the restarts are assumed to be spread evenly over its lifetime.
+func getRecentRestarts(restartCount int32, last *corev1.ContainerStateTerminated, podStart *time.Time, now time.Time) int32 {
+	if last.FinishedAt.IsZero() || now.Sub(last.FinishedAt.Time) > crashLoopWindow {
+		return 0
+	}
+
+	if podStart == nil || now.Sub(*podStart) <= crashLoopWindow {
+		return restartCount
+	}
+
+	estimate := float64(restartCount) * float64(crashLoopWindow) / float64(now.Sub(*podStart))
+	return int32(math.Max(1, math.Ceil(estimate)))
+}
+
+func (collector *CrashLoopCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
@@ -0,0 +1,163 @@
+package collector
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestCrashLoopCollectorGetName(t *testing.T) {
+	const expectedName = "crashloop"
+
+	c := NewCrashLoopCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestCrashLoopCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewCrashLoopCollector(nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCrashLoopCollectorCollect(t *testing.T) {
+	now := time.Date(2023, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	newPod := func(name string, started time.Time, statuses ...corev1.ContainerStatus) corev1.Pod {
+		startTime := metav1.NewTime(started)
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app"},
+			Spec:       corev1.PodSpec{NodeName: "node-1"},
+			Status:     corev1.PodStatus{StartTime: &startTime, ContainerStatuses: statuses},
+		}
+	}
+	newTerminated := func(exitCode, signal int32, reason string, finished time.Time) *corev1.ContainerStateTerminated {
+		return &corev1.ContainerStateTerminated{
+			ExitCode:   exitCode,
+			Signal:     signal,
+			Reason:     reason,
+			StartedAt:  metav1.NewTime(finished.Add(-10 * time.Second)),
+			FinishedAt: metav1.NewTime(finished),
+		}
+	}
+
+	healthy := newPod("healthy", now.Add(-time.Hour), corev1.ContainerStatus{
+		Name:  "main",
+		State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+	})
+
+	// A new pod restarting repeatedly within the last hour.
+	crashing := newPod("crashing", now.Add(-30*time.Minute), corev1.ContainerStatus{
+		Name:                 "main",
+		RestartCount:         6,
+		State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+		LastTerminationState: corev1.ContainerState{Terminated: newTerminated(137, 9, "OOMKilled", now.Add(-2*time.Minute))},
+	})
+
+	// A week-old pod with the same number of restarts, the last of them recent.
+	occasional := newPod("occasional", now.Add(-7*24*time.Hour), corev1.ContainerStatus{
+		Name:                 "main",
+		RestartCount:         6,
+		State:                corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+		LastTerminationState: corev1.ContainerState{Terminated: newTerminated(1, 0, "Error", now.Add(-10*time.Minute))},
+	})
+
+	// A completed job container which failed without restarting.
+	failed := newPod("failed", now.Add(-3*time.Hour), corev1.ContainerStatus{
+		Name:  "job",
+		State: corev1.ContainerState{Terminated: newTerminated(2, 0, "Error", now.Add(-2*time.Hour))},
+	})
+
+	clientset := fake.NewSimpleClientset()
+
+	// The fake clientset doesn't paginate, so serve the pods over two pages.
+	pages := []*corev1.PodList{
+		{ListMeta: metav1.ListMeta{Continue: "page-2"}, Items: []corev1.Pod{healthy, occasional}},
+		{Items: []corev1.Pod{crashing, failed}},
+	}
+	podListCalls := 0
+	clientset.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		page := pages[podListCalls]
+		podListCalls++
+		return true, page, nil
+	})
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+	}
+
+	c := NewCrashLoopCollector(clientset, runtimeInfo)
+	c.now = func() time.Time { return now }
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	if podListCalls != len(pages) {
+		t.Errorf("expected %d pod list calls, found %d", len(pages), podListCalls)
+	}
+
+	testDataValue(t, c.GetData()["crashloop-timeline"], func(raw string) {
+		var pods []CrashLoopPodTimeline
+		if err := json.Unmarshal([]byte(raw), &pods); err != nil {
+			t.Fatalf("unmarshal GetData(): %v", err)
+		}
+
+		names := []string{}
+		for _, pod := range pods {
+			names = append(names, pod.Name)
+		}
+		if !equalStringSlices(names, []string{"crashing", "failed", "occasional"}) {
+			t.Fatalf("unexpected pods %v", names)
+		}
+
+		crashing := pods[0].Containers[0]
+		if !crashing.CrashLooping || crashing.RecentRestarts != 6 || crashing.WaitingReason != "CrashLoopBackOff" ||
+			len(crashing.Terminations) != 1 || crashing.Terminations[0].ExitCode != 137 || crashing.Terminations[0].Signal != 9 ||
+			crashing.Terminations[0].Reason != "OOMKilled" || crashing.Terminations[0].FinishedAt == nil {
+			t.Errorf("unexpected crashing container %+v", crashing)
+		}
+
+		failed := pods[1].Containers[0]
+		if failed.CrashLooping || failed.RecentRestarts != 0 || len(failed.Terminations) != 1 || !failed.Terminations[0].Current {
+			t.Errorf("unexpected failed container %+v", failed)
+		}
+
+		occasional := pods[2].Containers[0]
+		if occasional.CrashLooping || occasional.RecentRestarts != 1 || occasional.RestartCount != 6 {
+			t.Errorf("unexpected occasional container %+v", occasional)
+		}
+	})
+}
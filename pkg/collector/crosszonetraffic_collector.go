@@ -0,0 +1,178 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Estimated fraction of traffic crossing zones at or above which a service is flagged.
+const highCrossZoneFraction = 0.5
+
+// Annotations which enable topology-aware routing (the older annotation is deprecated from K8s 1.27).
+var topologyAwareRoutingAnnotations = []string{
+	"service.kubernetes.io/topology-mode",
+	"service.kubernetes.io/topology-aware-hints",
+}
+
+type CrossZoneServiceInfo struct {
+	Namespace                  string         `json:"namespace"`
+	Name                       string         `json:"name"`
+	TopologyAwareRouting       bool           `json:"topologyAwareRouting"`
+	EndpointsPerZone           map[string]int `json:"endpointsPerZone"`
+	EstimatedCrossZoneFraction float64        `json:"estimatedCrossZoneFraction"`
+	HighCrossZoneExposure      bool           `json:"highCrossZoneExposure"`
+}
+
+// CrossZoneTrafficCollector defines a Cross Zone Traffic Collector struct
+type CrossZoneTrafficCollector struct {
+	data        map[string]string
+	clientset   kubernetes.Interface
+	runtimeInfo *utils.RuntimeInfo
+}
+
+// NewCrossZoneTrafficCollector is a constructor
+func NewCrossZoneTrafficCollector(clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *CrossZoneTrafficCollector {
+	return &CrossZoneTrafficCollector{
+		data:        make(map[string]string),
+		clientset:   clientset,
+		runtimeInfo: runtimeInfo,
+	}
+}
+
+func (collector *CrossZoneTrafficCollector) GetName() string {
+	return "crosszonetraffic"
+}
+
+func (collector *CrossZoneTrafficCollector) CheckSupported() error {
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *CrossZoneTrafficCollector) Collect() error {
+	ctx := context.Background()
+
+	nodeList, err := collector.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list nodes: %w", err)
+	}
+
+	nodeZones := map[string]string{}
+	for _, node := range nodeList.Items {
+		nodeZones[node.Name] = node.Labels[corev1.LabelTopologyZone]
+	}
+
+	serviceList, err := collector.clientset.CoreV1().Services(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list services: %w", err)
+	}
+
+	sliceList, err := collector.clientset.DiscoveryV1().EndpointSlices(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list endpoint slices: %w", err)
+	}
+
+	// Group endpoint slices by owning service
+	servicesSlices := map[string][]discoveryv1.EndpointSlice{}
+	for _, slice := range sliceList.Items {
+		serviceName, ok := slice.Labels[discoveryv1.LabelServiceName]
+		if !ok {
+			continue
+		}
+		key := slice.Namespace + "/" + serviceName
+		servicesSlices[key] = append(servicesSlices[key], slice)
+	}
+
+	result := []CrossZoneServiceInfo{}
+	for _, service := range serviceList.Items {
+		endpointsPerZone := map[string]int{}
+		totalEndpoints := 0
+		for _, slice := range servicesSlices[service.Namespace+"/"+service.Name] {
+			for _, endpoint := range slice.Endpoints {
+				if endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready {
+					continue
+				}
+
+				zone := ""
+				if endpoint.Zone != nil {
+					zone = *endpoint.Zone
+				} else if endpoint.NodeName != nil {
+					zone = nodeZones[*endpoint.NodeName]
+				}
+				if zone == "" {
+					zone = "unknown"
+				}
+
+				endpointsPerZone[zone]++
+				totalEndpoints++
+			}
+		}
+
+		if totalEndpoints == 0 {
+			continue
+		}
+
+		info := CrossZoneServiceInfo{
+			Namespace:                  service.Namespace,
+			Name:                       service.Name,
+			TopologyAwareRouting:       hasTopologyAwareRouting(&service),
+			EndpointsPerZone:           endpointsPerZone,
+			EstimatedCrossZoneFraction: estimateCrossZoneFraction(endpointsPerZone, totalEndpoints),
+		}
+		info.HighCrossZoneExposure = !info.TopologyAwareRouting && info.EstimatedCrossZoneFraction >= highCrossZoneFraction
+
+		result = append(result, info)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Namespace+"/"+result[i].Name < result[j].Namespace+"/"+result[j].Name
+	})
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshall cross-zone traffic to json: %w", err)
+	}
+
+	collector.data["crosszonetraffic"] = string(data)
+
+	return nil
+}
+
+func hasTopologyAwareRouting(service *corev1.Service) bool {
+	for _, annotation := range topologyAwareRoutingAnnotations {
+		value, ok := service.Annotations[annotation]
+		if ok && !strings.EqualFold(value, "disabled") && !strings.EqualFold(value, "false") {
+			return true
+		}
+	}
+	return false
+}
+
+// estimateCrossZoneFraction estimates the proportion of requests that would be routed to an endpoint
+// in a different zone from the client, assuming clients are distributed across zones in the same proportion
+// as the endpoints, and that kube-proxy picks an endpoint uniformly at random.
+func estimateCrossZoneFraction(endpointsPerZone map[string]int, totalEndpoints int) float64 {
+	sameZone := 0.0
+	for _, count := range endpointsPerZone {
+		proportion := float64(count) / float64(totalEndpoints)
+		sameZone += proportion * proportion
+	}
+	return 1 - sameZone
+}
+
+func (collector *CrossZoneTrafficCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
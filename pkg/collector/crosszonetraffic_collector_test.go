@@ -0,0 +1,129 @@
+package collector
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCrossZoneTrafficCollectorGetName(t *testing.T) {
+	const expectedName = "crosszonetraffic"
+
+	c := NewCrossZoneTrafficCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestCrossZoneTrafficCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewCrossZoneTrafficCollector(nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCrossZoneTrafficCollectorCollect(t *testing.T) {
+	newNode := func(name, zone string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{corev1.LabelTopologyZone: zone}}}
+	}
+	newService := func(name string, annotations map[string]string) *corev1.Service {
+		return &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app", Annotations: annotations}}
+	}
+	newSlice := func(serviceName string, nodeNames ...string) *discoveryv1.EndpointSlice {
+		endpoints := []discoveryv1.Endpoint{}
+		for i := range nodeNames {
+			endpoints = append(endpoints, discoveryv1.Endpoint{NodeName: &nodeNames[i]})
+		}
+		return &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      serviceName + "-abcde",
+				Namespace: "app",
+				Labels:    map[string]string{discoveryv1.LabelServiceName: serviceName},
+			},
+			Endpoints: endpoints,
+		}
+	}
+
+	clientset := fake.NewSimpleClientset(
+		newNode("node-1", "eastus-1"),
+		newNode("node-2", "eastus-2"),
+		newNode("node-3", "eastus-1"),
+		newService("spread", nil),
+		newSlice("spread", "node-1", "node-2"),
+		newService("aware", map[string]string{"service.kubernetes.io/topology-mode": "Auto"}),
+		newSlice("aware", "node-1", "node-2"),
+		newService("colocated", nil),
+		newSlice("colocated", "node-1", "node-3"),
+		newService("noendpoints", nil),
+	)
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+	}
+
+	c := NewCrossZoneTrafficCollector(clientset, runtimeInfo)
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	testDataValue(t, c.GetData()["crosszonetraffic"], func(raw string) {
+		var result []CrossZoneServiceInfo
+		if err := json.Unmarshal([]byte(raw), &result); err != nil {
+			t.Fatalf("unmarshal GetData(): %v", err)
+		}
+
+		expected := map[string]bool{
+			"spread":    true,
+			"aware":     false,
+			"colocated": false,
+		}
+		if len(result) != len(expected) {
+			t.Fatalf("expected %d services, found %d: %s", len(expected), len(result), raw)
+		}
+
+		for _, info := range result {
+			wantFlag, ok := expected[info.Name]
+			if !ok {
+				t.Errorf("unexpected service %s", info.Name)
+				continue
+			}
+			if info.HighCrossZoneExposure != wantFlag {
+				t.Errorf("service %s: expected highCrossZoneExposure %v, found %v", info.Name, wantFlag, info.HighCrossZoneExposure)
+			}
+		}
+
+		spread := result[2]
+		if spread.Name != "spread" || spread.EndpointsPerZone["eastus-1"] != 1 || spread.EndpointsPerZone["eastus-2"] != 1 {
+			t.Errorf("unexpected zone distribution for spread service: %+v", spread)
+		}
+	})
+}
@@ -0,0 +1,454 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// The maximum number of events reported for each DaemonSet.
+const daemonSetCoverageMaxEvents = 5
+
+// The reasons a node is missing a ready pod of a DaemonSet.
+const (
+	daemonSetGapUntoleratedTaint = "UntoleratedTaint"
+	daemonSetGapNoPod            = "NoPod"
+	daemonSetGapPodUnschedulable = "PodUnschedulable"
+	daemonSetGapPodNotReady      = "PodNotReady"
+)
+
+// Matches the node named in DaemonSet controller events, e.g. FailedPlacement: failed to place pod on "node-1": ...
+// and FailedDaemonPod: Found failed daemon pod default/ds-abcde on node node-1, will try to kill it
+var daemonSetEventNodeRegex = regexp.MustCompile(`\bon (?:node )?"?([a-z0-9][-a-z0-9.]*[a-z0-9])"?`)
+
+type DaemonSetCoverageReport struct {
+	NodeCount  int                 `json:"nodeCount"`
+	DaemonSets []DaemonSetCoverage `json:"daemonSets"`
+}
+
+type DaemonSetCoverage struct {
+	Namespace              string `json:"namespace"`
+	Name                   string `json:"name"`
+	DesiredNumberScheduled int32  `json:"desiredNumberScheduled"`
+	CurrentNumberScheduled int32  `json:"currentNumberScheduled"`
+	NumberReady            int32  `json:"numberReady"`
+	NumberMisscheduled     int32  `json:"numberMisscheduled"`
+	// EligibleNodes match the node selector and affinity of the pod template, and ExcludedNodes don't, by design.
+	EligibleNodes int                      `json:"eligibleNodes"`
+	ExcludedNodes int                      `json:"excludedNodes"`
+	Gaps          []DaemonSetNodeGap       `json:"gaps"`
+	Events        []DaemonSetCoverageEvent `json:"events"`
+}
+
+// DaemonSetNodeGap is an eligible node without a ready pod of the DaemonSet.
+type DaemonSetNodeGap struct {
+	Node    string `json:"node"`
+	Reason  string `json:"reason"`
+	Message string `json:"message,omitempty"`
+	Pod     string `json:"pod,omitempty"`
+}
+
+type DaemonSetCoverageEvent struct {
+	Reason        string    `json:"reason"`
+	Message       string    `json:"message"`
+	Count         int32     `json:"count"`
+	LastTimestamp time.Time `json:"lastTimestamp"`
+}
+
+// DaemonSetCoverageCollector defines a DaemonSet Coverage Collector struct
+type DaemonSetCoverageCollector struct {
+	data        map[string]string
+	clientset   kubernetes.Interface
+	runtimeInfo *utils.RuntimeInfo
+}
+
+// NewDaemonSetCoverageCollector is a constructor
+func NewDaemonSetCoverageCollector(clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *DaemonSetCoverageCollector {
+	return &DaemonSetCoverageCollector{
+		data:        make(map[string]string),
+		clientset:   clientset,
+		runtimeInfo: runtimeInfo,
+	}
+}
+
+func (collector *DaemonSetCoverageCollector) GetName() string {
+	return "daemonsetcoverage"
+}
+
+func (collector *DaemonSetCoverageCollector) CheckSupported() error {
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *DaemonSetCoverageCollector) Collect() error {
+	ctx := context.Background()
+
+	nodes := []corev1.Node{}
+	err := utils.ListAllPages(metav1.ListOptions{}, func(listOptions metav1.ListOptions) (string, error) {
+		nodeList, err := collector.clientset.CoreV1().Nodes().List(ctx, listOptions)
+		if err != nil {
+			return "", err
+		}
+		nodes = append(nodes, nodeList.Items...)
+
+		return nodeList.Continue, nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list nodes: %w", err)
+	}
+
+	daemonSets := []appsv1.DaemonSet{}
+	err = utils.ListAllPages(metav1.ListOptions{}, func(listOptions metav1.ListOptions) (string, error) {
+		daemonSetList, err := collector.clientset.AppsV1().DaemonSets(metav1.NamespaceAll).List(ctx, listOptions)
+		if err != nil {
+			return "", err
+		}
+		daemonSets = append(daemonSets, daemonSetList.Items...)
+
+		return daemonSetList.Continue, nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list daemonsets: %w", err)
+	}
+
+	// The pods of each DaemonSet, by node, identified by their controller reference.
+	daemonSetPods := map[types.UID]map[string]*corev1.Pod{}
+	for _, daemonSet := range daemonSets {
+		daemonSetPods[daemonSet.UID] = map[string]*corev1.Pod{}
+	}
+	err = utils.ListAllPages(metav1.ListOptions{}, func(listOptions metav1.ListOptions) (string, error) {
+		podList, err := collector.clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, listOptions)
+		if err != nil {
+			return "", err
+		}
+
+		for i := range podList.Items {
+			pod := &podList.Items[i]
+			owner := metav1.GetControllerOf(pod)
+			if owner == nil || owner.Kind != "DaemonSet" || pod.DeletionTimestamp != nil {
+				continue
+			}
+			if pods, ok := daemonSetPods[owner.UID]; ok {
+				pods[getDaemonSetPodNode(pod)] = pod
+			}
+		}
+
+		return podList.Continue, nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list pods: %w", err)
+	}
+
+	report := DaemonSetCoverageReport{
+		NodeCount:  len(nodes),
+		DaemonSets: []DaemonSetCoverage{},
+	}
+
+	gapIndexes := map[string]int{}
+	for _, daemonSet := range daemonSets {
+		coverage := getDaemonSetCoverage(&daemonSet, nodes, daemonSetPods[daemonSet.UID])
+		if len(coverage.Gaps) > 0 {
+			gapIndexes[daemonSet.Namespace+"/"+daemonSet.Name] = len(report.DaemonSets)
+		}
+		report.DaemonSets = append(report.DaemonSets, coverage)
+	}
+
+	if len(gapIndexes) > 0 {
+		if err := collector.addDaemonSetEvents(ctx, report.DaemonSets, gapIndexes); err != nil {
+			return err
+		}
+	}
+
+	sort.Slice(report.DaemonSets, func(i, j int) bool {
+		return report.DaemonSets[i].Namespace+"/"+report.DaemonSets[i].Name < report.DaemonSets[j].Namespace+"/"+report.DaemonSets[j].Name
+	})
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("marshall daemonset coverage to json: %w", err)
+	}
+
+	collector.data["daemonset-coverage"] = string(data)
+
+	return nil
+}
+
+// addDaemonSetEvents adds the recent events of the DaemonSets with gaps, and the messages of the events which name a
+// node to the gaps for that node which have none.
+func (collector *DaemonSetCoverageCollector) addDaemonSetEvents(ctx context.Context, coverages []DaemonSetCoverage, gapIndexes map[string]int) error {
+	eventsByDaemonSet := map[int][]corev1.Event{}
+
+	err := utils.ListAllPages(metav1.ListOptions{FieldSelector: "involvedObject.kind=DaemonSet"}, func(listOptions metav1.ListOptions) (string, error) {
+		eventList, err := collector.clientset.CoreV1().Events(metav1.NamespaceAll).List(ctx, listOptions)
+		if err != nil {
+			return "", err
+		}
+
+		for _, event := range eventList.Items {
+			index, ok := gapIndexes[event.InvolvedObject.Namespace+"/"+event.InvolvedObject.Name]
+			if !ok || event.InvolvedObject.Kind != "DaemonSet" {
+				continue
+			}
+			eventsByDaemonSet[index] = append(eventsByDaemonSet[index], event)
+		}
+
+		return eventList.Continue, nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list daemonset events: %w", err)
+	}
+
+	for index, events := range eventsByDaemonSet {
+		sort.Slice(events, func(i, j int) bool {
+			return getEventLastTimestamp(&events[i]).After(getEventLastTimestamp(&events[j]))
+		})
+
+		coverage := &coverages[index]
+		for _, event := range events {
+			if len(coverage.Events) < daemonSetCoverageMaxEvents {
+				coverage.Events = append(coverage.Events, DaemonSetCoverageEvent{
+					Reason:        event.Reason,
+					Message:       event.Message,
+					Count:         event.Count,
+					LastTimestamp: getEventLastTimestamp(&event),
+				})
+			}
+
+			// Events are newest first, so each gap gets the most recent message for its node.
+			match := daemonSetEventNodeRegex.FindStringSubmatch(event.Message)
+			if match == nil || event.Type != corev1.EventTypeWarning {
+				continue
+			}
+			for i := range coverage.Gaps {
+				gap := &coverage.Gaps[i]
+				if gap.Node == match[1] && len(gap.Message) == 0 {
+					gap.Message = fmt.Sprintf("%s: %s", event.Reason, event.Message)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// getDaemonSetCoverage finds the nodes the DaemonSet should run on, and the reason for each of them without a ready
+// pod.
+func getDaemonSetCoverage(daemonSet *appsv1.DaemonSet, nodes []corev1.Node, pods map[string]*corev1.Pod) DaemonSetCoverage {
+	coverage := DaemonSetCoverage{
+		Namespace:              daemonSet.Namespace,
+		Name:                   daemonSet.Name,
+		DesiredNumberScheduled: daemonSet.Status.DesiredNumberScheduled,
+		CurrentNumberScheduled: daemonSet.Status.CurrentNumberScheduled,
+		NumberReady:            daemonSet.Status.NumberReady,
+		NumberMisscheduled:     daemonSet.Status.NumberMisscheduled,
+		Gaps:                   []DaemonSetNodeGap{},
+		Events:                 []DaemonSetCoverageEvent{},
+	}
+
+	podSpec := &daemonSet.Spec.Template.Spec
+	tolerations := append(getDaemonSetDefaultTolerations(podSpec), podSpec.Tolerations...)
+
+	for i := range nodes {
+		node := &nodes[i]
+		if !daemonSetNodeSelectorMatches(podSpec, node) {
+			coverage.ExcludedNodes++
+			continue
+		}
+		coverage.EligibleNodes++
+
+		if taint := getUntoleratedTaint(node, tolerations); taint != nil {
+			coverage.Gaps = append(coverage.Gaps, DaemonSetNodeGap{
+				Node:    node.Name,
+				Reason:  daemonSetGapUntoleratedTaint,
+				Message: taint.ToString(),
+			})
+			continue
+		}
+
+		pod, ok := pods[node.Name]
+		if !ok {
+			coverage.Gaps = append(coverage.Gaps, DaemonSetNodeGap{Node: node.Name, Reason: daemonSetGapNoPod})
+			continue
+		}
+
+		if gap := getDaemonSetPodGap(pod); gap != nil {
+			gap.Node = node.Name
+			coverage.Gaps = append(coverage.Gaps, *gap)
+		}
+	}
+
+	return coverage
+}
+
+// getDaemonSetPodGap returns why a pod isn't ready, or nil if it is.
+func getDaemonSetPodGap(pod *corev1.Pod) *DaemonSetNodeGap {
+	for _, condition := range pod.Status.Conditions {
+		switch {
+		case condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue:
+			return nil
+		case condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse:
+			// e.g. "0/1 nodes are available: 1 Insufficient cpu."
+			return &DaemonSetNodeGap{Reason: daemonSetGapPodUnschedulable, Message: condition.Message, Pod: pod.Name}
+		}
+	}
+
+	message := string(pod.Status.Phase)
+	for _, status := range append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+		if status.State.Waiting != nil && len(status.State.Waiting.Reason) > 0 {
+			message = fmt.Sprintf("%s: container %s is waiting: %s", pod.Status.Phase, status.Name, status.State.Waiting.Reason)
+			break
+		}
+	}
+
+	return &DaemonSetNodeGap{Reason: daemonSetGapPodNotReady, Message: message, Pod: pod.Name}
+}
+
+// getDaemonSetPodNode returns the node a DaemonSet pod is for. Pods which aren't scheduled yet are pinned to their
+// node by a required node affinity on the node name.
+func getDaemonSetPodNode(pod *corev1.Pod) string {
+	if len(pod.Spec.NodeName) > 0 {
+		return pod.Spec.NodeName
+	}
+
+	if affinity := pod.Spec.Affinity; affinity != nil && affinity.NodeAffinity != nil && affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+			for _, field := range term.MatchFields {
+				if field.Key == "metadata.name" && field.Operator == corev1.NodeSelectorOpIn && len(field.Values) == 1 {
+					return field.Values[0]
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+// getDaemonSetDefaultTolerations returns the tolerations the DaemonSet controller adds to every pod, so that they run
+// on nodes which are cordoned or under pressure.
+// See: https://kubernetes.io/docs/concepts/workloads/controllers/daemonset/#taints-and-tolerations
+func getDaemonSetDefaultTolerations(podSpec *corev1.PodSpec) []corev1.Toleration {
+	tolerations := []corev1.Toleration{
+		{Key: "node.kubernetes.io/not-ready", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+		{Key: "node.kubernetes.io/unreachable", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+		{Key: "node.kubernetes.io/disk-pressure", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+		{Key: "node.kubernetes.io/memory-pressure", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+		{Key: "node.kubernetes.io/pid-pressure", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+		{Key: "node.kubernetes.io/unschedulable", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	}
+	if podSpec.HostNetwork {
+		tolerations = append(tolerations, corev1.Toleration{Key: "node.kubernetes.io/network-unavailable", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule})
+	}
+	return tolerations
+}
+
+// getUntoleratedTaint returns the first taint which prevents pods with the tolerations from being scheduled on the
+// node, or nil if there is none.
+func getUntoleratedTaint(node *corev1.Node, tolerations []corev1.Toleration) *corev1.Taint {
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+
+		tolerated := false
+		for j := range tolerations {
+			if tolerations[j].ToleratesTaint(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return taint
+		}
+	}
+
+	return nil
+}
+
+// daemonSetNodeSelectorMatches returns whether the node matches the node selector and required node affinity of a pod.
+func daemonSetNodeSelectorMatches(podSpec *corev1.PodSpec, node *corev1.Node) bool {
+	for key, value := range podSpec.NodeSelector {
+		if node.Labels[key] != value {
+			return false
+		}
+	}
+
+	affinity := podSpec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true
+	}
+
+	// The terms are ORed, and the requirements of each term are ANDed.
+	for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			continue
+		}
+
+		matches := true
+		for _, requirement := range term.MatchExpressions {
+			value, exists := node.Labels[requirement.Key]
+			matches = matches && nodeSelectorRequirementMatches(requirement, value, exists)
+		}
+		for _, requirement := range term.MatchFields {
+			// metadata.name is the only supported field.
+			matches = matches && requirement.Key == "metadata.name" && nodeSelectorRequirementMatches(requirement, node.Name, true)
+		}
+		if matches {
+			return true
+		}
+	}
+
+	return false
+}
+
+func nodeSelectorRequirementMatches(requirement corev1.NodeSelectorRequirement, value string, exists bool) bool {
+	switch requirement.Operator {
+	case corev1.NodeSelectorOpIn:
+		return exists && utils.Contains(requirement.Values, value)
+	case corev1.NodeSelectorOpNotIn:
+		return !exists || !utils.Contains(requirement.Values, value)
+	case corev1.NodeSelectorOpExists:
+		return exists
+	case corev1.NodeSelectorOpDoesNotExist:
+		return !exists
+	case corev1.NodeSelectorOpGt, corev1.NodeSelectorOpLt:
+		if !exists || len(requirement.Values) != 1 {
+			return false
+		}
+		actual, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return false
+		}
+		expected, err := strconv.ParseInt(requirement.Values[0], 10, 64)
+		if err != nil {
+			return false
+		}
+		if requirement.Operator == corev1.NodeSelectorOpGt {
+			return actual > expected
+		}
+		return actual < expected
+	default:
+		return false
+	}
+}
+
+func (collector *DaemonSetCoverageCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
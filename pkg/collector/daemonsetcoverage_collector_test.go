@@ -0,0 +1,218 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestDaemonSetCoverageCollectorGetName(t *testing.T) {
+	const expectedName = "daemonsetcoverage"
+
+	c := NewDaemonSetCoverageCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestDaemonSetCoverageCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewDaemonSetCoverageCollector(nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestDaemonSetCoverageCollectorCollect(t *testing.T) {
+	newNode := func(name, pool string, taints ...corev1.Taint) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"agentpool": pool, "kubernetes.io/os": "linux"}},
+			Spec:       corev1.NodeSpec{Taints: taints},
+		}
+	}
+
+	newDaemonSet := func(name string, uid types.UID, podSpec corev1.PodSpec, desired, ready int32) *appsv1.DaemonSet {
+		return &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kube-system", UID: uid},
+			Spec:       appsv1.DaemonSetSpec{Template: corev1.PodTemplateSpec{Spec: podSpec}},
+			Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: desired, CurrentNumberScheduled: desired, NumberReady: ready},
+		}
+	}
+
+	isController := true
+	newPod := func(name, nodeName, daemonSetName string, daemonSetUID types.UID, ready bool) *corev1.Pod {
+		status := corev1.ConditionFalse
+		if ready {
+			status = corev1.ConditionTrue
+		}
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       "kube-system",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "DaemonSet", Name: daemonSetName, UID: daemonSetUID, Controller: &isController}},
+			},
+			Spec: corev1.PodSpec{NodeName: nodeName},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+			},
+		}
+	}
+
+	cordoned := corev1.Taint{Key: "node.kubernetes.io/unschedulable", Effect: corev1.TaintEffectNoSchedule}
+	criticalAddonsOnly := corev1.Taint{Key: "CriticalAddonsOnly", Value: "true", Effect: corev1.TaintEffectNoSchedule}
+
+	// An unscheduled pod is pinned to its node by the DaemonSet controller.
+	pendingPod := newPod("agent-pending", "", "agent", "agent-uid", false)
+	pendingPod.Spec.Affinity = &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+			MatchFields: []corev1.NodeSelectorRequirement{{Key: "metadata.name", Operator: corev1.NodeSelectorOpIn, Values: []string{"user-2"}}},
+		}}},
+	}}
+	pendingPod.Status = corev1.PodStatus{
+		Phase:      corev1.PodPending,
+		Conditions: []corev1.PodCondition{{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Message: "0/4 nodes are available: 1 Insufficient cpu."}},
+	}
+
+	// The user pool DaemonSet only runs on nodes of the user pools.
+	userPoolAffinity := &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+			MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "agentpool", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"system"}}},
+		}}},
+	}}
+
+	objects := []runtime.Object{
+		newNode("system-0", "system", criticalAddonsOnly),
+		newNode("user-0", "user"),
+		newNode("user-1", "user", cordoned),
+		newNode("user-2", "user"),
+		newDaemonSet("agent", "agent-uid", corev1.PodSpec{NodeSelector: map[string]string{"kubernetes.io/os": "linux"}}, 3, 1),
+		newDaemonSet("user-agent", "user-agent-uid", corev1.PodSpec{Affinity: userPoolAffinity}, 3, 3),
+		newPod("agent-0", "user-0", "agent", "agent-uid", true),
+		newPod("agent-1", "user-1", "agent", "agent-uid", false),
+		pendingPod,
+		newPod("user-agent-0", "user-0", "user-agent", "user-agent-uid", true),
+		newPod("user-agent-1", "user-1", "user-agent", "user-agent-uid", true),
+		newPod("user-agent-2", "user-2", "user-agent", "user-agent-uid", true),
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "agent.1", Namespace: "kube-system"},
+			InvolvedObject: corev1.ObjectReference{Kind: "DaemonSet", Namespace: "kube-system", Name: "agent"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "FailedDaemonPod",
+			Message:        "Found failed daemon pod kube-system/agent-old on node user-1, will try to kill it",
+			Count:          3,
+		},
+	}
+	clientset := fake.NewSimpleClientset(objects...)
+
+	// The fake clientset doesn't paginate, so check that the DaemonSets are listed over two pages.
+	daemonSetListCalls := 0
+	clientset.PrependReactor("list", "daemonsets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		daemonSetListCalls++
+		if daemonSetListCalls == 1 {
+			return true, &appsv1.DaemonSetList{ListMeta: metav1.ListMeta{Continue: "page-2"}, Items: []appsv1.DaemonSet{*objects[5].(*appsv1.DaemonSet)}}, nil
+		}
+		return true, &appsv1.DaemonSetList{Items: []appsv1.DaemonSet{*objects[4].(*appsv1.DaemonSet)}}, nil
+	})
+
+	c := NewDaemonSetCoverageCollector(clientset, &utils.RuntimeInfo{})
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	if daemonSetListCalls != 2 {
+		t.Errorf("expected 2 daemonset list calls, found %d", daemonSetListCalls)
+	}
+
+	testDataValue(t, c.GetData()["daemonset-coverage"], func(raw string) {
+		var report DaemonSetCoverageReport
+		if err := json.Unmarshal([]byte(raw), &report); err != nil {
+			t.Fatalf("unmarshal GetData(): %v", err)
+		}
+
+		if report.NodeCount != 4 || len(report.DaemonSets) != 2 {
+			t.Fatalf("unexpected report: %+v", report)
+		}
+
+		agent := report.DaemonSets[0]
+		if agent.Name != "agent" || agent.EligibleNodes != 4 || agent.ExcludedNodes != 0 || agent.NumberReady != 1 {
+			t.Errorf("unexpected agent coverage: %+v", agent)
+		}
+		expectedGaps := []DaemonSetNodeGap{
+			{Node: "system-0", Reason: daemonSetGapUntoleratedTaint, Message: "CriticalAddonsOnly=true:NoSchedule"},
+			{Node: "user-1", Reason: daemonSetGapPodNotReady, Message: "Running", Pod: "agent-1"},
+			{Node: "user-2", Reason: daemonSetGapPodUnschedulable, Message: "0/4 nodes are available: 1 Insufficient cpu.", Pod: "agent-pending"},
+		}
+		if !reflect.DeepEqual(agent.Gaps, expectedGaps) {
+			t.Errorf("unexpected agent gaps:\nexpected %+v\nfound    %+v", expectedGaps, agent.Gaps)
+		}
+		if len(agent.Events) != 1 || agent.Events[0].Reason != "FailedDaemonPod" {
+			t.Errorf("unexpected agent events: %+v", agent.Events)
+		}
+
+		// Nodes excluded by the affinity aren't gaps, and the cordoned node is tolerated by default.
+		userAgent := report.DaemonSets[1]
+		if userAgent.Name != "user-agent" || userAgent.EligibleNodes != 3 || userAgent.ExcludedNodes != 1 || len(userAgent.Gaps) != 0 || len(userAgent.Events) != 0 {
+			t.Errorf("unexpected user-agent coverage: %+v", userAgent)
+		}
+	})
+}
+
+func TestDaemonSetCoverageEventNode(t *testing.T) {
+	c := NewDaemonSetCoverageCollector(nil, &utils.RuntimeInfo{})
+	coverages := []DaemonSetCoverage{{
+		Namespace: "kube-system",
+		Name:      "agent",
+		Gaps:      []DaemonSetNodeGap{{Node: "node-1", Reason: daemonSetGapNoPod}, {Node: "node-10", Reason: daemonSetGapNoPod}},
+		Events:    []DaemonSetCoverageEvent{},
+	}}
+
+	c.clientset = fake.NewSimpleClientset(&corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "agent.1", Namespace: "kube-system"},
+		InvolvedObject: corev1.ObjectReference{Kind: "DaemonSet", Namespace: "kube-system", Name: "agent"},
+		Type:           corev1.EventTypeWarning,
+		Reason:         "FailedPlacement",
+		Message:        `failed to place pod on "node-10": Node didn't have enough resource: cpu`,
+	})
+
+	if err := c.addDaemonSetEvents(context.Background(), coverages, map[string]int{"kube-system/agent": 0}); err != nil {
+		t.Fatalf("addDaemonSetEvents() error = %v", err)
+	}
+
+	if gaps := coverages[0].Gaps; len(gaps[0].Message) > 0 || gaps[1].Message != `FailedPlacement: failed to place pod on "node-10": Node didn't have enough resource: cpu` {
+		t.Errorf("unexpected gaps: %+v", gaps)
+	}
+}
@@ -0,0 +1,89 @@
+package collector
+
+import (
+	"sync"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+)
+
+// DependencyTracker records the completion of each collector in a run, so that collectors with dependencies can wait
+// for them and receive their data. Only dependency edges force an ordering: other collectors run concurrently.
+type DependencyTracker struct {
+	done      map[string]chan struct{}
+	completed map[string]interfaces.Collector
+	lock      sync.Mutex
+}
+
+// NewDependencyTracker is a constructor. Collectors not named here are treated as already complete, without output.
+func NewDependencyTracker(names []string) *DependencyTracker {
+	tracker := &DependencyTracker{
+		done:      map[string]chan struct{}{},
+		completed: map[string]interfaces.Collector{},
+	}
+	for _, name := range names {
+		tracker.done[name] = make(chan struct{})
+	}
+
+	return tracker
+}
+
+// Complete records that a collector has finished, releasing any collectors waiting for it. The collector is nil if
+// its output can't be used, because it is unsupported, failed or timed out. Only the first call for each collector
+// has an effect.
+func (tracker *DependencyTracker) Complete(name string, c interfaces.Collector) {
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+
+	done, ok := tracker.done[name]
+	if !ok {
+		return
+	}
+
+	select {
+	case <-done:
+		return
+	default:
+	}
+
+	if c != nil {
+		tracker.completed[name] = c
+	}
+	close(done)
+}
+
+// Wait blocks until the named collectors have completed, and returns those which completed successfully.
+func (tracker *DependencyTracker) Wait(names []string) map[string]interfaces.Collector {
+	for _, name := range names {
+		if done, ok := tracker.done[name]; ok {
+			<-done
+		}
+	}
+
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+
+	result := map[string]interfaces.Collector{}
+	for _, name := range names {
+		if c, ok := tracker.completed[name]; ok {
+			result[name] = c
+		}
+	}
+
+	return result
+}
+
+// SetDependencyData waits for the dependencies of a collector and, if it uses their output, passes it in.
+func (tracker *DependencyTracker) SetDependencyData(c interfaces.Collector, dependencies []string) {
+	completed := tracker.Wait(dependencies)
+
+	dependent, ok := c.(interfaces.DependentCollector)
+	if !ok {
+		return
+	}
+
+	for _, name := range dependencies {
+		if dependency, ok := completed[name]; ok {
+			dependent.SetDependencyData(name, dependency.GetData())
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package collector
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+// summaryCollector is a DependentCollector which records the data passed in for each dependency.
+type summaryCollector struct {
+	dependencyData map[string]map[string]interfaces.DataValue
+}
+
+func (collector *summaryCollector) GetName() string       { return "summary" }
+func (collector *summaryCollector) CheckSupported() error { return nil }
+func (collector *summaryCollector) Collect() error        { return nil }
+func (collector *summaryCollector) GetData() map[string]interfaces.DataValue {
+	return map[string]interfaces.DataValue{}
+}
+func (collector *summaryCollector) SetDependencyData(name string, data map[string]interfaces.DataValue) {
+	collector.dependencyData[name] = data
+}
+
+func TestDependencyTrackerSetDependencyData(t *testing.T) {
+	tracker := NewDependencyTracker([]string{"nodeconditions", "dmesg", "summary"})
+
+	nodeConditions := NewNodeConditionsCollector(nil, nil)
+	nodeConditions.data["nodeconditions"] = "{}"
+
+	summary := &summaryCollector{dependencyData: map[string]map[string]interfaces.DataValue{}}
+	waited := make(chan struct{})
+	go func() {
+		defer close(waited)
+		// azurecni isn't part of the run, so isn't waited for.
+		tracker.SetDependencyData(summary, []string{"nodeconditions", "dmesg", "azurecni"})
+	}()
+
+	tracker.Complete("nodeconditions", nodeConditions)
+	select {
+	case <-waited:
+		t.Fatalf("expected to wait for dmesg")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// A failed collector releases its dependents without passing in its data, and later calls have no effect.
+	tracker.Complete("dmesg", nil)
+	tracker.Complete("dmesg", NewDmesgCollector(utils.Linux, nil, nil, nil, nil))
+	select {
+	case <-waited:
+	case <-time.After(time.Second):
+		t.Fatalf("expected dependencies to be complete")
+	}
+
+	if !reflect.DeepEqual(summary.dependencyData, map[string]map[string]interfaces.DataValue{"nodeconditions": nodeConditions.GetData()}) {
+		t.Errorf("unexpected dependency data %v", summary.dependencyData)
+	}
+}
@@ -0,0 +1,156 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+type DeprecationReport struct {
+	// Deprecated counts the APIs for which the API server returned a deprecation warning.
+	Deprecated int             `json:"deprecated"`
+	APIs       []DeprecatedAPI `json:"apis"`
+}
+
+// DeprecatedAPI is the result of listing a resource through an API version which is known to be deprecated. Whether
+// any objects exist is reported rather than how many, since only one is listed.
+type DeprecatedAPI struct {
+	APIVersion string   `json:"apiVersion"`
+	Resource   string   `json:"resource"`
+	Served     bool     `json:"served"`
+	HasObjects bool     `json:"hasObjects"`
+	Warnings   []string `json:"warnings"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// deprecationWarningRecorder is a rest.WarningHandler which keeps the warnings returned by the API server, so that
+// they can be attributed to the request which returned them. Requests are made one at a time.
+type deprecationWarningRecorder struct {
+	lock     sync.Mutex
+	warnings []string
+}
+
+// HandleWarningHeader implements the rest.WarningHandler interface. Only warnings with code 299 (miscellaneous
+// persistent warning), which the API server uses for deprecations, are recorded.
+func (recorder *deprecationWarningRecorder) HandleWarningHeader(code int, agent string, text string) {
+	if code != 299 || len(text) == 0 {
+		return
+	}
+
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	if !utils.Contains(recorder.warnings, text) {
+		recorder.warnings = append(recorder.warnings, text)
+	}
+}
+
+// take returns the warnings recorded since it was last called.
+func (recorder *deprecationWarningRecorder) take() []string {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	warnings := recorder.warnings
+	recorder.warnings = nil
+	if warnings == nil {
+		return []string{}
+	}
+	return warnings
+}
+
+// DeprecationCollector defines a Deprecation Collector struct
+type DeprecationCollector struct {
+	data        map[string]string
+	kubeconfig  *rest.Config
+	runtimeInfo *utils.RuntimeInfo
+}
+
+// NewDeprecationCollector is a constructor
+func NewDeprecationCollector(config *rest.Config, runtimeInfo *utils.RuntimeInfo) *DeprecationCollector {
+	return &DeprecationCollector{
+		data:        make(map[string]string),
+		kubeconfig:  config,
+		runtimeInfo: runtimeInfo,
+	}
+}
+
+func (collector *DeprecationCollector) GetName() string {
+	return "deprecation"
+}
+
+func (collector *DeprecationCollector) CheckSupported() error {
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *DeprecationCollector) Collect() error {
+	ctx := context.Background()
+
+	// The warnings are only available to the warning handler of the client which made the request, so this has a
+	// client of its own rather than sharing one with other collectors.
+	recorder := &deprecationWarningRecorder{}
+	config := rest.CopyConfig(collector.kubeconfig)
+	config.WarningHandler = recorder
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("cannot create dynamic client: %w", err)
+	}
+
+	report := DeprecationReport{APIs: []DeprecatedAPI{}}
+	for _, gvr := range collector.runtimeInfo.DeprecatedResources {
+		api := DeprecatedAPI{
+			APIVersion: gvr.GroupVersion().String(),
+			Resource:   gvr.Resource,
+			Served:     true,
+		}
+
+		var list *unstructured.UnstructuredList
+		err := utils.RetryAPICall(func() (err error) {
+			list, err = dynamicClient.Resource(gvr).Namespace(metav1.NamespaceAll).List(ctx, metav1.ListOptions{Limit: 1})
+			return err
+		})
+		api.Warnings = recorder.take()
+
+		switch {
+		case apierrors.IsNotFound(err):
+			api.Served = false
+		case err != nil:
+			api.Error = err.Error()
+		default:
+			api.HasObjects = len(list.Items) > 0
+		}
+
+		if len(api.Warnings) > 0 {
+			report.Deprecated++
+		}
+		report.APIs = append(report.APIs, api)
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("marshall deprecations to json: %w", err)
+	}
+
+	collector.data["deprecations"] = string(data)
+
+	return nil
+}
+
+func (collector *DeprecationCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
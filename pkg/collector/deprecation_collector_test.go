@@ -0,0 +1,119 @@
+package collector
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+)
+
+func TestDeprecationCollectorGetName(t *testing.T) {
+	const expectedName = "deprecation"
+
+	c := NewDeprecationCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestDeprecationCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewDeprecationCollector(nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestDeprecationCollectorCollect(t *testing.T) {
+	const cronJobWarning = "batch/v1beta1 CronJob is deprecated in v1.21+, unavailable in v1.25+; use batch/v1 CronJob"
+	const componentStatusWarning = "v1 ComponentStatus is deprecated in v1.19+"
+
+	requests := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path+"?"+r.URL.RawQuery)
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/apis/batch/v1beta1/cronjobs":
+			w.Header().Add("Warning", `299 - "`+cronJobWarning+`"`)
+			w.Write([]byte(`{"kind":"CronJobList","apiVersion":"batch/v1beta1","metadata":{"continue":"next"},"items":[{"kind":"CronJob","apiVersion":"batch/v1beta1","metadata":{"name":"nightly","namespace":"default"}}]}`))
+		case "/api/v1/componentstatuses":
+			w.Header().Add("Warning", `299 - "`+componentStatusWarning+`"`)
+			w.Write([]byte(`{"kind":"ComponentStatusList","apiVersion":"v1","metadata":{},"items":[]}`))
+		case "/apis/policy/v1beta1/poddisruptionbudgets":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Forbidden","code":403,"message":"poddisruptionbudgets.policy is forbidden"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404,"message":"the server could not find the requested resource"}`))
+		}
+	}))
+	defer server.Close()
+
+	runtimeInfo := &utils.RuntimeInfo{
+		DeprecatedResources: []schema.GroupVersionResource{
+			{Group: "batch", Version: "v1beta1", Resource: "cronjobs"},
+			{Version: "v1", Resource: "componentstatuses"},
+			{Group: "policy", Version: "v1beta1", Resource: "poddisruptionbudgets"},
+			{Group: "policy", Version: "v1beta1", Resource: "podsecuritypolicies"},
+		},
+	}
+
+	c := NewDeprecationCollector(&rest.Config{Host: server.URL}, runtimeInfo)
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	if requests[0] != "/apis/batch/v1beta1/cronjobs?limit=1" {
+		t.Errorf("unexpected first request %s", requests[0])
+	}
+
+	testDataValue(t, c.GetData()["deprecations"], func(raw string) {
+		var report DeprecationReport
+		if err := json.Unmarshal([]byte(raw), &report); err != nil {
+			t.Fatalf("unmarshal GetData(): %v", err)
+		}
+
+		if report.Deprecated != 2 || len(report.APIs) != 4 {
+			t.Fatalf("unexpected report: %+v", report)
+		}
+
+		expected := []DeprecatedAPI{
+			{APIVersion: "batch/v1beta1", Resource: "cronjobs", Served: true, HasObjects: true, Warnings: []string{cronJobWarning}},
+			{APIVersion: "v1", Resource: "componentstatuses", Served: true, Warnings: []string{componentStatusWarning}},
+			{APIVersion: "policy/v1beta1", Resource: "poddisruptionbudgets", Served: true, Warnings: []string{}, Error: "poddisruptionbudgets.policy is forbidden"},
+			{APIVersion: "policy/v1beta1", Resource: "podsecuritypolicies", Served: false, Warnings: []string{}},
+		}
+		if !reflect.DeepEqual(report.APIs, expected) {
+			t.Errorf("unexpected APIs:\nexpected %+v\nfound    %+v", expected, report.APIs)
+		}
+	})
+}
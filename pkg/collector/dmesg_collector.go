@@ -0,0 +1,122 @@
+package collector
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+// Matches the kernel timestamp (seconds since boot) at the start of a dmesg line, e.g. "[ 1234.567890] ..."
+var dmesgTimestampRegex = regexp.MustCompile(`^\[\s*(\d+\.\d+)\]`)
+
+// DmesgCollector defines a Dmesg Collector struct
+type DmesgCollector struct {
+	data         map[string]string
+	osIdentifier utils.OSIdentifier
+	filePaths    *utils.KnownFilePaths
+	fileSystem   interfaces.FileSystemAccessor
+	runCommand   utils.HostCommandRunner
+	runtimeInfo  *utils.RuntimeInfo
+}
+
+// NewDmesgCollector is a constructor
+func NewDmesgCollector(osIdentifier utils.OSIdentifier, filePaths *utils.KnownFilePaths, fileSystem interfaces.FileSystemAccessor, runCommand utils.HostCommandRunner, runtimeInfo *utils.RuntimeInfo) *DmesgCollector {
+	return &DmesgCollector{
+		data:         make(map[string]string),
+		osIdentifier: osIdentifier,
+		filePaths:    filePaths,
+		fileSystem:   fileSystem,
+		runCommand:   runCommand,
+		runtimeInfo:  runtimeInfo,
+	}
+}
+
+func (collector *DmesgCollector) GetName() string {
+	return "dmesg"
+}
+
+func (collector *DmesgCollector) CheckSupported() error {
+	// The kernel ring buffer is a Linux concept.
+	if collector.osIdentifier != utils.Linux {
+		return fmt.Errorf("unsupported OS: %s", collector.osIdentifier)
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *DmesgCollector) Collect() error {
+	output, err := collector.runCommand("dmesg")
+	if err != nil {
+		// When kernel.dmesg_restrict is set, reading the ring buffer fails rather than returning nothing.
+		if strings.Contains(err.Error(), "Operation not permitted") {
+			return fmt.Errorf("unable to read the kernel ring buffer: the container requires the CAP_SYSLOG capability: %w", err)
+		}
+		return fmt.Errorf("error running dmesg: %w", err)
+	}
+
+	if collector.runtimeInfo.DmesgSince > 0 {
+		uptime, err := collector.getUptime()
+		if err != nil {
+			return err
+		}
+
+		output = filterDmesgSince(output, uptime-collector.runtimeInfo.DmesgSince)
+	}
+
+	collector.data["dmesg"] = output
+
+	return nil
+}
+
+// getUptime reads the time since boot, which is the reference point for the kernel timestamps.
+func (collector *DmesgCollector) getUptime() (time.Duration, error) {
+	uptimePath := path.Join(collector.filePaths.Proc, "uptime")
+	content, err := utils.GetContent(func() (io.ReadCloser, error) { return collector.fileSystem.GetFileReader(uptimePath) })
+	if err != nil {
+		return 0, fmt.Errorf("error reading %s: %w", uptimePath, err)
+	}
+
+	// The first value is the uptime in seconds, the second is the idle time.
+	fields := strings.Fields(content)
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("unexpected content in %s: %s", uptimePath, content)
+	}
+
+	seconds, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected uptime in %s: %w", uptimePath, err)
+	}
+
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// filterDmesgSince returns the dmesg lines logged at or after the specified time since boot.
+// Lines without a timestamp (continuations of multi-line messages) follow the preceding line.
+func filterDmesgSince(output string, since time.Duration) string {
+	var sb strings.Builder
+	include := false
+	for _, line := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
+		if matches := dmesgTimestampRegex.FindStringSubmatch(line); matches != nil {
+			seconds, err := strconv.ParseFloat(matches[1], 64)
+			include = err != nil || time.Duration(seconds*float64(time.Second)) >= since
+		}
+
+		if include {
+			sb.WriteString(line)
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+func (collector *DmesgCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
@@ -0,0 +1,116 @@
+package collector
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+func TestDmesgCollectorGetName(t *testing.T) {
+	const expectedName = "dmesg"
+
+	c := NewDmesgCollector("", nil, nil, nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestDmesgCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		osIdentifier utils.OSIdentifier
+		wantErr      bool
+	}{
+		{
+			osIdentifier: utils.Windows,
+			wantErr:      true,
+		},
+		{
+			osIdentifier: utils.Linux,
+			wantErr:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		c := NewDmesgCollector(tt.osIdentifier, nil, nil, nil, nil)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("CheckSupported() error = %v, wantErr %v", err, tt.wantErr)
+		}
+	}
+}
+
+func TestDmesgCollectorCollect(t *testing.T) {
+	const dmesg = `[    0.000000] Linux version 5.15.0-1041-azure (buildd@lcy02-amd64-079)
+[ 3000.123456] hv_netvsc 000d3a00-0000-0000-0000-000000000000 eth0: VF slot 1 removed
+[ 3500.000000] Memory cgroup out of memory: Killed process 4321 (java) total-vm:4194304kB, anon-rss:2097152kB
+[ 3500.000001]  oom_reaper: reaped process 4321 (java)
+`
+
+	filePaths := &utils.KnownFilePaths{Proc: "/proc"}
+	fs := test.NewFakeFileSystem(map[string]string{"/proc/uptime": "3700.00 7000.00\n"})
+
+	tests := []struct {
+		name       string
+		since      time.Duration
+		commandErr error
+		wantErr    *regexp.Regexp
+		wantData   map[string]*regexp.Regexp
+	}{
+		{
+			name:  "whole ring buffer",
+			since: 0,
+			wantData: map[string]*regexp.Regexp{
+				"dmesg": regexp.MustCompile(`^\[    0\.000000\] Linux version[^\n]*\n\[ 3000\.123456\][^\n]*\n\[ 3500\.000000\][^\n]*\n\[ 3500\.000001\][^\n]*\n$`),
+			},
+		},
+		{
+			name:  "last 10 minutes",
+			since: 10 * time.Minute,
+			wantData: map[string]*regexp.Regexp{
+				"dmesg": regexp.MustCompile(`^\[ 3500\.000000\] Memory cgroup out of memory[^\n]*\n\[ 3500\.000001\]  oom_reaper[^\n]*\n$`),
+			},
+		},
+		{
+			name:       "missing CAP_SYSLOG",
+			commandErr: errors.New("fail to run command on host: exit status 1: dmesg: read kernel buffer failed: Operation not permitted"),
+			wantErr:    regexp.MustCompile(`CAP_SYSLOG`),
+			wantData:   map[string]*regexp.Regexp{},
+		},
+		{
+			name:       "dmesg failure",
+			commandErr: errors.New("fail to run command on host: exit status 127: dmesg: not found"),
+			wantErr:    regexp.MustCompile(`error running dmesg`),
+			wantData:   map[string]*regexp.Regexp{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runCommand := func(command string, arg ...string) (string, error) {
+				if tt.commandErr != nil {
+					return "", tt.commandErr
+				}
+				return dmesg, nil
+			}
+			runtimeInfo := &utils.RuntimeInfo{
+				DmesgSince: tt.since,
+			}
+
+			c := NewDmesgCollector(utils.Linux, filePaths, fs, runCommand, runtimeInfo)
+			err := c.Collect()
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("Collect() error = %v", err)
+			}
+			if tt.wantErr != nil && (err == nil || !tt.wantErr.MatchString(err.Error())) {
+				t.Errorf("Collect() error = %v, expected match for %s", err, tt.wantErr)
+			}
+
+			compareCollectorData(t, tt.wantData, c.GetData())
+		})
+	}
+}
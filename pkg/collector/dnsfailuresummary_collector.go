@@ -0,0 +1,275 @@
+package collector
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// Maximum number of pod/query combinations reported as top offenders.
+	dnsFailureTopOffenders = 20
+
+	// The queries logged by CoreDNS within this window are summarized.
+	dnsFailureWindow = time.Hour
+
+	// The container of the CoreDNS pods which logs the queries.
+	coreDNSContainerName = "coredns"
+)
+
+// Response codes counted as failures, normalized to upper case (tracers report e.g. "NXDomain" or "NXDOMAIN").
+var dnsFailureResponseCodes = []string{"NXDOMAIN", "SERVFAIL"}
+
+// coreDNSQueryLogRegex matches the line logged by the CoreDNS log plugin for each response, in its default format, e.g.
+//
+//	[INFO] 10.244.0.5:43127 - 12345 "A IN api.example.com. udp 33 false 512" NXDOMAIN qr,rd,ra 108 0.000127s
+//
+// capturing the client address (in brackets if IPv6), the query type and name, and the response code.
+var coreDNSQueryLogRegex = regexp.MustCompile(`^\[INFO\] (\S+):\d+ - \d+ "(\S+) \S+ (\S+) [^"]*" (\S+) `)
+
+// DNSTraceEvent is a single DNS packet observed by a DNS tracer, such as Inspektor Gadget's `trace dns` gadget, or a
+// response logged by CoreDNS.
+type DNSTraceEvent struct {
+	Namespace    string `json:"namespace"`
+	Pod          string `json:"pod"`
+	QR           string `json:"qr"`
+	Name         string `json:"name"`
+	QType        string `json:"qtype"`
+	ResponseCode string `json:"rcode"`
+}
+
+// DNSTraceEventSource returns the DNS trace events captured over the collection window.
+type DNSTraceEventSource func() ([]DNSTraceEvent, error)
+
+// NewCoreDNSQueryLogSource returns a DNSTraceEventSource of the responses logged by the CoreDNS pods within the window,
+// attributed to the pods with the client addresses. CoreDNS only logs queries if its log plugin is enabled, such as
+// with a log.override in the coredns-custom ConfigMap of AKS, so there are otherwise no events. Responses to clients
+// which aren't pods, or are pods on the host network, are attributed to the client address.
+func NewCoreDNSQueryLogSource(clientset kubernetes.Interface) DNSTraceEventSource {
+	return func() ([]DNSTraceEvent, error) {
+		ctx := context.Background()
+
+		var coreDNSPods *corev1.PodList
+		err := utils.RetryAPICall(func() (err error) {
+			coreDNSPods, err = clientset.CoreV1().Pods(metav1.NamespaceSystem).List(ctx, metav1.ListOptions{LabelSelector: coreDNSPodSelector})
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to list CoreDNS pods: %w", err)
+		}
+
+		podsByIP := map[string]*corev1.Pod{}
+		err = utils.ListAllPages(metav1.ListOptions{}, func(listOptions metav1.ListOptions) (string, error) {
+			podList, err := clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, listOptions)
+			if err != nil {
+				return "", err
+			}
+
+			for i := range podList.Items {
+				pod := &podList.Items[i]
+				if pod.Spec.HostNetwork {
+					continue
+				}
+				for _, podIP := range pod.Status.PodIPs {
+					podsByIP[podIP.IP] = pod
+				}
+			}
+
+			return podList.Continue, nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to list pods: %w", err)
+		}
+
+		events := []DNSTraceEvent{}
+		sinceSeconds := int64(dnsFailureWindow.Seconds())
+		for _, pod := range coreDNSPods.Items {
+			if pod.Status.Phase != corev1.PodRunning {
+				continue
+			}
+
+			// The logs of one unavailable replica don't prevent the summary of the others.
+			podEvents, err := readCoreDNSQueryLog(ctx, clientset, &pod, sinceSeconds, podsByIP)
+			if err != nil {
+				log.Printf("Unable to read the query log of CoreDNS pod %s: %v", pod.Name, err)
+				continue
+			}
+			events = append(events, podEvents...)
+		}
+
+		return events, nil
+	}
+}
+
+func readCoreDNSQueryLog(ctx context.Context, clientset kubernetes.Interface, pod *corev1.Pod, sinceSeconds int64, podsByIP map[string]*corev1.Pod) ([]DNSTraceEvent, error) {
+	stream, err := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container:    coreDNSContainerName,
+		SinceSeconds: &sinceSeconds,
+	}).Stream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	return parseCoreDNSQueryLog(stream, podsByIP)
+}
+
+// parseCoreDNSQueryLog returns a response event for each query logged by CoreDNS, skipping the other lines it logs.
+func parseCoreDNSQueryLog(reader io.Reader, podsByIP map[string]*corev1.Pod) ([]DNSTraceEvent, error) {
+	events := []DNSTraceEvent{}
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		match := coreDNSQueryLogRegex.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+
+		event := DNSTraceEvent{QR: "R", QType: match[2], Name: match[3], ResponseCode: match[4]}
+		client := strings.Trim(match[1], "[]")
+		if pod, ok := podsByIP[client]; ok {
+			event.Namespace, event.Pod = pod.Namespace, pod.Name
+		} else {
+			event.Pod = client
+		}
+		events = append(events, event)
+	}
+
+	return events, scanner.Err()
+}
+
+type DNSFailureSummary struct {
+	TotalResponses int                  `json:"totalResponses"`
+	NXDomainCount  int                  `json:"nxdomainCount"`
+	ServFailCount  int                  `json:"servfailCount"`
+	TopOffenders   []DNSFailureOffender `json:"topOffenders"`
+}
+
+type DNSFailureOffender struct {
+	Namespace     string `json:"namespace"`
+	Pod           string `json:"pod"`
+	Name          string `json:"name"`
+	NXDomainCount int    `json:"nxdomainCount"`
+	ServFailCount int    `json:"servfailCount"`
+	Total         int    `json:"total"`
+}
+
+// DNSFailureSummaryCollector defines a DNS Failure Summary Collector struct
+type DNSFailureSummaryCollector struct {
+	data         map[string]string
+	osIdentifier utils.OSIdentifier
+	getEvents    DNSTraceEventSource
+}
+
+// NewDNSFailureSummaryCollector is a constructor
+func NewDNSFailureSummaryCollector(osIdentifier utils.OSIdentifier, getEvents DNSTraceEventSource) *DNSFailureSummaryCollector {
+	return &DNSFailureSummaryCollector{
+		data:         make(map[string]string),
+		osIdentifier: osIdentifier,
+		getEvents:    getEvents,
+	}
+}
+
+func (collector *DNSFailureSummaryCollector) GetName() string {
+	return "dnsfailuresummary"
+}
+
+func (collector *DNSFailureSummaryCollector) CheckSupported() error {
+	// Like the DNS configuration, the summary is only collected by Linux nodes.
+	if collector.osIdentifier != utils.Linux {
+		return fmt.Errorf("unsupported OS: %s", collector.osIdentifier)
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *DNSFailureSummaryCollector) Collect() error {
+	events, err := collector.getEvents()
+	if err != nil {
+		return fmt.Errorf("error getting DNS trace events: %w", err)
+	}
+
+	data, err := json.Marshal(summarizeDNSFailures(events))
+	if err != nil {
+		return fmt.Errorf("marshall DNS failure summary to json: %w", err)
+	}
+
+	collector.data["dnsfailuresummary"] = string(data)
+
+	return nil
+}
+
+func summarizeDNSFailures(events []DNSTraceEvent) *DNSFailureSummary {
+	summary := &DNSFailureSummary{
+		TopOffenders: []DNSFailureOffender{},
+	}
+
+	offenders := map[string]*DNSFailureOffender{}
+	for _, event := range events {
+		// Only responses carry a response code.
+		if event.QR != "R" {
+			continue
+		}
+		summary.TotalResponses++
+
+		rcode := strings.ToUpper(event.ResponseCode)
+		if !utils.Contains(dnsFailureResponseCodes, rcode) {
+			continue
+		}
+
+		key := fmt.Sprintf("%s/%s/%s", event.Namespace, event.Pod, event.Name)
+		offender, ok := offenders[key]
+		if !ok {
+			offender = &DNSFailureOffender{
+				Namespace: event.Namespace,
+				Pod:       event.Pod,
+				Name:      event.Name,
+			}
+			offenders[key] = offender
+		}
+
+		if rcode == "NXDOMAIN" {
+			summary.NXDomainCount++
+			offender.NXDomainCount++
+		} else {
+			summary.ServFailCount++
+			offender.ServFailCount++
+		}
+		offender.Total++
+	}
+
+	for _, offender := range offenders {
+		summary.TopOffenders = append(summary.TopOffenders, *offender)
+	}
+
+	sort.Slice(summary.TopOffenders, func(i, j int) bool {
+		a, b := summary.TopOffenders[i], summary.TopOffenders[j]
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		return fmt.Sprintf("%s/%s/%s", a.Namespace, a.Pod, a.Name) < fmt.Sprintf("%s/%s/%s", b.Namespace, b.Pod, b.Name)
+	})
+
+	if len(summary.TopOffenders) > dnsFailureTopOffenders {
+		summary.TopOffenders = summary.TopOffenders[:dnsFailureTopOffenders]
+	}
+
+	return summary
+}
+
+func (collector *DNSFailureSummaryCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
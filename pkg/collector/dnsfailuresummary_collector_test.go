@@ -0,0 +1,150 @@
+package collector
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDNSFailureSummaryCollectorGetName(t *testing.T) {
+	const expectedName = "dnsfailuresummary"
+
+	c := NewDNSFailureSummaryCollector("", nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestDNSFailureSummaryCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		osIdentifier utils.OSIdentifier
+		wantErr      bool
+	}{
+		{
+			osIdentifier: utils.Windows,
+			wantErr:      true,
+		},
+		{
+			osIdentifier: utils.Linux,
+			wantErr:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		c := NewDNSFailureSummaryCollector(tt.osIdentifier, nil)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("CheckSupported() error = %v, wantErr %v", err, tt.wantErr)
+		}
+	}
+}
+
+func TestDNSFailureSummaryCollectorCollect(t *testing.T) {
+	repeat := func(count int, event DNSTraceEvent) []DNSTraceEvent {
+		events := make([]DNSTraceEvent, count)
+		for i := range events {
+			events[i] = event
+		}
+		return events
+	}
+
+	events := []DNSTraceEvent{}
+	// Queries are not counted, only responses
+	events = append(events, repeat(10, DNSTraceEvent{Namespace: "app", Pod: "web-1", QR: "Q", Name: "db.app.svc.cluster.local.", QType: "A"})...)
+	events = append(events, repeat(3, DNSTraceEvent{Namespace: "app", Pod: "web-1", QR: "R", Name: "db.app.svc.cluster.local.", QType: "A", ResponseCode: "NoError"})...)
+	events = append(events, repeat(2, DNSTraceEvent{Namespace: "app", Pod: "web-1", QR: "R", Name: "api.example.com.", QType: "A", ResponseCode: "ServFail"})...)
+	events = append(events, repeat(5, DNSTraceEvent{Namespace: "app", Pod: "worker-1", QR: "R", Name: "queue.app.svc.cluster.local.app.svc.cluster.local.", QType: "AAAA", ResponseCode: "NXDomain"})...)
+	events = append(events, repeat(1, DNSTraceEvent{Namespace: "app", Pod: "worker-1", QR: "R", Name: "queue.app.svc.cluster.local.app.svc.cluster.local.", QType: "A", ResponseCode: "SERVFAIL"})...)
+	events = append(events, repeat(2, DNSTraceEvent{Namespace: "default", Pod: "client", QR: "R", Name: "missing.default.svc.cluster.local.", QType: "A", ResponseCode: "NXDOMAIN"})...)
+
+	c := NewDNSFailureSummaryCollector(utils.Linux, func() ([]DNSTraceEvent, error) { return events, nil })
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	testDataValue(t, c.GetData()["dnsfailuresummary"], func(raw string) {
+		var summary DNSFailureSummary
+		if err := json.Unmarshal([]byte(raw), &summary); err != nil {
+			t.Fatalf("unmarshal GetData(): %v", err)
+		}
+
+		if summary.TotalResponses != 13 || summary.NXDomainCount != 7 || summary.ServFailCount != 3 {
+			t.Errorf("unexpected totals: %+v", summary)
+		}
+
+		expectedRanking := []DNSFailureOffender{
+			{Namespace: "app", Pod: "worker-1", Name: "queue.app.svc.cluster.local.app.svc.cluster.local.", NXDomainCount: 5, ServFailCount: 1, Total: 6},
+			{Namespace: "app", Pod: "web-1", Name: "api.example.com.", ServFailCount: 2, Total: 2},
+			{Namespace: "default", Pod: "client", Name: "missing.default.svc.cluster.local.", NXDomainCount: 2, Total: 2},
+		}
+		if len(summary.TopOffenders) != len(expectedRanking) {
+			t.Fatalf("expected %d offenders, found %s", len(expectedRanking), raw)
+		}
+		for i, expected := range expectedRanking {
+			if summary.TopOffenders[i] != expected {
+				t.Errorf("unexpected offender at rank %d: expected %+v, found %+v", i+1, expected, summary.TopOffenders[i])
+			}
+		}
+	})
+}
+
+func TestDNSFailureSummaryCollectorCollectError(t *testing.T) {
+	c := NewDNSFailureSummaryCollector(utils.Linux, func() ([]DNSTraceEvent, error) { return nil, errors.New("tracer not running") })
+	if err := c.Collect(); err == nil {
+		t.Errorf("expected error when trace events are unavailable")
+	}
+}
+
+func TestParseCoreDNSQueryLog(t *testing.T) {
+	web := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "web-1"}}
+	podsByIP := map[string]*corev1.Pod{"10.244.0.5": web, "fd00::5": web}
+
+	queryLog := strings.Join([]string{
+		`.:53`,
+		`[INFO] plugin/reload: Running configuration SHA512 = 1a2b3c`,
+		`[INFO] 10.244.0.5:43127 - 12345 "A IN api.example.com. udp 33 false 512" NXDOMAIN qr,rd,ra 108 0.000127s`,
+		`[INFO] [fd00::5]:52001 - 2 "AAAA IN db.app.svc.cluster.local. udp 42 false 512" NOERROR qr,aa,rd 135 0.000081s`,
+		`[INFO] 10.224.0.4:40000 - 3 "A IN registry.example.com. tcp 49 false 65535" SERVFAIL qr,rd 49 2.001s`,
+		`[ERROR] plugin/errors: 2 registry.example.com. A: read udp 10.244.0.9:51234->168.63.129.16:53: i/o timeout`,
+	}, "\n")
+
+	events, err := parseCoreDNSQueryLog(strings.NewReader(queryLog), podsByIP)
+	if err != nil {
+		t.Fatalf("parseCoreDNSQueryLog() error = %v", err)
+	}
+
+	expected := []DNSTraceEvent{
+		{Namespace: "app", Pod: "web-1", QR: "R", Name: "api.example.com.", QType: "A", ResponseCode: "NXDOMAIN"},
+		{Namespace: "app", Pod: "web-1", QR: "R", Name: "db.app.svc.cluster.local.", QType: "AAAA", ResponseCode: "NOERROR"},
+		{Pod: "10.224.0.4", QR: "R", Name: "registry.example.com.", QType: "A", ResponseCode: "SERVFAIL"},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("unexpected events: %+v", events)
+	}
+}
+
+func TestCoreDNSQueryLogSource(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceSystem, Name: "coredns-1", Labels: map[string]string{"k8s-app": "kube-dns"}},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	)
+
+	// The fake clientset returns the same placeholder logs for every pod, which aren't CoreDNS query logs.
+	events, err := NewCoreDNSQueryLogSource(clientset)()
+	if err != nil {
+		t.Fatalf("source error = %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("unexpected events: %+v", events)
+	}
+}
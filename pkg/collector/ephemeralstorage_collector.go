@@ -0,0 +1,247 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Volumes can hold millions of files, so the walk of each directory stops after this many entries, and the usage is
+// reported as incomplete.
+const ephemeralStorageMaxEntries = 100000
+
+type EphemeralStorageReport struct {
+	Pods   []PodEphemeralStorage `json:"pods"`
+	Errors []string              `json:"errors"`
+}
+
+// PodEphemeralStorage describes the usage of node storage by a pod, from its emptyDir volumes and container logs.
+// The writable layers of its containers also count towards its usage, but are held by the container runtime and
+// aren't measured here.
+type PodEphemeralStorage struct {
+	Namespace    string `json:"namespace"`
+	Name         string `json:"name"`
+	RequestBytes int64  `json:"requestBytes,omitempty"`
+	LimitBytes   int64  `json:"limitBytes,omitempty"`
+	UsedBytes    int64  `json:"usedBytes"`
+	// OverLimit is set if the measured usage exceeds the sum of the container limits, at which point the kubelet
+	// evicts the pod.
+	OverLimit  bool                        `json:"overLimit"`
+	Complete   bool                        `json:"complete"`
+	Volumes    []EmptyDirUsage             `json:"volumes"`
+	Containers []ContainerEphemeralStorage `json:"containers"`
+}
+
+type EmptyDirUsage struct {
+	Name string `json:"name"`
+	// Medium is "Memory" for volumes backed by tmpfs, which count towards the memory usage of the pod rather than
+	// its ephemeral storage.
+	Medium         string   `json:"medium,omitempty"`
+	SizeLimitBytes int64    `json:"sizeLimitBytes,omitempty"`
+	UsedBytes      int64    `json:"usedBytes"`
+	Complete       bool     `json:"complete"`
+	MountedBy      []string `json:"mountedBy"`
+}
+
+type ContainerEphemeralStorage struct {
+	Name         string `json:"name"`
+	RequestBytes int64  `json:"requestBytes,omitempty"`
+	LimitBytes   int64  `json:"limitBytes,omitempty"`
+	LogBytes     int64  `json:"logBytes"`
+	LogsComplete bool   `json:"logsComplete"`
+}
+
+// EphemeralStorageCollector defines an Ephemeral Storage Collector struct
+type EphemeralStorageCollector struct {
+	data         map[string]string
+	osIdentifier utils.OSIdentifier
+	filePaths    *utils.KnownFilePaths
+	fileSystem   interfaces.FileSystemAccessor
+	clientset    kubernetes.Interface
+	runtimeInfo  *utils.RuntimeInfo
+}
+
+// NewEphemeralStorageCollector is a constructor
+func NewEphemeralStorageCollector(osIdentifier utils.OSIdentifier, filePaths *utils.KnownFilePaths, fileSystem interfaces.FileSystemAccessor, clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *EphemeralStorageCollector {
+	return &EphemeralStorageCollector{
+		data:         make(map[string]string),
+		osIdentifier: osIdentifier,
+		filePaths:    filePaths,
+		fileSystem:   fileSystem,
+		clientset:    clientset,
+		runtimeInfo:  runtimeInfo,
+	}
+}
+
+func (collector *EphemeralStorageCollector) GetName() string {
+	return "ephemeralstorage"
+}
+
+func (collector *EphemeralStorageCollector) CheckSupported() error {
+	// The kubelet pods directory is only mounted into the Linux container.
+	if collector.osIdentifier != utils.Linux {
+		return fmt.Errorf("unsupported OS: %s", collector.osIdentifier)
+	}
+
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *EphemeralStorageCollector) Collect() error {
+	pods, err := collector.clientset.CoreV1().Pods(metav1.NamespaceAll).List(context.Background(), metav1.ListOptions{
+		FieldSelector: "spec.nodeName=" + collector.runtimeInfo.HostNodeName,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list pods: %w", err)
+	}
+
+	report := &EphemeralStorageReport{
+		Pods:   []PodEphemeralStorage{},
+		Errors: []string{},
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName != collector.runtimeInfo.HostNodeName {
+			continue
+		}
+
+		usage, errs := collector.getPodUsage(pod)
+		report.Pods = append(report.Pods, *usage)
+		report.Errors = append(report.Errors, errs...)
+	}
+
+	// The largest consumers are the most likely cause of disk pressure, so they're listed first.
+	sort.SliceStable(report.Pods, func(i, j int) bool {
+		if report.Pods[i].UsedBytes != report.Pods[j].UsedBytes {
+			return report.Pods[i].UsedBytes > report.Pods[j].UsedBytes
+		}
+		return report.Pods[i].Namespace+"/"+report.Pods[i].Name < report.Pods[j].Namespace+"/"+report.Pods[j].Name
+	})
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("marshall ephemeral storage to json: %w", err)
+	}
+
+	collector.data["ephemeral-storage"] = string(data)
+
+	return nil
+}
+
+func (collector *EphemeralStorageCollector) getPodUsage(pod *corev1.Pod) (*PodEphemeralStorage, []string) {
+	usage := &PodEphemeralStorage{
+		Namespace:  pod.Namespace,
+		Name:       pod.Name,
+		Complete:   true,
+		Volumes:    []EmptyDirUsage{},
+		Containers: []ContainerEphemeralStorage{},
+	}
+	errs := []string{}
+
+	mountedBy := map[string][]string{}
+	for _, container := range pod.Spec.Containers {
+		for _, mount := range container.VolumeMounts {
+			mountedBy[mount.Name] = append(mountedBy[mount.Name], container.Name)
+		}
+	}
+
+	// See https://kubernetes.io/docs/concepts/storage/volumes/#emptydir for where the kubelet places emptyDir volumes.
+	for _, volume := range pod.Spec.Volumes {
+		if volume.EmptyDir == nil {
+			continue
+		}
+
+		volumeUsage := EmptyDirUsage{
+			Name:      volume.Name,
+			Medium:    string(volume.EmptyDir.Medium),
+			MountedBy: mountedBy[volume.Name],
+		}
+		if volumeUsage.MountedBy == nil {
+			volumeUsage.MountedBy = []string{}
+		}
+		if volume.EmptyDir.SizeLimit != nil {
+			volumeUsage.SizeLimitBytes = volume.EmptyDir.SizeLimit.Value()
+		}
+
+		volumePath := path.Join(collector.filePaths.KubeletPods, string(pod.UID), "volumes", "kubernetes.io~empty-dir", volume.Name)
+		size, complete, err := collector.getDirectoryUsage(volumePath)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s/%s: volume %s: %v", pod.Namespace, pod.Name, volume.Name, err))
+		}
+
+		volumeUsage.UsedBytes = size
+		volumeUsage.Complete = complete
+		usage.Complete = usage.Complete && complete
+		if volume.EmptyDir.Medium != corev1.StorageMediumMemory {
+			usage.UsedBytes += size
+		}
+
+		usage.Volumes = append(usage.Volumes, volumeUsage)
+	}
+
+	for _, container := range pod.Spec.Containers {
+		containerUsage := ContainerEphemeralStorage{Name: container.Name}
+		if request, ok := container.Resources.Requests[corev1.ResourceEphemeralStorage]; ok {
+			containerUsage.RequestBytes = request.Value()
+		}
+		if limit, ok := container.Resources.Limits[corev1.ResourceEphemeralStorage]; ok {
+			containerUsage.LimitBytes = limit.Value()
+		}
+
+		logPath := path.Join(collector.filePaths.PodLogs, fmt.Sprintf("%s_%s_%s", pod.Namespace, pod.Name, pod.UID), container.Name)
+		size, complete, err := collector.getDirectoryUsage(logPath)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s/%s: logs of container %s: %v", pod.Namespace, pod.Name, container.Name, err))
+		}
+
+		containerUsage.LogBytes = size
+		containerUsage.LogsComplete = complete
+		usage.Complete = usage.Complete && complete
+		usage.UsedBytes += size
+		usage.RequestBytes += containerUsage.RequestBytes
+		usage.LimitBytes += containerUsage.LimitBytes
+
+		usage.Containers = append(usage.Containers, containerUsage)
+	}
+
+	// The pod is only limited if every container is.
+	for _, container := range usage.Containers {
+		if container.LimitBytes == 0 {
+			usage.LimitBytes = 0
+			break
+		}
+	}
+	usage.OverLimit = usage.LimitBytes > 0 && usage.UsedBytes > usage.LimitBytes
+
+	return usage, errs
+}
+
+// getDirectoryUsage returns the usage of a directory, which is empty if it doesn't exist (e.g. the logs of a container
+// which hasn't started).
+func (collector *EphemeralStorageCollector) getDirectoryUsage(directoryPath string) (int64, bool, error) {
+	size, complete, err := collector.fileSystem.GetDirectoryUsage(directoryPath, ephemeralStorageMaxEntries)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, true, nil
+	}
+	return size, complete, err
+}
+
+func (collector *EphemeralStorageCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
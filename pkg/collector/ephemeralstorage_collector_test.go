@@ -0,0 +1,164 @@
+package collector
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestEphemeralStorageCollectorGetName(t *testing.T) {
+	const expectedName = "ephemeralstorage"
+
+	c := NewEphemeralStorageCollector(utils.Linux, nil, nil, nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestEphemeralStorageCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		osIdentifier  utils.OSIdentifier
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "Windows",
+			osIdentifier:  utils.Windows,
+			collectorList: []string{},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "Linux",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewEphemeralStorageCollector(tt.osIdentifier, nil, nil, nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestEphemeralStorageCollectorCollect(t *testing.T) {
+	sizeLimit := resource.MustParse("1Ki")
+	webPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app", UID: "uid-web"},
+		Spec: corev1.PodSpec{
+			NodeName: "node1",
+			Containers: []corev1.Container{
+				{
+					Name:         "nginx",
+					VolumeMounts: []corev1.VolumeMount{{Name: "cache"}, {Name: "scratch"}},
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceEphemeralStorage: resource.MustParse("10")},
+						Limits:   corev1.ResourceList{corev1.ResourceEphemeralStorage: resource.MustParse("20")},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{Name: "cache", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: &sizeLimit}}},
+				{Name: "scratch", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}}},
+				{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{}}},
+			},
+		},
+	}
+	idlePod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "idle", Namespace: "app", UID: "uid-idle"},
+		Spec: corev1.PodSpec{
+			NodeName:   "node1",
+			Containers: []corev1.Container{{Name: "main"}},
+		},
+	}
+	otherNodePod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "app", UID: "uid-other"},
+		Spec: corev1.PodSpec{
+			NodeName:   "node2",
+			Containers: []corev1.Container{{Name: "main"}},
+		},
+	}
+
+	filePaths := &utils.KnownFilePaths{
+		KubeletPods: "/var/lib/kubelet/pods",
+		PodLogs:     "/var/log/pods",
+	}
+	fileSystem := test.NewFakeFileSystem(map[string]string{
+		"/var/lib/kubelet/pods/uid-web/volumes/kubernetes.io~empty-dir/cache/a":   strings.Repeat("a", 15),
+		"/var/lib/kubelet/pods/uid-web/volumes/kubernetes.io~empty-dir/scratch/b": strings.Repeat("b", 100),
+		"/var/log/pods/app_web_uid-web/nginx/0.log":                               strings.Repeat("l", 10),
+		"/var/log/pods/app_other_uid-other/main/0.log":                            strings.Repeat("l", 1000),
+	})
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+		HostNodeName:  "node1",
+	}
+
+	c := NewEphemeralStorageCollector(utils.Linux, filePaths, fileSystem, fake.NewSimpleClientset(webPod, idlePod, otherNodePod), runtimeInfo)
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	// Memory-backed volumes don't count towards the usage, which is over the limit of the only container.
+	want := EphemeralStorageReport{
+		Pods: []PodEphemeralStorage{
+			{
+				Namespace:    "app",
+				Name:         "web",
+				RequestBytes: 10,
+				LimitBytes:   20,
+				UsedBytes:    25,
+				OverLimit:    true,
+				Complete:     true,
+				Volumes: []EmptyDirUsage{
+					{Name: "cache", SizeLimitBytes: 1024, UsedBytes: 15, Complete: true, MountedBy: []string{"nginx"}},
+					{Name: "scratch", Medium: "Memory", UsedBytes: 100, Complete: true, MountedBy: []string{"nginx"}},
+				},
+				Containers: []ContainerEphemeralStorage{
+					{Name: "nginx", RequestBytes: 10, LimitBytes: 20, LogBytes: 10, LogsComplete: true},
+				},
+			},
+			{
+				Namespace:  "app",
+				Name:       "idle",
+				Complete:   true,
+				Volumes:    []EmptyDirUsage{},
+				Containers: []ContainerEphemeralStorage{{Name: "main", LogsComplete: true}},
+			},
+		},
+		Errors: []string{},
+	}
+
+	testDataValue(t, c.GetData()["ephemeral-storage"], func(raw string) {
+		var report EphemeralStorageReport
+		if err := json.Unmarshal([]byte(raw), &report); err != nil {
+			t.Fatalf("unmarshal ephemeral storage: %v", err)
+		}
+		if !reflect.DeepEqual(report, want) {
+			t.Errorf("unexpected ephemeral storage:\nexpected %+v\nfound    %+v", want, report)
+		}
+	})
+}
@@ -0,0 +1,240 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+const etcdRequestDurationMetric = "etcd_request_duration_seconds"
+
+// The 99th percentile etcd request latency above which an operation is considered degraded. A healthy etcd
+// typically serves requests in well under 100ms.
+const etcdLatencyDegradedThreshold = 0.5
+
+// Metrics describing compaction, defragmentation and database size. The apiserver only exposes the database size,
+// but the others are included if the scraped metrics come from etcd itself.
+var etcdMaintenanceMetrics = []string{
+	"apiserver_storage_db_total_size_in_bytes",
+	"etcd_db_total_size_in_bytes",
+	"etcd_debugging_mvcc_db_compaction_total_duration_milliseconds",
+	"etcd_debugging_mvcc_db_compaction_keys_total",
+	"etcd_disk_backend_defrag_duration_seconds",
+	"etcd_mvcc_db_total_size_in_bytes",
+	"etcd_mvcc_db_total_size_in_use_in_bytes",
+}
+
+type EtcdLatencyReport struct {
+	Degraded    bool                   `json:"degraded"`
+	Operations  []EtcdOperationLatency `json:"operations"`
+	Maintenance map[string]float64     `json:"maintenance"`
+}
+
+// EtcdOperationLatency contains latency percentiles (in seconds) for an etcd operation. These are calculated from
+// histograms accumulated since the API server started.
+type EtcdOperationLatency struct {
+	Operation string  `json:"operation"`
+	Count     uint64  `json:"count"`
+	P50       float64 `json:"p50"`
+	P90       float64 `json:"p90"`
+	P99       float64 `json:"p99"`
+	Degraded  bool    `json:"degraded"`
+}
+
+// EtcdLatencyCollector defines an Etcd Latency Collector struct
+type EtcdLatencyCollector struct {
+	data          map[string]string
+	scrapeMetrics utils.MetricsScraper
+	metrics       string
+}
+
+// NewEtcdLatencyCollector is a constructor
+func NewEtcdLatencyCollector(scrapeMetrics utils.MetricsScraper) *EtcdLatencyCollector {
+	return &EtcdLatencyCollector{
+		data:          make(map[string]string),
+		scrapeMetrics: scrapeMetrics,
+	}
+}
+
+func (collector *EtcdLatencyCollector) GetName() string {
+	return "etcdlatency"
+}
+
+func (collector *EtcdLatencyCollector) CheckSupported() error {
+	// The metrics endpoint is not always accessible (e.g. on managed control planes that don't expose etcd metrics),
+	// so check that we can read it, and keep the result for collection.
+	metrics, err := collector.scrapeMetrics()
+	if err != nil {
+		return fmt.Errorf("metrics not reachable: %w", err)
+	}
+
+	if !strings.Contains(metrics, etcdRequestDurationMetric) {
+		return fmt.Errorf("metric %s not found", etcdRequestDurationMetric)
+	}
+
+	collector.metrics = metrics
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *EtcdLatencyCollector) Collect() error {
+	if collector.metrics == "" {
+		metrics, err := collector.scrapeMetrics()
+		if err != nil {
+			return err
+		}
+		collector.metrics = metrics
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(strings.NewReader(collector.metrics))
+	if err != nil {
+		return fmt.Errorf("error parsing metrics: %w", err)
+	}
+
+	report := EtcdLatencyReport{
+		Operations:  []EtcdOperationLatency{},
+		Maintenance: map[string]float64{},
+	}
+
+	if family, ok := families[etcdRequestDurationMetric]; ok {
+		report.Operations = getEtcdOperationLatencies(family)
+	}
+	for _, operation := range report.Operations {
+		report.Degraded = report.Degraded || operation.Degraded
+	}
+
+	for _, name := range etcdMaintenanceMetrics {
+		family, ok := families[name]
+		if !ok {
+			continue
+		}
+		for _, metric := range family.Metric {
+			report.Maintenance[name] += getMetricValue(metric)
+		}
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("marshall etcd latency to json: %w", err)
+	}
+
+	collector.data["etcdlatency"] = string(data)
+
+	return nil
+}
+
+// getEtcdOperationLatencies combines the histograms for each operation across all resource types, and calculates
+// the latency percentiles for each.
+func getEtcdOperationLatencies(family *dto.MetricFamily) []EtcdOperationLatency {
+	type histogram struct {
+		count   uint64
+		buckets map[float64]uint64
+	}
+
+	histograms := map[string]*histogram{}
+	for _, metric := range family.Metric {
+		if metric.Histogram == nil {
+			continue
+		}
+
+		operation := ""
+		for _, label := range metric.Label {
+			if label.GetName() == "operation" {
+				operation = label.GetValue()
+			}
+		}
+
+		h, ok := histograms[operation]
+		if !ok {
+			h = &histogram{buckets: map[float64]uint64{}}
+			histograms[operation] = h
+		}
+
+		h.count += metric.Histogram.GetSampleCount()
+		for _, bucket := range metric.Histogram.Bucket {
+			h.buckets[bucket.GetUpperBound()] += bucket.GetCumulativeCount()
+		}
+	}
+
+	result := []EtcdOperationLatency{}
+	for operation, h := range histograms {
+		if h.count == 0 {
+			continue
+		}
+
+		latency := EtcdOperationLatency{
+			Operation: operation,
+			Count:     h.count,
+			P50:       histogramQuantile(0.5, h.count, h.buckets),
+			P90:       histogramQuantile(0.9, h.count, h.buckets),
+			P99:       histogramQuantile(0.99, h.count, h.buckets),
+		}
+		latency.Degraded = latency.P99 > etcdLatencyDegradedThreshold
+
+		result = append(result, latency)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Operation < result[j].Operation
+	})
+
+	return result
+}
+
+// histogramQuantile estimates a quantile from cumulative histogram buckets, using linear interpolation within
+// the bucket containing the quantile (as Prometheus' histogram_quantile function does).
+func histogramQuantile(q float64, count uint64, buckets map[float64]uint64) float64 {
+	upperBounds := make([]float64, 0, len(buckets))
+	for upperBound := range buckets {
+		upperBounds = append(upperBounds, upperBound)
+	}
+	sort.Float64s(upperBounds)
+
+	rank := q * float64(count)
+	lowerBound := 0.0
+	var lowerCount uint64
+	for _, upperBound := range upperBounds {
+		cumulativeCount := buckets[upperBound]
+		if float64(cumulativeCount) >= rank {
+			// Latencies beyond the largest finite bucket can't be estimated, so report that bucket's bound.
+			if math.IsInf(upperBound, 1) || cumulativeCount == lowerCount {
+				return lowerBound
+			}
+			return lowerBound + (upperBound-lowerBound)*(rank-float64(lowerCount))/float64(cumulativeCount-lowerCount)
+		}
+		lowerBound = upperBound
+		lowerCount = cumulativeCount
+	}
+
+	// The +Inf bucket is missing, so all we know is that the quantile is above the largest bucket.
+	return lowerBound
+}
+
+func getMetricValue(metric *dto.Metric) float64 {
+	switch {
+	case metric.Gauge != nil:
+		return metric.Gauge.GetValue()
+	case metric.Counter != nil:
+		return metric.Counter.GetValue()
+	case metric.Untyped != nil:
+		return metric.Untyped.GetValue()
+	case metric.Histogram != nil:
+		return metric.Histogram.GetSampleSum()
+	case metric.Summary != nil:
+		return metric.Summary.GetSampleSum()
+	default:
+		return 0
+	}
+}
+
+func (collector *EtcdLatencyCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
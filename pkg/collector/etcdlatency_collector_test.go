@@ -0,0 +1,140 @@
+package collector
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+const sampleEtcdMetrics = `# HELP etcd_request_duration_seconds [ALPHA] Etcd request latency in seconds for each operation and object type.
+# TYPE etcd_request_duration_seconds histogram
+etcd_request_duration_seconds_bucket{operation="get",type="/registry/pods",le="0.005"} 90
+etcd_request_duration_seconds_bucket{operation="get",type="/registry/pods",le="0.025"} 100
+etcd_request_duration_seconds_bucket{operation="get",type="/registry/pods",le="0.1"} 100
+etcd_request_duration_seconds_bucket{operation="get",type="/registry/pods",le="1"} 100
+etcd_request_duration_seconds_bucket{operation="get",type="/registry/pods",le="+Inf"} 100
+etcd_request_duration_seconds_sum{operation="get",type="/registry/pods"} 0.5
+etcd_request_duration_seconds_count{operation="get",type="/registry/pods"} 100
+etcd_request_duration_seconds_bucket{operation="update",type="/registry/leases",le="0.005"} 10
+etcd_request_duration_seconds_bucket{operation="update",type="/registry/leases",le="0.025"} 20
+etcd_request_duration_seconds_bucket{operation="update",type="/registry/leases",le="0.1"} 40
+etcd_request_duration_seconds_bucket{operation="update",type="/registry/leases",le="1"} 90
+etcd_request_duration_seconds_bucket{operation="update",type="/registry/leases",le="+Inf"} 100
+etcd_request_duration_seconds_sum{operation="update",type="/registry/leases"} 80
+etcd_request_duration_seconds_count{operation="update",type="/registry/leases"} 100
+etcd_request_duration_seconds_bucket{operation="update",type="/registry/configmaps",le="0.005"} 0
+etcd_request_duration_seconds_bucket{operation="update",type="/registry/configmaps",le="0.025"} 0
+etcd_request_duration_seconds_bucket{operation="update",type="/registry/configmaps",le="0.1"} 0
+etcd_request_duration_seconds_bucket{operation="update",type="/registry/configmaps",le="1"} 0
+etcd_request_duration_seconds_bucket{operation="update",type="/registry/configmaps",le="+Inf"} 0
+etcd_request_duration_seconds_sum{operation="update",type="/registry/configmaps"} 0
+etcd_request_duration_seconds_count{operation="update",type="/registry/configmaps"} 0
+# HELP apiserver_storage_db_total_size_in_bytes [ALPHA] Total size of the storage database file physically allocated in bytes.
+# TYPE apiserver_storage_db_total_size_in_bytes gauge
+apiserver_storage_db_total_size_in_bytes{endpoint="https://10.0.0.1:2379"} 1.2e+07
+`
+
+func TestEtcdLatencyCollectorGetName(t *testing.T) {
+	const expectedName = "etcdlatency"
+
+	c := NewEtcdLatencyCollector(nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestEtcdLatencyCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name      string
+		metrics   string
+		scrapeErr error
+		wantErr   bool
+	}{
+		{
+			name:      "metrics unreachable",
+			scrapeErr: errors.New("forbidden"),
+			wantErr:   true,
+		},
+		{
+			name:    "no etcd metrics",
+			metrics: "# TYPE apiserver_request_total counter\napiserver_request_total 1\n",
+			wantErr: true,
+		},
+		{
+			name:    "etcd metrics",
+			metrics: sampleEtcdMetrics,
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		c := NewEtcdLatencyCollector(func() (string, error) { return tt.metrics, tt.scrapeErr })
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestEtcdLatencyCollectorCollect(t *testing.T) {
+	c := NewEtcdLatencyCollector(func() (string, error) { return sampleEtcdMetrics, nil })
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	testDataValue(t, c.GetData()["etcdlatency"], func(raw string) {
+		var report EtcdLatencyReport
+		if err := json.Unmarshal([]byte(raw), &report); err != nil {
+			t.Fatalf("unmarshal GetData(): %v", err)
+		}
+
+		if !report.Degraded {
+			t.Errorf("expected report to be degraded")
+		}
+
+		if len(report.Operations) != 2 {
+			t.Fatalf("expected 2 operations, found %d", len(report.Operations))
+		}
+
+		get := report.Operations[0]
+		if get.Operation != "get" || get.Degraded || get.Count != 100 {
+			t.Errorf("unexpected get latency: %+v", get)
+		}
+
+		update := report.Operations[1]
+		if update.Operation != "update" || !update.Degraded || update.Count != 100 {
+			t.Errorf("unexpected update latency: %+v", update)
+		}
+
+		// 50% of update requests complete within (0.1 + 0.9*10/50)s, and 90% within 1s
+		if math.Abs(update.P50-0.28) > 1e-9 || math.Abs(update.P90-1) > 1e-9 {
+			t.Errorf("unexpected update percentiles: %+v", update)
+		}
+
+		if report.Maintenance["apiserver_storage_db_total_size_in_bytes"] != 1.2e+07 {
+			t.Errorf("unexpected maintenance metrics: %v", report.Maintenance)
+		}
+	})
+}
+
+func TestHistogramQuantile(t *testing.T) {
+	buckets := map[float64]uint64{0.1: 50, 1: 100, math.Inf(1): 100}
+
+	tests := []struct {
+		q    float64
+		want float64
+	}{
+		{q: 0.25, want: 0.05},
+		{q: 0.5, want: 0.1},
+		{q: 0.75, want: 0.55},
+		{q: 1, want: 1},
+	}
+
+	for _, tt := range tests {
+		if actual := histogramQuantile(tt.q, 100, buckets); math.Abs(actual-tt.want) > 1e-9 {
+			t.Errorf("histogramQuantile(%v) = %v, want %v", tt.q, actual, tt.want)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// EventTimelineEntry is a single event, which may have occurred many times: the Kubernetes event recorder aggregates
+// repeated events into one object with a count, rather than creating a new object for each occurrence.
+type EventTimelineEntry struct {
+	FirstTimestamp time.Time `json:"firstTimestamp"`
+	LastTimestamp  time.Time `json:"lastTimestamp"`
+	Count          int32     `json:"count"`
+	Type           string    `json:"type"`
+	Reason         string    `json:"reason"`
+	Object         string    `json:"object"`
+	Message        string    `json:"message"`
+	Source         string    `json:"source,omitempty"`
+}
+
+type EventTimeline struct {
+	Namespace string               `json:"namespace"`
+	Selector  string               `json:"selector,omitempty"`
+	Since     time.Time            `json:"since"`
+	Objects   []string             `json:"objects,omitempty"`
+	Events    []EventTimelineEntry `json:"events"`
+}
+
+// EventTimelineCollector defines an Event Timeline Collector struct
+type EventTimelineCollector struct {
+	data        map[string]string
+	clientset   kubernetes.Interface
+	runtimeInfo *utils.RuntimeInfo
+	now         func() time.Time
+}
+
+// NewEventTimelineCollector is a constructor
+func NewEventTimelineCollector(clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *EventTimelineCollector {
+	return &EventTimelineCollector{
+		data:        make(map[string]string),
+		clientset:   clientset,
+		runtimeInfo: runtimeInfo,
+		now:         time.Now,
+	}
+}
+
+func (collector *EventTimelineCollector) GetName() string {
+	return "eventtimeline"
+}
+
+func (collector *EventTimelineCollector) CheckSupported() error {
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	if len(collector.runtimeInfo.EventTimelineNamespace) == 0 {
+		return fmt.Errorf("no namespace configured in %s", utils.EventTimelineNamespaceKey)
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *EventTimelineCollector) Collect() error {
+	ctx := context.Background()
+	namespace := collector.runtimeInfo.EventTimelineNamespace
+	selector := collector.runtimeInfo.EventTimelineSelector
+
+	timeline := EventTimeline{
+		Namespace: namespace,
+		Selector:  selector,
+		Since:     collector.now().Add(-collector.runtimeInfo.EventTimelineWindow).UTC(),
+		Events:    []EventTimelineEntry{},
+	}
+
+	// With a selector, only the events of the selected workload are included: events aren't labelled, so they are
+	// matched on the objects they refer to.
+	var objects map[string]bool
+	if len(selector) > 0 {
+		var err error
+		objects, err = collector.getSelectedObjects(ctx, namespace, selector)
+		if err != nil {
+			return err
+		}
+		for object := range objects {
+			timeline.Objects = append(timeline.Objects, object)
+		}
+		sort.Strings(timeline.Objects)
+	}
+
+	include := func(kind, name string) bool {
+		return objects == nil || objects[kind+"/"+name]
+	}
+
+	// Both APIs serve the same underlying objects, so each event is keyed on its UID. The core API is still the only
+	// one available on older clusters, and is listed first.
+	entries := map[types.UID]EventTimelineEntry{}
+
+	err := utils.ListAllPages(metav1.ListOptions{}, func(listOptions metav1.ListOptions) (string, error) {
+		eventList, err := collector.clientset.CoreV1().Events(namespace).List(ctx, listOptions)
+		if err != nil {
+			return "", err
+		}
+
+		for i := range eventList.Items {
+			event := &eventList.Items[i]
+			if include(event.InvolvedObject.Kind, event.InvolvedObject.Name) {
+				entries[event.UID] = getCoreEventTimelineEntry(event)
+			}
+		}
+
+		return eventList.Continue, nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list events in namespace %s: %w", namespace, err)
+	}
+
+	err = utils.ListAllPages(metav1.ListOptions{}, func(listOptions metav1.ListOptions) (string, error) {
+		eventList, err := collector.clientset.EventsV1().Events(namespace).List(ctx, listOptions)
+		if err != nil {
+			return "", err
+		}
+
+		for i := range eventList.Items {
+			event := &eventList.Items[i]
+			if _, ok := entries[event.UID]; ok {
+				continue
+			}
+			if include(event.Regarding.Kind, event.Regarding.Name) {
+				entries[event.UID] = getEventsEventTimelineEntry(event)
+			}
+		}
+
+		return eventList.Continue, nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list events.k8s.io events in namespace %s: %w", namespace, err)
+	}
+
+	for _, entry := range entries {
+		if entry.LastTimestamp.Before(timeline.Since) {
+			continue
+		}
+		timeline.Events = append(timeline.Events, entry)
+	}
+
+	sort.SliceStable(timeline.Events, func(i, j int) bool {
+		if !timeline.Events[i].LastTimestamp.Equal(timeline.Events[j].LastTimestamp) {
+			return timeline.Events[i].LastTimestamp.Before(timeline.Events[j].LastTimestamp)
+		}
+		return timeline.Events[i].Object+timeline.Events[i].Reason < timeline.Events[j].Object+timeline.Events[j].Reason
+	})
+
+	data, err := json.Marshal(timeline)
+	if err != nil {
+		return fmt.Errorf("marshall event timeline to json: %w", err)
+	}
+
+	collector.data["event-timeline"] = string(data)
+
+	return nil
+}
+
+// getSelectedObjects returns the pods and replica sets matching the selector, with their owners, as kind/name.
+// Replica sets of a deployment carry the labels of its pod template, so this includes the deployment itself.
+func (collector *EventTimelineCollector) getSelectedObjects(ctx context.Context, namespace, selector string) (map[string]bool, error) {
+	objects := map[string]bool{}
+	addObject := func(meta metav1.ObjectMeta, kind string) {
+		objects[kind+"/"+meta.Name] = true
+		for _, owner := range meta.OwnerReferences {
+			objects[owner.Kind+"/"+owner.Name] = true
+		}
+	}
+
+	err := utils.ListAllPages(metav1.ListOptions{LabelSelector: selector}, func(listOptions metav1.ListOptions) (string, error) {
+		podList, err := collector.clientset.CoreV1().Pods(namespace).List(ctx, listOptions)
+		if err != nil {
+			return "", err
+		}
+
+		for _, pod := range podList.Items {
+			addObject(pod.ObjectMeta, "Pod")
+		}
+
+		return podList.Continue, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list pods matching %s: %w", selector, err)
+	}
+
+	err = utils.ListAllPages(metav1.ListOptions{LabelSelector: selector}, func(listOptions metav1.ListOptions) (string, error) {
+		replicaSetList, err := collector.clientset.AppsV1().ReplicaSets(namespace).List(ctx, listOptions)
+		if err != nil {
+			return "", err
+		}
+
+		for _, replicaSet := range replicaSetList.Items {
+			addObject(replicaSet.ObjectMeta, "ReplicaSet")
+		}
+
+		return replicaSetList.Continue, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list replica sets matching %s: %w", selector, err)
+	}
+
+	return objects, nil
+}
+
+func getCoreEventTimelineEntry(event *corev1.Event) EventTimelineEntry {
+	entry := EventTimelineEntry{
+		FirstTimestamp: event.FirstTimestamp.Time,
+		LastTimestamp:  getEventLastTimestamp(event),
+		Count:          event.Count,
+		Type:           event.Type,
+		Reason:         event.Reason,
+		Object:         event.InvolvedObject.Kind + "/" + event.InvolvedObject.Name,
+		Message:        event.Message,
+		Source:         event.Source.Component,
+	}
+	if event.Series != nil {
+		entry.Count = event.Series.Count
+	}
+	if len(entry.Source) == 0 {
+		entry.Source = event.ReportingController
+	}
+
+	return normalizeEventTimelineEntry(entry, event.EventTime.Time)
+}
+
+func getEventsEventTimelineEntry(event *eventsv1.Event) EventTimelineEntry {
+	entry := EventTimelineEntry{
+		FirstTimestamp: event.DeprecatedFirstTimestamp.Time,
+		LastTimestamp:  event.DeprecatedLastTimestamp.Time,
+		Count:          event.DeprecatedCount,
+		Type:           event.Type,
+		Reason:         event.Reason,
+		Object:         event.Regarding.Kind + "/" + event.Regarding.Name,
+		Message:        event.Note,
+		Source:         event.ReportingController,
+	}
+	if event.Series != nil {
+		entry.Count = event.Series.Count
+		entry.LastTimestamp = event.Series.LastObservedTime.Time
+	}
+	if len(entry.Source) == 0 {
+		entry.Source = event.DeprecatedSource.Component
+	}
+
+	return normalizeEventTimelineEntry(entry, event.EventTime.Time)
+}
+
+// normalizeEventTimelineEntry fills in the timestamps and count which only one of the APIs populates: an event
+// which isn't part of a series occurred once, at its event time.
+func normalizeEventTimelineEntry(entry EventTimelineEntry, eventTime time.Time) EventTimelineEntry {
+	if entry.FirstTimestamp.IsZero() {
+		entry.FirstTimestamp = eventTime
+	}
+	if entry.LastTimestamp.IsZero() {
+		entry.LastTimestamp = entry.FirstTimestamp
+	}
+	if entry.Count == 0 {
+		entry.Count = 1
+	}
+	entry.FirstTimestamp = entry.FirstTimestamp.UTC()
+	entry.LastTimestamp = entry.LastTimestamp.UTC()
+
+	return entry
+}
+
+func (collector *EventTimelineCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
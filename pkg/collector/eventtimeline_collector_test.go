@@ -0,0 +1,191 @@
+package collector
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestEventTimelineCollectorGetName(t *testing.T) {
+	const expectedName = "eventtimeline"
+
+	c := NewEventTimelineCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestEventTimelineCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		namespace     string
+		wantErr       bool
+	}{
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			collectorList: []string{"connectedCluster"},
+			namespace:     "app",
+			wantErr:       true,
+		},
+		{
+			name:          "no namespace configured",
+			collectorList: []string{},
+			namespace:     "",
+			wantErr:       true,
+		},
+		{
+			name:          "namespace configured",
+			collectorList: []string{},
+			namespace:     "app",
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList:          tt.collectorList,
+			EventTimelineNamespace: tt.namespace,
+		}
+		c := NewEventTimelineCollector(nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestEventTimelineCollectorCollect(t *testing.T) {
+	now := time.Date(2023, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	newCoreEvent := func(uid, namespace, kind, name, reason string, count int32, first, last time.Time) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: uid, Namespace: namespace, UID: types.UID(uid)},
+			InvolvedObject: corev1.ObjectReference{Kind: kind, Namespace: namespace, Name: name},
+			Type:           corev1.EventTypeWarning,
+			Reason:         reason,
+			Message:        reason + " " + name,
+			Count:          count,
+			FirstTimestamp: metav1.NewTime(first),
+			LastTimestamp:  metav1.NewTime(last),
+			Source:         corev1.EventSource{Component: "kubelet"},
+		}
+	}
+
+	objects := []runtime.Object{
+		// Pods and replica sets of the selected workload.
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:            "web-abc-1",
+			Namespace:       "app",
+			Labels:          map[string]string{"app": "web"},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-abc"}},
+		}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Name:            "web-abc",
+			Namespace:       "app",
+			Labels:          map[string]string{"app": "web"},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web"}},
+		}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "app", Labels: map[string]string{"app": "db"}}},
+
+		// An aggregated event, which is also served by the events.k8s.io API.
+		newCoreEvent("backoff", "app", "Pod", "web-abc-1", "BackOff", 12, now.Add(-50*time.Minute), now.Add(-time.Minute)),
+		newCoreEvent("scaled", "app", "Deployment", "web", "ScalingReplicaSet", 1, now.Add(-30*time.Minute), now.Add(-30*time.Minute)),
+		newCoreEvent("db", "app", "Pod", "db-0", "Unhealthy", 1, now.Add(-10*time.Minute), now.Add(-10*time.Minute)),
+		newCoreEvent("old", "app", "Pod", "web-abc-1", "Pulled", 1, now.Add(-2*time.Hour), now.Add(-2*time.Hour)),
+		newCoreEvent("other", "other", "Pod", "web-abc-1", "Killing", 1, now, now),
+
+		&eventsv1.Event{
+			ObjectMeta:          metav1.ObjectMeta{Name: "backoff", Namespace: "app", UID: types.UID("backoff")},
+			Regarding:           corev1.ObjectReference{Kind: "Pod", Namespace: "app", Name: "web-abc-1"},
+			Reason:              "BackOff",
+			DeprecatedCount:     12,
+			ReportingController: "kubelet",
+		},
+		// Only recorded through the events.k8s.io API, as a series.
+		&eventsv1.Event{
+			ObjectMeta:          metav1.ObjectMeta{Name: "probe", Namespace: "app", UID: types.UID("probe")},
+			EventTime:           metav1.NewMicroTime(now.Add(-20 * time.Minute)),
+			Series:              &eventsv1.EventSeries{Count: 3, LastObservedTime: metav1.NewMicroTime(now.Add(-5 * time.Minute))},
+			Regarding:           corev1.ObjectReference{Kind: "Pod", Namespace: "app", Name: "web-abc-1"},
+			Type:                corev1.EventTypeWarning,
+			Reason:              "ProbeWarning",
+			Note:                "readiness probe slow",
+			ReportingController: "kubelet",
+		},
+	}
+
+	tests := []struct {
+		name        string
+		selector    string
+		wantObjects []string
+		wantEvents  []EventTimelineEntry
+	}{
+		{
+			name:        "namespace",
+			selector:    "",
+			wantObjects: nil,
+			wantEvents: []EventTimelineEntry{
+				{FirstTimestamp: now.Add(-30 * time.Minute), LastTimestamp: now.Add(-30 * time.Minute), Count: 1, Type: "Warning", Reason: "ScalingReplicaSet", Object: "Deployment/web", Message: "ScalingReplicaSet web", Source: "kubelet"},
+				{FirstTimestamp: now.Add(-10 * time.Minute), LastTimestamp: now.Add(-10 * time.Minute), Count: 1, Type: "Warning", Reason: "Unhealthy", Object: "Pod/db-0", Message: "Unhealthy db-0", Source: "kubelet"},
+				{FirstTimestamp: now.Add(-20 * time.Minute), LastTimestamp: now.Add(-5 * time.Minute), Count: 3, Type: "Warning", Reason: "ProbeWarning", Object: "Pod/web-abc-1", Message: "readiness probe slow", Source: "kubelet"},
+				{FirstTimestamp: now.Add(-50 * time.Minute), LastTimestamp: now.Add(-time.Minute), Count: 12, Type: "Warning", Reason: "BackOff", Object: "Pod/web-abc-1", Message: "BackOff web-abc-1", Source: "kubelet"},
+			},
+		},
+		{
+			name:        "label selector",
+			selector:    "app=web",
+			wantObjects: []string{"Deployment/web", "Pod/web-abc-1", "ReplicaSet/web-abc"},
+			wantEvents: []EventTimelineEntry{
+				{FirstTimestamp: now.Add(-30 * time.Minute), LastTimestamp: now.Add(-30 * time.Minute), Count: 1, Type: "Warning", Reason: "ScalingReplicaSet", Object: "Deployment/web", Message: "ScalingReplicaSet web", Source: "kubelet"},
+				{FirstTimestamp: now.Add(-20 * time.Minute), LastTimestamp: now.Add(-5 * time.Minute), Count: 3, Type: "Warning", Reason: "ProbeWarning", Object: "Pod/web-abc-1", Message: "readiness probe slow", Source: "kubelet"},
+				{FirstTimestamp: now.Add(-50 * time.Minute), LastTimestamp: now.Add(-time.Minute), Count: 12, Type: "Warning", Reason: "BackOff", Object: "Pod/web-abc-1", Message: "BackOff web-abc-1", Source: "kubelet"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runtimeInfo := &utils.RuntimeInfo{
+				CollectorList:          []string{},
+				EventTimelineNamespace: "app",
+				EventTimelineSelector:  tt.selector,
+				EventTimelineWindow:    time.Hour,
+			}
+
+			c := NewEventTimelineCollector(fake.NewSimpleClientset(objects...), runtimeInfo)
+			c.now = func() time.Time { return now }
+			if err := c.Collect(); err != nil {
+				t.Fatalf("Collect() error = %v", err)
+			}
+
+			testDataValue(t, c.GetData()["event-timeline"], func(raw string) {
+				var timeline EventTimeline
+				if err := json.Unmarshal([]byte(raw), &timeline); err != nil {
+					t.Fatalf("unmarshal GetData(): %v", err)
+				}
+
+				if timeline.Namespace != "app" || !timeline.Since.Equal(now.Add(-time.Hour)) {
+					t.Errorf("unexpected timeline namespace %s since %s", timeline.Namespace, timeline.Since)
+				}
+				if !reflect.DeepEqual(timeline.Objects, tt.wantObjects) {
+					t.Errorf("expected objects %v, found %v", tt.wantObjects, timeline.Objects)
+				}
+				if !reflect.DeepEqual(timeline.Events, tt.wantEvents) {
+					t.Errorf("expected events\n%+v\nfound\n%+v", tt.wantEvents, timeline.Events)
+				}
+			})
+		})
+	}
+}
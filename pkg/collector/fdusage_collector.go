@@ -0,0 +1,307 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// The number of processes reported in each ranking.
+const fdUsageTopProcesses = 20
+
+// Matches the container ID at the end of a cgroup path, for both cgroup drivers and container runtimes, e.g.
+// ".../cri-containerd-<id>.scope" or ".../pod<uid>/<id>".
+var containerCgroupRegex = regexp.MustCompile(`(?:^|[/-])([0-9a-f]{64})(?:\.scope)?$`)
+
+// Matches the pod UID within a cgroup path (see podCgroupRegex).
+var podUIDCgroupRegex = regexp.MustCompile(`pod([0-9a-fA-F]{8}[-_][0-9a-fA-F]{4}[-_][0-9a-fA-F]{4}[-_][0-9a-fA-F]{4}[-_][0-9a-fA-F]{12})`)
+
+type FDUsageReport struct {
+	ProcessCount          int              `json:"processCount"`
+	TotalFDs              int              `json:"totalFDs"`
+	TotalInotifyInstances int              `json:"totalInotifyInstances"`
+	TotalInotifyWatches   int              `json:"totalInotifyWatches"`
+	TopByFDs              []ProcessFDUsage `json:"topByFDs"`
+	TopByInotifyWatches   []ProcessFDUsage `json:"topByInotifyWatches"`
+}
+
+type ProcessFDUsage struct {
+	PID              int    `json:"pid"`
+	Command          string `json:"command"`
+	FDs              int    `json:"fds"`
+	FDSoftLimit      string `json:"fdSoftLimit,omitempty"`
+	InotifyInstances int    `json:"inotifyInstances"`
+	InotifyWatches   int    `json:"inotifyWatches"`
+	ContainerID      string `json:"containerID,omitempty"`
+	PodUID           string `json:"podUID,omitempty"`
+	Namespace        string `json:"namespace,omitempty"`
+	Pod              string `json:"pod,omitempty"`
+	Container        string `json:"container,omitempty"`
+}
+
+// podContainer identifies the container a process runs in.
+type podContainer struct {
+	namespace string
+	pod       string
+	container string
+}
+
+// FDUsageCollector defines a FD Usage Collector struct
+type FDUsageCollector struct {
+	data         map[string]string
+	osIdentifier utils.OSIdentifier
+	filePaths    *utils.KnownFilePaths
+	fileSystem   interfaces.FileSystemAccessor
+	clientset    kubernetes.Interface
+	runtimeInfo  *utils.RuntimeInfo
+}
+
+// NewFDUsageCollector is a constructor
+func NewFDUsageCollector(osIdentifier utils.OSIdentifier, filePaths *utils.KnownFilePaths, fileSystem interfaces.FileSystemAccessor, clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *FDUsageCollector {
+	return &FDUsageCollector{
+		data:         make(map[string]string),
+		osIdentifier: osIdentifier,
+		filePaths:    filePaths,
+		fileSystem:   fileSystem,
+		clientset:    clientset,
+		runtimeInfo:  runtimeInfo,
+	}
+}
+
+func (collector *FDUsageCollector) GetName() string {
+	return "fdusage"
+}
+
+func (collector *FDUsageCollector) CheckSupported() error {
+	// This walks the host's /proc file system, which only exists on Linux.
+	if collector.osIdentifier != utils.Linux {
+		return fmt.Errorf("unsupported OS: %s", collector.osIdentifier)
+	}
+
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *FDUsageCollector) Collect() error {
+	// The Periscope pod shares the host PID namespace, so all the node's processes are visible in /proc.
+	entries, err := collector.fileSystem.ListDirectory(collector.filePaths.Proc)
+	if err != nil {
+		return fmt.Errorf("error listing processes: %w", err)
+	}
+
+	report := &FDUsageReport{
+		TopByFDs:            []ProcessFDUsage{},
+		TopByInotifyWatches: []ProcessFDUsage{},
+	}
+
+	processes := []ProcessFDUsage{}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry)
+		if err != nil {
+			continue
+		}
+
+		// Processes may exit while the others are read, so any which can't be read completely are left out.
+		usage, err := collector.getProcessFDUsage(pid)
+		if err != nil {
+			continue
+		}
+
+		processes = append(processes, *usage)
+		report.TotalFDs += usage.FDs
+		report.TotalInotifyInstances += usage.InotifyInstances
+		report.TotalInotifyWatches += usage.InotifyWatches
+	}
+	report.ProcessCount = len(processes)
+
+	report.TopByFDs = getTopProcesses(processes, func(usage *ProcessFDUsage) int { return usage.FDs })
+	report.TopByInotifyWatches = getTopProcesses(processes, func(usage *ProcessFDUsage) int { return usage.InotifyWatches })
+
+	// The containers and limits are only looked up for the processes which are reported.
+	containers := collector.getPodContainers()
+	for _, top := range [][]ProcessFDUsage{report.TopByFDs, report.TopByInotifyWatches} {
+		for i := range top {
+			collector.addProcessDetails(&top[i], containers)
+		}
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("marshall fd usage to json: %w", err)
+	}
+
+	collector.data["fd-usage"] = string(data)
+
+	return nil
+}
+
+// getProcessFDUsage counts the open file descriptors of a process, and the inotify instances among them along with
+// the watches each has, which are listed in its fdinfo.
+func (collector *FDUsageCollector) getProcessFDUsage(pid int) (*ProcessFDUsage, error) {
+	processPath := path.Join(collector.filePaths.Proc, strconv.Itoa(pid))
+
+	command, err := collector.readProcessFile(processPath, "comm")
+	if err != nil {
+		return nil, err
+	}
+
+	fds, err := collector.fileSystem.ListDirectory(path.Join(processPath, "fd"))
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &ProcessFDUsage{
+		PID:     pid,
+		Command: strings.TrimSpace(command),
+		FDs:     len(fds),
+	}
+
+	for _, fd := range fds {
+		// A descriptor closed since the directory was listed is no longer an inotify instance.
+		target, err := collector.fileSystem.ReadLink(path.Join(processPath, "fd", fd))
+		if err != nil || target != "anon_inode:inotify" {
+			continue
+		}
+		usage.InotifyInstances++
+
+		fdinfo, err := collector.readProcessFile(processPath, path.Join("fdinfo", fd))
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(fdinfo, "\n") {
+			if strings.HasPrefix(line, "inotify wd:") {
+				usage.InotifyWatches++
+			}
+		}
+	}
+
+	return usage, nil
+}
+
+// getTopProcesses returns the processes with the highest non-zero values, highest first.
+func getTopProcesses(processes []ProcessFDUsage, getValue func(*ProcessFDUsage) int) []ProcessFDUsage {
+	result := []ProcessFDUsage{}
+	for i := range processes {
+		if getValue(&processes[i]) > 0 {
+			result = append(result, processes[i])
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if getValue(&result[i]) != getValue(&result[j]) {
+			return getValue(&result[i]) > getValue(&result[j])
+		}
+		return result[i].PID < result[j].PID
+	})
+
+	if len(result) > fdUsageTopProcesses {
+		result = result[:fdUsageTopProcesses]
+	}
+	return result
+}
+
+// addProcessDetails adds the FD limit of a process, and the container it runs in, if they can still be read.
+func (collector *FDUsageCollector) addProcessDetails(usage *ProcessFDUsage, containers map[string]podContainer) {
+	processPath := path.Join(collector.filePaths.Proc, strconv.Itoa(usage.PID))
+
+	if limits, err := collector.readProcessFile(processPath, "limits"); err == nil {
+		for _, limit := range parseProcLimits(limits) {
+			if limit.Name == "Max open files" {
+				usage.FDSoftLimit = limit.SoftLimit
+			}
+		}
+	}
+
+	cgroup, err := collector.readProcessFile(processPath, "cgroup")
+	if err != nil {
+		return
+	}
+
+	usage.PodUID, usage.ContainerID = parseProcessCgroup(cgroup)
+
+	if container, ok := containers[usage.ContainerID]; ok && len(usage.ContainerID) > 0 {
+		usage.Namespace = container.namespace
+		usage.Pod = container.pod
+		usage.Container = container.container
+	}
+}
+
+// parseProcessCgroup returns the UID of the pod and the ID of the container which a process runs in, from the
+// content of its /proc/<pid>/cgroup file, or empty strings if it doesn't run in one.
+func parseProcessCgroup(cgroup string) (string, string) {
+	podUID := ""
+	for _, line := range strings.Split(cgroup, "\n") {
+		// Each line is "hierarchy-ID:controllers:path".
+		parts := strings.SplitN(strings.TrimSpace(line), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+
+		if match := podUIDCgroupRegex.FindStringSubmatch(parts[2]); match != nil {
+			podUID = strings.ReplaceAll(match[1], "_", "-")
+		}
+		if match := containerCgroupRegex.FindStringSubmatch(parts[2]); match != nil {
+			return podUID, match[1]
+		}
+	}
+
+	return podUID, ""
+}
+
+// getPodContainers maps the IDs of the containers on this node to their pods. Processes are still reported by
+// container ID if the pods can't be listed.
+func (collector *FDUsageCollector) getPodContainers() map[string]podContainer {
+	result := map[string]podContainer{}
+
+	pods, err := collector.clientset.CoreV1().Pods(metav1.NamespaceAll).List(context.Background(), metav1.ListOptions{
+		FieldSelector: "spec.nodeName=" + collector.runtimeInfo.HostNodeName,
+	})
+	if err != nil {
+		log.Printf("Unable to list pods on node %s: %v", collector.runtimeInfo.HostNodeName, err)
+		return result
+	}
+
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName != collector.runtimeInfo.HostNodeName {
+			continue
+		}
+
+		for _, statuses := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses, pod.Status.EphemeralContainerStatuses} {
+			for _, status := range statuses {
+				// Container IDs are prefixed with the runtime, e.g. "containerd://<id>".
+				if _, id, found := strings.Cut(status.ContainerID, "://"); found {
+					result[id] = podContainer{namespace: pod.Namespace, pod: pod.Name, container: status.Name}
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+func (collector *FDUsageCollector) readProcessFile(processPath, name string) (string, error) {
+	return utils.GetContent(func() (io.ReadCloser, error) {
+		return collector.fileSystem.GetFileReader(path.Join(processPath, name))
+	})
+}
+
+func (collector *FDUsageCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
@@ -0,0 +1,128 @@
+package collector
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestFDUsageCollectorGetName(t *testing.T) {
+	const expectedName = "fdusage"
+
+	c := NewFDUsageCollector(utils.Linux, nil, nil, nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestFDUsageCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		osIdentifier  utils.OSIdentifier
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			osIdentifier:  utils.Windows,
+			collectorList: []string{},
+			wantErr:       true,
+		},
+		{
+			osIdentifier:  utils.Linux,
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			osIdentifier:  utils.Linux,
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{CollectorList: tt.collectorList}
+		c := NewFDUsageCollector(tt.osIdentifier, nil, nil, nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("CheckSupported() error = %v, wantErr %v", err, tt.wantErr)
+		}
+	}
+}
+
+func TestFDUsageCollectorCollect(t *testing.T) {
+	const containerID = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	const podUID = "11111111-2222-3333-4444-555555555555"
+
+	filePaths := &utils.KnownFilePaths{Proc: "/proc"}
+	fs := test.NewFakeFileSystem(map[string]string{
+		"/proc/meminfo": "MemTotal: 1024 kB\n",
+
+		// A containerized process with an inotify instance watching three files.
+		"/proc/100/comm":     "watcher\n",
+		"/proc/100/cgroup":   "0::/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod11111111_2222_3333_4444_555555555555.slice/cri-containerd-" + containerID + ".scope\n",
+		"/proc/100/limits":   "Limit                     Soft Limit           Hard Limit           Units\nMax open files            1024                 4096                 files\n",
+		"/proc/100/fdinfo/3": "pos:\t0\nflags:\t00\nmnt_id:\t15\ninotify wd:1 ino:a sdev:1 mask:100\ninotify wd:2 ino:b sdev:1 mask:100\ninotify wd:3 ino:c sdev:1 mask:100\n",
+
+		// A host process with more descriptors but no watches.
+		"/proc/200/comm":   "kubelet\n",
+		"/proc/200/cgroup": "0::/system.slice/kubelet.service\n",
+
+		// A process which exited after /proc was listed.
+		"/proc/300/comm": "short-lived\n",
+	})
+	fs.AddOrUpdateLink("/proc/100/fd/0", "/dev/null")
+	fs.AddOrUpdateLink("/proc/100/fd/3", "anon_inode:inotify")
+	for i := 0; i < 5; i++ {
+		fs.AddOrUpdateLink(fmt.Sprintf("/proc/200/fd/%d", i), "socket:[1234]")
+	}
+	fs.SetFileAccessError("/proc/300/fd", errors.New("no such file or directory"))
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "watcher-pod", Namespace: "default", UID: podUID},
+		Spec:       corev1.PodSpec{NodeName: "node1"},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "watcher", ContainerID: "containerd://" + containerID}},
+		},
+	}
+
+	runtimeInfo := &utils.RuntimeInfo{HostNodeName: "node1", CollectorList: []string{}}
+	c := NewFDUsageCollector(utils.Linux, filePaths, fs, fake.NewSimpleClientset(pod), runtimeInfo)
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	report := &FDUsageReport{}
+	if err := json.Unmarshal([]byte(c.data["fd-usage"]), report); err != nil {
+		t.Fatalf("unable to unmarshal fd-usage: %v", err)
+	}
+
+	if report.ProcessCount != 2 || report.TotalFDs != 7 || report.TotalInotifyInstances != 1 || report.TotalInotifyWatches != 3 {
+		t.Errorf("unexpected totals: %+v", report)
+	}
+
+	if len(report.TopByFDs) != 2 || report.TopByFDs[0].PID != 200 || report.TopByFDs[1].PID != 100 {
+		t.Errorf("unexpected processes by FDs: %+v", report.TopByFDs)
+	}
+
+	if len(report.TopByInotifyWatches) != 1 {
+		t.Fatalf("expected 1 process by inotify watches, found %+v", report.TopByInotifyWatches)
+	}
+	watcher := report.TopByInotifyWatches[0]
+	if watcher.Command != "watcher" || watcher.InotifyWatches != 3 || watcher.FDSoftLimit != "1024" {
+		t.Errorf("unexpected watcher usage: %+v", watcher)
+	}
+	if watcher.ContainerID != containerID || watcher.PodUID != podUID || watcher.Namespace != "default" || watcher.Pod != "watcher-pod" || watcher.Container != "watcher" {
+		t.Errorf("unexpected watcher container: %+v", watcher)
+	}
+	if kubelet := report.TopByFDs[0]; len(kubelet.ContainerID) > 0 || !strings.Contains(kubelet.Command, "kubelet") {
+		t.Errorf("unexpected kubelet usage: %+v", kubelet)
+	}
+}
@@ -0,0 +1,232 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// Argo CD Applications whose last reconciliation is older than this are reported as stale. Flux doesn't record
+// the time of routine reconciliations, so staleness is not reported for Flux resources.
+const gitOpsStaleThreshold = time.Hour
+
+var crdGVR = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+// The GitOps resources reported on, by CRD name.
+var gitOpsCRDs = map[string]string{
+	"applications.argoproj.io":                   "Application",
+	"kustomizations.kustomize.toolkit.fluxcd.io": "Kustomization",
+	"helmreleases.helm.toolkit.fluxcd.io":        "HelmRelease",
+}
+
+type GitOpsResourceStatus struct {
+	Kind           string     `json:"kind"`
+	Namespace      string     `json:"namespace"`
+	Name           string     `json:"name"`
+	SyncStatus     string     `json:"syncStatus,omitempty"`
+	HealthStatus   string     `json:"healthStatus,omitempty"`
+	Ready          string     `json:"ready,omitempty"`
+	Suspended      bool       `json:"suspended"`
+	Revision       string     `json:"revision,omitempty"`
+	LastReconciled *time.Time `json:"lastReconciled,omitempty"`
+	Errors         []string   `json:"errors"`
+	Stale          bool       `json:"stale"`
+	Failing        bool       `json:"failing"`
+}
+
+// GitOpsCollector defines a GitOps (Flux and Argo CD) Collector struct
+type GitOpsCollector struct {
+	data          map[string]string
+	dynamicClient dynamic.Interface
+	runtimeInfo   *utils.RuntimeInfo
+	now           func() time.Time
+}
+
+// NewGitOpsCollector is a constructor
+func NewGitOpsCollector(dynamicClient dynamic.Interface, runtimeInfo *utils.RuntimeInfo) *GitOpsCollector {
+	return &GitOpsCollector{
+		data:          make(map[string]string),
+		dynamicClient: dynamicClient,
+		runtimeInfo:   runtimeInfo,
+		now:           time.Now,
+	}
+}
+
+func (collector *GitOpsCollector) GetName() string {
+	return "gitops"
+}
+
+func (collector *GitOpsCollector) CheckSupported() error {
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *GitOpsCollector) Collect() error {
+	ctx := context.Background()
+
+	crds, err := collector.dynamicClient.Resource(crdGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list CRDs: %w", err)
+	}
+
+	result := []GitOpsResourceStatus{}
+	found := false
+	for i := range crds.Items {
+		crd := &crds.Items[i]
+		kind, ok := gitOpsCRDs[crd.GetName()]
+		if !ok {
+			continue
+		}
+		found = true
+
+		gvr, err := utils.GetStorageGVRFromCRD(crd)
+		if err != nil {
+			return fmt.Errorf("unable to get resource version for %s: %w", crd.GetName(), err)
+		}
+
+		resources, err := collector.dynamicClient.Resource(*gvr).Namespace(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("unable to list %s: %w", crd.GetName(), err)
+		}
+
+		for i := range resources.Items {
+			var status GitOpsResourceStatus
+			if kind == "Application" {
+				status = getArgoApplicationStatus(&resources.Items[i])
+			} else {
+				status = getFluxResourceStatus(kind, &resources.Items[i])
+			}
+			status.Stale = !status.Suspended && status.LastReconciled != nil && collector.now().Sub(*status.LastReconciled) > gitOpsStaleThreshold
+			result = append(result, status)
+		}
+	}
+
+	// Nothing to report if neither Flux nor Argo CD is installed.
+	if !found {
+		return nil
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return fmt.Sprintf("%s/%s/%s", result[i].Kind, result[i].Namespace, result[i].Name) < fmt.Sprintf("%s/%s/%s", result[j].Kind, result[j].Namespace, result[j].Name)
+	})
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshall GitOps status to json: %w", err)
+	}
+
+	collector.data["gitops"] = string(data)
+
+	return nil
+}
+
+// getArgoApplicationStatus reads the status of an Argo CD Application. See:
+// https://argo-cd.readthedocs.io/en/stable/operator-manual/declarative-setup/#applications
+func getArgoApplicationStatus(app *unstructured.Unstructured) GitOpsResourceStatus {
+	status := GitOpsResourceStatus{
+		Kind:      "Application",
+		Namespace: app.GetNamespace(),
+		Name:      app.GetName(),
+		Errors:    []string{},
+	}
+
+	status.SyncStatus, _, _ = unstructured.NestedString(app.Object, "status", "sync", "status")
+	status.HealthStatus, _, _ = unstructured.NestedString(app.Object, "status", "health", "status")
+	status.Revision, _, _ = unstructured.NestedString(app.Object, "status", "sync", "revision")
+	// The application controller refreshes this periodically (every 3 minutes by default), even without changes.
+	status.LastReconciled = getNestedTime(app, "status", "reconciledAt")
+
+	phase, _, _ := unstructured.NestedString(app.Object, "status", "operationState", "phase")
+	if phase == "Failed" || phase == "Error" {
+		message, _, _ := unstructured.NestedString(app.Object, "status", "operationState", "message")
+		status.Errors = append(status.Errors, fmt.Sprintf("last sync %s: %s", strings.ToLower(phase), message))
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(app.Object, "status", "conditions")
+	for _, item := range conditions {
+		condition, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditionType, _, _ := unstructured.NestedString(condition, "type")
+		message, _, _ := unstructured.NestedString(condition, "message")
+		if strings.HasSuffix(conditionType, "Error") {
+			status.Errors = append(status.Errors, fmt.Sprintf("%s: %s", conditionType, message))
+		}
+	}
+
+	status.Failing = (status.SyncStatus != "" && status.SyncStatus != "Synced") ||
+		(status.HealthStatus != "" && status.HealthStatus != "Healthy") ||
+		len(status.Errors) > 0
+
+	return status
+}
+
+// getFluxResourceStatus reads the status of a Flux Kustomization or HelmRelease, which report their
+// reconciliation result in a 'Ready' condition. See: https://fluxcd.io/flux/components/kustomize/kustomizations/#status
+func getFluxResourceStatus(kind string, resource *unstructured.Unstructured) GitOpsResourceStatus {
+	status := GitOpsResourceStatus{
+		Kind:      kind,
+		Namespace: resource.GetNamespace(),
+		Name:      resource.GetName(),
+		Errors:    []string{},
+	}
+
+	status.Suspended, _, _ = unstructured.NestedBool(resource.Object, "spec", "suspend")
+	status.Revision, _, _ = unstructured.NestedString(resource.Object, "status", "lastAppliedRevision")
+
+	conditions, _, _ := unstructured.NestedSlice(resource.Object, "status", "conditions")
+	for _, item := range conditions {
+		condition, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditionType, _, _ := unstructured.NestedString(condition, "type")
+		if conditionType != "Ready" {
+			continue
+		}
+
+		conditionStatus, _, _ := unstructured.NestedString(condition, "status")
+		reason, _, _ := unstructured.NestedString(condition, "reason")
+		message, _, _ := unstructured.NestedString(condition, "message")
+		status.Ready = conditionStatus
+		if conditionStatus == "False" {
+			status.Errors = append(status.Errors, fmt.Sprintf("%s: %s", reason, message))
+		}
+	}
+
+	status.Failing = status.Ready == "False"
+
+	return status
+}
+
+func getNestedTime(obj *unstructured.Unstructured, fields ...string) *time.Time {
+	value, found, err := unstructured.NestedString(obj.Object, fields...)
+	if !found || err != nil {
+		return nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+func (collector *GitOpsCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
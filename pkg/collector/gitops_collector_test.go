@@ -0,0 +1,170 @@
+package collector
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+)
+
+func TestGitOpsCollectorGetName(t *testing.T) {
+	const expectedName = "gitops"
+
+	c := NewGitOpsCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestGitOpsCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewGitOpsCollector(nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestGitOpsCollectorCollect(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	newCRD := func(name, version string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apiextensions.k8s.io/v1",
+			"kind":       "CustomResourceDefinition",
+			"metadata":   map[string]interface{}{"name": name},
+			"spec": map[string]interface{}{
+				"versions": []interface{}{
+					map[string]interface{}{"name": version, "storage": true},
+				},
+			},
+		}}
+	}
+
+	outOfSyncApp := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Application",
+		"metadata":   map[string]interface{}{"name": "guestbook", "namespace": "argocd"},
+		"status": map[string]interface{}{
+			"sync":         map[string]interface{}{"status": "OutOfSync", "revision": "abc123"},
+			"health":       map[string]interface{}{"status": "Degraded"},
+			"reconciledAt": now.Add(-2 * time.Hour).Format(time.RFC3339),
+			"operationState": map[string]interface{}{
+				"phase":   "Failed",
+				"message": "one or more objects failed to apply",
+			},
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "ComparisonError", "message": "rpc error: repository not accessible"},
+			},
+		},
+	}}
+	syncedApp := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Application",
+		"metadata":   map[string]interface{}{"name": "healthy", "namespace": "argocd"},
+		"status": map[string]interface{}{
+			"sync":         map[string]interface{}{"status": "Synced"},
+			"health":       map[string]interface{}{"status": "Healthy"},
+			"reconciledAt": now.Add(-time.Minute).Format(time.RFC3339),
+		},
+	}}
+
+	crdGVR := schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+	appGVR := schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "applications"}
+	listKinds := map[schema.GroupVersionResource]string{
+		crdGVR: "CustomResourceDefinitionList",
+		appGVR: "ApplicationList",
+	}
+
+	tests := []struct {
+		name     string
+		objects  []runtime.Object
+		wantData bool
+	}{
+		{
+			name:     "no GitOps CRDs",
+			objects:  []runtime.Object{newCRD("widgets.example.com", "v1")},
+			wantData: false,
+		},
+		{
+			name:     "Argo CD applications",
+			objects:  []runtime.Object{newCRD("applications.argoproj.io", "v1alpha1"), outOfSyncApp, syncedApp},
+			wantData: true,
+		},
+	}
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dynamicClient := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, tt.objects...)
+
+			c := NewGitOpsCollector(dynamicClient, runtimeInfo)
+			c.now = func() time.Time { return now }
+			if err := c.Collect(); err != nil {
+				t.Fatalf("Collect() error = %v", err)
+			}
+
+			value, ok := c.GetData()["gitops"]
+			if ok != tt.wantData {
+				t.Fatalf("expected data: %t, found %t", tt.wantData, ok)
+			}
+			if !ok {
+				return
+			}
+
+			testDataValue(t, value, func(raw string) {
+				var result []GitOpsResourceStatus
+				if err := json.Unmarshal([]byte(raw), &result); err != nil {
+					t.Fatalf("unmarshal GetData(): %v", err)
+				}
+
+				if len(result) != 2 {
+					t.Fatalf("expected 2 applications, found %s", raw)
+				}
+
+				guestbook := result[0]
+				if guestbook.Name != "guestbook" || guestbook.SyncStatus != "OutOfSync" || guestbook.HealthStatus != "Degraded" {
+					t.Errorf("unexpected status: %+v", guestbook)
+				}
+				if !guestbook.Failing || !guestbook.Stale || len(guestbook.Errors) != 2 {
+					t.Errorf("expected failing, stale application with 2 errors: %+v", guestbook)
+				}
+
+				healthy := result[1]
+				if healthy.Name != "healthy" || healthy.Failing || healthy.Stale || len(healthy.Errors) != 0 {
+					t.Errorf("expected healthy application: %+v", healthy)
+				}
+			})
+		})
+	}
+}
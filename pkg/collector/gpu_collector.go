@@ -0,0 +1,148 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// The per-GPU fields summarized by `nvidia-smi --query-gpu`, which are the ones most relevant to driver and
+// hardware problems. See `nvidia-smi --help-query-gpu`.
+var nvidiaSmiQueryFields = []string{
+	"index",
+	"name",
+	"pci.bus_id",
+	"driver_version",
+	"pstate",
+	"temperature.gpu",
+	"utilization.gpu",
+	"memory.used",
+	"memory.total",
+	"ecc.errors.uncorrected.volatile.total",
+	"clocks_throttle_reasons.active",
+}
+
+// The number of lines collected from the end of each device plugin container's logs.
+const gpuDevicePluginLogLines = int64(1000)
+
+// Pods of the NVIDIA device plugin are named after its daemonset, whether deployed by AKS, its Helm chart or the
+// GPU operator (e.g. "nvidia-device-plugin-daemonset-xxxxx").
+const gpuDevicePluginPodPrefix = "nvidia-device-plugin"
+
+// GPUCollector defines a GPU Collector struct
+type GPUCollector struct {
+	data         map[string]string
+	osIdentifier utils.OSIdentifier
+	filePaths    *utils.KnownFilePaths
+	fileSystem   interfaces.FileSystemAccessor
+	runCommand   utils.HostCommandRunner
+	clientset    kubernetes.Interface
+	runtimeInfo  *utils.RuntimeInfo
+}
+
+// NewGPUCollector is a constructor
+func NewGPUCollector(osIdentifier utils.OSIdentifier, filePaths *utils.KnownFilePaths, fileSystem interfaces.FileSystemAccessor, runCommand utils.HostCommandRunner, clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *GPUCollector {
+	return &GPUCollector{
+		data:         make(map[string]string),
+		osIdentifier: osIdentifier,
+		filePaths:    filePaths,
+		fileSystem:   fileSystem,
+		runCommand:   runCommand,
+		clientset:    clientset,
+		runtimeInfo:  runtimeInfo,
+	}
+}
+
+func (collector *GPUCollector) GetName() string {
+	return "gpu"
+}
+
+func (collector *GPUCollector) CheckSupported() error {
+	// NVIDIA drivers on Windows nodes are not supported by this collector.
+	if collector.osIdentifier != utils.Linux {
+		return fmt.Errorf("unsupported OS: %s", collector.osIdentifier)
+	}
+
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	// The NVIDIA control device is created by the driver, so its absence means this is not a GPU node.
+	exists, err := collector.fileSystem.FileExists(collector.filePaths.NvidiaControlDevice)
+	if err != nil {
+		return fmt.Errorf("error checking for NVIDIA devices: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("no NVIDIA devices found at %s", collector.filePaths.NvidiaControlDevice)
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *GPUCollector) Collect() error {
+	// A failing nvidia-smi is itself a useful symptom (e.g. a driver/library version mismatch), so its output is
+	// recorded rather than failing the collector.
+	collector.data["nvidia-smi"] = collector.runNvidiaSmi("-q", "-x")
+	collector.data["nvidia-smi-summary"] = collector.runNvidiaSmi("--query-gpu="+strings.Join(nvidiaSmiQueryFields, ","), "--format=csv")
+
+	devicePluginLogs, err := collector.getDevicePluginLogs()
+	if err != nil {
+		return err
+	}
+	collector.data["gpu-device-plugin"] = devicePluginLogs
+
+	return nil
+}
+
+func (collector *GPUCollector) runNvidiaSmi(arg ...string) string {
+	output, err := collector.runCommand("nvidia-smi", arg...)
+	if err != nil {
+		return fmt.Sprintf("nvidia-smi %s failed: %v\n", strings.Join(arg, " "), err)
+	}
+
+	return output
+}
+
+// getDevicePluginLogs returns the logs of the NVIDIA device plugin pod on this node, which advertises the GPUs to the
+// kubelet. It may run in kube-system or in the GPU operator's namespace, so all namespaces are searched.
+func (collector *GPUCollector) getDevicePluginLogs() (string, error) {
+	ctx := context.Background()
+	pods, err := collector.clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: "spec.nodeName=" + collector.runtimeInfo.HostNodeName,
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to list pods on node %s: %w", collector.runtimeInfo.HostNodeName, err)
+	}
+
+	var output strings.Builder
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName != collector.runtimeInfo.HostNodeName || !strings.HasPrefix(pod.Name, gpuDevicePluginPodPrefix) {
+			continue
+		}
+
+		for _, status := range pod.Status.ContainerStatuses {
+			writePodContainerLogs(ctx, collector.clientset, &output, pod, status.Name, gpuDevicePluginLogLines, false)
+			if status.RestartCount > 0 {
+				writePodContainerLogs(ctx, collector.clientset, &output, pod, status.Name, gpuDevicePluginLogLines, true)
+			}
+		}
+	}
+
+	// Without a device plugin, GPUs are not schedulable, so make its absence explicit.
+	if output.Len() == 0 {
+		return fmt.Sprintf("no %s pod found on node %s\n", gpuDevicePluginPodPrefix, collector.runtimeInfo.HostNodeName), nil
+	}
+
+	return output.String(), nil
+}
+
+func (collector *GPUCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
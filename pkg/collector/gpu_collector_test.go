@@ -0,0 +1,170 @@
+package collector
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGPUCollectorGetName(t *testing.T) {
+	const expectedName = "gpu"
+
+	c := NewGPUCollector("", nil, nil, nil, nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestGPUCollectorCheckSupported(t *testing.T) {
+	const nvidiaControlDevice = "/dev/nvidiactl"
+	filePaths := &utils.KnownFilePaths{NvidiaControlDevice: nvidiaControlDevice}
+
+	tests := []struct {
+		name          string
+		osIdentifier  utils.OSIdentifier
+		collectorList []string
+		files         map[string]string
+		wantErr       bool
+	}{
+		{
+			name:          "windows",
+			osIdentifier:  utils.Windows,
+			collectorList: []string{},
+			files:         map[string]string{nvidiaControlDevice: ""},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{"connectedCluster"},
+			files:         map[string]string{nvidiaControlDevice: ""},
+			wantErr:       true,
+		},
+		{
+			name:          "no NVIDIA devices",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{},
+			files:         map[string]string{},
+			wantErr:       true,
+		},
+		{
+			name:          "GPU node",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{},
+			files:         map[string]string{nvidiaControlDevice: ""},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewGPUCollector(tt.osIdentifier, filePaths, test.NewFakeFileSystem(tt.files), nil, nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestGPUCollectorCollect(t *testing.T) {
+	const nodeName = "gpu-node-1"
+	const nvidiaSmiXML = `<?xml version="1.0" ?><nvidia_smi_log><driver_version>535.54.03</driver_version></nvidia_smi_log>`
+	const nvidiaSmiCSV = "index, name, pci.bus_id\n0, Tesla T4, 00000001:00:00.0\n"
+
+	newPod := func(namespace, name, node string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec:       corev1.PodSpec{NodeName: node},
+			Status:     corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Name: "nvidia-device-plugin-ctr"}}},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		pods           []*corev1.Pod
+		nvidiaSmiErr   error
+		wantNvidiaSmi  string
+		wantSummary    string
+		wantPluginLogs string
+	}{
+		{
+			name: "device plugin on node",
+			pods: []*corev1.Pod{
+				newPod(metav1.NamespaceSystem, "nvidia-device-plugin-daemonset-abcde", nodeName),
+				newPod(metav1.NamespaceSystem, "nvidia-device-plugin-daemonset-fghij", "gpu-node-2"),
+				newPod(metav1.NamespaceSystem, "coredns-12345", nodeName),
+			},
+			wantNvidiaSmi:  nvidiaSmiXML,
+			wantSummary:    nvidiaSmiCSV,
+			wantPluginLogs: "==> nvidia-device-plugin-daemonset-abcde/nvidia-device-plugin-ctr <==\nfake logs\n\n",
+		},
+		{
+			name:           "nvidia-smi failure and no device plugin",
+			pods:           []*corev1.Pod{},
+			nvidiaSmiErr:   errors.New("Failed to initialize NVML: Driver/library version mismatch"),
+			wantNvidiaSmi:  "nvidia-smi -q -x failed: Failed to initialize NVML: Driver/library version mismatch\n",
+			wantPluginLogs: "no nvidia-device-plugin pod found on node gpu-node-1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset()
+			for _, pod := range tt.pods {
+				if _, err := clientset.CoreV1().Pods(pod.Namespace).Create(nil, pod, metav1.CreateOptions{}); err != nil {
+					t.Fatalf("unable to create pod: %v", err)
+				}
+			}
+
+			runCommand := func(command string, arg ...string) (string, error) {
+				if command != "nvidia-smi" {
+					return "", errors.New("unexpected command")
+				}
+				if tt.nvidiaSmiErr != nil {
+					return "", tt.nvidiaSmiErr
+				}
+				if strings.Join(arg, " ") == "-q -x" {
+					return nvidiaSmiXML, nil
+				}
+				return nvidiaSmiCSV, nil
+			}
+
+			runtimeInfo := &utils.RuntimeInfo{
+				HostNodeName:  nodeName,
+				CollectorList: []string{},
+			}
+			c := NewGPUCollector(utils.Linux, &utils.KnownFilePaths{}, nil, runCommand, clientset, runtimeInfo)
+			if err := c.Collect(); err != nil {
+				t.Fatalf("Collect() error = %v", err)
+			}
+
+			data := c.GetData()
+			testDataValue(t, data["nvidia-smi"], func(actual string) {
+				if actual != tt.wantNvidiaSmi {
+					t.Errorf("unexpected nvidia-smi output:\nExpected %q\nFound    %q", tt.wantNvidiaSmi, actual)
+				}
+			})
+			if tt.wantSummary != "" {
+				testDataValue(t, data["nvidia-smi-summary"], func(actual string) {
+					if actual != tt.wantSummary {
+						t.Errorf("unexpected nvidia-smi summary:\nExpected %q\nFound    %q", tt.wantSummary, actual)
+					}
+				})
+			}
+			testDataValue(t, data["gpu-device-plugin"], func(actual string) {
+				if actual != tt.wantPluginLogs {
+					t.Errorf("unexpected device plugin logs:\nExpected %q\nFound    %q", tt.wantPluginLogs, actual)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package collector
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	"helm.sh/helm/v3/pkg/release"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const redactedValue = "[REDACTED]"
+
+var gzipMagic = []byte{0x1f, 0x8b, 0x08}
+
+type HelmReleaseInfo struct {
+	Name         string                 `json:"name"`
+	Namespace    string                 `json:"namespace"`
+	Revision     int                    `json:"revision"`
+	Status       release.Status         `json:"status"`
+	ChartName    string                 `json:"chart"`
+	ChartVersion string                 `json:"chartVersion"`
+	AppVersion   string                 `json:"appVersion"`
+	Values       map[string]interface{} `json:"values"`
+}
+
+// HelmReleaseCollector defines a Helm Release Collector struct
+type HelmReleaseCollector struct {
+	data        map[string]string
+	clientset   kubernetes.Interface
+	runtimeInfo *utils.RuntimeInfo
+}
+
+// NewHelmReleaseCollector is a constructor
+func NewHelmReleaseCollector(clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *HelmReleaseCollector {
+	return &HelmReleaseCollector{
+		data:        make(map[string]string),
+		clientset:   clientset,
+		runtimeInfo: runtimeInfo,
+	}
+}
+
+func (collector *HelmReleaseCollector) GetName() string {
+	return "helmreleases"
+}
+
+func (collector *HelmReleaseCollector) CheckSupported() error {
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *HelmReleaseCollector) Collect() error {
+	// Helm stores each revision of each release in a secret of this type, labelled with the release details.
+	secretList, err := collector.clientset.CoreV1().Secrets(metav1.NamespaceAll).List(context.Background(), metav1.ListOptions{
+		LabelSelector: "owner=helm",
+		FieldSelector: "type=helm.sh/release.v1",
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list helm release secrets: %w", err)
+	}
+
+	// Only the latest revision of each release is reported.
+	latestReleases := map[string]*release.Release{}
+	for _, secret := range secretList.Items {
+		if secret.Type != "helm.sh/release.v1" {
+			continue
+		}
+
+		rls, err := decodeHelmRelease(secret.Data["release"])
+		if err != nil {
+			log.Printf("Unable to decode helm release secret %s/%s: %v", secret.Namespace, secret.Name, err)
+			continue
+		}
+
+		key := fmt.Sprintf("%s_%s", rls.Namespace, rls.Name)
+		if latest, ok := latestReleases[key]; !ok || rls.Version > latest.Version {
+			latestReleases[key] = rls
+		}
+	}
+
+	for key, rls := range latestReleases {
+		info := HelmReleaseInfo{
+			Name:      rls.Name,
+			Namespace: rls.Namespace,
+			Revision:  rls.Version,
+		}
+		if rls.Info != nil {
+			info.Status = rls.Info.Status
+		}
+		if rls.Chart != nil && rls.Chart.Metadata != nil {
+			info.ChartName = rls.Chart.Metadata.Name
+			info.ChartVersion = rls.Chart.Metadata.Version
+			info.AppVersion = rls.Chart.Metadata.AppVersion
+		}
+
+		// User-supplied values frequently contain credentials, so by default only their structure is included.
+		if collector.runtimeInfo.HelmReleaseValues {
+			info.Values = rls.Config
+		} else {
+			info.Values = redactValues(rls.Config)
+		}
+
+		data, err := json.Marshal(info)
+		if err != nil {
+			return fmt.Errorf("marshall helm release %s to json: %w", key, err)
+		}
+
+		collector.data[key] = string(data)
+	}
+
+	return nil
+}
+
+// decodeHelmRelease decodes the content of a Helm release secret, which is a base64-encoded, gzipped,
+// JSON-serialized release.
+func decodeHelmRelease(data []byte) (*release.Release, error) {
+	decoded, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding base64 content: %w", err)
+	}
+
+	if bytes.HasPrefix(decoded, gzipMagic) {
+		reader, err := gzip.NewReader(bytes.NewReader(decoded))
+		if err != nil {
+			return nil, fmt.Errorf("error reading gzip content: %w", err)
+		}
+		defer reader.Close()
+
+		decoded, err = io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("error decompressing content: %w", err)
+		}
+	}
+
+	var rls release.Release
+	if err := json.Unmarshal(decoded, &rls); err != nil {
+		return nil, fmt.Errorf("error unmarshalling release: %w", err)
+	}
+
+	return &rls, nil
+}
+
+// redactValues returns a copy of the values with the same structure, but with all leaf values redacted.
+func redactValues(values map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		result[key] = redactValue(value)
+	}
+	return result
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return redactValues(v)
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = redactValue(item)
+		}
+		return result
+	case nil:
+		return nil
+	default:
+		return redactedValue
+	}
+}
+
+func (collector *HelmReleaseCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
@@ -0,0 +1,135 @@
+package collector
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestHelmReleaseCollectorGetName(t *testing.T) {
+	const expectedName = "helmreleases"
+
+	c := NewHelmReleaseCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestHelmReleaseCollectorCheckSupported(t *testing.T) {
+	c := NewHelmReleaseCollector(nil, &utils.RuntimeInfo{})
+	if err := c.CheckSupported(); err != nil {
+		t.Errorf("CheckSupported() error = %v", err)
+	}
+}
+
+func newHelmReleaseSecret(t *testing.T, rls *release.Release) *corev1.Secret {
+	content, err := json.Marshal(rls)
+	if err != nil {
+		t.Fatalf("error marshalling release: %v", err)
+	}
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(content); err != nil {
+		t.Fatalf("error compressing release: %v", err)
+	}
+	writer.Close()
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("sh.helm.release.v1.%s.v%d", rls.Name, rls.Version),
+			Namespace: rls.Namespace,
+			Labels:    map[string]string{"owner": "helm", "name": rls.Name},
+		},
+		Type: "helm.sh/release.v1",
+		Data: map[string][]byte{"release": []byte(base64.StdEncoding.EncodeToString(buf.Bytes()))},
+	}
+}
+
+func TestHelmReleaseCollectorCollect(t *testing.T) {
+	newRelease := func(version int, status release.Status, chartVersion string) *release.Release {
+		return &release.Release{
+			Name:      "ingress",
+			Namespace: "ingress-basic",
+			Version:   version,
+			Info:      &release.Info{Status: status},
+			Chart:     &chart.Chart{Metadata: &chart.Metadata{Name: "ingress-nginx", Version: chartVersion, AppVersion: "1.9.0"}},
+			Config: map[string]interface{}{
+				"controller": map[string]interface{}{
+					"replicaCount": 2,
+					"password":     "hunter2",
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name             string
+		includeValues    bool
+		wantPasswordInfo string
+	}{
+		{
+			name:             "values redacted by default",
+			includeValues:    false,
+			wantPasswordInfo: redactedValue,
+		},
+		{
+			name:             "values included when opted in",
+			includeValues:    true,
+			wantPasswordInfo: "hunter2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(
+				newHelmReleaseSecret(t, newRelease(1, release.StatusSuperseded, "4.7.0")),
+				newHelmReleaseSecret(t, newRelease(2, release.StatusDeployed, "4.8.0")),
+			)
+
+			runtimeInfo := &utils.RuntimeInfo{
+				HelmReleaseValues: tt.includeValues,
+			}
+
+			c := NewHelmReleaseCollector(clientset, runtimeInfo)
+			if err := c.Collect(); err != nil {
+				t.Fatalf("Collect() error = %v", err)
+			}
+
+			data := c.GetData()
+			if len(data) != 1 {
+				t.Fatalf("expected 1 release, found %d", len(data))
+			}
+
+			testDataValue(t, data["ingress-basic_ingress"], func(raw string) {
+				var info HelmReleaseInfo
+				if err := json.Unmarshal([]byte(raw), &info); err != nil {
+					t.Fatalf("unmarshal GetData(): %v", err)
+				}
+
+				if info.Revision != 2 || info.Status != release.StatusDeployed || info.ChartVersion != "4.8.0" || info.ChartName != "ingress-nginx" {
+					t.Errorf("unexpected release info: %+v", info)
+				}
+
+				controller, ok := info.Values["controller"].(map[string]interface{})
+				if !ok {
+					t.Fatalf("expected controller values, found %v", info.Values)
+				}
+				if controller["password"] != tt.wantPasswordInfo {
+					t.Errorf("expected password value %s, found %v", tt.wantPasswordInfo, controller["password"])
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+type HugePagesStatus struct {
+	Sizes      []HugePagesSize     `json:"sizes"`
+	MemInfo    map[string]int64    `json:"memInfo"`
+	Mismatches []HugePagesMismatch `json:"mismatches"`
+}
+
+type HugePagesSize struct {
+	Size           string `json:"size"`
+	Total          int64  `json:"total"`
+	Free           int64  `json:"free"`
+	Reserved       int64  `json:"reserved"`
+	Surplus        int64  `json:"surplus"`
+	RequestedPages int64  `json:"requestedPages"`
+	Overcommitted  bool   `json:"overcommitted"`
+}
+
+type HugePagesMismatch struct {
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	Size      string `json:"size"`
+	Requested string `json:"requested"`
+	Reason    string `json:"reason"`
+}
+
+// HugePagesCollector defines a HugePages Collector struct
+type HugePagesCollector struct {
+	data         map[string]string
+	osIdentifier utils.OSIdentifier
+	filePaths    *utils.KnownFilePaths
+	fileSystem   interfaces.FileSystemAccessor
+	clientset    kubernetes.Interface
+	runtimeInfo  *utils.RuntimeInfo
+}
+
+// NewHugePagesCollector is a constructor
+func NewHugePagesCollector(osIdentifier utils.OSIdentifier, filePaths *utils.KnownFilePaths, fileSystem interfaces.FileSystemAccessor, clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *HugePagesCollector {
+	return &HugePagesCollector{
+		data:         make(map[string]string),
+		osIdentifier: osIdentifier,
+		filePaths:    filePaths,
+		fileSystem:   fileSystem,
+		clientset:    clientset,
+		runtimeInfo:  runtimeInfo,
+	}
+}
+
+func (collector *HugePagesCollector) GetName() string {
+	return "hugepages"
+}
+
+func (collector *HugePagesCollector) CheckSupported() error {
+	if collector.osIdentifier != utils.Linux {
+		return fmt.Errorf("unsupported OS: %s", collector.osIdentifier)
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *HugePagesCollector) Collect() error {
+	status := HugePagesStatus{
+		Sizes:      []HugePagesSize{},
+		Mismatches: []HugePagesMismatch{},
+	}
+
+	// The hugepages directory contains a subdirectory for each supported page size, e.g. hugepages-2048kB.
+	files, err := collector.fileSystem.ListFiles(collector.filePaths.HugePages)
+	if err != nil {
+		return fmt.Errorf("unable to list hugepages in %s: %w", collector.filePaths.HugePages, err)
+	}
+
+	sizesByBytes := map[int64]*HugePagesSize{}
+	for _, file := range files {
+		sizeKB, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(path.Base(path.Dir(file)), "hugepages-"), "kB"), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		size, ok := sizesByBytes[sizeKB*1024]
+		if !ok {
+			size = &HugePagesSize{Size: getHugePageSizeName(sizeKB * 1024)}
+			sizesByBytes[sizeKB*1024] = size
+		}
+
+		value, err := collector.readInt(file)
+		if err != nil {
+			continue
+		}
+
+		switch path.Base(file) {
+		case "nr_hugepages":
+			size.Total = value
+		case "free_hugepages":
+			size.Free = value
+		case "resv_hugepages":
+			size.Reserved = value
+		case "surplus_hugepages":
+			size.Surplus = value
+		}
+	}
+
+	memInfo, err := collector.readMemInfo()
+	if err != nil {
+		return err
+	}
+	status.MemInfo = memInfo
+
+	podList, err := collector.clientset.CoreV1().Pods(metav1.NamespaceAll).List(context.Background(), metav1.ListOptions{
+		FieldSelector: "spec.nodeName=" + collector.runtimeInfo.HostNodeName,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list pods on node %s: %w", collector.runtimeInfo.HostNodeName, err)
+	}
+
+	requestedBytes := map[int64]int64{}
+	for _, pod := range podList.Items {
+		if pod.Spec.NodeName != collector.runtimeInfo.HostNodeName {
+			continue
+		}
+
+		// Completed pods no longer hold their hugepages.
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+
+		for sizeBytes, requested := range getPodHugePagesRequests(&pod) {
+			requestedBytes[sizeBytes] += requested.Value()
+
+			sizeName := getHugePageSizeName(sizeBytes)
+			if size, ok := sizesByBytes[sizeBytes]; !ok || size.Total == 0 {
+				status.Mismatches = append(status.Mismatches, HugePagesMismatch{
+					Namespace: pod.Namespace,
+					Pod:       pod.Name,
+					Size:      sizeName,
+					Requested: requested.String(),
+					Reason:    fmt.Sprintf("no %s hugepages are configured on the node", sizeName),
+				})
+			}
+		}
+	}
+
+	for sizeBytes, size := range sizesByBytes {
+		size.RequestedPages = requestedBytes[sizeBytes] / sizeBytes
+		size.Overcommitted = size.RequestedPages > size.Total
+		status.Sizes = append(status.Sizes, *size)
+	}
+
+	sort.Slice(status.Sizes, func(i, j int) bool {
+		return status.Sizes[i].Size < status.Sizes[j].Size
+	})
+	sort.Slice(status.Mismatches, func(i, j int) bool {
+		a, b := status.Mismatches[i], status.Mismatches[j]
+		return a.Namespace+"/"+a.Pod+"/"+a.Size < b.Namespace+"/"+b.Pod+"/"+b.Size
+	})
+
+	data, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("marshall hugepages to json: %w", err)
+	}
+
+	collector.data["hugepages"] = string(data)
+
+	return nil
+}
+
+func (collector *HugePagesCollector) readInt(filePath string) (int64, error) {
+	content, err := utils.GetContent(func() (io.ReadCloser, error) { return collector.fileSystem.GetFileReader(filePath) })
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseInt(strings.TrimSpace(content), 10, 64)
+}
+
+// readMemInfo reads the hugepages fields from /proc/meminfo, which describe the default page size.
+// Sizes are in kB, and counts in pages.
+func (collector *HugePagesCollector) readMemInfo() (map[string]int64, error) {
+	memInfoPath := path.Join(collector.filePaths.Proc, "meminfo")
+	content, err := utils.GetContent(func() (io.ReadCloser, error) { return collector.fileSystem.GetFileReader(memInfoPath) })
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", memInfoPath, err)
+	}
+
+	// Lines are of the form `HugePages_Total:       0` or `Hugepagesize:       2048 kB`
+	memInfo := map[string]int64{}
+	for _, line := range strings.Split(content, "\n") {
+		name, value, found := strings.Cut(line, ":")
+		if !found || !strings.HasPrefix(strings.ToLower(name), "huge") {
+			continue
+		}
+
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if number, err := strconv.ParseInt(fields[0], 10, 64); err == nil {
+			memInfo[name] = number
+		}
+	}
+
+	return memInfo, nil
+}
+
+// getPodHugePagesRequests returns the effective hugepages requests of a pod, keyed by page size in bytes.
+// As with other resources, this is the greater of the sum of the app containers and the largest init container.
+func getPodHugePagesRequests(pod *corev1.Pod) map[int64]resource.Quantity {
+	requests := map[int64]resource.Quantity{}
+	forEachHugePagesRequest := func(container *corev1.Container, apply func(sizeBytes int64, quantity resource.Quantity)) {
+		for name, quantity := range container.Resources.Requests {
+			if !strings.HasPrefix(string(name), corev1.ResourceHugePagesPrefix) {
+				continue
+			}
+
+			size, err := resource.ParseQuantity(strings.TrimPrefix(string(name), corev1.ResourceHugePagesPrefix))
+			if err != nil || size.Value() <= 0 {
+				continue
+			}
+
+			apply(size.Value(), quantity)
+		}
+	}
+
+	for i := range pod.Spec.Containers {
+		forEachHugePagesRequest(&pod.Spec.Containers[i], func(sizeBytes int64, quantity resource.Quantity) {
+			total := requests[sizeBytes]
+			total.Add(quantity)
+			requests[sizeBytes] = total
+		})
+	}
+
+	for i := range pod.Spec.InitContainers {
+		forEachHugePagesRequest(&pod.Spec.InitContainers[i], func(sizeBytes int64, quantity resource.Quantity) {
+			if total := requests[sizeBytes]; quantity.Cmp(total) > 0 {
+				requests[sizeBytes] = quantity
+			}
+		})
+	}
+
+	return requests
+}
+
+// getHugePageSizeName formats a page size as used in the resource name, e.g. 2Mi or 1Gi.
+func getHugePageSizeName(sizeBytes int64) string {
+	return resource.NewQuantity(sizeBytes, resource.BinarySI).String()
+}
+
+func (collector *HugePagesCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
@@ -0,0 +1,143 @@
+package collector
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestHugePagesCollectorGetName(t *testing.T) {
+	const expectedName = "hugepages"
+
+	c := NewHugePagesCollector("", nil, nil, nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestHugePagesCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		osIdentifier utils.OSIdentifier
+		wantErr      bool
+	}{
+		{
+			osIdentifier: utils.Windows,
+			wantErr:      true,
+		},
+		{
+			osIdentifier: utils.Linux,
+			wantErr:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		c := NewHugePagesCollector(tt.osIdentifier, nil, nil, nil, nil)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("CheckSupported() error = %v, wantErr %v", err, tt.wantErr)
+		}
+	}
+}
+
+func TestHugePagesCollectorCollect(t *testing.T) {
+	const nodeName = "node-1"
+
+	filePaths := &utils.KnownFilePaths{
+		Proc:      "/proc",
+		HugePages: "/sys/kernel/mm/hugepages",
+	}
+
+	fileSystem := test.NewFakeFileSystem(map[string]string{
+		"/proc/meminfo": `MemTotal:        8129364 kB
+MemFree:         1234567 kB
+HugePages_Total:     512
+HugePages_Free:      448
+HugePages_Rsvd:        0
+HugePages_Surp:        0
+Hugepagesize:       2048 kB
+Hugetlb:         1048576 kB
+`,
+		"/sys/kernel/mm/hugepages/hugepages-2048kB/nr_hugepages":         "512\n",
+		"/sys/kernel/mm/hugepages/hugepages-2048kB/free_hugepages":       "448\n",
+		"/sys/kernel/mm/hugepages/hugepages-2048kB/resv_hugepages":       "0\n",
+		"/sys/kernel/mm/hugepages/hugepages-2048kB/surplus_hugepages":    "0\n",
+		"/sys/kernel/mm/hugepages/hugepages-1048576kB/nr_hugepages":      "0\n",
+		"/sys/kernel/mm/hugepages/hugepages-1048576kB/free_hugepages":    "0\n",
+		"/sys/kernel/mm/hugepages/hugepages-1048576kB/resv_hugepages":    "0\n",
+		"/sys/kernel/mm/hugepages/hugepages-1048576kB/surplus_hugepages": "0\n",
+	})
+
+	newPod := func(name, node string, requests corev1.ResourceList) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app"},
+			Spec: corev1.PodSpec{
+				NodeName: node,
+				Containers: []corev1.Container{
+					{Name: "main", Resources: corev1.ResourceRequirements{Requests: requests}},
+				},
+			},
+		}
+	}
+
+	clientset := fake.NewSimpleClientset(
+		newPod("dpdk", nodeName, corev1.ResourceList{
+			"hugepages-2Mi":       resource.MustParse("1536Mi"),
+			corev1.ResourceMemory: resource.MustParse("1Gi"),
+		}),
+		newPod("database", nodeName, corev1.ResourceList{
+			"hugepages-1Gi": resource.MustParse("2Gi"),
+		}),
+		newPod("no-hugepages", nodeName, corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse("100m"),
+		}),
+		newPod("other-node", "node-2", corev1.ResourceList{
+			"hugepages-2Mi": resource.MustParse("1Gi"),
+		}),
+	)
+
+	runtimeInfo := &utils.RuntimeInfo{
+		HostNodeName: nodeName,
+	}
+
+	c := NewHugePagesCollector(utils.Linux, filePaths, fileSystem, clientset, runtimeInfo)
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	testDataValue(t, c.GetData()["hugepages"], func(raw string) {
+		var status HugePagesStatus
+		if err := json.Unmarshal([]byte(raw), &status); err != nil {
+			t.Fatalf("unmarshal GetData(): %v", err)
+		}
+
+		expectedSizes := []HugePagesSize{
+			{Size: "1Gi", RequestedPages: 2, Overcommitted: true},
+			{Size: "2Mi", Total: 512, Free: 448, RequestedPages: 768, Overcommitted: true},
+		}
+		if !reflect.DeepEqual(status.Sizes, expectedSizes) {
+			t.Errorf("expected sizes %+v, found %+v", expectedSizes, status.Sizes)
+		}
+
+		if status.MemInfo["HugePages_Total"] != 512 || status.MemInfo["Hugepagesize"] != 2048 || status.MemInfo["Hugetlb"] != 1048576 {
+			t.Errorf("unexpected meminfo %v", status.MemInfo)
+		}
+		if _, ok := status.MemInfo["MemTotal"]; ok {
+			t.Errorf("expected only hugepages fields in meminfo, found %v", status.MemInfo)
+		}
+
+		expectedMismatches := []HugePagesMismatch{
+			{Namespace: "app", Pod: "database", Size: "1Gi", Requested: "2Gi", Reason: "no 1Gi hugepages are configured on the node"},
+		}
+		if !reflect.DeepEqual(status.Mismatches, expectedMismatches) {
+			t.Errorf("expected mismatches %+v, found %+v", expectedMismatches, status.Mismatches)
+		}
+	})
+}
@@ -0,0 +1,223 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Container waiting reasons which indicate the image could not be pulled.
+var imagePullFailureReasons = []string{"ImagePullBackOff", "ErrImagePull"}
+
+type ImagePullProblems struct {
+	Pods []ImagePullPodInfo `json:"pods"`
+	Node *ImagePullNodeInfo `json:"node,omitempty"`
+}
+
+type ImagePullPodInfo struct {
+	Namespace  string                   `json:"namespace"`
+	Name       string                   `json:"name"`
+	NodeName   string                   `json:"nodeName"`
+	Containers []ImagePullContainerInfo `json:"containers"`
+	Events     []ImagePullEvent         `json:"events"`
+}
+
+type ImagePullContainerInfo struct {
+	Name    string `json:"name"`
+	Image   string `json:"image"`
+	Reason  string `json:"reason"`
+	Message string `json:"message,omitempty"`
+}
+
+type ImagePullEvent struct {
+	Type          string    `json:"type"`
+	Reason        string    `json:"reason"`
+	Message       string    `json:"message"`
+	Count         int32     `json:"count"`
+	LastTimestamp time.Time `json:"lastTimestamp"`
+}
+
+type ImagePullNodeInfo struct {
+	Name       string           `json:"name"`
+	Images     []ContainerImage `json:"images"`
+	PullErrors []string         `json:"pullErrors"`
+}
+
+type ContainerImage struct {
+	ID          string   `json:"id"`
+	RepoTags    []string `json:"repoTags"`
+	RepoDigests []string `json:"repoDigests"`
+	Size        string   `json:"size"`
+}
+
+// ImagePullCollector defines an Image Pull Collector struct
+type ImagePullCollector struct {
+	data         map[string]string
+	osIdentifier utils.OSIdentifier
+	clientset    kubernetes.Interface
+	runCommand   utils.HostCommandRunner
+	runtimeInfo  *utils.RuntimeInfo
+}
+
+// NewImagePullCollector is a constructor
+func NewImagePullCollector(osIdentifier utils.OSIdentifier, clientset kubernetes.Interface, runCommand utils.HostCommandRunner, runtimeInfo *utils.RuntimeInfo) *ImagePullCollector {
+	return &ImagePullCollector{
+		data:         make(map[string]string),
+		osIdentifier: osIdentifier,
+		clientset:    clientset,
+		runCommand:   runCommand,
+		runtimeInfo:  runtimeInfo,
+	}
+}
+
+func (collector *ImagePullCollector) GetName() string {
+	return "imagepull"
+}
+
+func (collector *ImagePullCollector) CheckSupported() error {
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *ImagePullCollector) Collect() error {
+	ctx := context.Background()
+
+	podList, err := collector.clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list pods: %w", err)
+	}
+
+	problems := ImagePullProblems{
+		Pods: []ImagePullPodInfo{},
+	}
+
+	podIndexes := map[string]int{}
+	for _, pod := range podList.Items {
+		containers := getImagePullFailures(pod.Status.InitContainerStatuses)
+		containers = append(containers, getImagePullFailures(pod.Status.ContainerStatuses)...)
+		if len(containers) == 0 {
+			continue
+		}
+
+		podIndexes[pod.Namespace+"/"+pod.Name] = len(problems.Pods)
+		problems.Pods = append(problems.Pods, ImagePullPodInfo{
+			Namespace:  pod.Namespace,
+			Name:       pod.Name,
+			NodeName:   pod.Spec.NodeName,
+			Containers: containers,
+			Events:     []ImagePullEvent{},
+		})
+	}
+
+	if len(problems.Pods) > 0 {
+		eventList, err := collector.clientset.CoreV1().Events(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+			FieldSelector: "involvedObject.kind=Pod",
+		})
+		if err != nil {
+			return fmt.Errorf("unable to list pod events: %w", err)
+		}
+
+		for _, event := range eventList.Items {
+			index, ok := podIndexes[event.InvolvedObject.Namespace+"/"+event.InvolvedObject.Name]
+			if !ok || event.InvolvedObject.Kind != "Pod" {
+				continue
+			}
+
+			problems.Pods[index].Events = append(problems.Pods[index].Events, ImagePullEvent{
+				Type:          event.Type,
+				Reason:        event.Reason,
+				Message:       event.Message,
+				Count:         event.Count,
+				LastTimestamp: event.LastTimestamp.Time,
+			})
+		}
+
+		for _, pod := range problems.Pods {
+			sort.Slice(pod.Events, func(i, j int) bool {
+				return pod.Events[i].LastTimestamp.Before(pod.Events[j].LastTimestamp)
+			})
+		}
+	}
+
+	// The image store and containerd logs are only accessible on Linux nodes.
+	if collector.osIdentifier == utils.Linux {
+		problems.Node = collector.getNodeInfo()
+	}
+
+	data, err := json.Marshal(problems)
+	if err != nil {
+		return fmt.Errorf("marshall image pull problems to json: %w", err)
+	}
+
+	collector.data["imagepull-problems"] = string(data)
+
+	return nil
+}
+
+func getImagePullFailures(statuses []corev1.ContainerStatus) []ImagePullContainerInfo {
+	result := []ImagePullContainerInfo{}
+	for _, status := range statuses {
+		if status.State.Waiting == nil || !utils.Contains(imagePullFailureReasons, status.State.Waiting.Reason) {
+			continue
+		}
+
+		result = append(result, ImagePullContainerInfo{
+			Name:    status.Name,
+			Image:   status.Image,
+			Reason:  status.State.Waiting.Reason,
+			Message: status.State.Waiting.Message,
+		})
+	}
+	return result
+}
+
+// getNodeInfo reads the images held by containerd on this node, and any recent pull failures it has logged.
+// Failures are logged rather than returned, so that the cluster-wide results are still reported.
+func (collector *ImagePullCollector) getNodeInfo() *ImagePullNodeInfo {
+	nodeInfo := &ImagePullNodeInfo{
+		Name:       collector.runtimeInfo.HostNodeName,
+		Images:     []ContainerImage{},
+		PullErrors: []string{},
+	}
+
+	output, err := collector.runCommand("crictl", "images", "-o", "json")
+	if err != nil {
+		log.Printf("Unable to list containerd images: %v", err)
+	} else {
+		var imageList struct {
+			Images []ContainerImage `json:"images"`
+		}
+		if err := json.Unmarshal([]byte(output), &imageList); err != nil {
+			log.Printf("Unable to parse containerd image list: %v", err)
+		} else if imageList.Images != nil {
+			nodeInfo.Images = imageList.Images
+		}
+	}
+
+	output, err = collector.runCommand("journalctl", "-u", "containerd", "--since", "-1h", "--no-pager", "-o", "cat")
+	if err != nil {
+		log.Printf("Unable to read containerd logs: %v", err)
+	} else {
+		for _, line := range strings.Split(output, "\n") {
+			if strings.Contains(line, "PullImage") && strings.Contains(line, "failed") {
+				nodeInfo.PullErrors = append(nodeInfo.PullErrors, strings.TrimSpace(line))
+			}
+		}
+	}
+
+	return nodeInfo
+}
+
+func (collector *ImagePullCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
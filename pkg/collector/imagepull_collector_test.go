@@ -0,0 +1,170 @@
+package collector
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestImagePullCollectorGetName(t *testing.T) {
+	const expectedName = "imagepull"
+
+	c := NewImagePullCollector("", nil, nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestImagePullCollectorCheckSupported(t *testing.T) {
+	c := NewImagePullCollector(utils.Linux, nil, nil, nil)
+	if err := c.CheckSupported(); err != nil {
+		t.Errorf("CheckSupported() error = %v", err)
+	}
+}
+
+func TestImagePullCollectorCollect(t *testing.T) {
+	const crictlImages = `{"images":[{"id":"sha256:abc","repoTags":["mcr.microsoft.com/oss/kubernetes/pause:3.6"],"repoDigests":[],"size":"299396"}]}`
+	const containerdLogs = `time="2023-01-01T00:00:00Z" level=info msg="PullImage \"myregistry.azurecr.io/app:v2\""
+time="2023-01-01T00:00:01Z" level=error msg="PullImage \"myregistry.azurecr.io/app:v2\" failed" error="failed to resolve reference: 401 Unauthorized"
+`
+
+	backoffPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Spec:       corev1.PodSpec{NodeName: "node1"},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:  "app",
+					Image: "myregistry.azurecr.io/app:v2",
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+						Reason:  "ImagePullBackOff",
+						Message: "Back-off pulling image \"myregistry.azurecr.io/app:v2\"",
+					}},
+				},
+			},
+		},
+	}
+	healthyPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "healthy", Namespace: "default"},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:  "app",
+					State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+				},
+			},
+		},
+	}
+	pullFailedEvent := &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "app.1", Namespace: "default"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "app", Namespace: "default"},
+		Type:           corev1.EventTypeWarning,
+		Reason:         "Failed",
+		Message:        "Failed to pull image \"myregistry.azurecr.io/app:v2\": 401 Unauthorized",
+		Count:          3,
+	}
+	unrelatedEvent := &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "healthy.1", Namespace: "default"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "healthy", Namespace: "default"},
+		Type:           corev1.EventTypeNormal,
+		Reason:         "Started",
+	}
+
+	tests := []struct {
+		name           string
+		osIdentifier   utils.OSIdentifier
+		commandErr     error
+		wantNode       bool
+		wantImages     int
+		wantPullErrors int
+	}{
+		{
+			name:           "linux node",
+			osIdentifier:   utils.Linux,
+			wantNode:       true,
+			wantImages:     1,
+			wantPullErrors: 1,
+		},
+		{
+			name:           "linux node commands fail",
+			osIdentifier:   utils.Linux,
+			commandErr:     errors.New("command not found"),
+			wantNode:       true,
+			wantImages:     0,
+			wantPullErrors: 0,
+		},
+		{
+			name:         "windows node",
+			osIdentifier: utils.Windows,
+			wantNode:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runCommand := func(command string, arg ...string) (string, error) {
+				if tt.commandErr != nil {
+					return "", tt.commandErr
+				}
+				switch command {
+				case "crictl":
+					return crictlImages, nil
+				case "journalctl":
+					return containerdLogs, nil
+				}
+				return "", errors.New("unexpected command")
+			}
+
+			clientset := fake.NewSimpleClientset(backoffPod, healthyPod, pullFailedEvent, unrelatedEvent)
+			runtimeInfo := &utils.RuntimeInfo{
+				HostNodeName: "node1",
+			}
+
+			c := NewImagePullCollector(tt.osIdentifier, clientset, runCommand, runtimeInfo)
+			if err := c.Collect(); err != nil {
+				t.Fatalf("Collect() error = %v", err)
+			}
+
+			testDataValue(t, c.GetData()["imagepull-problems"], func(raw string) {
+				var result ImagePullProblems
+				if err := json.Unmarshal([]byte(raw), &result); err != nil {
+					t.Fatalf("unmarshal GetData(): %v", err)
+				}
+
+				if len(result.Pods) != 1 || result.Pods[0].Name != "app" {
+					t.Fatalf("expected only the failing pod, found %s", raw)
+				}
+
+				pod := result.Pods[0]
+				if len(pod.Containers) != 1 || pod.Containers[0].Reason != "ImagePullBackOff" || pod.Containers[0].Image != "myregistry.azurecr.io/app:v2" {
+					t.Errorf("unexpected containers: %+v", pod.Containers)
+				}
+				if len(pod.Events) != 1 || pod.Events[0].Reason != "Failed" || pod.Events[0].Count != 3 {
+					t.Errorf("unexpected events: %+v", pod.Events)
+				}
+
+				if (result.Node != nil) != tt.wantNode {
+					t.Fatalf("expected node info: %v, found %s", tt.wantNode, raw)
+				}
+				if result.Node == nil {
+					return
+				}
+				if result.Node.Name != "node1" {
+					t.Errorf("unexpected node name: %s", result.Node.Name)
+				}
+				if len(result.Node.Images) != tt.wantImages {
+					t.Errorf("expected %d images, found %d", tt.wantImages, len(result.Node.Images))
+				}
+				if len(result.Node.PullErrors) != tt.wantPullErrors {
+					t.Errorf("expected %d pull errors, found %d", tt.wantPullErrors, len(result.Node.PullErrors))
+				}
+			})
+		})
+	}
+}
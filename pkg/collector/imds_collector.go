@@ -0,0 +1,76 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+// IMDSCollector defines an Azure Instance Metadata Service Collector struct
+type IMDSCollector struct {
+	data        map[string]string
+	runtimeInfo *utils.RuntimeInfo
+	endpoint    string
+	httpClient  *http.Client
+}
+
+var imdsQueries = []struct {
+	key  string
+	path string
+}{
+	{key: "instance", path: "/metadata/instance?api-version=2021-02-01"},
+	{key: "network", path: "/metadata/instance/network?api-version=2021-02-01"},
+	{key: "scheduledevents", path: "/metadata/scheduledevents?api-version=2020-07-01"},
+}
+
+// NewIMDSCollector is a constructor
+func NewIMDSCollector(runtimeInfo *utils.RuntimeInfo, endpoint string, httpClient *http.Client) *IMDSCollector {
+	return &IMDSCollector{
+		data:        make(map[string]string),
+		runtimeInfo: runtimeInfo,
+		endpoint:    endpoint,
+		httpClient:  httpClient,
+	}
+}
+
+func (collector *IMDSCollector) GetName() string {
+	return "imds"
+}
+
+func (collector *IMDSCollector) CheckSupported() error {
+	// Connected clusters are not expected to be running on Azure VMs.
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *IMDSCollector) Collect() error {
+	for _, query := range imdsQueries {
+		content, err := utils.GetIMDSContent(collector.httpClient, collector.endpoint, query.path)
+		if err != nil {
+			if errors.Is(err, utils.ErrIMDSUnreachable) {
+				// Not running on an Azure VM (or IMDS is blocked), so there is nothing to collect.
+				log.Printf("Skipping IMDS collection: %v", err)
+				return nil
+			}
+			return err
+		}
+
+		// The response is deliberately not logged, since the instance metadata includes user-defined tags.
+		collector.data[query.key] = string(content)
+	}
+
+	return nil
+}
+
+func (collector *IMDSCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
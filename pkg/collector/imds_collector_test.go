@@ -0,0 +1,116 @@
+package collector
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+func TestIMDSCollectorGetName(t *testing.T) {
+	const expectedName = "imds"
+
+	c := NewIMDSCollector(nil, "", nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestIMDSCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewIMDSCollector(runtimeInfo, "", nil)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestIMDSCollectorCollect(t *testing.T) {
+	responses := map[string]string{
+		"/metadata/instance":         `{"compute":{"vmSize":"Standard_DS2_v2","zone":"1"}}`,
+		"/metadata/instance/network": `{"interface":[{"macAddress":"000D3A000000"}]}`,
+		"/metadata/scheduledevents":  `{"DocumentIncarnation":1,"Events":[]}`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata") != "true" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	unreachableServer := httptest.NewServer(http.NotFoundHandler())
+	unreachableServer.Close()
+
+	tests := []struct {
+		name     string
+		endpoint string
+		wantErr  bool
+		wantData map[string]*regexp.Regexp
+	}{
+		{
+			name:     "IMDS available",
+			endpoint: server.URL,
+			wantErr:  false,
+			wantData: map[string]*regexp.Regexp{
+				"instance":        regexp.MustCompile(`"vmSize":"Standard_DS2_v2"`),
+				"network":         regexp.MustCompile(`"macAddress":"000D3A000000"`),
+				"scheduledevents": regexp.MustCompile(`"DocumentIncarnation":1`),
+			},
+		},
+		{
+			name:     "IMDS unreachable",
+			endpoint: unreachableServer.URL,
+			wantErr:  false,
+			wantData: map[string]*regexp.Regexp{},
+		},
+	}
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewIMDSCollector(runtimeInfo, tt.endpoint, utils.NewIMDSClient(time.Second))
+			err := c.Collect()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Collect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			compareCollectorData(t, tt.wantData, c.GetData())
+		})
+	}
+}
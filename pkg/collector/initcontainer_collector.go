@@ -0,0 +1,182 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const initContainerLogTailLines = int64(50)
+
+type InitContainerPodInfo struct {
+	Namespace      string              `json:"namespace"`
+	Name           string              `json:"name"`
+	Phase          string              `json:"phase"`
+	InitContainers []InitContainerInfo `json:"initContainers"`
+}
+
+type InitContainerInfo struct {
+	Name                  string     `json:"name"`
+	State                 string     `json:"state"`
+	Reason                string     `json:"reason,omitempty"`
+	Message               string     `json:"message,omitempty"`
+	RestartCount          int32      `json:"restartCount"`
+	LastTerminationReason string     `json:"lastTerminationReason,omitempty"`
+	LastExitCode          int32      `json:"lastExitCode,omitempty"`
+	LastFinishedAt        *time.Time `json:"lastFinishedAt,omitempty"`
+	Logs                  string     `json:"logs,omitempty"`
+}
+
+// InitContainerCollector defines an Init Container Collector struct
+type InitContainerCollector struct {
+	data        map[string]string
+	clientset   kubernetes.Interface
+	runtimeInfo *utils.RuntimeInfo
+}
+
+// NewInitContainerCollector is a constructor
+func NewInitContainerCollector(clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *InitContainerCollector {
+	return &InitContainerCollector{
+		data:        make(map[string]string),
+		clientset:   clientset,
+		runtimeInfo: runtimeInfo,
+	}
+}
+
+func (collector *InitContainerCollector) GetName() string {
+	return "initcontainers"
+}
+
+func (collector *InitContainerCollector) CheckSupported() error {
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *InitContainerCollector) Collect() error {
+	podList, err := collector.clientset.CoreV1().Pods(metav1.NamespaceAll).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list pods: %w", err)
+	}
+
+	result := []InitContainerPodInfo{}
+	for _, pod := range podList.Items {
+		// Sidecar containers are declared as init containers, but are expected to keep running.
+		sidecars := map[string]bool{}
+		for _, container := range pod.Spec.InitContainers {
+			if container.RestartPolicy != nil && *container.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+				sidecars[container.Name] = true
+			}
+		}
+
+		initContainers := []InitContainerInfo{}
+		for _, status := range pod.Status.InitContainerStatuses {
+			if sidecars[status.Name] || isInitContainerCompleted(&status) {
+				continue
+			}
+
+			info := InitContainerInfo{
+				Name:         status.Name,
+				RestartCount: status.RestartCount,
+			}
+
+			switch {
+			case status.State.Waiting != nil:
+				info.State = "Waiting"
+				info.Reason = status.State.Waiting.Reason
+				info.Message = status.State.Waiting.Message
+			case status.State.Running != nil:
+				info.State = "Running"
+			case status.State.Terminated != nil:
+				info.State = "Terminated"
+				info.Reason = status.State.Terminated.Reason
+				info.Message = status.State.Terminated.Message
+			}
+
+			if terminated := status.LastTerminationState.Terminated; terminated != nil {
+				info.LastTerminationReason = terminated.Reason
+				info.LastExitCode = terminated.ExitCode
+				if !terminated.FinishedAt.IsZero() {
+					info.LastFinishedAt = &terminated.FinishedAt.Time
+				}
+			}
+
+			// A container that is waiting to restart has no current logs, so use those of the previous instance.
+			previous := status.State.Waiting != nil && status.RestartCount > 0
+			logs, err := getInitContainerLogs(collector.clientset, &pod, status.Name, previous)
+			if err != nil {
+				info.Logs = fmt.Sprintf("unable to get logs: %v", err)
+			} else {
+				info.Logs = logs
+			}
+
+			initContainers = append(initContainers, info)
+		}
+
+		if len(initContainers) == 0 {
+			continue
+		}
+
+		result = append(result, InitContainerPodInfo{
+			Namespace:      pod.Namespace,
+			Name:           pod.Name,
+			Phase:          string(pod.Status.Phase),
+			InitContainers: initContainers,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Namespace+"/"+result[i].Name < result[j].Namespace+"/"+result[j].Name
+	})
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshall init containers to json: %w", err)
+	}
+
+	collector.data["initcontainers"] = string(data)
+
+	return nil
+}
+
+func isInitContainerCompleted(status *corev1.ContainerStatus) bool {
+	return status.State.Terminated != nil && status.State.Terminated.ExitCode == 0
+}
+
+func getInitContainerLogs(clientset kubernetes.Interface, pod *corev1.Pod, containerName string, previous bool) (string, error) {
+	tailLines := initContainerLogTailLines
+	stream, err := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: containerName,
+		TailLines: &tailLines,
+		Previous:  previous,
+	}).Stream(context.Background())
+	if err != nil {
+		return "", err
+	}
+	defer stream.Close()
+
+	buf := new(bytes.Buffer)
+	if _, err := io.Copy(buf, stream); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func (collector *InitContainerCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
@@ -0,0 +1,141 @@
+package collector
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestInitContainerCollectorGetName(t *testing.T) {
+	const expectedName = "initcontainers"
+
+	c := NewInitContainerCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestInitContainerCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewInitContainerCollector(nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestInitContainerCollectorCollect(t *testing.T) {
+	sidecarRestartPolicy := corev1.ContainerRestartPolicyAlways
+
+	loopingPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "looping", Namespace: "app"},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "migrate"}, {Name: "wait-for-db"}},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+			InitContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:  "migrate",
+					State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0, Reason: "Completed"}},
+				},
+				{
+					Name:         "wait-for-db",
+					RestartCount: 5,
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+						Reason:  "CrashLoopBackOff",
+						Message: "back-off 2m40s restarting failed container",
+					}},
+					LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 1,
+						Reason:   "Error",
+					}},
+				},
+			},
+		},
+	}
+	healthyPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "healthy", Namespace: "app"},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "init"}, {Name: "proxy", RestartPolicy: &sidecarRestartPolicy}},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			InitContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:  "init",
+					State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0, Reason: "Completed"}},
+				},
+				{
+					Name:  "proxy",
+					State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+				},
+			},
+		},
+	}
+
+	clientset := fake.NewSimpleClientset(loopingPod, healthyPod)
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+	}
+
+	c := NewInitContainerCollector(clientset, runtimeInfo)
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	testDataValue(t, c.GetData()["initcontainers"], func(raw string) {
+		var result []InitContainerPodInfo
+		if err := json.Unmarshal([]byte(raw), &result); err != nil {
+			t.Fatalf("unmarshal GetData(): %v", err)
+		}
+
+		if len(result) != 1 || result[0].Name != "looping" {
+			t.Fatalf("expected only the looping pod, found %s", raw)
+		}
+
+		initContainers := result[0].InitContainers
+		if len(initContainers) != 1 {
+			t.Fatalf("expected 1 init container, found %d", len(initContainers))
+		}
+
+		info := initContainers[0]
+		if info.Name != "wait-for-db" || info.State != "Waiting" || info.Reason != "CrashLoopBackOff" {
+			t.Errorf("unexpected init container state: %+v", info)
+		}
+		if info.RestartCount != 5 || info.LastTerminationReason != "Error" || info.LastExitCode != 1 {
+			t.Errorf("unexpected init container restart details: %+v", info)
+		}
+		// The fake clientset returns fixed log content.
+		if info.Logs != "fake logs" {
+			t.Errorf("unexpected logs: %s", info.Logs)
+		}
+	})
+}
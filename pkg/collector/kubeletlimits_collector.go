@@ -0,0 +1,186 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+// Minimum values below which a kubelet resource limit is considered low enough to cause problems
+// on a busy node. The keys are the limit names as they appear in /proc/<pid>/limits.
+var kubeletLimitThresholds = map[string]int64{
+	"Max open files": 65536,
+	"Max processes":  32768,
+}
+
+// The equivalent thresholds for the kubelet systemd unit properties.
+var kubeletUnitPropertyThresholds = map[string]int64{
+	"LimitNOFILE": 65536,
+	"LimitNPROC":  32768,
+	"TasksMax":    32768,
+}
+
+// Matches lines such as "Max open files            1048576              1048576              files"
+var procLimitsLineRegex = regexp.MustCompile(`^(.+?)\s{2,}(\S+)\s+(\S+)\s*(\S*)\s*$`)
+
+type KubeletProcessLimit struct {
+	Name      string `json:"name"`
+	SoftLimit string `json:"softLimit"`
+	HardLimit string `json:"hardLimit"`
+	Units     string `json:"units"`
+	Low       bool   `json:"low"`
+}
+
+type KubeletUnitProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Low   bool   `json:"low"`
+}
+
+type KubeletLimits struct {
+	PID            string                `json:"pid"`
+	ProcessLimits  []KubeletProcessLimit `json:"processLimits"`
+	UnitProperties []KubeletUnitProperty `json:"unitProperties"`
+	Warnings       []string              `json:"warnings"`
+}
+
+// KubeletLimitsCollector defines a Kubelet Limits Collector struct
+type KubeletLimitsCollector struct {
+	data         map[string]string
+	osIdentifier utils.OSIdentifier
+	filePaths    *utils.KnownFilePaths
+	fileSystem   interfaces.FileSystemAccessor
+	runCommand   utils.HostCommandRunner
+}
+
+// NewKubeletLimitsCollector is a constructor
+func NewKubeletLimitsCollector(osIdentifier utils.OSIdentifier, filePaths *utils.KnownFilePaths, fileSystem interfaces.FileSystemAccessor, runCommand utils.HostCommandRunner) *KubeletLimitsCollector {
+	return &KubeletLimitsCollector{
+		data:         make(map[string]string),
+		osIdentifier: osIdentifier,
+		filePaths:    filePaths,
+		fileSystem:   fileSystem,
+		runCommand:   runCommand,
+	}
+}
+
+func (collector *KubeletLimitsCollector) GetName() string {
+	return "kubeletlimits"
+}
+
+func (collector *KubeletLimitsCollector) CheckSupported() error {
+	// This reads the kubelet systemd unit and process information, which only exist on Linux.
+	if collector.osIdentifier != utils.Linux {
+		return fmt.Errorf("unsupported OS: %s", collector.osIdentifier)
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *KubeletLimitsCollector) Collect() error {
+	output, err := collector.runCommand("systemctl", "show", "kubelet", "-p", "MainPID", "-p", "LimitNOFILE", "-p", "LimitNPROC", "-p", "TasksMax")
+	if err != nil {
+		return fmt.Errorf("error reading kubelet unit properties: %w", err)
+	}
+
+	limits := KubeletLimits{
+		ProcessLimits:  []KubeletProcessLimit{},
+		UnitProperties: []KubeletUnitProperty{},
+		Warnings:       []string{},
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		name, value, found := strings.Cut(strings.TrimSpace(line), "=")
+		if !found {
+			continue
+		}
+
+		if name == "MainPID" {
+			limits.PID = value
+			continue
+		}
+
+		property := KubeletUnitProperty{Name: name, Value: value}
+		if threshold, ok := kubeletUnitPropertyThresholds[name]; ok && isBelowThreshold(value, threshold) {
+			property.Low = true
+			limits.Warnings = append(limits.Warnings, fmt.Sprintf("kubelet unit %s=%s is below the recommended minimum of %d", name, value, threshold))
+		}
+		limits.UnitProperties = append(limits.UnitProperties, property)
+	}
+
+	// The Periscope pod shares the host PID namespace, so the kubelet process is visible in /proc.
+	if limits.PID == "" || limits.PID == "0" {
+		limits.Warnings = append(limits.Warnings, "kubelet process not running")
+	} else {
+		limitsPath := path.Join(collector.filePaths.Proc, limits.PID, "limits")
+		content, err := utils.GetContent(func() (io.ReadCloser, error) { return collector.fileSystem.GetFileReader(limitsPath) })
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", limitsPath, err)
+		}
+
+		for _, processLimit := range parseProcLimits(content) {
+			if threshold, ok := kubeletLimitThresholds[processLimit.Name]; ok && isBelowThreshold(processLimit.SoftLimit, threshold) {
+				processLimit.Low = true
+				limits.Warnings = append(limits.Warnings, fmt.Sprintf("kubelet '%s' soft limit %s is below the recommended minimum of %d", processLimit.Name, processLimit.SoftLimit, threshold))
+			}
+			limits.ProcessLimits = append(limits.ProcessLimits, processLimit)
+		}
+	}
+
+	data, err := json.Marshal(limits)
+	if err != nil {
+		return fmt.Errorf("marshall kubelet limits to json: %w", err)
+	}
+
+	collector.data["kubeletlimits"] = string(data)
+
+	return nil
+}
+
+func parseProcLimits(content string) []KubeletProcessLimit {
+	result := []KubeletProcessLimit{}
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		// Skip the header line
+		if i == 0 {
+			continue
+		}
+
+		matches := procLimitsLineRegex.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+
+		result = append(result, KubeletProcessLimit{
+			Name:      strings.TrimSpace(matches[1]),
+			SoftLimit: matches[2],
+			HardLimit: matches[3],
+			Units:     matches[4],
+		})
+	}
+
+	return result
+}
+
+// isBelowThreshold returns true if the value is numeric and less than the threshold.
+// Non-numeric values such as 'unlimited' or 'infinity' are never below the threshold.
+func isBelowThreshold(value string, threshold int64) bool {
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	return number < threshold
+}
+
+func (collector *KubeletLimitsCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
@@ -0,0 +1,146 @@
+package collector
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+func TestKubeletLimitsCollectorGetName(t *testing.T) {
+	const expectedName = "kubeletlimits"
+
+	c := NewKubeletLimitsCollector("", nil, nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestKubeletLimitsCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		osIdentifier utils.OSIdentifier
+		wantErr      bool
+	}{
+		{
+			osIdentifier: utils.Windows,
+			wantErr:      true,
+		},
+		{
+			osIdentifier: utils.Linux,
+			wantErr:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		c := NewKubeletLimitsCollector(tt.osIdentifier, nil, nil, nil)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("CheckSupported() error = %v, wantErr %v", err, tt.wantErr)
+		}
+	}
+}
+
+func TestKubeletLimitsCollectorCollect(t *testing.T) {
+	const unitProperties = "MainPID=1234\nLimitNOFILE=4096\nLimitNPROC=infinity\nTasksMax=infinity\n"
+
+	const procLimits = `Limit                     Soft Limit           Hard Limit           Units
+Max cpu time              unlimited            unlimited            seconds
+Max open files            4096                 4096                 files
+Max processes             unlimited            unlimited            processes
+`
+
+	tests := []struct {
+		name           string
+		commandOutput  string
+		commandErr     error
+		files          map[string]string
+		wantErr        bool
+		wantLowLimits  []string
+		wantLowUnit    []string
+		wantWarnings   int
+		wantLimitCount int
+	}{
+		{
+			name:          "systemctl failure",
+			commandOutput: "",
+			commandErr:    errors.New("systemctl not found"),
+			files:         map[string]string{},
+			wantErr:       true,
+		},
+		{
+			name:          "missing limits file",
+			commandOutput: unitProperties,
+			files:         map[string]string{},
+			wantErr:       true,
+		},
+		{
+			name:           "low open files limit",
+			commandOutput:  unitProperties,
+			files:          map[string]string{"/proc/1234/limits": procLimits},
+			wantErr:        false,
+			wantLowLimits:  []string{"Max open files"},
+			wantLowUnit:    []string{"LimitNOFILE"},
+			wantWarnings:   2,
+			wantLimitCount: 3,
+		},
+	}
+
+	filePaths := &utils.KnownFilePaths{Proc: "/proc"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runCommand := func(command string, arg ...string) (string, error) {
+				return tt.commandOutput, tt.commandErr
+			}
+
+			c := NewKubeletLimitsCollector(utils.Linux, filePaths, test.NewFakeFileSystem(tt.files), runCommand)
+			err := c.Collect()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Collect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			testDataValue(t, c.GetData()["kubeletlimits"], func(raw string) {
+				var limits KubeletLimits
+				if err := json.Unmarshal([]byte(raw), &limits); err != nil {
+					t.Fatalf("unmarshal GetData(): %v", err)
+				}
+
+				if limits.PID != "1234" {
+					t.Errorf("unexpected PID %s", limits.PID)
+				}
+				if len(limits.ProcessLimits) != tt.wantLimitCount {
+					t.Errorf("expected %d process limits, found %d", tt.wantLimitCount, len(limits.ProcessLimits))
+				}
+				if len(limits.Warnings) != tt.wantWarnings {
+					t.Errorf("expected %d warnings, found %v", tt.wantWarnings, limits.Warnings)
+				}
+
+				lowLimits := []string{}
+				for _, limit := range limits.ProcessLimits {
+					if limit.Low {
+						lowLimits = append(lowLimits, limit.Name)
+					}
+				}
+				if !equalStringSlices(lowLimits, tt.wantLowLimits) {
+					t.Errorf("expected low process limits %v, found %v", tt.wantLowLimits, lowLimits)
+				}
+
+				lowUnit := []string{}
+				for _, property := range limits.UnitProperties {
+					if property.Low {
+						lowUnit = append(lowUnit, property.Name)
+					}
+				}
+				if !equalStringSlices(lowUnit, tt.wantLowUnit) {
+					t.Errorf("expected low unit properties %v, found %v", tt.wantLowUnit, lowUnit)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+type MountFailureCause string
+
+const (
+	MountFailureSubPathNotFound  MountFailureCause = "subPathNotFound"
+	MountFailurePermissionDenied MountFailureCause = "permissionDenied"
+	MountFailureTimeout          MountFailureCause = "timeout"
+	MountFailureOther            MountFailureCause = "other"
+)
+
+// Kubelet includes the volume name in mount failure messages, e.g. `MountVolume.SetUp failed for volume "data" : ...`
+var mountFailureVolumeRegex = regexp.MustCompile(`for volume "([^"]+)"`)
+
+type MountFailure struct {
+	Namespace     string    `json:"namespace"`
+	Pod           string    `json:"pod"`
+	Volume        string    `json:"volume,omitempty"`
+	Reason        string    `json:"reason"`
+	Message       string    `json:"message"`
+	Count         int32     `json:"count"`
+	LastTimestamp time.Time `json:"lastTimestamp"`
+}
+
+// MountFailureCollector defines a Mount Failure Collector struct
+type MountFailureCollector struct {
+	data        map[string]string
+	clientset   kubernetes.Interface
+	runtimeInfo *utils.RuntimeInfo
+}
+
+// NewMountFailureCollector is a constructor
+func NewMountFailureCollector(clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *MountFailureCollector {
+	return &MountFailureCollector{
+		data:        make(map[string]string),
+		clientset:   clientset,
+		runtimeInfo: runtimeInfo,
+	}
+}
+
+func (collector *MountFailureCollector) GetName() string {
+	return "mountfailures"
+}
+
+func (collector *MountFailureCollector) CheckSupported() error {
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *MountFailureCollector) Collect() error {
+	// Events are only retained for a short period (one hour by default), so all of these are recent failures.
+	eventList, err := collector.clientset.CoreV1().Events(metav1.NamespaceAll).List(context.Background(), metav1.ListOptions{
+		FieldSelector: "involvedObject.kind=Pod",
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list pod events: %w", err)
+	}
+
+	result := map[MountFailureCause][]MountFailure{}
+	for _, event := range eventList.Items {
+		if event.InvolvedObject.Kind != "Pod" {
+			continue
+		}
+		if event.Reason != "FailedMount" && !strings.Contains(event.Message, "MountVolume.SetUp failed") {
+			continue
+		}
+
+		failure := MountFailure{
+			Namespace:     event.InvolvedObject.Namespace,
+			Pod:           event.InvolvedObject.Name,
+			Reason:        event.Reason,
+			Message:       event.Message,
+			Count:         event.Count,
+			LastTimestamp: event.LastTimestamp.Time,
+		}
+		if matches := mountFailureVolumeRegex.FindStringSubmatch(event.Message); matches != nil {
+			failure.Volume = matches[1]
+		}
+
+		cause := classifyMountFailure(event.Message)
+		result[cause] = append(result[cause], failure)
+	}
+
+	for _, failures := range result {
+		sort.Slice(failures, func(i, j int) bool {
+			if failures[i].Namespace != failures[j].Namespace {
+				return failures[i].Namespace < failures[j].Namespace
+			}
+			if failures[i].Pod != failures[j].Pod {
+				return failures[i].Pod < failures[j].Pod
+			}
+			return failures[i].Volume < failures[j].Volume
+		})
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshall mount failures to json: %w", err)
+	}
+
+	collector.data["mountfailures"] = string(data)
+
+	return nil
+}
+
+// classifyMountFailure determines the likely cause of a mount failure from the event message.
+func classifyMountFailure(message string) MountFailureCause {
+	lower := strings.ToLower(message)
+	switch {
+	case strings.Contains(lower, "subpath") && (strings.Contains(lower, "no such file or directory") || strings.Contains(lower, "not found") || strings.Contains(lower, "does not exist")):
+		return MountFailureSubPathNotFound
+	case strings.Contains(lower, "permission denied") || strings.Contains(lower, "operation not permitted"):
+		return MountFailurePermissionDenied
+	case strings.Contains(lower, "timed out") || strings.Contains(lower, "timeout") || strings.Contains(lower, "deadline exceeded"):
+		return MountFailureTimeout
+	default:
+		return MountFailureOther
+	}
+}
+
+func (collector *MountFailureCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
@@ -0,0 +1,117 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestMountFailureCollectorGetName(t *testing.T) {
+	const expectedName = "mountfailures"
+
+	c := NewMountFailureCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestMountFailureCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewMountFailureCollector(nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestMountFailureCollectorCollect(t *testing.T) {
+	newEvent := func(pod, reason, message string) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s.%s", pod, reason), Namespace: "app"},
+			InvolvedObject: corev1.ObjectReference{
+				Kind:      "Pod",
+				Namespace: "app",
+				Name:      pod,
+			},
+			Reason:  reason,
+			Message: message,
+			Count:   1,
+		}
+	}
+
+	clientset := fake.NewSimpleClientset(
+		newEvent("containerconfig", "Failed", `Error: failed to prepare subPath for volumeMount "config" of container "web": stat /var/lib/kubelet/pods/1234/volumes/config/app.conf: no such file or directory`),
+		newEvent("permission", "FailedMount", `MountVolume.SetUp failed for volume "data" : mount failed: exit status 32, output: mount: /mnt/data: permission denied`),
+		newEvent("timeout", "FailedMount", `Unable to attach or mount volumes: unmounted volumes=[cache], unattached volumes=[cache]: timed out waiting for the condition`),
+		newEvent("other", "FailedMount", `MountVolume.SetUp failed for volume "secrets" : secret "missing" not found`),
+		newEvent("subpathmount", "FailedMount", `MountVolume.SetUp failed for volume "logs" : subPath "app/logs" does not exist`),
+		newEvent("unrelated", "Pulled", `Successfully pulled image "nginx"`),
+	)
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+	}
+
+	c := NewMountFailureCollector(clientset, runtimeInfo)
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	testDataValue(t, c.GetData()["mountfailures"], func(raw string) {
+		var result map[MountFailureCause][]MountFailure
+		if err := json.Unmarshal([]byte(raw), &result); err != nil {
+			t.Fatalf("unmarshal GetData(): %v", err)
+		}
+
+		expected := map[MountFailureCause][]string{
+			MountFailureSubPathNotFound:  {"subpathmount"},
+			MountFailurePermissionDenied: {"permission"},
+			MountFailureTimeout:          {"timeout"},
+			MountFailureOther:            {"other"},
+		}
+		if len(result) != len(expected) {
+			t.Errorf("expected %d causes, found %d: %s", len(expected), len(result), raw)
+		}
+
+		for cause, expectedPods := range expected {
+			pods := []string{}
+			for _, failure := range result[cause] {
+				pods = append(pods, failure.Pod)
+			}
+			if !equalStringSlices(pods, expectedPods) {
+				t.Errorf("expected pods %v for cause %s, found %v", expectedPods, cause, pods)
+			}
+		}
+
+		if volume := result[MountFailurePermissionDenied][0].Volume; volume != "data" {
+			t.Errorf("expected volume data, found %s", volume)
+		}
+	})
+}
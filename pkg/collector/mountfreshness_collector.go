@@ -0,0 +1,461 @@
+package collector
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// The kubelet refreshes mounted Secrets and ConfigMaps on its periodic sync of each pod, from a cache with its own
+// TTL, so the mounted copies of objects updated more recently than this may not be stale yet.
+const mountFreshnessGracePeriod = 2 * time.Minute
+
+// The kubelet writes each version of a mounted volume to a new directory, named for the time it was written, and
+// switches the ..data link to it. See: https://pkg.go.dev/k8s.io/kubernetes/pkg/volume/util#AtomicWriter
+const (
+	mountDataLink             = "..data"
+	mountTimestampDirLayout   = "..2006_01_02_15_04_05"
+	mountFreshnessSecretKind  = "Secret"
+	mountFreshnessConfigKind  = "ConfigMap"
+	kubeletSecretVolumeDir    = "kubernetes.io~secret"
+	kubeletConfigMapVolumeDir = "kubernetes.io~configmap"
+	kubeletProjectedVolumeDir = "kubernetes.io~projected"
+)
+
+type MountFreshnessReport struct {
+	CheckedMounts int              `json:"checkedMounts"`
+	StaleMounts   []MountFreshness `json:"staleMounts"`
+}
+
+// MountFreshness describes the mounted copy of a Secret or ConfigMap in a pod's volume. Only content hashes are
+// compared, so the content itself is never reported.
+type MountFreshness struct {
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	Volume    string `json:"volume"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	// ResourceVersion and UpdatedAt are those of the live object, and MountedAt is when the kubelet last wrote the
+	// volume. The kubelet doesn't record the resourceVersion it mounted, so the content is compared instead.
+	ResourceVersion string     `json:"resourceVersion,omitempty"`
+	UpdatedAt       *time.Time `json:"updatedAt,omitempty"`
+	MountedAt       *time.Time `json:"mountedAt,omitempty"`
+	ChangedKeys     []string   `json:"changedKeys"`
+	MissingKeys     []string   `json:"missingKeys"`
+	RemovedKeys     []string   `json:"removedKeys"`
+	// SubPathContainers mount the volume with a subPath, which the kubelet never updates, and started before the
+	// object was last updated.
+	SubPathContainers []string `json:"subPathContainers"`
+	Error             string   `json:"error,omitempty"`
+}
+
+// mountSource is a Secret or ConfigMap mounted in a volume, either on its own or as one of the sources of a projected
+// volume.
+type mountSource struct {
+	kind     string
+	name     string
+	items    []corev1.KeyToPath
+	optional bool
+}
+
+// mountedObject is the live content of a Secret or ConfigMap.
+type mountedObject struct {
+	data            map[string][]byte
+	resourceVersion string
+	updatedAt       *time.Time
+}
+
+// MountFreshnessCollector defines a Mount Freshness Collector struct
+type MountFreshnessCollector struct {
+	data         map[string]string
+	osIdentifier utils.OSIdentifier
+	filePaths    *utils.KnownFilePaths
+	fileSystem   interfaces.FileSystemAccessor
+	clientset    kubernetes.Interface
+	runtimeInfo  *utils.RuntimeInfo
+	now          func() time.Time
+}
+
+// NewMountFreshnessCollector is a constructor
+func NewMountFreshnessCollector(osIdentifier utils.OSIdentifier, filePaths *utils.KnownFilePaths, fileSystem interfaces.FileSystemAccessor, clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *MountFreshnessCollector {
+	return &MountFreshnessCollector{
+		data:         make(map[string]string),
+		osIdentifier: osIdentifier,
+		filePaths:    filePaths,
+		fileSystem:   fileSystem,
+		clientset:    clientset,
+		runtimeInfo:  runtimeInfo,
+		now:          time.Now,
+	}
+}
+
+func (collector *MountFreshnessCollector) GetName() string {
+	return "mountfreshness"
+}
+
+func (collector *MountFreshnessCollector) CheckSupported() error {
+	// This reads the kubelet's pod volumes on the host, which is only supported on Linux.
+	if collector.osIdentifier != utils.Linux {
+		return fmt.Errorf("unsupported OS: %s", collector.osIdentifier)
+	}
+
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *MountFreshnessCollector) Collect() error {
+	ctx := context.Background()
+
+	var pods *corev1.PodList
+	err := utils.RetryAPICall(func() (err error) {
+		pods, err = collector.clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+			FieldSelector: "spec.nodeName=" + collector.runtimeInfo.HostNodeName,
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list pods on node %s: %w", collector.runtimeInfo.HostNodeName, err)
+	}
+
+	report := MountFreshnessReport{StaleMounts: []MountFreshness{}}
+
+	// Objects are often mounted by many pods, so each is only fetched once.
+	objects := map[string]*mountedObject{}
+	objectErrors := map[string]error{}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName != collector.runtimeInfo.HostNodeName || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+
+		for _, volume := range pod.Spec.Volumes {
+			volumeDir, sources := getMountSources(&volume)
+			if len(sources) == 0 {
+				continue
+			}
+			volumePath := path.Join(collector.filePaths.KubeletPods, string(pod.UID), "volumes", volumeDir, volume.Name)
+
+			for _, source := range sources {
+				objectKey := fmt.Sprintf("%s/%s/%s", source.kind, pod.Namespace, source.name)
+				if _, ok := objects[objectKey]; !ok {
+					objects[objectKey], objectErrors[objectKey] = collector.getMountedObject(ctx, source.kind, pod.Namespace, source.name)
+				}
+
+				object, err := objects[objectKey], objectErrors[objectKey]
+				if object == nil && err == nil && source.optional {
+					continue
+				}
+
+				report.CheckedMounts++
+				freshness := MountFreshness{
+					Namespace:         pod.Namespace,
+					Pod:               pod.Name,
+					Volume:            volume.Name,
+					Kind:              source.kind,
+					Name:              source.name,
+					ChangedKeys:       []string{},
+					MissingKeys:       []string{},
+					RemovedKeys:       []string{},
+					SubPathContainers: []string{},
+				}
+
+				switch {
+				case err != nil:
+					freshness.Error = err.Error()
+				case object == nil:
+					freshness.Error = fmt.Sprintf("%s %s/%s not found", source.kind, pod.Namespace, source.name)
+				default:
+					// Only a Secret or ConfigMap mounted on its own has a volume with no other files.
+					exclusive := volumeDir != kubeletProjectedVolumeDir
+					if !collector.compareMountedFiles(&freshness, volumePath, source, object, exclusive) {
+						continue
+					}
+				}
+
+				report.StaleMounts = append(report.StaleMounts, freshness)
+			}
+		}
+	}
+
+	// Files mounted with a subPath are never updated, so they are stale even when the volume itself is up to date.
+	for i := range pods.Items {
+		collector.addSubPathContainers(&report, &pods.Items[i], objects)
+	}
+
+	sort.Slice(report.StaleMounts, func(i, j int) bool {
+		a, b := report.StaleMounts[i], report.StaleMounts[j]
+		return strings.Join([]string{a.Namespace, a.Pod, a.Volume, a.Kind, a.Name}, "/") < strings.Join([]string{b.Namespace, b.Pod, b.Volume, b.Kind, b.Name}, "/")
+	})
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("marshall mount freshness to json: %w", err)
+	}
+
+	collector.data["mount-freshness"] = string(data)
+
+	return nil
+}
+
+// getMountSources returns the kubelet's directory for the type of a volume, and the Secrets and ConfigMaps mounted in
+// it. Projected volumes which include service account tokens, which are rotated by design, or other kinds of
+// source are left out.
+func getMountSources(volume *corev1.Volume) (string, []mountSource) {
+	switch {
+	case volume.Secret != nil:
+		return kubeletSecretVolumeDir, []mountSource{{
+			kind:     mountFreshnessSecretKind,
+			name:     volume.Secret.SecretName,
+			items:    volume.Secret.Items,
+			optional: volume.Secret.Optional != nil && *volume.Secret.Optional,
+		}}
+	case volume.ConfigMap != nil:
+		return kubeletConfigMapVolumeDir, []mountSource{{
+			kind:     mountFreshnessConfigKind,
+			name:     volume.ConfigMap.Name,
+			items:    volume.ConfigMap.Items,
+			optional: volume.ConfigMap.Optional != nil && *volume.ConfigMap.Optional,
+		}}
+	case volume.Projected != nil:
+		sources := []mountSource{}
+		for _, projection := range volume.Projected.Sources {
+			switch {
+			case projection.Secret != nil:
+				sources = append(sources, mountSource{
+					kind:     mountFreshnessSecretKind,
+					name:     projection.Secret.Name,
+					items:    projection.Secret.Items,
+					optional: projection.Secret.Optional != nil && *projection.Secret.Optional,
+				})
+			case projection.ConfigMap != nil:
+				sources = append(sources, mountSource{
+					kind:     mountFreshnessConfigKind,
+					name:     projection.ConfigMap.Name,
+					items:    projection.ConfigMap.Items,
+					optional: projection.ConfigMap.Optional != nil && *projection.ConfigMap.Optional,
+				})
+			default:
+				return kubeletProjectedVolumeDir, nil
+			}
+		}
+		return kubeletProjectedVolumeDir, sources
+	default:
+		return "", nil
+	}
+}
+
+// getMountedObject returns the live content of a Secret or ConfigMap, or nil if it doesn't exist.
+func (collector *MountFreshnessCollector) getMountedObject(ctx context.Context, kind, namespace, name string) (*mountedObject, error) {
+	var object *mountedObject
+	err := utils.RetryAPICall(func() error {
+		var objectMeta metav1.ObjectMeta
+		data := map[string][]byte{}
+
+		if kind == mountFreshnessSecretKind {
+			secret, err := collector.clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			objectMeta = secret.ObjectMeta
+			for key, value := range secret.Data {
+				data[key] = value
+			}
+		} else {
+			configMap, err := collector.clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			objectMeta = configMap.ObjectMeta
+			for key, value := range configMap.Data {
+				data[key] = []byte(value)
+			}
+			for key, value := range configMap.BinaryData {
+				data[key] = value
+			}
+		}
+
+		object = &mountedObject{data: data, resourceVersion: objectMeta.ResourceVersion, updatedAt: getLastUpdateTime(&objectMeta)}
+		return nil
+	})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to get %s %s/%s: %w", kind, namespace, name, err)
+	}
+
+	return object, nil
+}
+
+// getLastUpdateTime returns the latest time of the managed fields of an object, which is when it was last written,
+// or its creation time if it has none.
+func getLastUpdateTime(objectMeta *metav1.ObjectMeta) *time.Time {
+	var latest *time.Time
+	for _, entry := range objectMeta.ManagedFields {
+		if entry.Time != nil && (latest == nil || entry.Time.Time.After(*latest)) {
+			updated := entry.Time.Time
+			latest = &updated
+		}
+	}
+
+	if latest == nil && !objectMeta.CreationTimestamp.IsZero() {
+		created := objectMeta.CreationTimestamp.Time
+		latest = &created
+	}
+
+	return latest
+}
+
+// compareMountedFiles compares the files of a mounted source with the live object, returning whether they differ
+// enough to report: the object was last updated longer ago than the kubelet takes to refresh the volume.
+func (collector *MountFreshnessCollector) compareMountedFiles(freshness *MountFreshness, volumePath string, source mountSource, object *mountedObject, exclusive bool) bool {
+	freshness.ResourceVersion = object.resourceVersion
+	freshness.UpdatedAt = object.updatedAt
+
+	if target, err := collector.fileSystem.ReadLink(path.Join(volumePath, mountDataLink)); err == nil {
+		timestamp := path.Base(target)
+		if index := strings.LastIndex(timestamp, "."); index > 0 {
+			if mountedAt, err := time.Parse(mountTimestampDirLayout, timestamp[:index]); err == nil {
+				freshness.MountedAt = &mountedAt
+			}
+		}
+	}
+
+	// Without items, every key is mounted as a file of the same name.
+	files := map[string]string{}
+	if len(source.items) == 0 {
+		for key := range object.data {
+			files[key] = key
+		}
+	} else {
+		for _, item := range source.items {
+			if _, ok := object.data[item.Key]; ok {
+				files[item.Key] = item.Path
+			}
+		}
+	}
+
+	for key, filePath := range files {
+		content, err := utils.GetContent(func() (io.ReadCloser, error) {
+			return collector.fileSystem.GetFileReader(path.Join(volumePath, filePath))
+		})
+		if err != nil {
+			freshness.MissingKeys = append(freshness.MissingKeys, key)
+			continue
+		}
+
+		if sha256.Sum256([]byte(content)) != sha256.Sum256(object.data[key]) {
+			freshness.ChangedKeys = append(freshness.ChangedKeys, key)
+		}
+	}
+
+	// Files are only left behind for removed keys if the volume holds every key of a single object.
+	if exclusive && len(source.items) == 0 {
+		if entries, err := collector.fileSystem.ListDirectory(volumePath); err == nil {
+			for _, entry := range entries {
+				if _, ok := object.data[entry]; !ok && !strings.HasPrefix(entry, "..") {
+					freshness.RemovedKeys = append(freshness.RemovedKeys, entry)
+				}
+			}
+		} else {
+			// The kubelet failed to set up the volume, or it has been torn down.
+			freshness.Error = fmt.Sprintf("volume not mounted: %v", err)
+		}
+	}
+
+	sort.Strings(freshness.ChangedKeys)
+	sort.Strings(freshness.MissingKeys)
+	sort.Strings(freshness.RemovedKeys)
+
+	stale := len(freshness.ChangedKeys) > 0 || len(freshness.MissingKeys) > 0 || len(freshness.RemovedKeys) > 0
+	if !stale {
+		return len(freshness.Error) > 0
+	}
+
+	recentlyUpdated := object.updatedAt != nil && collector.now().Sub(*object.updatedAt) < mountFreshnessGracePeriod
+	return !recentlyUpdated || len(freshness.Error) > 0
+}
+
+// addSubPathContainers adds the containers which mount a Secret or ConfigMap volume with a subPath, and started before
+// the object was last updated, so they still see an earlier version.
+func (collector *MountFreshnessCollector) addSubPathContainers(report *MountFreshnessReport, pod *corev1.Pod, objects map[string]*mountedObject) {
+	if pod.Spec.NodeName != collector.runtimeInfo.HostNodeName || pod.Status.Phase != corev1.PodRunning {
+		return
+	}
+
+	started := map[string]time.Time{}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Running != nil {
+			started[status.Name] = status.State.Running.StartedAt.Time
+		}
+	}
+
+	for _, volume := range pod.Spec.Volumes {
+		_, sources := getMountSources(&volume)
+		for _, source := range sources {
+			object := objects[fmt.Sprintf("%s/%s/%s", source.kind, pod.Namespace, source.name)]
+			if object == nil || object.updatedAt == nil {
+				continue
+			}
+
+			containers := []string{}
+			for _, container := range pod.Spec.Containers {
+				for _, mount := range container.VolumeMounts {
+					startedAt, ok := started[container.Name]
+					if mount.Name == volume.Name && len(mount.SubPath) > 0 && ok && startedAt.Before(*object.updatedAt) {
+						containers = append(containers, container.Name)
+						break
+					}
+				}
+			}
+			if len(containers) == 0 {
+				continue
+			}
+
+			index := -1
+			for i, freshness := range report.StaleMounts {
+				if freshness.Namespace == pod.Namespace && freshness.Pod == pod.Name && freshness.Volume == volume.Name && freshness.Kind == source.kind && freshness.Name == source.name {
+					index = i
+				}
+			}
+			if index < 0 {
+				index = len(report.StaleMounts)
+				report.StaleMounts = append(report.StaleMounts, MountFreshness{
+					Namespace:       pod.Namespace,
+					Pod:             pod.Name,
+					Volume:          volume.Name,
+					Kind:            source.kind,
+					Name:            source.name,
+					ResourceVersion: object.resourceVersion,
+					UpdatedAt:       object.updatedAt,
+					ChangedKeys:     []string{},
+					MissingKeys:     []string{},
+					RemovedKeys:     []string{},
+				})
+			}
+			report.StaleMounts[index].SubPathContainers = containers
+		}
+	}
+}
+
+func (collector *MountFreshnessCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
@@ -0,0 +1,185 @@
+package collector
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestMountFreshnessCollectorGetName(t *testing.T) {
+	const expectedName = "mountfreshness"
+
+	c := NewMountFreshnessCollector(utils.Linux, nil, nil, nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestMountFreshnessCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		osIdentifier  utils.OSIdentifier
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "windows",
+			osIdentifier:  utils.Windows,
+			collectorList: []string{},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "linux",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewMountFreshnessCollector(tt.osIdentifier, nil, nil, nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestMountFreshnessCollectorCollect(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	updatedAt := func(age time.Duration) metav1.ObjectMeta {
+		updated := metav1.NewTime(now.Add(-age))
+		return metav1.ObjectMeta{
+			Namespace:       "app",
+			ResourceVersion: "42",
+			ManagedFields:   []metav1.ManagedFieldsEntry{{Manager: "kubectl", Time: &updated}},
+		}
+	}
+
+	appConfig := &corev1.ConfigMap{ObjectMeta: updatedAt(time.Hour), Data: map[string]string{"a": "new", "b": "same"}}
+	appConfig.Name = "app-config"
+	flags := &corev1.ConfigMap{ObjectMeta: updatedAt(30 * time.Second), Data: map[string]string{"enabled": "true"}}
+	flags.Name = "feature-flags"
+	rootCA := &corev1.ConfigMap{ObjectMeta: updatedAt(time.Hour), Data: map[string]string{"ca.crt": "ca"}}
+	rootCA.Name = "kube-root-ca.crt"
+	creds := &corev1.Secret{ObjectMeta: updatedAt(time.Hour), Data: map[string][]byte{"token": []byte("secret")}}
+	creds.Name = "app-creds"
+
+	optional := true
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app", UID: "uid-web"},
+		Spec: corev1.PodSpec{
+			NodeName: "node1",
+			Containers: []corev1.Container{
+				{Name: "main", VolumeMounts: []corev1.VolumeMount{{Name: "config", MountPath: "/config"}, {Name: "creds", MountPath: "/creds"}}},
+				{Name: "sidecar", VolumeMounts: []corev1.VolumeMount{{Name: "creds", MountPath: "/token", SubPath: "token"}}},
+			},
+			Volumes: []corev1.Volume{
+				{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "app-config"}}}},
+				{Name: "creds", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "app-creds"}}},
+				{Name: "flags", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "feature-flags"}}}},
+				{Name: "extra", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "not-created", Optional: &optional}}},
+				{Name: "kube-api-access", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{
+					{ServiceAccountToken: &corev1.ServiceAccountTokenProjection{Path: "token"}},
+					{ConfigMap: &corev1.ConfigMapProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "kube-root-ca.crt"}}},
+				}}}},
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "main", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(now.Add(-2 * time.Hour))}}},
+				{Name: "sidecar", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(now.Add(-2 * time.Hour))}}},
+			},
+		},
+	}
+	otherNodePod := pod.DeepCopy()
+	otherNodePod.Name = "other"
+	otherNodePod.UID = "uid-other"
+	otherNodePod.Spec.NodeName = "node2"
+
+	const volumes = "/var/lib/kubelet/pods/uid-web/volumes"
+	fileSystem := test.NewFakeFileSystem(map[string]string{
+		volumes + "/kubernetes.io~configmap/config/a":       "old",
+		volumes + "/kubernetes.io~configmap/config/b":       "same",
+		volumes + "/kubernetes.io~configmap/config/removed": "gone",
+		volumes + "/kubernetes.io~secret/creds/token":       "secret",
+		volumes + "/kubernetes.io~configmap/flags/enabled":  "false",
+	})
+	fileSystem.AddOrUpdateLink(volumes+"/kubernetes.io~configmap/config/..data", "..2023_12_31_10_00_00.1234567")
+	fileSystem.AddOrUpdateLink(volumes+"/kubernetes.io~secret/creds/..data", "..2024_01_01_11_05_00.7654321")
+
+	clientset := fake.NewSimpleClientset(pod, otherNodePod, appConfig, flags, rootCA, creds)
+	filePaths := &utils.KnownFilePaths{KubeletPods: "/var/lib/kubelet/pods"}
+	runtimeInfo := &utils.RuntimeInfo{HostNodeName: "node1"}
+
+	c := NewMountFreshnessCollector(utils.Linux, filePaths, fileSystem, clientset, runtimeInfo)
+	c.now = func() time.Time { return now }
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	testDataValue(t, c.GetData()["mount-freshness"], func(raw string) {
+		var report MountFreshnessReport
+		if err := json.Unmarshal([]byte(raw), &report); err != nil {
+			t.Fatalf("unmarshal GetData(): %v", err)
+		}
+
+		// The optional Secret doesn't exist and the projected volume includes a token, so neither is checked.
+		if report.CheckedMounts != 3 {
+			t.Errorf("expected 3 checked mounts, found %d", report.CheckedMounts)
+		}
+
+		configUpdatedAt := now.Add(-time.Hour)
+		configMountedAt := time.Date(2023, 12, 31, 10, 0, 0, 0, time.UTC)
+		expected := []MountFreshness{
+			{
+				Namespace:         "app",
+				Pod:               "web",
+				Volume:            "config",
+				Kind:              "ConfigMap",
+				Name:              "app-config",
+				ResourceVersion:   "42",
+				UpdatedAt:         &configUpdatedAt,
+				MountedAt:         &configMountedAt,
+				ChangedKeys:       []string{"a"},
+				MissingKeys:       []string{},
+				RemovedKeys:       []string{"removed"},
+				SubPathContainers: []string{},
+			},
+			{
+				Namespace:         "app",
+				Pod:               "web",
+				Volume:            "creds",
+				Kind:              "Secret",
+				Name:              "app-creds",
+				ResourceVersion:   "42",
+				UpdatedAt:         &configUpdatedAt,
+				ChangedKeys:       []string{},
+				MissingKeys:       []string{},
+				RemovedKeys:       []string{},
+				SubPathContainers: []string{"sidecar"},
+			},
+		}
+		if !reflect.DeepEqual(report.StaleMounts, expected) {
+			t.Errorf("unexpected stale mounts:\nexpected %+v\nfound    %+v", expected, report.StaleMounts)
+		}
+	})
+}
@@ -0,0 +1,207 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+// The IPv4 and ICMP headers added to a ping payload.
+const icmpHeaderBytes = 28
+
+// The smallest MTU probed: the payload of a default ping, which is used to check the target is reachable at all.
+const minProbedMTU = 56 + icmpHeaderBytes
+
+// The path MTU is assumed not to exceed this if the primary interface MTU can't be determined.
+const defaultProbedMTU = 1500
+
+// Name prefixes of the interfaces created by CNI plugins for pod networking.
+var podNetworkInterfacePrefixes = []string{"azure", "azv", "veth", "cni", "cbr", "cilium", "lxc", "flannel", "vxlan", "tunl", "cali"}
+
+// Matches lines of `ip -o link show` such as "2: eth0: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1500 qdisc mq state UP ..."
+// and "5: azv1234@if4: <...> mtu 1500 ...", capturing the name without the peer suffix.
+var ipLinkLineRegex = regexp.MustCompile(`^\d+:\s+([^:@\s]+)(?:@\S+)?:\s+<[^>]*>.*\smtu\s+(\d+)`)
+var ipLinkStateRegex = regexp.MustCompile(`\sstate\s+(\S+)`)
+var ipRouteDeviceRegex = regexp.MustCompile(`\sdev\s+(\S+)`)
+
+type MTUReport struct {
+	PrimaryInterface string         `json:"primaryInterface,omitempty"`
+	Interfaces       []MTUInterface `json:"interfaces"`
+	PathProbe        *MTUPathProbe  `json:"pathProbe,omitempty"`
+	Warnings         []string       `json:"warnings"`
+}
+
+type MTUInterface struct {
+	Name       string `json:"name"`
+	MTU        int    `json:"mtu"`
+	State      string `json:"state,omitempty"`
+	Primary    bool   `json:"primary"`
+	PodNetwork bool   `json:"podNetwork"`
+}
+
+type MTUPathProbe struct {
+	Target  string `json:"target"`
+	PathMTU int    `json:"pathMtu,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// MTUCollector defines a MTU Collector struct
+type MTUCollector struct {
+	data         map[string]string
+	osIdentifier utils.OSIdentifier
+	runCommand   utils.HostCommandRunner
+	runtimeInfo  *utils.RuntimeInfo
+}
+
+// NewMTUCollector is a constructor
+func NewMTUCollector(osIdentifier utils.OSIdentifier, runCommand utils.HostCommandRunner, runtimeInfo *utils.RuntimeInfo) *MTUCollector {
+	return &MTUCollector{
+		data:         make(map[string]string),
+		osIdentifier: osIdentifier,
+		runCommand:   runCommand,
+		runtimeInfo:  runtimeInfo,
+	}
+}
+
+func (collector *MTUCollector) GetName() string {
+	return "mtu"
+}
+
+func (collector *MTUCollector) CheckSupported() error {
+	// This uses `ip` and `ping` in the host's network namespace, which are only available on Linux.
+	if collector.osIdentifier != utils.Linux {
+		return fmt.Errorf("unsupported OS: %s", collector.osIdentifier)
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *MTUCollector) Collect() error {
+	linkOutput, err := collector.runCommand("ip", "-o", "link", "show")
+	if err != nil {
+		return fmt.Errorf("error listing network interfaces: %w", err)
+	}
+
+	report := MTUReport{
+		Interfaces: []MTUInterface{},
+		Warnings:   []string{},
+	}
+
+	// The primary interface is the one with the default route. Without one, interfaces are still reported.
+	routeOutput, err := collector.runCommand("ip", "-o", "route", "show", "default")
+	if err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("unable to determine the primary interface: %v", err))
+	} else if match := ipRouteDeviceRegex.FindStringSubmatch(routeOutput); match != nil {
+		report.PrimaryInterface = match[1]
+	}
+
+	primaryMTU := 0
+	for _, line := range strings.Split(linkOutput, "\n") {
+		match := ipLinkLineRegex.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		mtu, _ := strconv.Atoi(match[2])
+		info := MTUInterface{
+			Name:       match[1],
+			MTU:        mtu,
+			Primary:    match[1] == report.PrimaryInterface,
+			PodNetwork: isPodNetworkInterface(match[1]),
+		}
+		if stateMatch := ipLinkStateRegex.FindStringSubmatch(line); stateMatch != nil {
+			info.State = stateMatch[1]
+		}
+		if info.Primary {
+			primaryMTU = mtu
+		}
+
+		report.Interfaces = append(report.Interfaces, info)
+	}
+
+	// Pod traffic leaving the node goes through the primary interface, so larger pod MTUs cause fragmentation or drops.
+	if primaryMTU > 0 {
+		for _, info := range report.Interfaces {
+			if info.PodNetwork && info.MTU > primaryMTU {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("pod network interface %s MTU %d exceeds primary interface %s MTU %d", info.Name, info.MTU, report.PrimaryInterface, primaryMTU))
+			}
+		}
+	}
+
+	if target := collector.runtimeInfo.MTUProbeTarget; len(target) > 0 {
+		maxMTU := primaryMTU
+		if maxMTU == 0 {
+			maxMTU = defaultProbedMTU
+		}
+
+		report.PathProbe = collector.probePathMTU(target, maxMTU)
+		if report.PathProbe.PathMTU > 0 && primaryMTU > 0 && report.PathProbe.PathMTU < primaryMTU {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("path MTU %d to %s is less than primary interface %s MTU %d", report.PathProbe.PathMTU, target, report.PrimaryInterface, primaryMTU))
+		}
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("marshall MTU report to json: %w", err)
+	}
+
+	collector.data["mtu"] = string(data)
+
+	return nil
+}
+
+// probePathMTU finds the largest packet that reaches the target without fragmentation, by a binary search of pings
+// with the don't-fragment bit set. If even a small ping fails, because the target is unreachable or `ping` is not
+// available or lacks the capability to open raw sockets, the error is reported rather than a path MTU.
+func (collector *MTUCollector) probePathMTU(target string, maxMTU int) *MTUPathProbe {
+	probe := &MTUPathProbe{Target: target}
+
+	if err := collector.ping(target, minProbedMTU); err != nil {
+		probe.Error = fmt.Sprintf("unable to ping target: %v", err)
+		return probe
+	}
+
+	if collector.ping(target, maxMTU) == nil {
+		probe.PathMTU = maxMTU
+		return probe
+	}
+
+	// The lower bound is always known to succeed, and the upper bound to fail.
+	low, high := minProbedMTU, maxMTU
+	for high-low > 1 {
+		mid := (low + high) / 2
+		if collector.ping(target, mid) == nil {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+
+	probe.PathMTU = low
+	return probe
+}
+
+func (collector *MTUCollector) ping(target string, mtu int) error {
+	_, err := collector.runCommand("ping", "-c", "1", "-W", "1", "-M", "do", "-s", strconv.Itoa(mtu-icmpHeaderBytes), target)
+	return err
+}
+
+func isPodNetworkInterface(name string) bool {
+	for _, prefix := range podNetworkInterfacePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (collector *MTUCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
@@ -0,0 +1,158 @@
+package collector
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+func TestMTUCollectorGetName(t *testing.T) {
+	const expectedName = "mtu"
+
+	c := NewMTUCollector("", nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestMTUCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		osIdentifier utils.OSIdentifier
+		wantErr      bool
+	}{
+		{
+			osIdentifier: utils.Windows,
+			wantErr:      true,
+		},
+		{
+			osIdentifier: utils.Linux,
+			wantErr:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		c := NewMTUCollector(tt.osIdentifier, nil, nil)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("CheckSupported() error = %v, wantErr %v", err, tt.wantErr)
+		}
+	}
+}
+
+func TestMTUCollectorCollect(t *testing.T) {
+	const linkOutput = `1: lo: <LOOPBACK,UP,LOWER_UP> mtu 65536 qdisc noqueue state UNKNOWN mode DEFAULT group default qlen 1000\    link/loopback 00:00:00:00:00:00 brd 00:00:00:00:00:00
+2: eth0: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1500 qdisc mq state UP mode DEFAULT group default qlen 1000\    link/ether 00:0d:3a:00:00:01 brd ff:ff:ff:ff:ff:ff
+3: azv1234@if4: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1500 qdisc noqueue state UP mode DEFAULT group default\    link/ether aa:aa:aa:aa:aa:aa brd ff:ff:ff:ff:ff:ff link-netns cni-1
+4: cilium_vxlan: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 9000 qdisc noqueue state UNKNOWN mode DEFAULT group default\    link/ether bb:bb:bb:bb:bb:bb brd ff:ff:ff:ff:ff:ff
+`
+	const routeOutput = "default via 10.224.0.1 dev eth0 proto dhcp src 10.224.0.4 metric 100 \n"
+
+	tests := []struct {
+		name         string
+		probeTarget  string
+		pathMTU      int
+		pingErr      error
+		wantPathMTU  int
+		wantProbe    bool
+		wantWarnings int
+	}{
+		{
+			name:         "no probe target",
+			wantProbe:    false,
+			wantWarnings: 1,
+		},
+		{
+			name:         "path MTU matches interface",
+			probeTarget:  "10.0.0.1",
+			pathMTU:      1500,
+			wantPathMTU:  1500,
+			wantProbe:    true,
+			wantWarnings: 1,
+		},
+		{
+			name:         "path MTU less than interface",
+			probeTarget:  "10.0.0.1",
+			pathMTU:      1400,
+			wantPathMTU:  1400,
+			wantProbe:    true,
+			wantWarnings: 2,
+		},
+		{
+			name:         "ping unavailable",
+			probeTarget:  "10.0.0.1",
+			pingErr:      errors.New("ping: socket: Operation not permitted"),
+			wantPathMTU:  0,
+			wantProbe:    true,
+			wantWarnings: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runCommand := func(command string, arg ...string) (string, error) {
+				switch command + " " + strings.Join(arg, " ") {
+				case "ip -o link show":
+					return linkOutput, nil
+				case "ip -o route show default":
+					return routeOutput, nil
+				}
+				if command == "ping" {
+					if tt.pingErr != nil {
+						return "", tt.pingErr
+					}
+					size, _ := strconv.Atoi(arg[len(arg)-2])
+					if size+icmpHeaderBytes > tt.pathMTU {
+						return "", errors.New("ping: local error: message too long")
+					}
+					return "1 packets transmitted, 1 received", nil
+				}
+				return "", errors.New("unexpected command")
+			}
+
+			c := NewMTUCollector(utils.Linux, runCommand, &utils.RuntimeInfo{MTUProbeTarget: tt.probeTarget})
+			if err := c.Collect(); err != nil {
+				t.Fatalf("Collect() error = %v", err)
+			}
+
+			testDataValue(t, c.GetData()["mtu"], func(raw string) {
+				var report MTUReport
+				if err := json.Unmarshal([]byte(raw), &report); err != nil {
+					t.Fatalf("unmarshal GetData(): %v", err)
+				}
+
+				if report.PrimaryInterface != "eth0" || len(report.Interfaces) != 4 {
+					t.Errorf("unexpected interfaces (primary %s): %+v", report.PrimaryInterface, report.Interfaces)
+				}
+				for _, info := range report.Interfaces {
+					wantPodNetwork := info.Name == "azv1234" || info.Name == "cilium_vxlan"
+					if info.PodNetwork != wantPodNetwork || info.Primary != (info.Name == "eth0") {
+						t.Errorf("unexpected classification of %+v", info)
+					}
+				}
+
+				// The cilium_vxlan MTU exceeds that of eth0.
+				if len(report.Warnings) != tt.wantWarnings {
+					t.Errorf("expected %d warnings, found %v", tt.wantWarnings, report.Warnings)
+				}
+
+				if (report.PathProbe != nil) != tt.wantProbe {
+					t.Fatalf("unexpected path probe %+v", report.PathProbe)
+				}
+				if report.PathProbe == nil {
+					return
+				}
+				if report.PathProbe.PathMTU != tt.wantPathMTU {
+					t.Errorf("expected path MTU %d, found %+v", tt.wantPathMTU, report.PathProbe)
+				}
+				if (tt.pingErr != nil) != (report.PathProbe.Error != "") {
+					t.Errorf("unexpected probe error %q", report.PathProbe.Error)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+// The `ip` objects captured, by data key. Routes and rules are read from every table, since policy routing (e.g. by
+// Cilium or for multiple NICs) is often what's being debugged.
+var networkInterfaceCommands = []struct {
+	key  string
+	args []string
+}{
+	{key: "ip-addr", args: []string{"addr", "show"}},
+	{key: "ip-route", args: []string{"route", "show", "table", "all"}},
+	{key: "ip-rule", args: []string{"rule", "show"}},
+	{key: "ip-neigh", args: []string{"neigh", "show"}},
+}
+
+// NetworkInterfaceCollector defines a Network Interface Collector struct
+type NetworkInterfaceCollector struct {
+	data         map[string]string
+	osIdentifier utils.OSIdentifier
+	runCommand   utils.HostCommandRunner
+}
+
+// NewNetworkInterfaceCollector is a constructor
+func NewNetworkInterfaceCollector(osIdentifier utils.OSIdentifier, runCommand utils.HostCommandRunner) *NetworkInterfaceCollector {
+	return &NetworkInterfaceCollector{
+		data:         make(map[string]string),
+		osIdentifier: osIdentifier,
+		runCommand:   runCommand,
+	}
+}
+
+func (collector *NetworkInterfaceCollector) GetName() string {
+	return "networkinterface"
+}
+
+func (collector *NetworkInterfaceCollector) CheckSupported() error {
+	// This uses `ip` in the host's network namespace, which is only available on Linux.
+	if collector.osIdentifier != utils.Linux {
+		return fmt.Errorf("unsupported OS: %s", collector.osIdentifier)
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *NetworkInterfaceCollector) Collect() error {
+	// The commands run in the host's namespaces with the node's own `ip`, so they don't depend on iproute2 in this
+	// image. Its JSON output is preferred, but older versions only support text.
+	failed := []string{}
+	for _, command := range networkInterfaceCommands {
+		output, err := collector.runIPCommand(command.args)
+		if err != nil {
+			log.Printf("Unable to run ip %s: %v", strings.Join(command.args, " "), err)
+			failed = append(failed, command.key)
+			continue
+		}
+
+		collector.data[command.key] = output
+	}
+
+	if len(failed) == len(networkInterfaceCommands) {
+		return fmt.Errorf("unable to read network interfaces and routes: %s all failed", strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// runIPCommand runs `ip` with the given arguments, returning JSON output if supported, or text otherwise.
+func (collector *NetworkInterfaceCollector) runIPCommand(args []string) (string, error) {
+	output, err := collector.runCommand("ip", append([]string{"-json", "-details"}, args...)...)
+	if err == nil && json.Valid([]byte(output)) {
+		return output, nil
+	}
+
+	return collector.runCommand("ip", append([]string{"-details"}, args...)...)
+}
+
+func (collector *NetworkInterfaceCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
@@ -0,0 +1,122 @@
+package collector
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+func TestNetworkInterfaceCollectorGetName(t *testing.T) {
+	const expectedName = "networkinterface"
+
+	c := NewNetworkInterfaceCollector("", nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestNetworkInterfaceCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		osIdentifier utils.OSIdentifier
+		wantErr      bool
+	}{
+		{
+			osIdentifier: utils.Windows,
+			wantErr:      true,
+		},
+		{
+			osIdentifier: utils.Linux,
+			wantErr:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		c := NewNetworkInterfaceCollector(tt.osIdentifier, nil)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("CheckSupported() error = %v, wantErr %v", err, tt.wantErr)
+		}
+	}
+}
+
+func TestNetworkInterfaceCollectorCollect(t *testing.T) {
+	const addrOutput = `[{"ifindex":1,"ifname":"lo","mtu":65536,"addr_info":[{"family":"inet","local":"127.0.0.1","prefixlen":8}]}]`
+	const routeOutput = `[{"dst":"default","gateway":"10.224.0.1","dev":"eth0","table":"main"}]`
+	const ruleTextOutput = "0:\tfrom all lookup local\n32766:\tfrom all lookup main\n"
+
+	tests := []struct {
+		name     string
+		commands map[string]string
+		wantErr  bool
+		wantData map[string]string
+	}{
+		{
+			name: "json output",
+			commands: map[string]string{
+				"ip -json -details addr show":            addrOutput,
+				"ip -json -details route show table all": routeOutput,
+				"ip -json -details rule show":            "[]",
+				"ip -json -details neigh show":           "[]",
+				"ip -details rule show":                  ruleTextOutput,
+				"ip -details route show table all":       "unexpected",
+			},
+			wantData: map[string]string{
+				"ip-addr":  addrOutput,
+				"ip-route": routeOutput,
+				"ip-rule":  "[]",
+				"ip-neigh": "[]",
+			},
+		},
+		{
+			// Without JSON support, `ip` prints an error, or ignores the option and prints text.
+			name: "text fallback and missing command",
+			commands: map[string]string{
+				"ip -json -details addr show": addrOutput,
+				"ip -json -details rule show": ruleTextOutput,
+				"ip -details rule show":       ruleTextOutput,
+			},
+			wantData: map[string]string{
+				"ip-addr": addrOutput,
+				"ip-rule": ruleTextOutput,
+			},
+		},
+		{
+			name:     "all commands fail",
+			commands: map[string]string{},
+			wantErr:  true,
+			wantData: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runCommand := func(command string, arg ...string) (string, error) {
+				if output, ok := tt.commands[command+" "+strings.Join(arg, " ")]; ok {
+					return output, nil
+				}
+				return "", errors.New("exit status 255: Object \"neigh\" is unknown")
+			}
+
+			c := NewNetworkInterfaceCollector(utils.Linux, runCommand)
+			err := c.Collect()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Collect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			data := c.GetData()
+			if len(data) != len(tt.wantData) {
+				t.Errorf("expected %d data keys, found %d", len(tt.wantData), len(data))
+			}
+			for key, expected := range tt.wantData {
+				testDataValue(t, data[key], func(raw string) {
+					if raw != expected {
+						t.Errorf("unexpected %s: expected %q, found %q", key, expected, raw)
+					}
+				})
+			}
+		})
+	}
+}
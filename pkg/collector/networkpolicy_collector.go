@@ -0,0 +1,213 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+var networkPolicyGVR = schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "networkpolicies"}
+
+// Policy CRDs which are only present if the relevant network policy engine (or API) is installed.
+var networkPolicyCRDs = []string{
+	"ciliumnetworkpolicies.cilium.io",
+	"ciliumclusterwidenetworkpolicies.cilium.io",
+	"adminnetworkpolicies.policy.networking.k8s.io",
+	"baselineadminnetworkpolicies.policy.networking.k8s.io",
+}
+
+const ciliumEndpointCRD = "ciliumendpoints.cilium.io"
+
+// NetworkPolicyCollector defines a Network Policy Collector struct
+type NetworkPolicyCollector struct {
+	data          map[string]string
+	osIdentifier  utils.OSIdentifier
+	clientset     kubernetes.Interface
+	dynamicClient dynamic.Interface
+	runCommand    utils.HostCommandRunner
+	runtimeInfo   *utils.RuntimeInfo
+}
+
+// NewNetworkPolicyCollector is a constructor
+func NewNetworkPolicyCollector(osIdentifier utils.OSIdentifier, clientset kubernetes.Interface, dynamicClient dynamic.Interface, runCommand utils.HostCommandRunner, runtimeInfo *utils.RuntimeInfo) *NetworkPolicyCollector {
+	return &NetworkPolicyCollector{
+		data:          make(map[string]string),
+		osIdentifier:  osIdentifier,
+		clientset:     clientset,
+		dynamicClient: dynamicClient,
+		runCommand:    runCommand,
+		runtimeInfo:   runtimeInfo,
+	}
+}
+
+func (collector *NetworkPolicyCollector) GetName() string {
+	return "networkpolicy"
+}
+
+func (collector *NetworkPolicyCollector) CheckSupported() error {
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *NetworkPolicyCollector) Collect() error {
+	output, err := collector.getJsonListOutput(networkPolicyGVR)
+	if err != nil {
+		return fmt.Errorf("error listing network policies: %w", err)
+	}
+	collector.data[networkPolicyGVR.Resource] = output
+
+	// The policy CRDs may not be installed, so find which ones are before trying to list their resources.
+	crds, err := collector.getInstalledCRDs()
+	if err != nil {
+		return fmt.Errorf("error getting CRDs: %w", err)
+	}
+
+	for _, crdName := range networkPolicyCRDs {
+		crd, ok := crds[crdName]
+		if !ok {
+			log.Printf("CRD %s not installed, skipping", crdName)
+			continue
+		}
+
+		gvr, err := utils.GetStorageGVRFromCRD(crd)
+		if err != nil {
+			return fmt.Errorf("error getting GVR from CRD %s: %w", crdName, err)
+		}
+
+		output, err := collector.getJsonListOutput(*gvr)
+		if err != nil {
+			return fmt.Errorf("error listing %s resources: %w", gvr.String(), err)
+		}
+		collector.data[gvr.Resource] = output
+	}
+
+	// Datapath state can only be read from the host on Linux nodes.
+	if collector.osIdentifier != utils.Linux {
+		return nil
+	}
+
+	if crd, ok := crds[ciliumEndpointCRD]; ok {
+		if err := collector.collectCiliumEndpoints(crd); err != nil {
+			return fmt.Errorf("error collecting Cilium endpoints: %w", err)
+		}
+	}
+
+	collector.collectAzureNpmState()
+
+	return nil
+}
+
+// getJsonListOutput lists all the resources of the given type, as 'kubectl get [kind] -o json' would.
+func (collector *NetworkPolicyCollector) getJsonListOutput(gvr schema.GroupVersionResource) (string, error) {
+	list, err := collector.dynamicClient.Resource(gvr).Namespace(metav1.NamespaceAll).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	return utils.PrintUnstructuredAsJson(list)
+}
+
+func (collector *NetworkPolicyCollector) getInstalledCRDs() (map[string]*unstructured.Unstructured, error) {
+	crdList, err := collector.dynamicClient.Resource(crdGVR).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	crds := map[string]*unstructured.Unstructured{}
+	for i := range crdList.Items {
+		crds[crdList.Items[i].GetName()] = &crdList.Items[i]
+	}
+
+	return crds, nil
+}
+
+// collectCiliumEndpoints stores the Cilium endpoints (which include the policy enforcement state) for the pods on
+// this node.
+func (collector *NetworkPolicyCollector) collectCiliumEndpoints(crd *unstructured.Unstructured) error {
+	podList, err := collector.clientset.CoreV1().Pods(metav1.NamespaceAll).List(context.Background(), metav1.ListOptions{
+		FieldSelector: "spec.nodeName=" + collector.runtimeInfo.HostNodeName,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list pods on node %s: %w", collector.runtimeInfo.HostNodeName, err)
+	}
+
+	// Cilium endpoints have the same name and namespace as their pods.
+	nodePods := map[string]bool{}
+	for _, pod := range podList.Items {
+		nodePods[pod.Namespace+"/"+pod.Name] = true
+	}
+
+	gvr, err := utils.GetStorageGVRFromCRD(crd)
+	if err != nil {
+		return fmt.Errorf("error getting GVR from CRD %s: %w", crd.GetName(), err)
+	}
+
+	endpointList, err := collector.dynamicClient.Resource(*gvr).Namespace(metav1.NamespaceAll).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing %s resources: %w", gvr.String(), err)
+	}
+
+	endpoints := []map[string]interface{}{}
+	for _, endpoint := range endpointList.Items {
+		if nodePods[endpoint.GetNamespace()+"/"+endpoint.GetName()] {
+			endpoints = append(endpoints, endpoint.Object)
+		}
+	}
+
+	data, err := json.Marshal(endpoints)
+	if err != nil {
+		return fmt.Errorf("marshall cilium endpoints to json: %w", err)
+	}
+
+	collector.data["datapath/ciliumendpoints"] = string(data)
+
+	return nil
+}
+
+// collectAzureNpmState stores the ipsets and iptables rules programmed by Azure Network Policy Manager. These won't
+// exist if NPM is not in use, in which case nothing is stored.
+func (collector *NetworkPolicyCollector) collectAzureNpmState() {
+	rules, err := collector.runCommand("iptables-save", "-t", "filter")
+	if err != nil {
+		log.Printf("Unable to read iptables filter rules: %v", err)
+		return
+	}
+
+	npmRules := []string{}
+	for _, line := range strings.Split(rules, "\n") {
+		if strings.Contains(line, "AZURE-NPM") {
+			npmRules = append(npmRules, line)
+		}
+	}
+	if len(npmRules) == 0 {
+		return
+	}
+
+	collector.data["datapath/azure-npm-iptables"] = strings.Join(npmRules, "\n")
+
+	ipsets, err := collector.runCommand("ipset", "list")
+	if err != nil {
+		log.Printf("Unable to list ipsets: %v", err)
+		return
+	}
+
+	collector.data["datapath/azure-npm-ipsets"] = ipsets
+}
+
+func (collector *NetworkPolicyCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
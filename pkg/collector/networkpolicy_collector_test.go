@@ -0,0 +1,194 @@
+package collector
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNetworkPolicyCollectorGetName(t *testing.T) {
+	const expectedName = "networkpolicy"
+
+	c := NewNetworkPolicyCollector("", nil, nil, nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestNetworkPolicyCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewNetworkPolicyCollector(utils.Linux, nil, nil, nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNetworkPolicyCollectorCollect(t *testing.T) {
+	const (
+		hostNodeName = "node-1"
+		npmRules     = "*filter\n:AZURE-NPM - [0:0]\n-A FORWARD -j AZURE-NPM\n-A KUBE-FORWARD -j ACCEPT\nCOMMIT\n"
+	)
+
+	newCRD := func(name, version string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apiextensions.k8s.io/v1",
+			"kind":       "CustomResourceDefinition",
+			"metadata":   map[string]interface{}{"name": name},
+			"spec": map[string]interface{}{
+				"versions": []interface{}{
+					map[string]interface{}{"name": version, "storage": true},
+				},
+			},
+		}}
+	}
+
+	newResource := func(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata":   map[string]interface{}{"name": name, "namespace": namespace},
+		}}
+	}
+
+	denyAll := newResource("networking.k8s.io/v1", "NetworkPolicy", "shop", "deny-all")
+	ciliumPolicy := newResource("cilium.io/v2", "CiliumNetworkPolicy", "shop", "allow-frontend")
+	nodeEndpoint := newResource("cilium.io/v2", "CiliumEndpoint", "shop", "cart-1")
+	otherEndpoint := newResource("cilium.io/v2", "CiliumEndpoint", "shop", "cart-2")
+	nodePod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "cart-1", Namespace: "shop"},
+		Spec:       corev1.PodSpec{NodeName: hostNodeName},
+	}
+
+	listKinds := map[schema.GroupVersionResource]string{
+		crdGVR:           "CustomResourceDefinitionList",
+		networkPolicyGVR: "NetworkPolicyList",
+		{Group: "cilium.io", Version: "v2", Resource: "ciliumnetworkpolicies"}:            "CiliumNetworkPolicyList",
+		{Group: "cilium.io", Version: "v2", Resource: "ciliumclusterwidenetworkpolicies"}: "CiliumClusterwideNetworkPolicyList",
+		{Group: "cilium.io", Version: "v2", Resource: "ciliumendpoints"}:                  "CiliumEndpointList",
+	}
+
+	tests := []struct {
+		name         string
+		osIdentifier utils.OSIdentifier
+		objects      []runtime.Object
+		iptables     string
+		iptablesErr  error
+		ipsets       string
+		expectedData map[string]*regexp.Regexp
+	}{
+		{
+			name:         "no network policy engine",
+			osIdentifier: utils.Linux,
+			objects:      []runtime.Object{denyAll},
+			iptables:     "*filter\n-A KUBE-FORWARD -j ACCEPT\nCOMMIT\n",
+			expectedData: map[string]*regexp.Regexp{
+				"networkpolicies": regexp.MustCompile(`"name": "deny-all"`),
+			},
+		},
+		{
+			name:         "iptables unavailable",
+			osIdentifier: utils.Linux,
+			iptablesErr:  errors.New("iptables-save not found"),
+			expectedData: map[string]*regexp.Regexp{
+				"networkpolicies": regexp.MustCompile(`"items": \[\]`),
+			},
+		},
+		{
+			name:         "azure npm",
+			osIdentifier: utils.Linux,
+			iptables:     npmRules,
+			ipsets:       "Name: azure-npm-123\nType: hash:net\n",
+			expectedData: map[string]*regexp.Regexp{
+				"networkpolicies":             regexp.MustCompile(`"items"`),
+				"datapath/azure-npm-iptables": regexp.MustCompile(`^:AZURE-NPM - \[0:0\]\n-A FORWARD -j AZURE-NPM$`),
+				"datapath/azure-npm-ipsets":   regexp.MustCompile(`^Name: azure-npm-123`),
+			},
+		},
+		{
+			name:         "cilium policies and endpoints of this node",
+			osIdentifier: utils.Linux,
+			objects: []runtime.Object{
+				newCRD("ciliumnetworkpolicies.cilium.io", "v2"),
+				newCRD("ciliumclusterwidenetworkpolicies.cilium.io", "v2"),
+				newCRD("ciliumendpoints.cilium.io", "v2"),
+				ciliumPolicy,
+				nodeEndpoint,
+				otherEndpoint,
+			},
+			expectedData: map[string]*regexp.Regexp{
+				"networkpolicies":                  regexp.MustCompile(`"items"`),
+				"ciliumnetworkpolicies":            regexp.MustCompile(`"name": "allow-frontend"`),
+				"ciliumclusterwidenetworkpolicies": regexp.MustCompile(`"items"`),
+				"datapath/ciliumendpoints":         regexp.MustCompile(`^\[{"apiVersion":"cilium.io/v2","kind":"CiliumEndpoint","metadata":{"name":"cart-1","namespace":"shop"}}\]$`),
+			},
+		},
+		{
+			name:         "windows has no datapath state",
+			osIdentifier: utils.Windows,
+			objects:      []runtime.Object{newCRD("ciliumendpoints.cilium.io", "v2"), nodeEndpoint},
+			iptables:     npmRules,
+			expectedData: map[string]*regexp.Regexp{
+				"networkpolicies": regexp.MustCompile(`"items"`),
+			},
+		},
+	}
+
+	runtimeInfo := &utils.RuntimeInfo{
+		HostNodeName:  hostNodeName,
+		CollectorList: []string{},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runCommand := func(command string, arg ...string) (string, error) {
+				if command == "ipset" {
+					return tt.ipsets, nil
+				}
+				return tt.iptables, tt.iptablesErr
+			}
+
+			clientset := fake.NewSimpleClientset(nodePod)
+			dynamicClient := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, tt.objects...)
+
+			c := NewNetworkPolicyCollector(tt.osIdentifier, clientset, dynamicClient, runCommand, runtimeInfo)
+			err := c.Collect()
+			if err != nil {
+				t.Fatalf("Collect() error = %v", err)
+			}
+
+			compareCollectorData(t, tt.expectedData, c.GetData())
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+type NodeConditionsInfo struct {
+	Name          string                `json:"name"`
+	Unschedulable bool                  `json:"unschedulable"`
+	Conditions    []NodeConditionInfo   `json:"conditions"`
+	Taints        []NodeConditionsTaint `json:"taints"`
+}
+
+type NodeConditionInfo struct {
+	Type               string     `json:"type"`
+	Status             string     `json:"status"`
+	Reason             string     `json:"reason,omitempty"`
+	Message            string     `json:"message,omitempty"`
+	LastTransitionTime *time.Time `json:"lastTransitionTime,omitempty"`
+}
+
+type NodeConditionsTaint struct {
+	Key    string `json:"key"`
+	Value  string `json:"value,omitempty"`
+	Effect string `json:"effect"`
+}
+
+// NodeConditionsCollector defines a Node Conditions Collector struct
+type NodeConditionsCollector struct {
+	data        map[string]string
+	clientset   kubernetes.Interface
+	runtimeInfo *utils.RuntimeInfo
+}
+
+// NewNodeConditionsCollector is a constructor
+func NewNodeConditionsCollector(clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *NodeConditionsCollector {
+	return &NodeConditionsCollector{
+		data:        make(map[string]string),
+		clientset:   clientset,
+		runtimeInfo: runtimeInfo,
+	}
+}
+
+func (collector *NodeConditionsCollector) GetName() string {
+	return "nodeconditions"
+}
+
+func (collector *NodeConditionsCollector) CheckSupported() error {
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *NodeConditionsCollector) Collect() error {
+	result := []NodeConditionsInfo{}
+
+	err := utils.ListAllPages(metav1.ListOptions{}, func(listOptions metav1.ListOptions) (string, error) {
+		nodeList, err := collector.clientset.CoreV1().Nodes().List(context.Background(), listOptions)
+		if err != nil {
+			return "", err
+		}
+
+		for _, node := range nodeList.Items {
+			info := NodeConditionsInfo{
+				Name:          node.Name,
+				Unschedulable: node.Spec.Unschedulable,
+				Conditions:    []NodeConditionInfo{},
+				Taints:        []NodeConditionsTaint{},
+			}
+
+			for _, condition := range node.Status.Conditions {
+				conditionInfo := NodeConditionInfo{
+					Type:    string(condition.Type),
+					Status:  string(condition.Status),
+					Reason:  condition.Reason,
+					Message: condition.Message,
+				}
+				if !condition.LastTransitionTime.IsZero() {
+					lastTransitionTime := condition.LastTransitionTime.Time
+					conditionInfo.LastTransitionTime = &lastTransitionTime
+				}
+				info.Conditions = append(info.Conditions, conditionInfo)
+			}
+
+			for _, taint := range node.Spec.Taints {
+				info.Taints = append(info.Taints, NodeConditionsTaint{
+					Key:    taint.Key,
+					Value:  taint.Value,
+					Effect: string(taint.Effect),
+				})
+			}
+
+			result = append(result, info)
+		}
+
+		return nodeList.Continue, nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list nodes: %w", err)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshall node conditions to json: %w", err)
+	}
+
+	collector.data["node-conditions"] = string(data)
+
+	return nil
+}
+
+func (collector *NodeConditionsCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
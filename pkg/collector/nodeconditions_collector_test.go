@@ -0,0 +1,141 @@
+package collector
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestNodeConditionsCollectorGetName(t *testing.T) {
+	const expectedName = "nodeconditions"
+
+	c := NewNodeConditionsCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestNodeConditionsCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewNodeConditionsCollector(nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNodeConditionsCollectorCollect(t *testing.T) {
+	transitionTime := time.Date(2023, 6, 15, 12, 0, 0, 0, time.UTC)
+	earlierTransitionTime := transitionTime.Add(-time.Hour)
+
+	healthy := corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue, Reason: "KubeletReady", LastTransitionTime: metav1.NewTime(transitionTime)},
+				{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionFalse, Reason: "KubeletHasSufficientMemory", LastTransitionTime: metav1.NewTime(earlierTransitionTime)},
+			},
+		},
+	}
+
+	cordoned := corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-0"},
+		Spec: corev1.NodeSpec{
+			Unschedulable: true,
+			Taints: []corev1.Taint{
+				{Key: "node.kubernetes.io/unschedulable", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "node.kubernetes.io/disk-pressure", Effect: corev1.TaintEffectNoSchedule},
+			},
+		},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeDiskPressure, Status: corev1.ConditionTrue, Reason: "KubeletHasDiskPressure", Message: "kubelet has disk pressure"},
+			},
+		},
+	}
+
+	clientset := fake.NewSimpleClientset()
+
+	// The fake clientset doesn't paginate, so serve the nodes over two pages.
+	pages := []*corev1.NodeList{
+		{ListMeta: metav1.ListMeta{Continue: "page-2"}, Items: []corev1.Node{healthy}},
+		{Items: []corev1.Node{cordoned}},
+	}
+	nodeListCalls := 0
+	clientset.PrependReactor("list", "nodes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		page := pages[nodeListCalls]
+		nodeListCalls++
+		return true, page, nil
+	})
+
+	c := NewNodeConditionsCollector(clientset, &utils.RuntimeInfo{})
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	if nodeListCalls != len(pages) {
+		t.Errorf("expected %d node list calls, found %d", len(pages), nodeListCalls)
+	}
+
+	testDataValue(t, c.GetData()["node-conditions"], func(raw string) {
+		var nodes []NodeConditionsInfo
+		if err := json.Unmarshal([]byte(raw), &nodes); err != nil {
+			t.Fatalf("unmarshal GetData(): %v", err)
+		}
+
+		expected := []NodeConditionsInfo{
+			{
+				Name:          "node-0",
+				Unschedulable: true,
+				Conditions: []NodeConditionInfo{
+					{Type: "DiskPressure", Status: "True", Reason: "KubeletHasDiskPressure", Message: "kubelet has disk pressure"},
+				},
+				Taints: []NodeConditionsTaint{
+					{Key: "node.kubernetes.io/unschedulable", Effect: "NoSchedule"},
+					{Key: "node.kubernetes.io/disk-pressure", Effect: "NoSchedule"},
+				},
+			},
+			{
+				Name: "node-1",
+				Conditions: []NodeConditionInfo{
+					{Type: "Ready", Status: "True", Reason: "KubeletReady", LastTransitionTime: &transitionTime},
+					{Type: "MemoryPressure", Status: "False", Reason: "KubeletHasSufficientMemory", LastTransitionTime: &earlierTransitionTime},
+				},
+				Taints: []NodeConditionsTaint{},
+			},
+		}
+		if !reflect.DeepEqual(nodes, expected) {
+			t.Errorf("unexpected node conditions:\nexpected %+v\nfound    %+v", expected, nodes)
+		}
+	})
+}
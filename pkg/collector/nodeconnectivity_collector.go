@@ -0,0 +1,186 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// The kubelet listens on every node, so its port can be used as a target without deploying anything.
+	nodeConnectivityPort = 10250
+
+	// Every node probes its peers at the same time, so the number of targets per node, and the number of concurrent
+	// probes, are capped to keep the total number of connections in a large cluster manageable.
+	nodeConnectivityMaxTargets  = 50
+	nodeConnectivityConcurrency = 10
+	nodeConnectivityTimeout     = 3 * time.Second
+)
+
+// NodeConnectivityRow is the row of the reachability matrix for this node. Each node contributes its own row.
+type NodeConnectivityRow struct {
+	SourceNode string                   `json:"sourceNode"`
+	Port       int                      `json:"port"`
+	Targets    []NodeConnectivityTarget `json:"targets"`
+	// SkippedNodes is the number of peers which weren't probed because of the target cap.
+	SkippedNodes int `json:"skippedNodes"`
+}
+
+type NodeConnectivityTarget struct {
+	Node      string   `json:"node"`
+	Address   string   `json:"address"`
+	PodCIDRs  []string `json:"podCIDRs"`
+	Ready     bool     `json:"ready"`
+	Reachable bool     `json:"reachable"`
+	LatencyMs float64  `json:"latencyMs,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// NodeConnectivityCollector defines a Node Connectivity Collector struct
+type NodeConnectivityCollector struct {
+	data        map[string]string
+	clientset   kubernetes.Interface
+	probe       utils.TCPConnectionProber
+	runtimeInfo *utils.RuntimeInfo
+}
+
+// NewNodeConnectivityCollector is a constructor
+func NewNodeConnectivityCollector(clientset kubernetes.Interface, probe utils.TCPConnectionProber, runtimeInfo *utils.RuntimeInfo) *NodeConnectivityCollector {
+	return &NodeConnectivityCollector{
+		data:        make(map[string]string),
+		clientset:   clientset,
+		probe:       probe,
+		runtimeInfo: runtimeInfo,
+	}
+}
+
+func (collector *NodeConnectivityCollector) GetName() string {
+	return "nodeconnectivity"
+}
+
+func (collector *NodeConnectivityCollector) CheckSupported() error {
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *NodeConnectivityCollector) Collect() error {
+	nodes, err := collector.clientset.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list nodes: %w", err)
+	}
+
+	targets, skipped := getNodeConnectivityTargets(nodes.Items, collector.runtimeInfo.HostNodeName)
+	row := &NodeConnectivityRow{
+		SourceNode:   collector.runtimeInfo.HostNodeName,
+		Port:         nodeConnectivityPort,
+		Targets:      targets,
+		SkippedNodes: skipped,
+	}
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, nodeConnectivityConcurrency)
+	for i := range row.Targets {
+		target := &row.Targets[i]
+		if target.Address == "" {
+			target.Error = "node has no internal IP address"
+			continue
+		}
+
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			latency, err := collector.probe(target.Address, nodeConnectivityTimeout)
+			if err != nil {
+				target.Error = err.Error()
+				return
+			}
+
+			target.Reachable = true
+			target.LatencyMs = float64(latency.Microseconds()) / 1000
+		}()
+	}
+	wg.Wait()
+
+	data, err := json.Marshal(row)
+	if err != nil {
+		return fmt.Errorf("marshall node connectivity to json: %w", err)
+	}
+
+	collector.data["node-connectivity"] = string(data)
+
+	return nil
+}
+
+// getNodeConnectivityTargets returns the peers of the source node to probe, and the number which were skipped. The
+// nodes are ordered by name, and each node takes the peers which follow it (wrapping around), so that if the number
+// of targets is capped the rows of all nodes still cover every pair between neighbours.
+func getNodeConnectivityTargets(nodes []corev1.Node, sourceNode string) ([]NodeConnectivityTarget, int) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+
+	start := sort.Search(len(nodes), func(i int) bool {
+		return nodes[i].Name > sourceNode
+	})
+
+	targets := []NodeConnectivityTarget{}
+	skipped := 0
+	for i := 0; i < len(nodes); i++ {
+		node := &nodes[(start+i)%len(nodes)]
+		if node.Name == sourceNode {
+			continue
+		}
+		if len(targets) == nodeConnectivityMaxTargets {
+			skipped++
+			continue
+		}
+
+		target := NodeConnectivityTarget{
+			Node:     node.Name,
+			PodCIDRs: node.Spec.PodCIDRs,
+		}
+		if target.PodCIDRs == nil {
+			target.PodCIDRs = []string{}
+		}
+
+		for _, address := range node.Status.Addresses {
+			if address.Type == corev1.NodeInternalIP {
+				target.Address = net.JoinHostPort(address.Address, strconv.Itoa(nodeConnectivityPort))
+				break
+			}
+		}
+
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == corev1.NodeReady {
+				target.Ready = condition.Status == corev1.ConditionTrue
+			}
+		}
+
+		targets = append(targets, target)
+	}
+
+	return targets, skipped
+}
+
+func (collector *NodeConnectivityCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
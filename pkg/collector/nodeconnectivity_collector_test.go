@@ -0,0 +1,144 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNodeConnectivityCollectorGetName(t *testing.T) {
+	const expectedName = "nodeconnectivity"
+
+	c := NewNodeConnectivityCollector(nil, nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestNodeConnectivityCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewNodeConnectivityCollector(nil, nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func newConnectivityTestNode(name, internalIP string, ready bool) *corev1.Node {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       corev1.NodeSpec{PodCIDRs: []string{}},
+	}
+	if internalIP != "" {
+		node.Status.Addresses = []corev1.NodeAddress{
+			{Type: corev1.NodeHostName, Address: name},
+			{Type: corev1.NodeInternalIP, Address: internalIP},
+		}
+	}
+
+	status := corev1.ConditionTrue
+	if !ready {
+		status = corev1.ConditionFalse
+	}
+	node.Status.Conditions = []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}}
+	return node
+}
+
+func TestNodeConnectivityCollectorCollect(t *testing.T) {
+	node1 := newConnectivityTestNode("node1", "10.224.0.4", true)
+	node1.Spec.PodCIDRs = []string{"10.244.0.0/24"}
+
+	objects := []runtime.Object{
+		node1,
+		newConnectivityTestNode("node2", "10.224.0.5", true),
+		newConnectivityTestNode("node3", "10.224.0.6", false),
+		newConnectivityTestNode("node4", "", true),
+	}
+
+	probe := func(address string, timeout time.Duration) (time.Duration, error) {
+		if timeout <= 0 {
+			t.Errorf("expected a bounded timeout for %s", address)
+		}
+		if address == "10.224.0.6:10250" {
+			return 0, fmt.Errorf("TCP connection to %s: i/o timeout", address)
+		}
+		return 1500 * time.Microsecond, nil
+	}
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+		HostNodeName:  "node2",
+	}
+
+	c := NewNodeConnectivityCollector(fake.NewSimpleClientset(objects...), probe, runtimeInfo)
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	want := NodeConnectivityRow{
+		SourceNode: "node2",
+		Port:       10250,
+		Targets: []NodeConnectivityTarget{
+			{Node: "node3", Address: "10.224.0.6:10250", PodCIDRs: []string{}, Ready: false, Error: "TCP connection to 10.224.0.6:10250: i/o timeout"},
+			{Node: "node4", PodCIDRs: []string{}, Ready: true, Error: "node has no internal IP address"},
+			{Node: "node1", Address: "10.224.0.4:10250", PodCIDRs: []string{"10.244.0.0/24"}, Ready: true, Reachable: true, LatencyMs: 1.5},
+		},
+	}
+
+	testDataValue(t, c.GetData()["node-connectivity"], func(raw string) {
+		var row NodeConnectivityRow
+		if err := json.Unmarshal([]byte(raw), &row); err != nil {
+			t.Fatalf("unmarshal node connectivity: %v", err)
+		}
+		if !reflect.DeepEqual(row, want) {
+			t.Errorf("unexpected node connectivity:\nexpected %+v\nfound    %+v", want, row)
+		}
+	})
+}
+
+func TestGetNodeConnectivityTargets(t *testing.T) {
+	nodes := []corev1.Node{}
+	for i := 0; i < nodeConnectivityMaxTargets+10; i++ {
+		nodes = append(nodes, *newConnectivityTestNode(fmt.Sprintf("node%03d", i), fmt.Sprintf("10.0.%d.%d", i/256, i%256), true))
+	}
+
+	targets, skipped := getNodeConnectivityTargets(nodes, "node055")
+	if len(targets) != nodeConnectivityMaxTargets || skipped != 9 {
+		t.Fatalf("expected %d targets and 9 skipped, found %d and %d", nodeConnectivityMaxTargets, len(targets), skipped)
+	}
+
+	// The targets are the peers following the source node, wrapping around to the start.
+	if targets[0].Node != "node056" || targets[4].Node != "node000" || targets[len(targets)-1].Node != "node045" {
+		t.Errorf("unexpected targets %s, %s ... %s", targets[0].Node, targets[4].Node, targets[len(targets)-1].Node)
+	}
+}
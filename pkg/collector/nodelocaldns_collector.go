@@ -0,0 +1,231 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	nodeLocalDNSName        = "node-local-dns"
+	nodeLocalDNSPodSelector = "k8s-app=node-local-dns"
+
+	// Defaults of the node-cache binary, used if the container arguments don't override them.
+	nodeLocalDNSDefaultInterface   = "nodelocaldns"
+	nodeLocalDNSDefaultMetricsPort = 9253
+)
+
+// NodeLocalDNSConfig describes how the node-local-dns pod on this node is configured, and whether the node is set up
+// to send DNS traffic to it: its interface must hold the local IPs, and the NOTRACK rules must exempt DNS traffic to
+// those IPs from connection tracking.
+type NodeLocalDNSConfig struct {
+	Pod                 string   `json:"pod"`
+	Image               string   `json:"image"`
+	LocalIPs            []string `json:"localIPs"`
+	InterfaceName       string   `json:"interfaceName"`
+	Interface           string   `json:"interface"`
+	Corefile            string   `json:"corefile"`
+	NoTrackRules        []string `json:"noTrackRules"`
+	LocalIPsWithoutRule []string `json:"localIPsWithoutRule"`
+	Errors              []string `json:"errors"`
+}
+
+// NodeLocalDNSCollector defines a NodeLocal DNSCache Collector struct
+type NodeLocalDNSCollector struct {
+	data          map[string]string
+	osIdentifier  utils.OSIdentifier
+	clientset     kubernetes.Interface
+	runCommand    utils.HostCommandRunner
+	scrapeMetrics utils.PodMetricsScraper
+	runtimeInfo   *utils.RuntimeInfo
+	pod           *corev1.Pod
+}
+
+// NewNodeLocalDNSCollector is a constructor
+func NewNodeLocalDNSCollector(osIdentifier utils.OSIdentifier, clientset kubernetes.Interface, runCommand utils.HostCommandRunner, scrapeMetrics utils.PodMetricsScraper, runtimeInfo *utils.RuntimeInfo) *NodeLocalDNSCollector {
+	return &NodeLocalDNSCollector{
+		data:          make(map[string]string),
+		osIdentifier:  osIdentifier,
+		clientset:     clientset,
+		runCommand:    runCommand,
+		scrapeMetrics: scrapeMetrics,
+		runtimeInfo:   runtimeInfo,
+	}
+}
+
+func (collector *NodeLocalDNSCollector) GetName() string {
+	return "nodelocaldns"
+}
+
+func (collector *NodeLocalDNSCollector) CheckSupported() error {
+	// NodeLocal DNSCache relies on a dummy interface and iptables rules, and is only deployed to Linux nodes.
+	if collector.osIdentifier != utils.Linux {
+		return fmt.Errorf("unsupported OS: %s", collector.osIdentifier)
+	}
+
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	ctx := context.Background()
+	_, err := collector.clientset.AppsV1().DaemonSets(metav1.NamespaceSystem).Get(ctx, nodeLocalDNSName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("NodeLocal DNSCache is not installed: daemonset %s not found", nodeLocalDNSName)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to get daemonset %s: %w", nodeLocalDNSName, err)
+	}
+
+	pods, err := collector.clientset.CoreV1().Pods(metav1.NamespaceSystem).List(ctx, metav1.ListOptions{
+		LabelSelector: nodeLocalDNSPodSelector,
+		FieldSelector: "spec.nodeName=" + collector.runtimeInfo.HostNodeName,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list %s pods: %w", nodeLocalDNSName, err)
+	}
+
+	for i := range pods.Items {
+		if pods.Items[i].Spec.NodeName == collector.runtimeInfo.HostNodeName {
+			collector.pod = &pods.Items[i]
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no %s pod found on node %s", nodeLocalDNSName, collector.runtimeInfo.HostNodeName)
+}
+
+// Collect implements the interface method
+func (collector *NodeLocalDNSCollector) Collect() error {
+	if collector.pod == nil {
+		if err := collector.CheckSupported(); err != nil {
+			return err
+		}
+	}
+
+	ctx := context.Background()
+	config := getNodeLocalDNSConfig(collector.pod)
+
+	configMap, err := collector.clientset.CoreV1().ConfigMaps(metav1.NamespaceSystem).Get(ctx, nodeLocalDNSName, metav1.GetOptions{})
+	if err != nil {
+		config.Errors = append(config.Errors, fmt.Sprintf("unable to get configmap %s: %v", nodeLocalDNSName, err))
+	} else {
+		config.Corefile = configMap.Data["Corefile"]
+	}
+
+	config.Interface, err = collector.runCommand("ip", "-o", "addr", "show", "dev", config.InterfaceName)
+	if err != nil {
+		config.Errors = append(config.Errors, fmt.Sprintf("unable to show interface %s: %v", config.InterfaceName, err))
+	}
+
+	rules, err := collector.runCommand("iptables", "-w", "-t", "raw", "-S")
+	if err != nil {
+		config.Errors = append(config.Errors, fmt.Sprintf("unable to list iptables raw rules: %v", err))
+	} else {
+		config.NoTrackRules, config.LocalIPsWithoutRule = getNoTrackRules(rules, config.LocalIPs)
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("marshall node-local-dns config to json: %w", err)
+	}
+
+	collector.data["nodelocaldns-config"] = string(data)
+
+	metrics, err := collector.scrapeMetrics(collector.pod.Namespace, collector.pod.Name, getMetricsPort(collector.pod, nodeLocalDNSDefaultMetricsPort))
+	if err != nil {
+		return err
+	}
+
+	collector.data["nodelocaldns-metrics"] = metrics
+
+	return nil
+}
+
+// getNodeLocalDNSConfig reads the local IPs and interface name from the arguments of the node-cache container,
+// which are given as e.g. "-localip 169.254.20.10,10.0.0.10" or "-localip=169.254.20.10".
+func getNodeLocalDNSConfig(pod *corev1.Pod) *NodeLocalDNSConfig {
+	config := &NodeLocalDNSConfig{
+		Pod:                 pod.Name,
+		LocalIPs:            []string{},
+		InterfaceName:       nodeLocalDNSDefaultInterface,
+		NoTrackRules:        []string{},
+		LocalIPsWithoutRule: []string{},
+		Errors:              []string{},
+	}
+
+	for _, container := range pod.Spec.Containers {
+		args := append(append([]string{}, container.Command...), container.Args...)
+		localIPs := getFlagValue(args, "localip")
+		if localIPs == "" {
+			continue
+		}
+
+		config.Image = container.Image
+		config.LocalIPs = strings.Split(localIPs, ",")
+		if name := getFlagValue(args, "interfacename"); name != "" {
+			config.InterfaceName = name
+		}
+	}
+
+	return config
+}
+
+func getFlagValue(args []string, flag string) string {
+	for i, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if name != flag {
+			continue
+		}
+		if hasValue {
+			return value
+		}
+		if i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+
+	return ""
+}
+
+// getNoTrackRules returns the rules of `iptables -t raw -S` which exempt traffic from connection tracking, and the
+// local IPs which no such rule refers to.
+func getNoTrackRules(rules string, localIPs []string) ([]string, []string) {
+	noTrackRules := []string{}
+	for _, rule := range strings.Split(rules, "\n") {
+		if strings.Contains(rule, "NOTRACK") || strings.Contains(rule, "--notrack") {
+			noTrackRules = append(noTrackRules, strings.TrimSpace(rule))
+		}
+	}
+
+	withoutRule := []string{}
+	for _, ip := range localIPs {
+		found := false
+		for _, rule := range noTrackRules {
+			if strings.Contains(rule, " "+ip+"/") || strings.Contains(rule, " "+ip+" ") {
+				found = true
+				break
+			}
+		}
+		if !found {
+			withoutRule = append(withoutRule, ip)
+		}
+	}
+
+	return noTrackRules, withoutRule
+}
+
+func (collector *NodeLocalDNSCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
@@ -0,0 +1,178 @@
+package collector
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const testNodeLocalDNSRawRules = `-P PREROUTING ACCEPT
+-P OUTPUT ACCEPT
+-A PREROUTING -d 169.254.20.10/32 -p udp -m udp --dport 53 -j NOTRACK
+-A OUTPUT -s 169.254.20.10/32 -p udp -m udp --sport 53 -j NOTRACK
+-A PREROUTING -d 10.0.0.1/32 -p tcp -j ACCEPT
+`
+
+func newNodeLocalDNSPod(name, nodeName string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: metav1.NamespaceSystem, Labels: map[string]string{"k8s-app": "node-local-dns"}},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+			Containers: []corev1.Container{{
+				Name:  "node-cache",
+				Image: "registry.k8s.io/dns/k8s-dns-node-cache:1.22.20",
+				Args:  []string{"-localip", "169.254.20.10,10.0.0.10", "-conf", "/etc/Corefile", "-interfacename=nodelocaldns"},
+			}},
+		},
+	}
+}
+
+func TestNodeLocalDNSCollectorGetName(t *testing.T) {
+	const expectedName = "nodelocaldns"
+
+	c := NewNodeLocalDNSCollector(utils.Linux, nil, nil, nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestNodeLocalDNSCollectorCheckSupported(t *testing.T) {
+	daemonSet := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "node-local-dns", Namespace: metav1.NamespaceSystem}}
+
+	tests := []struct {
+		name          string
+		osIdentifier  utils.OSIdentifier
+		collectorList []string
+		objects       []runtime.Object
+		wantErr       bool
+	}{
+		{
+			name:          "Windows",
+			osIdentifier:  utils.Windows,
+			collectorList: []string{},
+			objects:       []runtime.Object{daemonSet, newNodeLocalDNSPod("node-local-dns-1", "node1")},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{"connectedCluster"},
+			objects:       []runtime.Object{daemonSet, newNodeLocalDNSPod("node-local-dns-1", "node1")},
+			wantErr:       true,
+		},
+		{
+			name:          "not installed",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{},
+			objects:       []runtime.Object{},
+			wantErr:       true,
+		},
+		{
+			name:          "no pod on this node",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{},
+			objects:       []runtime.Object{daemonSet, newNodeLocalDNSPod("node-local-dns-2", "node2")},
+			wantErr:       true,
+		},
+		{
+			name:          "pod on this node",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{},
+			objects:       []runtime.Object{daemonSet, newNodeLocalDNSPod("node-local-dns-1", "node1")},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+			HostNodeName:  "node1",
+		}
+		c := NewNodeLocalDNSCollector(tt.osIdentifier, fake.NewSimpleClientset(tt.objects...), nil, nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNodeLocalDNSCollectorCollect(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "node-local-dns", Namespace: metav1.NamespaceSystem}},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-local-dns", Namespace: metav1.NamespaceSystem},
+			Data:       map[string]string{"Corefile": "cluster.local:53 {\n    cache 30\n}\n"},
+		},
+		newNodeLocalDNSPod("node-local-dns-2", "node2"),
+		newNodeLocalDNSPod("node-local-dns-1", "node1"),
+	)
+
+	runCommand := func(command string, arg ...string) (string, error) {
+		switch command + " " + strings.Join(arg, " ") {
+		case "ip -o addr show dev nodelocaldns":
+			return "12: nodelocaldns    inet 169.254.20.10/32 scope global nodelocaldns\n", nil
+		case "iptables -w -t raw -S":
+			return testNodeLocalDNSRawRules, nil
+		}
+		return "", errors.New("unexpected command")
+	}
+
+	scrapedPods := []string{}
+	scrapeMetrics := func(namespace, pod, port string) (string, error) {
+		scrapedPods = append(scrapedPods, pod+":"+port)
+		return "coredns_dns_requests_total{zone=\".\"} 5\n", nil
+	}
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+		HostNodeName:  "node1",
+	}
+
+	c := NewNodeLocalDNSCollector(utils.Linux, clientset, runCommand, scrapeMetrics, runtimeInfo)
+	if err := c.CheckSupported(); err != nil {
+		t.Fatalf("CheckSupported() error = %v", err)
+	}
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(scrapedPods, []string{"node-local-dns-1:9253"}) {
+		t.Errorf("unexpected scraped pods %v", scrapedPods)
+	}
+	if !strings.Contains(c.data["nodelocaldns-metrics"], "coredns_dns_requests_total") {
+		t.Errorf("unexpected metrics %s", c.data["nodelocaldns-metrics"])
+	}
+
+	var config NodeLocalDNSConfig
+	if err := json.Unmarshal([]byte(c.data["nodelocaldns-config"]), &config); err != nil {
+		t.Fatalf("unable to unmarshal config: %v", err)
+	}
+
+	expected := NodeLocalDNSConfig{
+		Pod:           "node-local-dns-1",
+		Image:         "registry.k8s.io/dns/k8s-dns-node-cache:1.22.20",
+		LocalIPs:      []string{"169.254.20.10", "10.0.0.10"},
+		InterfaceName: "nodelocaldns",
+		Interface:     "12: nodelocaldns    inet 169.254.20.10/32 scope global nodelocaldns\n",
+		Corefile:      "cluster.local:53 {\n    cache 30\n}\n",
+		NoTrackRules: []string{
+			"-A PREROUTING -d 169.254.20.10/32 -p udp -m udp --dport 53 -j NOTRACK",
+			"-A OUTPUT -s 169.254.20.10/32 -p udp -m udp --sport 53 -j NOTRACK",
+		},
+		LocalIPsWithoutRule: []string{"10.0.0.10"},
+		Errors:              []string{},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("unexpected config:\nexpected %+v\nfound    %+v", expected, config)
+	}
+}
@@ -2,25 +2,34 @@ package collector
 
 import (
 	"fmt"
+	"io"
+	"math"
 	"strings"
 
 	"github.com/Azure/aks-periscope/pkg/interfaces"
 	"github.com/Azure/aks-periscope/pkg/utils"
 )
 
+// Node log entries with this prefix name a journald unit (e.g. `journal:kubelet.service`) rather than a file path.
+const nodeLogsJournalPrefix = "journal:"
+
 // NodeLogsCollector defines a NodeLogs Collector struct
 type NodeLogsCollector struct {
-	data        map[string]interfaces.DataValue
-	runtimeInfo *utils.RuntimeInfo
-	fileSystem  interfaces.FileSystemAccessor
+	data          map[string]interfaces.DataValue
+	runtimeInfo   *utils.RuntimeInfo
+	filePaths     *utils.KnownFilePaths
+	fileSystem    interfaces.FileSystemAccessor
+	streamCommand utils.HostCommandStreamer
 }
 
 // NewNodeLogsCollector is a constructor
-func NewNodeLogsCollector(runtimeInfo *utils.RuntimeInfo, fileSystem interfaces.FileSystemAccessor) *NodeLogsCollector {
+func NewNodeLogsCollector(runtimeInfo *utils.RuntimeInfo, filePaths *utils.KnownFilePaths, fileSystem interfaces.FileSystemAccessor, streamCommand utils.HostCommandStreamer) *NodeLogsCollector {
 	return &NodeLogsCollector{
-		data:        make(map[string]interfaces.DataValue),
-		runtimeInfo: runtimeInfo,
-		fileSystem:  fileSystem,
+		data:          make(map[string]interfaces.DataValue),
+		runtimeInfo:   runtimeInfo,
+		filePaths:     filePaths,
+		fileSystem:    fileSystem,
+		streamCommand: streamCommand,
 	}
 }
 
@@ -41,7 +50,15 @@ func (collector *NodeLogsCollector) CheckSupported() error {
 // Collect implements the interface method
 func (collector *NodeLogsCollector) Collect() error {
 	for _, nodeLog := range collector.runtimeInfo.NodeLogs {
-		normalizedNodeLog := strings.Replace(nodeLog, "/", "_", -1)
+		if strings.HasPrefix(nodeLog, nodeLogsJournalPrefix) {
+			if err := collector.collectJournalUnit(strings.TrimPrefix(nodeLog, nodeLogsJournalPrefix)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Path separators are replaced so that each log is a single key (data keys must not contain '\').
+		normalizedNodeLog := strings.NewReplacer("/", "_", `\`, "_").Replace(nodeLog)
 		if normalizedNodeLog[0] == '_' {
 			normalizedNodeLog = normalizedNodeLog[1:]
 		}
@@ -57,6 +74,46 @@ func (collector *NodeLogsCollector) Collect() error {
 	return nil
 }
 
+func (collector *NodeLogsCollector) collectJournalUnit(unit string) error {
+	if len(unit) == 0 {
+		return fmt.Errorf("missing unit name in node log entry '%s'", nodeLogsJournalPrefix)
+	}
+
+	// The journald runtime directory is only defined (and present) on Linux nodes running journald.
+	hasJournald := false
+	if len(collector.filePaths.JournaldRuntime) > 0 {
+		exists, err := collector.fileSystem.FileExists(collector.filePaths.JournaldRuntime)
+		if err != nil {
+			return fmt.Errorf("error checking for journald: %w", err)
+		}
+		hasJournald = exists
+	}
+	if !hasJournald {
+		return fmt.Errorf("cannot collect journal unit %s: journald is not available on this node", unit)
+	}
+
+	args := []string{"-u", unit}
+	if collector.runtimeInfo.NodeLogsJournalSince > 0 {
+		// journalctl takes a time relative to now, in whole seconds.
+		sinceSeconds := int64(math.Ceil(collector.runtimeInfo.NodeLogsJournalSince.Seconds()))
+		args = append(args, "--since", fmt.Sprintf("-%ds", sinceSeconds))
+	}
+	args = append(args, "--no-pager", "-o", "short-iso")
+
+	// Journals can be large, and are held until the end of the run for the final zip, so they are written
+	// straight to disk rather than being read into memory.
+	value, err := utils.NewFileBackedDataValueFromWriter(func(w io.Writer) error {
+		return collector.streamCommand(w, "journalctl", args...)
+	})
+	if err != nil {
+		return fmt.Errorf("error reading journal for unit %s: %w", unit, err)
+	}
+
+	collector.data["journal_"+unit] = value
+
+	return nil
+}
+
 func (collector *NodeLogsCollector) GetData() map[string]interfaces.DataValue {
 	return collector.data
 }
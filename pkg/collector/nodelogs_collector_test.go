@@ -1,7 +1,11 @@
 package collector
 
 import (
+	"errors"
+	"io"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Azure/aks-periscope/pkg/test"
 	"github.com/Azure/aks-periscope/pkg/utils"
@@ -10,7 +14,7 @@ import (
 func TestNodeLogsCollectorGetName(t *testing.T) {
 	const expectedName = "nodelogs"
 
-	c := NewNodeLogsCollector(nil, nil)
+	c := NewNodeLogsCollector(nil, nil, nil, nil)
 	actualName := c.GetName()
 	if actualName != expectedName {
 		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
@@ -39,7 +43,7 @@ func TestNodeLogsCollectorCheckSupported(t *testing.T) {
 		runtimeInfo := &utils.RuntimeInfo{
 			CollectorList: tt.collectorList,
 		}
-		c := NewNodeLogsCollector(runtimeInfo, nil)
+		c := NewNodeLogsCollector(runtimeInfo, nil, nil, nil)
 		err := c.CheckSupported()
 		if (err != nil) != tt.wantErr {
 			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
@@ -56,71 +60,197 @@ func TestNodeLogsCollectorCollect(t *testing.T) {
 		file2Name        = "/var/log/test2.log"
 		file2ExpectedKey = "var_log_test2.log"
 		file2Content     = "Test 2 Content"
+
+		windowsFileName        = `C:\AzureData\CustomDataSetupScript.log`
+		windowsFileExpectedKey = "C:_AzureData_CustomDataSetupScript.log"
+		windowsFileContent     = "Windows Content"
+
+		journaldRuntime    = "/run/systemd/journal"
+		unitExpectedKey    = "journal_kubelet.service"
+		unitJournalContent = "2023-06-15T12:00:00+0000 node-1 kubelet[1234]: I0615 Started kubelet"
 	)
 
 	testLogFiles := map[string]string{
-		file1Name: file1Content,
-		file2Name: file2Content,
+		file1Name:       file1Content,
+		file2Name:       file2Content,
+		windowsFileName: windowsFileContent,
+	}
+
+	streamCommand := func(stdout io.Writer, command string, arg ...string) error {
+		if command == "journalctl" && strings.Join(arg, " ") == "-u kubelet.service --no-pager -o short-iso" {
+			_, err := io.WriteString(stdout, unitJournalContent)
+			return err
+		}
+		return errors.New("fail to run command on host: exit status 1: no entries")
 	}
 
 	tests := []struct {
-		name      string
-		filePaths []string
-		wantData  map[string]string
-		wantErr   bool
+		name     string
+		nodeLogs []string
+		journald bool
+		wantData map[string]string
+		wantErr  bool
 	}{
 		{
-			name:      "missing first log file",
-			filePaths: []string{"/var/log/missing.log", file2Name},
-			wantData:  nil,
-			wantErr:   true,
+			name:     "missing first log file",
+			nodeLogs: []string{"/var/log/missing.log", file2Name},
+			wantData: nil,
+			wantErr:  true,
+		},
+		{
+			name:     "missing second log file",
+			nodeLogs: []string{file1Name, "/var/log/missing.log"},
+			wantData: nil,
+			wantErr:  true,
+		},
+		{
+			name:     "all log files exist",
+			nodeLogs: []string{file1Name, file2Name},
+			wantData: map[string]string{
+				file1ExpectedKey: file1Content,
+				file2ExpectedKey: file2Content,
+			},
+			wantErr: false,
 		},
 		{
-			name:      "missing second log file",
-			filePaths: []string{file1Name, "/var/log/missing.log"},
-			wantData:  nil,
-			wantErr:   true,
+			name:     "windows log file",
+			nodeLogs: []string{windowsFileName},
+			wantData: map[string]string{
+				windowsFileExpectedKey: windowsFileContent,
+			},
+			wantErr: false,
 		},
 		{
-			name:      "all log files exist",
-			filePaths: []string{file1Name, file2Name},
+			name:     "log files and journal units",
+			nodeLogs: []string{file1Name, "journal:kubelet.service", file2Name},
+			journald: true,
 			wantData: map[string]string{
 				file1ExpectedKey: file1Content,
+				unitExpectedKey:  unitJournalContent,
 				file2ExpectedKey: file2Content,
 			},
 			wantErr: false,
 		},
+		{
+			name:     "journal unit without journald",
+			nodeLogs: []string{file1Name, "journal:kubelet.service"},
+			journald: false,
+			wantData: nil,
+			wantErr:  true,
+		},
+		{
+			name:     "journal unit without name",
+			nodeLogs: []string{"journal:"},
+			journald: true,
+			wantData: nil,
+			wantErr:  true,
+		},
+		{
+			name:     "journal unit read failure",
+			nodeLogs: []string{"journal:missing.service"},
+			journald: true,
+			wantData: nil,
+			wantErr:  true,
+		},
 	}
 
-	fs := test.NewFakeFileSystem(testLogFiles)
+	filePaths := &utils.KnownFilePaths{JournaldRuntime: journaldRuntime}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			files := map[string]string{}
+			for path, content := range testLogFiles {
+				files[path] = content
+			}
+			if tt.journald {
+				files[journaldRuntime] = ""
+			}
+			fs := test.NewFakeFileSystem(files)
+
 			runtimeInfo := &utils.RuntimeInfo{
-				NodeLogs:      []string{file1Name, file2Name},
+				NodeLogs:      tt.nodeLogs,
 				CollectorList: []string{},
 			}
-			c := NewNodeLogsCollector(runtimeInfo, fs)
+			c := NewNodeLogsCollector(runtimeInfo, filePaths, fs, streamCommand)
 			err := c.Collect()
+			defer utils.RemoveFileBackedData(c)
 
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Collect() error = %v, wantErr %v", err, tt.wantErr)
+			}
 			if err != nil {
-				if !tt.wantErr {
-					t.Errorf("Collect() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			dataItems := c.GetData()
+			if len(dataItems) != len(tt.wantData) {
+				t.Errorf("expected %d data items, found %d", len(tt.wantData), len(dataItems))
+			}
+			for key, expectedValue := range tt.wantData {
+				result, ok := dataItems[key]
+				if !ok {
+					t.Errorf("missing key %s", key)
+					continue
 				}
-			} else {
-				dataItems := c.GetData()
-				for key, expectedValue := range tt.wantData {
-					result, ok := dataItems[key]
-					if !ok {
-						t.Errorf("missing key %s", key)
+
+				testDataValue(t, result, func(actualValue string) {
+					if actualValue != expectedValue {
+						t.Errorf("unexpected value for key %s.\nExpected '%s'\nFound '%s'", key, expectedValue, actualValue)
 					}
+				})
+			}
+		})
+	}
+}
 
-					testDataValue(t, result, func(actualValue string) {
-						if actualValue != expectedValue {
-							t.Errorf("unexpected value for key %s.\nExpected '%s'\nFound '%s'", key, expectedValue, actualValue)
-						}
-					})
-				}
+func TestNodeLogsCollectorJournalSince(t *testing.T) {
+	const journaldRuntime = "/run/systemd/journal"
+
+	tests := []struct {
+		name         string
+		journalSince time.Duration
+		wantArgs     string
+	}{
+		{
+			name:         "no limit",
+			journalSince: 0,
+			wantArgs:     "-u kubelet.service --no-pager -o short-iso",
+		},
+		{
+			name:         "limited to 90 minutes",
+			journalSince: 90 * time.Minute,
+			wantArgs:     "-u kubelet.service --since -5400s --no-pager -o short-iso",
+		},
+		{
+			name:         "partial seconds rounded up",
+			journalSince: 1500 * time.Millisecond,
+			wantArgs:     "-u kubelet.service --since -2s --no-pager -o short-iso",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actualArgs string
+			streamCommand := func(stdout io.Writer, command string, arg ...string) error {
+				actualArgs = strings.Join(arg, " ")
+				return nil
+			}
+
+			runtimeInfo := &utils.RuntimeInfo{
+				NodeLogs:             []string{"journal:kubelet.service"},
+				NodeLogsJournalSince: tt.journalSince,
+			}
+			filePaths := &utils.KnownFilePaths{JournaldRuntime: journaldRuntime}
+			fs := test.NewFakeFileSystem(map[string]string{journaldRuntime: ""})
+
+			c := NewNodeLogsCollector(runtimeInfo, filePaths, fs, streamCommand)
+			if err := c.Collect(); err != nil {
+				t.Fatalf("Collect() error = %v", err)
+			}
+			defer utils.RemoveFileBackedData(c)
+
+			if actualArgs != tt.wantArgs {
+				t.Errorf("unexpected journalctl arguments: expected %q, found %q", tt.wantArgs, actualArgs)
 			}
 		})
 	}
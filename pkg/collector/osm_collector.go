@@ -25,22 +25,39 @@ import (
 	"k8s.io/client-go/transport/spdy"
 )
 
+const osmMeshConfigCRDName = "meshconfigs.config.openservicemesh.io"
+
+// The port of the Envoy admin interface in OSM sidecars, which only listens on localhost.
+const osmEnvoyAdminPort = 15000
+
+// The number of lines collected from the end of each OSM controller container's logs.
+const osmControllerLogLines = int64(1000)
+
+// Pods with an Envoy sidecar injected by OSM are labelled with the proxy's identity.
+const osmProxyUUIDLabel = "osm-proxy-uuid"
+
 // OsmCollector defines an OSM Collector struct
 type OsmCollector struct {
 	data          map[string]string
 	kubeconfig    *rest.Config
 	commandRunner *utils.KubeCommandRunner
 	runtimeInfo   *utils.RuntimeInfo
+	isInstalled   func() error
 }
 
 // NewOsmCollector is a constructor
 func NewOsmCollector(config *rest.Config, runtimeInfo *utils.RuntimeInfo) *OsmCollector {
-	return &OsmCollector{
+	collector := &OsmCollector{
 		data:          make(map[string]string),
 		kubeconfig:    config,
 		commandRunner: utils.NewKubeCommandRunner(config),
 		runtimeInfo:   runtimeInfo,
 	}
+	collector.isInstalled = func() error {
+		_, err := collector.commandRunner.GetGVRForCRD(osmMeshConfigCRDName)
+		return err
+	}
+	return collector
 }
 
 func (collector *OsmCollector) GetName() string {
@@ -48,8 +65,13 @@ func (collector *OsmCollector) GetName() string {
 }
 
 func (collector *OsmCollector) CheckSupported() error {
-	if !utils.Contains(collector.runtimeInfo.CollectorList, "OSM") {
-		return fmt.Errorf("not included because 'OSM' not in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	if utils.Contains(collector.runtimeInfo.CollectorList, "OSM") {
+		return nil
+	}
+
+	// Otherwise the control plane and the proxies on this node are collected wherever OSM is installed.
+	if err := collector.isInstalled(); err != nil {
+		return fmt.Errorf("not included because OSM is not installed (%v) and 'OSM' not in COLLECTOR_LIST variable. Included values: %s", err, strings.Join(collector.runtimeInfo.CollectorList, " "))
 	}
 
 	return nil
@@ -70,6 +92,20 @@ func (collector *OsmCollector) Collect() error {
 		return fmt.Errorf("error listing deployments in all namespaces: %w", err)
 	}
 
+	if len(meshDeploymentList.Items) == 0 {
+		log.Printf("No OSM controller deployments found")
+		return nil
+	}
+
+	collector.collectControllerLogs(clientset)
+	collector.collectMeshConfigs()
+	collector.collectNodeEnvoyConfigs(clientset)
+
+	// The full contents of every mesh are only collected on request, since they are large and the same on every node.
+	if !utils.Contains(collector.runtimeInfo.CollectorList, "OSM") {
+		return nil
+	}
+
 	for _, deployment := range meshDeploymentList.Items {
 		meshName, found := deployment.Labels["meshName"]
 		if !found {
@@ -100,6 +136,91 @@ func (collector *OsmCollector) Collect() error {
 	return nil
 }
 
+// collectControllerLogs collects the recent logs of the OSM controllers of all meshes.
+func (collector *OsmCollector) collectControllerLogs(clientset *kubernetes.Clientset) {
+	ctx := context.Background()
+	pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{LabelSelector: "app=osm-controller"})
+	if err != nil {
+		collector.data["osm-controller-log"] = fmt.Sprintf("Failed to list OSM controller pods: %+v\n", err)
+		log.Print(collector.data["osm-controller-log"])
+		return
+	}
+
+	var output strings.Builder
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		for _, status := range pod.Status.ContainerStatuses {
+			writePodContainerLogs(ctx, clientset, &output, pod, status.Name, osmControllerLogLines, false)
+			if status.RestartCount > 0 {
+				writePodContainerLogs(ctx, clientset, &output, pod, status.Name, osmControllerLogLines, true)
+			}
+		}
+	}
+
+	collector.data["osm-controller-log"] = output.String()
+}
+
+// collectMeshConfigs collects the MeshConfig of each mesh, which holds the mesh-wide settings.
+func (collector *OsmCollector) collectMeshConfigs() {
+	gvr, err := collector.commandRunner.GetGVRForCRD(osmMeshConfigCRDName)
+	if err != nil {
+		collector.data["osm-meshconfig"] = fmt.Sprintf("Failed to read MeshConfig CRD: %+v\n", err)
+		log.Print(collector.data["osm-meshconfig"])
+		return
+	}
+
+	value, err := collector.commandRunner.GetJsonListOutput(gvr, "", &metav1.ListOptions{})
+	if err != nil {
+		value = fmt.Sprintf("Failed to collect MeshConfigs: %+v\n", err)
+		log.Print(value)
+	}
+	collector.data["osm-meshconfig"] = value
+}
+
+// collectNodeEnvoyConfigs collects the config dump and stats of the Envoy sidecars of pods on this node.
+// Each instance of Periscope collects those on its own node, so that the proxies are spread across nodes.
+func (collector *OsmCollector) collectNodeEnvoyConfigs(clientset *kubernetes.Clientset) {
+	pods, err := clientset.CoreV1().Pods("").List(context.Background(), metav1.ListOptions{
+		FieldSelector: "spec.nodeName=" + collector.runtimeInfo.HostNodeName,
+	})
+	if err != nil {
+		log.Printf("Failed to list pods on node %s: %+v", collector.runtimeInfo.HostNodeName, err)
+		return
+	}
+
+	for _, pod := range getOsmProxyPods(pods.Items, collector.runtimeInfo.HostNodeName) {
+		err := collector.withEnvoyAdminPortForward(pod.Namespace, pod.Name, func(localPort int) {
+			for _, query := range []string{"config_dump", "stats"} {
+				key := utils.JoinDataKey("envoy-config", pod.Namespace, pod.Name, query)
+				value, err := queryEnvoyAdmin(localPort, query)
+				if err != nil {
+					value = fmt.Sprintf("Failed to collect Envoy %s for pod %s in namespace %s: %+v\n", query, pod.Name, pod.Namespace, err)
+					log.Print(value)
+				}
+				collector.data[key] = value
+			}
+		})
+		if err != nil {
+			log.Printf("Failed to port-forward to Envoy admin for pod %s in namespace %s: %+v", pod.Name, pod.Namespace, err)
+		}
+	}
+}
+
+// getOsmProxyPods returns the running pods on the node which have an OSM Envoy sidecar.
+func getOsmProxyPods(pods []corev1.Pod, nodeName string) []corev1.Pod {
+	result := []corev1.Pod{}
+	for _, pod := range pods {
+		if pod.Spec.NodeName != nodeName || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		if _, ok := pod.Labels[osmProxyUUIDLabel]; ok {
+			result = append(result, pod)
+		}
+	}
+
+	return result
+}
+
 // callNamespaceCollectors calls functions to collect data for osm-controller namespace and namespaces monitored by a given mesh
 func (collector *OsmCollector) callNamespaceCollectors(clientset *kubernetes.Clientset, monitoredNamespaces []string, controllerNamespace string, meshName string) {
 	for _, namespace := range monitoredNamespaces {
@@ -200,11 +321,18 @@ func (collector *OsmCollector) collectDataFromEnvoys(clientset *kubernetes.Clien
 }
 
 func (collector *OsmCollector) portForwardAndRunEnvoyQueries(meshName, namespace, podName string) error {
+	return collector.withEnvoyAdminPortForward(namespace, podName, func(localPort int) {
+		collector.runEnvoyQueries(meshName, namespace, podName, localPort)
+	})
+}
+
+// withEnvoyAdminPortForward forwards a local port to the Envoy admin port of the pod while running the queries.
+func (collector *OsmCollector) withEnvoyAdminPortForward(namespace, podName string, runQueries func(localPort int)) error {
 	var buffOut, buffErr bytes.Buffer
 	readyChan := make(chan struct{})
 	stopChan := make(chan struct{}, 1)
 	errorChan := make(chan error)
-	const localPort = 15000
+	const localPort = osmEnvoyAdminPort
 
 	defer close(stopChan)
 
@@ -213,7 +341,7 @@ func (collector *OsmCollector) portForwardAndRunEnvoyQueries(meshName, namespace
 			namespace: namespace,
 			podName:   podName,
 			localPort: localPort,
-			podPort:   15000,
+			podPort:   osmEnvoyAdminPort,
 			outStream: bufio.NewWriter(&buffOut),
 			errStream: bufio.NewWriter(&buffErr),
 			readyChan: readyChan,
@@ -228,7 +356,7 @@ func (collector *OsmCollector) portForwardAndRunEnvoyQueries(meshName, namespace
 	case err := <-errorChan:
 		return err
 	case <-readyChan:
-		collector.runEnvoyQueries(meshName, namespace, podName, localPort)
+		runQueries(localPort)
 	}
 
 	return nil
@@ -237,21 +365,31 @@ func (collector *OsmCollector) portForwardAndRunEnvoyQueries(meshName, namespace
 func (collector *OsmCollector) runEnvoyQueries(meshName, namespace, podName string, localPort int) {
 	envoyQueries := [5]string{"config_dump", "clusters", "listeners", "ready", "stats"}
 	for _, query := range envoyQueries {
-		queryUrl := fmt.Sprintf("http://localhost:%d/%s", localPort, query)
-		responseBody, err := utils.GetUrlWithRetries(queryUrl, 5)
+		secretRemovedResponse, err := queryEnvoyAdmin(localPort, query)
 		if err != nil {
 			log.Printf("Failed to collect Envoy %s for pod %s in OSM monitored namespace %s: %+v", query, podName, namespace, err)
 			continue
 		}
-		// Remove certificate secrets from Envoy config i.e., "inline_bytes" field from response
-		re := regexp.MustCompile("(?m)[\r\n]+^.*inline_bytes.*$")
-		secretRemovedResponse := re.ReplaceAllString(string(responseBody), "---redacted---")
 
 		key := fmt.Sprintf("%s/envoy/%s%s", meshName, podName, query)
 		collector.data[key] = secretRemovedResponse
 	}
 }
 
+// Matches the lines of Envoy config holding certificate secrets, i.e. the "inline_bytes" fields.
+var envoyInlineBytesRegex = regexp.MustCompile("(?m)[\r\n]+^.*inline_bytes.*$")
+
+// queryEnvoyAdmin gets the response of an Envoy admin endpoint, with certificate secrets removed.
+func queryEnvoyAdmin(localPort int, query string) (string, error) {
+	queryUrl := fmt.Sprintf("http://localhost:%d/%s", localPort, query)
+	responseBody, err := utils.GetUrlWithRetries(queryUrl, 5)
+	if err != nil {
+		return "", err
+	}
+
+	return envoyInlineBytesRegex.ReplaceAllString(string(responseBody), "---redacted---"), nil
+}
+
 type portForwardParams struct {
 	namespace string
 	podName   string
@@ -2,6 +2,7 @@ package collector
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/Azure/aks-periscope/pkg/test"
 	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
@@ -27,16 +29,25 @@ func TestOsmCollectorCheckSupported(t *testing.T) {
 	tests := []struct {
 		name       string
 		collectors []string
+		installErr error
 		wantErr    bool
 	}{
 		{
-			name:       "OSM not included",
+			name:       "OSM not included or installed",
 			collectors: []string{"NOT_OSM"},
+			installErr: errors.New("crd meshconfigs.config.openservicemesh.io not found"),
 			wantErr:    true,
 		},
+		{
+			name:       "OSM installed",
+			collectors: []string{"NOT_OSM"},
+			installErr: nil,
+			wantErr:    false,
+		},
 		{
 			name:       "OSM included",
 			collectors: []string{"OSM"},
+			installErr: errors.New("crd meshconfigs.config.openservicemesh.io not found"),
 			wantErr:    false,
 		},
 	}
@@ -46,6 +57,7 @@ func TestOsmCollectorCheckSupported(t *testing.T) {
 			CollectorList: tt.collectors,
 		}
 		c := NewOsmCollector(nil, runtimeInfo)
+		c.isInstalled = func() error { return tt.installErr }
 		err := c.CheckSupported()
 		if (err != nil) != tt.wantErr {
 			t.Errorf("CheckSupported() for %s error = %v, wantErr %v", tt.name, err, tt.wantErr)
@@ -53,6 +65,32 @@ func TestOsmCollectorCheckSupported(t *testing.T) {
 	}
 }
 
+func TestGetOsmProxyPods(t *testing.T) {
+	newPod := func(name, nodeName string, phase corev1.PodPhase, labels map[string]string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "bookstore", Name: name, Labels: labels},
+			Spec:       corev1.PodSpec{NodeName: nodeName},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+	proxyLabels := map[string]string{"app": "bookstore", osmProxyUUIDLabel: "0fd5ad5e-6bd6-4b3a-8f6e-1a0f3f3b1b7c"}
+
+	pods := []corev1.Pod{
+		newPod("injected", "node-1", corev1.PodRunning, proxyLabels),
+		newPod("not-injected", "node-1", corev1.PodRunning, map[string]string{"app": "bookstore"}),
+		newPod("other-node", "node-2", corev1.PodRunning, proxyLabels),
+		newPod("pending", "node-1", corev1.PodPending, proxyLabels),
+	}
+
+	names := []string{}
+	for _, pod := range getOsmProxyPods(pods, "node-1") {
+		names = append(names, pod.Name)
+	}
+	if !equalStringSlices(names, []string{"injected"}) {
+		t.Errorf("unexpected proxy pods %v", names)
+	}
+}
+
 func setupOsmTest(t *testing.T) *test.ClusterFixture {
 	fixture, _ := test.GetClusterFixture()
 
@@ -143,6 +181,10 @@ func getExpectedOsmData(clientset *kubernetes.Clientset, knownNamespaces *test.K
 	}
 
 	result := map[string]*regexp.Regexp{
+		// osm-controller-log will have a header for the controller container, followed by its logs
+		"osm-controller-log": regexp.MustCompile(`^==> osm-controller-\S+/osm-controller <==\n`),
+		// osm-meshconfig will be a json list of MeshConfig items
+		"osm-meshconfig": regexp.MustCompile(`^{\n    "apiVersion": "v1",\n\s+"items": \[\n\s+{\n\s+"apiVersion": "config\.openservicemesh\.io/\w+",\n\s+"kind": "MeshConfig",\n`),
 		// all_resources_list will be listed in tabular format
 		fmt.Sprintf("%s/control_plane/all_resources_list", meshName): regexp.MustCompile(`^(NAMESPACE\s+NAME\s.*\n(.*\n)*){4}`),
 		// all_resources_configs will be a json list of items
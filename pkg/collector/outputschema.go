@@ -0,0 +1,55 @@
+package collector
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+// outputSchemas declare the shape of collector output which is read by downstream analyzers, named by data key.
+//
+//go:embed schemas/*.json
+var outputSchemas embed.FS
+
+// getOutputSchema returns the schema declared for a data key, or nil if there is none.
+func getOutputSchema(key string) (*utils.JSONSchema, error) {
+	content, err := outputSchemas.ReadFile("schemas/" + key + ".json")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read schema for %s: %w", key, err)
+	}
+
+	return utils.ParseJSONSchema(content)
+}
+
+// validateOutput checks JSON output against the schema declared for its data key, as configured in
+// DIAGNOSTIC_OUTPUT_SCHEMA_VALIDATION. A mismatch is only returned as an error if validation is set to fail, and is
+// otherwise logged.
+func validateOutput(runtimeInfo *utils.RuntimeInfo, collectorName, key string, output []byte) error {
+	if runtimeInfo == nil || runtimeInfo.OutputSchemaValidation == "" || runtimeInfo.OutputSchemaValidation == utils.OutputSchemaValidationOff {
+		return nil
+	}
+
+	schema, err := getOutputSchema(key)
+	if err != nil {
+		return err
+	}
+	if schema == nil {
+		return nil
+	}
+
+	if err := schema.Validate(output); err != nil {
+		if runtimeInfo.OutputSchemaValidation == utils.OutputSchemaValidationFail {
+			return fmt.Errorf("output %s does not match its schema: %w", key, err)
+		}
+		log.Printf("Collector: %s, output %s does not match its schema: %v", collectorName, key, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package collector
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+func TestOutputSchemasParse(t *testing.T) {
+	names, err := fs.Glob(outputSchemas, "schemas/*.json")
+	if err != nil || len(names) == 0 {
+		t.Fatalf("expected embedded schemas, found %v (error %v)", names, err)
+	}
+
+	for _, name := range names {
+		key := strings.TrimSuffix(strings.TrimPrefix(name, "schemas/"), ".json")
+		if schema, err := getOutputSchema(key); err != nil || schema == nil {
+			t.Errorf("schema for %s: %v", key, err)
+		}
+	}
+}
+
+func TestValidateOutput(t *testing.T) {
+	const conforming = `[{"namespace":"app","kind":"Deployment","name":"web","source":"webhook","admitter":"policy.example.com","message":"denied","count":2,"lastTimestamp":"2024-01-01T00:00:00Z"}]`
+	const nonConforming = `[{"namespace":"app","kind":"Deployment","source":"LimitRange","admitter":"policy.example.com","message":"denied","count":"2","lastTimestamp":"2024-01-01T00:00:00Z"}]`
+
+	tests := []struct {
+		name       string
+		validation string
+		key        string
+		output     string
+		wantErr    bool
+	}{
+		{
+			name:       "conforming output",
+			validation: utils.OutputSchemaValidationFail,
+			key:        "admission-rejections",
+			output:     conforming,
+			wantErr:    false,
+		},
+		{
+			name:       "non-conforming output failing collector",
+			validation: utils.OutputSchemaValidationFail,
+			key:        "admission-rejections",
+			output:     nonConforming,
+			wantErr:    true,
+		},
+		{
+			name:       "non-conforming output only logged",
+			validation: utils.OutputSchemaValidationWarn,
+			key:        "admission-rejections",
+			output:     nonConforming,
+			wantErr:    false,
+		},
+		{
+			name:       "validation off",
+			validation: utils.OutputSchemaValidationOff,
+			key:        "admission-rejections",
+			output:     `{}`,
+			wantErr:    false,
+		},
+		{
+			name:       "no declared schema",
+			validation: utils.OutputSchemaValidationFail,
+			key:        "undeclared",
+			output:     `{}`,
+			wantErr:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runtimeInfo := &utils.RuntimeInfo{OutputSchemaValidation: tt.validation}
+			err := validateOutput(runtimeInfo, "admissionrejections", tt.key, []byte(tt.output))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateOutput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Every mismatch is reported together.
+	err := validateOutput(&utils.RuntimeInfo{OutputSchemaValidation: utils.OutputSchemaValidationFail}, "admissionrejections", "admission-rejections", []byte(nonConforming))
+	for _, want := range []string{"missing required property 'name'", "$[0].count: expected integer", "$[0].source"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to contain %q, found %v", want, err)
+		}
+	}
+}
@@ -10,7 +10,6 @@ import (
 	"github.com/Azure/aks-periscope/pkg/utils"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
-	restclient "k8s.io/client-go/rest"
 )
 
 type PDBInfo struct {
@@ -23,15 +22,15 @@ type PDBInfo struct {
 // PDBCollector defines a Pod disruption Budget Collector struct
 type PDBCollector struct {
 	data        map[string]string
-	kubeconfig  *restclient.Config
+	clientset   kubernetes.Interface
 	runtimeInfo *utils.RuntimeInfo
 }
 
 // NewPDBCollector is a constructor
-func NewPDBCollector(config *restclient.Config, runtimeInfo *utils.RuntimeInfo) *PDBCollector {
+func NewPDBCollector(clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *PDBCollector {
 	return &PDBCollector{
 		data:        make(map[string]string),
-		kubeconfig:  config,
+		clientset:   clientset,
 		runtimeInfo: runtimeInfo,
 	}
 }
@@ -50,21 +49,15 @@ func (collector *PDBCollector) CheckSupported() error {
 
 // Collect implements the interface method
 func (collector *PDBCollector) Collect() error {
-	// Creates the clientset
-	clientset, err := kubernetes.NewForConfig(collector.kubeconfig)
-	if err != nil {
-		return fmt.Errorf("getting access to K8S failed: %w", err)
-	}
-
 	ctxBackground := context.Background()
 
-	namespacesList, err := clientset.CoreV1().Namespaces().List(ctxBackground, metav1.ListOptions{})
+	namespacesList, err := collector.clientset.CoreV1().Namespaces().List(ctxBackground, metav1.ListOptions{})
 	if err != nil {
 		return fmt.Errorf("unable to list namespaces in the cluster: %w", err)
 	}
 
 	for _, namespace := range namespacesList.Items {
-		podDistInterface, err := clientset.PolicyV1().PodDisruptionBudgets(namespace.Name).List(ctxBackground, metav1.ListOptions{})
+		podDistInterface, err := collector.clientset.PolicyV1().PodDisruptionBudgets(namespace.Name).List(ctxBackground, metav1.ListOptions{})
 		if err != nil {
 			return fmt.Errorf("listing PDB error: %w", err)
 		}
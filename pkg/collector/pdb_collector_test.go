@@ -1,10 +1,17 @@
 package collector
 
 import (
+	"encoding/json"
+	"reflect"
 	"testing"
 
-	"github.com/Azure/aks-periscope/pkg/test"
 	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func TestPDBCollectorGetName(t *testing.T) {
@@ -48,37 +55,80 @@ func TestPDBCollectorCheckSupported(t *testing.T) {
 }
 
 func TestPDBCollectorCollect(t *testing.T) {
+	minAvailable := intstr.FromInt(1)
+	maxUnavailable := intstr.FromString("25%")
+
+	newNamespace := func(name string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+
 	tests := []struct {
 		name    string
-		want    int
-		wantErr bool
+		objects []runtime.Object
+		want    map[string][]PDBInfo
 	}{
 		{
-			name:    "get pdb information for logs",
-			want:    1,
-			wantErr: false,
+			name:    "no namespaces",
+			objects: []runtime.Object{},
+			want:    map[string][]PDBInfo{},
+		},
+		{
+			name: "namespaces with and without PDBs",
+			objects: []runtime.Object{
+				newNamespace("default"),
+				newNamespace("app"),
+				&policyv1.PodDisruptionBudget{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app"},
+					Spec:       policyv1.PodDisruptionBudgetSpec{MinAvailable: &minAvailable},
+					Status:     policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: 2},
+				},
+				&policyv1.PodDisruptionBudget{
+					ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "app"},
+					Spec:       policyv1.PodDisruptionBudgetSpec{MaxUnavailable: &maxUnavailable},
+				},
+			},
+			want: map[string][]PDBInfo{
+				"pdb-default": {},
+				"pdb-app": {
+					{Name: "api", MinAvailable: "<nil>", MaxUnavailable: "25%", DisruptionsAllowed: 0},
+					{Name: "web", MinAvailable: "1", MaxUnavailable: "<nil>", DisruptionsAllowed: 2},
+				},
+			},
 		},
 	}
 
-	fixture, _ := test.GetClusterFixture()
-
 	runtimeInfo := &utils.RuntimeInfo{
 		CollectorList: []string{},
 	}
 
-	c := NewPDBCollector(fixture.PeriscopeAccess.ClientConfig, runtimeInfo)
-
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := c.Collect()
+			c := NewPDBCollector(fake.NewSimpleClientset(tt.objects...), runtimeInfo)
+			if err := c.Collect(); err != nil {
+				t.Fatalf("Collect() error = %v", err)
+			}
 
-			if (err != nil) != tt.wantErr {
-				t.Errorf("Collect() error = %v, wantErr %v", err, tt.wantErr)
+			data := c.GetData()
+			if len(data) != len(tt.want) {
+				t.Errorf("expected %d keys, found %d", len(tt.want), len(data))
 			}
-			raw := c.GetData()
 
-			if len(raw) < tt.want {
-				t.Errorf("len(GetData()) = %v, want %v", len(raw), tt.want)
+			for key, want := range tt.want {
+				value, ok := data[key]
+				if !ok {
+					t.Errorf("missing key %s", key)
+					continue
+				}
+
+				testDataValue(t, value, func(raw string) {
+					var pdbs []PDBInfo
+					if err := json.Unmarshal([]byte(raw), &pdbs); err != nil {
+						t.Fatalf("unmarshal %s: %v", key, err)
+					}
+					if !reflect.DeepEqual(pdbs, want) {
+						t.Errorf("unexpected PDBs for %s:\nexpected %+v\nfound    %+v", key, want, pdbs)
+					}
+				})
 			}
 		})
 	}
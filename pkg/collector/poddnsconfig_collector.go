@@ -0,0 +1,327 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// The ndots which the kubelet sets for pods using the cluster DNS, and the resolver's default otherwise.
+const (
+	clusterDNSNdots = 5
+	resolverNdots   = 1
+)
+
+// Search lists longer than this were truncated by older versions of glibc, and are rejected by some resolvers.
+const (
+	podDNSMaxSearches    = 6
+	podDNSMaxSearchChars = 256
+)
+
+// The number of dots in a typical external name, such as "www.example.com".
+const podDNSExternalNameDots = 2
+
+type PodDNSConfigReport struct {
+	Pods []PodDNSConfig `json:"pods"`
+}
+
+// PodDNSConfig is the resolv.conf of a pod, as its containers see it, and the DNS settings of its spec which determine
+// it. The resolver tries each search domain in turn for names with fewer dots than ndots before the name itself, so
+// ExternalNameQueries is the number of queries (of each record type) made to resolve a name such as "www.example.com".
+type PodDNSConfig struct {
+	Namespace           string               `json:"namespace"`
+	Pod                 string               `json:"pod"`
+	DNSPolicy           corev1.DNSPolicy     `json:"dnsPolicy"`
+	HostNetwork         bool                 `json:"hostNetwork"`
+	DNSConfig           *corev1.PodDNSConfig `json:"dnsConfig,omitempty"`
+	Nameservers         []string             `json:"nameservers"`
+	Searches            []string             `json:"searches"`
+	Ndots               int                  `json:"ndots"`
+	Options             []string             `json:"options"`
+	ExternalNameQueries int                  `json:"externalNameQueries"`
+	Warnings            []string             `json:"warnings"`
+	Error               string               `json:"error,omitempty"`
+}
+
+// resolvConf holds the settings of a resolv.conf file.
+type resolvConf struct {
+	nameservers []string
+	searches    []string
+	ndots       int
+	options     []string
+}
+
+// PodDNSConfigCollector defines a Pod DNS Config Collector struct
+type PodDNSConfigCollector struct {
+	data         map[string]string
+	osIdentifier utils.OSIdentifier
+	filePaths    *utils.KnownFilePaths
+	fileSystem   interfaces.FileSystemAccessor
+	clientset    kubernetes.Interface
+	runtimeInfo  *utils.RuntimeInfo
+}
+
+// NewPodDNSConfigCollector is a constructor
+func NewPodDNSConfigCollector(osIdentifier utils.OSIdentifier, filePaths *utils.KnownFilePaths, fileSystem interfaces.FileSystemAccessor, clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *PodDNSConfigCollector {
+	return &PodDNSConfigCollector{
+		data:         make(map[string]string),
+		osIdentifier: osIdentifier,
+		filePaths:    filePaths,
+		fileSystem:   fileSystem,
+		clientset:    clientset,
+		runtimeInfo:  runtimeInfo,
+	}
+}
+
+func (collector *PodDNSConfigCollector) GetName() string {
+	return "poddnsconfig"
+}
+
+func (collector *PodDNSConfigCollector) CheckSupported() error {
+	// This reads resolv.conf through the root of the pods' processes in /proc, which is only supported on Linux.
+	if collector.osIdentifier != utils.Linux {
+		return fmt.Errorf("unsupported OS: %s", collector.osIdentifier)
+	}
+
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *PodDNSConfigCollector) Collect() error {
+	var pods *corev1.PodList
+	err := utils.RetryAPICall(func() (err error) {
+		pods, err = collector.clientset.CoreV1().Pods(metav1.NamespaceAll).List(context.Background(), metav1.ListOptions{
+			FieldSelector: "spec.nodeName=" + collector.runtimeInfo.HostNodeName,
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list pods on node %s: %w", collector.runtimeInfo.HostNodeName, err)
+	}
+
+	// Every container of a pod has the same resolv.conf, so it is read from the first process found in any of them.
+	podIndexes := map[string]int{}
+	report := PodDNSConfigReport{Pods: []PodDNSConfig{}}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName != collector.runtimeInfo.HostNodeName || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+
+		podIndexes[string(pod.UID)] = len(report.Pods)
+		report.Pods = append(report.Pods, PodDNSConfig{
+			Namespace:   pod.Namespace,
+			Pod:         pod.Name,
+			DNSPolicy:   pod.Spec.DNSPolicy,
+			HostNetwork: pod.Spec.HostNetwork,
+			DNSConfig:   pod.Spec.DNSConfig,
+			Nameservers: []string{},
+			Searches:    []string{},
+			Options:     []string{},
+			Warnings:    []string{},
+		})
+	}
+
+	resolvConfs, err := collector.readPodResolvConfs(podIndexes)
+	if err != nil {
+		return err
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		index, ok := podIndexes[string(pod.UID)]
+		if !ok {
+			continue
+		}
+
+		config := &report.Pods[index]
+		conf, ok := resolvConfs[string(pod.UID)]
+		if !ok {
+			config.Error = "no running process found to read resolv.conf from"
+			continue
+		}
+
+		config.Nameservers = conf.nameservers
+		config.Searches = conf.searches
+		config.Ndots = conf.ndots
+		config.Options = conf.options
+		config.Warnings = getPodDNSWarnings(pod, conf)
+
+		// Names with at least ndots dots are tried as they are, and others with each search domain first.
+		config.ExternalNameQueries = 1
+		if podDNSExternalNameDots < conf.ndots {
+			config.ExternalNameQueries += len(conf.searches)
+		}
+	}
+
+	sort.Slice(report.Pods, func(i, j int) bool {
+		a, b := report.Pods[i], report.Pods[j]
+		return a.Namespace < b.Namespace || (a.Namespace == b.Namespace && a.Pod < b.Pod)
+	})
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("marshall pod dns config to json: %w", err)
+	}
+
+	collector.data["pod-dns-config"] = string(data)
+
+	return nil
+}
+
+// readPodResolvConfs reads the resolv.conf of each of the given pods (by UID), through the root directory of one of
+// their processes. The Periscope pod shares the host PID namespace, so all the node's processes are visible in /proc.
+func (collector *PodDNSConfigCollector) readPodResolvConfs(podUIDs map[string]int) (map[string]*resolvConf, error) {
+	entries, err := collector.fileSystem.ListDirectory(collector.filePaths.Proc)
+	if err != nil {
+		return nil, fmt.Errorf("error listing processes: %w", err)
+	}
+
+	result := map[string]*resolvConf{}
+	for _, entry := range entries {
+		if len(result) == len(podUIDs) {
+			break
+		}
+		if _, err := strconv.Atoi(entry); err != nil {
+			continue
+		}
+
+		// Processes may exit while the others are read, so any which can't be read are skipped.
+		processPath := path.Join(collector.filePaths.Proc, entry)
+		cgroup, err := collector.readFile(path.Join(processPath, "cgroup"))
+		if err != nil {
+			continue
+		}
+
+		podUID, containerID := parseProcessCgroup(cgroup)
+		if _, ok := podUIDs[podUID]; !ok || len(containerID) == 0 || result[podUID] != nil {
+			continue
+		}
+
+		content, err := collector.readFile(path.Join(processPath, "root", "etc", "resolv.conf"))
+		if err != nil {
+			continue
+		}
+		result[podUID] = parseResolvConf(content)
+	}
+
+	return result, nil
+}
+
+// parseResolvConf parses the nameserver, search (or domain) and options lines of a resolv.conf file, as the resolver
+// does: the last search or domain line applies, and ndots is 1 unless set.
+func parseResolvConf(content string) *resolvConf {
+	conf := &resolvConf{
+		nameservers: []string{},
+		searches:    []string{},
+		ndots:       resolverNdots,
+		options:     []string{},
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], ";") {
+			continue
+		}
+
+		switch fields[0] {
+		case "nameserver":
+			if len(fields) > 1 {
+				conf.nameservers = append(conf.nameservers, fields[1])
+			}
+		case "search", "domain":
+			conf.searches = append([]string{}, fields[1:]...)
+		case "options":
+			for _, option := range fields[1:] {
+				conf.options = append(conf.options, option)
+				if value := strings.TrimPrefix(option, "ndots:"); value != option {
+					if ndots, err := strconv.Atoi(value); err == nil {
+						conf.ndots = ndots
+					}
+				}
+			}
+		}
+	}
+
+	return conf
+}
+
+// getPodDNSWarnings compares the resolv.conf of a pod with the one the kubelet generates for its DNS policy and config,
+// and flags settings which cause excess lookups.
+func getPodDNSWarnings(pod *corev1.Pod, conf *resolvConf) []string {
+	warnings := []string{}
+
+	// Pods on the host network only use the cluster DNS with ClusterFirstWithHostNet, and otherwise use the node's.
+	policy := pod.Spec.DNSPolicy
+	if len(policy) == 0 {
+		policy = corev1.DNSClusterFirst
+	}
+	clusterDNS := policy == corev1.DNSClusterFirstWithHostNet || (policy == corev1.DNSClusterFirst && !pod.Spec.HostNetwork)
+	if policy == corev1.DNSClusterFirst && pod.Spec.HostNetwork {
+		warnings = append(warnings, "dnsPolicy ClusterFirst on the host network uses the node's DNS settings: use ClusterFirstWithHostNet to resolve cluster services")
+	}
+
+	defaultNdots := resolverNdots
+	if clusterDNS {
+		defaultNdots = clusterDNSNdots
+		if len(conf.searches) == 0 || !strings.HasPrefix(conf.searches[0], pod.Namespace+".svc.") {
+			warnings = append(warnings, fmt.Sprintf("search list doesn't start with %s.svc.<cluster domain> as expected for dnsPolicy %s", pod.Namespace, policy))
+		}
+	}
+
+	if config := pod.Spec.DNSConfig; config != nil {
+		for _, nameserver := range config.Nameservers {
+			if !utils.Contains(conf.nameservers, nameserver) {
+				warnings = append(warnings, fmt.Sprintf("nameserver %s of dnsConfig is missing", nameserver))
+			}
+		}
+		for _, search := range config.Searches {
+			if !utils.Contains(conf.searches, search) {
+				warnings = append(warnings, fmt.Sprintf("search domain %s of dnsConfig is missing", search))
+			}
+		}
+		for _, option := range config.Options {
+			if option.Name != "ndots" || option.Value == nil {
+				continue
+			}
+			if ndots, err := strconv.Atoi(*option.Value); err == nil && ndots != conf.ndots {
+				warnings = append(warnings, fmt.Sprintf("ndots is %d, but dnsConfig sets %d", conf.ndots, ndots))
+			}
+		}
+	}
+
+	if conf.ndots != defaultNdots {
+		warnings = append(warnings, fmt.Sprintf("ndots is %d rather than the default of %d for dnsPolicy %s", conf.ndots, defaultNdots, policy))
+	}
+
+	if length := len(strings.Join(conf.searches, " ")); len(conf.searches) > podDNSMaxSearches || length > podDNSMaxSearchChars {
+		warnings = append(warnings, fmt.Sprintf("search list has %d domains (%d characters), more than some resolvers support (%d domains, %d characters), and each adds a lookup for names with fewer than %d dots", len(conf.searches), length, podDNSMaxSearches, podDNSMaxSearchChars, conf.ndots))
+	}
+
+	return warnings
+}
+
+func (collector *PodDNSConfigCollector) readFile(filePath string) (string, error) {
+	return utils.GetContent(func() (io.ReadCloser, error) {
+		return collector.fileSystem.GetFileReader(filePath)
+	})
+}
+
+func (collector *PodDNSConfigCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
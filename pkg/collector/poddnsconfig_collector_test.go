@@ -0,0 +1,175 @@
+package collector
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPodDNSConfigCollectorGetName(t *testing.T) {
+	const expectedName = "poddnsconfig"
+
+	c := NewPodDNSConfigCollector(utils.Linux, nil, nil, nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestPodDNSConfigCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		osIdentifier  utils.OSIdentifier
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "windows",
+			osIdentifier:  utils.Windows,
+			collectorList: []string{},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "linux",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewPodDNSConfigCollector(tt.osIdentifier, nil, nil, nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestParseResolvConf(t *testing.T) {
+	const content = `# Generated by the kubelet
+nameserver 10.0.0.10
+search old.example.com
+search app.svc.cluster.local svc.cluster.local cluster.local
+options ndots:5 timeout:2
+`
+
+	conf := parseResolvConf(content)
+	expected := &resolvConf{
+		nameservers: []string{"10.0.0.10"},
+		searches:    []string{"app.svc.cluster.local", "svc.cluster.local", "cluster.local"},
+		ndots:       5,
+		options:     []string{"ndots:5", "timeout:2"},
+	}
+	if !reflect.DeepEqual(conf, expected) {
+		t.Errorf("unexpected resolv.conf:\nexpected %+v\nfound    %+v", expected, conf)
+	}
+
+	if conf := parseResolvConf("nameserver 168.63.129.16\n"); conf.ndots != resolverNdots || len(conf.searches) != 0 {
+		t.Errorf("unexpected default resolv.conf %+v", conf)
+	}
+}
+
+func TestPodDNSConfigCollectorCollect(t *testing.T) {
+	ndots := "2"
+	newPod := func(name string, uid types.UID, policy corev1.DNSPolicy, hostNetwork bool, config *corev1.PodDNSConfig) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app", UID: uid},
+			Spec:       corev1.PodSpec{NodeName: "node1", DNSPolicy: policy, HostNetwork: hostNetwork, DNSConfig: config},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	cgroup := func(uid string) string {
+		return "0::/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod" + strings.ReplaceAll(uid, "-", "_") + ".slice/cri-containerd-" + strings.Repeat("a", 64) + ".scope\n"
+	}
+
+	const (
+		webUID   = "11111111-1111-1111-1111-111111111111"
+		tunedUID = "22222222-2222-2222-2222-222222222222"
+		agentUID = "33333333-3333-3333-3333-333333333333"
+		goneUID  = "44444444-4444-4444-4444-444444444444"
+	)
+
+	clientset := fake.NewSimpleClientset(
+		newPod("web", webUID, corev1.DNSClusterFirst, false, nil),
+		newPod("tuned", tunedUID, corev1.DNSClusterFirst, false, &corev1.PodDNSConfig{
+			Searches: []string{"corp.example.com"},
+			Options:  []corev1.PodDNSConfigOption{{Name: "ndots", Value: &ndots}},
+		}),
+		newPod("agent", agentUID, corev1.DNSClusterFirst, true, nil),
+		newPod("gone", goneUID, corev1.DNSClusterFirst, false, nil),
+	)
+
+	clusterSearch := "search app.svc.cluster.local svc.cluster.local cluster.local"
+	fileSystem := test.NewFakeFileSystem(map[string]string{
+		// The first process isn't in a container.
+		"/proc/1/cgroup":                  "0::/init.scope\n",
+		"/proc/100/cgroup":                cgroup(webUID),
+		"/proc/100/root/etc/resolv.conf":  "nameserver 10.0.0.10\n" + clusterSearch + "\noptions ndots:5\n",
+		"/proc/200/cgroup":                cgroup(tunedUID),
+		"/proc/200/root/etc/resolv.conf":  "nameserver 10.0.0.10\n" + clusterSearch + " corp.example.com a.example.com b.example.com c.example.com\noptions ndots:2\n",
+		"/proc/300/cgroup":                cgroup(agentUID),
+		"/proc/300/root/etc/resolv.conf":  "nameserver 168.63.129.16\nsearch abc.internal.cloudapp.net\n",
+		"/proc/self/root/etc/resolv.conf": "nameserver 10.0.0.10\n",
+	})
+
+	filePaths := &utils.KnownFilePaths{Proc: "/proc"}
+	c := NewPodDNSConfigCollector(utils.Linux, filePaths, fileSystem, clientset, &utils.RuntimeInfo{HostNodeName: "node1"})
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	testDataValue(t, c.GetData()["pod-dns-config"], func(raw string) {
+		var report PodDNSConfigReport
+		if err := json.Unmarshal([]byte(raw), &report); err != nil {
+			t.Fatalf("unmarshal GetData(): %v", err)
+		}
+
+		pods := map[string]PodDNSConfig{}
+		for _, pod := range report.Pods {
+			pods[pod.Pod] = pod
+		}
+		if len(pods) != 4 {
+			t.Fatalf("unexpected pods: %+v", report.Pods)
+		}
+
+		// The default cluster DNS settings try every search domain first.
+		if web := pods["web"]; web.Ndots != 5 || len(web.Searches) != 3 || web.ExternalNameQueries != 4 || len(web.Warnings) != 0 {
+			t.Errorf("unexpected web config: %+v", web)
+		}
+
+		tuned := pods["tuned"]
+		if tuned.Ndots != 2 || tuned.ExternalNameQueries != 1 || tuned.DNSConfig == nil {
+			t.Errorf("unexpected tuned config: %+v", tuned)
+		}
+		if len(tuned.Warnings) != 2 || !strings.Contains(tuned.Warnings[0], "ndots is 2 rather than the default of 5") || !strings.Contains(tuned.Warnings[1], "search list has 7 domains") {
+			t.Errorf("unexpected tuned warnings: %v", tuned.Warnings)
+		}
+
+		agent := pods["agent"]
+		if agent.Ndots != resolverNdots || len(agent.Warnings) != 1 || !strings.Contains(agent.Warnings[0], "ClusterFirstWithHostNet") {
+			t.Errorf("unexpected agent config: %+v", agent)
+		}
+
+		if gone := pods["gone"]; len(gone.Error) == 0 {
+			t.Errorf("expected an error for a pod without processes: %+v", gone)
+		}
+	})
+}
@@ -0,0 +1,230 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// The number of most recent events reported for each pod.
+	podHealthMaxEvents = 5
+)
+
+type UnhealthyPodInfo struct {
+	Namespace  string                   `json:"namespace"`
+	Name       string                   `json:"name"`
+	NodeName   string                   `json:"nodeName,omitempty"`
+	Phase      string                   `json:"phase"`
+	Reason     string                   `json:"reason,omitempty"`
+	Message    string                   `json:"message,omitempty"`
+	Containers []PodHealthContainerInfo `json:"containers"`
+	Events     []PodHealthEvent         `json:"events"`
+}
+
+type PodHealthContainerInfo struct {
+	Name                  string     `json:"name"`
+	Init                  bool       `json:"init,omitempty"`
+	Ready                 bool       `json:"ready"`
+	RestartCount          int32      `json:"restartCount"`
+	State                 string     `json:"state"`
+	Reason                string     `json:"reason,omitempty"`
+	Message               string     `json:"message,omitempty"`
+	ExitCode              int32      `json:"exitCode,omitempty"`
+	LastTerminationReason string     `json:"lastTerminationReason,omitempty"`
+	LastExitCode          int32      `json:"lastExitCode,omitempty"`
+	LastFinishedAt        *time.Time `json:"lastFinishedAt,omitempty"`
+}
+
+type PodHealthEvent struct {
+	Type          string    `json:"type"`
+	Reason        string    `json:"reason"`
+	Message       string    `json:"message"`
+	Count         int32     `json:"count"`
+	LastTimestamp time.Time `json:"lastTimestamp"`
+}
+
+// PodHealthCollector defines a Pod Health Collector struct
+type PodHealthCollector struct {
+	data        map[string]string
+	clientset   kubernetes.Interface
+	runtimeInfo *utils.RuntimeInfo
+}
+
+// NewPodHealthCollector is a constructor
+func NewPodHealthCollector(clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *PodHealthCollector {
+	return &PodHealthCollector{
+		data:        make(map[string]string),
+		clientset:   clientset,
+		runtimeInfo: runtimeInfo,
+	}
+}
+
+func (collector *PodHealthCollector) GetName() string {
+	return "podhealth"
+}
+
+func (collector *PodHealthCollector) CheckSupported() error {
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *PodHealthCollector) Collect() error {
+	ctx := context.Background()
+
+	result := []UnhealthyPodInfo{}
+	podIndexes := map[string]int{}
+
+	err := utils.ListAllPages(metav1.ListOptions{}, func(listOptions metav1.ListOptions) (string, error) {
+		podList, err := collector.clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, listOptions)
+		if err != nil {
+			return "", err
+		}
+
+		for _, pod := range podList.Items {
+			if pod.Status.Phase == corev1.PodRunning || pod.Status.Phase == corev1.PodSucceeded {
+				continue
+			}
+
+			containers := getPodHealthContainers(pod.Status.InitContainerStatuses, true)
+			containers = append(containers, getPodHealthContainers(pod.Status.ContainerStatuses, false)...)
+
+			podIndexes[pod.Namespace+"/"+pod.Name] = len(result)
+			result = append(result, UnhealthyPodInfo{
+				Namespace:  pod.Namespace,
+				Name:       pod.Name,
+				NodeName:   pod.Spec.NodeName,
+				Phase:      string(pod.Status.Phase),
+				Reason:     pod.Status.Reason,
+				Message:    pod.Status.Message,
+				Containers: containers,
+				Events:     []PodHealthEvent{},
+			})
+		}
+
+		return podList.Continue, nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list pods: %w", err)
+	}
+
+	if len(result) > 0 {
+		err := utils.ListAllPages(metav1.ListOptions{FieldSelector: "involvedObject.kind=Pod"}, func(listOptions metav1.ListOptions) (string, error) {
+			eventList, err := collector.clientset.CoreV1().Events(metav1.NamespaceAll).List(ctx, listOptions)
+			if err != nil {
+				return "", err
+			}
+
+			for _, event := range eventList.Items {
+				index, ok := podIndexes[event.InvolvedObject.Namespace+"/"+event.InvolvedObject.Name]
+				if !ok || event.InvolvedObject.Kind != "Pod" {
+					continue
+				}
+
+				result[index].Events = append(result[index].Events, PodHealthEvent{
+					Type:          event.Type,
+					Reason:        event.Reason,
+					Message:       event.Message,
+					Count:         event.Count,
+					LastTimestamp: getEventLastTimestamp(&event),
+				})
+			}
+
+			return eventList.Continue, nil
+		})
+		if err != nil {
+			return fmt.Errorf("unable to list pod events: %w", err)
+		}
+
+		for i := range result {
+			events := result[i].Events
+			sort.Slice(events, func(i, j int) bool {
+				return events[i].LastTimestamp.After(events[j].LastTimestamp)
+			})
+			if len(events) > podHealthMaxEvents {
+				result[i].Events = events[:podHealthMaxEvents]
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Namespace+"/"+result[i].Name < result[j].Namespace+"/"+result[j].Name
+	})
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshall unhealthy pods to json: %w", err)
+	}
+
+	collector.data["unhealthy-pods"] = string(data)
+
+	return nil
+}
+
+func getPodHealthContainers(statuses []corev1.ContainerStatus, init bool) []PodHealthContainerInfo {
+	result := []PodHealthContainerInfo{}
+	for _, status := range statuses {
+		info := PodHealthContainerInfo{
+			Name:         status.Name,
+			Init:         init,
+			Ready:        status.Ready,
+			RestartCount: status.RestartCount,
+		}
+
+		switch {
+		case status.State.Waiting != nil:
+			info.State = "Waiting"
+			info.Reason = status.State.Waiting.Reason
+			info.Message = status.State.Waiting.Message
+		case status.State.Running != nil:
+			info.State = "Running"
+		case status.State.Terminated != nil:
+			info.State = "Terminated"
+			info.Reason = status.State.Terminated.Reason
+			info.Message = status.State.Terminated.Message
+			info.ExitCode = status.State.Terminated.ExitCode
+		}
+
+		if terminated := status.LastTerminationState.Terminated; terminated != nil {
+			info.LastTerminationReason = terminated.Reason
+			info.LastExitCode = terminated.ExitCode
+			if !terminated.FinishedAt.IsZero() {
+				info.LastFinishedAt = &terminated.FinishedAt.Time
+			}
+		}
+
+		result = append(result, info)
+	}
+
+	return result
+}
+
+// getEventLastTimestamp returns when the event last occurred. Events created through the events.k8s.io API
+// only populate the event time and series, not the legacy timestamps.
+func getEventLastTimestamp(event *corev1.Event) time.Time {
+	switch {
+	case event.Series != nil && !event.Series.LastObservedTime.IsZero():
+		return event.Series.LastObservedTime.Time
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	default:
+		return event.EventTime.Time
+	}
+}
+
+func (collector *PodHealthCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
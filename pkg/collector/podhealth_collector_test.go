@@ -0,0 +1,184 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestPodHealthCollectorGetName(t *testing.T) {
+	const expectedName = "podhealth"
+
+	c := NewPodHealthCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestPodHealthCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewPodHealthCollector(nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPodHealthCollectorCollect(t *testing.T) {
+	baseTime := time.Date(2023, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	newPod := func(name string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app"},
+			Spec:       corev1.PodSpec{NodeName: "node-1"},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+
+	running := newPod("running", corev1.PodRunning)
+	succeeded := newPod("succeeded", corev1.PodSucceeded)
+
+	pending := newPod("pending", corev1.PodPending)
+	pending.Spec.NodeName = ""
+
+	failed := newPod("evicted", corev1.PodFailed)
+	failed.Status.Reason = "Evicted"
+	failed.Status.Message = "The node was low on resource: memory."
+
+	initCrash := newPod("init-crash", corev1.PodPending)
+	initCrash.Status.InitContainerStatuses = []corev1.ContainerStatus{
+		{
+			Name:         "migrate",
+			RestartCount: 4,
+			State: corev1.ContainerState{
+				Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff", Message: "back-off 1m20s"},
+			},
+			LastTerminationState: corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 1, FinishedAt: metav1.NewTime(baseTime)},
+			},
+		},
+	}
+	initCrash.Status.ContainerStatuses = []corev1.ContainerStatus{
+		{
+			Name:  "main",
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "PodInitializing"}},
+		},
+	}
+
+	objects := []runtime.Object{}
+	for i := 0; i < podHealthMaxEvents+2; i++ {
+		objects = append(objects, &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: fmt.Sprintf("pending.%d", i), Namespace: "app"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "app", Name: "pending"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "FailedScheduling",
+			Message:        fmt.Sprintf("attempt %d", i),
+			LastTimestamp:  metav1.NewTime(baseTime.Add(time.Duration(i) * time.Minute)),
+		})
+	}
+	objects = append(objects, &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "running.1", Namespace: "app"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "app", Name: "running"},
+		Type:           corev1.EventTypeNormal,
+		Reason:         "Started",
+	})
+
+	clientset := fake.NewSimpleClientset(objects...)
+
+	// The fake clientset doesn't paginate, so serve the pods over two pages.
+	pages := []*corev1.PodList{
+		{ListMeta: metav1.ListMeta{Continue: "page-2"}, Items: []corev1.Pod{*running, *pending, *failed}},
+		{Items: []corev1.Pod{*succeeded, *initCrash}},
+	}
+	podListCalls := 0
+	clientset.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		page := pages[podListCalls]
+		podListCalls++
+		return true, page, nil
+	})
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+	}
+
+	c := NewPodHealthCollector(clientset, runtimeInfo)
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	if podListCalls != len(pages) {
+		t.Errorf("expected %d pod list calls, found %d", len(pages), podListCalls)
+	}
+
+	testDataValue(t, c.GetData()["unhealthy-pods"], func(raw string) {
+		var pods []UnhealthyPodInfo
+		if err := json.Unmarshal([]byte(raw), &pods); err != nil {
+			t.Fatalf("unmarshal GetData(): %v", err)
+		}
+
+		names := []string{}
+		for _, pod := range pods {
+			names = append(names, pod.Name)
+		}
+		if !equalStringSlices(names, []string{"evicted", "init-crash", "pending"}) {
+			t.Fatalf("unexpected unhealthy pods %v", names)
+		}
+
+		evicted := pods[0]
+		if evicted.Phase != "Failed" || evicted.Reason != "Evicted" || evicted.Message == "" {
+			t.Errorf("unexpected evicted pod %+v", evicted)
+		}
+
+		initCrash := pods[1]
+		if len(initCrash.Containers) != 2 {
+			t.Fatalf("expected 2 containers, found %+v", initCrash.Containers)
+		}
+		migrate := initCrash.Containers[0]
+		if !migrate.Init || migrate.State != "Waiting" || migrate.Reason != "CrashLoopBackOff" || migrate.RestartCount != 4 ||
+			migrate.LastTerminationReason != "Error" || migrate.LastExitCode != 1 || migrate.LastFinishedAt == nil {
+			t.Errorf("unexpected init container %+v", migrate)
+		}
+		if main := initCrash.Containers[1]; main.Init || main.Reason != "PodInitializing" {
+			t.Errorf("unexpected container %+v", main)
+		}
+
+		pending := pods[2]
+		if len(pending.Events) != podHealthMaxEvents {
+			t.Fatalf("expected %d events, found %d", podHealthMaxEvents, len(pending.Events))
+		}
+		expectedMessage := fmt.Sprintf("attempt %d", podHealthMaxEvents+1)
+		if pending.Events[0].Message != expectedMessage {
+			t.Errorf("expected most recent event first (%s), found %s", expectedMessage, pending.Events[0].Message)
+		}
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"strings"
 	"time"
 
@@ -14,13 +15,12 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
-	restclient "k8s.io/client-go/rest"
 )
 
 // PodsContainerLogsCollector defines a Pods Container Logs Collector struct
 type PodsContainerLogsCollector struct {
 	data        map[string]string
-	kubeconfig  *restclient.Config
+	clientset   kubernetes.Interface
 	runtimeInfo *utils.RuntimeInfo
 }
 
@@ -35,10 +35,10 @@ type PodsContainerStruct struct {
 }
 
 // NewPodsContainerLogs is a constructor
-func NewPodsContainerLogsCollector(config *restclient.Config, runtimeInfo *utils.RuntimeInfo) *PodsContainerLogsCollector {
+func NewPodsContainerLogsCollector(clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *PodsContainerLogsCollector {
 	return &PodsContainerLogsCollector{
 		data:        make(map[string]string),
-		kubeconfig:  config,
+		clientset:   clientset,
 		runtimeInfo: runtimeInfo,
 	}
 }
@@ -57,15 +57,9 @@ func (collector *PodsContainerLogsCollector) CheckSupported() error {
 
 // Collect implements the interface method
 func (collector *PodsContainerLogsCollector) Collect() error {
-	// Creates the clientset
-	clientset, err := kubernetes.NewForConfig(collector.kubeconfig)
-	if err != nil {
-		return fmt.Errorf("getting access to K8S failed: %w", err)
-	}
-
 	for _, namespace := range collector.runtimeInfo.ContainerLogsNamespaces {
 		// List the pods in the given namespace
-		podList, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+		podList, err := collector.clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
 
 		if err != nil {
 			return fmt.Errorf("getting pods failed: %w", err)
@@ -94,7 +88,7 @@ func (collector *PodsContainerLogsCollector) Collect() error {
 			for _, containerItem := range pod.Spec.Containers {
 				containerName := containerItem.Name
 				// Get pods container logs
-				containerLogs, err := getPodContainerLogs(namespace, pod.Name, containerName, clientset)
+				containerLogs, err := getPodContainerLogs(namespace, pod.Name, collector.getLogOptions(containerName), collector.clientset)
 
 				if err != nil {
 					return fmt.Errorf("getting container logs failed: %w", err)
@@ -128,21 +122,33 @@ func (collector *PodsContainerLogsCollector) GetData() map[string]interfaces.Dat
 	return utils.ToDataValueMap(collector.data)
 }
 
+// getLogOptions bounds the logs collected for a container to the most recent lines, and optionally to those logged
+// within a time window, since chatty containers can log far more than is useful to collect.
+func (collector *PodsContainerLogsCollector) getLogOptions(containerName string) *v1.PodLogOptions {
+	tailLines := collector.runtimeInfo.ContainerLogsTailLines
+	podLogOptions := &v1.PodLogOptions{
+		Container: containerName,
+		TailLines: &tailLines,
+	}
+
+	if collector.runtimeInfo.ContainerLogsSince > 0 {
+		// The API only accepts whole seconds, so round up to include the whole window.
+		sinceSeconds := int64(math.Ceil(collector.runtimeInfo.ContainerLogsSince.Seconds()))
+		podLogOptions.SinceSeconds = &sinceSeconds
+	}
+
+	return podLogOptions
+}
+
 func getPodContainerLogs(
 	namespace string,
 	podName string,
-	containerName string,
-	clientset *kubernetes.Clientset) (string, error) {
-
-	count := int64(100)
-	podLogOptions := v1.PodLogOptions{
-		Container: containerName,
-		TailLines: &count,
-	}
+	podLogOptions *v1.PodLogOptions,
+	clientset kubernetes.Interface) (string, error) {
 
 	podLogRequest := clientset.CoreV1().
 		Pods(namespace).
-		GetLogs(podName, &podLogOptions)
+		GetLogs(podName, podLogOptions)
 	stream, err := podLogRequest.Stream(context.Background())
 
 	if err != nil {
@@ -1,10 +1,14 @@
 package collector
 
 import (
+	"encoding/json"
 	"testing"
+	"time"
 
-	"github.com/Azure/aks-periscope/pkg/test"
 	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func TestPodsContainerLogsCollectorGetName(t *testing.T) {
@@ -47,37 +51,134 @@ func TestPodsContainerLogsCollectorCheckSupported(t *testing.T) {
 	}
 }
 
-func TestPodsContainerLogsCollectorCollect(t *testing.T) {
+func TestPodsContainerLogsCollectorGetLogOptions(t *testing.T) {
 	tests := []struct {
-		name    string
-		want    int
-		wantErr bool
+		name             string
+		tailLines        int64
+		since            time.Duration
+		wantSinceSeconds int64
 	}{
 		{
-			name:    "get pods container logs",
-			want:    1,
-			wantErr: false,
+			name:             "line cap only",
+			tailLines:        100,
+			since:            0,
+			wantSinceSeconds: 0,
+		},
+		{
+			name:             "line cap and time window",
+			tailLines:        2000,
+			since:            15 * time.Minute,
+			wantSinceSeconds: 900,
+		},
+		{
+			name:             "partial seconds rounded up",
+			tailLines:        100,
+			since:            1500 * time.Millisecond,
+			wantSinceSeconds: 2,
 		},
 	}
 
-	fixture, _ := test.GetClusterFixture()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runtimeInfo := &utils.RuntimeInfo{
+				ContainerLogsTailLines: tt.tailLines,
+				ContainerLogsSince:     tt.since,
+			}
+			c := NewPodsContainerLogsCollector(nil, runtimeInfo)
+			options := c.getLogOptions("app")
 
-	runtimeInfo := &utils.RuntimeInfo{
-		ContainerLogsNamespaces: []string{"kube-system"},
+			if options.Container != "app" {
+				t.Errorf("unexpected container %s", options.Container)
+			}
+			if options.TailLines == nil || *options.TailLines != tt.tailLines {
+				t.Errorf("unexpected tail lines %v, want %d", options.TailLines, tt.tailLines)
+			}
+			if tt.wantSinceSeconds == 0 {
+				if options.SinceSeconds != nil {
+					t.Errorf("unexpected since seconds %d", *options.SinceSeconds)
+				}
+			} else if options.SinceSeconds == nil || *options.SinceSeconds != tt.wantSinceSeconds {
+				t.Errorf("unexpected since seconds %v, want %d", options.SinceSeconds, tt.wantSinceSeconds)
+			}
+		})
+	}
+}
+
+func TestPodsContainerLogsCollectorCollect(t *testing.T) {
+	newPod := func(namespace, name string, containers ...string) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+		for _, container := range containers {
+			pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: container})
+			pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, corev1.ContainerStatus{Name: container, Ready: true, RestartCount: 1})
+		}
+		return pod
+	}
+
+	clientset := fake.NewSimpleClientset(
+		newPod("kube-system", "coredns-1", "coredns"),
+		newPod("app", "web-1", "nginx", "sidecar"),
+		newPod("other", "ignored-1", "main"),
+	)
+
+	tests := []struct {
+		name       string
+		namespaces []string
+		want       map[string]PodsContainerStruct
+	}{
+		{
+			name:       "no namespaces",
+			namespaces: []string{},
+			want:       map[string]PodsContainerStruct{},
+		},
+		{
+			name:       "selected namespaces",
+			namespaces: []string{"kube-system", "app"},
+			want: map[string]PodsContainerStruct{
+				"coredns-1-coredns": {Name: "coredns-1", Ready: "1/1", Status: "Running", Restart: 1, ContainerName: "coredns", ContainerLog: "fake logs"},
+				"web-1-nginx":       {Name: "web-1", Ready: "2/2", Status: "Running", Restart: 2, ContainerName: "nginx", ContainerLog: "fake logs"},
+				"web-1-sidecar":     {Name: "web-1", Ready: "2/2", Status: "Running", Restart: 2, ContainerName: "sidecar", ContainerLog: "fake logs"},
+			},
+		},
 	}
-	c := NewPodsContainerLogsCollector(fixture.PeriscopeAccess.ClientConfig, runtimeInfo)
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := c.Collect()
+			runtimeInfo := &utils.RuntimeInfo{
+				ContainerLogsNamespaces: tt.namespaces,
+				ContainerLogsTailLines:  100,
+			}
+			c := NewPodsContainerLogsCollector(clientset, runtimeInfo)
+			if err := c.Collect(); err != nil {
+				t.Fatalf("Collect() error = %v", err)
+			}
 
-			if (err != nil) != tt.wantErr {
-				t.Errorf("Collect() error = %v, wantErr %v", err, tt.wantErr)
+			data := c.GetData()
+			if len(data) != len(tt.want) {
+				t.Errorf("expected %d keys, found %d", len(tt.want), len(data))
 			}
-			raw := c.GetData()
 
-			if len(raw) < tt.want {
-				t.Errorf("len(GetData()) = %v, want %v", len(raw), tt.want)
+			for key, want := range tt.want {
+				value, ok := data[key]
+				if !ok {
+					t.Errorf("missing key %s", key)
+					continue
+				}
+
+				testDataValue(t, value, func(raw string) {
+					var result PodsContainerStruct
+					if err := json.Unmarshal([]byte(raw), &result); err != nil {
+						t.Fatalf("unmarshal %s: %v", key, err)
+					}
+
+					// The age depends on the current time, so isn't compared.
+					result.Age = 0
+					if result != want {
+						t.Errorf("unexpected data for %s:\nexpected %+v\nfound    %+v", key, want, result)
+					}
+				})
 			}
 		})
 	}
@@ -0,0 +1,326 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// Pods created within this window are reported, which is also about as long as the events of their image pulls
+	// are retained by the API server.
+	podStartupWindow = time.Hour
+
+	// At most this many pods are reported, the slowest to start first.
+	podStartupMaxPods = 200
+)
+
+// The message of a Pulled event for an image which was pulled, rather than already present. The duration is followed by
+// the time including waiting for other pulls, in newer versions of the kubelet, and is missing in older versions.
+var podStartupPulledRegex = regexp.MustCompile(`^Successfully pulled image "[^"]*" in ([0-9.]+[a-zµ]+)`)
+
+type PodStartupReport struct {
+	WindowMinutes      int          `json:"windowMinutes"`
+	PodCount           int          `json:"podCount"`
+	ImagePullDominated int          `json:"imagePullDominated"`
+	Pods               []PodStartup `json:"pods"`
+}
+
+// PodStartup holds the durations of the phases of a pod's startup, in milliseconds, from its creation to it being
+// ready. Each phase ends when the pod's condition of the same name last became true, so a phase is missing if the pod
+// hasn't reached it yet, or if its conditions don't record it (as for pods whose containers have since restarted).
+//
+// ContainersReadyMs spans pulling images, starting the containers and passing their startup and readiness probes, and
+// ImagePullMs is the total time spent pulling the pod's images, from the kubelet's Pulled events. The kubelet pulls
+// images one at a time by default, so this can include time spent waiting for the pulls of other pods.
+type PodStartup struct {
+	Namespace          string     `json:"namespace"`
+	Name               string     `json:"name"`
+	NodeName           string     `json:"nodeName,omitempty"`
+	CreatedAt          time.Time  `json:"createdAt"`
+	Ready              bool       `json:"ready"`
+	ScheduledMs        *int64     `json:"scheduledMs,omitempty"`
+	InitializedMs      *int64     `json:"initializedMs,omitempty"`
+	ContainersReadyMs  *int64     `json:"containersReadyMs,omitempty"`
+	ReadyMs            *int64     `json:"readyMs,omitempty"`
+	TotalMs            *int64     `json:"totalMs,omitempty"`
+	ContainersStartMs  *int64     `json:"containersStartMs,omitempty"`
+	ImagePullMs        *int64     `json:"imagePullMs,omitempty"`
+	ImagesPulled       int        `json:"imagesPulled"`
+	ImagePullDominated bool       `json:"imagePullDominated"`
+	ContainersStarted  *time.Time `json:"containersStarted,omitempty"`
+}
+
+// PodStartupCollector defines a Pod Startup Collector struct
+type PodStartupCollector struct {
+	data        map[string]string
+	clientset   kubernetes.Interface
+	runtimeInfo *utils.RuntimeInfo
+	now         func() time.Time
+}
+
+// NewPodStartupCollector is a constructor
+func NewPodStartupCollector(clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *PodStartupCollector {
+	return &PodStartupCollector{
+		data:        make(map[string]string),
+		clientset:   clientset,
+		runtimeInfo: runtimeInfo,
+		now:         time.Now,
+	}
+}
+
+func (collector *PodStartupCollector) GetName() string {
+	return "podstartup"
+}
+
+func (collector *PodStartupCollector) CheckSupported() error {
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *PodStartupCollector) Collect() error {
+	ctx := context.Background()
+	since := collector.now().Add(-podStartupWindow)
+
+	pods := []corev1.Pod{}
+	err := utils.ListAllPages(metav1.ListOptions{}, func(listOptions metav1.ListOptions) (string, error) {
+		podList, err := collector.clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, listOptions)
+		if err != nil {
+			return "", err
+		}
+
+		for _, pod := range podList.Items {
+			if !pod.CreationTimestamp.Time.Before(since) {
+				pods = append(pods, pod)
+			}
+		}
+
+		return podList.Continue, nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list pods: %w", err)
+	}
+
+	pulls, err := collector.getImagePulls(ctx, since)
+	if err != nil {
+		return err
+	}
+
+	report := PodStartupReport{
+		WindowMinutes: int(podStartupWindow.Minutes()),
+		PodCount:      len(pods),
+		Pods:          []PodStartup{},
+	}
+	for i := range pods {
+		startup := getPodStartup(&pods[i], pulls[string(pods[i].UID)])
+		if startup.ImagePullDominated {
+			report.ImagePullDominated++
+		}
+		report.Pods = append(report.Pods, startup)
+	}
+
+	// Pods which aren't ready yet are the slowest of all, and are listed first.
+	sort.SliceStable(report.Pods, func(i, j int) bool {
+		a, b := report.Pods[i], report.Pods[j]
+		if a.Ready != b.Ready {
+			return !a.Ready
+		}
+		if a.TotalMs != nil && b.TotalMs != nil && *a.TotalMs != *b.TotalMs {
+			return *a.TotalMs > *b.TotalMs
+		}
+		return a.Namespace+"/"+a.Name < b.Namespace+"/"+b.Name
+	})
+	if len(report.Pods) > podStartupMaxPods {
+		report.Pods = report.Pods[:podStartupMaxPods]
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("marshall pod startup to json: %w", err)
+	}
+
+	collector.data["pod-startup"] = string(data)
+
+	return nil
+}
+
+// podImagePull is an image pull of a pod, from a Pulled event.
+type podImagePull struct {
+	duration time.Duration
+	at       time.Time
+}
+
+// getImagePulls returns the image pulls of each pod (by UID) since the given time.
+func (collector *PodStartupCollector) getImagePulls(ctx context.Context, since time.Time) (map[string][]podImagePull, error) {
+	pulls := map[string][]podImagePull{}
+
+	listOptions := metav1.ListOptions{FieldSelector: "involvedObject.kind=Pod,reason=Pulled"}
+	err := utils.ListAllPages(listOptions, func(listOptions metav1.ListOptions) (string, error) {
+		eventList, err := collector.clientset.CoreV1().Events(metav1.NamespaceAll).List(ctx, listOptions)
+		if err != nil {
+			return "", err
+		}
+
+		for _, event := range eventList.Items {
+			if event.InvolvedObject.Kind != "Pod" || event.Reason != "Pulled" {
+				continue
+			}
+
+			// Images which were already present on the node are also reported as pulled, and take no time.
+			match := podStartupPulledRegex.FindStringSubmatch(event.Message)
+			if match == nil {
+				continue
+			}
+			duration, err := time.ParseDuration(match[1])
+			if err != nil {
+				continue
+			}
+
+			at := getEventLastTimestamp(&event)
+			if at.Before(since) {
+				continue
+			}
+
+			uid := string(event.InvolvedObject.UID)
+			pulls[uid] = append(pulls[uid], podImagePull{duration: duration, at: at})
+		}
+
+		return eventList.Continue, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list events: %w", err)
+	}
+
+	return pulls, nil
+}
+
+// getPodStartup computes the startup phases of a pod from its conditions, container states and image pulls.
+func getPodStartup(pod *corev1.Pod, pulls []podImagePull) PodStartup {
+	created := pod.CreationTimestamp.Time
+	scheduled := getPodConditionTime(pod, corev1.PodScheduled)
+	initialized := getPodConditionTime(pod, corev1.PodInitialized)
+	containersReady := getPodConditionTime(pod, corev1.ContainersReady)
+	ready := getPodConditionTime(pod, corev1.PodReady)
+
+	startup := PodStartup{
+		Namespace: pod.Namespace,
+		Name:      pod.Name,
+		NodeName:  pod.Spec.NodeName,
+		CreatedAt: created,
+		Ready:     ready != nil,
+	}
+
+	// Each phase starts at the end of the latest phase before it which is known.
+	start := &created
+	startup.ScheduledMs, start = getPodStartupPhase(start, scheduled)
+	startup.InitializedMs, start = getPodStartupPhase(start, initialized)
+	startup.ContainersReadyMs, start = getPodStartupPhase(start, containersReady)
+	startup.ReadyMs, _ = getPodStartupPhase(start, ready)
+	startup.TotalMs, _ = getPodStartupPhase(&created, ready)
+
+	// The containers are started one after the other, so they have all started once the last of them has.
+	containersStarted := getContainersStarted(pod.Status.ContainerStatuses)
+	if containersStarted != nil {
+		startup.ContainersStarted = containersStarted
+		if initialized != nil {
+			startup.ContainersStartMs, _ = getPodStartupPhase(initialized, containersStarted)
+		}
+	}
+
+	// Pulls after the pod first became ready are for restarted containers, and aren't part of its startup.
+	var pullTime time.Duration
+	for _, pull := range pulls {
+		if ready != nil && pull.at.After(*ready) {
+			continue
+		}
+		pullTime += pull.duration
+		startup.ImagesPulled++
+	}
+	if startup.ImagesPulled > 0 {
+		pullMs := pullTime.Milliseconds()
+		startup.ImagePullMs = &pullMs
+	}
+
+	// Pulling images dominated the startup if it took longer than every other phase together.
+	end := ready
+	if end == nil {
+		end = containersReady
+	}
+	if startup.ImagePullMs != nil && end != nil {
+		startup.ImagePullDominated = *startup.ImagePullMs*2 > end.Sub(created).Milliseconds()
+	}
+
+	return startup
+}
+
+// getPodStartupPhase returns the duration of a phase in milliseconds, and the start of the next phase, which is the
+// end of this phase if known, and otherwise the start of this phase.
+func getPodStartupPhase(start, end *time.Time) (*int64, *time.Time) {
+	if end == nil {
+		return nil, start
+	}
+	if start == nil || end.Before(*start) {
+		return nil, end
+	}
+
+	ms := end.Sub(*start).Milliseconds()
+	return &ms, end
+}
+
+// getPodConditionTime returns the time the pod condition of the given type last became true, if it is true.
+func getPodConditionTime(pod *corev1.Pod, conditionType corev1.PodConditionType) *time.Time {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue && !condition.LastTransitionTime.IsZero() {
+			return &condition.LastTransitionTime.Time
+		}
+	}
+	return nil
+}
+
+// getContainersStarted returns the time the last of the containers first started, as far as their current and
+// previous states record, or nil if any of them hasn't started.
+func getContainersStarted(statuses []corev1.ContainerStatus) *time.Time {
+	var result *time.Time
+	for _, status := range statuses {
+		var started *time.Time
+		for _, state := range []corev1.ContainerState{status.LastTerminationState, status.State} {
+			var startedAt metav1.Time
+			switch {
+			case state.Running != nil:
+				startedAt = state.Running.StartedAt
+			case state.Terminated != nil:
+				startedAt = state.Terminated.StartedAt
+			}
+			if !startedAt.IsZero() && (started == nil || startedAt.Time.Before(*started)) {
+				t := startedAt.Time
+				started = &t
+			}
+		}
+
+		if started == nil {
+			return nil
+		}
+		if result == nil || started.After(*result) {
+			result = started
+		}
+	}
+	return result
+}
+
+func (collector *PodStartupCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
@@ -0,0 +1,135 @@
+package collector
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPodStartupCollectorGetName(t *testing.T) {
+	const expectedName = "podstartup"
+
+	c := NewPodStartupCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestPodStartupCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		c := NewPodStartupCollector(nil, &utils.RuntimeInfo{CollectorList: tt.collectorList})
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("CheckSupported() error = %v, wantErr %v", err, tt.wantErr)
+		}
+	}
+}
+
+func TestPodStartupCollectorCollect(t *testing.T) {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	created := now.Add(-10 * time.Minute)
+	at := func(seconds int) metav1.Time {
+		return metav1.NewTime(created.Add(time.Duration(seconds) * time.Second))
+	}
+	condition := func(conditionType corev1.PodConditionType, seconds int) corev1.PodCondition {
+		return corev1.PodCondition{Type: conditionType, Status: corev1.ConditionTrue, LastTransitionTime: at(seconds)}
+	}
+	newPod := func(name string, uid types.UID, createdAt time.Time, conditions ...corev1.PodCondition) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app", UID: uid, CreationTimestamp: metav1.NewTime(createdAt)},
+			Spec:       corev1.PodSpec{NodeName: "node1"},
+			Status: corev1.PodStatus{
+				Conditions: conditions,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "main", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: at(50)}}},
+				},
+			},
+		}
+	}
+	pulled := func(name, uid, message string, seconds int) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "app"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "app", Name: name, UID: types.UID(uid)},
+			Reason:         "Pulled",
+			Message:        message,
+			LastTimestamp:  at(seconds),
+		}
+	}
+
+	clientset := fake.NewSimpleClientset(
+		newPod("slow-pull", "uid-1", created,
+			condition(corev1.PodScheduled, 2), condition(corev1.PodInitialized, 5),
+			condition(corev1.ContainersReady, 60), condition(corev1.PodReady, 60)),
+		newPod("cached", "uid-2", created,
+			condition(corev1.PodScheduled, 1), condition(corev1.PodInitialized, 1),
+			condition(corev1.ContainersReady, 90), condition(corev1.PodReady, 91)),
+		// A pod which hasn't been scheduled has no other conditions.
+		newPod("pending", "uid-3", created,
+			corev1.PodCondition{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: "Unschedulable"}),
+		newPod("old", "uid-4", now.Add(-2*time.Hour), condition(corev1.PodReady, 1)),
+		pulled("slow-pull", "uid-1", `Successfully pulled image "registry.example.com/app:v1" in 40.5s (40.5s including waiting)`, 48),
+		pulled("cached", "uid-2", `Container image "registry.example.com/app:v1" already present on machine`, 2),
+	)
+
+	c := NewPodStartupCollector(clientset, &utils.RuntimeInfo{})
+	c.now = func() time.Time { return now }
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	testDataValue(t, c.GetData()["pod-startup"], func(raw string) {
+		var report PodStartupReport
+		if err := json.Unmarshal([]byte(raw), &report); err != nil {
+			t.Fatalf("unmarshal GetData(): %v", err)
+		}
+
+		if report.PodCount != 3 || report.ImagePullDominated != 1 || len(report.Pods) != 3 {
+			t.Fatalf("unexpected report: %+v", report)
+		}
+
+		// Pods which aren't ready come first, then the slowest.
+		pending, cached, slowPull := report.Pods[0], report.Pods[1], report.Pods[2]
+		if pending.Name != "pending" || cached.Name != "cached" || slowPull.Name != "slow-pull" {
+			t.Fatalf("unexpected order: %+v", report.Pods)
+		}
+
+		if pending.Ready || pending.ScheduledMs != nil || pending.TotalMs != nil || pending.ImagePullDominated {
+			t.Errorf("unexpected pending pod: %+v", pending)
+		}
+
+		if !isMs(slowPull.ScheduledMs, 2000) || !isMs(slowPull.InitializedMs, 3000) || !isMs(slowPull.ContainersReadyMs, 55000) ||
+			!isMs(slowPull.ReadyMs, 0) || !isMs(slowPull.TotalMs, 60000) || !isMs(slowPull.ContainersStartMs, 45000) ||
+			!isMs(slowPull.ImagePullMs, 40500) || slowPull.ImagesPulled != 1 || !slowPull.ImagePullDominated {
+			t.Errorf("unexpected slow-pull pod: %+v", slowPull)
+		}
+
+		if !isMs(cached.TotalMs, 91000) || cached.ImagePullMs != nil || cached.ImagesPulled != 0 || cached.ImagePullDominated {
+			t.Errorf("unexpected cached pod: %+v", cached)
+		}
+	})
+}
+
+func isMs(ms *int64, expected int64) bool {
+	return ms != nil && *ms == expected
+}
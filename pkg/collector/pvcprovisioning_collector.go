@@ -0,0 +1,359 @@
+package collector
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// The number of most recent events reported for each PVC.
+	pvcProvisioningMaxEvents = 5
+
+	// The external-provisioner sidecar of a CSI driver's controller is conventionally deployed in a container with
+	// this name. Its logs name each PVC it provisions as namespace/name.
+	pvcProvisionerContainerName = "csi-provisioner"
+	pvcProvisionerLogLines      = int64(1000)
+	pvcProvisionerMaxLogLines   = 20
+)
+
+// Annotations set on PVCs by the scheduler and the PV controller.
+const (
+	pvcSelectedNodeAnnotation           = "volume.kubernetes.io/selected-node"
+	pvcStorageProvisionerAnnotation     = "volume.kubernetes.io/storage-provisioner"
+	pvcBetaStorageProvisionerAnnotation = "volume.beta.kubernetes.io/storage-provisioner"
+	defaultStorageClassAnnotation       = "storageclass.kubernetes.io/is-default-class"
+)
+
+type PendingPVCInfo struct {
+	Namespace               string                 `json:"namespace"`
+	Name                    string                 `json:"name"`
+	StorageClass            string                 `json:"storageClass,omitempty"`
+	Provisioner             string                 `json:"provisioner,omitempty"`
+	VolumeBindingMode       string                 `json:"volumeBindingMode,omitempty"`
+	SelectedNode            string                 `json:"selectedNode,omitempty"`
+	WaitingForFirstConsumer bool                   `json:"waitingForFirstConsumer"`
+	Blocked                 bool                   `json:"blocked"`
+	Reason                  string                 `json:"reason,omitempty"`
+	Age                     string                 `json:"age"`
+	Events                  []PVCProvisioningEvent `json:"events"`
+	ProvisionerLogs         []PVCProvisionerLogs   `json:"provisionerLogs"`
+}
+
+type PVCProvisioningEvent struct {
+	Type          string    `json:"type"`
+	Reason        string    `json:"reason"`
+	Message       string    `json:"message"`
+	Count         int32     `json:"count"`
+	LastTimestamp time.Time `json:"lastTimestamp"`
+}
+
+type PVCProvisionerLogs struct {
+	Pod      string   `json:"pod"`
+	NodeName string   `json:"nodeName"`
+	Lines    []string `json:"lines"`
+}
+
+// PVCProvisioningCollector defines a PVC Provisioning Collector struct
+type PVCProvisioningCollector struct {
+	data        map[string]string
+	clientset   kubernetes.Interface
+	runtimeInfo *utils.RuntimeInfo
+	now         func() time.Time
+}
+
+// NewPVCProvisioningCollector is a constructor
+func NewPVCProvisioningCollector(clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *PVCProvisioningCollector {
+	return &PVCProvisioningCollector{
+		data:        make(map[string]string),
+		clientset:   clientset,
+		runtimeInfo: runtimeInfo,
+		now:         time.Now,
+	}
+}
+
+func (collector *PVCProvisioningCollector) GetName() string {
+	return "pvcprovisioning"
+}
+
+func (collector *PVCProvisioningCollector) CheckSupported() error {
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *PVCProvisioningCollector) Collect() error {
+	ctx := context.Background()
+
+	storageClassList, err := collector.clientset.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list storage classes: %w", err)
+	}
+
+	storageClasses := map[string]*storagev1.StorageClass{}
+	defaultStorageClass := ""
+	for i := range storageClassList.Items {
+		storageClass := &storageClassList.Items[i]
+		storageClasses[storageClass.Name] = storageClass
+		if storageClass.Annotations[defaultStorageClassAnnotation] == "true" {
+			defaultStorageClass = storageClass.Name
+		}
+	}
+
+	result := []PendingPVCInfo{}
+	pvcIndexes := map[string]int{}
+
+	err = utils.ListAllPages(metav1.ListOptions{}, func(listOptions metav1.ListOptions) (string, error) {
+		pvcList, err := collector.clientset.CoreV1().PersistentVolumeClaims(metav1.NamespaceAll).List(ctx, listOptions)
+		if err != nil {
+			return "", err
+		}
+
+		for i := range pvcList.Items {
+			pvc := &pvcList.Items[i]
+			if pvc.Status.Phase != corev1.ClaimPending {
+				continue
+			}
+
+			pvcIndexes[pvc.Namespace+"/"+pvc.Name] = len(result)
+			result = append(result, collector.getPendingPVCInfo(pvc, storageClasses, defaultStorageClass))
+		}
+
+		return pvcList.Continue, nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list persistent volume claims: %w", err)
+	}
+
+	if len(result) > 0 {
+		if err := collector.addEvents(ctx, result, pvcIndexes); err != nil {
+			return err
+		}
+
+		if err := collector.addProvisionerLogs(ctx, result); err != nil {
+			return err
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Namespace+"/"+result[i].Name < result[j].Namespace+"/"+result[j].Name
+	})
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshall pending PVCs to json: %w", err)
+	}
+
+	if err := validateOutput(collector.runtimeInfo, collector.GetName(), "pending-pvcs", data); err != nil {
+		return err
+	}
+
+	collector.data["pending-pvcs"] = string(data)
+
+	return nil
+}
+
+func (collector *PVCProvisioningCollector) getPendingPVCInfo(pvc *corev1.PersistentVolumeClaim, storageClasses map[string]*storagev1.StorageClass, defaultStorageClass string) PendingPVCInfo {
+	info := PendingPVCInfo{
+		Namespace:       pvc.Namespace,
+		Name:            pvc.Name,
+		SelectedNode:    pvc.Annotations[pvcSelectedNodeAnnotation],
+		Age:             collector.now().Sub(pvc.CreationTimestamp.Time).Round(time.Second).String(),
+		Events:          []PVCProvisioningEvent{},
+		ProvisionerLogs: []PVCProvisionerLogs{},
+	}
+
+	// A nil class means the default class, but an empty one means no class (i.e. binding to a pre-provisioned PV).
+	info.StorageClass = defaultStorageClass
+	if pvc.Spec.StorageClassName != nil {
+		info.StorageClass = *pvc.Spec.StorageClassName
+	}
+
+	// The provisioner annotation is set by the PV controller once provisioning is requested.
+	info.Provisioner = pvc.Annotations[pvcStorageProvisionerAnnotation]
+	if info.Provisioner == "" {
+		info.Provisioner = pvc.Annotations[pvcBetaStorageProvisionerAnnotation]
+	}
+
+	storageClass, ok := storageClasses[info.StorageClass]
+	if ok {
+		if info.Provisioner == "" {
+			info.Provisioner = storageClass.Provisioner
+		}
+		if storageClass.VolumeBindingMode != nil {
+			info.VolumeBindingMode = string(*storageClass.VolumeBindingMode)
+		}
+	}
+
+	switch {
+	case info.StorageClass == "" && pvc.Spec.VolumeName == "":
+		info.Blocked = true
+		info.Reason = "no storage class, and no matching persistent volume is bound"
+	case info.StorageClass != "" && !ok:
+		info.Blocked = true
+		info.Reason = fmt.Sprintf("storage class %s does not exist", info.StorageClass)
+	case info.VolumeBindingMode == string(storagev1.VolumeBindingWaitForFirstConsumer) && info.SelectedNode == "":
+		// Pending by design: the volume isn't provisioned until a pod using the claim is scheduled to a node.
+		info.WaitingForFirstConsumer = true
+	default:
+		info.Blocked = true
+	}
+
+	return info
+}
+
+// addEvents adds the most recent events of each pending PVC, which usually explain why provisioning is failing.
+func (collector *PVCProvisioningCollector) addEvents(ctx context.Context, result []PendingPVCInfo, pvcIndexes map[string]int) error {
+	err := utils.ListAllPages(metav1.ListOptions{FieldSelector: "involvedObject.kind=PersistentVolumeClaim"}, func(listOptions metav1.ListOptions) (string, error) {
+		eventList, err := collector.clientset.CoreV1().Events(metav1.NamespaceAll).List(ctx, listOptions)
+		if err != nil {
+			return "", err
+		}
+
+		for _, event := range eventList.Items {
+			index, ok := pvcIndexes[event.InvolvedObject.Namespace+"/"+event.InvolvedObject.Name]
+			if !ok || event.InvolvedObject.Kind != "PersistentVolumeClaim" {
+				continue
+			}
+
+			result[index].Events = append(result[index].Events, PVCProvisioningEvent{
+				Type:          event.Type,
+				Reason:        event.Reason,
+				Message:       event.Message,
+				Count:         event.Count,
+				LastTimestamp: getEventLastTimestamp(&event),
+			})
+		}
+
+		return eventList.Continue, nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list persistent volume claim events: %w", err)
+	}
+
+	for i := range result {
+		events := result[i].Events
+		sort.Slice(events, func(i, j int) bool {
+			return events[i].LastTimestamp.After(events[j].LastTimestamp)
+		})
+		if len(events) > pvcProvisioningMaxEvents {
+			result[i].Events = events[:pvcProvisioningMaxEvents]
+		}
+	}
+
+	return nil
+}
+
+// addProvisionerLogs adds the lines of the external-provisioner logs which mention each blocked PVC. The logs are
+// only collected from the provisioners on the node this instance of Periscope is running on, since the instances on
+// the other nodes will collect their own.
+func (collector *PVCProvisioningCollector) addProvisionerLogs(ctx context.Context, result []PendingPVCInfo) error {
+	podList, err := collector.clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: "spec.nodeName=" + collector.runtimeInfo.HostNodeName,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list pods on node %s: %w", collector.runtimeInfo.HostNodeName, err)
+	}
+
+	for _, pod := range podList.Items {
+		if pod.Spec.NodeName != collector.runtimeInfo.HostNodeName || !hasContainer(&pod, pvcProvisionerContainerName) {
+			continue
+		}
+
+		tailLines := pvcProvisionerLogLines
+		podLogOptions := &corev1.PodLogOptions{Container: pvcProvisionerContainerName, TailLines: &tailLines}
+		containerLogs, err := getPodContainerLogs(pod.Namespace, pod.Name, podLogOptions, collector.clientset)
+		if err != nil {
+			log.Printf("Unable to get logs for %s/%s container %s: %v", pod.Namespace, pod.Name, pvcProvisionerContainerName, err)
+			continue
+		}
+
+		for i := range result {
+			if !result[i].Blocked {
+				continue
+			}
+
+			lines := getLinesMentioning(containerLogs, result[i].Namespace+"/"+result[i].Name, pvcProvisionerMaxLogLines)
+			if len(lines) == 0 {
+				continue
+			}
+
+			result[i].ProvisionerLogs = append(result[i].ProvisionerLogs, PVCProvisionerLogs{
+				Pod:      pod.Namespace + "/" + pod.Name,
+				NodeName: pod.Spec.NodeName,
+				Lines:    lines,
+			})
+		}
+	}
+
+	return nil
+}
+
+func hasContainer(pod *corev1.Pod, name string) bool {
+	for _, container := range pod.Spec.Containers {
+		if container.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// getLinesMentioning returns the last maxLines lines of the logs containing the text. The text is matched as a whole
+// namespace/name, so that a PVC's name isn't matched within a longer one (e.g. "default/data" in "default/data-0").
+func getLinesMentioning(logs, text string, maxLines int) []string {
+	lines := []string{}
+	scanner := bufio.NewScanner(strings.NewReader(logs))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if containsWhole(line, text) {
+			lines = append(lines, line)
+		}
+	}
+
+	if len(lines) > maxLines {
+		lines = lines[len(lines)-maxLines:]
+	}
+
+	return lines
+}
+
+func containsWhole(line, text string) bool {
+	for offset := 0; ; {
+		index := strings.Index(line[offset:], text)
+		if index < 0 {
+			return false
+		}
+
+		start := offset + index
+		end := start + len(text)
+		if (start == 0 || !isNameCharacter(line[start-1])) && (end == len(line) || !isNameCharacter(line[end])) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+// isNameCharacter returns whether the character can be part of a Kubernetes object name.
+func isNameCharacter(c byte) bool {
+	return c == '-' || c == '.' || c == '/' || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9')
+}
+
+func (collector *PVCProvisioningCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
@@ -0,0 +1,182 @@
+package collector
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPVCProvisioningCollectorGetName(t *testing.T) {
+	const expectedName = "pvcprovisioning"
+
+	c := NewPVCProvisioningCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestPVCProvisioningCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewPVCProvisioningCollector(nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPVCProvisioningCollectorCollect(t *testing.T) {
+	immediate := storagev1.VolumeBindingImmediate
+	waitForFirstConsumer := storagev1.VolumeBindingWaitForFirstConsumer
+	newStorageClass := func(name string, mode *storagev1.VolumeBindingMode, isDefault bool) *storagev1.StorageClass {
+		storageClass := &storagev1.StorageClass{
+			ObjectMeta:        metav1.ObjectMeta{Name: name},
+			Provisioner:       "disk.csi.azure.com",
+			VolumeBindingMode: mode,
+		}
+		if isDefault {
+			storageClass.Annotations = map[string]string{defaultStorageClassAnnotation: "true"}
+		}
+		return storageClass
+	}
+	newPVC := func(name string, storageClass *string, phase corev1.PersistentVolumeClaimPhase, annotations map[string]string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: annotations},
+			Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: storageClass},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: phase},
+		}
+	}
+	stringPtr := func(s string) *string { return &s }
+
+	tests := []struct {
+		name    string
+		objects []runtime.Object
+		want    []PendingPVCInfo
+	}{
+		{
+			name:    "no PVCs",
+			objects: []runtime.Object{},
+			want:    []PendingPVCInfo{},
+		},
+		{
+			name: "pending PVCs",
+			objects: []runtime.Object{
+				newStorageClass("managed-csi", &immediate, true),
+				newStorageClass("managed-csi-wffc", &waitForFirstConsumer, false),
+				newPVC("bound", nil, corev1.ClaimBound, nil),
+				newPVC("blocked", nil, corev1.ClaimPending, nil),
+				newPVC("waiting", stringPtr("managed-csi-wffc"), corev1.ClaimPending, nil),
+				newPVC("scheduled", stringPtr("managed-csi-wffc"), corev1.ClaimPending, map[string]string{pvcSelectedNodeAnnotation: "node2"}),
+				newPVC("missing-class", stringPtr("premium"), corev1.ClaimPending, nil),
+				&corev1.Event{
+					ObjectMeta:     metav1.ObjectMeta{Name: "blocked.1", Namespace: "default"},
+					InvolvedObject: corev1.ObjectReference{Kind: "PersistentVolumeClaim", Namespace: "default", Name: "blocked"},
+					Type:           corev1.EventTypeWarning,
+					Reason:         "ProvisioningFailed",
+					Message:        "failed to provision volume",
+					Count:          3,
+				},
+			},
+			want: []PendingPVCInfo{
+				{
+					Namespace: "default", Name: "blocked", StorageClass: "managed-csi", Provisioner: "disk.csi.azure.com", VolumeBindingMode: "Immediate", Blocked: true,
+					Events: []PVCProvisioningEvent{{Type: corev1.EventTypeWarning, Reason: "ProvisioningFailed", Message: "failed to provision volume", Count: 3}},
+				},
+				{
+					Namespace: "default", Name: "missing-class", StorageClass: "premium", Blocked: true, Reason: "storage class premium does not exist",
+					Events: []PVCProvisioningEvent{},
+				},
+				{
+					Namespace: "default", Name: "scheduled", StorageClass: "managed-csi-wffc", Provisioner: "disk.csi.azure.com", VolumeBindingMode: "WaitForFirstConsumer", SelectedNode: "node2", Blocked: true,
+					Events: []PVCProvisioningEvent{},
+				},
+				{
+					Namespace: "default", Name: "waiting", StorageClass: "managed-csi-wffc", Provisioner: "disk.csi.azure.com", VolumeBindingMode: "WaitForFirstConsumer", WaitingForFirstConsumer: true,
+					Events: []PVCProvisioningEvent{},
+				},
+			},
+		},
+	}
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList:          []string{},
+		HostNodeName:           "node1",
+		OutputSchemaValidation: utils.OutputSchemaValidationFail,
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewPVCProvisioningCollector(fake.NewSimpleClientset(tt.objects...), runtimeInfo)
+			if err := c.Collect(); err != nil {
+				t.Fatalf("Collect() error = %v", err)
+			}
+
+			testDataValue(t, c.GetData()["pending-pvcs"], func(raw string) {
+				var result []PendingPVCInfo
+				if err := json.Unmarshal([]byte(raw), &result); err != nil {
+					t.Fatalf("unmarshal pending PVCs: %v", err)
+				}
+				for i := range result {
+					result[i].Age = ""
+					result[i].ProvisionerLogs = nil
+					for j := range result[i].Events {
+						result[i].Events[j].LastTimestamp = result[i].Events[j].LastTimestamp.UTC()
+					}
+				}
+				for i := range tt.want {
+					tt.want[i].Age = ""
+				}
+				if !reflect.DeepEqual(result, tt.want) {
+					t.Errorf("unexpected pending PVCs:\nexpected %+v\nfound    %+v", tt.want, result)
+				}
+			})
+		})
+	}
+}
+
+func TestGetLinesMentioning(t *testing.T) {
+	logs := `I0101 "Started provisioning" PVC="default/data"
+I0101 "Started provisioning" PVC="default/data-0"
+E0101 failed to provision volume for claim "default/data": rpc error
+I0101 "Started provisioning" PVC="other/data"`
+
+	want := []string{
+		`I0101 "Started provisioning" PVC="default/data"`,
+		`E0101 failed to provision volume for claim "default/data": rpc error`,
+	}
+	if lines := getLinesMentioning(logs, "default/data", 10); !reflect.DeepEqual(lines, want) {
+		t.Errorf("unexpected lines:\nexpected %v\nfound    %v", want, lines)
+	}
+
+	if lines := getLinesMentioning(logs, "default/data", 1); !reflect.DeepEqual(lines, want[1:]) {
+		t.Errorf("expected only the last line, found %v", lines)
+	}
+}
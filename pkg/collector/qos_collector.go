@@ -0,0 +1,199 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metrics "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// The order in which the kubelet considers pods of each QoS class for eviction under memory pressure.
+var qosEvictionOrder = map[corev1.PodQOSClass]int{
+	corev1.PodQOSBestEffort: 0,
+	corev1.PodQOSBurstable:  1,
+	corev1.PodQOSGuaranteed: 2,
+}
+
+type QoSRanking struct {
+	Node string `json:"node"`
+	// MetricsError is set if pod memory usage couldn't be read (e.g. because metrics-server isn't running), in which
+	// case pods are ranked by QoS class and memory request only.
+	MetricsError string            `json:"metricsError,omitempty"`
+	Pods         []PodEvictionRisk `json:"pods"`
+}
+
+// PodEvictionRisk describes a pod on the node, with a rank of 1 for the pod most likely to be evicted first.
+type PodEvictionRisk struct {
+	Rank               int    `json:"rank"`
+	Namespace          string `json:"namespace"`
+	Name               string `json:"name"`
+	QoSClass           string `json:"qosClass"`
+	Priority           *int32 `json:"priority,omitempty"`
+	MemoryRequestBytes int64  `json:"memoryRequestBytes"`
+	// MemoryLimitBytes is only set if every container has a memory limit.
+	MemoryLimitBytes        int64   `json:"memoryLimitBytes,omitempty"`
+	MemoryUsageBytes        *int64  `json:"memoryUsageBytes,omitempty"`
+	MemoryAboveRequestBytes int64   `json:"memoryAboveRequestBytes"`
+	UsageToRequestRatio     float64 `json:"usageToRequestRatio,omitempty"`
+}
+
+// QoSCollector defines a QoS Collector struct
+type QoSCollector struct {
+	data          map[string]string
+	clientset     kubernetes.Interface
+	metricsClient metrics.Interface
+	runtimeInfo   *utils.RuntimeInfo
+}
+
+// NewQoSCollector is a constructor
+func NewQoSCollector(clientset kubernetes.Interface, metricsClient metrics.Interface, runtimeInfo *utils.RuntimeInfo) *QoSCollector {
+	return &QoSCollector{
+		data:          make(map[string]string),
+		clientset:     clientset,
+		metricsClient: metricsClient,
+		runtimeInfo:   runtimeInfo,
+	}
+}
+
+func (collector *QoSCollector) GetName() string {
+	return "qos"
+}
+
+func (collector *QoSCollector) CheckSupported() error {
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *QoSCollector) Collect() error {
+	ctx := context.Background()
+	var pods *corev1.PodList
+	err := utils.RetryAPICall(func() (err error) {
+		pods, err = collector.clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+			FieldSelector: "spec.nodeName=" + collector.runtimeInfo.HostNodeName,
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list pods: %w", err)
+	}
+
+	ranking := &QoSRanking{
+		Node: collector.runtimeInfo.HostNodeName,
+		Pods: []PodEvictionRisk{},
+	}
+
+	// Pod metrics can't be selected by node, so those of all pods are listed once rather than fetching each in turn.
+	usage := map[string]int64{}
+	var podMetrics *metricsv1beta1.PodMetricsList
+	err = utils.RetryAPICall(func() (err error) {
+		podMetrics, err = collector.metricsClient.MetricsV1beta1().PodMetricses(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		ranking.MetricsError = fmt.Sprintf("unable to list pod metrics: %v", err)
+	} else {
+		for _, podMetric := range podMetrics.Items {
+			var podUsage int64
+			for _, container := range podMetric.Containers {
+				podUsage += container.Usage.Memory().Value()
+			}
+			usage[podMetric.Namespace+"/"+podMetric.Name] = podUsage
+		}
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName != collector.runtimeInfo.HostNodeName || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+
+		risk := getPodEvictionRisk(pod)
+		if podUsage, ok := usage[pod.Namespace+"/"+pod.Name]; ok {
+			risk.MemoryUsageBytes = &podUsage
+			if podUsage > risk.MemoryRequestBytes {
+				risk.MemoryAboveRequestBytes = podUsage - risk.MemoryRequestBytes
+			}
+			if risk.MemoryRequestBytes > 0 {
+				risk.UsageToRequestRatio = float64(podUsage) / float64(risk.MemoryRequestBytes)
+			}
+		}
+
+		ranking.Pods = append(ranking.Pods, risk)
+	}
+
+	// Within each QoS class, the pods using the most memory above their request are evicted first.
+	sort.SliceStable(ranking.Pods, func(i, j int) bool {
+		a, b := ranking.Pods[i], ranking.Pods[j]
+		if a.QoSClass != b.QoSClass {
+			return qosEvictionOrder[corev1.PodQOSClass(a.QoSClass)] < qosEvictionOrder[corev1.PodQOSClass(b.QoSClass)]
+		}
+		if a.MemoryAboveRequestBytes != b.MemoryAboveRequestBytes {
+			return a.MemoryAboveRequestBytes > b.MemoryAboveRequestBytes
+		}
+		return a.Namespace+"/"+a.Name < b.Namespace+"/"+b.Name
+	})
+	for i := range ranking.Pods {
+		ranking.Pods[i].Rank = i + 1
+	}
+
+	data, err := json.Marshal(ranking)
+	if err != nil {
+		return fmt.Errorf("marshall QoS ranking to json: %w", err)
+	}
+
+	collector.data["qos-ranking"] = string(data)
+
+	return nil
+}
+
+// getPodEvictionRisk sums the memory requests and limits of the containers of a pod. Pods without any requests or
+// limits are BestEffort.
+func getPodEvictionRisk(pod *corev1.Pod) PodEvictionRisk {
+	risk := PodEvictionRisk{
+		Namespace: pod.Namespace,
+		Name:      pod.Name,
+		QoSClass:  string(getPodQOSClass(pod)),
+		Priority:  pod.Spec.Priority,
+	}
+
+	allLimited := len(pod.Spec.Containers) > 0
+	for _, container := range pod.Spec.Containers {
+		// The request defaults to the limit if only the limit is set.
+		request, hasRequest := container.Resources.Requests[corev1.ResourceMemory]
+		limit, hasLimit := container.Resources.Limits[corev1.ResourceMemory]
+		if hasRequest {
+			risk.MemoryRequestBytes += request.Value()
+		} else if hasLimit {
+			risk.MemoryRequestBytes += limit.Value()
+		}
+
+		if hasLimit {
+			risk.MemoryLimitBytes += limit.Value()
+		} else {
+			allLimited = false
+		}
+	}
+
+	if !allLimited {
+		risk.MemoryLimitBytes = 0
+	}
+
+	return risk
+}
+
+func (collector *QoSCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
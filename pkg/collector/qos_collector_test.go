@@ -0,0 +1,177 @@
+package collector
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+func TestQoSCollectorGetName(t *testing.T) {
+	const expectedName = "qos"
+
+	c := NewQoSCollector(nil, nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestQoSCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewQoSCollector(nil, nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestQoSCollectorCollect(t *testing.T) {
+	const mi = 1024 * 1024
+
+	newPod := func(name, nodeName string, requests, limits corev1.ResourceList) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app"},
+			Spec: corev1.PodSpec{
+				NodeName: nodeName,
+				Containers: []corev1.Container{{
+					Name:      "main",
+					Resources: corev1.ResourceRequirements{Requests: requests, Limits: limits},
+				}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	memory := func(quantity string) corev1.ResourceList {
+		return corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(quantity)}
+	}
+	newPodMetrics := func(name, usage string) *metricsv1beta1.PodMetrics {
+		return &metricsv1beta1.PodMetrics{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app"},
+			Containers: []metricsv1beta1.ContainerMetrics{{Name: "main", Usage: memory(usage)}},
+		}
+	}
+
+	guaranteedResources := corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("1Gi")}
+	completed := newPod("completed", "node1", nil, nil)
+	completed.Status.Phase = corev1.PodSucceeded
+
+	clientset := fake.NewSimpleClientset(
+		newPod("guaranteed", "node1", guaranteedResources, guaranteedResources),
+		newPod("burstable-low", "node1", memory("200Mi"), nil),
+		newPod("burstable-high", "node1", memory("100Mi"), nil),
+		newPod("besteffort", "node1", nil, nil),
+		newPod("other-node", "node2", nil, nil),
+		completed,
+	)
+
+	podMetrics := []runtime.Object{
+		newPodMetrics("guaranteed", "500Mi"),
+		newPodMetrics("burstable-low", "150Mi"),
+		newPodMetrics("burstable-high", "300Mi"),
+		newPodMetrics("besteffort", "100Mi"),
+	}
+
+	int64Ptr := func(value int64) *int64 { return &value }
+
+	tests := []struct {
+		name       string
+		metricsErr error
+		want       QoSRanking
+	}{
+		{
+			name: "with metrics",
+			want: QoSRanking{
+				Node: "node1",
+				Pods: []PodEvictionRisk{
+					{Rank: 1, Namespace: "app", Name: "besteffort", QoSClass: "BestEffort", MemoryUsageBytes: int64Ptr(100 * mi), MemoryAboveRequestBytes: 100 * mi},
+					{Rank: 2, Namespace: "app", Name: "burstable-high", QoSClass: "Burstable", MemoryRequestBytes: 100 * mi, MemoryUsageBytes: int64Ptr(300 * mi), MemoryAboveRequestBytes: 200 * mi, UsageToRequestRatio: 3},
+					{Rank: 3, Namespace: "app", Name: "burstable-low", QoSClass: "Burstable", MemoryRequestBytes: 200 * mi, MemoryUsageBytes: int64Ptr(150 * mi), UsageToRequestRatio: 0.75},
+					{Rank: 4, Namespace: "app", Name: "guaranteed", QoSClass: "Guaranteed", MemoryRequestBytes: 1024 * mi, MemoryLimitBytes: 1024 * mi, MemoryUsageBytes: int64Ptr(500 * mi), UsageToRequestRatio: 500.0 / 1024},
+				},
+			},
+		},
+		{
+			name:       "without metrics",
+			metricsErr: errors.New("the server could not find the requested resource"),
+			want: QoSRanking{
+				Node:         "node1",
+				MetricsError: "unable to list pod metrics: the server could not find the requested resource",
+				Pods: []PodEvictionRisk{
+					{Rank: 1, Namespace: "app", Name: "besteffort", QoSClass: "BestEffort"},
+					{Rank: 2, Namespace: "app", Name: "burstable-high", QoSClass: "Burstable", MemoryRequestBytes: 100 * mi},
+					{Rank: 3, Namespace: "app", Name: "burstable-low", QoSClass: "Burstable", MemoryRequestBytes: 200 * mi},
+					{Rank: 4, Namespace: "app", Name: "guaranteed", QoSClass: "Guaranteed", MemoryRequestBytes: 1024 * mi, MemoryLimitBytes: 1024 * mi},
+				},
+			},
+		},
+	}
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+		HostNodeName:  "node1",
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The fake tracker would guess the resource name of pod metrics wrongly, so they are added explicitly.
+			metricsClient := metricsfake.NewSimpleClientset()
+			for _, object := range podMetrics {
+				if err := metricsClient.Tracker().Create(metricsv1beta1.SchemeGroupVersion.WithResource("pods"), object, "app"); err != nil {
+					t.Fatalf("unable to add pod metrics: %v", err)
+				}
+			}
+			if tt.metricsErr != nil {
+				metricsClient.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, tt.metricsErr
+				})
+			}
+
+			c := NewQoSCollector(clientset, metricsClient, runtimeInfo)
+			if err := c.Collect(); err != nil {
+				t.Fatalf("Collect() error = %v", err)
+			}
+
+			testDataValue(t, c.GetData()["qos-ranking"], func(raw string) {
+				var ranking QoSRanking
+				if err := json.Unmarshal([]byte(raw), &ranking); err != nil {
+					t.Fatalf("unmarshal QoS ranking: %v", err)
+				}
+				if !reflect.DeepEqual(ranking, tt.want) {
+					t.Errorf("unexpected QoS ranking:\nexpected %+v\nfound    %+v", tt.want, ranking)
+				}
+			})
+		})
+	}
+}
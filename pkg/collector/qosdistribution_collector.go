@@ -0,0 +1,148 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Number of BestEffort pods on a node at or above which the node is flagged. These are the first pods
+// to be evicted under node pressure.
+const highBestEffortPodCount = 10
+
+type NodeQoSDistribution struct {
+	Node               string  `json:"node"`
+	Guaranteed         int     `json:"guaranteed"`
+	Burstable          int     `json:"burstable"`
+	BestEffort         int     `json:"bestEffort"`
+	BestEffortFraction float64 `json:"bestEffortFraction"`
+	HighBestEffortRisk bool    `json:"highBestEffortRisk"`
+}
+
+// QoSDistributionCollector defines a QoS Distribution Collector struct
+type QoSDistributionCollector struct {
+	data        map[string]string
+	clientset   kubernetes.Interface
+	runtimeInfo *utils.RuntimeInfo
+}
+
+// NewQoSDistributionCollector is a constructor
+func NewQoSDistributionCollector(clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *QoSDistributionCollector {
+	return &QoSDistributionCollector{
+		data:        make(map[string]string),
+		clientset:   clientset,
+		runtimeInfo: runtimeInfo,
+	}
+}
+
+func (collector *QoSDistributionCollector) GetName() string {
+	return "qosdistribution"
+}
+
+func (collector *QoSDistributionCollector) CheckSupported() error {
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *QoSDistributionCollector) Collect() error {
+	podList, err := collector.clientset.CoreV1().Pods(metav1.NamespaceAll).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list pods: %w", err)
+	}
+
+	nodes := map[string]*NodeQoSDistribution{}
+	for _, pod := range podList.Items {
+		// Only pods occupying a node are relevant to eviction.
+		if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+
+		node, ok := nodes[pod.Spec.NodeName]
+		if !ok {
+			node = &NodeQoSDistribution{Node: pod.Spec.NodeName}
+			nodes[pod.Spec.NodeName] = node
+		}
+
+		switch getPodQOSClass(&pod) {
+		case corev1.PodQOSGuaranteed:
+			node.Guaranteed++
+		case corev1.PodQOSBurstable:
+			node.Burstable++
+		case corev1.PodQOSBestEffort:
+			node.BestEffort++
+		}
+	}
+
+	result := []NodeQoSDistribution{}
+	for _, node := range nodes {
+		node.BestEffortFraction = float64(node.BestEffort) / float64(node.Guaranteed+node.Burstable+node.BestEffort)
+		node.HighBestEffortRisk = node.BestEffort >= highBestEffortPodCount
+		result = append(result, *node)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Node < result[j].Node
+	})
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshall QoS distribution to json: %w", err)
+	}
+
+	collector.data["qosdistribution"] = string(data)
+
+	return nil
+}
+
+// getPodQOSClass returns the QoS class assigned by the API server, or, if that is not yet populated,
+// derives it from the container resources.
+// See: https://kubernetes.io/docs/concepts/workloads/pods/pod-qos/
+func getPodQOSClass(pod *corev1.Pod) corev1.PodQOSClass {
+	if pod.Status.QOSClass != "" {
+		return pod.Status.QOSClass
+	}
+
+	containers := append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+
+	hasResources := false
+	guaranteed := true
+	for _, container := range containers {
+		for _, resourceName := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			request, hasRequest := container.Resources.Requests[resourceName]
+			limit, hasLimit := container.Resources.Limits[resourceName]
+			if hasRequest || hasLimit {
+				hasResources = true
+			}
+
+			// Requests default to limits if unset.
+			if !hasLimit || (hasRequest && request.Cmp(limit) != 0) {
+				guaranteed = false
+			}
+		}
+	}
+
+	switch {
+	case !hasResources:
+		return corev1.PodQOSBestEffort
+	case guaranteed:
+		return corev1.PodQOSGuaranteed
+	default:
+		return corev1.PodQOSBurstable
+	}
+}
+
+func (collector *QoSDistributionCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
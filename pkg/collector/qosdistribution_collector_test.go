@@ -0,0 +1,123 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestQoSDistributionCollectorGetName(t *testing.T) {
+	const expectedName = "qosdistribution"
+
+	c := NewQoSDistributionCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestQoSDistributionCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewQoSDistributionCollector(nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestQoSDistributionCollectorCollect(t *testing.T) {
+	newPod := func(name, nodeName string, phase corev1.PodPhase, resources corev1.ResourceRequirements) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: corev1.PodSpec{
+				NodeName:   nodeName,
+				Containers: []corev1.Container{{Name: "app", Resources: resources}},
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	guaranteed := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("128Mi")},
+		Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("128Mi")},
+	}
+	burstable := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+	}
+	bestEffort := corev1.ResourceRequirements{}
+
+	objects := []runtime.Object{
+		newPod("guaranteed-1", "node1", corev1.PodRunning, guaranteed),
+		newPod("burstable-1", "node1", corev1.PodRunning, burstable),
+		newPod("burstable-2", "node1", corev1.PodRunning, burstable),
+		newPod("besteffort-1", "node1", corev1.PodRunning, bestEffort),
+		// Completed and unscheduled pods are excluded
+		newPod("completed", "node1", corev1.PodSucceeded, bestEffort),
+		newPod("pending", "", corev1.PodPending, bestEffort),
+	}
+	for i := 0; i < highBestEffortPodCount; i++ {
+		objects = append(objects, newPod(fmt.Sprintf("besteffort-node2-%d", i), "node2", corev1.PodRunning, bestEffort))
+	}
+	// The API server's QoS class takes precedence
+	assigned := newPod("assigned", "node2", corev1.PodRunning, bestEffort)
+	assigned.Status.QOSClass = corev1.PodQOSGuaranteed
+	objects = append(objects, assigned)
+
+	clientset := fake.NewSimpleClientset(objects...)
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+	}
+
+	c := NewQoSDistributionCollector(clientset, runtimeInfo)
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	testDataValue(t, c.GetData()["qosdistribution"], func(raw string) {
+		var result []NodeQoSDistribution
+		if err := json.Unmarshal([]byte(raw), &result); err != nil {
+			t.Fatalf("unmarshal GetData(): %v", err)
+		}
+
+		expected := []NodeQoSDistribution{
+			{Node: "node1", Guaranteed: 1, Burstable: 2, BestEffort: 1, BestEffortFraction: 0.25, HighBestEffortRisk: false},
+			{Node: "node2", Guaranteed: 1, Burstable: 0, BestEffort: highBestEffortPodCount, BestEffortFraction: float64(highBestEffortPodCount) / float64(highBestEffortPodCount+1), HighBestEffortRisk: true},
+		}
+		if len(result) != len(expected) {
+			t.Fatalf("expected %d nodes, found %s", len(expected), raw)
+		}
+		for i := range expected {
+			if result[i] != expected[i] {
+				t.Errorf("unexpected distribution: expected %+v, found %+v", expected[i], result[i])
+			}
+		}
+	})
+}
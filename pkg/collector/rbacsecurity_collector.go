@@ -0,0 +1,324 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const clusterAdminRoleName = "cluster-admin"
+
+// Verbs which allow the contents of secrets to be read.
+var secretsReadVerbs = []string{"get", "list", "watch", rbacv1.VerbAll}
+
+type RBACSummary struct {
+	Checks     []string                      `json:"checks"`
+	Namespaces map[string]*NamespaceRBACInfo `json:"namespaces"`
+	// ClusterRoles are not namespaced, so those granting secrets access apply to any namespace they are bound in.
+	SecretsAccessClusterRoles []SecretsAccessRole `json:"secretsAccessClusterRoles,omitempty"`
+}
+
+type NamespaceRBACInfo struct {
+	ClusterAdminServiceAccounts []ClusterAdminServiceAccount `json:"clusterAdminServiceAccounts,omitempty"`
+	PrivilegedPods              []PrivilegedPod              `json:"privilegedPods,omitempty"`
+	HostNamespacePods           []HostNamespacePod           `json:"hostNamespacePods,omitempty"`
+	SecretsAccessRoles          []SecretsAccessRole          `json:"secretsAccessRoles,omitempty"`
+}
+
+type ClusterAdminServiceAccount struct {
+	Name string `json:"name"`
+	// Binding is the ClusterRoleBinding, or the RoleBinding (scoping cluster-admin to its namespace) granting the role.
+	Binding     string `json:"binding"`
+	BindingKind string `json:"bindingKind"`
+}
+
+type PrivilegedPod struct {
+	Name       string   `json:"name"`
+	Containers []string `json:"containers"`
+}
+
+type HostNamespacePod struct {
+	Name        string `json:"name"`
+	HostNetwork bool   `json:"hostNetwork"`
+	HostPID     bool   `json:"hostPID"`
+	HostIPC     bool   `json:"hostIPC"`
+}
+
+type SecretsAccessRole struct {
+	Name  string   `json:"name"`
+	Verbs []string `json:"verbs"`
+}
+
+// RBACSecurityCollector defines a RBAC Security Collector struct
+type RBACSecurityCollector struct {
+	data        map[string]string
+	clientset   kubernetes.Interface
+	runtimeInfo *utils.RuntimeInfo
+}
+
+// NewRBACSecurityCollector is a constructor
+func NewRBACSecurityCollector(clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *RBACSecurityCollector {
+	return &RBACSecurityCollector{
+		data:        make(map[string]string),
+		clientset:   clientset,
+		runtimeInfo: runtimeInfo,
+	}
+}
+
+func (collector *RBACSecurityCollector) GetName() string {
+	return "rbacsecurity"
+}
+
+func (collector *RBACSecurityCollector) CheckSupported() error {
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *RBACSecurityCollector) Collect() error {
+	ctx := context.Background()
+	checks := collector.runtimeInfo.RBACChecks
+	summary := &RBACSummary{
+		Checks:     checks,
+		Namespaces: map[string]*NamespaceRBACInfo{},
+	}
+
+	if utils.Contains(checks, utils.RBACCheckClusterAdmin) {
+		if err := collector.collectClusterAdminServiceAccounts(ctx, summary); err != nil {
+			return err
+		}
+	}
+
+	if utils.Contains(checks, utils.RBACCheckPrivileged) || utils.Contains(checks, utils.RBACCheckHostNamespaces) {
+		if err := collector.collectPodSecurity(ctx, summary); err != nil {
+			return err
+		}
+	}
+
+	if utils.Contains(checks, utils.RBACCheckSecretsAccess) {
+		if err := collector.collectSecretsAccessRoles(ctx, summary); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("marshall RBAC summary to json: %w", err)
+	}
+
+	collector.data["rbac-summary"] = string(data)
+
+	return nil
+}
+
+func (collector *RBACSecurityCollector) collectClusterAdminServiceAccounts(ctx context.Context, summary *RBACSummary) error {
+	clusterRoleBindings, err := collector.clientset.RbacV1().ClusterRoleBindings().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list cluster role bindings: %w", err)
+	}
+
+	for _, binding := range clusterRoleBindings.Items {
+		if isClusterAdminRef(binding.RoleRef) {
+			addClusterAdminServiceAccounts(summary, binding.Subjects, "", binding.Name, "ClusterRoleBinding")
+		}
+	}
+
+	roleBindings, err := collector.clientset.RbacV1().RoleBindings(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list role bindings: %w", err)
+	}
+
+	for _, binding := range roleBindings.Items {
+		if isClusterAdminRef(binding.RoleRef) {
+			addClusterAdminServiceAccounts(summary, binding.Subjects, binding.Namespace, binding.Name, "RoleBinding")
+		}
+	}
+
+	for _, info := range summary.Namespaces {
+		sort.Slice(info.ClusterAdminServiceAccounts, func(i, j int) bool {
+			a, b := info.ClusterAdminServiceAccounts[i], info.ClusterAdminServiceAccounts[j]
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+			return a.Binding < b.Binding
+		})
+	}
+
+	return nil
+}
+
+func isClusterAdminRef(roleRef rbacv1.RoleRef) bool {
+	return roleRef.Kind == "ClusterRole" && roleRef.Name == clusterAdminRoleName
+}
+
+// addClusterAdminServiceAccounts records the service account subjects of a binding under their own namespace, which
+// for a RoleBinding defaults to the namespace of the binding.
+func addClusterAdminServiceAccounts(summary *RBACSummary, subjects []rbacv1.Subject, bindingNamespace, bindingName, bindingKind string) {
+	for _, subject := range subjects {
+		if subject.Kind != rbacv1.ServiceAccountKind {
+			continue
+		}
+
+		namespace := subject.Namespace
+		if len(namespace) == 0 {
+			namespace = bindingNamespace
+		}
+
+		info := summary.getNamespace(namespace)
+		info.ClusterAdminServiceAccounts = append(info.ClusterAdminServiceAccounts, ClusterAdminServiceAccount{
+			Name:        subject.Name,
+			Binding:     bindingName,
+			BindingKind: bindingKind,
+		})
+	}
+}
+
+func (collector *RBACSecurityCollector) collectPodSecurity(ctx context.Context, summary *RBACSummary) error {
+	checkPrivileged := utils.Contains(summary.Checks, utils.RBACCheckPrivileged)
+	checkHostNamespaces := utils.Contains(summary.Checks, utils.RBACCheckHostNamespaces)
+
+	err := utils.ListAllPages(metav1.ListOptions{}, func(listOptions metav1.ListOptions) (string, error) {
+		podList, err := collector.clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, listOptions)
+		if err != nil {
+			return "", err
+		}
+
+		for i := range podList.Items {
+			pod := &podList.Items[i]
+
+			if checkPrivileged {
+				if containers := getPrivilegedContainers(pod); len(containers) > 0 {
+					info := summary.getNamespace(pod.Namespace)
+					info.PrivilegedPods = append(info.PrivilegedPods, PrivilegedPod{Name: pod.Name, Containers: containers})
+				}
+			}
+
+			if checkHostNamespaces && (pod.Spec.HostNetwork || pod.Spec.HostPID || pod.Spec.HostIPC) {
+				info := summary.getNamespace(pod.Namespace)
+				info.HostNamespacePods = append(info.HostNamespacePods, HostNamespacePod{
+					Name:        pod.Name,
+					HostNetwork: pod.Spec.HostNetwork,
+					HostPID:     pod.Spec.HostPID,
+					HostIPC:     pod.Spec.HostIPC,
+				})
+			}
+		}
+
+		return podList.Continue, nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list pods: %w", err)
+	}
+
+	for _, info := range summary.Namespaces {
+		sort.Slice(info.PrivilegedPods, func(i, j int) bool { return info.PrivilegedPods[i].Name < info.PrivilegedPods[j].Name })
+		sort.Slice(info.HostNamespacePods, func(i, j int) bool { return info.HostNamespacePods[i].Name < info.HostNamespacePods[j].Name })
+	}
+
+	return nil
+}
+
+// getPrivilegedContainers returns the names of the pod's containers, including init containers, which run privileged.
+func getPrivilegedContainers(pod *corev1.Pod) []string {
+	containers := []string{}
+	for _, specContainers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, container := range specContainers {
+			if container.SecurityContext != nil && container.SecurityContext.Privileged != nil && *container.SecurityContext.Privileged {
+				containers = append(containers, container.Name)
+			}
+		}
+	}
+
+	return containers
+}
+
+func (collector *RBACSecurityCollector) collectSecretsAccessRoles(ctx context.Context, summary *RBACSummary) error {
+	clusterRoles, err := collector.clientset.RbacV1().ClusterRoles().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list cluster roles: %w", err)
+	}
+
+	summary.SecretsAccessClusterRoles = []SecretsAccessRole{}
+	for _, role := range clusterRoles.Items {
+		if verbs := getSecretsReadVerbs(role.Rules); len(verbs) > 0 {
+			summary.SecretsAccessClusterRoles = append(summary.SecretsAccessClusterRoles, SecretsAccessRole{Name: role.Name, Verbs: verbs})
+		}
+	}
+	sort.Slice(summary.SecretsAccessClusterRoles, func(i, j int) bool {
+		return summary.SecretsAccessClusterRoles[i].Name < summary.SecretsAccessClusterRoles[j].Name
+	})
+
+	roles, err := collector.clientset.RbacV1().Roles(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list roles: %w", err)
+	}
+
+	for _, role := range roles.Items {
+		if verbs := getSecretsReadVerbs(role.Rules); len(verbs) > 0 {
+			info := summary.getNamespace(role.Namespace)
+			info.SecretsAccessRoles = append(info.SecretsAccessRoles, SecretsAccessRole{Name: role.Name, Verbs: verbs})
+		}
+	}
+
+	for _, info := range summary.Namespaces {
+		sort.Slice(info.SecretsAccessRoles, func(i, j int) bool { return info.SecretsAccessRoles[i].Name < info.SecretsAccessRoles[j].Name })
+	}
+
+	return nil
+}
+
+// getSecretsReadVerbs returns the verbs allowing secrets to be read that are granted by any of the rules, including
+// through wildcards. Rules restricted to named secrets still count, since those secrets are readable.
+func getSecretsReadVerbs(rules []rbacv1.PolicyRule) []string {
+	verbs := []string{}
+	for _, rule := range rules {
+		if !containsAny(rule.APIGroups, "", rbacv1.APIGroupAll) || !containsAny(rule.Resources, "secrets", rbacv1.ResourceAll) {
+			continue
+		}
+
+		for _, verb := range rule.Verbs {
+			if utils.Contains(secretsReadVerbs, verb) && !utils.Contains(verbs, verb) {
+				verbs = append(verbs, verb)
+			}
+		}
+	}
+
+	sort.Strings(verbs)
+	return verbs
+}
+
+func containsAny(values []string, candidates ...string) bool {
+	for _, candidate := range candidates {
+		if utils.Contains(values, candidate) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (summary *RBACSummary) getNamespace(namespace string) *NamespaceRBACInfo {
+	info, ok := summary.Namespaces[namespace]
+	if !ok {
+		info = &NamespaceRBACInfo{}
+		summary.Namespaces[namespace] = info
+	}
+
+	return info
+}
+
+func (collector *RBACSecurityCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
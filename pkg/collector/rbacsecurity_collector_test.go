@@ -0,0 +1,173 @@
+package collector
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRBACSecurityCollectorGetName(t *testing.T) {
+	const expectedName = "rbacsecurity"
+
+	c := NewRBACSecurityCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestRBACSecurityCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewRBACSecurityCollector(nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRBACSecurityCollectorCollect(t *testing.T) {
+	privileged := true
+	clusterAdmin := rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "cluster-admin"}
+
+	clientset := fake.NewSimpleClientset(
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "ci-admin"},
+			RoleRef:    clusterAdmin,
+			Subjects: []rbacv1.Subject{
+				{Kind: rbacv1.ServiceAccountKind, Name: "deployer", Namespace: "ci"},
+				{Kind: rbacv1.GroupKind, Name: "system:masters"},
+			},
+		},
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "view-all"},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "view"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: "viewer", Namespace: "ci"}},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "app-admin", Namespace: "app"},
+			RoleRef:    clusterAdmin,
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: "operator"}},
+		},
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "secret-reader"},
+			Rules:      []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"list", "get", "create"}}},
+		},
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-reader"},
+			Rules:      []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}},
+		},
+		&rbacv1.Role{
+			ObjectMeta: metav1.ObjectMeta{Name: "everything", Namespace: "app"},
+			Rules:      []rbacv1.PolicyRule{{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}},
+		},
+		&rbacv1.Role{
+			ObjectMeta: metav1.ObjectMeta{Name: "secret-writer", Namespace: "app"},
+			Rules:      []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"create", "update"}}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "csi-node", Namespace: "kube-system"},
+			Spec: corev1.PodSpec{
+				HostNetwork:    true,
+				InitContainers: []corev1.Container{{Name: "init", SecurityContext: &corev1.SecurityContext{Privileged: &privileged}}},
+				Containers:     []corev1.Container{{Name: "driver", SecurityContext: &corev1.SecurityContext{Privileged: &privileged}}, {Name: "registrar"}},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "debugger", Namespace: "app"},
+			Spec:       corev1.PodSpec{HostPID: true, Containers: []corev1.Container{{Name: "shell"}}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx"}}},
+		},
+	)
+
+	tests := []struct {
+		name   string
+		checks []string
+		want   RBACSummary
+	}{
+		{
+			name:   "all checks",
+			checks: []string{utils.RBACCheckClusterAdmin, utils.RBACCheckHostNamespaces, utils.RBACCheckPrivileged, utils.RBACCheckSecretsAccess},
+			want: RBACSummary{
+				Checks: []string{utils.RBACCheckClusterAdmin, utils.RBACCheckHostNamespaces, utils.RBACCheckPrivileged, utils.RBACCheckSecretsAccess},
+				Namespaces: map[string]*NamespaceRBACInfo{
+					"ci": {
+						ClusterAdminServiceAccounts: []ClusterAdminServiceAccount{{Name: "deployer", Binding: "ci-admin", BindingKind: "ClusterRoleBinding"}},
+					},
+					"app": {
+						ClusterAdminServiceAccounts: []ClusterAdminServiceAccount{{Name: "operator", Binding: "app-admin", BindingKind: "RoleBinding"}},
+						HostNamespacePods:           []HostNamespacePod{{Name: "debugger", HostPID: true}},
+						SecretsAccessRoles:          []SecretsAccessRole{{Name: "everything", Verbs: []string{"*"}}},
+					},
+					"kube-system": {
+						PrivilegedPods:    []PrivilegedPod{{Name: "csi-node", Containers: []string{"init", "driver"}}},
+						HostNamespacePods: []HostNamespacePod{{Name: "csi-node", HostNetwork: true}},
+					},
+				},
+				SecretsAccessClusterRoles: []SecretsAccessRole{{Name: "secret-reader", Verbs: []string{"get", "list"}}},
+			},
+		},
+		{
+			name:   "privileged only",
+			checks: []string{utils.RBACCheckPrivileged},
+			want: RBACSummary{
+				Checks: []string{utils.RBACCheckPrivileged},
+				Namespaces: map[string]*NamespaceRBACInfo{
+					"kube-system": {
+						PrivilegedPods: []PrivilegedPod{{Name: "csi-node", Containers: []string{"init", "driver"}}},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runtimeInfo := &utils.RuntimeInfo{RBACChecks: tt.checks}
+			c := NewRBACSecurityCollector(clientset, runtimeInfo)
+			if err := c.Collect(); err != nil {
+				t.Fatalf("Collect() error = %v", err)
+			}
+
+			var summary RBACSummary
+			if err := json.Unmarshal([]byte(c.data["rbac-summary"]), &summary); err != nil {
+				t.Fatalf("unable to unmarshal summary: %v", err)
+			}
+
+			if !reflect.DeepEqual(summary, tt.want) {
+				got, _ := json.Marshal(summary)
+				want, _ := json.Marshal(tt.want)
+				t.Errorf("unexpected summary:\n%s\nexpected:\n%s", got, want)
+			}
+		})
+	}
+}
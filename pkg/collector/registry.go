@@ -0,0 +1,180 @@
+package collector
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+// Values of COLLECTOR_LIST which change the behavior of collectors, rather than naming a collector to run. Like
+// collector names, they are matched case-insensitively.
+var collectorListFlags = []string{"connectedCluster", "OSM", "SMI"}
+
+// Constructor creates a collector. It is only called for collectors which are selected to run.
+type Constructor func() interfaces.Collector
+
+// Registry maps collector names to their constructors, so that the names in COLLECTOR_LIST can be validated and
+// used to select the collectors to run.
+type Registry struct {
+	names        []string
+	constructors map[string]Constructor
+	dependencies map[string][]string
+}
+
+// NewRegistry is a constructor
+func NewRegistry() *Registry {
+	return &Registry{
+		names:        []string{},
+		constructors: map[string]Constructor{},
+		dependencies: map[string][]string{},
+	}
+}
+
+// Register adds a collector, which must return the same name from GetName. Collectors are created in the order
+// they are registered. A collector which uses the output of others names them as dependencies: they are selected
+// whenever it is, and it only collects once they have completed (see DependencyTracker). Dependencies may be
+// registered later, but must be registered before collectors are created.
+func (registry *Registry) Register(name string, constructor Constructor, dependencies ...string) {
+	if _, ok := registry.constructors[name]; ok {
+		panic(fmt.Sprintf("collector %s is already registered", name))
+	}
+
+	registry.names = append(registry.names, name)
+	registry.constructors[name] = constructor
+	registry.dependencies[name] = dependencies
+}
+
+// Dependencies returns the names of the collectors the named collector depends on.
+func (registry *Registry) Dependencies(name string) []string {
+	return append([]string{}, registry.dependencies[name]...)
+}
+
+// Names returns the names of all registered collectors, in alphabetical order.
+func (registry *Registry) Names() []string {
+	names := append([]string{}, registry.names...)
+	sort.Strings(names)
+	return names
+}
+
+// ValidateCollectorList returns an error listing the valid values if any entry of the collector list is neither a
+// registered collector nor one of the special values (such as 'connectedCluster').
+func (registry *Registry) ValidateCollectorList(collectorList []string) error {
+	for _, entry := range collectorList {
+		if !utils.Contains(registry.names, entry) && !utils.Contains(collectorListFlags, entry) {
+			return fmt.Errorf("invalid COLLECTOR_LIST entry '%s': expected one of %s, or a collector name: %s", entry, strings.Join(collectorListFlags, ", "), strings.Join(registry.Names(), ", "))
+		}
+	}
+
+	return nil
+}
+
+// Selected returns the names of the collectors to run, in registration order. If the collector list contains only
+// special values, all collectors are selected. Otherwise only the named collectors are, where 'OSM' and 'SMI' also
+// name the osm and smi collectors, along with the collectors they depend on.
+func (registry *Registry) Selected(collectorList []string) []string {
+	onlyFlags := true
+	for _, entry := range collectorList {
+		if !utils.Contains(collectorListFlags, entry) {
+			onlyFlags = false
+		}
+	}
+
+	if onlyFlags {
+		return append([]string{}, registry.names...)
+	}
+
+	included := map[string]bool{}
+	var include func(name string)
+	include = func(name string) {
+		if included[name] {
+			return
+		}
+		included[name] = true
+		for _, dependency := range registry.dependencies[name] {
+			include(dependency)
+		}
+	}
+
+	for _, name := range registry.names {
+		if utils.Contains(collectorList, name) {
+			include(name)
+		}
+	}
+
+	selected := []string{}
+	for _, name := range registry.names {
+		if included[name] {
+			selected = append(selected, name)
+		}
+	}
+
+	return selected
+}
+
+// Create constructs the named collectors, which must be registered, as must their dependencies.
+func (registry *Registry) Create(names []string) ([]interfaces.Collector, error) {
+	if err := registry.validateDependencies(names); err != nil {
+		return nil, err
+	}
+
+	collectors := []interfaces.Collector{}
+	for _, name := range names {
+		constructor, ok := registry.constructors[name]
+		if !ok {
+			return nil, fmt.Errorf("collector %s is not registered", name)
+		}
+
+		c := constructor()
+		if c.GetName() != name {
+			return nil, fmt.Errorf("collector %s is registered as %s", c.GetName(), name)
+		}
+		collectors = append(collectors, c)
+	}
+
+	return collectors, nil
+}
+
+// validateDependencies checks that the dependencies of the named collectors are registered, and that none depends on
+// itself, directly or indirectly, which would leave it waiting forever.
+func (registry *Registry) validateDependencies(names []string) error {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+
+	states := map[string]int{}
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		path = append(path, name)
+		switch states[name] {
+		case visiting:
+			return fmt.Errorf("collector dependency cycle: %s", strings.Join(path, " -> "))
+		case visited:
+			return nil
+		}
+
+		states[name] = visiting
+		for _, dependency := range registry.dependencies[name] {
+			if _, ok := registry.constructors[dependency]; !ok {
+				return fmt.Errorf("collector %s depends on %s, which is not registered", name, dependency)
+			}
+			if err := visit(dependency, path); err != nil {
+				return err
+			}
+		}
+		states[name] = visited
+
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name, []string{}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package collector
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+func newTestRegistry() *Registry {
+	registry := NewRegistry()
+	registry.Register("networkoutbound", func() interfaces.Collector { return NewNetworkOutboundCollector() })
+	registry.Register("azurecni", func() interfaces.Collector { return NewAzureCNICollector(nil, nil) })
+	registry.Register("cgroup", func() interfaces.Collector { return NewCgroupCollector(utils.Linux, nil, nil) })
+	return registry
+}
+
+func TestRegistryValidateCollectorList(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "empty",
+			collectorList: []string{},
+			wantErr:       false,
+		},
+		{
+			name:          "special values",
+			collectorList: []string{"connectedCluster", "OSM", "SMI"},
+			wantErr:       false,
+		},
+		{
+			name:          "collector names",
+			collectorList: []string{"azurecni", "connectedCluster"},
+			wantErr:       false,
+		},
+		{
+			name:          "unknown name",
+			collectorList: []string{"azurecni", "azure-cni"},
+			wantErr:       true,
+		},
+		{
+			name:          "case-insensitive",
+			collectorList: []string{"connectedcluster", "AzureCNI"},
+			wantErr:       false,
+		},
+	}
+
+	registry := newTestRegistry()
+	for _, tt := range tests {
+		err := registry.ValidateCollectorList(tt.collectorList)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+
+		// The error lists the valid values, so that a typo can be corrected.
+		if err != nil && !strings.Contains(err.Error(), "azurecni, cgroup, networkoutbound") {
+			t.Errorf("%s: expected error to list collector names, found %v", tt.name, err)
+		}
+	}
+}
+
+func TestRegistrySelected(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		want          []string
+	}{
+		{
+			name:          "all by default",
+			collectorList: []string{},
+			want:          []string{"networkoutbound", "azurecni", "cgroup", "osm", "dmesg"},
+		},
+		{
+			name:          "special values only",
+			collectorList: []string{"connectedCluster", "OSM"},
+			want:          []string{"networkoutbound", "azurecni", "cgroup", "osm", "dmesg"},
+		},
+		{
+			name:          "special value naming a collector",
+			collectorList: []string{"OSM", "azurecni"},
+			want:          []string{"azurecni", "osm"},
+		},
+		{
+			name:          "named collectors in registration order",
+			collectorList: []string{"cgroup", "connectedCluster", "networkoutbound"},
+			want:          []string{"networkoutbound", "cgroup"},
+		},
+		{
+			name:          "dependencies of named collectors",
+			collectorList: []string{"dmesg"},
+			want:          []string{"azurecni", "osm", "dmesg"},
+		},
+	}
+
+	registry := newTestRegistry()
+	registry.Register("osm", func() interfaces.Collector { return NewOsmCollector(nil, nil) }, "azurecni")
+	registry.Register("dmesg", func() interfaces.Collector { return NewDmesgCollector(utils.Linux, nil, nil, nil, nil) }, "osm")
+	for _, tt := range tests {
+		selected := registry.Selected(tt.collectorList)
+		if !reflect.DeepEqual(selected, tt.want) {
+			t.Errorf("%s: expected %v, found %v", tt.name, tt.want, selected)
+		}
+	}
+}
+
+func TestRegistryCreateWithDependencies(t *testing.T) {
+	tests := []struct {
+		name         string
+		dependencies map[string][]string
+		create       []string
+		wantErr      bool
+	}{
+		{
+			name:         "registered dependency",
+			dependencies: map[string][]string{"cgroup": {"azurecni"}},
+			create:       []string{"azurecni", "cgroup"},
+			wantErr:      false,
+		},
+		{
+			name:         "unregistered dependency",
+			dependencies: map[string][]string{"cgroup": {"dmesg"}},
+			create:       []string{"cgroup"},
+			wantErr:      true,
+		},
+		{
+			name:         "dependency on itself",
+			dependencies: map[string][]string{"cgroup": {"cgroup"}},
+			create:       []string{"cgroup"},
+			wantErr:      true,
+		},
+		{
+			name:         "indirect cycle",
+			dependencies: map[string][]string{"cgroup": {"azurecni"}, "azurecni": {"networkoutbound"}, "networkoutbound": {"cgroup"}},
+			create:       []string{"networkoutbound"},
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		registry := NewRegistry()
+		registry.Register("networkoutbound", func() interfaces.Collector { return NewNetworkOutboundCollector() }, tt.dependencies["networkoutbound"]...)
+		registry.Register("azurecni", func() interfaces.Collector { return NewAzureCNICollector(nil, nil) }, tt.dependencies["azurecni"]...)
+		registry.Register("cgroup", func() interfaces.Collector { return NewCgroupCollector(utils.Linux, nil, nil) }, tt.dependencies["cgroup"]...)
+
+		_, err := registry.Create(tt.create)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRegistryCreate(t *testing.T) {
+	registry := newTestRegistry()
+	collectors, err := registry.Create([]string{"azurecni", "cgroup"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if len(collectors) != 2 || collectors[0].GetName() != "azurecni" || collectors[1].GetName() != "cgroup" {
+		t.Errorf("unexpected collectors %v", collectors)
+	}
+
+	if _, err := registry.Create([]string{"missing"}); err == nil {
+		t.Errorf("expected error creating unregistered collector")
+	}
+
+	// A collector registered under the wrong name couldn't be selected by its own name.
+	registry.Register("dmesg", func() interfaces.Collector { return NewNetworkOutboundCollector() })
+	if _, err := registry.Create([]string{"dmesg"}); err == nil {
+		t.Errorf("expected error creating collector registered under the wrong name")
+	}
+}
@@ -0,0 +1,215 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+// Route types which drop traffic (or, for throw, end the lookup in this table) rather than forwarding it.
+// See `man ip-route`.
+var droppingRouteTypes = []string{"blackhole", "unreachable", "prohibit", "throw"}
+
+// Route types which can't carry traffic to a remote host.
+var nonForwardingRouteTypes = []string{"local", "broadcast", "multicast", "anycast", "nat"}
+
+type Route struct {
+	Type        string `json:"type"`
+	Destination string `json:"destination"`
+	Via         string `json:"via,omitempty"`
+	Dev         string `json:"dev,omitempty"`
+	Table       string `json:"table,omitempty"`
+	Metric      string `json:"metric,omitempty"`
+	Raw         string `json:"raw"`
+
+	network *net.IPNet
+}
+
+type APIServerRouteResult struct {
+	IP         string `json:"ip"`
+	Route      *Route `json:"route,omitempty"`
+	Suspicious bool   `json:"suspicious"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+type RouteValidation struct {
+	APIServerHost    string                 `json:"apiServerHost"`
+	DefaultGateway   string                 `json:"defaultGateway,omitempty"`
+	APIServerRoutes  []APIServerRouteResult `json:"apiServerRoutes"`
+	SuspiciousRoutes []Route                `json:"suspiciousRoutes"`
+}
+
+// RouteValidationCollector defines a Route Validation Collector struct
+type RouteValidationCollector struct {
+	data          map[string]string
+	osIdentifier  utils.OSIdentifier
+	apiServerHost string
+	runCommand    utils.HostCommandRunner
+}
+
+// NewRouteValidationCollector is a constructor. The API server host may be a URL or a host name/IP address.
+func NewRouteValidationCollector(osIdentifier utils.OSIdentifier, apiServerHost string, runCommand utils.HostCommandRunner) *RouteValidationCollector {
+	return &RouteValidationCollector{
+		data:          make(map[string]string),
+		osIdentifier:  osIdentifier,
+		apiServerHost: apiServerHost,
+		runCommand:    runCommand,
+	}
+}
+
+func (collector *RouteValidationCollector) GetName() string {
+	return "routevalidation"
+}
+
+func (collector *RouteValidationCollector) CheckSupported() error {
+	// This uses the `ip` command to read the host route table, which is not available on Windows.
+	if collector.osIdentifier != utils.Linux {
+		return fmt.Errorf("unsupported OS: %s", collector.osIdentifier)
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *RouteValidationCollector) Collect() error {
+	host := collector.apiServerHost
+	if u, err := url.Parse(host); err == nil && u.Hostname() != "" {
+		host = u.Hostname()
+	}
+
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return fmt.Errorf("unable to resolve API server host %s: %w", host, err)
+	}
+
+	output, err := collector.runCommand("ip", "-4", "route", "show", "table", "main")
+	if err != nil {
+		return fmt.Errorf("error reading route table: %w", err)
+	}
+
+	routes := parseRoutes(output)
+
+	validation := RouteValidation{
+		APIServerHost:    host,
+		APIServerRoutes:  []APIServerRouteResult{},
+		SuspiciousRoutes: []Route{},
+	}
+
+	for _, route := range routes {
+		if route.Destination == "default" && route.Type == "unicast" && validation.DefaultGateway == "" {
+			validation.DefaultGateway = route.Via
+		}
+		if utils.Contains(droppingRouteTypes, route.Type) {
+			validation.SuspiciousRoutes = append(validation.SuspiciousRoutes, *route)
+		}
+	}
+
+	for _, ip := range ips {
+		if ip.To4() == nil {
+			continue
+		}
+
+		result := APIServerRouteResult{IP: ip.String()}
+		route := matchRoute(routes, ip)
+		switch {
+		case route == nil:
+			result.Suspicious = true
+			result.Reason = "no route to the API server"
+		case utils.Contains(droppingRouteTypes, route.Type):
+			result.Suspicious = true
+			result.Reason = fmt.Sprintf("traffic to the API server is dropped by a %s route", route.Type)
+		case utils.Contains(nonForwardingRouteTypes, route.Type):
+			result.Suspicious = true
+			result.Reason = fmt.Sprintf("traffic to the API server matches a %s route", route.Type)
+		case route.Via != "" && route.Via != validation.DefaultGateway:
+			result.Suspicious = true
+			result.Reason = fmt.Sprintf("traffic to the API server is routed via %s rather than the default gateway %s", route.Via, validation.DefaultGateway)
+		}
+		result.Route = route
+
+		validation.APIServerRoutes = append(validation.APIServerRoutes, result)
+	}
+
+	data, err := json.Marshal(validation)
+	if err != nil {
+		return fmt.Errorf("marshall route validation to json: %w", err)
+	}
+
+	collector.data["routevalidation"] = string(data)
+
+	return nil
+}
+
+// parseRoutes parses the output of `ip route show`, e.g.
+// "default via 10.224.0.1 dev eth0 proto dhcp src 10.224.0.4 metric 100" or "blackhole 20.0.0.0/8".
+func parseRoutes(output string) []*Route {
+	routes := []*Route{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		route := &Route{Type: "unicast", Raw: strings.TrimSpace(line)}
+		if utils.Contains(droppingRouteTypes, fields[0]) || utils.Contains(nonForwardingRouteTypes, fields[0]) || fields[0] == "unicast" {
+			route.Type = fields[0]
+			fields = fields[1:]
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		route.Destination = fields[0]
+		for i := 1; i+1 < len(fields); i++ {
+			switch fields[i] {
+			case "via":
+				route.Via = fields[i+1]
+			case "dev":
+				route.Dev = fields[i+1]
+			case "table":
+				route.Table = fields[i+1]
+			case "metric":
+				route.Metric = fields[i+1]
+			}
+		}
+
+		destination := route.Destination
+		if destination == "default" {
+			destination = "0.0.0.0/0"
+		} else if !strings.Contains(destination, "/") {
+			destination += "/32"
+		}
+		if _, network, err := net.ParseCIDR(destination); err == nil {
+			route.network = network
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+// matchRoute returns the most specific route containing the IP address, taking the first listed (lowest metric)
+// where there are several with the same prefix length.
+func matchRoute(routes []*Route, ip net.IP) *Route {
+	var best *Route
+	bestPrefix := -1
+	for _, route := range routes {
+		if !route.network.Contains(ip) {
+			continue
+		}
+		prefix, _ := route.network.Mask.Size()
+		if prefix > bestPrefix {
+			best = route
+			bestPrefix = prefix
+		}
+	}
+	return best
+}
+
+func (collector *RouteValidationCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
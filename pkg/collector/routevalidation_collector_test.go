@@ -0,0 +1,128 @@
+package collector
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+func TestRouteValidationCollectorGetName(t *testing.T) {
+	const expectedName = "routevalidation"
+
+	c := NewRouteValidationCollector("", "", nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestRouteValidationCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		osIdentifier utils.OSIdentifier
+		wantErr      bool
+	}{
+		{
+			osIdentifier: utils.Windows,
+			wantErr:      true,
+		},
+		{
+			osIdentifier: utils.Linux,
+			wantErr:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		c := NewRouteValidationCollector(tt.osIdentifier, "", nil)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("CheckSupported() error = %v, wantErr %v", err, tt.wantErr)
+		}
+	}
+}
+
+func TestRouteValidationCollectorCollect(t *testing.T) {
+	const routeTable = `default via 10.224.0.1 dev eth0 proto dhcp src 10.224.0.4 metric 100
+10.224.0.0/16 dev eth0 proto kernel scope link src 10.224.0.4
+168.63.129.16 via 10.224.0.1 dev eth0 proto dhcp src 10.224.0.4 metric 100
+blackhole 20.0.0.0/8
+172.16.0.0/12 via 10.224.0.100 dev eth0
+`
+
+	tests := []struct {
+		name           string
+		apiServerHost  string
+		commandErr     error
+		wantErr        bool
+		wantSuspicious bool
+		wantRouteType  string
+	}{
+		{
+			name:           "blackholed API server",
+			apiServerHost:  "https://20.10.10.10:443",
+			wantSuspicious: true,
+			wantRouteType:  "blackhole",
+		},
+		{
+			name:           "API server via unexpected next-hop",
+			apiServerHost:  "172.20.0.10",
+			wantSuspicious: true,
+			wantRouteType:  "unicast",
+		},
+		{
+			name:           "API server via default gateway",
+			apiServerHost:  "https://52.10.10.10:443",
+			wantSuspicious: false,
+			wantRouteType:  "unicast",
+		},
+		{
+			name:          "route table unavailable",
+			apiServerHost: "52.10.10.10",
+			commandErr:    errors.New("ip not found"),
+			wantErr:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runCommand := func(command string, arg ...string) (string, error) {
+				return routeTable, tt.commandErr
+			}
+
+			c := NewRouteValidationCollector(utils.Linux, tt.apiServerHost, runCommand)
+			err := c.Collect()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Collect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			testDataValue(t, c.GetData()["routevalidation"], func(raw string) {
+				var validation RouteValidation
+				if err := json.Unmarshal([]byte(raw), &validation); err != nil {
+					t.Fatalf("unmarshal GetData(): %v", err)
+				}
+
+				if validation.DefaultGateway != "10.224.0.1" {
+					t.Errorf("unexpected default gateway %s", validation.DefaultGateway)
+				}
+				if len(validation.SuspiciousRoutes) != 1 || validation.SuspiciousRoutes[0].Destination != "20.0.0.0/8" {
+					t.Errorf("expected the blackhole route to be flagged, found %+v", validation.SuspiciousRoutes)
+				}
+
+				if len(validation.APIServerRoutes) != 1 {
+					t.Fatalf("expected 1 API server route, found %s", raw)
+				}
+				result := validation.APIServerRoutes[0]
+				if result.Suspicious != tt.wantSuspicious {
+					t.Errorf("expected suspicious=%t, found %+v", tt.wantSuspicious, result)
+				}
+				if result.Route == nil || result.Route.Type != tt.wantRouteType {
+					t.Errorf("expected %s route, found %+v", tt.wantRouteType, result.Route)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package collector
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+const scheduledEventsPath = "/metadata/scheduledevents?api-version=2020-07-01"
+
+// The name of this VM as it appears in the resources of scheduled events (e.g. "aks-nodepool1-12345678-vmss_0").
+const vmNamePath = "/metadata/instance/compute/name?api-version=2021-02-01&format=text"
+
+// How often scheduled events are polled within DIAGNOSTIC_SCHEDULED_EVENTS_WINDOW.
+const scheduledEventsPollInterval = 10 * time.Second
+
+// Scheduled event types which disrupt the node, where Freeze includes live migration. See:
+// https://learn.microsoft.com/en-us/azure/virtual-machines/linux/scheduled-events#event-properties
+var disruptiveScheduledEventTypes = []string{"Freeze", "Reboot", "Redeploy", "Preempt", "Terminate"}
+
+type scheduledEventsDocument struct {
+	DocumentIncarnation int                  `json:"DocumentIncarnation"`
+	Events              []imdsScheduledEvent `json:"Events"`
+}
+
+type imdsScheduledEvent struct {
+	EventId           string   `json:"EventId"`
+	EventType         string   `json:"EventType"`
+	EventStatus       string   `json:"EventStatus"`
+	ResourceType      string   `json:"ResourceType"`
+	Resources         []string `json:"Resources"`
+	NotBefore         string   `json:"NotBefore"`
+	Description       string   `json:"Description"`
+	EventSource       string   `json:"EventSource"`
+	DurationInSeconds int      `json:"DurationInSeconds"`
+}
+
+type ScheduledEvent struct {
+	EventId           string     `json:"eventId"`
+	EventType         string     `json:"eventType"`
+	EventStatus       string     `json:"eventStatus"`
+	Resources         []string   `json:"resources"`
+	NotBefore         *time.Time `json:"notBefore,omitempty"`
+	DurationInSeconds int        `json:"durationInSeconds"`
+	Description       string     `json:"description,omitempty"`
+	EventSource       string     `json:"eventSource,omitempty"`
+	FirstSeen         time.Time  `json:"firstSeen"`
+	LastSeen          time.Time  `json:"lastSeen"`
+}
+
+type ScheduledEventsInfo struct {
+	VMName              string           `json:"vmName,omitempty"`
+	DocumentIncarnation int              `json:"documentIncarnation"`
+	Polls               int              `json:"polls"`
+	Events              []ScheduledEvent `json:"events"`
+}
+
+// ScheduledEventsCollector defines an Azure Scheduled Events Collector struct
+type ScheduledEventsCollector struct {
+	data         map[string]string
+	runtimeInfo  *utils.RuntimeInfo
+	endpoint     string
+	httpClient   *http.Client
+	pollInterval time.Duration
+}
+
+// NewScheduledEventsCollector is a constructor
+func NewScheduledEventsCollector(runtimeInfo *utils.RuntimeInfo, endpoint string, httpClient *http.Client) *ScheduledEventsCollector {
+	return &ScheduledEventsCollector{
+		data:         make(map[string]string),
+		runtimeInfo:  runtimeInfo,
+		endpoint:     endpoint,
+		httpClient:   httpClient,
+		pollInterval: scheduledEventsPollInterval,
+	}
+}
+
+func (collector *ScheduledEventsCollector) GetName() string {
+	return "scheduledevents"
+}
+
+func (collector *ScheduledEventsCollector) CheckSupported() error {
+	// Connected clusters are not expected to be running on Azure VMs.
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *ScheduledEventsCollector) Collect() error {
+	// The events are only read, never acknowledged: approving an event would allow the platform to start it early.
+	document, err := collector.getScheduledEvents()
+	if err != nil {
+		if errors.Is(err, utils.ErrIMDSUnreachable) {
+			// Not running on an Azure VM (or IMDS is blocked), so there is nothing to collect.
+			log.Printf("Skipping scheduled events collection: %v", err)
+			return nil
+		}
+		return err
+	}
+
+	// Events for a scale set may affect other instances, so only those listing this VM are recorded.
+	// If the VM name can't be determined, all events are recorded rather than none.
+	vmName, err := utils.GetIMDSContent(collector.httpClient, collector.endpoint, vmNamePath)
+	if err != nil {
+		log.Printf("Unable to determine VM name, recording all scheduled events: %v", err)
+	}
+
+	info := ScheduledEventsInfo{
+		VMName: strings.TrimSpace(string(vmName)),
+		Events: []ScheduledEvent{},
+	}
+	eventIndexes := map[string]int{}
+
+	// Events may be scheduled and started within the window, so the endpoint is polled until it ends,
+	// keeping the latest state of each event.
+	deadline := time.Now().Add(collector.runtimeInfo.ScheduledEventsWindow)
+	for {
+		collector.recordEvents(&info, eventIndexes, document, time.Now().UTC())
+
+		if !time.Now().Add(collector.pollInterval).Before(deadline) {
+			break
+		}
+		time.Sleep(collector.pollInterval)
+
+		document, err = collector.getScheduledEvents()
+		if err != nil {
+			// Keep what has been seen so far if a later poll fails.
+			log.Printf("Stopping scheduled events polling: %v", err)
+			break
+		}
+	}
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("marshall scheduled events to json: %w", err)
+	}
+
+	collector.data["scheduled-events"] = string(data)
+
+	return nil
+}
+
+func (collector *ScheduledEventsCollector) getScheduledEvents() (*scheduledEventsDocument, error) {
+	content, err := utils.GetIMDSContent(collector.httpClient, collector.endpoint, scheduledEventsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var document scheduledEventsDocument
+	if err := json.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("error parsing scheduled events: %w", err)
+	}
+
+	return &document, nil
+}
+
+func (collector *ScheduledEventsCollector) recordEvents(info *ScheduledEventsInfo, eventIndexes map[string]int, document *scheduledEventsDocument, seen time.Time) {
+	info.Polls++
+	info.DocumentIncarnation = document.DocumentIncarnation
+
+	for _, event := range document.Events {
+		if !utils.Contains(disruptiveScheduledEventTypes, event.EventType) {
+			continue
+		}
+		if len(info.VMName) > 0 && !utils.Contains(event.Resources, info.VMName) {
+			continue
+		}
+
+		scheduledEvent := ScheduledEvent{
+			EventId:           event.EventId,
+			EventType:         event.EventType,
+			EventStatus:       event.EventStatus,
+			Resources:         event.Resources,
+			DurationInSeconds: event.DurationInSeconds,
+			Description:       event.Description,
+			EventSource:       event.EventSource,
+			FirstSeen:         seen,
+			LastSeen:          seen,
+		}
+
+		// NotBefore is empty once an event has started, so the time it was scheduled for is kept from earlier polls.
+		if notBefore, err := time.Parse(time.RFC1123, event.NotBefore); err == nil {
+			scheduledEvent.NotBefore = &notBefore
+		}
+
+		index, ok := eventIndexes[event.EventId]
+		if !ok {
+			eventIndexes[event.EventId] = len(info.Events)
+			info.Events = append(info.Events, scheduledEvent)
+			continue
+		}
+
+		previous := info.Events[index]
+		scheduledEvent.FirstSeen = previous.FirstSeen
+		if scheduledEvent.NotBefore == nil {
+			scheduledEvent.NotBefore = previous.NotBefore
+		}
+		info.Events[index] = scheduledEvent
+	}
+}
+
+func (collector *ScheduledEventsCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
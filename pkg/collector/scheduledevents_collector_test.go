@@ -0,0 +1,188 @@
+package collector
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+func TestScheduledEventsCollectorGetName(t *testing.T) {
+	const expectedName = "scheduledevents"
+
+	c := NewScheduledEventsCollector(nil, "", nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestScheduledEventsCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewScheduledEventsCollector(runtimeInfo, "", nil)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestScheduledEventsCollectorCollect(t *testing.T) {
+	const vmName = "aks-nodepool1-12345678-vmss_0"
+	const payload = `{
+		"DocumentIncarnation": 3,
+		"Events": [
+			{
+				"EventId": "602d9444-d2cd-49c7-8624-8643e7171297",
+				"EventType": "Reboot",
+				"ResourceType": "VirtualMachine",
+				"Resources": ["aks-nodepool1-12345678-vmss_0"],
+				"EventStatus": "Scheduled",
+				"NotBefore": "Mon, 19 Sep 2016 18:29:47 GMT",
+				"Description": "Virtual machine is going to be restarted as requested by authorized user.",
+				"EventSource": "User",
+				"DurationInSeconds": 15
+			},
+			{
+				"EventId": "5b4e1b0e-6f3b-4c5e-9d0a-1f8f2b0c6d7e",
+				"EventType": "Redeploy",
+				"ResourceType": "VirtualMachine",
+				"Resources": ["aks-nodepool1-12345678-vmss_1"],
+				"EventStatus": "Scheduled",
+				"NotBefore": "Mon, 19 Sep 2016 18:29:47 GMT",
+				"DurationInSeconds": -1
+			},
+			{
+				"EventId": "0e8d1d3a-5bd2-4f0e-8b48-0d0e7a4a7d3e",
+				"EventType": "Unknown",
+				"ResourceType": "VirtualMachine",
+				"Resources": ["aks-nodepool1-12345678-vmss_0"],
+				"EventStatus": "Scheduled",
+				"NotBefore": "",
+				"DurationInSeconds": -1
+			}
+		]
+	}`
+
+	// A live migration, which is scheduled on the first poll and has started by the second.
+	freezePayloads := []string{
+		`{"DocumentIncarnation": 1, "Events": [{"EventId": "a1b2c3d4", "EventType": "Freeze", "Resources": ["aks-nodepool1-12345678-vmss_0"], "EventStatus": "Scheduled", "NotBefore": "Tue, 20 Sep 2016 10:00:00 GMT", "EventSource": "Platform", "DurationInSeconds": 5}]}`,
+		`{"DocumentIncarnation": 2, "Events": [{"EventId": "a1b2c3d4", "EventType": "Freeze", "Resources": ["aks-nodepool1-12345678-vmss_0"], "EventStatus": "Started", "NotBefore": "", "EventSource": "Platform", "DurationInSeconds": 5}]}`,
+	}
+
+	newServer := func(getPayload func() string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Metadata") != "true" || r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			switch r.URL.Path {
+			case "/metadata/instance/compute/name":
+				w.Write([]byte(vmName))
+			case "/metadata/scheduledevents":
+				w.Write([]byte(getPayload()))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+	}
+
+	server := newServer(func() string { return payload })
+	defer server.Close()
+
+	polls := 0
+	pollingServer := newServer(func() string {
+		polls++
+		if polls > len(freezePayloads) {
+			return freezePayloads[len(freezePayloads)-1]
+		}
+		return freezePayloads[polls-1]
+	})
+	defer pollingServer.Close()
+
+	invalidServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("not json"))
+	}))
+	defer invalidServer.Close()
+
+	unreachableServer := httptest.NewServer(http.NotFoundHandler())
+	unreachableServer.Close()
+
+	tests := []struct {
+		name     string
+		endpoint string
+		window   time.Duration
+		wantErr  bool
+		wantData map[string]*regexp.Regexp
+	}{
+		{
+			name:     "pending reboot",
+			endpoint: server.URL,
+			wantErr:  false,
+			wantData: map[string]*regexp.Regexp{
+				"scheduled-events": regexp.MustCompile(`^{"vmName":"aks-nodepool1-12345678-vmss_0","documentIncarnation":3,"polls":1,"events":\[{"eventId":"602d9444-d2cd-49c7-8624-8643e7171297","eventType":"Reboot","eventStatus":"Scheduled","resources":\["aks-nodepool1-12345678-vmss_0"\],"notBefore":"2016-09-19T18:29:47Z","durationInSeconds":15,[^]]*}\]}$`),
+			},
+		},
+		{
+			name:     "live migration started within window",
+			endpoint: pollingServer.URL,
+			window:   250 * time.Millisecond,
+			wantErr:  false,
+			wantData: map[string]*regexp.Regexp{
+				"scheduled-events": regexp.MustCompile(`^{"vmName":"aks-nodepool1-12345678-vmss_0","documentIncarnation":2,"polls":[2-9],"events":\[{"eventId":"a1b2c3d4","eventType":"Freeze","eventStatus":"Started","resources":\["aks-nodepool1-12345678-vmss_0"\],"notBefore":"2016-09-20T10:00:00Z","durationInSeconds":5,"eventSource":"Platform","firstSeen":"[^"]+","lastSeen":"[^"]+"}\]}$`),
+			},
+		},
+		{
+			name:     "invalid payload",
+			endpoint: invalidServer.URL,
+			wantErr:  true,
+			wantData: map[string]*regexp.Regexp{},
+		},
+		{
+			name:     "IMDS unreachable",
+			endpoint: unreachableServer.URL,
+			wantErr:  false,
+			wantData: map[string]*regexp.Regexp{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runtimeInfo := &utils.RuntimeInfo{
+				CollectorList:         []string{},
+				ScheduledEventsWindow: tt.window,
+			}
+			c := NewScheduledEventsCollector(runtimeInfo, tt.endpoint, utils.NewIMDSClient(time.Second))
+			c.pollInterval = 100 * time.Millisecond
+			err := c.Collect()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Collect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			compareCollectorData(t, tt.wantData, c.GetData())
+		})
+	}
+}
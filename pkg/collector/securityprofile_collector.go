@@ -0,0 +1,244 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Deprecated annotations which may still be used to reference security profiles.
+const (
+	seccompPodAnnotation             = "seccomp.security.alpha.kubernetes.io/pod"
+	seccompContainerAnnotationPrefix = "container.seccomp.security.alpha.kubernetes.io/"
+	appArmorAnnotationPrefix         = "container.apparmor.security.beta.kubernetes.io/"
+	localhostProfilePrefix           = "localhost/"
+)
+
+type SecurityProfileStatus struct {
+	AppArmorEnabled        bool                     `json:"appArmorEnabled"`
+	AppArmorProfiles       []string                 `json:"appArmorProfiles"`
+	SELinuxEnabled         bool                     `json:"seLinuxEnabled"`
+	SELinuxEnforcing       bool                     `json:"seLinuxEnforcing"`
+	SeccompProfiles        []string                 `json:"seccompProfiles"`
+	MissingProfileRefs     []MissingSecurityProfile `json:"missingProfileReferences"`
+	UnavailableDataSources []string                 `json:"unavailableDataSources,omitempty"`
+}
+
+type MissingSecurityProfile struct {
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	Container string `json:"container,omitempty"`
+	Type      string `json:"type"`
+	Profile   string `json:"profile"`
+}
+
+// SecurityProfileCollector defines a Security Profile Collector struct
+type SecurityProfileCollector struct {
+	data         map[string]string
+	osIdentifier utils.OSIdentifier
+	filePaths    *utils.KnownFilePaths
+	fileSystem   interfaces.FileSystemAccessor
+	clientset    kubernetes.Interface
+	runtimeInfo  *utils.RuntimeInfo
+}
+
+// NewSecurityProfileCollector is a constructor
+func NewSecurityProfileCollector(osIdentifier utils.OSIdentifier, filePaths *utils.KnownFilePaths, fileSystem interfaces.FileSystemAccessor, clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *SecurityProfileCollector {
+	return &SecurityProfileCollector{
+		data:         make(map[string]string),
+		osIdentifier: osIdentifier,
+		filePaths:    filePaths,
+		fileSystem:   fileSystem,
+		clientset:    clientset,
+		runtimeInfo:  runtimeInfo,
+	}
+}
+
+func (collector *SecurityProfileCollector) GetName() string {
+	return "securityprofiles"
+}
+
+func (collector *SecurityProfileCollector) CheckSupported() error {
+	if collector.osIdentifier != utils.Linux {
+		return fmt.Errorf("unsupported OS: %s", collector.osIdentifier)
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *SecurityProfileCollector) Collect() error {
+	status := SecurityProfileStatus{
+		AppArmorProfiles:       []string{},
+		SeccompProfiles:        []string{},
+		MissingProfileRefs:     []MissingSecurityProfile{},
+		UnavailableDataSources: []string{},
+	}
+
+	// Each of these sources may legitimately be absent (e.g. SELinux is not present on Ubuntu nodes),
+	// so we record what we couldn't read rather than failing.
+	appArmorEnabled, err := collector.readFile(collector.filePaths.AppArmorEnabled)
+	if err != nil {
+		status.UnavailableDataSources = append(status.UnavailableDataSources, err.Error())
+	}
+	status.AppArmorEnabled = strings.TrimSpace(appArmorEnabled) == "Y"
+
+	appArmorProfiles := map[string]bool{}
+	if status.AppArmorEnabled {
+		content, err := collector.readFile(collector.filePaths.AppArmorProfiles)
+		if err != nil {
+			status.UnavailableDataSources = append(status.UnavailableDataSources, err.Error())
+		}
+		// Each line is of the form `<profile name> (<mode>)`
+		for _, line := range strings.Split(content, "\n") {
+			line = strings.TrimSpace(line)
+			if len(line) == 0 {
+				continue
+			}
+			status.AppArmorProfiles = append(status.AppArmorProfiles, line)
+			if index := strings.LastIndex(line, " ("); index > 0 {
+				line = line[:index]
+			}
+			appArmorProfiles[line] = true
+		}
+	}
+
+	seLinuxEnforce, err := collector.readFile(collector.filePaths.SELinuxEnforce)
+	status.SELinuxEnabled = err == nil
+	status.SELinuxEnforcing = strings.TrimSpace(seLinuxEnforce) == "1"
+
+	seccompProfiles := map[string]bool{}
+	files, err := collector.fileSystem.ListFiles(collector.filePaths.SeccompProfiles)
+	if err != nil {
+		status.UnavailableDataSources = append(status.UnavailableDataSources, err.Error())
+	}
+	for _, file := range files {
+		profile := strings.TrimPrefix(file, collector.filePaths.SeccompProfiles+"/")
+		status.SeccompProfiles = append(status.SeccompProfiles, profile)
+		seccompProfiles[profile] = true
+	}
+	sort.Strings(status.SeccompProfiles)
+
+	podList, err := collector.clientset.CoreV1().Pods(metav1.NamespaceAll).List(context.Background(), metav1.ListOptions{
+		FieldSelector: "spec.nodeName=" + collector.runtimeInfo.HostNodeName,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list pods on node %s: %w", collector.runtimeInfo.HostNodeName, err)
+	}
+
+	for _, pod := range podList.Items {
+		if pod.Spec.NodeName != collector.runtimeInfo.HostNodeName {
+			continue
+		}
+
+		for _, ref := range getSeccompProfileRefs(&pod) {
+			if !seccompProfiles[path.Clean(ref.Profile)] {
+				status.MissingProfileRefs = append(status.MissingProfileRefs, ref)
+			}
+		}
+
+		// AppArmor profiles can only be checked if we know which are loaded.
+		if status.AppArmorEnabled {
+			for _, ref := range getAppArmorProfileRefs(&pod) {
+				if !appArmorProfiles[ref.Profile] {
+					status.MissingProfileRefs = append(status.MissingProfileRefs, ref)
+				}
+			}
+		}
+	}
+
+	data, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("marshall security profiles to json: %w", err)
+	}
+
+	collector.data["securityprofiles"] = string(data)
+
+	return nil
+}
+
+func (collector *SecurityProfileCollector) readFile(filePath string) (string, error) {
+	content, err := utils.GetContent(func() (io.ReadCloser, error) { return collector.fileSystem.GetFileReader(filePath) })
+	if err != nil {
+		return "", fmt.Errorf("unable to read %s: %w", filePath, err)
+	}
+
+	return content, nil
+}
+
+// getSeccompProfileRefs returns references to localhost seccomp profiles, which are paths relative to
+// the kubelet's seccomp profile root, from both the security context and the deprecated annotations.
+func getSeccompProfileRefs(pod *corev1.Pod) []MissingSecurityProfile {
+	refs := []MissingSecurityProfile{}
+	addRef := func(container, profile string) {
+		refs = append(refs, MissingSecurityProfile{
+			Namespace: pod.Namespace,
+			Pod:       pod.Name,
+			Container: container,
+			Type:      "seccomp",
+			Profile:   profile,
+		})
+	}
+
+	isLocalhost := func(profile *corev1.SeccompProfile) bool {
+		return profile != nil && profile.Type == corev1.SeccompProfileTypeLocalhost && profile.LocalhostProfile != nil
+	}
+
+	if pod.Spec.SecurityContext != nil && isLocalhost(pod.Spec.SecurityContext.SeccompProfile) {
+		addRef("", *pod.Spec.SecurityContext.SeccompProfile.LocalhostProfile)
+	}
+	if value, ok := pod.Annotations[seccompPodAnnotation]; ok && strings.HasPrefix(value, localhostProfilePrefix) {
+		addRef("", strings.TrimPrefix(value, localhostProfilePrefix))
+	}
+
+	containers := append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	for _, container := range containers {
+		if container.SecurityContext != nil && isLocalhost(container.SecurityContext.SeccompProfile) {
+			addRef(container.Name, *container.SecurityContext.SeccompProfile.LocalhostProfile)
+		}
+		if value, ok := pod.Annotations[seccompContainerAnnotationPrefix+container.Name]; ok && strings.HasPrefix(value, localhostProfilePrefix) {
+			addRef(container.Name, strings.TrimPrefix(value, localhostProfilePrefix))
+		}
+	}
+
+	return refs
+}
+
+// getAppArmorProfileRefs returns references to localhost AppArmor profiles, which are the names of profiles
+// loaded on the node.
+func getAppArmorProfileRefs(pod *corev1.Pod) []MissingSecurityProfile {
+	refs := []MissingSecurityProfile{}
+	for annotation, value := range pod.Annotations {
+		if !strings.HasPrefix(annotation, appArmorAnnotationPrefix) || !strings.HasPrefix(value, localhostProfilePrefix) {
+			continue
+		}
+
+		refs = append(refs, MissingSecurityProfile{
+			Namespace: pod.Namespace,
+			Pod:       pod.Name,
+			Container: strings.TrimPrefix(annotation, appArmorAnnotationPrefix),
+			Type:      "apparmor",
+			Profile:   strings.TrimPrefix(value, localhostProfilePrefix),
+		})
+	}
+
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].Container < refs[j].Container
+	})
+
+	return refs
+}
+
+func (collector *SecurityProfileCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
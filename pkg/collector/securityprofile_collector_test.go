@@ -0,0 +1,132 @@
+package collector
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSecurityProfileCollectorGetName(t *testing.T) {
+	const expectedName = "securityprofiles"
+
+	c := NewSecurityProfileCollector("", nil, nil, nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestSecurityProfileCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		osIdentifier utils.OSIdentifier
+		wantErr      bool
+	}{
+		{
+			osIdentifier: utils.Windows,
+			wantErr:      true,
+		},
+		{
+			osIdentifier: utils.Linux,
+			wantErr:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		c := NewSecurityProfileCollector(tt.osIdentifier, nil, nil, nil, nil)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("CheckSupported() error = %v, wantErr %v", err, tt.wantErr)
+		}
+	}
+}
+
+func TestSecurityProfileCollectorCollect(t *testing.T) {
+	const nodeName = "node-1"
+
+	filePaths := &utils.KnownFilePaths{
+		AppArmorEnabled:  "/sys/module/apparmor/parameters/enabled",
+		AppArmorProfiles: "/host/sys/kernel/security/apparmor/profiles",
+		SELinuxEnforce:   "/sys/fs/selinux/enforce",
+		SeccompProfiles:  "/host/var/lib/kubelet/seccomp",
+	}
+
+	fileSystem := test.NewFakeFileSystem(map[string]string{
+		filePaths.AppArmorEnabled:                   "Y\n",
+		filePaths.AppArmorProfiles:                  "cri-containerd.apparmor.d (enforce)\nk8s-nginx (enforce)\n",
+		filePaths.SeccompProfiles + "/audit.json":   "{}",
+		filePaths.SeccompProfiles + "/profiles/web": "{}",
+	})
+
+	newPod := func(name, node string, seccompProfile string, annotations map[string]string) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app", Annotations: annotations},
+			Spec: corev1.PodSpec{
+				NodeName:   node,
+				Containers: []corev1.Container{{Name: "main"}},
+			},
+		}
+		if seccompProfile != "" {
+			pod.Spec.SecurityContext = &corev1.PodSecurityContext{
+				SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeLocalhost, LocalhostProfile: &seccompProfile},
+			}
+		}
+		return pod
+	}
+
+	clientset := fake.NewSimpleClientset(
+		newPod("existing-seccomp", nodeName, "profiles/web", nil),
+		newPod("missing-seccomp", nodeName, "profiles/missing.json", nil),
+		newPod("missing-seccomp-other-node", "node-2", "profiles/missing.json", nil),
+		newPod("existing-apparmor", nodeName, "", map[string]string{appArmorAnnotationPrefix + "main": "localhost/k8s-nginx"}),
+		newPod("missing-apparmor", nodeName, "", map[string]string{appArmorAnnotationPrefix + "main": "localhost/k8s-missing"}),
+		newPod("runtime-default", nodeName, "", map[string]string{appArmorAnnotationPrefix + "main": "runtime/default"}),
+	)
+
+	runtimeInfo := &utils.RuntimeInfo{
+		HostNodeName: nodeName,
+	}
+
+	c := NewSecurityProfileCollector(utils.Linux, filePaths, fileSystem, clientset, runtimeInfo)
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	testDataValue(t, c.GetData()["securityprofiles"], func(raw string) {
+		var status SecurityProfileStatus
+		if err := json.Unmarshal([]byte(raw), &status); err != nil {
+			t.Fatalf("unmarshal GetData(): %v", err)
+		}
+
+		if !status.AppArmorEnabled {
+			t.Errorf("expected AppArmor to be enabled")
+		}
+		if status.SELinuxEnabled || status.SELinuxEnforcing {
+			t.Errorf("expected SELinux to be disabled")
+		}
+		if !equalStringSlices(status.SeccompProfiles, []string{"audit.json", "profiles/web"}) {
+			t.Errorf("unexpected seccomp profiles %v", status.SeccompProfiles)
+		}
+
+		missing := map[string]string{}
+		for _, ref := range status.MissingProfileRefs {
+			missing[ref.Pod] = ref.Type + ":" + ref.Profile
+		}
+		expected := map[string]string{
+			"missing-seccomp":  "seccomp:profiles/missing.json",
+			"missing-apparmor": "apparmor:k8s-missing",
+		}
+		if len(missing) != len(expected) {
+			t.Errorf("expected missing profiles %v, found %v", expected, missing)
+		}
+		for pod, profile := range expected {
+			if missing[pod] != profile {
+				t.Errorf("expected missing profile %s for pod %s, found '%s'", profile, pod, missing[pod])
+			}
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package collector
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"runtime/pprof"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+// SelfDiagnosticsCollector defines a Self Diagnostics Collector struct
+type SelfDiagnosticsCollector struct {
+	data        map[string]string
+	runtimeInfo *utils.RuntimeInfo
+}
+
+// NewSelfDiagnosticsCollector is a constructor
+func NewSelfDiagnosticsCollector(runtimeInfo *utils.RuntimeInfo) *SelfDiagnosticsCollector {
+	return &SelfDiagnosticsCollector{
+		data:        make(map[string]string),
+		runtimeInfo: runtimeInfo,
+	}
+}
+
+func (collector *SelfDiagnosticsCollector) GetName() string {
+	return "selfdiagnostics"
+}
+
+func (collector *SelfDiagnosticsCollector) CheckSupported() error {
+	// The profiles are only of use when debugging Periscope, so they aren't collected unless asked for.
+	if !collector.runtimeInfo.SelfProfiles {
+		return fmt.Errorf("not enabled: %s is not true", utils.SelfProfilesKey)
+	}
+
+	return nil
+}
+
+// Collect implements the interface method. It runs alongside the other collectors, so the goroutine dump shows
+// what they are doing (or waiting on) at the time.
+func (collector *SelfDiagnosticsCollector) Collect() error {
+	// Debug level 2 prints the full stack of every goroutine, in the same format as an unrecovered panic, including
+	// how long each has been blocked.
+	var goroutines bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&goroutines, 2); err != nil {
+		return fmt.Errorf("write goroutine dump: %w", err)
+	}
+	collector.data["periscope-goroutines"] = goroutines.String()
+
+	// The heap profile reflects the last garbage collection, so one is run first to make it current. It is written
+	// in the gzipped protobuf format read by `go tool pprof`.
+	runtime.GC()
+	var heap bytes.Buffer
+	if err := pprof.Lookup("heap").WriteTo(&heap, 0); err != nil {
+		return fmt.Errorf("write heap profile: %w", err)
+	}
+	collector.data["periscope-heap"] = heap.String()
+
+	return nil
+}
+
+func (collector *SelfDiagnosticsCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
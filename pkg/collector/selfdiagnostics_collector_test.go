@@ -0,0 +1,75 @@
+package collector
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+func TestSelfDiagnosticsCollectorGetName(t *testing.T) {
+	const expectedName = "selfdiagnostics"
+
+	c := NewSelfDiagnosticsCollector(nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestSelfDiagnosticsCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name         string
+		selfProfiles bool
+		wantErr      bool
+	}{
+		{
+			name:         "not enabled",
+			selfProfiles: false,
+			wantErr:      true,
+		},
+		{
+			name:         "enabled",
+			selfProfiles: true,
+			wantErr:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		c := NewSelfDiagnosticsCollector(&utils.RuntimeInfo{SelfProfiles: tt.selfProfiles})
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSelfDiagnosticsCollectorCollect(t *testing.T) {
+	c := NewSelfDiagnosticsCollector(&utils.RuntimeInfo{SelfProfiles: true})
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	data := c.GetData()
+
+	// The dump includes the stack of the goroutine which took it.
+	testDataValue(t, data["periscope-goroutines"], func(raw string) {
+		if !strings.HasPrefix(raw, "goroutine ") || !strings.Contains(raw, "TestSelfDiagnosticsCollectorCollect") {
+			t.Errorf("unexpected goroutine dump:\n%s", raw)
+		}
+	})
+
+	testDataValue(t, data["periscope-heap"], func(raw string) {
+		reader, err := gzip.NewReader(bytes.NewReader([]byte(raw)))
+		if err != nil {
+			t.Fatalf("heap profile is not gzipped: %v", err)
+		}
+		profile, err := io.ReadAll(reader)
+		if err != nil || len(profile) == 0 {
+			t.Errorf("unexpected heap profile (%d bytes): %v", len(profile), err)
+		}
+	})
+}
@@ -0,0 +1,253 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+type ServiceEndpointInfo struct {
+	Namespace             string                     `json:"namespace"`
+	Name                  string                     `json:"name"`
+	Type                  string                     `json:"type"`
+	ClusterIP             string                     `json:"clusterIP,omitempty"`
+	ExternalTrafficPolicy string                     `json:"externalTrafficPolicy,omitempty"`
+	Ports                 []ServiceEndpointPort      `json:"ports"`
+	Ingress               []ServiceEndpointIngress   `json:"ingress"`
+	EndpointSlices        []ServiceEndpointSliceInfo `json:"endpointSlices"`
+	ReadyEndpoints        int                        `json:"readyEndpoints"`
+	NotReadyEndpoints     int                        `json:"notReadyEndpoints"`
+	NoReadyEndpoints      bool                       `json:"noReadyEndpoints"`
+	LoadBalancerPending   bool                       `json:"loadBalancerPending,omitempty"`
+	Conditions            []ServiceEndpointCondition `json:"conditions,omitempty"`
+}
+
+type ServiceEndpointPort struct {
+	Name       string `json:"name,omitempty"`
+	Protocol   string `json:"protocol"`
+	Port       int32  `json:"port"`
+	TargetPort string `json:"targetPort,omitempty"`
+	NodePort   int32  `json:"nodePort,omitempty"`
+}
+
+type ServiceEndpointIngress struct {
+	IP       string `json:"ip,omitempty"`
+	Hostname string `json:"hostname,omitempty"`
+}
+
+type ServiceEndpointCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type ServiceEndpointSliceInfo struct {
+	Name        string                   `json:"name"`
+	AddressType string                   `json:"addressType"`
+	Ready       []ServiceEndpointAddress `json:"ready"`
+	NotReady    []ServiceEndpointAddress `json:"notReady"`
+}
+
+type ServiceEndpointAddress struct {
+	Addresses   []string `json:"addresses"`
+	NodeName    string   `json:"nodeName,omitempty"`
+	Pod         string   `json:"pod,omitempty"`
+	Terminating bool     `json:"terminating,omitempty"`
+}
+
+// ServiceEndpointCollector defines a Service Endpoint Collector struct
+type ServiceEndpointCollector struct {
+	data        map[string]string
+	clientset   kubernetes.Interface
+	runtimeInfo *utils.RuntimeInfo
+}
+
+// NewServiceEndpointCollector is a constructor
+func NewServiceEndpointCollector(clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *ServiceEndpointCollector {
+	return &ServiceEndpointCollector{
+		data:        make(map[string]string),
+		clientset:   clientset,
+		runtimeInfo: runtimeInfo,
+	}
+}
+
+func (collector *ServiceEndpointCollector) GetName() string {
+	return "serviceendpoints"
+}
+
+func (collector *ServiceEndpointCollector) CheckSupported() error {
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *ServiceEndpointCollector) Collect() error {
+	ctx := context.Background()
+
+	result := []ServiceEndpointInfo{}
+	serviceIndexes := map[string]int{}
+
+	err := utils.ListAllPages(metav1.ListOptions{}, func(listOptions metav1.ListOptions) (string, error) {
+		serviceList, err := collector.clientset.CoreV1().Services(metav1.NamespaceAll).List(ctx, listOptions)
+		if err != nil {
+			return "", err
+		}
+
+		for _, service := range serviceList.Items {
+			if service.Spec.Type != corev1.ServiceTypeLoadBalancer && service.Spec.Type != corev1.ServiceTypeNodePort {
+				continue
+			}
+
+			serviceIndexes[service.Namespace+"/"+service.Name] = len(result)
+			result = append(result, getServiceEndpointInfo(&service))
+		}
+
+		return serviceList.Continue, nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list services: %w", err)
+	}
+
+	if len(result) > 0 {
+		err := utils.ListAllPages(metav1.ListOptions{}, func(listOptions metav1.ListOptions) (string, error) {
+			sliceList, err := collector.clientset.DiscoveryV1().EndpointSlices(metav1.NamespaceAll).List(ctx, listOptions)
+			if err != nil {
+				return "", err
+			}
+
+			for _, slice := range sliceList.Items {
+				serviceName, ok := slice.Labels[discoveryv1.LabelServiceName]
+				if !ok {
+					continue
+				}
+				index, ok := serviceIndexes[slice.Namespace+"/"+serviceName]
+				if !ok {
+					continue
+				}
+
+				sliceInfo := getServiceEndpointSliceInfo(&slice)
+				result[index].EndpointSlices = append(result[index].EndpointSlices, sliceInfo)
+				result[index].ReadyEndpoints += len(sliceInfo.Ready)
+				result[index].NotReadyEndpoints += len(sliceInfo.NotReady)
+			}
+
+			return sliceList.Continue, nil
+		})
+		if err != nil {
+			return fmt.Errorf("unable to list endpoint slices: %w", err)
+		}
+	}
+
+	for i := range result {
+		result[i].NoReadyEndpoints = result[i].ReadyEndpoints == 0
+		slices := result[i].EndpointSlices
+		sort.Slice(slices, func(i, j int) bool {
+			return slices[i].Name < slices[j].Name
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Namespace+"/"+result[i].Name < result[j].Namespace+"/"+result[j].Name
+	})
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshall service endpoints to json: %w", err)
+	}
+
+	collector.data["service-endpoints"] = string(data)
+
+	return nil
+}
+
+func getServiceEndpointInfo(service *corev1.Service) ServiceEndpointInfo {
+	info := ServiceEndpointInfo{
+		Namespace:             service.Namespace,
+		Name:                  service.Name,
+		Type:                  string(service.Spec.Type),
+		ClusterIP:             service.Spec.ClusterIP,
+		ExternalTrafficPolicy: string(service.Spec.ExternalTrafficPolicy),
+		Ports:                 []ServiceEndpointPort{},
+		Ingress:               []ServiceEndpointIngress{},
+		EndpointSlices:        []ServiceEndpointSliceInfo{},
+	}
+
+	for _, port := range service.Spec.Ports {
+		portInfo := ServiceEndpointPort{
+			Name:     port.Name,
+			Protocol: string(port.Protocol),
+			Port:     port.Port,
+			NodePort: port.NodePort,
+		}
+		if port.TargetPort.String() != "0" {
+			portInfo.TargetPort = port.TargetPort.String()
+		}
+		info.Ports = append(info.Ports, portInfo)
+	}
+
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
+		info.Ingress = append(info.Ingress, ServiceEndpointIngress{IP: ingress.IP, Hostname: ingress.Hostname})
+	}
+
+	for _, condition := range service.Status.Conditions {
+		info.Conditions = append(info.Conditions, ServiceEndpointCondition{
+			Type:    condition.Type,
+			Status:  string(condition.Status),
+			Reason:  condition.Reason,
+			Message: condition.Message,
+		})
+	}
+
+	// A load balancer without an ingress point hasn't (yet) been provisioned by the cloud provider.
+	info.LoadBalancerPending = service.Spec.Type == corev1.ServiceTypeLoadBalancer && len(info.Ingress) == 0
+
+	return info
+}
+
+func getServiceEndpointSliceInfo(slice *discoveryv1.EndpointSlice) ServiceEndpointSliceInfo {
+	info := ServiceEndpointSliceInfo{
+		Name:        slice.Name,
+		AddressType: string(slice.AddressType),
+		Ready:       []ServiceEndpointAddress{},
+		NotReady:    []ServiceEndpointAddress{},
+	}
+
+	for _, endpoint := range slice.Endpoints {
+		address := ServiceEndpointAddress{
+			Addresses:   endpoint.Addresses,
+			Terminating: endpoint.Conditions.Terminating != nil && *endpoint.Conditions.Terminating,
+		}
+		if endpoint.NodeName != nil {
+			address.NodeName = *endpoint.NodeName
+		}
+		if endpoint.TargetRef != nil && endpoint.TargetRef.Kind == "Pod" {
+			address.Pod = endpoint.TargetRef.Name
+		}
+
+		// A nil Ready condition is interpreted as ready.
+		if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+			info.Ready = append(info.Ready, address)
+		} else {
+			info.NotReady = append(info.NotReady, address)
+		}
+	}
+
+	return info
+}
+
+func (collector *ServiceEndpointCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
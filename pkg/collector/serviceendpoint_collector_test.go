@@ -0,0 +1,183 @@
+package collector
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestServiceEndpointCollectorGetName(t *testing.T) {
+	const expectedName = "serviceendpoints"
+
+	c := NewServiceEndpointCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestServiceEndpointCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewServiceEndpointCollector(nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestServiceEndpointCollectorCollect(t *testing.T) {
+	newService := func(name string, serviceType corev1.ServiceType) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app"},
+			Spec: corev1.ServiceSpec{
+				Type:      serviceType,
+				ClusterIP: "10.0.0.10",
+				Ports: []corev1.ServicePort{
+					{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80, TargetPort: intstr.FromString("web"), NodePort: 30080},
+				},
+			},
+		}
+	}
+
+	boolPtr := func(value bool) *bool {
+		return &value
+	}
+	newEndpoint := func(address string, pod string, ready bool) discoveryv1.Endpoint {
+		nodeName := "node-1"
+		return discoveryv1.Endpoint{
+			Addresses:  []string{address},
+			Conditions: discoveryv1.EndpointConditions{Ready: boolPtr(ready)},
+			NodeName:   &nodeName,
+			TargetRef:  &corev1.ObjectReference{Kind: "Pod", Namespace: "app", Name: pod},
+		}
+	}
+	newSlice := func(name string, serviceName string, endpoints ...discoveryv1.Endpoint) *discoveryv1.EndpointSlice {
+		return &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "app",
+				Labels:    map[string]string{discoveryv1.LabelServiceName: serviceName},
+			},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints:   endpoints,
+		}
+	}
+
+	web := newService("web", corev1.ServiceTypeLoadBalancer)
+	web.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeLocal
+	web.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "20.1.2.3"}}
+
+	pending := newService("pending", corev1.ServiceTypeLoadBalancer)
+
+	nodePort := newService("nodeport", corev1.ServiceTypeNodePort)
+
+	internal := newService("internal", corev1.ServiceTypeClusterIP)
+
+	clientset := fake.NewSimpleClientset(
+		newSlice("web-abc12", "web", newEndpoint("10.244.0.5", "web-1", true), newEndpoint("10.244.0.6", "web-2", false)),
+		newSlice("web-def34", "web", newEndpoint("10.244.1.5", "web-3", true)),
+		newSlice("nodeport-abc12", "nodeport", newEndpoint("10.244.0.7", "nodeport-1", false)),
+		newSlice("internal-abc12", "internal", newEndpoint("10.244.0.8", "internal-1", true)),
+		&discoveryv1.EndpointSlice{ObjectMeta: metav1.ObjectMeta{Name: "unowned", Namespace: "app"}},
+	)
+
+	// The fake clientset doesn't paginate, so serve the services over two pages.
+	pages := []*corev1.ServiceList{
+		{ListMeta: metav1.ListMeta{Continue: "page-2"}, Items: []corev1.Service{*web, *internal}},
+		{Items: []corev1.Service{*pending, *nodePort}},
+	}
+	serviceListCalls := 0
+	clientset.PrependReactor("list", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		page := pages[serviceListCalls]
+		serviceListCalls++
+		return true, page, nil
+	})
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+	}
+
+	c := NewServiceEndpointCollector(clientset, runtimeInfo)
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	if serviceListCalls != len(pages) {
+		t.Errorf("expected %d service list calls, found %d", len(pages), serviceListCalls)
+	}
+
+	testDataValue(t, c.GetData()["service-endpoints"], func(raw string) {
+		var services []ServiceEndpointInfo
+		if err := json.Unmarshal([]byte(raw), &services); err != nil {
+			t.Fatalf("unmarshal GetData(): %v", err)
+		}
+
+		names := []string{}
+		for _, service := range services {
+			names = append(names, service.Name)
+		}
+		if !equalStringSlices(names, []string{"nodeport", "pending", "web"}) {
+			t.Fatalf("unexpected services %v", names)
+		}
+
+		nodePort := services[0]
+		if nodePort.Type != "NodePort" || nodePort.LoadBalancerPending || !nodePort.NoReadyEndpoints || nodePort.NotReadyEndpoints != 1 {
+			t.Errorf("unexpected node port service %+v", nodePort)
+		}
+
+		pending := services[1]
+		if !pending.LoadBalancerPending || !pending.NoReadyEndpoints || len(pending.EndpointSlices) != 0 {
+			t.Errorf("unexpected pending service %+v", pending)
+		}
+
+		web := services[2]
+		if web.LoadBalancerPending || web.NoReadyEndpoints || web.ReadyEndpoints != 2 || web.NotReadyEndpoints != 1 {
+			t.Errorf("unexpected web service %+v", web)
+		}
+		if len(web.Ingress) != 1 || web.Ingress[0].IP != "20.1.2.3" || web.ExternalTrafficPolicy != "Local" {
+			t.Errorf("unexpected web load balancer status %+v", web)
+		}
+		if len(web.Ports) != 1 || web.Ports[0].TargetPort != "web" || web.Ports[0].NodePort != 30080 {
+			t.Errorf("unexpected web ports %+v", web.Ports)
+		}
+		if len(web.EndpointSlices) != 2 {
+			t.Fatalf("expected 2 endpoint slices, found %+v", web.EndpointSlices)
+		}
+		slice := web.EndpointSlices[0]
+		if slice.Name != "web-abc12" || len(slice.Ready) != 1 || len(slice.NotReady) != 1 {
+			t.Fatalf("unexpected endpoint slice %+v", slice)
+		}
+		if notReady := slice.NotReady[0]; notReady.Pod != "web-2" || notReady.NodeName != "node-1" || !equalStringSlices(notReady.Addresses, []string{"10.244.0.6"}) {
+			t.Errorf("unexpected not-ready endpoint %+v", notReady)
+		}
+	})
+}
@@ -86,3 +86,15 @@ func compareCollectorData(t *testing.T, expectedData map[string]*regexp.Regexp,
 		t.Errorf("unexpected keys in actual data:\n%s", strings.Join(unexpectedDataKeys, "\n"))
 	}
 }
+
+func equalStringSlices(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
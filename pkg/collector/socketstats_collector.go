@@ -0,0 +1,282 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// Pods leaking connections can hold tens of thousands of sockets, so only the counts are complete.
+	socketStatsMaxSockets = 500
+	socketStatsTopPeers   = 10
+)
+
+type SocketStatsReport struct {
+	Pods []PodSocketStats `json:"pods"`
+	// SkippedHostNetwork lists the pods using the host network namespace, whose sockets are those of the node and
+	// would be counted once for each such pod.
+	SkippedHostNetwork []string `json:"skippedHostNetwork"`
+	Errors             []string `json:"errors"`
+}
+
+type PodSocketStats struct {
+	Namespace string         `json:"namespace"`
+	Name      string         `json:"name"`
+	Total     int            `json:"total"`
+	States    map[string]int `json:"states"`
+	// TopPeers are the remote addresses with the most sockets, which point to the destination of a connection leak
+	// or the cause of SNAT port exhaustion.
+	TopPeers  []SocketPeerCount `json:"topPeers"`
+	Sockets   []TCPSocket       `json:"sockets"`
+	Truncated bool              `json:"truncated"`
+}
+
+type SocketPeerCount struct {
+	Peer  string `json:"peer"`
+	Count int    `json:"count"`
+}
+
+type TCPSocket struct {
+	State   string `json:"state"`
+	RecvQ   int    `json:"recvQ"`
+	SendQ   int    `json:"sendQ"`
+	Local   string `json:"local"`
+	Peer    string `json:"peer"`
+	Process string `json:"process,omitempty"`
+}
+
+// SocketStatsCollector defines a Socket Stats Collector struct
+type SocketStatsCollector struct {
+	data         map[string]string
+	osIdentifier utils.OSIdentifier
+	clientset    kubernetes.Interface
+	runCommand   utils.HostCommandRunner
+	runtimeInfo  *utils.RuntimeInfo
+}
+
+// NewSocketStatsCollector is a constructor
+func NewSocketStatsCollector(osIdentifier utils.OSIdentifier, clientset kubernetes.Interface, runCommand utils.HostCommandRunner, runtimeInfo *utils.RuntimeInfo) *SocketStatsCollector {
+	return &SocketStatsCollector{
+		data:         make(map[string]string),
+		osIdentifier: osIdentifier,
+		clientset:    clientset,
+		runCommand:   runCommand,
+		runtimeInfo:  runtimeInfo,
+	}
+}
+
+func (collector *SocketStatsCollector) GetName() string {
+	return "socketstats"
+}
+
+func (collector *SocketStatsCollector) CheckSupported() error {
+	// Entering the network namespace of a pod relies on nsenter, and requires CAP_SYS_ADMIN.
+	if collector.osIdentifier != utils.Linux {
+		return fmt.Errorf("unsupported OS: %s", collector.osIdentifier)
+	}
+
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *SocketStatsCollector) Collect() error {
+	pods, err := collector.clientset.CoreV1().Pods(metav1.NamespaceAll).List(context.Background(), metav1.ListOptions{
+		FieldSelector: "spec.nodeName=" + collector.runtimeInfo.HostNodeName,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list pods: %w", err)
+	}
+
+	sandboxes, err := collector.getPodSandboxes()
+	if err != nil {
+		return err
+	}
+
+	report := &SocketStatsReport{
+		Pods:               []PodSocketStats{},
+		SkippedHostNetwork: []string{},
+		Errors:             []string{},
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName != collector.runtimeInfo.HostNodeName || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+
+		podName := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+		if pod.Spec.HostNetwork {
+			report.SkippedHostNetwork = append(report.SkippedHostNetwork, podName)
+			continue
+		}
+
+		sandboxID, ok := sandboxes[string(pod.UID)]
+		if !ok {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: no ready pod sandbox found", podName))
+			continue
+		}
+
+		stats, err := collector.getPodSocketStats(sandboxID)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", podName, err))
+			continue
+		}
+
+		stats.Namespace = pod.Namespace
+		stats.Name = pod.Name
+		report.Pods = append(report.Pods, *stats)
+	}
+
+	sort.Slice(report.Pods, func(i, j int) bool {
+		if report.Pods[i].Total != report.Pods[j].Total {
+			return report.Pods[i].Total > report.Pods[j].Total
+		}
+		return report.Pods[i].Namespace+"/"+report.Pods[i].Name < report.Pods[j].Namespace+"/"+report.Pods[j].Name
+	})
+	sort.Strings(report.SkippedHostNetwork)
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("marshall socket stats to json: %w", err)
+	}
+
+	collector.data["socket-stats"] = string(data)
+
+	return nil
+}
+
+// getPodSandboxes returns the IDs of the ready pod sandboxes known to the container runtime, by pod UID.
+func (collector *SocketStatsCollector) getPodSandboxes() (map[string]string, error) {
+	output, err := collector.runCommand("crictl", "pods", "--state", "ready", "-o", "json")
+	if err != nil {
+		return nil, fmt.Errorf("unable to list pod sandboxes: %w", err)
+	}
+
+	var sandboxList struct {
+		Items []struct {
+			ID       string `json:"id"`
+			Metadata struct {
+				UID string `json:"uid"`
+			} `json:"metadata"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(output), &sandboxList); err != nil {
+		return nil, fmt.Errorf("unable to parse pod sandbox list: %w", err)
+	}
+
+	sandboxes := map[string]string{}
+	for _, sandbox := range sandboxList.Items {
+		sandboxes[sandbox.Metadata.UID] = sandbox.ID
+	}
+
+	return sandboxes, nil
+}
+
+// getPodSocketStats lists the TCP sockets in the network namespace of a pod sandbox, which is entered through the
+// sandbox process.
+func (collector *SocketStatsCollector) getPodSocketStats(sandboxID string) (*PodSocketStats, error) {
+	output, err := collector.runCommand("crictl", "inspectp", "-o", "json", sandboxID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to inspect pod sandbox %s: %w", sandboxID, err)
+	}
+
+	var sandbox struct {
+		Info struct {
+			Pid int `json:"pid"`
+		} `json:"info"`
+	}
+	if err := json.Unmarshal([]byte(output), &sandbox); err != nil {
+		return nil, fmt.Errorf("unable to parse pod sandbox %s: %w", sandboxID, err)
+	}
+	if sandbox.Info.Pid == 0 {
+		return nil, fmt.Errorf("no process found for pod sandbox %s", sandboxID)
+	}
+
+	output, err = collector.runCommand("nsenter", "--target", strconv.Itoa(sandbox.Info.Pid), "--net", "--", "ss", "-tanp")
+	if err != nil {
+		return nil, fmt.Errorf("unable to list sockets: %w", err)
+	}
+
+	return getSocketStats(output), nil
+}
+
+// getSocketStats parses the output of `ss -tanp`, which has a header line followed by a line for each socket:
+// State Recv-Q Send-Q Local-Address:Port Peer-Address:Port [Process]
+func getSocketStats(output string) *PodSocketStats {
+	stats := &PodSocketStats{
+		States:   map[string]int{},
+		TopPeers: []SocketPeerCount{},
+		Sockets:  []TCPSocket{},
+	}
+
+	peerCounts := map[string]int{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 || fields[0] == "State" {
+			continue
+		}
+
+		socket := TCPSocket{
+			State:   getTCPStateName(fields[0]),
+			Local:   fields[3],
+			Peer:    fields[4],
+			Process: strings.Join(fields[5:], " "),
+		}
+		socket.RecvQ, _ = strconv.Atoi(fields[1])
+		socket.SendQ, _ = strconv.Atoi(fields[2])
+
+		stats.Total++
+		stats.States[socket.State]++
+		if socket.State != "LISTEN" {
+			peerCounts[socket.Peer]++
+		}
+
+		if len(stats.Sockets) < socketStatsMaxSockets {
+			stats.Sockets = append(stats.Sockets, socket)
+		} else {
+			stats.Truncated = true
+		}
+	}
+
+	for peer, count := range peerCounts {
+		stats.TopPeers = append(stats.TopPeers, SocketPeerCount{Peer: peer, Count: count})
+	}
+	sort.Slice(stats.TopPeers, func(i, j int) bool {
+		if stats.TopPeers[i].Count != stats.TopPeers[j].Count {
+			return stats.TopPeers[i].Count > stats.TopPeers[j].Count
+		}
+		return stats.TopPeers[i].Peer < stats.TopPeers[j].Peer
+	})
+	if len(stats.TopPeers) > socketStatsTopPeers {
+		stats.TopPeers = stats.TopPeers[:socketStatsTopPeers]
+	}
+
+	return stats
+}
+
+// getTCPStateName converts the abbreviated state names used by ss (e.g. ESTAB, TIME-WAIT) to the kernel names
+// (e.g. ESTABLISHED, TIME_WAIT).
+func getTCPStateName(state string) string {
+	if state == "ESTAB" {
+		return "ESTABLISHED"
+	}
+	return strings.ReplaceAll(state, "-", "_")
+}
+
+func (collector *SocketStatsCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
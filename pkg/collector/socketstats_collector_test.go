@@ -0,0 +1,148 @@
+package collector
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSocketStatsCollectorGetName(t *testing.T) {
+	const expectedName = "socketstats"
+
+	c := NewSocketStatsCollector(utils.Linux, nil, nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestSocketStatsCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		osIdentifier  utils.OSIdentifier
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "Windows",
+			osIdentifier:  utils.Windows,
+			collectorList: []string{},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "Linux",
+			osIdentifier:  utils.Linux,
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewSocketStatsCollector(tt.osIdentifier, nil, nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSocketStatsCollectorCollect(t *testing.T) {
+	newPod := func(name string, uid types.UID, hostNetwork bool) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app", UID: "uid-" + uid},
+			Spec:       corev1.PodSpec{NodeName: "node1", HostNetwork: hostNetwork},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+
+	clientset := fake.NewSimpleClientset(
+		newPod("web", "web", false),
+		newPod("agent", "agent", true),
+		newPod("missing", "missing", false),
+	)
+
+	const sandboxes = `{"items": [
+		{"id": "sandbox-web", "metadata": {"name": "web", "namespace": "app", "uid": "uid-web"}},
+		{"id": "sandbox-agent", "metadata": {"name": "agent", "namespace": "app", "uid": "uid-agent"}}
+	]}`
+
+	const sockets = `State     Recv-Q Send-Q Local Address:Port  Peer Address:Port Process
+LISTEN    0      511    0.0.0.0:80          0.0.0.0:*         users:(("nginx",pid=1234,fd=6))
+ESTAB     0      0      10.244.0.5:80       10.244.1.7:51234  users:(("nginx",pid=1235,fd=11))
+TIME-WAIT 0      0      10.244.0.5:43210    10.0.0.10:5432
+TIME-WAIT 0      0      10.244.0.5:43212    10.0.0.10:5432
+`
+
+	runCommand := func(command string, arg ...string) (string, error) {
+		commandLine := strings.Join(append([]string{command}, arg...), " ")
+		switch commandLine {
+		case "crictl pods --state ready -o json":
+			return sandboxes, nil
+		case "crictl inspectp -o json sandbox-web":
+			return `{"info": {"pid": 4321}}`, nil
+		case "nsenter --target 4321 --net -- ss -tanp":
+			return sockets, nil
+		}
+		return "", errors.New("unexpected command: " + commandLine)
+	}
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+		HostNodeName:  "node1",
+	}
+
+	c := NewSocketStatsCollector(utils.Linux, clientset, runCommand, runtimeInfo)
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	want := SocketStatsReport{
+		Pods: []PodSocketStats{
+			{
+				Namespace: "app",
+				Name:      "web",
+				Total:     4,
+				States:    map[string]int{"LISTEN": 1, "ESTABLISHED": 1, "TIME_WAIT": 2},
+				TopPeers: []SocketPeerCount{
+					{Peer: "10.0.0.10:5432", Count: 2},
+					{Peer: "10.244.1.7:51234", Count: 1},
+				},
+				Sockets: []TCPSocket{
+					{State: "LISTEN", RecvQ: 0, SendQ: 511, Local: "0.0.0.0:80", Peer: "0.0.0.0:*", Process: `users:(("nginx",pid=1234,fd=6))`},
+					{State: "ESTABLISHED", Local: "10.244.0.5:80", Peer: "10.244.1.7:51234", Process: `users:(("nginx",pid=1235,fd=11))`},
+					{State: "TIME_WAIT", Local: "10.244.0.5:43210", Peer: "10.0.0.10:5432"},
+					{State: "TIME_WAIT", Local: "10.244.0.5:43212", Peer: "10.0.0.10:5432"},
+				},
+			},
+		},
+		SkippedHostNetwork: []string{"app/agent"},
+		Errors:             []string{"app/missing: no ready pod sandbox found"},
+	}
+
+	testDataValue(t, c.GetData()["socket-stats"], func(raw string) {
+		var report SocketStatsReport
+		if err := json.Unmarshal([]byte(raw), &report); err != nil {
+			t.Fatalf("unmarshal socket stats: %v", err)
+		}
+		if !reflect.DeepEqual(report, want) {
+			t.Errorf("unexpected socket stats:\nexpected %+v\nfound    %+v", want, report)
+		}
+	})
+}
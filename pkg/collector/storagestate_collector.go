@@ -0,0 +1,249 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Labels of the node-driver pods for the Azure Disk and Azure File CSI drivers.
+var csiNodeDriverPodLabels = []string{"app=csi-azuredisk-node", "app=csi-azurefile-node"}
+
+// The number of lines collected from the end of each node-driver container's logs.
+const csiNodeDriverLogLines = int64(100)
+
+type VolumeAttachmentInfo struct {
+	Name         string `json:"name"`
+	Attacher     string `json:"attacher"`
+	NodeName     string `json:"nodeName"`
+	PVName       string `json:"persistentVolumeName,omitempty"`
+	Attached     bool   `json:"attached"`
+	AttachError  string `json:"attachError,omitempty"`
+	DetachError  string `json:"detachError,omitempty"`
+	DeletionTime string `json:"deletionTime,omitempty"`
+}
+
+type CSINodeInfo struct {
+	Name    string          `json:"name"`
+	Drivers []CSINodeDriver `json:"drivers"`
+}
+
+type CSINodeDriver struct {
+	Name               string `json:"name"`
+	NodeID             string `json:"nodeID"`
+	AllocatableVolumes *int32 `json:"allocatableVolumes,omitempty"`
+}
+
+type CSIDriverInfo struct {
+	Name           string `json:"name"`
+	AttachRequired *bool  `json:"attachRequired,omitempty"`
+	PodInfoOnMount *bool  `json:"podInfoOnMount,omitempty"`
+}
+
+type UnhealthyVolumeInfo struct {
+	Namespace    string `json:"namespace,omitempty"`
+	Name         string `json:"name"`
+	Phase        string `json:"phase"`
+	StorageClass string `json:"storageClass,omitempty"`
+	VolumeName   string `json:"volumeName,omitempty"`
+	ClaimRef     string `json:"claimRef,omitempty"`
+	Message      string `json:"message,omitempty"`
+}
+
+// StorageStateCollector defines a Storage State Collector struct
+type StorageStateCollector struct {
+	data        map[string]string
+	clientset   kubernetes.Interface
+	runtimeInfo *utils.RuntimeInfo
+}
+
+// NewStorageStateCollector is a constructor
+func NewStorageStateCollector(clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *StorageStateCollector {
+	return &StorageStateCollector{
+		data:        make(map[string]string),
+		clientset:   clientset,
+		runtimeInfo: runtimeInfo,
+	}
+}
+
+func (collector *StorageStateCollector) GetName() string {
+	return "storagestate"
+}
+
+func (collector *StorageStateCollector) CheckSupported() error {
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *StorageStateCollector) Collect() error {
+	ctx := context.Background()
+
+	volumeAttachmentList, err := collector.clientset.StorageV1().VolumeAttachments().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list volume attachments: %w", err)
+	}
+
+	volumeAttachments := make([]VolumeAttachmentInfo, 0, len(volumeAttachmentList.Items))
+	for _, attachment := range volumeAttachmentList.Items {
+		info := VolumeAttachmentInfo{
+			Name:     attachment.Name,
+			Attacher: attachment.Spec.Attacher,
+			NodeName: attachment.Spec.NodeName,
+			Attached: attachment.Status.Attached,
+		}
+		if attachment.Spec.Source.PersistentVolumeName != nil {
+			info.PVName = *attachment.Spec.Source.PersistentVolumeName
+		}
+		if attachment.Status.AttachError != nil {
+			info.AttachError = attachment.Status.AttachError.Message
+		}
+		if attachment.Status.DetachError != nil {
+			info.DetachError = attachment.Status.DetachError.Message
+		}
+		if attachment.DeletionTimestamp != nil {
+			info.DeletionTime = attachment.DeletionTimestamp.String()
+		}
+		volumeAttachments = append(volumeAttachments, info)
+	}
+
+	csiNodeList, err := collector.clientset.StorageV1().CSINodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list CSI nodes: %w", err)
+	}
+
+	csiNodes := make([]CSINodeInfo, 0, len(csiNodeList.Items))
+	for _, csiNode := range csiNodeList.Items {
+		info := CSINodeInfo{Name: csiNode.Name, Drivers: []CSINodeDriver{}}
+		for _, driver := range csiNode.Spec.Drivers {
+			nodeDriver := CSINodeDriver{Name: driver.Name, NodeID: driver.NodeID}
+			if driver.Allocatable != nil {
+				nodeDriver.AllocatableVolumes = driver.Allocatable.Count
+			}
+			info.Drivers = append(info.Drivers, nodeDriver)
+		}
+		csiNodes = append(csiNodes, info)
+	}
+
+	csiDriverList, err := collector.clientset.StorageV1().CSIDrivers().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list CSI drivers: %w", err)
+	}
+
+	csiDrivers := make([]CSIDriverInfo, 0, len(csiDriverList.Items))
+	for _, csiDriver := range csiDriverList.Items {
+		csiDrivers = append(csiDrivers, CSIDriverInfo{
+			Name:           csiDriver.Name,
+			AttachRequired: csiDriver.Spec.AttachRequired,
+			PodInfoOnMount: csiDriver.Spec.PodInfoOnMount,
+		})
+	}
+
+	pvcList, err := collector.clientset.CoreV1().PersistentVolumeClaims(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list persistent volume claims: %w", err)
+	}
+
+	pvcs := []UnhealthyVolumeInfo{}
+	for _, pvc := range pvcList.Items {
+		if pvc.Status.Phase != corev1.ClaimPending && pvc.Status.Phase != corev1.ClaimLost {
+			continue
+		}
+
+		info := UnhealthyVolumeInfo{
+			Namespace:  pvc.Namespace,
+			Name:       pvc.Name,
+			Phase:      string(pvc.Status.Phase),
+			VolumeName: pvc.Spec.VolumeName,
+		}
+		if pvc.Spec.StorageClassName != nil {
+			info.StorageClass = *pvc.Spec.StorageClassName
+		}
+		pvcs = append(pvcs, info)
+	}
+
+	pvList, err := collector.clientset.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list persistent volumes: %w", err)
+	}
+
+	pvs := []UnhealthyVolumeInfo{}
+	for _, pv := range pvList.Items {
+		if pv.Status.Phase != corev1.VolumePending && pv.Status.Phase != corev1.VolumeFailed {
+			continue
+		}
+
+		info := UnhealthyVolumeInfo{
+			Name:         pv.Name,
+			Phase:        string(pv.Status.Phase),
+			StorageClass: pv.Spec.StorageClassName,
+			Message:      pv.Status.Message,
+		}
+		if pv.Spec.ClaimRef != nil {
+			info.ClaimRef = pv.Spec.ClaimRef.Namespace + "/" + pv.Spec.ClaimRef.Name
+		}
+		pvs = append(pvs, info)
+	}
+
+	for key, value := range map[string]interface{}{
+		"volumeattachments":      volumeAttachments,
+		"csinodes":               csiNodes,
+		"csidrivers":             csiDrivers,
+		"persistentvolumeclaims": pvcs,
+		"persistentvolumes":      pvs,
+	} {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("marshall %s to json: %w", key, err)
+		}
+		collector.data[key] = string(data)
+	}
+
+	// The node-driver logs are only collected from the node this instance of Periscope is running on,
+	// since the instances on the other nodes will collect their own.
+	for _, labelSelector := range csiNodeDriverPodLabels {
+		podList, err := collector.clientset.CoreV1().Pods("kube-system").List(ctx, metav1.ListOptions{
+			LabelSelector: labelSelector,
+			FieldSelector: "spec.nodeName=" + collector.runtimeInfo.HostNodeName,
+		})
+		if err != nil {
+			return fmt.Errorf("unable to list CSI node-driver pods with label %s: %w", labelSelector, err)
+		}
+
+		for _, pod := range podList.Items {
+			if pod.Spec.NodeName != collector.runtimeInfo.HostNodeName {
+				continue
+			}
+
+			for _, container := range pod.Spec.Containers {
+				tailLines := csiNodeDriverLogLines
+				podLogOptions := &corev1.PodLogOptions{Container: container.Name, TailLines: &tailLines}
+				containerLogs, err := getPodContainerLogs(pod.Namespace, pod.Name, podLogOptions, collector.clientset)
+				if err != nil {
+					// The driver may not have started yet, which is itself useful information from the other data.
+					log.Printf("Unable to get logs for %s/%s container %s: %v", pod.Namespace, pod.Name, container.Name, err)
+					continue
+				}
+
+				collector.data[fmt.Sprintf("logs_%s_%s", pod.Name, container.Name)] = containerLogs
+			}
+		}
+	}
+
+	return nil
+}
+
+func (collector *StorageStateCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
@@ -0,0 +1,133 @@
+package collector
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestStorageStateCollectorGetName(t *testing.T) {
+	const expectedName = "storagestate"
+
+	c := NewStorageStateCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestStorageStateCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewStorageStateCollector(nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestStorageStateCollectorCollect(t *testing.T) {
+	pvName := "pv-1"
+	newDriverPod := func(name, nodeName string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kube-system", Labels: map[string]string{"app": "csi-azuredisk-node"}},
+			Spec:       corev1.PodSpec{NodeName: nodeName, Containers: []corev1.Container{{Name: "azuredisk"}}},
+		}
+	}
+
+	tests := []struct {
+		name            string
+		objects         []runtime.Object
+		wantKeys        []string
+		wantAttachments []VolumeAttachmentInfo
+	}{
+		{
+			name:            "no storage",
+			objects:         []runtime.Object{},
+			wantKeys:        []string{"volumeattachments", "csinodes", "csidrivers", "persistentvolumeclaims", "persistentvolumes"},
+			wantAttachments: []VolumeAttachmentInfo{},
+		},
+		{
+			name: "attachments and node-driver pods",
+			objects: []runtime.Object{
+				&storagev1.VolumeAttachment{
+					ObjectMeta: metav1.ObjectMeta{Name: "csi-1234"},
+					Spec: storagev1.VolumeAttachmentSpec{
+						Attacher: "disk.csi.azure.com",
+						NodeName: "node1",
+						Source:   storagev1.VolumeAttachmentSource{PersistentVolumeName: &pvName},
+					},
+					Status: storagev1.VolumeAttachmentStatus{AttachError: &storagev1.VolumeError{Message: "disk busy"}},
+				},
+				newDriverPod("csi-azuredisk-node-1", "node1"),
+				newDriverPod("csi-azuredisk-node-2", "node2"),
+			},
+			wantKeys: []string{"volumeattachments", "csinodes", "csidrivers", "persistentvolumeclaims", "persistentvolumes", "logs_csi-azuredisk-node-1_azuredisk"},
+			wantAttachments: []VolumeAttachmentInfo{
+				{Name: "csi-1234", Attacher: "disk.csi.azure.com", NodeName: "node1", PVName: pvName, AttachError: "disk busy"},
+			},
+		},
+	}
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+		HostNodeName:  "node1",
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewStorageStateCollector(fake.NewSimpleClientset(tt.objects...), runtimeInfo)
+			if err := c.Collect(); err != nil {
+				t.Fatalf("Collect() error = %v", err)
+			}
+
+			// Node-driver logs are only collected from the pods on this node.
+			data := c.GetData()
+			if len(data) != len(tt.wantKeys) {
+				t.Errorf("expected keys %v, found %d keys", tt.wantKeys, len(data))
+			}
+			for _, key := range tt.wantKeys {
+				if _, ok := data[key]; !ok {
+					t.Errorf("missing key %s", key)
+				}
+			}
+
+			testDataValue(t, data["volumeattachments"], func(raw string) {
+				var attachments []VolumeAttachmentInfo
+				if err := json.Unmarshal([]byte(raw), &attachments); err != nil {
+					t.Fatalf("unmarshal volume attachments: %v", err)
+				}
+				if !reflect.DeepEqual(attachments, tt.wantAttachments) {
+					t.Errorf("unexpected volume attachments:\nexpected %+v\nfound    %+v", tt.wantAttachments, attachments)
+				}
+			})
+		})
+	}
+}
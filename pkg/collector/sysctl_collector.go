@@ -0,0 +1,227 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+const (
+	// Kubernetes nodes run many processes watching files (kubelet, containerd, log shippers...), so the distribution
+	// defaults for inotify are too low. AKS node images raise them to these.
+	sysctlMinInotifyMaxUserWatches   = 524288
+	sysctlMinInotifyMaxUserInstances = 1024
+
+	// Usage of a table above this proportion of its limit is flagged, since new entries fail once it is full.
+	sysctlHighUsageRatio = 0.9
+)
+
+type SysctlReport struct {
+	Important []ImportantSysctl `json:"important"`
+	All       map[string]string `json:"all"`
+}
+
+type ImportantSysctl struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	Missing bool   `json:"missing,omitempty"`
+	Warning string `json:"warning,omitempty"`
+}
+
+type KernelModule struct {
+	Name     string   `json:"name"`
+	Size     int64    `json:"size"`
+	UseCount int      `json:"useCount"`
+	UsedBy   []string `json:"usedBy"`
+}
+
+// sysctlChecks return a warning if the value of a sysctl (or its relationship with another) is known to cause failures.
+var sysctlChecks = map[string]func(value string, all map[string]string) string{
+	"net.ipv4.ip_forward": func(value string, _ map[string]string) string {
+		if value != "1" {
+			return "IP forwarding is disabled, so traffic to and from pods is not routed"
+		}
+		return ""
+	},
+	"net.bridge.bridge-nf-call-iptables": func(value string, _ map[string]string) string {
+		if value != "1" {
+			return "bridged traffic bypasses iptables, so service and network policy rules don't apply to it"
+		}
+		return ""
+	},
+	"fs.inotify.max_user_watches": func(value string, _ map[string]string) string {
+		return checkSysctlMinimum(value, sysctlMinInotifyMaxUserWatches, "adding inotify watches fails with 'no space left on device'")
+	},
+	"fs.inotify.max_user_instances": func(value string, _ map[string]string) string {
+		return checkSysctlMinimum(value, sysctlMinInotifyMaxUserInstances, "creating inotify instances fails with 'too many open files'")
+	},
+	"net.netfilter.nf_conntrack_count": func(value string, all map[string]string) string {
+		return checkSysctlUsage(value, all["net.netfilter.nf_conntrack_max"], "the conntrack table is nearly full, and new connections are dropped once it is")
+	},
+	"fs.file-nr": func(value string, _ map[string]string) string {
+		// Allocated, free (always 0 since Linux 2.6) and maximum file handles.
+		fields := strings.Fields(value)
+		if len(fields) != 3 {
+			return ""
+		}
+		return checkSysctlUsage(fields[0], fields[2], "the system is nearly out of file handles")
+	},
+}
+
+// SysctlCollector defines a Sysctl Collector struct
+type SysctlCollector struct {
+	data         map[string]string
+	osIdentifier utils.OSIdentifier
+	runCommand   utils.HostCommandRunner
+	runtimeInfo  *utils.RuntimeInfo
+}
+
+// NewSysctlCollector is a constructor
+func NewSysctlCollector(osIdentifier utils.OSIdentifier, runCommand utils.HostCommandRunner, runtimeInfo *utils.RuntimeInfo) *SysctlCollector {
+	return &SysctlCollector{
+		data:         make(map[string]string),
+		osIdentifier: osIdentifier,
+		runCommand:   runCommand,
+		runtimeInfo:  runtimeInfo,
+	}
+}
+
+func (collector *SysctlCollector) GetName() string {
+	return "sysctl"
+}
+
+func (collector *SysctlCollector) CheckSupported() error {
+	if collector.osIdentifier != utils.Linux {
+		return fmt.Errorf("unsupported OS: %s", collector.osIdentifier)
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *SysctlCollector) Collect() error {
+	// Network sysctls are per network namespace, so they are read on the host rather than from our own /proc/sys.
+	output, err := collector.runCommand("sysctl", "-a")
+	if err != nil {
+		return fmt.Errorf("unable to list sysctls: %w", err)
+	}
+
+	report := SysctlReport{
+		Important: []ImportantSysctl{},
+		All:       parseSysctlOutput(output),
+	}
+
+	for _, name := range collector.runtimeInfo.SysctlKeys {
+		value, ok := report.All[name]
+		important := ImportantSysctl{Name: name, Value: value, Missing: !ok}
+		if check, hasCheck := sysctlChecks[name]; ok && hasCheck {
+			important.Warning = check(value, report.All)
+		}
+		report.Important = append(report.Important, important)
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("marshall sysctls to json: %w", err)
+	}
+
+	collector.data["sysctl"] = string(data)
+
+	output, err = collector.runCommand("lsmod")
+	if err != nil {
+		return fmt.Errorf("unable to list kernel modules: %w", err)
+	}
+
+	data, err = json.Marshal(parseLsmodOutput(output))
+	if err != nil {
+		return fmt.Errorf("marshall kernel modules to json: %w", err)
+	}
+
+	collector.data["kernel-modules"] = string(data)
+
+	return nil
+}
+
+// parseSysctlOutput parses the 'name = value' lines of `sysctl -a`. Values which can't be read are reported on other
+// lines (e.g. "sysctl: permission denied on key ..."), which are ignored.
+func parseSysctlOutput(output string) map[string]string {
+	result := map[string]string{}
+	for _, line := range strings.Split(output, "\n") {
+		name, value, ok := strings.Cut(line, " = ")
+		if !ok || strings.HasPrefix(name, "sysctl:") {
+			continue
+		}
+		result[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+
+	return result
+}
+
+// parseLsmodOutput parses the output of `lsmod`, e.g.
+// Module                  Size  Used by
+// br_netfilter           32768  0
+// bridge                307200  1 br_netfilter
+func parseLsmodOutput(output string) []KernelModule {
+	modules := []KernelModule{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[0] == "Module" {
+			continue
+		}
+
+		size, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		useCount, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+
+		module := KernelModule{Name: fields[0], Size: size, UseCount: useCount, UsedBy: []string{}}
+		if len(fields) > 3 {
+			for _, user := range strings.Split(fields[3], ",") {
+				if user != "" && user != "-" {
+					module.UsedBy = append(module.UsedBy, user)
+				}
+			}
+		}
+		modules = append(modules, module)
+	}
+
+	return modules
+}
+
+func checkSysctlMinimum(value string, minimum int64, consequence string) string {
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || number >= minimum {
+		return ""
+	}
+
+	return fmt.Sprintf("below the recommended minimum of %d: %s", minimum, consequence)
+}
+
+func checkSysctlUsage(used, limit string, consequence string) string {
+	usedNumber, err := strconv.ParseFloat(used, 64)
+	if err != nil {
+		return ""
+	}
+	limitNumber, err := strconv.ParseFloat(limit, 64)
+	if err != nil || limitNumber <= 0 {
+		return ""
+	}
+
+	if usedNumber/limitNumber < sysctlHighUsageRatio {
+		return ""
+	}
+
+	return fmt.Sprintf("%s of %s in use: %s", used, limit, consequence)
+}
+
+func (collector *SysctlCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
@@ -0,0 +1,139 @@
+package collector
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+func TestSysctlCollectorGetName(t *testing.T) {
+	const expectedName = "sysctl"
+
+	c := NewSysctlCollector("", nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestSysctlCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		osIdentifier utils.OSIdentifier
+		wantErr      bool
+	}{
+		{
+			osIdentifier: utils.Windows,
+			wantErr:      true,
+		},
+		{
+			osIdentifier: utils.Linux,
+			wantErr:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		c := NewSysctlCollector(tt.osIdentifier, nil, nil)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("CheckSupported() error = %v, wantErr %v", err, tt.wantErr)
+		}
+	}
+}
+
+func TestSysctlCollectorCollect(t *testing.T) {
+	const sysctlOutput = `fs.file-nr = 2048	0	9223372036854775807
+fs.inotify.max_user_instances = 128
+fs.inotify.max_user_watches = 1048576
+kernel.pid_max = 4194304
+net.ipv4.ip_forward = 1
+net.netfilter.nf_conntrack_count = 131000
+net.netfilter.nf_conntrack_max = 131072
+sysctl: permission denied on key 'vm.stat_refresh'
+`
+	const lsmodOutput = `Module                  Size  Used by
+br_netfilter           32768  0
+bridge                307200  1 br_netfilter
+xt_conntrack           16384  12
+nf_conntrack          172032  3 xt_conntrack,nf_nat,xt_MASQUERADE
+`
+
+	runCommand := func(command string, arg ...string) (string, error) {
+		switch strings.Join(append([]string{command}, arg...), " ") {
+		case "sysctl -a":
+			return sysctlOutput, nil
+		case "lsmod":
+			return lsmodOutput, nil
+		}
+		return "", errors.New("unexpected command")
+	}
+
+	runtimeInfo := &utils.RuntimeInfo{
+		SysctlKeys: []string{"net.ipv4.ip_forward", "net.bridge.bridge-nf-call-iptables", "fs.inotify.max_user_watches", "fs.inotify.max_user_instances", "net.netfilter.nf_conntrack_count", "fs.file-nr"},
+	}
+
+	c := NewSysctlCollector(utils.Linux, runCommand, runtimeInfo)
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	data := c.GetData()
+
+	testDataValue(t, data["sysctl"], func(raw string) {
+		var report SysctlReport
+		if err := json.Unmarshal([]byte(raw), &report); err != nil {
+			t.Fatalf("unmarshal GetData(): %v", err)
+		}
+
+		if len(report.All) != 7 || report.All["kernel.pid_max"] != "4194304" {
+			t.Errorf("unexpected sysctls %v", report.All)
+		}
+
+		if len(report.Important) != len(runtimeInfo.SysctlKeys) {
+			t.Fatalf("expected %d important sysctls, found %+v", len(runtimeInfo.SysctlKeys), report.Important)
+		}
+
+		warnings := map[string]string{}
+		for _, sysctl := range report.Important {
+			warnings[sysctl.Name] = sysctl.Warning
+		}
+		for _, name := range []string{"net.ipv4.ip_forward", "net.bridge.bridge-nf-call-iptables", "fs.inotify.max_user_watches", "fs.file-nr"} {
+			if warnings[name] != "" {
+				t.Errorf("unexpected warning for %s: %s", name, warnings[name])
+			}
+		}
+		for _, name := range []string{"fs.inotify.max_user_instances", "net.netfilter.nf_conntrack_count"} {
+			if warnings[name] == "" {
+				t.Errorf("expected warning for %s", name)
+			}
+		}
+
+		if bridge := report.Important[1]; !bridge.Missing || bridge.Value != "" {
+			t.Errorf("expected missing sysctl, found %+v", bridge)
+		}
+	})
+
+	testDataValue(t, data["kernel-modules"], func(raw string) {
+		var modules []KernelModule
+		if err := json.Unmarshal([]byte(raw), &modules); err != nil {
+			t.Fatalf("unmarshal GetData(): %v", err)
+		}
+
+		if len(modules) != 4 {
+			t.Fatalf("expected 4 modules, found %+v", modules)
+		}
+		conntrack := modules[3]
+		if conntrack.Name != "nf_conntrack" || conntrack.Size != 172032 || conntrack.UseCount != 3 || !equalStringSlices(conntrack.UsedBy, []string{"xt_conntrack", "nf_nat", "xt_MASQUERADE"}) {
+			t.Errorf("unexpected module %+v", conntrack)
+		}
+	})
+}
+
+func TestParseSysctlOutputDisabledForwarding(t *testing.T) {
+	all := parseSysctlOutput("net.ipv4.ip_forward = 0\n")
+	if warning := sysctlChecks["net.ipv4.ip_forward"](all["net.ipv4.ip_forward"], all); warning == "" {
+		t.Errorf("expected warning for disabled IP forwarding")
+	}
+}
@@ -0,0 +1,176 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// SystemComponentLogsCollector defines a System Component Logs Collector struct
+type SystemComponentLogsCollector struct {
+	data        map[string]string
+	clientset   kubernetes.Interface
+	runtimeInfo *utils.RuntimeInfo
+}
+
+// NewSystemComponentLogsCollector is a constructor
+func NewSystemComponentLogsCollector(clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *SystemComponentLogsCollector {
+	return &SystemComponentLogsCollector{
+		data:        make(map[string]string),
+		clientset:   clientset,
+		runtimeInfo: runtimeInfo,
+	}
+}
+
+func (collector *SystemComponentLogsCollector) GetName() string {
+	return "systemcomponentlogs"
+}
+
+func (collector *SystemComponentLogsCollector) CheckSupported() error {
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *SystemComponentLogsCollector) Collect() error {
+	return collector.CollectStreams(utils.NewStringDataStreamHandler(collector.data))
+}
+
+// CollectStreams implements the interface method. The logs of each component are passed to the handler as they are
+// read from the API server, so that they don't all need to be held in memory.
+func (collector *SystemComponentLogsCollector) CollectStreams(handler interfaces.DataStreamHandler) error {
+	ctx := context.Background()
+
+	for _, component := range collector.runtimeInfo.SystemComponents {
+		kind, name, _ := strings.Cut(component, "/")
+
+		selector, err := collector.getSelector(ctx, kind, name)
+		if err != nil {
+			return fmt.Errorf("unable to get %s: %w", component, err)
+		}
+
+		// Not every component is present in every cluster (e.g. konnectivity-agent is only used by some clusters).
+		if selector == nil {
+			continue
+		}
+
+		podSelector, err := metav1.LabelSelectorAsSelector(selector)
+		if err != nil {
+			return fmt.Errorf("invalid selector for %s: %w", component, err)
+		}
+
+		pods, err := collector.clientset.CoreV1().Pods(metav1.NamespaceSystem).List(ctx, metav1.ListOptions{LabelSelector: podSelector.String()})
+		if err != nil {
+			return fmt.Errorf("unable to list pods for %s: %w", component, err)
+		}
+
+		if err := collector.streamLogs(ctx, name, pods.Items, handler); err != nil {
+			return fmt.Errorf("unable to export logs for %s: %w", component, err)
+		}
+	}
+
+	return nil
+}
+
+// streamLogs writes the logs of the pods to a pipe, which is read by the handler.
+func (collector *SystemComponentLogsCollector) streamLogs(ctx context.Context, key string, pods []corev1.Pod, handler interfaces.DataStreamHandler) error {
+	// If the handler stops reading early, the remaining log requests are cancelled.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	reader, writer := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range pods {
+			for _, status := range pods[i].Status.ContainerStatuses {
+				writePodContainerLogs(ctx, collector.clientset, writer, &pods[i], status.Name, collector.runtimeInfo.SystemComponentLogLines, false)
+
+				// The logs of the previous instance show why a restarted container failed.
+				if status.RestartCount > 0 {
+					writePodContainerLogs(ctx, collector.clientset, writer, &pods[i], status.Name, collector.runtimeInfo.SystemComponentLogLines, true)
+				}
+			}
+		}
+		writer.Close()
+	}()
+
+	err := handler(key, reader)
+
+	// Unblock the writer, in case the handler returned without reading all the logs.
+	cancel()
+	reader.Close()
+	<-done
+
+	return err
+}
+
+// getSelector returns the pod selector of a kube-system deployment or daemonset, or nil if it doesn't exist.
+func (collector *SystemComponentLogsCollector) getSelector(ctx context.Context, kind, name string) (*metav1.LabelSelector, error) {
+	var selector *metav1.LabelSelector
+	var err error
+	switch kind {
+	case "deployment":
+		var deployment *appsv1.Deployment
+		if deployment, err = collector.clientset.AppsV1().Deployments(metav1.NamespaceSystem).Get(ctx, name, metav1.GetOptions{}); err == nil {
+			selector = deployment.Spec.Selector
+		}
+	case "daemonset":
+		var daemonSet *appsv1.DaemonSet
+		if daemonSet, err = collector.clientset.AppsV1().DaemonSets(metav1.NamespaceSystem).Get(ctx, name, metav1.GetOptions{}); err == nil {
+			selector = daemonSet.Spec.Selector
+		}
+	default:
+		return nil, fmt.Errorf("unsupported kind %s", kind)
+	}
+
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+
+	return selector, err
+}
+
+// writePodContainerLogs writes the tail of a container's logs to the output, under a header identifying the container.
+// Failures are written in place of the logs, so that one unavailable container doesn't prevent collection of the others.
+func writePodContainerLogs(ctx context.Context, clientset kubernetes.Interface, output io.Writer, pod *corev1.Pod, container string, tailLines int64, previous bool) {
+	header := fmt.Sprintf("==> %s/%s", pod.Name, container)
+	if previous {
+		header += " (previous)"
+	}
+	fmt.Fprintf(output, "%s <==\n", header)
+
+	request := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: container,
+		TailLines: &tailLines,
+		Previous:  previous,
+	})
+
+	stream, err := request.Stream(ctx)
+	if err != nil {
+		fmt.Fprintf(output, "unable to get logs: %v\n\n", err)
+		return
+	}
+	defer stream.Close()
+
+	if _, err := io.Copy(output, stream); err != nil {
+		fmt.Fprintf(output, "\nunable to read logs: %v\n", err)
+	}
+	io.WriteString(output, "\n\n")
+}
+
+func (collector *SystemComponentLogsCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
@@ -0,0 +1,168 @@
+package collector
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSystemComponentLogsCollectorGetName(t *testing.T) {
+	const expectedName = "systemcomponentlogs"
+
+	c := NewSystemComponentLogsCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestSystemComponentLogsCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewSystemComponentLogsCollector(nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSystemComponentLogsCollectorCollect(t *testing.T) {
+	newPod := func(name string, labels map[string]string, restartCount int32) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceSystem, Name: name, Labels: labels},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "main", RestartCount: restartCount}},
+			},
+		}
+	}
+
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceSystem, Name: "coredns"},
+			Spec:       appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"k8s-app": "kube-dns"}}},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceSystem, Name: "azure-ip-masq-agent"},
+			Spec:       appsv1.DaemonSetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"k8s-app": "azure-ip-masq-agent"}}},
+		},
+		newPod("coredns-1", map[string]string{"k8s-app": "kube-dns"}, 0),
+		newPod("coredns-2", map[string]string{"k8s-app": "kube-dns"}, 3),
+		newPod("azure-ip-masq-agent-1", map[string]string{"k8s-app": "azure-ip-masq-agent"}, 0),
+		newPod("kube-proxy-1", map[string]string{"k8s-app": "kube-proxy"}, 0),
+	)
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList:           []string{},
+		SystemComponents:        []string{"deployment/coredns", "deployment/konnectivity-agent", "daemonset/azure-ip-masq-agent"},
+		SystemComponentLogLines: 100,
+	}
+
+	c := NewSystemComponentLogsCollector(clientset, runtimeInfo)
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	data := c.GetData()
+	if len(data) != 2 {
+		t.Errorf("expected logs for 2 components, found %d", len(data))
+	}
+
+	expectedHeaders := map[string][]string{
+		"coredns":             {"==> coredns-1/main <==", "==> coredns-2/main <==", "==> coredns-2/main (previous) <=="},
+		"azure-ip-masq-agent": {"==> azure-ip-masq-agent-1/main <=="},
+	}
+	for key, headers := range expectedHeaders {
+		value, ok := data[key]
+		if !ok {
+			t.Errorf("missing key %s", key)
+			continue
+		}
+
+		testDataValue(t, value, func(actual string) {
+			found := []string{}
+			for _, line := range strings.Split(actual, "\n") {
+				if strings.HasPrefix(line, "==> ") {
+					found = append(found, line)
+				}
+			}
+			if !equalStringSlices(found, headers) {
+				t.Errorf("unexpected logs for %s: expected containers %v, found:\n%s", key, headers, actual)
+			}
+		})
+	}
+}
+
+func TestSystemComponentLogsCollectorCollectStreams(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceSystem, Name: "coredns"},
+			Spec:       appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"k8s-app": "kube-dns"}}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceSystem, Name: "coredns-1", Labels: map[string]string{"k8s-app": "kube-dns"}},
+			Status:     corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Name: "main"}}},
+		},
+	)
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList:           []string{},
+		SystemComponents:        []string{"deployment/coredns"},
+		SystemComponentLogLines: 100,
+	}
+
+	c := NewSystemComponentLogsCollector(clientset, runtimeInfo)
+
+	streamed := map[string]string{}
+	err := c.CollectStreams(func(key string, reader io.Reader) error {
+		content, err := io.ReadAll(reader)
+		streamed[key] = string(content)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("CollectStreams() error = %v", err)
+	}
+	if !strings.HasPrefix(streamed["coredns"], "==> coredns-1/main <==") {
+		t.Errorf("unexpected streamed logs %v", streamed)
+	}
+
+	// Streamed data is passed to the handler, rather than held by the collector.
+	if len(c.GetData()) != 0 {
+		t.Errorf("unexpected data held by collector")
+	}
+
+	// A handler which fails without reading the stream must not leave the writer blocked.
+	handlerErr := errors.New("export failed")
+	err = c.CollectStreams(func(key string, reader io.Reader) error {
+		return handlerErr
+	})
+	if !errors.Is(err, handlerErr) {
+		t.Errorf("expected handler error, found %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+// The journal is bounded both by age and by number of lines, since a crash-looping unit can log heavily.
+const (
+	systemdJournalSince    = "-1h"
+	systemdJournalMaxLines = 500
+)
+
+type SystemdUnitInfo struct {
+	Unit         string `json:"unit"`
+	Status       string `json:"status"`
+	StatusError  string `json:"statusError,omitempty"`
+	Journal      string `json:"journal"`
+	JournalError string `json:"journalError,omitempty"`
+}
+
+// SystemdCollector defines a Systemd Collector struct
+type SystemdCollector struct {
+	data         map[string]string
+	osIdentifier utils.OSIdentifier
+	filePaths    *utils.KnownFilePaths
+	fileSystem   interfaces.FileSystemAccessor
+	runCommand   utils.HostCommandRunner
+	runtimeInfo  *utils.RuntimeInfo
+}
+
+// NewSystemdCollector is a constructor
+func NewSystemdCollector(osIdentifier utils.OSIdentifier, filePaths *utils.KnownFilePaths, fileSystem interfaces.FileSystemAccessor, runCommand utils.HostCommandRunner, runtimeInfo *utils.RuntimeInfo) *SystemdCollector {
+	return &SystemdCollector{
+		data:         make(map[string]string),
+		osIdentifier: osIdentifier,
+		filePaths:    filePaths,
+		fileSystem:   fileSystem,
+		runCommand:   runCommand,
+		runtimeInfo:  runtimeInfo,
+	}
+}
+
+func (collector *SystemdCollector) GetName() string {
+	return "systemd"
+}
+
+func (collector *SystemdCollector) CheckSupported() error {
+	if collector.osIdentifier != utils.Linux {
+		return fmt.Errorf("unsupported OS: %s", collector.osIdentifier)
+	}
+
+	// This directory exists only if the host was booted with systemd (see sd_booted(3)).
+	booted, err := collector.fileSystem.FileExists(collector.filePaths.SystemdRuntime)
+	if err != nil {
+		return fmt.Errorf("error checking for systemd: %w", err)
+	}
+	if !booted {
+		return fmt.Errorf("host is not running systemd: %s not found", collector.filePaths.SystemdRuntime)
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *SystemdCollector) Collect() error {
+	for _, unit := range collector.runtimeInfo.SystemdUnits {
+		info := SystemdUnitInfo{Unit: unit}
+
+		// `systemctl status` exits with a non-zero code for inactive or failed units, which are exactly the ones
+		// we're interested in, so failures are recorded rather than aborting the collection.
+		status, err := collector.runCommand("systemctl", "status", unit, "--no-pager", "--full")
+		if err != nil {
+			info.StatusError = err.Error()
+		}
+		info.Status = status
+
+		journal, err := collector.runCommand("journalctl", "-u", unit, "--since", systemdJournalSince, "--lines", strconv.Itoa(systemdJournalMaxLines), "--no-pager", "-o", "short-iso")
+		if err != nil {
+			info.JournalError = err.Error()
+		}
+		info.Journal = journal
+
+		data, err := json.Marshal(info)
+		if err != nil {
+			return fmt.Errorf("marshall systemd unit %s to json: %w", unit, err)
+		}
+
+		collector.data[unit] = string(data)
+	}
+
+	return nil
+}
+
+func (collector *SystemdCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
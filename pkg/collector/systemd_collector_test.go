@@ -0,0 +1,127 @@
+package collector
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+func TestSystemdCollectorGetName(t *testing.T) {
+	const expectedName = "systemd"
+
+	c := NewSystemdCollector("", nil, nil, nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestSystemdCollectorCheckSupported(t *testing.T) {
+	filePaths := &utils.KnownFilePaths{SystemdRuntime: "/run/systemd/system"}
+
+	tests := []struct {
+		name         string
+		osIdentifier utils.OSIdentifier
+		files        map[string]string
+		wantErr      bool
+	}{
+		{
+			name:         "windows",
+			osIdentifier: utils.Windows,
+			files:        map[string]string{filePaths.SystemdRuntime: ""},
+			wantErr:      true,
+		},
+		{
+			name:         "linux without systemd",
+			osIdentifier: utils.Linux,
+			files:        map[string]string{},
+			wantErr:      true,
+		},
+		{
+			name:         "linux with systemd",
+			osIdentifier: utils.Linux,
+			files:        map[string]string{filePaths.SystemdRuntime: ""},
+			wantErr:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewSystemdCollector(tt.osIdentifier, filePaths, test.NewFakeFileSystem(tt.files), nil, nil)
+			err := c.CheckSupported()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckSupported() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSystemdCollectorCollect(t *testing.T) {
+	commands := []string{}
+	runCommand := func(command string, arg ...string) (string, error) {
+		commandLine := strings.Join(append([]string{command}, arg...), " ")
+		commands = append(commands, commandLine)
+
+		switch {
+		case strings.HasPrefix(commandLine, "systemctl status kubelet"):
+			return "● kubelet.service - Kubelet\n     Active: active (running)\n", nil
+		case strings.HasPrefix(commandLine, "systemctl status containerd"):
+			return "", errors.New("fail to run command on host: exit status 3: ● containerd.service\n     Active: failed (Result: exit-code)")
+		case strings.HasPrefix(commandLine, "journalctl -u kubelet"):
+			return "2023-06-15T12:00:00+0000 node-1 kubelet[1234]: I0615 Started kubelet\n", nil
+		case strings.HasPrefix(commandLine, "journalctl -u containerd"):
+			return "2023-06-15T12:00:00+0000 node-1 systemd[1]: containerd.service: Failed with result 'exit-code'.\n", nil
+		}
+		return "", errors.New("unexpected command")
+	}
+
+	runtimeInfo := &utils.RuntimeInfo{
+		SystemdUnits: []string{"kubelet", "containerd"},
+	}
+
+	c := NewSystemdCollector(utils.Linux, nil, nil, runCommand, runtimeInfo)
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	for _, commandLine := range commands {
+		if strings.HasPrefix(commandLine, "journalctl") && (!strings.Contains(commandLine, "--since -1h") || !strings.Contains(commandLine, "--lines 500")) {
+			t.Errorf("expected journal to be bounded, found command %s", commandLine)
+		}
+	}
+
+	data := c.GetData()
+	if len(data) != 2 {
+		t.Fatalf("expected data for 2 units, found %d", len(data))
+	}
+
+	testDataValue(t, data["kubelet"], func(raw string) {
+		var info SystemdUnitInfo
+		if err := json.Unmarshal([]byte(raw), &info); err != nil {
+			t.Fatalf("unmarshal GetData(): %v", err)
+		}
+		if !strings.Contains(info.Status, "active (running)") || info.StatusError != "" {
+			t.Errorf("unexpected kubelet status %+v", info)
+		}
+		if !strings.Contains(info.Journal, "Started kubelet") {
+			t.Errorf("unexpected kubelet journal %s", info.Journal)
+		}
+	})
+
+	testDataValue(t, data["containerd"], func(raw string) {
+		var info SystemdUnitInfo
+		if err := json.Unmarshal([]byte(raw), &info); err != nil {
+			t.Fatalf("unmarshal GetData(): %v", err)
+		}
+		if !strings.Contains(info.StatusError, "Active: failed") {
+			t.Errorf("expected containerd status error, found %+v", info)
+		}
+		if !strings.Contains(info.Journal, "Failed with result") {
+			t.Errorf("unexpected containerd journal %s", info.Journal)
+		}
+	})
+}
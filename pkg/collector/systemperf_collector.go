@@ -8,16 +8,17 @@ import (
 
 	"github.com/Azure/aks-periscope/pkg/interfaces"
 	"github.com/Azure/aks-periscope/pkg/utils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	restclient "k8s.io/client-go/rest"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
 	metrics "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
 // SystemPerfCollector defines a SystemPerf Collector struct
 type SystemPerfCollector struct {
-	data        map[string]string
-	kubeconfig  *restclient.Config
-	runtimeInfo *utils.RuntimeInfo
+	data          map[string]string
+	metricsClient metrics.Interface
+	runtimeInfo   *utils.RuntimeInfo
 }
 
 type NodeMetrics struct {
@@ -33,11 +34,11 @@ type PodMetrics struct {
 }
 
 // NewSystemPerfCollector is a constructor
-func NewSystemPerfCollector(config *restclient.Config, runtimeInfo *utils.RuntimeInfo) *SystemPerfCollector {
+func NewSystemPerfCollector(metricsClient metrics.Interface, runtimeInfo *utils.RuntimeInfo) *SystemPerfCollector {
 	return &SystemPerfCollector{
-		data:        make(map[string]string),
-		kubeconfig:  config,
-		runtimeInfo: runtimeInfo,
+		data:          make(map[string]string),
+		metricsClient: metricsClient,
+		runtimeInfo:   runtimeInfo,
 	}
 }
 
@@ -50,17 +51,26 @@ func (collector *SystemPerfCollector) CheckSupported() error {
 		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
 	}
 
+	// The metrics API is served by metrics-server through API aggregation, so is only registered if it is installed.
+	groupVersion := metricsv1beta1.SchemeGroupVersion.String()
+	_, err := collector.metricsClient.Discovery().ServerResourcesForGroupVersion(groupVersion)
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("metrics-server not installed: API %s is not registered", groupVersion)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to discover API %s: %w", groupVersion, err)
+	}
+
 	return nil
 }
 
 // Collect implements the interface method
 func (collector *SystemPerfCollector) Collect() error {
-	metric, err := metrics.NewForConfig(collector.kubeconfig)
-	if err != nil {
-		return fmt.Errorf("metrics for config error: %w", err)
-	}
-
-	nodeMetrics, err := metric.MetricsV1beta1().NodeMetricses().List(context.TODO(), metav1.ListOptions{})
+	var nodeMetrics *metricsv1beta1.NodeMetricsList
+	err := utils.RetryAPICall(func() (err error) {
+		nodeMetrics, err = collector.metricsClient.MetricsV1beta1().NodeMetricses().List(context.TODO(), metav1.ListOptions{})
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("node metrics error: %w", err)
 	}
@@ -89,7 +99,11 @@ func (collector *SystemPerfCollector) Collect() error {
 
 	collector.data["nodes"] = string(jsonNodeResult)
 
-	podMetrics, err := metric.MetricsV1beta1().PodMetricses(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	var podMetrics *metricsv1beta1.PodMetricsList
+	err = utils.RetryAPICall(func() (err error) {
+		podMetrics, err = collector.metricsClient.MetricsV1beta1().PodMetricses(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("pod metrics failure: %w", err)
 	}
@@ -2,10 +2,16 @@ package collector
 
 import (
 	"encoding/json"
+	"reflect"
 	"testing"
 
-	"github.com/Azure/aks-periscope/pkg/test"
 	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
 )
 
 func TestSystemPerfCollectorGetName(t *testing.T) {
@@ -19,28 +25,45 @@ func TestSystemPerfCollectorGetName(t *testing.T) {
 }
 
 func TestSystemPerfCollectorCheckSupported(t *testing.T) {
+	metricsResources := &metav1.APIResourceList{
+		GroupVersion: metricsv1beta1.SchemeGroupVersion.String(),
+		APIResources: []metav1.APIResource{{Name: "nodes"}, {Name: "pods"}},
+	}
+
 	tests := []struct {
 		name          string
 		collectorList []string
+		resources     []*metav1.APIResourceList
 		wantErr       bool
 	}{
 		{
 			name:          "'connectedCluster' in COLLECTOR_LIST",
 			collectorList: []string{"connectedCluster"},
+			resources:     []*metav1.APIResourceList{metricsResources},
 			wantErr:       true,
 		},
 		{
 			name:          "'connectedCluster' not in COLLECTOR_LIST",
 			collectorList: []string{},
+			resources:     []*metav1.APIResourceList{metricsResources},
 			wantErr:       false,
 		},
+		{
+			name:          "metrics-server not installed",
+			collectorList: []string{},
+			resources:     []*metav1.APIResourceList{},
+			wantErr:       true,
+		},
 	}
 
 	for _, tt := range tests {
 		runtimeInfo := &utils.RuntimeInfo{
 			CollectorList: tt.collectorList,
 		}
-		c := NewSystemPerfCollector(nil, runtimeInfo)
+		metricsClient := metricsfake.NewSimpleClientset()
+		metricsClient.Resources = tt.resources
+
+		c := NewSystemPerfCollector(metricsClient, runtimeInfo)
 		err := c.CheckSupported()
 		if (err != nil) != tt.wantErr {
 			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
@@ -49,43 +72,84 @@ func TestSystemPerfCollectorCheckSupported(t *testing.T) {
 }
 
 func TestSystemPerfCollectorCollect(t *testing.T) {
+	newUsage := func(cpu, memory string) corev1.ResourceList {
+		return corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(memory),
+		}
+	}
+
 	tests := []struct {
-		name    string
-		want    int
-		wantErr bool
+		name     string
+		objects  []runtime.Object
+		wantNode []NodeMetrics
+		wantPod  []PodMetrics
 	}{
 		{
-			name:    "get node logs",
-			want:    1,
-			wantErr: false,
+			name:     "no metrics",
+			objects:  []runtime.Object{},
+			wantNode: []NodeMetrics{},
+			wantPod:  []PodMetrics{},
+		},
+		{
+			name: "node and pod metrics",
+			objects: []runtime.Object{
+				&metricsv1beta1.NodeMetrics{
+					ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+					Usage:      newUsage("250m", "1Gi"),
+				},
+				&metricsv1beta1.PodMetrics{
+					ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "app"},
+					Containers: []metricsv1beta1.ContainerMetrics{{Name: "nginx", Usage: newUsage("10m", "64Mi")}},
+				},
+			},
+			wantNode: []NodeMetrics{{NodeName: "node1", CPUUsage: 250, MemoryUsage: 1 << 30}},
+			wantPod:  []PodMetrics{{ContainerName: "nginx", CPUUsage: 10, MemoryUsage: 64 << 20}},
 		},
 	}
 
-	fixture, _ := test.GetClusterFixture()
-
 	runtimeInfo := &utils.RuntimeInfo{
 		CollectorList: []string{},
 	}
 
-	c := NewSystemPerfCollector(fixture.PeriscopeAccess.ClientConfig, runtimeInfo)
-
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := c.Collect()
-			if (err != nil) != tt.wantErr {
-				t.Errorf("Collect() error = %v, wantErr %v", err, tt.wantErr)
+			// The fake tracker would guess the resource names of the metrics types wrongly, so they are added
+			// under the resources the typed clients list.
+			metricsClient := metricsfake.NewSimpleClientset()
+			for _, object := range tt.objects {
+				gvr := metricsv1beta1.SchemeGroupVersion.WithResource("pods")
+				if _, ok := object.(*metricsv1beta1.NodeMetrics); ok {
+					gvr = metricsv1beta1.SchemeGroupVersion.WithResource("nodes")
+				}
+				namespace := object.(metav1.Object).GetNamespace()
+				if err := metricsClient.Tracker().Create(gvr, object, namespace); err != nil {
+					t.Fatalf("unable to add %T: %v", object, err)
+				}
 			}
 
-			result := c.GetData()["nodes"]
-			testDataValue(t, result, func(raw string) {
-				var nodeMetrices []NodeMetrics
+			c := NewSystemPerfCollector(metricsClient, runtimeInfo)
+			if err := c.Collect(); err != nil {
+				t.Fatalf("Collect() error = %v", err)
+			}
 
-				if err := json.Unmarshal([]byte(raw), &nodeMetrices); err != nil {
-					t.Errorf("unmarshal GetData(): %v", err)
+			data := c.GetData()
+			testDataValue(t, data["nodes"], func(raw string) {
+				var nodes []NodeMetrics
+				if err := json.Unmarshal([]byte(raw), &nodes); err != nil {
+					t.Fatalf("unmarshal nodes: %v", err)
 				}
-
-				if len(nodeMetrices) < tt.want {
-					t.Errorf("len(GetData()) = %v, want %v", len(nodeMetrices), tt.want)
+				if !reflect.DeepEqual(nodes, tt.wantNode) {
+					t.Errorf("unexpected node metrics:\nexpected %+v\nfound    %+v", tt.wantNode, nodes)
+				}
+			})
+			testDataValue(t, data["pods"], func(raw string) {
+				var pods []PodMetrics
+				if err := json.Unmarshal([]byte(raw), &pods); err != nil {
+					t.Fatalf("unmarshal pods: %v", err)
+				}
+				if !reflect.DeepEqual(pods, tt.wantPod) {
+					t.Errorf("unexpected pod metrics:\nexpected %+v\nfound    %+v", tt.wantPod, pods)
 				}
 			})
 		})
@@ -0,0 +1,151 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Kubelets more than this many minor versions older than the API server are outside the supported skew
+// on the versions of Kubernetes AKS supports. See: https://kubernetes.io/releases/version-skew-policy/#kubelet
+const maxKubeletMinorVersionSkew = 1
+
+type VersionSkewReport struct {
+	ServerVersion string            `json:"serverVersion"`
+	Nodes         []NodeVersionInfo `json:"nodes"`
+	Warnings      []string          `json:"warnings"`
+}
+
+type NodeVersionInfo struct {
+	Name                    string `json:"name"`
+	KubeletVersion          string `json:"kubeletVersion"`
+	KubeProxyVersion        string `json:"kubeProxyVersion,omitempty"`
+	ContainerRuntimeVersion string `json:"containerRuntimeVersion"`
+	OSImage                 string `json:"osImage"`
+	KernelVersion           string `json:"kernelVersion"`
+	MinorVersionsBehind     int    `json:"minorVersionsBehind"`
+}
+
+// VersionSkewCollector defines a Version Skew Collector struct
+type VersionSkewCollector struct {
+	data        map[string]string
+	clientset   kubernetes.Interface
+	runtimeInfo *utils.RuntimeInfo
+}
+
+// NewVersionSkewCollector is a constructor
+func NewVersionSkewCollector(clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *VersionSkewCollector {
+	return &VersionSkewCollector{
+		data:        make(map[string]string),
+		clientset:   clientset,
+		runtimeInfo: runtimeInfo,
+	}
+}
+
+func (collector *VersionSkewCollector) GetName() string {
+	return "versionskew"
+}
+
+func (collector *VersionSkewCollector) CheckSupported() error {
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *VersionSkewCollector) Collect() error {
+	serverVersionInfo, err := collector.clientset.Discovery().ServerVersion()
+	if err != nil {
+		return fmt.Errorf("unable to get API server version: %w", err)
+	}
+
+	report := VersionSkewReport{
+		ServerVersion: serverVersionInfo.GitVersion,
+		Nodes:         []NodeVersionInfo{},
+		Warnings:      []string{},
+	}
+
+	serverVersion, err := version.ParseGeneric(serverVersionInfo.GitVersion)
+	if err != nil {
+		return fmt.Errorf("unable to parse API server version %s: %w", serverVersionInfo.GitVersion, err)
+	}
+
+	err = utils.ListAllPages(metav1.ListOptions{}, func(listOptions metav1.ListOptions) (string, error) {
+		nodeList, err := collector.clientset.CoreV1().Nodes().List(context.Background(), listOptions)
+		if err != nil {
+			return "", err
+		}
+
+		for _, node := range nodeList.Items {
+			nodeInfo := node.Status.NodeInfo
+			info := NodeVersionInfo{
+				Name:                    node.Name,
+				KubeletVersion:          nodeInfo.KubeletVersion,
+				KubeProxyVersion:        nodeInfo.KubeProxyVersion,
+				ContainerRuntimeVersion: nodeInfo.ContainerRuntimeVersion,
+				OSImage:                 nodeInfo.OSImage,
+				KernelVersion:           nodeInfo.KernelVersion,
+			}
+
+			kubeletVersion, err := version.ParseGeneric(nodeInfo.KubeletVersion)
+			if err != nil {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("node %s: unable to parse kubelet version '%s'", node.Name, nodeInfo.KubeletVersion))
+			} else {
+				info.MinorVersionsBehind = minorVersionsBehind(serverVersion, kubeletVersion)
+				if info.MinorVersionsBehind > maxKubeletMinorVersionSkew {
+					report.Warnings = append(report.Warnings, fmt.Sprintf("node %s: kubelet %s is %d minor versions behind the API server %s", node.Name, nodeInfo.KubeletVersion, info.MinorVersionsBehind, serverVersionInfo.GitVersion))
+				} else if info.MinorVersionsBehind < 0 {
+					report.Warnings = append(report.Warnings, fmt.Sprintf("node %s: kubelet %s is newer than the API server %s", node.Name, nodeInfo.KubeletVersion, serverVersionInfo.GitVersion))
+				}
+			}
+
+			report.Nodes = append(report.Nodes, info)
+		}
+
+		return nodeList.Continue, nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list nodes: %w", err)
+	}
+
+	sort.Slice(report.Nodes, func(i, j int) bool {
+		return report.Nodes[i].Name < report.Nodes[j].Name
+	})
+	sort.Strings(report.Warnings)
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("marshall version skew to json: %w", err)
+	}
+
+	if err := validateOutput(collector.runtimeInfo, collector.GetName(), "version-skew", data); err != nil {
+		return err
+	}
+
+	collector.data["version-skew"] = string(data)
+
+	return nil
+}
+
+// minorVersionsBehind returns how many minor versions the node version is behind the server version,
+// which is negative if the node version is newer. Major versions are weighted so that they always dominate.
+func minorVersionsBehind(serverVersion, nodeVersion *version.Version) int {
+	const minorVersionsPerMajor = 1000
+	server := int(serverVersion.Major())*minorVersionsPerMajor + int(serverVersion.Minor())
+	node := int(nodeVersion.Major())*minorVersionsPerMajor + int(nodeVersion.Minor())
+	return server - node
+}
+
+func (collector *VersionSkewCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
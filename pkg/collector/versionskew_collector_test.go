@@ -0,0 +1,129 @@
+package collector
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestVersionSkewCollectorGetName(t *testing.T) {
+	const expectedName = "versionskew"
+
+	c := NewVersionSkewCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestVersionSkewCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewVersionSkewCollector(nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestVersionSkewCollectorCollect(t *testing.T) {
+	newNode := func(name, kubeletVersion string) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{
+				NodeInfo: corev1.NodeSystemInfo{
+					KubeletVersion:          kubeletVersion,
+					KubeProxyVersion:        kubeletVersion,
+					ContainerRuntimeVersion: "containerd://1.7.5-1",
+					OSImage:                 "Ubuntu 22.04.3 LTS",
+					KernelVersion:           "5.15.0-1049-azure",
+				},
+			},
+		}
+	}
+
+	clientset := fake.NewSimpleClientset()
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{Major: "1", Minor: "28", GitVersion: "v1.28.3"}
+
+	// The fake clientset doesn't paginate, so serve the nodes over two pages.
+	pages := []*corev1.NodeList{
+		{ListMeta: metav1.ListMeta{Continue: "page-2"}, Items: []corev1.Node{newNode("node-2", "v1.26.6"), newNode("node-0", "v1.28.3")}},
+		{Items: []corev1.Node{newNode("node-1", "v1.27.7"), newNode("node-3", "v1.29.0"), newNode("node-4", "unknown")}},
+	}
+	nodeListCalls := 0
+	clientset.PrependReactor("list", "nodes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		page := pages[nodeListCalls]
+		nodeListCalls++
+		return true, page, nil
+	})
+
+	c := NewVersionSkewCollector(clientset, &utils.RuntimeInfo{OutputSchemaValidation: utils.OutputSchemaValidationFail})
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	if nodeListCalls != len(pages) {
+		t.Errorf("expected %d node list calls, found %d", len(pages), nodeListCalls)
+	}
+
+	testDataValue(t, c.GetData()["version-skew"], func(raw string) {
+		var report VersionSkewReport
+		if err := json.Unmarshal([]byte(raw), &report); err != nil {
+			t.Fatalf("unmarshal GetData(): %v", err)
+		}
+
+		if report.ServerVersion != "v1.28.3" {
+			t.Errorf("unexpected server version %s", report.ServerVersion)
+		}
+
+		behind := map[string]int{}
+		for _, node := range report.Nodes {
+			behind[node.Name] = node.MinorVersionsBehind
+			if node.ContainerRuntimeVersion != "containerd://1.7.5-1" {
+				t.Errorf("unexpected container runtime version %s for %s", node.ContainerRuntimeVersion, node.Name)
+			}
+		}
+		expectedBehind := map[string]int{"node-0": 0, "node-1": 1, "node-2": 2, "node-3": -1, "node-4": 0}
+		if !reflect.DeepEqual(behind, expectedBehind) {
+			t.Errorf("unexpected minor versions behind:\nexpected %v\nfound    %v", expectedBehind, behind)
+		}
+
+		expectedWarnings := []string{
+			"node node-2: kubelet v1.26.6 is 2 minor versions behind the API server v1.28.3",
+			"node node-3: kubelet v1.29.0 is newer than the API server v1.28.3",
+			"node node-4: unable to parse kubelet version 'unknown'",
+		}
+		if !reflect.DeepEqual(report.Warnings, expectedWarnings) {
+			t.Errorf("unexpected warnings:\nexpected %v\nfound    %v", expectedWarnings, report.Warnings)
+		}
+	})
+}
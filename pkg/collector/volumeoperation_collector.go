@@ -0,0 +1,159 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Event reasons emitted by the external resizer and snapshot controllers when a volume operation fails.
+var volumeOperationFailureReasons = []string{"VolumeResizeFailed", "SnapshotFailed"}
+
+type VolumeOperationCondition struct {
+	Type               string    `json:"type"`
+	Status             string    `json:"status"`
+	Message            string    `json:"message,omitempty"`
+	LastTransitionTime time.Time `json:"lastTransitionTime"`
+}
+
+type VolumeOperationFailure struct {
+	Reason        string    `json:"reason"`
+	Message       string    `json:"message"`
+	Count         int32     `json:"count"`
+	LastTimestamp time.Time `json:"lastTimestamp"`
+}
+
+type VolumeOperationInfo struct {
+	Kind       string                     `json:"kind"`
+	Namespace  string                     `json:"namespace"`
+	Name       string                     `json:"name"`
+	VolumeName string                     `json:"volumeName,omitempty"`
+	Conditions []VolumeOperationCondition `json:"conditions"`
+	Failures   []VolumeOperationFailure   `json:"failures"`
+}
+
+// VolumeOperationCollector defines a Volume Operation Collector struct
+type VolumeOperationCollector struct {
+	data        map[string]string
+	clientset   kubernetes.Interface
+	runtimeInfo *utils.RuntimeInfo
+}
+
+// NewVolumeOperationCollector is a constructor
+func NewVolumeOperationCollector(clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *VolumeOperationCollector {
+	return &VolumeOperationCollector{
+		data:        make(map[string]string),
+		clientset:   clientset,
+		runtimeInfo: runtimeInfo,
+	}
+}
+
+func (collector *VolumeOperationCollector) GetName() string {
+	return "volumeoperations"
+}
+
+func (collector *VolumeOperationCollector) CheckSupported() error {
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *VolumeOperationCollector) Collect() error {
+	ctx := context.Background()
+
+	// Keyed by kind/namespace/name, so that conditions and events for the same object are grouped together.
+	affected := map[string]*VolumeOperationInfo{}
+	getInfo := func(kind, namespace, name string) *VolumeOperationInfo {
+		key := fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+		info, ok := affected[key]
+		if !ok {
+			info = &VolumeOperationInfo{
+				Kind:       kind,
+				Namespace:  namespace,
+				Name:       name,
+				Conditions: []VolumeOperationCondition{},
+				Failures:   []VolumeOperationFailure{},
+			}
+			affected[key] = info
+		}
+		return info
+	}
+
+	pvcList, err := collector.clientset.CoreV1().PersistentVolumeClaims(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list persistent volume claims: %w", err)
+	}
+
+	for _, pvc := range pvcList.Items {
+		for _, condition := range pvc.Status.Conditions {
+			if condition.Type != corev1.PersistentVolumeClaimFileSystemResizePending && condition.Type != corev1.PersistentVolumeClaimResizing {
+				continue
+			}
+
+			info := getInfo("PersistentVolumeClaim", pvc.Namespace, pvc.Name)
+			info.VolumeName = pvc.Spec.VolumeName
+			info.Conditions = append(info.Conditions, VolumeOperationCondition{
+				Type:               string(condition.Type),
+				Status:             string(condition.Status),
+				Message:            condition.Message,
+				LastTransitionTime: condition.LastTransitionTime.Time,
+			})
+		}
+	}
+
+	for _, reason := range volumeOperationFailureReasons {
+		eventList, err := collector.clientset.CoreV1().Events(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+			FieldSelector: "reason=" + reason,
+		})
+		if err != nil {
+			return fmt.Errorf("unable to list %s events: %w", reason, err)
+		}
+
+		for _, event := range eventList.Items {
+			if event.Reason != reason {
+				continue
+			}
+
+			info := getInfo(event.InvolvedObject.Kind, event.InvolvedObject.Namespace, event.InvolvedObject.Name)
+			info.Failures = append(info.Failures, VolumeOperationFailure{
+				Reason:        event.Reason,
+				Message:       event.Message,
+				Count:         event.Count,
+				LastTimestamp: event.LastTimestamp.Time,
+			})
+		}
+	}
+
+	result := make([]VolumeOperationInfo, 0, len(affected))
+	for _, info := range affected {
+		result = append(result, *info)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return fmt.Sprintf("%s/%s/%s", result[i].Kind, result[i].Namespace, result[i].Name) < fmt.Sprintf("%s/%s/%s", result[j].Kind, result[j].Namespace, result[j].Name)
+	})
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshall volume operations to json: %w", err)
+	}
+
+	collector.data["volumeoperations"] = string(data)
+
+	return nil
+}
+
+func (collector *VolumeOperationCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
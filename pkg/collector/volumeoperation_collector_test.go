@@ -0,0 +1,118 @@
+package collector
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestVolumeOperationCollectorGetName(t *testing.T) {
+	const expectedName = "volumeoperations"
+
+	c := NewVolumeOperationCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestVolumeOperationCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewVolumeOperationCollector(nil, runtimeInfo)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestVolumeOperationCollectorCollect(t *testing.T) {
+	const failureMessage = "resize volume \"pvc-1234\" by resizer \"disk.csi.azure.com\" failed: disk is attached"
+
+	stuckPvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "app"},
+		Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pvc-1234"},
+		Status: corev1.PersistentVolumeClaimStatus{
+			Conditions: []corev1.PersistentVolumeClaimCondition{
+				{Type: corev1.PersistentVolumeClaimResizing, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+	healthyPvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "app"},
+		Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pvc-5678"},
+	}
+	resizeFailedEvent := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{Name: "data.1", Namespace: "app"},
+		InvolvedObject: corev1.ObjectReference{
+			Kind:      "PersistentVolumeClaim",
+			Namespace: "app",
+			Name:      "data",
+		},
+		Reason:  "VolumeResizeFailed",
+		Message: failureMessage,
+		Count:   3,
+	}
+	unrelatedEvent := &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "logs.1", Namespace: "app"},
+		InvolvedObject: corev1.ObjectReference{Kind: "PersistentVolumeClaim", Namespace: "app", Name: "logs"},
+		Reason:         "ProvisioningSucceeded",
+	}
+
+	clientset := fake.NewSimpleClientset(stuckPvc, healthyPvc, resizeFailedEvent, unrelatedEvent)
+
+	runtimeInfo := &utils.RuntimeInfo{
+		CollectorList: []string{},
+	}
+
+	c := NewVolumeOperationCollector(clientset, runtimeInfo)
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	testDataValue(t, c.GetData()["volumeoperations"], func(raw string) {
+		var result []VolumeOperationInfo
+		if err := json.Unmarshal([]byte(raw), &result); err != nil {
+			t.Fatalf("unmarshal GetData(): %v", err)
+		}
+
+		if len(result) != 1 {
+			t.Fatalf("expected 1 affected volume, found %d: %s", len(result), raw)
+		}
+
+		info := result[0]
+		if info.Name != "data" || info.VolumeName != "pvc-1234" {
+			t.Errorf("unexpected affected volume: %+v", info)
+		}
+		if len(info.Conditions) != 1 || info.Conditions[0].Type != string(corev1.PersistentVolumeClaimResizing) {
+			t.Errorf("expected Resizing condition, found %+v", info.Conditions)
+		}
+		if len(info.Failures) != 1 || info.Failures[0].Reason != "VolumeResizeFailed" || info.Failures[0].Message != failureMessage {
+			t.Errorf("expected VolumeResizeFailed failure, found %+v", info.Failures)
+		}
+	})
+}
@@ -0,0 +1,327 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	webhookKindValidating = "validating"
+	webhookKindMutating   = "mutating"
+	webhookKindConversion = "conversion"
+
+	webhookTargetService = "service"
+	webhookTargetURL     = "url"
+)
+
+type WebhookReport struct {
+	Webhooks []WebhookInfo `json:"webhooks"`
+	Errors   []string      `json:"errors"`
+}
+
+type WebhookInfo struct {
+	Kind          string `json:"kind"`
+	Configuration string `json:"configuration"`
+	Name          string `json:"name,omitempty"`
+	Target        string `json:"target"`
+	Service       string `json:"service,omitempty"`
+	URL           string `json:"url,omitempty"`
+	Address       string `json:"address,omitempty"`
+	// FailurePolicy is empty for conversion webhooks, which have no such setting: if they fail, so does the request.
+	FailurePolicy  string `json:"failurePolicy,omitempty"`
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+	// ReadyEndpoints is the number of ready endpoints of the target service, and is only set for service targets.
+	ReadyEndpoints     *int    `json:"readyEndpoints,omitempty"`
+	TCPConnected       bool    `json:"tcpConnected"`
+	TCPLatencyMs       float64 `json:"tcpLatencyMs,omitempty"`
+	TLSHandshake       bool    `json:"tlsHandshake"`
+	HandshakeLatencyMs float64 `json:"handshakeLatencyMs,omitempty"`
+	Reachable          bool    `json:"reachable"`
+	// Impact describes what happens to API requests handled by the webhook while it is unreachable.
+	Impact string `json:"impact,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// WebhookCollector defines a Webhook Collector struct
+type WebhookCollector struct {
+	data          map[string]string
+	clientset     kubernetes.Interface
+	dynamicClient dynamic.Interface
+	runtimeInfo   *utils.RuntimeInfo
+	probe         utils.TLSEndpointProber
+}
+
+// NewWebhookCollector is a constructor
+func NewWebhookCollector(clientset kubernetes.Interface, dynamicClient dynamic.Interface, runtimeInfo *utils.RuntimeInfo, probe utils.TLSEndpointProber) *WebhookCollector {
+	return &WebhookCollector{
+		data:          make(map[string]string),
+		clientset:     clientset,
+		dynamicClient: dynamicClient,
+		runtimeInfo:   runtimeInfo,
+		probe:         probe,
+	}
+}
+
+func (collector *WebhookCollector) GetName() string {
+	return "webhooks"
+}
+
+func (collector *WebhookCollector) CheckSupported() error {
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *WebhookCollector) Collect() error {
+	ctx := context.Background()
+
+	report := WebhookReport{
+		Webhooks: []WebhookInfo{},
+		Errors:   []string{},
+	}
+
+	validating, err := collector.clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list validating webhook configurations: %w", err)
+	}
+
+	mutating, err := collector.clientset.AdmissionregistrationV1().MutatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list mutating webhook configurations: %w", err)
+	}
+
+	crds, err := collector.dynamicClient.Resource(crdGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list CRDs: %w", err)
+	}
+
+	for _, config := range validating.Items {
+		for _, webhook := range config.Webhooks {
+			info := newWebhookInfo(webhookKindValidating, config.Name, webhook.Name, webhook.ClientConfig)
+			info.FailurePolicy = getFailurePolicy(webhook.FailurePolicy)
+			info.TimeoutSeconds = webhook.TimeoutSeconds
+			report.Webhooks = append(report.Webhooks, info)
+		}
+	}
+
+	for _, config := range mutating.Items {
+		for _, webhook := range config.Webhooks {
+			info := newWebhookInfo(webhookKindMutating, config.Name, webhook.Name, webhook.ClientConfig)
+			info.FailurePolicy = getFailurePolicy(webhook.FailurePolicy)
+			info.TimeoutSeconds = webhook.TimeoutSeconds
+			report.Webhooks = append(report.Webhooks, info)
+		}
+	}
+
+	for i := range crds.Items {
+		crd := &crds.Items[i]
+		strategy, _, _ := unstructured.NestedString(crd.Object, "spec", "conversion", "strategy")
+		if strategy != "Webhook" {
+			continue
+		}
+
+		clientConfig, err := getConversionWebhookClientConfig(crd)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s/%s: %v", webhookKindConversion, crd.GetName(), err))
+			continue
+		}
+
+		report.Webhooks = append(report.Webhooks, newWebhookInfo(webhookKindConversion, crd.GetName(), "", *clientConfig))
+	}
+
+	// Webhooks often share a serving endpoint or service, so each is only checked once.
+	probeResults := map[string]*WebhookInfo{}
+	readyEndpoints := map[string]*int{}
+	for i := range report.Webhooks {
+		info := &report.Webhooks[i]
+		if info.Address == "" {
+			continue
+		}
+
+		if info.Target == webhookTargetService {
+			if _, ok := readyEndpoints[info.Service]; !ok {
+				count, err := collector.countReadyEndpoints(ctx, info.Service)
+				if err != nil {
+					report.Errors = append(report.Errors, fmt.Sprintf("unable to list endpoints for service %s: %v", info.Service, err))
+				}
+				readyEndpoints[info.Service] = count
+			}
+			info.ReadyEndpoints = readyEndpoints[info.Service]
+		}
+
+		result, ok := probeResults[info.Address]
+		if !ok {
+			result = collector.probeAddress(info.Address)
+			probeResults[info.Address] = result
+		}
+
+		info.TCPConnected = result.TCPConnected
+		info.TCPLatencyMs = result.TCPLatencyMs
+		info.TLSHandshake = result.TLSHandshake
+		info.HandshakeLatencyMs = result.HandshakeLatencyMs
+		info.Reachable = result.Reachable
+		info.Error = result.Error
+	}
+
+	for i := range report.Webhooks {
+		report.Webhooks[i].Impact = getWebhookImpact(&report.Webhooks[i])
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("marshall webhooks to json: %w", err)
+	}
+
+	collector.data["webhooks"] = string(data)
+
+	return nil
+}
+
+// newWebhookInfo describes the target of a webhook. Services are called by the API server through the cluster network,
+// so their reachability from this node is a good indicator. URLs may be anywhere the API server can reach, which
+// might not include this node.
+func newWebhookInfo(kind, configName, webhookName string, clientConfig admissionregistrationv1.WebhookClientConfig) WebhookInfo {
+	info := WebhookInfo{
+		Kind:          kind,
+		Configuration: configName,
+		Name:          webhookName,
+	}
+
+	if service := clientConfig.Service; service != nil {
+		info.Target = webhookTargetService
+		info.Service = fmt.Sprintf("%s/%s", service.Namespace, service.Name)
+	} else {
+		info.Target = webhookTargetURL
+		if clientConfig.URL != nil {
+			info.URL = *clientConfig.URL
+		}
+	}
+
+	address, err := getWebhookAddress(&clientConfig)
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+
+	info.Address = address
+	return info
+}
+
+// getConversionWebhookClientConfig reads the client configuration of a CRD conversion webhook, which has the same
+// structure as that of an admission webhook. See:
+// https://kubernetes.io/docs/tasks/extend-kubernetes/custom-resources/custom-resource-definition-versioning/#configure-customresourcedefinition-to-use-conversion-webhooks
+func getConversionWebhookClientConfig(crd *unstructured.Unstructured) (*admissionregistrationv1.WebhookClientConfig, error) {
+	clientConfig, found, err := unstructured.NestedMap(crd.Object, "spec", "conversion", "webhook", "clientConfig")
+	if err != nil {
+		return nil, fmt.Errorf("invalid conversion webhook client config: %w", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("no conversion webhook client config")
+	}
+
+	result := &admissionregistrationv1.WebhookClientConfig{}
+	if url, found, _ := unstructured.NestedString(clientConfig, "url"); found {
+		result.URL = &url
+	}
+
+	if service, found, _ := unstructured.NestedMap(clientConfig, "service"); found {
+		result.Service = &admissionregistrationv1.ServiceReference{}
+		result.Service.Namespace, _, _ = unstructured.NestedString(service, "namespace")
+		result.Service.Name, _, _ = unstructured.NestedString(service, "name")
+		if port, found, _ := unstructured.NestedInt64(service, "port"); found {
+			port32 := int32(port)
+			result.Service.Port = &port32
+		}
+	}
+
+	return result, nil
+}
+
+func (collector *WebhookCollector) probeAddress(address string) *WebhookInfo {
+	result, err := collector.probe(address)
+	info := &WebhookInfo{
+		Reachable: err == nil,
+	}
+	if err != nil {
+		info.Error = err.Error()
+	}
+	if result != nil {
+		info.TCPConnected = result.TCPConnected
+		info.TLSHandshake = result.TLSHandshake
+		if result.TCPConnected {
+			info.TCPLatencyMs = float64(result.TCPLatency) / float64(time.Millisecond)
+		}
+		if result.TLSHandshake {
+			info.HandshakeLatencyMs = float64(result.HandshakeLatency) / float64(time.Millisecond)
+		}
+	}
+
+	return info
+}
+
+// countReadyEndpoints counts the ready endpoints of a service, given as namespace/name.
+func (collector *WebhookCollector) countReadyEndpoints(ctx context.Context, service string) (*int, error) {
+	namespace, name, _ := strings.Cut(service, "/")
+	slices, err := collector.clientset.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", discoveryv1.LabelServiceName, name),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	count := 0
+	for _, slice := range slices.Items {
+		for _, endpoint := range slice.Endpoints {
+			// A nil Ready condition is interpreted as ready.
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				count++
+			}
+		}
+	}
+
+	return &count, nil
+}
+
+// getFailurePolicy returns the failure policy of an admission webhook, which defaults to Fail.
+func getFailurePolicy(policy *admissionregistrationv1.FailurePolicyType) string {
+	if policy == nil {
+		return string(admissionregistrationv1.Fail)
+	}
+
+	return string(*policy)
+}
+
+// getWebhookImpact describes the effect of an unreachable webhook on the API requests it handles.
+func getWebhookImpact(info *WebhookInfo) string {
+	if info.Reachable {
+		return ""
+	}
+
+	switch {
+	case info.Kind == webhookKindConversion:
+		return "requests needing conversion between resource versions fail"
+	case info.FailurePolicy == string(admissionregistrationv1.Ignore):
+		return "matching requests are admitted without the webhook"
+	default:
+		return "matching requests are rejected"
+	}
+}
+
+func (collector *WebhookCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
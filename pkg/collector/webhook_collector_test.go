@@ -0,0 +1,191 @@
+package collector
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWebhookCollectorGetName(t *testing.T) {
+	const expectedName = "webhooks"
+
+	c := NewWebhookCollector(nil, nil, nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestWebhookCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name          string
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			name:          "'connectedCluster' in COLLECTOR_LIST",
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			name:          "'connectedCluster' not in COLLECTOR_LIST",
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &utils.RuntimeInfo{
+			CollectorList: tt.collectorList,
+		}
+		c := NewWebhookCollector(nil, nil, runtimeInfo, nil)
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestWebhookCollectorCollect(t *testing.T) {
+	port := int32(8443)
+	ignore := admissionregistrationv1.Ignore
+	externalURL := "https://external.example.com/validate"
+	ready := true
+	notReady := false
+
+	clientset := fake.NewSimpleClientset(
+		&admissionregistrationv1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy"},
+			Webhooks: []admissionregistrationv1.ValidatingWebhook{
+				{Name: "validate.policy.io", ClientConfig: admissionregistrationv1.WebhookClientConfig{Service: &admissionregistrationv1.ServiceReference{Namespace: "app", Name: "webhook", Port: &port}}},
+				{Name: "external.policy.io", FailurePolicy: &ignore, ClientConfig: admissionregistrationv1.WebhookClientConfig{URL: &externalURL}},
+			},
+		},
+		&admissionregistrationv1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "injector"},
+			Webhooks: []admissionregistrationv1.MutatingWebhook{
+				{Name: "mutate.policy.io", ClientConfig: admissionregistrationv1.WebhookClientConfig{Service: &admissionregistrationv1.ServiceReference{Namespace: "app", Name: "webhook", Port: &port}}},
+			},
+		},
+		&discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "webhook-abc", Labels: map[string]string{discoveryv1.LabelServiceName: "webhook"}},
+			Endpoints: []discoveryv1.Endpoint{
+				{Addresses: []string{"10.0.0.1"}, Conditions: discoveryv1.EndpointConditions{Ready: &ready}},
+				{Addresses: []string{"10.0.0.2"}, Conditions: discoveryv1.EndpointConditions{Ready: &notReady}},
+			},
+		},
+		&discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "other-abc", Labels: map[string]string{discoveryv1.LabelServiceName: "other"}},
+			Endpoints:  []discoveryv1.Endpoint{{Addresses: []string{"10.0.0.3"}}},
+		},
+	)
+
+	newCRD := func(name string, conversion map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apiextensions.k8s.io/v1",
+			"kind":       "CustomResourceDefinition",
+			"metadata":   map[string]interface{}{"name": name},
+			"spec":       map[string]interface{}{"conversion": conversion},
+		}}
+	}
+
+	crdGVR := schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+	dynamicClient := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{crdGVR: "CustomResourceDefinitionList"},
+		newCRD("widgets.example.com", map[string]interface{}{"strategy": "None"}),
+		newCRD("gadgets.example.com", map[string]interface{}{
+			"strategy": "Webhook",
+			"webhook": map[string]interface{}{
+				"clientConfig": map[string]interface{}{
+					"service": map[string]interface{}{"namespace": "gadgets", "name": "converter", "port": int64(9443)},
+				},
+			},
+		}),
+	)
+
+	probedAddresses := []string{}
+	probe := func(address string) (*utils.TLSProbeResult, error) {
+		probedAddresses = append(probedAddresses, address)
+		switch address {
+		case "webhook.app.svc:8443":
+			return &utils.TLSProbeResult{TCPConnected: true, TCPLatency: 2 * time.Millisecond, TLSHandshake: true, HandshakeLatency: 5 * time.Millisecond}, nil
+		case "converter.gadgets.svc:9443":
+			return &utils.TLSProbeResult{TCPConnected: true, TCPLatency: time.Millisecond}, errors.New("handshake failed")
+		default:
+			return &utils.TLSProbeResult{}, errors.New("connection refused")
+		}
+	}
+
+	c := NewWebhookCollector(clientset, dynamicClient, &utils.RuntimeInfo{CollectorList: []string{}}, probe)
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	if !equalStringSlices(probedAddresses, []string{"webhook.app.svc:8443", "external.example.com:443", "converter.gadgets.svc:9443"}) {
+		t.Errorf("unexpected probed addresses %v", probedAddresses)
+	}
+
+	testDataValue(t, c.GetData()["webhooks"], func(raw string) {
+		var report WebhookReport
+		if err := json.Unmarshal([]byte(raw), &report); err != nil {
+			t.Fatalf("unmarshal GetData(): %v", err)
+		}
+
+		if len(report.Errors) != 0 {
+			t.Errorf("unexpected errors %v", report.Errors)
+		}
+
+		expected := map[string]struct {
+			target         string
+			failurePolicy  string
+			readyEndpoints int
+			reachable      bool
+			tlsHandshake   bool
+			impact         string
+		}{
+			"validating/policy/validate.policy.io": {target: "service", failurePolicy: "Fail", readyEndpoints: 1, reachable: true, tlsHandshake: true},
+			"validating/policy/external.policy.io": {target: "url", failurePolicy: "Ignore", readyEndpoints: -1, impact: "matching requests are admitted without the webhook"},
+			"mutating/injector/mutate.policy.io":   {target: "service", failurePolicy: "Fail", readyEndpoints: 1, reachable: true, tlsHandshake: true},
+			"conversion/gadgets.example.com/":      {target: "service", readyEndpoints: 0, impact: "requests needing conversion between resource versions fail"},
+		}
+
+		if len(report.Webhooks) != len(expected) {
+			t.Errorf("expected %d webhooks, found %+v", len(expected), report.Webhooks)
+		}
+		for _, webhook := range report.Webhooks {
+			key := webhook.Kind + "/" + webhook.Configuration + "/" + webhook.Name
+			want, ok := expected[key]
+			if !ok {
+				t.Errorf("unexpected webhook %s", key)
+				continue
+			}
+
+			if webhook.Target != want.target || webhook.FailurePolicy != want.failurePolicy || webhook.Reachable != want.reachable ||
+				webhook.TLSHandshake != want.tlsHandshake || webhook.Impact != want.impact {
+				t.Errorf("unexpected result for %s: %+v", key, webhook)
+			}
+
+			if want.readyEndpoints < 0 {
+				if webhook.ReadyEndpoints != nil {
+					t.Errorf("expected no endpoint count for %s, found %d", key, *webhook.ReadyEndpoints)
+				}
+			} else if webhook.ReadyEndpoints == nil || *webhook.ReadyEndpoints != want.readyEndpoints {
+				t.Errorf("expected %d ready endpoints for %s, found %v", want.readyEndpoints, key, webhook.ReadyEndpoints)
+			}
+
+			if webhook.Reachable && (webhook.TCPLatencyMs != 2 || webhook.HandshakeLatencyMs != 5) {
+				t.Errorf("unexpected latencies for %s: %+v", key, webhook)
+			}
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+// The files written by the host process for each run, by output key. HNS is only accessible from the host, so the
+// cmdlets are run by the same host process container which exports the Windows logs.
+var windowsHNSFiles = map[string]string{
+	"hns-networks":  "networks.json",
+	"hns-endpoints": "endpoints.json",
+	"hns-policies":  "policies.json",
+	"hns-log":       "hns.log",
+}
+
+// WindowsHNSCollector defines a Windows Host Network Service Collector struct
+type WindowsHNSCollector struct {
+	data         map[string]interfaces.DataValue
+	osIdentifier utils.OSIdentifier
+	runtimeInfo  *utils.RuntimeInfo
+	filePaths    *utils.KnownFilePaths
+	fileSystem   interfaces.FileSystemAccessor
+	pollInterval time.Duration
+	timeout      time.Duration
+}
+
+// NewWindowsHNSCollector is a constructor
+func NewWindowsHNSCollector(osIdentifier utils.OSIdentifier, runtimeInfo *utils.RuntimeInfo, filePaths *utils.KnownFilePaths, fileSystem interfaces.FileSystemAccessor, pollInterval, timeout time.Duration) *WindowsHNSCollector {
+	return &WindowsHNSCollector{
+		data:         make(map[string]interfaces.DataValue),
+		osIdentifier: osIdentifier,
+		runtimeInfo:  runtimeInfo,
+		filePaths:    filePaths,
+		fileSystem:   fileSystem,
+		pollInterval: pollInterval,
+		timeout:      timeout,
+	}
+}
+
+func (collector *WindowsHNSCollector) GetName() string {
+	return "windowshns"
+}
+
+func (collector *WindowsHNSCollector) CheckSupported() error {
+	if collector.osIdentifier != utils.Windows {
+		return fmt.Errorf("unsupported OS: %s", collector.osIdentifier)
+	}
+
+	// As for the Windows logs, the HNS state is exported by a host process container which consumers deploy
+	// explicitly, and which writes its output for the current run.
+	if !collector.runtimeInfo.HasFeature(utils.WindowsHpc) {
+		return fmt.Errorf("feature not set: %s", utils.WindowsHpc)
+	}
+
+	if len(collector.runtimeInfo.RunId) == 0 {
+		return errors.New("diagnostic run ID not set")
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *WindowsHNSCollector) Collect() error {
+	err := waitForWindowsLogsOutput(collector.fileSystem, collector.filePaths, collector.runtimeInfo.RunId, collector.pollInterval, collector.timeout)
+	if err != nil {
+		return err
+	}
+
+	hnsDirectory := path.Join(collector.filePaths.WindowsLogsOutput, "hns")
+	for key, fileName := range windowsHNSFiles {
+		filePath := path.Join(hnsDirectory, fileName)
+
+		// The host process skips any cmdlet or log which isn't available on the node.
+		exists, err := collector.fileSystem.FileExists(filePath)
+		if err != nil {
+			return fmt.Errorf("error checking existence of %s: %w", filePath, err)
+		}
+		if !exists {
+			continue
+		}
+
+		size, err := collector.fileSystem.GetFileSize(filePath)
+		if err != nil {
+			return fmt.Errorf("error getting file size %s: %w", filePath, err)
+		}
+
+		collector.data[key] = utils.NewFilePathDataValue(collector.fileSystem, filePath, size)
+	}
+
+	if len(collector.data) == 0 {
+		return fmt.Errorf("no HNS state found in %s", hnsDirectory)
+	}
+
+	return nil
+}
+
+func (collector *WindowsHNSCollector) GetData() map[string]interfaces.DataValue {
+	return collector.data
+}
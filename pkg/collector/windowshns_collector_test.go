@@ -0,0 +1,199 @@
+package collector
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+func TestWindowsHNSCollectorGetName(t *testing.T) {
+	const expectedName = "windowshns"
+
+	c := NewWindowsHNSCollector("", nil, nil, nil, 0, 0)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestWindowsHNSCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		name         string
+		runId        string
+		features     []utils.Feature
+		osIdentifier utils.OSIdentifier
+		wantErr      bool
+	}{
+		{
+			name:         "Run ID not set",
+			runId:        "",
+			features:     []utils.Feature{utils.WindowsHpc},
+			osIdentifier: utils.Windows,
+			wantErr:      true,
+		},
+		{
+			name:         "Feature not set",
+			runId:        "this_run",
+			features:     []utils.Feature{},
+			osIdentifier: utils.Windows,
+			wantErr:      true,
+		},
+		{
+			name:         "Linux",
+			runId:        "this_run",
+			features:     []utils.Feature{utils.WindowsHpc},
+			osIdentifier: utils.Linux,
+			wantErr:      true,
+		},
+		{
+			name:         "Supported",
+			runId:        "this_run",
+			features:     []utils.Feature{utils.WindowsHpc},
+			osIdentifier: utils.Windows,
+			wantErr:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runtimeInfo := &utils.RuntimeInfo{
+				RunId:    tt.runId,
+				Features: map[utils.Feature]bool{},
+			}
+			for _, feature := range tt.features {
+				runtimeInfo.Features[feature] = true
+			}
+
+			c := NewWindowsHNSCollector(tt.osIdentifier, runtimeInfo, nil, nil, 0, 0)
+			err := c.CheckSupported()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckSupported() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWindowsHNSCollectorCollect(t *testing.T) {
+	const runId = "test_run"
+	notificationPath := fmt.Sprintf("/output/%s", runId)
+
+	tests := []struct {
+		name          string
+		exportedFiles map[string]string
+		errorPaths    []string
+		wantErr       bool
+		wantData      map[string]string
+	}{
+		{
+			name: "timeout elapses - no completion notification",
+			exportedFiles: map[string]string{
+				"/output/hns/networks.json": `[{"Name":"azure"}]`,
+			},
+			errorPaths: []string{},
+			wantErr:    true,
+			wantData:   nil,
+		},
+		{
+			name: "no HNS state",
+			exportedFiles: map[string]string{
+				notificationPath:        "",
+				"/output/logs/test.log": "log file content",
+			},
+			errorPaths: []string{},
+			wantErr:    true,
+			wantData:   nil,
+		},
+		{
+			name: "read HNS state error",
+			exportedFiles: map[string]string{
+				notificationPath:            "",
+				"/output/hns/networks.json": `[{"Name":"azure"}]`,
+			},
+			errorPaths: []string{"/output/hns/networks.json"},
+			wantErr:    true,
+			wantData:   nil,
+		},
+		{
+			name: "successful HNS collection without log",
+			exportedFiles: map[string]string{
+				notificationPath:             "",
+				"/output/hns/networks.json":  `[{"Name":"azure"}]`,
+				"/output/hns/endpoints.json": `[{"IPAddress":"10.240.0.20"}]`,
+				"/output/hns/policies.json":  `[]`,
+			},
+			errorPaths: []string{},
+			wantErr:    false,
+			wantData: map[string]string{
+				"hns-networks":  `[{"Name":"azure"}]`,
+				"hns-endpoints": `[{"IPAddress":"10.240.0.20"}]`,
+				"hns-policies":  `[]`,
+			},
+		},
+		{
+			name: "successful HNS collection with log",
+			exportedFiles: map[string]string{
+				notificationPath:            "",
+				"/output/hns/networks.json": `[{"Name":"azure"}]`,
+				"/output/hns/hns.log":       "hns log content",
+			},
+			errorPaths: []string{},
+			wantErr:    false,
+			wantData: map[string]string{
+				"hns-networks": `[{"Name":"azure"}]`,
+				"hns-log":      "hns log content",
+			},
+		},
+	}
+
+	runtimeInfo := &utils.RuntimeInfo{
+		RunId:    runId,
+		Features: map[utils.Feature]bool{utils.WindowsHpc: true},
+	}
+
+	filePaths := &utils.KnownFilePaths{WindowsLogsOutput: "/output"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := test.NewFakeFileSystem(map[string]string{})
+
+			c := NewWindowsHNSCollector(utils.Windows, runtimeInfo, filePaths, fs, time.Microsecond, 10*time.Millisecond)
+
+			for path, content := range tt.exportedFiles {
+				fs.AddOrUpdateFile(path, content)
+			}
+
+			for _, path := range tt.errorPaths {
+				fs.SetFileAccessError(path, fmt.Errorf("expected error accessing %s", path))
+			}
+
+			err := c.Collect()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Collect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			dataItems := c.GetData()
+			if len(dataItems) != len(tt.wantData) {
+				t.Errorf("expected %d items, found %d", len(tt.wantData), len(dataItems))
+			}
+			for key, expectedValue := range tt.wantData {
+				result, ok := dataItems[key]
+				if !ok {
+					t.Errorf("missing key %s", key)
+					continue
+				}
+
+				testDataValue(t, result, func(actualValue string) {
+					if actualValue != expectedValue {
+						t.Errorf("unexpected value for key %s.\nExpected '%s'\nFound '%s'", key, expectedValue, actualValue)
+					}
+				})
+			}
+		})
+	}
+}
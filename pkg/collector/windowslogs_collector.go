@@ -64,18 +64,9 @@ func (collector *WindowsLogsCollector) CheckSupported() error {
 
 // Collect implements the interface method
 func (collector *WindowsLogsCollector) Collect() error {
-	// Exporting the logs is done by a separate process, which will place an empty file in a known
-	// location to indicate completion. The name of that file is the current 'run ID'.
-	completionNotificationPath := path.Join(collector.filePaths.WindowsLogsOutput, collector.runtimeInfo.RunId)
-
-	// Poll to check existence of this file.
-	err := wait.PollUntilContextTimeout(context.Background(), collector.pollInterval, collector.timeout, false,
-		func(context.Context) (bool, error) {
-			return collector.fileSystem.FileExists(completionNotificationPath)
-		})
-
+	err := waitForWindowsLogsOutput(collector.fileSystem, collector.filePaths, collector.runtimeInfo.RunId, collector.pollInterval, collector.timeout)
 	if err != nil {
-		return fmt.Errorf("error waiting for windows log collection: %w", err)
+		return err
 	}
 
 	// We should now expect to find a 'logs' directory containing all the logs for this run.
@@ -98,6 +89,25 @@ func (collector *WindowsLogsCollector) Collect() error {
 	return nil
 }
 
+// waitForWindowsLogsOutput waits for the host process to complete its output for the run. Exporting the logs is done
+// by a separate process, which will place an empty file in a known location to indicate completion. The name of that
+// file is the current 'run ID'.
+func waitForWindowsLogsOutput(fileSystem interfaces.FileSystemAccessor, filePaths *utils.KnownFilePaths, runId string, pollInterval, timeout time.Duration) error {
+	completionNotificationPath := path.Join(filePaths.WindowsLogsOutput, runId)
+
+	// Poll to check existence of this file.
+	err := wait.PollUntilContextTimeout(context.Background(), pollInterval, timeout, false,
+		func(context.Context) (bool, error) {
+			return fileSystem.FileExists(completionNotificationPath)
+		})
+
+	if err != nil {
+		return fmt.Errorf("error waiting for windows log collection: %w", err)
+	}
+
+	return nil
+}
+
 func (collector *WindowsLogsCollector) GetData() map[string]interfaces.DataValue {
 	return collector.data
 }
@@ -0,0 +1,461 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// The kinds of workload in the inventory.
+const (
+	workloadKindDeployment  = "Deployment"
+	workloadKindStatefulSet = "StatefulSet"
+	workloadKindDaemonSet   = "DaemonSet"
+	workloadKindJob         = "Job"
+	workloadKindCronJob     = "CronJob"
+)
+
+type WorkloadInventory struct {
+	Namespaces []WorkloadNamespaceInventory `json:"namespaces"`
+	Images     []WorkloadImage              `json:"images"`
+}
+
+type WorkloadNamespaceInventory struct {
+	Namespace string            `json:"namespace"`
+	Counts    map[string]int    `json:"counts"`
+	Workloads []WorkloadSummary `json:"workloads"`
+}
+
+// WorkloadSummary describes a workload without its full manifest. Desired and Ready are the replicas of deployments
+// and stateful sets, the scheduled and ready pods of daemon sets, and the completions and successful pods of jobs.
+// Cron jobs have neither, and Active is the number of their jobs which are running.
+//
+// App and ManagedBy are taken from the recommended app.kubernetes.io labels (or the Helm release annotations), and
+// Digests are the digests of the images running in the workload's pods.
+type WorkloadSummary struct {
+	Kind         string     `json:"kind"`
+	Name         string     `json:"name"`
+	App          string     `json:"app,omitempty"`
+	ManagedBy    string     `json:"managedBy,omitempty"`
+	Desired      *int32     `json:"desired,omitempty"`
+	Ready        *int32     `json:"ready,omitempty"`
+	Active       int32      `json:"active,omitempty"`
+	Failed       int32      `json:"failed,omitempty"`
+	Suspended    bool       `json:"suspended,omitempty"`
+	Schedule     string     `json:"schedule,omitempty"`
+	LastSchedule *time.Time `json:"lastSchedule,omitempty"`
+	Images       []string   `json:"images"`
+	Digests      []string   `json:"digests"`
+}
+
+// WorkloadImage is an image which pods are running, with the digests it resolved to. An image with more than one
+// digest is a mutable tag which was pulled at different times.
+type WorkloadImage struct {
+	Image   string   `json:"image"`
+	Digests []string `json:"digests"`
+	Pods    int      `json:"pods"`
+}
+
+// WorkloadInventoryCollector defines a Workload Inventory Collector struct
+type WorkloadInventoryCollector struct {
+	data        map[string]string
+	clientset   kubernetes.Interface
+	runtimeInfo *utils.RuntimeInfo
+}
+
+// NewWorkloadInventoryCollector is a constructor
+func NewWorkloadInventoryCollector(clientset kubernetes.Interface, runtimeInfo *utils.RuntimeInfo) *WorkloadInventoryCollector {
+	return &WorkloadInventoryCollector{
+		data:        make(map[string]string),
+		clientset:   clientset,
+		runtimeInfo: runtimeInfo,
+	}
+}
+
+func (collector *WorkloadInventoryCollector) GetName() string {
+	return "workloadinventory"
+}
+
+func (collector *WorkloadInventoryCollector) CheckSupported() error {
+	if utils.Contains(collector.runtimeInfo.CollectorList, "connectedCluster") {
+		return fmt.Errorf("not included because 'connectedCluster' is in COLLECTOR_LIST variable. Included values: %s", strings.Join(collector.runtimeInfo.CollectorList, " "))
+	}
+
+	return nil
+}
+
+// Collect implements the interface method
+func (collector *WorkloadInventoryCollector) Collect() error {
+	ctx := context.Background()
+
+	namespaces := map[string]*WorkloadNamespaceInventory{}
+	add := func(namespace string, meta metav1.ObjectMeta, summary WorkloadSummary, podSpec corev1.PodSpec) {
+		summary.App = getWorkloadLabel(meta, "app.kubernetes.io/name", "app")
+		summary.ManagedBy = getWorkloadLabel(meta, "app.kubernetes.io/managed-by")
+		if len(summary.ManagedBy) == 0 && len(meta.Annotations["meta.helm.sh/release-name"]) > 0 {
+			summary.ManagedBy = "Helm"
+		}
+		summary.Images = getPodSpecImages(podSpec)
+		summary.Digests = []string{}
+
+		inventory, ok := namespaces[namespace]
+		if !ok {
+			inventory = &WorkloadNamespaceInventory{Namespace: namespace, Counts: map[string]int{}, Workloads: []WorkloadSummary{}}
+			namespaces[namespace] = inventory
+		}
+		inventory.Counts[summary.Kind]++
+		inventory.Workloads = append(inventory.Workloads, summary)
+	}
+
+	deployments, err := collector.listDeployments(ctx)
+	if err != nil {
+		return err
+	}
+	statefulSets, err := collector.listStatefulSets(ctx)
+	if err != nil {
+		return err
+	}
+	daemonSets, err := collector.listDaemonSets(ctx)
+	if err != nil {
+		return err
+	}
+	jobs, err := collector.listJobs(ctx)
+	if err != nil {
+		return err
+	}
+	cronJobs, err := collector.listCronJobs(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range deployments {
+		summary := WorkloadSummary{Kind: workloadKindDeployment, Name: d.Name, Desired: getReplicas(d.Spec.Replicas), Ready: getReplicas(&d.Status.ReadyReplicas)}
+		add(d.Namespace, d.ObjectMeta, summary, d.Spec.Template.Spec)
+	}
+	for _, s := range statefulSets {
+		summary := WorkloadSummary{Kind: workloadKindStatefulSet, Name: s.Name, Desired: getReplicas(s.Spec.Replicas), Ready: getReplicas(&s.Status.ReadyReplicas)}
+		add(s.Namespace, s.ObjectMeta, summary, s.Spec.Template.Spec)
+	}
+	for _, d := range daemonSets {
+		summary := WorkloadSummary{Kind: workloadKindDaemonSet, Name: d.Name, Desired: getReplicas(&d.Status.DesiredNumberScheduled), Ready: getReplicas(&d.Status.NumberReady)}
+		add(d.Namespace, d.ObjectMeta, summary, d.Spec.Template.Spec)
+	}
+	for _, j := range jobs {
+		summary := WorkloadSummary{Kind: workloadKindJob, Name: j.Name, Desired: getReplicas(j.Spec.Completions), Ready: getReplicas(&j.Status.Succeeded), Active: j.Status.Active, Failed: j.Status.Failed}
+		add(j.Namespace, j.ObjectMeta, summary, j.Spec.Template.Spec)
+	}
+	for _, c := range cronJobs {
+		summary := WorkloadSummary{Kind: workloadKindCronJob, Name: c.Name, Active: int32(len(c.Status.Active)), Schedule: c.Spec.Schedule}
+		if c.Spec.Suspend != nil {
+			summary.Suspended = *c.Spec.Suspend
+		}
+		if c.Status.LastScheduleTime != nil {
+			lastSchedule := c.Status.LastScheduleTime.Time
+			summary.LastSchedule = &lastSchedule
+		}
+		add(c.Namespace, c.ObjectMeta, summary, c.Spec.JobTemplate.Spec.Template.Spec)
+	}
+
+	// Workloads are keyed by kind, namespace and name, so that pods can be matched to them through their owners. They
+	// are appended to a slice for each namespace, so pointers to them are only taken once all are added.
+	workloads := map[string]*WorkloadSummary{}
+	namespaceNames := []string{}
+	for _, inventory := range namespaces {
+		namespaceNames = append(namespaceNames, inventory.Namespace)
+		for i := range inventory.Workloads {
+			workload := &inventory.Workloads[i]
+			workloads[getWorkloadKey(workload.Kind, inventory.Namespace, workload.Name)] = workload
+		}
+	}
+
+	images, err := collector.addPodDigests(ctx, workloads, jobs)
+	if err != nil {
+		return err
+	}
+
+	result := WorkloadInventory{Namespaces: []WorkloadNamespaceInventory{}, Images: images}
+	sort.Strings(namespaceNames)
+	for _, namespace := range namespaceNames {
+		inventory := namespaces[namespace]
+		sort.Slice(inventory.Workloads, func(i, j int) bool {
+			a, b := inventory.Workloads[i], inventory.Workloads[j]
+			return a.Kind < b.Kind || (a.Kind == b.Kind && a.Name < b.Name)
+		})
+		result.Namespaces = append(result.Namespaces, *inventory)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshall workload inventory to json: %w", err)
+	}
+
+	collector.data["workload-inventory"] = string(data)
+
+	return nil
+}
+
+// addPodDigests adds the digests of the images of running pods to the workloads which own them, and returns every
+// image in use with its digests. Pods of deployments are owned by replica sets, and those of cron jobs by jobs.
+func (collector *WorkloadInventoryCollector) addPodDigests(ctx context.Context, workloads map[string]*WorkloadSummary, jobs []batchv1.Job) ([]WorkloadImage, error) {
+	replicaSets, err := collector.listReplicaSets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The workload which owns each replica set and job, if any.
+	owners := map[string]string{}
+	for _, r := range replicaSets {
+		if owner := metav1.GetControllerOf(&r); owner != nil && owner.Kind == workloadKindDeployment {
+			owners[getWorkloadKey("ReplicaSet", r.Namespace, r.Name)] = getWorkloadKey(workloadKindDeployment, r.Namespace, owner.Name)
+		}
+	}
+	for _, j := range jobs {
+		if owner := metav1.GetControllerOf(&j); owner != nil && owner.Kind == workloadKindCronJob {
+			owners[getWorkloadKey(workloadKindJob, j.Namespace, j.Name)] = getWorkloadKey(workloadKindCronJob, j.Namespace, owner.Name)
+		}
+	}
+
+	images := map[string]*WorkloadImage{}
+	err = utils.ListAllPages(metav1.ListOptions{}, func(listOptions metav1.ListOptions) (string, error) {
+		podList, err := collector.clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, listOptions)
+		if err != nil {
+			return "", err
+		}
+
+		for i := range podList.Items {
+			pod := &podList.Items[i]
+			digests := map[string]bool{}
+			podImages := map[string]bool{}
+			for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+				digest := getImageDigest(status.ImageID)
+				if len(digest) == 0 {
+					continue
+				}
+				digests[digest] = true
+				podImages[status.Image] = true
+
+				image, ok := images[status.Image]
+				if !ok {
+					image = &WorkloadImage{Image: status.Image, Digests: []string{}}
+					images[status.Image] = image
+				}
+				if !utils.Contains(image.Digests, digest) {
+					image.Digests = append(image.Digests, digest)
+				}
+			}
+			for image := range podImages {
+				images[image].Pods++
+			}
+
+			owner := metav1.GetControllerOf(pod)
+			if owner == nil {
+				continue
+			}
+			key := getWorkloadKey(owner.Kind, pod.Namespace, owner.Name)
+			for {
+				if workload, ok := workloads[key]; ok {
+					for digest := range digests {
+						if !utils.Contains(workload.Digests, digest) {
+							workload.Digests = append(workload.Digests, digest)
+						}
+					}
+				}
+				next, ok := owners[key]
+				if !ok {
+					break
+				}
+				key = next
+			}
+		}
+
+		return podList.Continue, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list pods: %w", err)
+	}
+
+	result := []WorkloadImage{}
+	for _, image := range images {
+		sort.Strings(image.Digests)
+		result = append(result, *image)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Image < result[j].Image
+	})
+	for _, workload := range workloads {
+		sort.Strings(workload.Digests)
+	}
+
+	return result, nil
+}
+
+func (collector *WorkloadInventoryCollector) listDeployments(ctx context.Context) ([]appsv1.Deployment, error) {
+	result := []appsv1.Deployment{}
+	err := utils.ListAllPages(metav1.ListOptions{}, func(listOptions metav1.ListOptions) (string, error) {
+		list, err := collector.clientset.AppsV1().Deployments(metav1.NamespaceAll).List(ctx, listOptions)
+		if err != nil {
+			return "", err
+		}
+
+		result = append(result, list.Items...)
+		return list.Continue, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list deployments: %w", err)
+	}
+
+	return result, nil
+}
+
+func (collector *WorkloadInventoryCollector) listStatefulSets(ctx context.Context) ([]appsv1.StatefulSet, error) {
+	result := []appsv1.StatefulSet{}
+	err := utils.ListAllPages(metav1.ListOptions{}, func(listOptions metav1.ListOptions) (string, error) {
+		list, err := collector.clientset.AppsV1().StatefulSets(metav1.NamespaceAll).List(ctx, listOptions)
+		if err != nil {
+			return "", err
+		}
+
+		result = append(result, list.Items...)
+		return list.Continue, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list stateful sets: %w", err)
+	}
+
+	return result, nil
+}
+
+func (collector *WorkloadInventoryCollector) listDaemonSets(ctx context.Context) ([]appsv1.DaemonSet, error) {
+	result := []appsv1.DaemonSet{}
+	err := utils.ListAllPages(metav1.ListOptions{}, func(listOptions metav1.ListOptions) (string, error) {
+		list, err := collector.clientset.AppsV1().DaemonSets(metav1.NamespaceAll).List(ctx, listOptions)
+		if err != nil {
+			return "", err
+		}
+
+		result = append(result, list.Items...)
+		return list.Continue, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list daemon sets: %w", err)
+	}
+
+	return result, nil
+}
+
+func (collector *WorkloadInventoryCollector) listReplicaSets(ctx context.Context) ([]appsv1.ReplicaSet, error) {
+	result := []appsv1.ReplicaSet{}
+	err := utils.ListAllPages(metav1.ListOptions{}, func(listOptions metav1.ListOptions) (string, error) {
+		list, err := collector.clientset.AppsV1().ReplicaSets(metav1.NamespaceAll).List(ctx, listOptions)
+		if err != nil {
+			return "", err
+		}
+
+		result = append(result, list.Items...)
+		return list.Continue, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list replica sets: %w", err)
+	}
+
+	return result, nil
+}
+
+func (collector *WorkloadInventoryCollector) listJobs(ctx context.Context) ([]batchv1.Job, error) {
+	result := []batchv1.Job{}
+	err := utils.ListAllPages(metav1.ListOptions{}, func(listOptions metav1.ListOptions) (string, error) {
+		list, err := collector.clientset.BatchV1().Jobs(metav1.NamespaceAll).List(ctx, listOptions)
+		if err != nil {
+			return "", err
+		}
+
+		result = append(result, list.Items...)
+		return list.Continue, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list jobs: %w", err)
+	}
+
+	return result, nil
+}
+
+func (collector *WorkloadInventoryCollector) listCronJobs(ctx context.Context) ([]batchv1.CronJob, error) {
+	result := []batchv1.CronJob{}
+	err := utils.ListAllPages(metav1.ListOptions{}, func(listOptions metav1.ListOptions) (string, error) {
+		list, err := collector.clientset.BatchV1().CronJobs(metav1.NamespaceAll).List(ctx, listOptions)
+		if err != nil {
+			return "", err
+		}
+
+		result = append(result, list.Items...)
+		return list.Continue, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list cron jobs: %w", err)
+	}
+
+	return result, nil
+}
+
+// getReplicas returns a copy of a number of replicas, which is 1 if unset, as it is for the replicas of deployments
+// and stateful sets and the completions of jobs.
+func getReplicas(replicas *int32) *int32 {
+	result := int32(1)
+	if replicas != nil {
+		result = *replicas
+	}
+	return &result
+}
+
+func getWorkloadKey(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+// getWorkloadLabel returns the value of the first of the given labels which is set.
+func getWorkloadLabel(meta metav1.ObjectMeta, labels ...string) string {
+	for _, label := range labels {
+		if value := meta.Labels[label]; len(value) > 0 {
+			return value
+		}
+	}
+	return ""
+}
+
+// getPodSpecImages returns the distinct images of the init and app containers of a pod spec, in order.
+func getPodSpecImages(spec corev1.PodSpec) []string {
+	images := []string{}
+	for _, container := range append(spec.InitContainers, spec.Containers...) {
+		if !utils.Contains(images, container.Image) {
+			images = append(images, container.Image)
+		}
+	}
+	return images
+}
+
+// getImageDigest returns the digest of the image ID of a container, such as "sha256:...", which runtimes report as
+// "<repository>@<digest>", optionally with a "docker-pullable://" prefix, or as the digest alone.
+func getImageDigest(imageID string) string {
+	digest := imageID
+	if i := strings.LastIndex(imageID, "@"); i >= 0 {
+		digest = imageID[i+1:]
+	}
+	if !strings.Contains(digest, ":") || strings.Contains(digest, "/") {
+		return ""
+	}
+	return digest
+}
+
+func (collector *WorkloadInventoryCollector) GetData() map[string]interfaces.DataValue {
+	return utils.ToDataValueMap(collector.data)
+}
@@ -0,0 +1,183 @@
+package collector
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWorkloadInventoryCollectorGetName(t *testing.T) {
+	const expectedName = "workloadinventory"
+
+	c := NewWorkloadInventoryCollector(nil, nil)
+	actualName := c.GetName()
+	if actualName != expectedName {
+		t.Errorf("unexpected name: expected %s, found %s", expectedName, actualName)
+	}
+}
+
+func TestWorkloadInventoryCollectorCheckSupported(t *testing.T) {
+	tests := []struct {
+		collectorList []string
+		wantErr       bool
+	}{
+		{
+			collectorList: []string{"connectedCluster"},
+			wantErr:       true,
+		},
+		{
+			collectorList: []string{},
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		c := NewWorkloadInventoryCollector(nil, &utils.RuntimeInfo{CollectorList: tt.collectorList})
+		err := c.CheckSupported()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("CheckSupported() error = %v, wantErr %v", err, tt.wantErr)
+		}
+	}
+}
+
+func TestGetImageDigest(t *testing.T) {
+	tests := map[string]string{
+		"docker.io/library/nginx@sha256:abc":         "sha256:abc",
+		"docker-pullable://nginx@sha256:abc":         "sha256:abc",
+		"sha256:abc":                                 "sha256:abc",
+		"mcr.microsoft.com/oss/kubernetes/pause:3.6": "",
+		"": "",
+	}
+
+	for imageID, expected := range tests {
+		if actual := getImageDigest(imageID); actual != expected {
+			t.Errorf("unexpected digest of %q: expected %q, found %q", imageID, expected, actual)
+		}
+	}
+}
+
+func TestWorkloadInventoryCollectorCollect(t *testing.T) {
+	replicas := int32(3)
+	controller := true
+	ownedBy := func(kind, name string) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{Kind: kind, Name: name, Controller: &controller}}
+	}
+	newPod := func(namespace, name, owner, ownerName, image, imageID string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, OwnerReferences: ownedBy(owner, ownerName)},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "main", Image: image, ImageID: imageID}},
+			},
+		}
+	}
+	template := func(image string) corev1.PodTemplateSpec {
+		return corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: image}}}}
+	}
+
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "shop",
+				Name:        "web",
+				Labels:      map[string]string{"app.kubernetes.io/name": "storefront"},
+				Annotations: map[string]string{"meta.helm.sh/release-name": "shop"},
+			},
+			Spec:   appsv1.DeploymentSpec{Replicas: &replicas, Template: template("nginx:1.25")},
+			Status: appsv1.DeploymentStatus{ReadyReplicas: 2},
+		},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web-abc", OwnerReferences: ownedBy("Deployment", "web")}},
+		newPod("shop", "web-abc-1", "ReplicaSet", "web-abc", "nginx:1.25", "docker.io/library/nginx@sha256:bbb"),
+		newPod("shop", "web-abc-2", "ReplicaSet", "web-abc", "nginx:1.25", "docker.io/library/nginx@sha256:aaa"),
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "db", Labels: map[string]string{"app": "postgres", "app.kubernetes.io/managed-by": "operator"}},
+			Spec:       appsv1.StatefulSetSpec{Template: template("postgres:16")},
+			Status:     appsv1.StatefulSetStatus{ReadyReplicas: 1},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "agent"},
+			Spec:       appsv1.DaemonSetSpec{Template: template("agent:1")},
+			Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberReady: 2},
+		},
+		&batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "backup"},
+			Spec:       batchv1.CronJobSpec{Schedule: "0 * * * *", JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: template("backup:2")}}},
+			Status:     batchv1.CronJobStatus{Active: []corev1.ObjectReference{{Name: "backup-123"}}},
+		},
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "backup-123", OwnerReferences: ownedBy("CronJob", "backup")},
+			Spec:       batchv1.JobSpec{Template: template("backup:2")},
+			Status:     batchv1.JobStatus{Active: 1},
+		},
+		newPod("shop", "backup-123-xyz", "Job", "backup-123", "backup:2", "sha256:ccc"),
+	)
+
+	c := NewWorkloadInventoryCollector(clientset, &utils.RuntimeInfo{})
+	if err := c.Collect(); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	testDataValue(t, c.GetData()["workload-inventory"], func(raw string) {
+		var inventory WorkloadInventory
+		if err := json.Unmarshal([]byte(raw), &inventory); err != nil {
+			t.Fatalf("unmarshal GetData(): %v", err)
+		}
+
+		if len(inventory.Namespaces) != 2 || inventory.Namespaces[0].Namespace != "kube-system" || inventory.Namespaces[1].Namespace != "shop" {
+			t.Fatalf("unexpected namespaces: %+v", inventory.Namespaces)
+		}
+
+		shop := inventory.Namespaces[1]
+		expectedCounts := map[string]int{"CronJob": 1, "Deployment": 1, "Job": 1, "StatefulSet": 1}
+		if !reflect.DeepEqual(shop.Counts, expectedCounts) {
+			t.Errorf("unexpected counts %v", shop.Counts)
+		}
+
+		workloads := map[string]WorkloadSummary{}
+		for _, workload := range shop.Workloads {
+			workloads[workload.Kind+"/"+workload.Name] = workload
+		}
+
+		web := workloads["Deployment/web"]
+		if *web.Desired != 3 || *web.Ready != 2 || web.App != "storefront" || web.ManagedBy != "Helm" {
+			t.Errorf("unexpected deployment %+v", web)
+		}
+		if !reflect.DeepEqual(web.Images, []string{"nginx:1.25"}) || !reflect.DeepEqual(web.Digests, []string{"sha256:aaa", "sha256:bbb"}) {
+			t.Errorf("unexpected deployment images %v, digests %v", web.Images, web.Digests)
+		}
+
+		db := workloads["StatefulSet/db"]
+		if *db.Desired != 1 || *db.Ready != 1 || db.App != "postgres" || db.ManagedBy != "operator" || len(db.Digests) != 0 {
+			t.Errorf("unexpected stateful set %+v", db)
+		}
+
+		job := workloads["Job/backup-123"]
+		if *job.Desired != 1 || *job.Ready != 0 || job.Active != 1 || !reflect.DeepEqual(job.Digests, []string{"sha256:ccc"}) {
+			t.Errorf("unexpected job %+v", job)
+		}
+
+		backup := workloads["CronJob/backup"]
+		if backup.Desired != nil || backup.Active != 1 || backup.Schedule != "0 * * * *" || !reflect.DeepEqual(backup.Digests, []string{"sha256:ccc"}) {
+			t.Errorf("unexpected cron job %+v", backup)
+		}
+
+		agent := inventory.Namespaces[0].Workloads[0]
+		if agent.Kind != "DaemonSet" || *agent.Desired != 3 || *agent.Ready != 2 {
+			t.Errorf("unexpected daemon set %+v", agent)
+		}
+
+		expectedImages := []WorkloadImage{
+			{Image: "backup:2", Digests: []string{"sha256:ccc"}, Pods: 1},
+			{Image: "nginx:1.25", Digests: []string{"sha256:aaa", "sha256:bbb"}, Pods: 2},
+		}
+		if !reflect.DeepEqual(inventory.Images, expectedImages) {
+			t.Errorf("unexpected images %+v", inventory.Images)
+		}
+	})
+}
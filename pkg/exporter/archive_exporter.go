@@ -0,0 +1,223 @@
+package exporter
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+type ArchiveFormat string
+
+const (
+	ArchiveFormatTarGz ArchiveFormat = "tar.gz"
+	ArchiveFormatZip   ArchiveFormat = "zip"
+)
+
+// GetArchiveFormat returns the archive format most easily opened on the given OS.
+func GetArchiveFormat(osIdentifier utils.OSIdentifier) ArchiveFormat {
+	if osIdentifier == utils.Windows {
+		return ArchiveFormatZip
+	}
+	return ArchiveFormatTarGz
+}
+
+// ArchiveExporter wraps an Exporter, exporting all the data of the producers on a node as a single archive. Producers
+// are held as they are exported, and only archived once ExportNodeArchive is called at the end of the run.
+type ArchiveExporter struct {
+	exporter  interfaces.Exporter
+	format    ArchiveFormat
+	lock      sync.Mutex
+	producers []interfaces.DataProducer
+}
+
+func NewArchiveExporter(exporter interfaces.Exporter, format ArchiveFormat) *ArchiveExporter {
+	return &ArchiveExporter{
+		exporter: exporter,
+		format:   format,
+	}
+}
+
+// Export implements the interface method
+func (exporter *ArchiveExporter) Export(producer interfaces.DataProducer) error {
+	exporter.lock.Lock()
+	defer exporter.lock.Unlock()
+
+	exporter.producers = append(exporter.producers, producer)
+	return nil
+}
+
+// ExportNodeArchive exports the data of all the producers exported so far as a single archive named after the node,
+// with entries named by producer and key, as in the node's zip archive.
+func (exporter *ArchiveExporter) ExportNodeArchive(nodeName string) error {
+	exporter.lock.Lock()
+	producers := make([]interfaces.DataProducer, len(exporter.producers))
+	copy(producers, exporter.producers)
+	exporter.lock.Unlock()
+
+	return exportArchive(exporter.exporter, exporter.format, &nodeDataProducer{name: nodeName, producers: producers})
+}
+
+func (exporter *ArchiveExporter) ExportReader(name string, reader io.ReadSeeker) error {
+	return exporter.exporter.ExportReader(name, reader)
+}
+
+// exportArchive writes the producer's data to an archive in a temporary file, so that large values are not held
+// in memory, and exports the archive as a single file named after the producer.
+func exportArchive(exporter interfaces.Exporter, format ArchiveFormat, producer interfaces.DataProducer) error {
+	file, err := os.CreateTemp("", fmt.Sprintf("periscope-%s-*.%s", producer.GetName(), format))
+	if err != nil {
+		return fmt.Errorf("create archive file: %w", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	if err := WriteArchive(file, format, producer); err != nil {
+		return fmt.Errorf("write %s archive: %w", producer.GetName(), err)
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("rewind archive file: %w", err)
+	}
+
+	return exporter.ExportReader(fmt.Sprintf("%s.%s", producer.GetName(), format), file)
+}
+
+// WriteArchive streams each of the producer's data values into an archive entry named by its key.
+func WriteArchive(w io.Writer, format ArchiveFormat, producer interfaces.DataProducer) error {
+	switch format {
+	case ArchiveFormatTarGz:
+		return writeTarGz(w, producer)
+	case ArchiveFormatZip:
+		return writeZip(w, producer)
+	default:
+		return fmt.Errorf("unsupported archive format: %s", format)
+	}
+}
+
+func writeTarGz(w io.Writer, producer interfaces.DataProducer) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	data := producer.GetData()
+	for _, key := range utils.SortedKeys(data) {
+		value := data[key]
+		if _, err := utils.SplitDataKey(key); err != nil {
+			log.Printf("Error creating archive entry: %v", err)
+			continue
+		}
+
+		// Tar entries need their size up front, so we rely on the reported length. If the content has since changed
+		// (e.g. a growing log file), it is truncated or padded with zeros to that length.
+		length := value.GetLength()
+		header := &tar.Header{
+			Name:    key,
+			Mode:    0644,
+			Size:    length,
+			ModTime: time.Now(),
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("write header for %s: %w", key, err)
+		}
+
+		written, err := copyValue(tw, value, length)
+		if err != nil {
+			// As for zip archives, don't let one failed value prevent export of all the others.
+			log.Printf("Error writing archive entry %q: %v", key, err)
+		}
+		if written < length {
+			log.Printf("Warning: archive entry %q is %d bytes shorter than its recorded length, and is padded with zeros", key, length-written)
+			if _, err := io.CopyN(tw, zeroReader{}, length-written); err != nil {
+				return fmt.Errorf("pad content for %s: %w", key, err)
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return gz.Close()
+}
+
+func writeZip(w io.Writer, producer interfaces.DataProducer) error {
+	z := zip.NewWriter(w)
+
+	data := producer.GetData()
+	for _, key := range utils.SortedKeys(data) {
+		value := data[key]
+		if _, err := utils.SplitDataKey(key); err != nil {
+			log.Printf("Error creating archive entry: %v", err)
+			continue
+		}
+
+		dataf, err := z.Create(key)
+		if err != nil {
+			return fmt.Errorf("create entry for %s: %w", key, err)
+		}
+
+		if _, err := copyValue(dataf, value, -1); err != nil {
+			log.Printf("Error writing archive entry %q: %v", key, err)
+		}
+	}
+
+	return z.Close()
+}
+
+// copyValue copies the content of the value to the writer, up to a maximum number of bytes if maxBytes is not negative.
+func copyValue(w io.Writer, value interfaces.DataValue, maxBytes int64) (int64, error) {
+	reader, err := value.GetReader()
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+
+	if maxBytes < 0 {
+		return io.Copy(w, reader)
+	}
+
+	written, err := io.CopyN(w, reader, maxBytes)
+	if errors.Is(err, io.EOF) {
+		err = nil
+	}
+	return written, err
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+// nodeDataProducer combines the data of several producers, prefixing each key with the name of its producer.
+type nodeDataProducer struct {
+	name      string
+	producers []interfaces.DataProducer
+}
+
+func (p *nodeDataProducer) GetName() string {
+	return p.name
+}
+
+func (p *nodeDataProducer) GetData() map[string]interfaces.DataValue {
+	data := map[string]interfaces.DataValue{}
+	for _, producer := range p.producers {
+		for key, value := range producer.GetData() {
+			data[producer.GetName()+utils.DataKeySeparator+key] = value
+		}
+	}
+
+	return data
+}
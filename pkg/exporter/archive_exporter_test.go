@@ -0,0 +1,173 @@
+package exporter
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+type recordingExporter struct {
+	exported map[string][]byte
+}
+
+func (e *recordingExporter) Export(producer interfaces.DataProducer) error {
+	return nil
+}
+
+func (e *recordingExporter) ExportReader(name string, reader io.ReadSeeker) error {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	e.exported[name] = content
+	return nil
+}
+
+func readTarGzEntries(t *testing.T, content []byte) map[string]string {
+	gz, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("error reading gzip content: %v", err)
+	}
+
+	entries := map[string]string{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("error reading tar content: %v", err)
+		}
+		entry, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("error reading tar entry: %v", err)
+		}
+		entries[header.Name] = string(entry)
+	}
+
+	return entries
+}
+
+func readZipEntries(t *testing.T, content []byte) map[string]string {
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatalf("error reading zip content: %v", err)
+	}
+
+	entries := map[string]string{}
+	for _, file := range zr.File {
+		entry, err := utils.GetContent(file.Open)
+		if err != nil {
+			t.Fatalf("error reading zip entry: %v", err)
+		}
+		entries[file.Name] = entry
+	}
+
+	return entries
+}
+
+func TestArchiveExporterExport(t *testing.T) {
+	fs := test.NewFakeFileSystem(map[string]string{"/var/log/grown.log": "0123456789"})
+
+	producer := &testDataProducer{
+		name: "test",
+		data: map[string]interfaces.DataValue{
+			"key1": utils.NewStringDataValue("value1"),
+			"key2": utils.NewStringDataValue(""),
+			// The file has grown since its length was read.
+			"grown": utils.NewFilePathDataValue(fs, "/var/log/grown.log", 4),
+			// Nested keys become directories, escaped segments are kept as file names, and invalid keys are skipped.
+			"nested/dir/key3":               utils.NewStringDataValue("value3"),
+			utils.JoinDataKey("/var/log/x"): utils.NewStringDataValue("x"),
+			"../escaped":                    utils.NewStringDataValue("escaped"),
+		},
+	}
+	other := &testDataProducer{
+		name: "other",
+		data: map[string]interfaces.DataValue{"key1": utils.NewStringDataValue("other1")},
+	}
+
+	tests := []struct {
+		format      ArchiveFormat
+		wantName    string
+		readEntries func(*testing.T, []byte) map[string]string
+		wantEntries map[string]string
+	}{
+		{
+			format:      ArchiveFormatTarGz,
+			wantName:    "node-1.tar.gz",
+			readEntries: readTarGzEntries,
+			wantEntries: map[string]string{"test/key1": "value1", "test/key2": "", "test/grown": "0123", "test/nested/dir/key3": "value3", "test/%2Fvar%2Flog%2Fx": "x", "other/key1": "other1"},
+		},
+		{
+			format:      ArchiveFormatZip,
+			wantName:    "node-1.zip",
+			readEntries: readZipEntries,
+			wantEntries: map[string]string{"test/key1": "value1", "test/key2": "", "test/grown": "0123456789", "test/nested/dir/key3": "value3", "test/%2Fvar%2Flog%2Fx": "x", "other/key1": "other1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			inner := &recordingExporter{exported: map[string][]byte{}}
+			exporter := NewArchiveExporter(inner, tt.format)
+			for _, p := range []interfaces.DataProducer{producer, other} {
+				if err := exporter.Export(p); err != nil {
+					t.Fatalf("Export() error = %v", err)
+				}
+			}
+
+			// Nothing is exported until the node's archive is complete.
+			if len(inner.exported) != 0 {
+				t.Fatalf("expected no exports before the node archive, found %d", len(inner.exported))
+			}
+			if err := exporter.ExportNodeArchive("node-1"); err != nil {
+				t.Fatalf("ExportNodeArchive() error = %v", err)
+			}
+
+			if len(inner.exported) != 1 {
+				t.Fatalf("expected a single exported archive, found %d", len(inner.exported))
+			}
+			content, ok := inner.exported[tt.wantName]
+			if !ok {
+				t.Fatalf("expected archive named %s", tt.wantName)
+			}
+
+			entries := tt.readEntries(t, content)
+			if len(entries) != len(tt.wantEntries) {
+				t.Errorf("expected entries %v, found %v", tt.wantEntries, entries)
+			}
+			for key, expected := range tt.wantEntries {
+				if entries[key] != expected {
+					t.Errorf("unexpected content for %s: expected '%s', found '%s'", key, expected, entries[key])
+				}
+			}
+		})
+	}
+}
+
+func TestWriteArchivePadsShrunkContent(t *testing.T) {
+	fs := test.NewFakeFileSystem(map[string]string{"/var/log/shrunk.log": "01"})
+	producer := &testDataProducer{
+		name: "test",
+		data: map[string]interfaces.DataValue{"shrunk": utils.NewFilePathDataValue(fs, "/var/log/shrunk.log", 4)},
+	}
+
+	buffer := new(bytes.Buffer)
+	if err := WriteArchive(buffer, ArchiveFormatTarGz, producer); err != nil {
+		t.Fatalf("WriteArchive() error = %v", err)
+	}
+
+	entries := readTarGzEntries(t, buffer.Bytes())
+	if entries["shrunk"] != "01\x00\x00" {
+		t.Errorf("unexpected content '%q'", entries["shrunk"])
+	}
+}
@@ -1,12 +1,18 @@
 package exporter
 
 import (
+	"bytes"
 	"context"
+	"crypto/md5"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/url"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/Azure/aks-periscope/pkg/interfaces"
 	"github.com/Azure/aks-periscope/pkg/utils"
@@ -18,6 +24,12 @@ type AzureBlobExporter struct {
 	runtimeInfo    *utils.RuntimeInfo
 	knownFilePaths *utils.KnownFilePaths
 	containerName  string
+
+	// The container URL is created on first use and shared by all exports, so that the pipeline isn't rebuilt and the
+	// container isn't created again for each key. Failures aren't cached, so a later export tries again.
+	createContainerURL func(*utils.RuntimeInfo, *utils.KnownFilePaths) (azblob.ContainerURL, error)
+	containerURLLock   sync.Mutex
+	containerURL       *azblob.ContainerURL
 }
 
 type StorageKeyType string
@@ -30,26 +42,93 @@ var storageKeyTypes = map[string]StorageKeyType{
 	"Container": Container,
 }
 
+// sasExpiryWarningPeriod is the time before SAS expiry after which we warn that uploads may start failing.
+const sasExpiryWarningPeriod = 5 * time.Minute
+
+// The 'se' (signed expiry) field of a SAS token is a UTC time in one of the ISO 8601 formats accepted by Azure Storage.
+// https://learn.microsoft.com/en-us/rest/api/storageservices/formatting-datetime-values
+var sasExpiryLayouts = []string{
+	"2006-01-02T15:04:05Z",
+	"2006-01-02T15:04Z",
+	"2006-01-02",
+}
+
+const (
+	// Payloads larger than this are uploaded in staged blocks, so that a failed upload can be resumed. Smaller ones
+	// are cheap enough to upload again from the start.
+	stagedUploadThreshold = 256 * 1024 * 1024
+	stagedUploadBlockSize = 8 * 1024 * 1024
+
+	stagedUploadMaxAttempts = 3
+	stagedUploadRetryDelay  = 5 * time.Second
+)
+
+const (
+	// The container URL outlives any one access token, so tokens are refreshed well within their lifetime (at least
+	// an hour for both managed and workload identities), and again shortly after a failed refresh.
+	storageTokenRefreshInterval      = 30 * time.Minute
+	storageTokenRefreshRetryInterval = time.Minute
+)
+
+// blockStager is the subset of the methods of azblob.BlockBlobURL used for staged uploads.
+type blockStager interface {
+	GetBlockList(ctx context.Context, listType azblob.BlockListType, ac azblob.LeaseAccessConditions) (*azblob.BlockList, error)
+	StageBlock(ctx context.Context, base64BlockID string, body io.ReadSeeker, ac azblob.LeaseAccessConditions, transactionalMD5 []byte, cpk azblob.ClientProvidedKeyOptions) (*azblob.BlockBlobStageBlockResponse, error)
+	CommitBlockList(ctx context.Context, base64BlockIDs []string, h azblob.BlobHTTPHeaders, metadata azblob.Metadata, ac azblob.BlobAccessConditions, tier azblob.AccessTierType, blobTagsMap azblob.BlobTagsMap, cpk azblob.ClientProvidedKeyOptions, immutability azblob.ImmutabilityPolicyOptions) (*azblob.BlockBlobCommitBlockListResponse, error)
+}
+
 func NewAzureBlobExporter(runtimeInfo *utils.RuntimeInfo, knownFilePaths *utils.KnownFilePaths, containerName string) *AzureBlobExporter {
 	return &AzureBlobExporter{
 		runtimeInfo:    runtimeInfo,
 		knownFilePaths: knownFilePaths,
 		containerName:  containerName,
+
+		createContainerURL: createContainerURL,
 	}
 }
 
+// getContainerURL returns the container URL shared by all exports, creating it (and the container) on first use.
+func (exporter *AzureBlobExporter) getContainerURL() (azblob.ContainerURL, error) {
+	exporter.containerURLLock.Lock()
+	defer exporter.containerURLLock.Unlock()
+
+	if exporter.containerURL != nil {
+		return *exporter.containerURL, nil
+	}
+
+	containerURL, err := exporter.createContainerURL(exporter.runtimeInfo, exporter.knownFilePaths)
+	if err != nil {
+		return azblob.ContainerURL{}, err
+	}
+
+	exporter.containerURL = &containerURL
+	return containerURL, nil
+}
+
 func createContainerURL(runtimeInfo *utils.RuntimeInfo, knownFilePaths *utils.KnownFilePaths) (azblob.ContainerURL, error) {
-	if runtimeInfo.StorageAccountName == "" || runtimeInfo.StorageSasKey == "" || runtimeInfo.StorageContainerName == "" {
+	// A configured identity takes precedence over the SAS token, which is then not needed.
+	useIdentity := runtimeInfo.StorageIdentity != nil
+	if runtimeInfo.StorageAccountName == "" || runtimeInfo.StorageContainerName == "" || (!useIdentity && runtimeInfo.StorageSasKey == "") {
 		log.Print("Storage Account information were not provided. Export to Azure Storage Account will be skipped.")
 		return azblob.ContainerURL{}, errors.New("Storage not configured.")
 	}
 
+	credential, err := createStorageCredential(runtimeInfo)
+	if err != nil {
+		return azblob.ContainerURL{}, err
+	}
+
 	ctx := context.Background()
 
-	pipeline := azblob.NewPipeline(azblob.NewAnonymousCredential(), azblob.PipelineOptions{})
+	pipeline := azblob.NewPipeline(credential, azblob.PipelineOptions{})
+
+	sasKey := runtimeInfo.StorageSasKey
+	if useIdentity {
+		sasKey = ""
+	}
 
 	ses := utils.GetStorageEndpointSuffix(knownFilePaths)
-	url, err := url.Parse(fmt.Sprintf("https://%s.blob.%s/%s%s", runtimeInfo.StorageAccountName, ses, runtimeInfo.StorageContainerName, runtimeInfo.StorageSasKey))
+	url, err := url.Parse(fmt.Sprintf("https://%s.blob.%s/%s%s", runtimeInfo.StorageAccountName, ses, runtimeInfo.StorageContainerName, sasKey))
 	if err != nil {
 		return azblob.ContainerURL{}, fmt.Errorf("build blob container url: %w", err)
 	}
@@ -77,15 +156,102 @@ func createContainerURL(runtimeInfo *utils.RuntimeInfo, knownFilePaths *utils.Kn
 	return containerURL, nil
 }
 
+// createStorageCredential creates a credential from an access token for the configured identity if there is one, and
+// otherwise an anonymous credential, for which the SAS token in the URL authorizes access.
+func createStorageCredential(runtimeInfo *utils.RuntimeInfo) (azblob.Credential, error) {
+	if runtimeInfo.StorageIdentity == nil {
+		if err := validateSasExpiry(runtimeInfo.StorageSasKey, time.Now()); err != nil {
+			return nil, err
+		}
+		return azblob.NewAnonymousCredential(), nil
+	}
+
+	tokenSource := runtimeInfo.StorageIdentity.GetTokenSource()
+	token, err := tokenSource()
+	if err != nil {
+		return nil, fmt.Errorf("acquire storage access token for client ID %s: %w", runtimeInfo.StorageIdentity.ClientID, err)
+	}
+
+	// The container URL is shared by all exports, so the token is refreshed in the background for as long as it's used.
+	refresh := func(credential azblob.TokenCredential) time.Duration {
+		token, err := tokenSource()
+		if err != nil {
+			log.Printf("Unable to refresh storage access token for client ID %s: %v", runtimeInfo.StorageIdentity.ClientID, err)
+			return storageTokenRefreshRetryInterval
+		}
+
+		credential.SetToken(token)
+		return storageTokenRefreshInterval
+	}
+
+	return azblob.NewTokenCredential(token, refresh), nil
+}
+
+// validateSasExpiry checks the expiry time of the SAS token, so that we can fail early with a clear error
+// rather than for each individual upload. Tokens without a parseable expiry are assumed to be valid.
+func validateSasExpiry(sasKey string, now time.Time) error {
+	query, err := url.ParseQuery(strings.TrimPrefix(sasKey, "?"))
+	if err != nil {
+		return nil
+	}
+
+	signedExpiry := query.Get("se")
+	if signedExpiry == "" {
+		return nil
+	}
+
+	for _, layout := range sasExpiryLayouts {
+		expiry, err := time.Parse(layout, signedExpiry)
+		if err != nil {
+			continue
+		}
+
+		if !now.Before(expiry) {
+			return fmt.Errorf("SAS token expired at %s", expiry.Format(time.RFC3339))
+		}
+
+		if expiry.Sub(now) < sasExpiryWarningPeriod {
+			log.Printf("Warning: SAS token expires at %s, uploads may fail if collection takes longer than %s", expiry.Format(time.RFC3339), expiry.Sub(now).Round(time.Second))
+		}
+
+		return nil
+	}
+
+	return nil
+}
+
+// getBlobName returns the blob path for a key: <runId>/<node>/<key>. The run identifier (EXPORT_RUN_ID, defaulting to
+// DIAGNOSTIC_RUN_ID) distinguishes runs exporting to the same storage container, and is omitted if empty.
+func (exporter *AzureBlobExporter) getBlobName(key string) string {
+	segments := []string{}
+	for _, segment := range []string{exporter.containerName, exporter.runtimeInfo.HostNodeName, key} {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
 // Export implements the interface method
 func (exporter *AzureBlobExporter) Export(producer interfaces.DataProducer) error {
-	containerURL, err := createContainerURL(exporter.runtimeInfo, exporter.knownFilePaths)
+	containerURL, err := exporter.getContainerURL()
 	if err != nil {
 		return err
 	}
 
-	for key, value := range producer.GetData() {
-		blobURL := containerURL.NewBlockBlobURL(fmt.Sprintf("%s/%s/%s", exporter.containerName, exporter.runtimeInfo.HostNodeName, key))
+	data := producer.GetData()
+	for _, key := range utils.SortedKeys(data) {
+		value := data[key]
+		blobURL := containerURL.NewBlockBlobURL(exporter.getBlobName(key))
+
+		skip, err := exporter.skipExisting(blobURL.BlobURL, key)
+		if err != nil {
+			return err
+		}
+		if skip {
+			continue
+		}
 
 		log.Printf("\tAppend blob file: %s (of size %d bytes)", key, value.GetLength())
 
@@ -97,7 +263,7 @@ func (exporter *AzureBlobExporter) Export(producer interfaces.DataProducer) erro
 
 			defer valueReadCloser.Close()
 
-			_, err = azblob.UploadStreamToBlockBlob(context.Background(), valueReadCloser, blobURL, azblob.UploadStreamToBlockBlobOptions{})
+			_, err = azblob.UploadStreamToBlockBlob(context.Background(), valueReadCloser, blobURL, exporter.getUploadOptions())
 			return err
 		}()
 
@@ -110,14 +276,196 @@ func (exporter *AzureBlobExporter) Export(producer interfaces.DataProducer) erro
 }
 
 func (exporter *AzureBlobExporter) ExportReader(name string, reader io.ReadSeeker) error {
-	containerURL, err := createContainerURL(exporter.runtimeInfo, exporter.knownFilePaths)
+	containerURL, err := exporter.getContainerURL()
 	if err != nil {
 		return err
 	}
 
-	blobUrl := containerURL.NewBlockBlobURL(fmt.Sprintf("%s/%s/%s", exporter.containerName, exporter.runtimeInfo.HostNodeName, name))
+	size, err := getRemainingSize(reader)
+	if err != nil {
+		return fmt.Errorf("get size of %s: %w", name, err)
+	}
+
+	blobUrl := containerURL.NewBlockBlobURL(exporter.getBlobName(name))
+	skip, err := exporter.skipExisting(blobUrl.BlobURL, name)
+	if err != nil || skip {
+		return err
+	}
+
+	if size > stagedUploadThreshold {
+		log.Printf("Uploading the file with blob name: %s in staged blocks (%d bytes)\n", name, size)
+		return uploadInStagedBlocks(context.Background(), blobUrl, reader, stagedUploadBlockSize, stagedUploadRetryDelay)
+	}
+
 	log.Printf("Uploading the file with blob name: %s\n", name)
-	_, err = azblob.UploadStreamToBlockBlob(context.Background(), reader, blobUrl, azblob.UploadStreamToBlockBlobOptions{})
+	_, err = azblob.UploadStreamToBlockBlob(context.Background(), reader, blobUrl, exporter.getUploadOptions())
+
+	return err
+}
+
+// getUploadOptions returns the options for streamed uploads, which are read into buffers of the configured size that
+// are uploaded as blocks in parallel.
+func (exporter *AzureBlobExporter) getUploadOptions() azblob.UploadStreamToBlockBlobOptions {
+	return azblob.UploadStreamToBlockBlobOptions{
+		BufferSize: exporter.runtimeInfo.AzureBlobBufferSize,
+		MaxBuffers: exporter.runtimeInfo.AzureBlobMaxBuffers,
+	}
+}
+
+// skipExisting returns whether the upload of the named blob should be skipped because it already exists, such as when
+// adding to the output of an earlier run with EXPORT_EXISTING set to skip. Otherwise, existing blobs are overwritten.
+func (exporter *AzureBlobExporter) skipExisting(blobURL azblob.BlobURL, name string) (bool, error) {
+	if exporter.runtimeInfo.ExportExisting != utils.ExportExistingSkip {
+		return false, nil
+	}
+
+	exists, err := blobExists(context.Background(), blobURL)
+	if err != nil {
+		return false, fmt.Errorf("check whether blob %s exists: %w", name, err)
+	}
+
+	if exists {
+		log.Printf("Skipping blob name: %s, which already exists\n", name)
+	}
+
+	return exists, nil
+}
+
+func blobExists(ctx context.Context, blobURL azblob.BlobURL) (bool, error) {
+	_, err := blobURL.GetProperties(ctx, azblob.BlobAccessConditions{}, azblob.ClientProvidedKeyOptions{})
+	if err == nil {
+		return true, nil
+	}
+
+	storageError, ok := err.(azblob.StorageError)
+	if ok && storageError.ServiceCode() == azblob.ServiceCodeBlobNotFound {
+		return false, nil
+	}
+
+	return false, err
+}
+
+// uploadInStagedBlocks stages the content of the reader in blocks and commits them as the content of the blob. The ID
+// of each block is derived from its index and MD5 hash, so blocks which were already staged with the same content,
+// by an earlier attempt or an earlier run, are not uploaded again. The service verifies the MD5 hash of each block
+// that is staged.
+func uploadInStagedBlocks(ctx context.Context, stager blockStager, reader io.ReadSeeker, blockSize int, retryDelay time.Duration) error {
+	start, err := reader.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("get position of content to upload: %w", err)
+	}
+
+	for attempt := 1; attempt <= stagedUploadMaxAttempts; attempt++ {
+		if attempt > 1 {
+			log.Printf("Staged upload failed (attempt %d of %d), resuming: %v", attempt-1, stagedUploadMaxAttempts, err)
+			time.Sleep(retryDelay * time.Duration(attempt-1))
+		}
+
+		if _, err = reader.Seek(start, io.SeekStart); err != nil {
+			return fmt.Errorf("rewind content to upload: %w", err)
+		}
+
+		var blockIDs []string
+		blockIDs, err = stageBlocks(ctx, stager, reader, blockSize)
+		if err != nil {
+			continue
+		}
+
+		_, err = stager.CommitBlockList(ctx, blockIDs, azblob.BlobHTTPHeaders{}, azblob.Metadata{}, azblob.BlobAccessConditions{}, azblob.DefaultAccessTier, azblob.BlobTagsMap{}, azblob.ClientProvidedKeyOptions{}, azblob.ImmutabilityPolicyOptions{})
+		if err == nil {
+			return nil
+		}
+		err = fmt.Errorf("commit block list: %w", err)
+	}
+
+	return err
+}
+
+// getRemainingSize returns the number of bytes from the current position of the reader to its end, leaving the
+// position unchanged.
+func getRemainingSize(reader io.ReadSeeker) (int64, error) {
+	start, err := reader.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+
+	end, err := reader.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := reader.Seek(start, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	return end - start, nil
+}
+
+// stageBlocks stages each block of the content which isn't already staged or committed, returning the IDs of all the
+// blocks in order.
+func stageBlocks(ctx context.Context, stager blockStager, reader io.Reader, blockSize int) ([]string, error) {
+	existingBlocks := map[string]int64{}
+	blockList, err := stager.GetBlockList(ctx, azblob.BlockListAll, azblob.LeaseAccessConditions{})
+	if err != nil {
+		// The blob doesn't exist until its first block list is committed, and before that this may fail with
+		// BlobNotFound. Either way, nothing can be resumed and every block is staged.
+		log.Printf("Unable to list existing blocks, staging all blocks: %v", err)
+	} else {
+		for _, block := range append(blockList.CommittedBlocks, blockList.UncommittedBlocks...) {
+			existingBlocks[block.Name] = block.Size
+		}
+	}
+
+	blockIDs := []string{}
+	reusedCount := 0
+	buffer := make([]byte, blockSize)
+	for {
+		n, readErr := io.ReadFull(reader, buffer)
+		if n > 0 {
+			block := buffer[:n]
+			hash := md5.Sum(block)
+			blockID := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%06d-%x", len(blockIDs), hash)))
+
+			if size, ok := existingBlocks[blockID]; ok && size == int64(n) {
+				reusedCount++
+			} else if _, err := stager.StageBlock(ctx, blockID, bytes.NewReader(block), azblob.LeaseAccessConditions{}, hash[:], azblob.ClientProvidedKeyOptions{}); err != nil {
+				return nil, fmt.Errorf("stage block %d: %w", len(blockIDs), err)
+			}
+
+			blockIDs = append(blockIDs, blockID)
+		}
+
+		if errors.Is(readErr, io.EOF) || errors.Is(readErr, io.ErrUnexpectedEOF) {
+			break
+		}
+		if readErr != nil {
+			return nil, fmt.Errorf("read content to upload: %w", readErr)
+		}
+	}
+
+	if reusedCount > 0 {
+		log.Printf("Resumed staged upload, reusing %d of %d blocks", reusedCount, len(blockIDs))
+	}
+
+	return blockIDs, nil
+}
+
+// ExportStream implements the interface method. The content is uploaded in blocks as it is read, so only the blocks
+// in flight are held in memory.
+func (exporter *AzureBlobExporter) ExportStream(name string, reader io.Reader) error {
+	containerURL, err := exporter.getContainerURL()
+	if err != nil {
+		return err
+	}
+
+	blobUrl := containerURL.NewBlockBlobURL(exporter.getBlobName(name))
+	skip, err := exporter.skipExisting(blobUrl.BlobURL, name)
+	if err != nil || skip {
+		return err
+	}
+
+	log.Printf("Uploading the stream with blob name: %s\n", name)
+	_, err = azblob.UploadStreamToBlockBlob(context.Background(), reader, blobUrl, exporter.getUploadOptions())
 
 	return err
 }
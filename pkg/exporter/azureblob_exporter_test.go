@@ -0,0 +1,271 @@
+package exporter
+
+import (
+	"context"
+	"crypto/md5"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+	"github.com/Azure/azure-storage-blob-go/azblob"
+)
+
+func TestAzureBlobExporterGetBlobName(t *testing.T) {
+	tests := []struct {
+		name     string
+		runId    string
+		key      string
+		expected string
+	}{
+		{
+			name:     "with run identifier",
+			runId:    "2023-06-15T12-00-00Z",
+			key:      "dns",
+			expected: "2023-06-15T12-00-00Z/node-1/dns",
+		},
+		{
+			name:     "with run identifier and nested key",
+			runId:    "ticket-1234",
+			key:      "containerlogs/kube-system_coredns",
+			expected: "ticket-1234/node-1/containerlogs/kube-system_coredns",
+		},
+		{
+			name:     "without run identifier",
+			runId:    "",
+			key:      "node-1.zip",
+			expected: "node-1/node-1.zip",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runtimeInfo := &utils.RuntimeInfo{HostNodeName: "node-1"}
+			exporter := NewAzureBlobExporter(runtimeInfo, nil, tt.runId)
+			actual := exporter.getBlobName(tt.key)
+			if actual != tt.expected {
+				t.Errorf("unexpected blob name: expected %s, found %s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestAzureBlobExporterGetContainerURL(t *testing.T) {
+	exporter := NewAzureBlobExporter(&utils.RuntimeInfo{}, nil, "run1")
+
+	attempts := 0
+	failures := 1
+	exporter.createContainerURL = func(*utils.RuntimeInfo, *utils.KnownFilePaths) (azblob.ContainerURL, error) {
+		attempts++
+		if attempts <= failures {
+			return azblob.ContainerURL{}, errors.New("create container: service unavailable")
+		}
+
+		containerURL, _ := url.Parse("https://account.blob.core.windows.net/container")
+		return azblob.NewContainerURL(*containerURL, azblob.NewPipeline(azblob.NewAnonymousCredential(), azblob.PipelineOptions{})), nil
+	}
+
+	// A failure isn't cached, so the next export tries again.
+	if _, err := exporter.getContainerURL(); err == nil {
+		t.Fatalf("expected error creating container URL")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			containerURL, err := exporter.getContainerURL()
+			if err != nil {
+				t.Errorf("getContainerURL() error = %v", err)
+				return
+			}
+			if containerURL.String() != "https://account.blob.core.windows.net/container" {
+				t.Errorf("unexpected container URL %s", containerURL.String())
+			}
+		}()
+	}
+	wg.Wait()
+
+	if attempts != failures+1 {
+		t.Errorf("expected container creation to be attempted %d times, found %d", failures+1, attempts)
+	}
+}
+
+func TestValidateSasExpiry(t *testing.T) {
+	now := time.Date(2023, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		sasKey  string
+		wantErr bool
+	}{
+		{
+			name:    "expired token",
+			sasKey:  "?sv=2021-06-08&ss=b&srt=sco&sp=rlacw&se=2023-06-15T11:00:00Z&sig=abc",
+			wantErr: true,
+		},
+		{
+			name:    "expired token with minute precision",
+			sasKey:  "?sv=2021-06-08&se=2023-06-15T11:59Z&sig=abc",
+			wantErr: true,
+		},
+		{
+			name:    "expired token with date only",
+			sasKey:  "?sv=2021-06-08&se=2023-06-14&sig=abc",
+			wantErr: true,
+		},
+		{
+			name:    "token expiring soon",
+			sasKey:  "?sv=2021-06-08&se=2023-06-15T12:02:00Z&sig=abc",
+			wantErr: false,
+		},
+		{
+			name:    "valid token",
+			sasKey:  "?sv=2021-06-08&se=2023-06-16T12:00:00Z&sig=abc",
+			wantErr: false,
+		},
+		{
+			name:    "token without leading question mark",
+			sasKey:  "sv=2021-06-08&se=2023-06-16T12:00:00Z&sig=abc",
+			wantErr: false,
+		},
+		{
+			name:    "token without expiry",
+			sasKey:  "?sv=2021-06-08&sig=abc",
+			wantErr: false,
+		},
+		{
+			name:    "token with unparseable expiry",
+			sasKey:  "?sv=2021-06-08&se=tomorrow&sig=abc",
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSasExpiry(tt.sasKey, now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateSasExpiry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// fakeBlockStager holds staged blocks in memory, and fails the StageBlock calls numbered in failStages.
+type fakeBlockStager struct {
+	t          *testing.T
+	blocks     map[string][]byte
+	committed  []string
+	stageCalls int
+	failStages map[int]bool
+}
+
+func (stager *fakeBlockStager) GetBlockList(ctx context.Context, listType azblob.BlockListType, ac azblob.LeaseAccessConditions) (*azblob.BlockList, error) {
+	blockList := &azblob.BlockList{}
+	for name, content := range stager.blocks {
+		blockList.UncommittedBlocks = append(blockList.UncommittedBlocks, azblob.Block{Name: name, Size: int64(len(content))})
+	}
+	return blockList, nil
+}
+
+func (stager *fakeBlockStager) StageBlock(ctx context.Context, base64BlockID string, body io.ReadSeeker, ac azblob.LeaseAccessConditions, transactionalMD5 []byte, cpk azblob.ClientProvidedKeyOptions) (*azblob.BlockBlobStageBlockResponse, error) {
+	stager.stageCalls++
+	if stager.failStages[stager.stageCalls] {
+		return nil, errors.New("connection reset")
+	}
+
+	content, err := io.ReadAll(body)
+	if err != nil {
+		stager.t.Fatalf("error reading block: %v", err)
+	}
+	if hash := md5.Sum(content); string(hash[:]) != string(transactionalMD5) {
+		stager.t.Errorf("MD5 mismatch for block %s", base64BlockID)
+	}
+
+	stager.blocks[base64BlockID] = content
+	return &azblob.BlockBlobStageBlockResponse{}, nil
+}
+
+func (stager *fakeBlockStager) CommitBlockList(ctx context.Context, base64BlockIDs []string, h azblob.BlobHTTPHeaders, metadata azblob.Metadata, ac azblob.BlobAccessConditions, tier azblob.AccessTierType, blobTagsMap azblob.BlobTagsMap, cpk azblob.ClientProvidedKeyOptions, immutability azblob.ImmutabilityPolicyOptions) (*azblob.BlockBlobCommitBlockListResponse, error) {
+	stager.committed = base64BlockIDs
+	return &azblob.BlockBlobCommitBlockListResponse{}, nil
+}
+
+func (stager *fakeBlockStager) getCommittedContent() string {
+	content := ""
+	for _, id := range stager.committed {
+		content += string(stager.blocks[id])
+	}
+	return content
+}
+
+func TestUploadInStagedBlocks(t *testing.T) {
+	tests := []struct {
+		name           string
+		content        string
+		previousUpload string
+		failStages     map[int]bool
+		wantStageCalls int
+		wantErr        bool
+	}{
+		{
+			name:           "new upload",
+			content:        "abcdefghij",
+			wantStageCalls: 3,
+		},
+		{
+			name:           "resumed from earlier run",
+			content:        "abcdefghij",
+			previousUpload: "abcdefgh",
+			wantStageCalls: 1,
+		},
+		{
+			name:           "earlier run with different content",
+			content:        "abcdefghij",
+			previousUpload: "abcdxxxx",
+			wantStageCalls: 2,
+		},
+		{
+			name:           "resumed after failed block",
+			content:        "abcdefghij",
+			failStages:     map[int]bool{2: true},
+			wantStageCalls: 4,
+		},
+		{
+			name:           "persistent failure",
+			content:        "abcdefghij",
+			failStages:     map[int]bool{1: true, 2: true, 3: true},
+			wantStageCalls: 3,
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stager := &fakeBlockStager{t: t, blocks: map[string][]byte{}, failStages: map[int]bool{}}
+			if tt.previousUpload != "" {
+				if err := uploadInStagedBlocks(context.Background(), stager, strings.NewReader(tt.previousUpload), 4, 0); err != nil {
+					t.Fatalf("previous upload error = %v", err)
+				}
+				stager.stageCalls = 0
+			}
+			stager.failStages = tt.failStages
+
+			err := uploadInStagedBlocks(context.Background(), stager, strings.NewReader(tt.content), 4, 0)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("uploadInStagedBlocks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if stager.stageCalls != tt.wantStageCalls {
+				t.Errorf("expected %d StageBlock calls, found %d", tt.wantStageCalls, stager.stageCalls)
+			}
+			if !tt.wantErr && stager.getCommittedContent() != tt.content {
+				t.Errorf("expected committed content %q, found %q", tt.content, stager.getCommittedContent())
+			}
+		})
+	}
+}
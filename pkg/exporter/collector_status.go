@@ -0,0 +1,85 @@
+package exporter
+
+import (
+	"sort"
+	"sync"
+)
+
+// CollectorState describes how far a collector (or diagnoser) got in a run.
+type CollectorState string
+
+const (
+	// CollectorSupported means the collector passed CheckSupported but has not (yet) reported a result.
+	CollectorSupported CollectorState = "supported"
+	CollectorSkipped   CollectorState = "skipped"
+	CollectorCollected CollectorState = "collected"
+	CollectorFailed    CollectorState = "failed"
+)
+
+// CollectorStatus records the outcome of a single collector. The reason is the CheckSupported error for skipped
+// collectors, and the collection error for failed ones.
+type CollectorStatus struct {
+	Name   string         `json:"name"`
+	State  CollectorState `json:"state"`
+	Reason string         `json:"reason,omitempty"`
+}
+
+// CollectorStatusRecorder records the status of each collector as a run progresses. It is safe for concurrent use.
+type CollectorStatusRecorder struct {
+	lock     sync.Mutex
+	statuses map[string]CollectorStatus
+}
+
+func NewCollectorStatusRecorder() *CollectorStatusRecorder {
+	return &CollectorStatusRecorder{
+		statuses: map[string]CollectorStatus{},
+	}
+}
+
+// RecordSupported records that the collector passed CheckSupported and is about to run.
+func (recorder *CollectorStatusRecorder) RecordSupported(name string) {
+	recorder.record(name, CollectorSupported, nil)
+}
+
+// RecordSkipped records that the collector was not run, with the error from CheckSupported as the reason.
+func (recorder *CollectorStatusRecorder) RecordSkipped(name string, reason error) {
+	recorder.record(name, CollectorSkipped, reason)
+}
+
+// RecordCollected records the result of running the collector: collected if err is nil, and otherwise failed.
+func (recorder *CollectorStatusRecorder) RecordCollected(name string, err error) {
+	if err != nil {
+		recorder.record(name, CollectorFailed, err)
+		return
+	}
+
+	recorder.record(name, CollectorCollected, nil)
+}
+
+func (recorder *CollectorStatusRecorder) record(name string, state CollectorState, reason error) {
+	status := CollectorStatus{Name: name, State: state}
+	if reason != nil {
+		status.Reason = reason.Error()
+	}
+
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+	recorder.statuses[name] = status
+}
+
+// GetStatuses returns the status of every recorded collector, ordered by name.
+func (recorder *CollectorStatusRecorder) GetStatuses() []CollectorStatus {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	statuses := make([]CollectorStatus, 0, len(recorder.statuses))
+	for _, status := range recorder.statuses {
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	return statuses
+}
@@ -0,0 +1,30 @@
+package exporter
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCollectorStatusRecorder(t *testing.T) {
+	recorder := NewCollectorStatusRecorder()
+
+	recorder.RecordSkipped("windowslogs", errors.New("unsupported OS: linux"))
+	recorder.RecordSupported("dns")
+	recorder.RecordSupported("iptables")
+	recorder.RecordSupported("helm")
+	recorder.RecordCollected("dns", nil)
+	recorder.RecordCollected("iptables", errors.New("exit status 1"))
+
+	expected := []CollectorStatus{
+		{Name: "dns", State: CollectorCollected},
+		{Name: "helm", State: CollectorSupported},
+		{Name: "iptables", State: CollectorFailed, Reason: "exit status 1"},
+		{Name: "windowslogs", State: CollectorSkipped, Reason: "unsupported OS: linux"},
+	}
+
+	actual := recorder.GetStatuses()
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected statuses:\nExpected %+v\nFound    %+v", expected, actual)
+	}
+}
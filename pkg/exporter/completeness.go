@@ -0,0 +1,76 @@
+package exporter
+
+import (
+	"sort"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+)
+
+// CompletenessReport records which of the expected data producers contributed output to an exported bundle.
+type CompletenessReport struct {
+	Complete       bool                `json:"complete"`
+	Expected       []string            `json:"expected"`
+	MissingOutput  []string            `json:"missingOutput"`
+	EmptyKeys      map[string][]string `json:"emptyKeys"`
+	UnexpectedData []string            `json:"unexpectedData"`
+}
+
+// ValidateCompleteness checks the data of the exported producers against the names of the producers which were
+// expected to run. An expected producer is reported as missing output if it was not exported, or if all of its values
+// are empty. Individual empty values are listed separately, since these can be legitimate (e.g. an empty log file).
+func ValidateCompleteness(expected []string, exported []interfaces.DataProducer) *CompletenessReport {
+	report := &CompletenessReport{
+		Expected:       append([]string{}, expected...),
+		MissingOutput:  []string{},
+		EmptyKeys:      map[string][]string{},
+		UnexpectedData: []string{},
+	}
+	sort.Strings(report.Expected)
+
+	expectedNames := map[string]bool{}
+	for _, name := range expected {
+		expectedNames[name] = true
+	}
+
+	// A streaming collector has two producers with its name, one recording the data it streamed and one for any data
+	// it returned from GetData, so the data of producers with the same name is merged.
+	producedKeys := map[string]map[string]bool{}
+	for _, producer := range exported {
+		name := producer.GetName()
+		if !expectedNames[name] {
+			report.UnexpectedData = append(report.UnexpectedData, name)
+			continue
+		}
+
+		if _, ok := producedKeys[name]; !ok {
+			producedKeys[name] = map[string]bool{}
+		}
+		for key, value := range producer.GetData() {
+			producedKeys[name][key] = value.GetLength() > 0
+		}
+	}
+
+	for _, name := range report.Expected {
+		hasOutput := false
+		emptyKeys := []string{}
+		for key, nonEmpty := range producedKeys[name] {
+			if nonEmpty {
+				hasOutput = true
+			} else {
+				emptyKeys = append(emptyKeys, key)
+			}
+		}
+
+		if !hasOutput {
+			report.MissingOutput = append(report.MissingOutput, name)
+		} else if len(emptyKeys) > 0 {
+			sort.Strings(emptyKeys)
+			report.EmptyKeys[name] = emptyKeys
+		}
+	}
+
+	sort.Strings(report.UnexpectedData)
+	report.Complete = len(report.MissingOutput) == 0
+
+	return report
+}
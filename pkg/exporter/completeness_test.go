@@ -0,0 +1,95 @@
+package exporter
+
+import (
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+func TestValidateCompleteness(t *testing.T) {
+	withOutput := &testDataProducer{
+		name: "withoutput",
+		data: map[string]interfaces.DataValue{
+			"key1": utils.NewStringDataValue("value1"),
+			"key2": utils.NewStringDataValue(""),
+		},
+	}
+	noKeys := &testDataProducer{
+		name: "nokeys",
+		data: map[string]interfaces.DataValue{},
+	}
+	emptyValues := &testDataProducer{
+		name: "emptyvalues",
+		data: map[string]interfaces.DataValue{"key1": utils.NewStringDataValue("")},
+	}
+	unexpected := &testDataProducer{
+		name: "unexpected",
+		data: map[string]interfaces.DataValue{"key1": utils.NewStringDataValue("value1")},
+	}
+
+	tests := []struct {
+		name           string
+		expected       []string
+		exported       []interfaces.DataProducer
+		wantComplete   bool
+		wantMissing    []string
+		wantEmptyKeys  map[string][]string
+		wantUnexpected []string
+	}{
+		{
+			name:           "all collectors produced output",
+			expected:       []string{"withoutput"},
+			exported:       []interfaces.DataProducer{withOutput},
+			wantComplete:   true,
+			wantMissing:    []string{},
+			wantEmptyKeys:  map[string][]string{"withoutput": {"key2"}},
+			wantUnexpected: []string{},
+		},
+		{
+			name:           "collectors producing nothing",
+			expected:       []string{"withoutput", "nokeys", "emptyvalues", "notexported"},
+			exported:       []interfaces.DataProducer{withOutput, noKeys, emptyValues, unexpected},
+			wantComplete:   false,
+			wantMissing:    []string{"emptyvalues", "nokeys", "notexported"},
+			wantEmptyKeys:  map[string][]string{"withoutput": {"key2"}},
+			wantUnexpected: []string{"unexpected"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := ValidateCompleteness(tt.expected, tt.exported)
+
+			if report.Complete != tt.wantComplete {
+				t.Errorf("expected complete %v, found %v", tt.wantComplete, report.Complete)
+			}
+			if !equalStrings(report.MissingOutput, tt.wantMissing) {
+				t.Errorf("expected missing output %v, found %v", tt.wantMissing, report.MissingOutput)
+			}
+			if !equalStrings(report.UnexpectedData, tt.wantUnexpected) {
+				t.Errorf("expected unexpected data %v, found %v", tt.wantUnexpected, report.UnexpectedData)
+			}
+			if len(report.EmptyKeys) != len(tt.wantEmptyKeys) {
+				t.Errorf("expected empty keys %v, found %v", tt.wantEmptyKeys, report.EmptyKeys)
+			}
+			for name, keys := range tt.wantEmptyKeys {
+				if !equalStrings(report.EmptyKeys[name], keys) {
+					t.Errorf("expected empty keys %v for %s, found %v", keys, name, report.EmptyKeys[name])
+				}
+			}
+		})
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,376 @@
+package exporter
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+)
+
+const (
+	encryptionKeyFileName = "encryption-key.json"
+	encryptedFileSuffix   = ".enc"
+
+	encryptionAlgorithm       = "AES-256-GCM"
+	keyWrapAlgorithmRSA       = "RSA-OAEP-256"
+	keyWrapAlgorithmSymmetric = "A256GCM"
+
+	// Content is encrypted in chunks, so that it can be encrypted and decrypted as a stream. Each chunk has its own
+	// authentication tag.
+	encryptionChunkSize       = 64 * 1024
+	encryptionNoncePrefixSize = 8
+	encryptionKeySize         = 32
+)
+
+// encryptionMagic starts every encrypted file, identifying the format and its version.
+var encryptionMagic = []byte("PSE1")
+
+// encryptionHeaderSize is the size of the header of an encrypted file: the magic, the chunk size and the nonce prefix.
+var encryptionHeaderSize = len(encryptionMagic) + 4 + encryptionNoncePrefixSize
+
+// EncryptionKeyInfo is exported alongside the encrypted data, holding the data key wrapped with the configured key.
+type EncryptionKeyInfo struct {
+	Algorithm        string `json:"algorithm"`
+	ChunkSize        int    `json:"chunkSize"`
+	KeyWrapAlgorithm string `json:"keyWrapAlgorithm"`
+	// KeyFingerprint is the SHA-256 hash of the wrapping key (for an RSA public key, its PKCS #1 DER encoding),
+	// identifying which key is needed to unwrap the data key.
+	KeyFingerprint string `json:"keyFingerprint"`
+	WrappedKey     string `json:"wrappedKey"`
+}
+
+// EncryptingExporter wraps an Exporter, encrypting everything exported through it with a data key generated for the
+// run. The data key is wrapped with the configured RSA public key or symmetric key, and exported unencrypted as
+// encryption-key.json by ExportWrappedKey.
+//
+// Each encrypted file is named with a .enc suffix and consists of a header followed by the encrypted chunks:
+//   - the magic "PSE1", the big-endian uint32 chunk size, and an 8 byte random nonce prefix
+//   - each chunk of at most chunk size bytes of content sealed with AES-256-GCM, using the nonce prefix followed by
+//     the big-endian uint32 index of the chunk as nonce, and a single byte of additional data which is 1 for the last
+//     chunk and 0 otherwise, so that truncation is detected. Empty content is a single empty last chunk.
+type EncryptingExporter struct {
+	exporter interfaces.Exporter
+	dataKey  []byte
+	keyInfo  EncryptionKeyInfo
+	keyErr   error
+}
+
+// NewEncryptingExporter creates an exporter encrypting data with a data key wrapped by the key in keyFile: either a
+// PEM RSA public key, or a base64 encoded 256 bit symmetric key. Any problem with the key is reported by each export,
+// so that nothing is exported unencrypted.
+func NewEncryptingExporter(exporter interfaces.Exporter, keyFile string) *EncryptingExporter {
+	encryptingExporter := &EncryptingExporter{exporter: exporter}
+	encryptingExporter.dataKey, encryptingExporter.keyInfo, encryptingExporter.keyErr = createDataKey(keyFile)
+
+	return encryptingExporter
+}
+
+// Export implements the interface method
+func (exporter *EncryptingExporter) Export(producer interfaces.DataProducer) error {
+	if exporter.keyErr != nil {
+		return exporter.keyErr
+	}
+
+	return exporter.exporter.Export(&encryptingDataProducer{producer: producer, dataKey: exporter.dataKey})
+}
+
+// ExportReader implements the interface method. The content is encrypted to a temporary file, so that the wrapped
+// exporter can rewind it without it being held in memory.
+func (exporter *EncryptingExporter) ExportReader(name string, reader io.ReadSeeker) error {
+	if exporter.keyErr != nil {
+		return exporter.keyErr
+	}
+
+	encryptingReader, err := newEncryptingReader(reader, exporter.dataKey)
+	if err != nil {
+		return fmt.Errorf("encrypt %s: %w", name, err)
+	}
+
+	return spoolStream(name+encryptedFileSuffix, encryptingReader, exporter.exporter.ExportReader)
+}
+
+// ExportStream implements the interface method. The content is encrypted as it is read.
+func (exporter *EncryptingExporter) ExportStream(name string, reader io.Reader) error {
+	if exporter.keyErr != nil {
+		return exporter.keyErr
+	}
+
+	encryptingReader, err := newEncryptingReader(reader, exporter.dataKey)
+	if err != nil {
+		return fmt.Errorf("encrypt %s: %w", name, err)
+	}
+
+	return exportStream(exporter.exporter, name+encryptedFileSuffix, encryptingReader)
+}
+
+// ExportWrappedKey exports the wrapped data key, which is needed to decrypt everything exported in this run.
+func (exporter *EncryptingExporter) ExportWrappedKey() error {
+	if exporter.keyErr != nil {
+		return exporter.keyErr
+	}
+
+	data, err := json.Marshal(exporter.keyInfo)
+	if err != nil {
+		return fmt.Errorf("marshall encryption key to json: %w", err)
+	}
+
+	return exporter.exporter.ExportReader(encryptionKeyFileName, bytes.NewReader(data))
+}
+
+// createDataKey generates a random data key, and wraps it with the key in the key file.
+func createDataKey(keyFile string) ([]byte, EncryptionKeyInfo, error) {
+	content, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, EncryptionKeyInfo{}, fmt.Errorf("read encryption key: %w", err)
+	}
+
+	dataKey := make([]byte, encryptionKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, EncryptionKeyInfo{}, fmt.Errorf("generate data key: %w", err)
+	}
+
+	keyInfo := EncryptionKeyInfo{Algorithm: encryptionAlgorithm, ChunkSize: encryptionChunkSize}
+
+	var wrappedKey []byte
+	if block, _ := pem.Decode(content); block != nil {
+		publicKey, err := parseRSAPublicKey(block)
+		if err != nil {
+			return nil, EncryptionKeyInfo{}, fmt.Errorf("parse encryption key %s: %w", keyFile, err)
+		}
+
+		wrappedKey, err = rsa.EncryptOAEP(sha256.New(), rand.Reader, publicKey, dataKey, nil)
+		if err != nil {
+			return nil, EncryptionKeyInfo{}, fmt.Errorf("wrap data key: %w", err)
+		}
+
+		keyInfo.KeyWrapAlgorithm = keyWrapAlgorithmRSA
+		keyInfo.KeyFingerprint = getKeyFingerprint(x509.MarshalPKCS1PublicKey(publicKey))
+	} else {
+		wrappingKey, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(content)))
+		if err != nil || len(wrappingKey) != encryptionKeySize {
+			return nil, EncryptionKeyInfo{}, fmt.Errorf("encryption key %s is neither a PEM RSA public key nor a base64 encoded %d byte key", keyFile, encryptionKeySize)
+		}
+
+		wrappedKey, err = wrapDataKey(wrappingKey, dataKey)
+		if err != nil {
+			return nil, EncryptionKeyInfo{}, fmt.Errorf("wrap data key: %w", err)
+		}
+
+		keyInfo.KeyWrapAlgorithm = keyWrapAlgorithmSymmetric
+		keyInfo.KeyFingerprint = getKeyFingerprint(wrappingKey)
+	}
+
+	keyInfo.WrappedKey = base64.StdEncoding.EncodeToString(wrappedKey)
+
+	return dataKey, keyInfo, nil
+}
+
+func parseRSAPublicKey(block *pem.Block) (*rsa.PublicKey, error) {
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		publicKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("unsupported public key type %T: expected RSA", key)
+		}
+		return publicKey, nil
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %s: expected a public key", block.Type)
+	}
+}
+
+// wrapDataKey encrypts the data key with AES-256-GCM, returning the random nonce followed by the sealed key.
+func wrapDataKey(wrappingKey, dataKey []byte) ([]byte, error) {
+	aead, err := newAESGCM(wrappingKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, dataKey, nil), nil
+}
+
+func getKeyFingerprint(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:])
+}
+
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// getEncryptedLength returns the length of the encrypted form of content of the given length.
+func getEncryptedLength(length int64) int64 {
+	chunks := (length + encryptionChunkSize - 1) / encryptionChunkSize
+	if chunks == 0 {
+		chunks = 1
+	}
+
+	// The tag size of AES-GCM is 16 bytes.
+	return int64(encryptionHeaderSize) + length + chunks*16
+}
+
+// encryptingReader encrypts the content of a reader in the format described on EncryptingExporter as it is read.
+type encryptingReader struct {
+	source  *bufio.Reader
+	aead    cipher.AEAD
+	nonce   []byte
+	index   uint32
+	chunk   []byte
+	sealed  []byte
+	pending []byte
+	done    bool
+}
+
+func newEncryptingReader(source io.Reader, dataKey []byte) (*encryptingReader, error) {
+	aead, err := newAESGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce[:encryptionNoncePrefixSize]); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+
+	header := make([]byte, 0, encryptionHeaderSize)
+	header = append(header, encryptionMagic...)
+	header = binary.BigEndian.AppendUint32(header, encryptionChunkSize)
+	header = append(header, nonce[:encryptionNoncePrefixSize]...)
+
+	return &encryptingReader{
+		source:  bufio.NewReader(source),
+		aead:    aead,
+		nonce:   nonce,
+		chunk:   make([]byte, encryptionChunkSize),
+		sealed:  make([]byte, 0, encryptionChunkSize+aead.Overhead()),
+		pending: header,
+	}, nil
+}
+
+func (r *encryptingReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.sealNextChunk(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+func (r *encryptingReader) sealNextChunk() error {
+	n, err := io.ReadFull(r.source, r.chunk)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+
+	// A full chunk is only the last if nothing follows it.
+	last := n < len(r.chunk)
+	if !last {
+		if _, err := r.source.Peek(1); errors.Is(err, io.EOF) {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if !last && r.index == math.MaxUint32 {
+		return errors.New("content too large to encrypt")
+	}
+
+	additionalData := []byte{0}
+	if last {
+		additionalData[0] = 1
+	}
+
+	binary.BigEndian.PutUint32(r.nonce[encryptionNoncePrefixSize:], r.index)
+	r.sealed = r.aead.Seal(r.sealed[:0], r.nonce, r.chunk[:n], additionalData)
+	r.pending = r.sealed
+	r.index++
+	r.done = last
+
+	return nil
+}
+
+// encryptingDataProducer wraps each value of a DataProducer so that it is encrypted when read.
+type encryptingDataProducer struct {
+	producer interfaces.DataProducer
+	dataKey  []byte
+}
+
+func (p *encryptingDataProducer) GetName() string {
+	return p.producer.GetName()
+}
+
+func (p *encryptingDataProducer) GetData() map[string]interfaces.DataValue {
+	data := p.producer.GetData()
+	result := make(map[string]interfaces.DataValue, len(data))
+	for key, value := range data {
+		result[key+encryptedFileSuffix] = &encryptingDataValue{value: value, dataKey: p.dataKey}
+	}
+
+	return result
+}
+
+type encryptingDataValue struct {
+	value   interfaces.DataValue
+	dataKey []byte
+}
+
+func (v *encryptingDataValue) GetLength() int64 {
+	return getEncryptedLength(v.value.GetLength())
+}
+
+func (v *encryptingDataValue) GetReader() (io.ReadCloser, error) {
+	reader, err := v.value.GetReader()
+	if err != nil {
+		return nil, err
+	}
+
+	encryptingReader, err := newEncryptingReader(reader, v.dataKey)
+	if err != nil {
+		reader.Close()
+		return nil, err
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{encryptingReader, reader}, nil
+}
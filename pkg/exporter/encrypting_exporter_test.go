@@ -0,0 +1,268 @@
+package exporter
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+// decryptContent decrypts content in the format written by EncryptingExporter.
+func decryptContent(dataKey, content []byte) ([]byte, error) {
+	if len(content) < encryptionHeaderSize || !bytes.Equal(content[:len(encryptionMagic)], encryptionMagic) {
+		return nil, errors.New("missing header")
+	}
+
+	aead, err := newAESGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	chunkSize := int(binary.BigEndian.Uint32(content[len(encryptionMagic):]))
+	nonce := make([]byte, aead.NonceSize())
+	copy(nonce, content[len(encryptionMagic)+4:encryptionHeaderSize])
+	content = content[encryptionHeaderSize:]
+
+	result := []byte{}
+	for index := uint32(0); ; index++ {
+		size := chunkSize + aead.Overhead()
+		last := len(content) <= size
+		if last {
+			size = len(content)
+		}
+
+		additionalData := []byte{0}
+		if last {
+			additionalData[0] = 1
+		}
+
+		binary.BigEndian.PutUint32(nonce[encryptionNoncePrefixSize:], index)
+		chunk, err := aead.Open(nil, nonce, content[:size], additionalData)
+		if err != nil {
+			return nil, fmt.Errorf("chunk %d: %w", index, err)
+		}
+
+		result = append(result, chunk...)
+		content = content[size:]
+		if last {
+			return result, nil
+		}
+	}
+}
+
+func newTestDataKey(t *testing.T) []byte {
+	key := make([]byte, encryptionKeySize)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return key
+}
+
+func TestEncryptingReader(t *testing.T) {
+	dataKey := newTestDataKey(t)
+
+	for _, length := range []int{0, 1, encryptionChunkSize - 1, encryptionChunkSize, encryptionChunkSize + 1, 3 * encryptionChunkSize} {
+		t.Run(fmt.Sprintf("%d bytes", length), func(t *testing.T) {
+			content := make([]byte, length)
+			if _, err := rand.Read(content); err != nil {
+				t.Fatalf("generate content: %v", err)
+			}
+
+			reader, err := newEncryptingReader(bytes.NewReader(content), dataKey)
+			if err != nil {
+				t.Fatalf("newEncryptingReader() error = %v", err)
+			}
+			encrypted, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("read encrypted content: %v", err)
+			}
+
+			if int64(len(encrypted)) != getEncryptedLength(int64(length)) {
+				t.Errorf("expected encrypted length %d, found %d", getEncryptedLength(int64(length)), len(encrypted))
+			}
+
+			decrypted, err := decryptContent(dataKey, encrypted)
+			if err != nil {
+				t.Fatalf("decrypt: %v", err)
+			}
+			if !bytes.Equal(decrypted, content) {
+				t.Errorf("decrypted content doesn't match")
+			}
+
+			// Dropping the last chunk must be detected.
+			if length > encryptionChunkSize {
+				truncated := encrypted[:encryptionHeaderSize+encryptionChunkSize+16]
+				if _, err := decryptContent(dataKey, truncated); err == nil {
+					t.Errorf("expected truncated content to fail decryption")
+				}
+			}
+		})
+	}
+}
+
+func TestEncryptingExporter(t *testing.T) {
+	dir := t.TempDir()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate RSA key: %v", err)
+	}
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal public key: %v", err)
+	}
+	symmetricKey := newTestDataKey(t)
+
+	tests := []struct {
+		name      string
+		keyFile   string
+		unwrap    func(wrappedKey []byte) ([]byte, error)
+		wantAlg   string
+		wantError bool
+	}{
+		{
+			name:    "RSA public key",
+			keyFile: writeTestFile(t, dir, "public.pem", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDER})),
+			unwrap: func(wrappedKey []byte) ([]byte, error) {
+				return rsa.DecryptOAEP(sha256.New(), rand.Reader, privateKey, wrappedKey, nil)
+			},
+			wantAlg: keyWrapAlgorithmRSA,
+		},
+		{
+			name:    "symmetric key",
+			keyFile: writeTestFile(t, dir, "symmetric.key", []byte(base64.StdEncoding.EncodeToString(symmetricKey)+"\n")),
+			unwrap: func(wrappedKey []byte) ([]byte, error) {
+				aead, err := newAESGCM(symmetricKey)
+				if err != nil {
+					return nil, err
+				}
+				return aead.Open(nil, wrappedKey[:aead.NonceSize()], wrappedKey[aead.NonceSize():], nil)
+			},
+			wantAlg: keyWrapAlgorithmSymmetric,
+		},
+		{
+			name:      "invalid key",
+			keyFile:   writeTestFile(t, dir, "invalid.key", []byte("not a key")),
+			wantError: true,
+		},
+		{
+			name:      "missing key",
+			keyFile:   dir + "/missing.key",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := &fakeTargetExporter{readers: map[string]string{}}
+			exporter := NewEncryptingExporter(target, tt.keyFile)
+
+			err := exporter.ExportReader("node1.zip", strings.NewReader("zip content"))
+			if tt.wantError {
+				// Nothing may be exported unencrypted.
+				if err == nil || len(target.readers) != 0 {
+					t.Errorf("expected error and no export, found error %v and exports %v", err, target.readers)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExportReader() error = %v", err)
+			}
+			if err := exporter.ExportStream("stream", strings.NewReader("stream content")); err != nil {
+				t.Fatalf("ExportStream() error = %v", err)
+			}
+			if err := exporter.ExportWrappedKey(); err != nil {
+				t.Fatalf("ExportWrappedKey() error = %v", err)
+			}
+
+			var keyInfo EncryptionKeyInfo
+			if err := json.Unmarshal([]byte(target.readers[encryptionKeyFileName]), &keyInfo); err != nil {
+				t.Fatalf("unmarshal encryption key: %v", err)
+			}
+			if keyInfo.KeyWrapAlgorithm != tt.wantAlg || keyInfo.Algorithm != encryptionAlgorithm || keyInfo.ChunkSize != encryptionChunkSize {
+				t.Errorf("unexpected encryption key info %+v", keyInfo)
+			}
+
+			wrappedKey, err := base64.StdEncoding.DecodeString(keyInfo.WrappedKey)
+			if err != nil {
+				t.Fatalf("decode wrapped key: %v", err)
+			}
+			dataKey, err := tt.unwrap(wrappedKey)
+			if err != nil {
+				t.Fatalf("unwrap data key: %v", err)
+			}
+
+			for name, want := range map[string]string{"node1.zip.enc": "zip content", "stream.enc": "stream content"} {
+				decrypted, err := decryptContent(dataKey, []byte(target.readers[name]))
+				if err != nil {
+					t.Fatalf("decrypt %s: %v", name, err)
+				}
+				if string(decrypted) != want {
+					t.Errorf("%s: expected %q, found %q", name, want, decrypted)
+				}
+			}
+		})
+	}
+}
+
+func TestEncryptingExporterExport(t *testing.T) {
+	keyFile := writeTestFile(t, t.TempDir(), "symmetric.key", []byte(base64.StdEncoding.EncodeToString(newTestDataKey(t))))
+	target := &recordingDataExporter{}
+	exporter := NewEncryptingExporter(target, keyFile)
+
+	producer := &testDataProducer{
+		name: "collector1",
+		data: map[string]interfaces.DataValue{"key1": utils.NewStringDataValue("value1")},
+	}
+	if err := exporter.Export(producer); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	value, ok := target.data["key1.enc"]
+	if !ok || len(target.data) != 1 {
+		t.Fatalf("expected only key1.enc to be exported, found %v", target.data)
+	}
+
+	reader, err := value.GetReader()
+	if err != nil {
+		t.Fatalf("GetReader() error = %v", err)
+	}
+	defer reader.Close()
+	encrypted, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("read encrypted value: %v", err)
+	}
+
+	if int64(len(encrypted)) != value.GetLength() {
+		t.Errorf("expected length %d, found %d", value.GetLength(), len(encrypted))
+	}
+	decrypted, err := decryptContent(exporter.dataKey, encrypted)
+	if err != nil || string(decrypted) != "value1" {
+		t.Errorf("expected to decrypt value1, found %q (error %v)", decrypted, err)
+	}
+}
+
+type recordingDataExporter struct {
+	data map[string]interfaces.DataValue
+}
+
+func (e *recordingDataExporter) Export(producer interfaces.DataProducer) error {
+	e.data = producer.GetData()
+	return nil
+}
+
+func (e *recordingDataExporter) ExportReader(name string, reader io.ReadSeeker) error {
+	return nil
+}
@@ -0,0 +1,236 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+)
+
+const (
+	// Objects larger than this are uploaded in chunks of this size in a resumable upload session, so that a failed
+	// chunk is retried on its own rather than uploading the object again from the start. Smaller objects are uploaded
+	// in a single request. Each upload buffers one chunk in memory.
+	gcsUploadChunkSize = 16 * 1024 * 1024
+
+	// The time for which the upload of each chunk is retried.
+	gcsChunkRetryDeadline = 2 * time.Minute
+)
+
+// GCSExporter defines an exporter which uploads data to a Google Cloud Storage bucket, using the same
+// <run ID>/<node>/<key> object names as the Azure Blob exporter uses for blobs.
+type GCSExporter struct {
+	runtimeInfo *utils.RuntimeInfo
+	bucketName  string
+	runId       string
+	chunkSize   int
+
+	// Uploads of the same content to the same name are idempotent, so every request is retried, which the client
+	// otherwise only does for uploads with preconditions.
+	retryOptions []storage.RetryOption
+
+	// The bucket is checked on first use and shared by all exports. Failures aren't cached, so a later export tries
+	// again.
+	createClient func(context.Context, *utils.RuntimeInfo) (*storage.Client, error)
+	bucketLock   sync.Mutex
+	bucket       *storage.BucketHandle
+}
+
+func NewGCSExporter(runtimeInfo *utils.RuntimeInfo, bucketName, runId string) *GCSExporter {
+	return &GCSExporter{
+		runtimeInfo:  runtimeInfo,
+		bucketName:   bucketName,
+		runId:        runId,
+		chunkSize:    gcsUploadChunkSize,
+		retryOptions: []storage.RetryOption{storage.WithPolicy(storage.RetryAlways)},
+		createClient: createGCSClient,
+	}
+}
+
+// createGCSClient creates a client authenticated with the service account key (or credential configuration) file if
+// one is configured, and otherwise with the application default credentials, such as those of a workload identity.
+func createGCSClient(ctx context.Context, runtimeInfo *utils.RuntimeInfo) (*storage.Client, error) {
+	options := []option.ClientOption{}
+	if len(runtimeInfo.GCSExportCredentials) > 0 {
+		options = append(options, option.WithCredentialsFile(runtimeInfo.GCSExportCredentials))
+	}
+
+	client, err := storage.NewClient(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("create GCS client: %w", err)
+	}
+
+	return client, nil
+}
+
+// getBucket returns the bucket shared by all exports, checking that it exists on first use.
+func (exporter *GCSExporter) getBucket(ctx context.Context) (*storage.BucketHandle, error) {
+	exporter.bucketLock.Lock()
+	defer exporter.bucketLock.Unlock()
+
+	if exporter.bucket != nil {
+		return exporter.bucket, nil
+	}
+
+	client, err := exporter.createClient(ctx, exporter.runtimeInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	bucket := client.Bucket(exporter.bucketName).Retryer(exporter.retryOptions...)
+	if _, err := bucket.Attrs(ctx); err != nil {
+		// Credentials which can only create objects (such as with the Storage Object Creator role) can't get the
+		// bucket, in which case a missing bucket is reported by the first upload instead.
+		var apiError *googleapi.Error
+		if !errors.As(err, &apiError) || apiError.Code != http.StatusForbidden {
+			client.Close()
+			return nil, exporter.wrapError(fmt.Sprintf("get GCS bucket %s", exporter.bucketName), err)
+		}
+		log.Printf("Unable to check GCS bucket %s exists, continuing: %v", exporter.bucketName, err)
+	}
+
+	exporter.bucket = bucket
+	return bucket, nil
+}
+
+// wrapError adds context to an error from the GCS client, with a clear message if the bucket doesn't exist.
+func (exporter *GCSExporter) wrapError(action string, err error) error {
+	var apiError *googleapi.Error
+	if errors.Is(err, storage.ErrBucketNotExist) || (errors.As(err, &apiError) && apiError.Code == http.StatusNotFound && strings.Contains(strings.ToLower(apiError.Message), "bucket")) {
+		return fmt.Errorf("%s: bucket %s does not exist, or is not visible to the configured credentials: %w", action, exporter.bucketName, err)
+	}
+
+	return fmt.Errorf("%s: %w", action, err)
+}
+
+// getObjectName returns the object name for a key: <runId>/<node>/<key>. The run identifier (EXPORT_RUN_ID,
+// defaulting to DIAGNOSTIC_RUN_ID) distinguishes runs exporting to the same bucket, and is omitted if empty.
+func (exporter *GCSExporter) getObjectName(key string) string {
+	segments := []string{}
+	for _, segment := range []string{exporter.runId, exporter.runtimeInfo.HostNodeName, key} {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// Export implements the interface method
+func (exporter *GCSExporter) Export(producer interfaces.DataProducer) error {
+	ctx := context.Background()
+	bucket, err := exporter.getBucket(ctx)
+	if err != nil {
+		return err
+	}
+
+	data := producer.GetData()
+	for _, key := range utils.SortedKeys(data) {
+		value := data[key]
+		object := bucket.Object(exporter.getObjectName(key))
+
+		skip, err := exporter.skipExisting(ctx, object, key)
+		if err != nil {
+			return err
+		}
+		if skip {
+			continue
+		}
+
+		log.Printf("\tUpload object: %s (of size %d bytes)", key, value.GetLength())
+
+		err = func() error {
+			valueReadCloser, err := value.GetReader()
+			if err != nil {
+				return err
+			}
+
+			defer valueReadCloser.Close()
+
+			return exporter.upload(ctx, object, valueReadCloser)
+		}()
+
+		if err != nil {
+			return exporter.wrapError(fmt.Sprintf("upload file %s to GCS", key), err)
+		}
+	}
+
+	return nil
+}
+
+func (exporter *GCSExporter) ExportReader(name string, reader io.ReadSeeker) error {
+	return exporter.ExportStream(name, reader)
+}
+
+// ExportStream implements the interface method. The content is uploaded in chunks as it is read, so only one chunk is
+// held in memory.
+func (exporter *GCSExporter) ExportStream(name string, reader io.Reader) error {
+	ctx := context.Background()
+	bucket, err := exporter.getBucket(ctx)
+	if err != nil {
+		return err
+	}
+
+	object := bucket.Object(exporter.getObjectName(name))
+	skip, err := exporter.skipExisting(ctx, object, name)
+	if err != nil || skip {
+		return err
+	}
+
+	log.Printf("Uploading the file with object name: %s\n", name)
+	if err := exporter.upload(ctx, object, reader); err != nil {
+		return exporter.wrapError(fmt.Sprintf("upload file %s to GCS", name), err)
+	}
+
+	return nil
+}
+
+// upload writes the content of the reader to the object, replacing any existing content. The upload is cancelled,
+// leaving any existing content unchanged, if the content can't be read.
+func (exporter *GCSExporter) upload(ctx context.Context, object *storage.ObjectHandle, reader io.Reader) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	writer := object.Retryer(exporter.retryOptions...).NewWriter(ctx)
+	writer.ChunkSize = exporter.chunkSize
+	writer.ChunkRetryDeadline = gcsChunkRetryDeadline
+
+	if _, err := io.Copy(writer, reader); err != nil {
+		cancel()
+		writer.Close()
+		return err
+	}
+
+	return writer.Close()
+}
+
+// skipExisting returns whether the upload of the named object should be skipped because it already exists, such as
+// when adding to the output of an earlier run with EXPORT_EXISTING set to skip. Otherwise, existing objects are
+// overwritten.
+func (exporter *GCSExporter) skipExisting(ctx context.Context, object *storage.ObjectHandle, name string) (bool, error) {
+	if exporter.runtimeInfo.ExportExisting != utils.ExportExistingSkip {
+		return false, nil
+	}
+
+	_, err := object.Attrs(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, exporter.wrapError(fmt.Sprintf("check whether object %s exists", name), err)
+	}
+
+	log.Printf("Skipping object name: %s, which already exists\n", name)
+	return true, nil
+}
@@ -0,0 +1,262 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+	"github.com/googleapis/gax-go/v2"
+	"google.golang.org/api/option"
+)
+
+// fakeGCSServer implements the parts of the GCS JSON API used by the exporter: getting buckets and objects, and
+// multipart and resumable uploads. The first request of each resumable upload session after the first chunk fails,
+// to check it is retried.
+type fakeGCSServer struct {
+	bucket   string
+	lock     sync.Mutex
+	objects  map[string][]byte
+	sessions map[string]*fakeGCSSession
+	failures int
+}
+
+type fakeGCSSession struct {
+	name    string
+	content []byte
+	failed  bool
+}
+
+func newFakeGCSServer(t *testing.T, bucket string) (*fakeGCSServer, *httptest.Server) {
+	fake := &fakeGCSServer{
+		bucket:   bucket,
+		objects:  map[string][]byte{},
+		sessions: map[string]*fakeGCSSession{},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fake.lock.Lock()
+		defer fake.lock.Unlock()
+
+		if err := fake.handle(w, r); err != nil {
+			t.Errorf("unexpected request %s %s: %v", r.Method, r.URL, err)
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return fake, server
+}
+
+func (fake *fakeGCSServer) handle(w http.ResponseWriter, r *http.Request) error {
+	switch {
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/storage/v1/b/"):
+		bucket, object, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/storage/v1/b/"), "/o/")
+		if bucket != fake.bucket {
+			return writeGCSError(w, http.StatusNotFound, "The specified bucket does not exist.")
+		}
+		if len(object) == 0 {
+			return json.NewEncoder(w).Encode(map[string]string{"name": bucket})
+		}
+		if _, ok := fake.objects[object]; !ok {
+			return writeGCSError(w, http.StatusNotFound, "No such object: "+bucket+"/"+object)
+		}
+		return writeGCSObject(w, bucket, object)
+
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/upload/storage/v1/b/"):
+		bucket := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/upload/storage/v1/b/"), "/o")
+		if bucket != fake.bucket {
+			return writeGCSError(w, http.StatusNotFound, "The specified bucket does not exist.")
+		}
+
+		name := r.URL.Query().Get("name")
+		switch r.URL.Query().Get("uploadType") {
+		case "multipart":
+			content, err := readMultipartMedia(r)
+			if err != nil {
+				return err
+			}
+			fake.objects[name] = content
+			return writeGCSObject(w, bucket, name)
+		case "resumable":
+			id := strconv.Itoa(len(fake.sessions))
+			fake.sessions[id] = &fakeGCSSession{name: name}
+			w.Header().Set("Location", fmt.Sprintf("http://%s/session/%s", r.Host, id))
+			return nil
+		}
+
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/session/"):
+		session, ok := fake.sessions[strings.TrimPrefix(r.URL.Path, "/session/")]
+		if !ok {
+			return fmt.Errorf("unknown session")
+		}
+
+		content, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+
+		// The content range is "bytes <first>-<last>/<total>", where the total is "*" until the last chunk.
+		var first, last int
+		var total string
+		if _, err := fmt.Sscanf(r.Header.Get("Content-Range"), "bytes %d-%d/%s", &first, &last, &total); err != nil {
+			return fmt.Errorf("content range %q: %w", r.Header.Get("Content-Range"), err)
+		}
+		if first > 0 && !session.failed {
+			session.failed = true
+			fake.failures++
+			return writeGCSError(w, http.StatusServiceUnavailable, "Backend Error")
+		}
+		if first != len(session.content) {
+			return fmt.Errorf("chunk starts at %d, after %d bytes", first, len(session.content))
+		}
+		session.content = append(session.content, content...)
+
+		// The client asks for incomplete uploads to be reported with a header rather than the status code.
+		if total == "*" {
+			w.Header().Set("Range", fmt.Sprintf("bytes=0-%d", last))
+			w.Header().Set("X-Http-Status-Code-Override", "308")
+			return nil
+		}
+		fake.objects[session.name] = session.content
+		return writeGCSObject(w, fake.bucket, session.name)
+	}
+
+	return fmt.Errorf("not implemented")
+}
+
+func readMultipartMedia(r *http.Request) ([]byte, error) {
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+
+	// The first part is the metadata of the object, and the second its content.
+	reader := multipart.NewReader(r.Body, params["boundary"])
+	for i := 0; i < 2; i++ {
+		part, err := reader.NextPart()
+		if err != nil {
+			return nil, err
+		}
+		if i == 1 {
+			return io.ReadAll(part)
+		}
+	}
+	return nil, nil
+}
+
+func writeGCSObject(w http.ResponseWriter, bucket, name string) error {
+	return json.NewEncoder(w).Encode(map[string]string{"bucket": bucket, "name": name})
+}
+
+func writeGCSError(w http.ResponseWriter, code int, message string) error {
+	w.WriteHeader(code)
+	return json.NewEncoder(w).Encode(map[string]interface{}{"error": map[string]interface{}{"code": code, "message": message}})
+}
+
+func newTestGCSExporter(runtimeInfo *utils.RuntimeInfo, bucket string, server *httptest.Server) *GCSExporter {
+	exporter := NewGCSExporter(runtimeInfo, bucket, "run-1")
+	exporter.retryOptions = append(exporter.retryOptions, storage.WithBackoff(gax.Backoff{Initial: time.Millisecond}))
+	exporter.createClient = func(ctx context.Context, _ *utils.RuntimeInfo) (*storage.Client, error) {
+		return storage.NewClient(ctx, option.WithEndpoint(server.URL+"/storage/v1/"), option.WithoutAuthentication())
+	}
+	return exporter
+}
+
+func TestGCSExporterGetObjectName(t *testing.T) {
+	tests := []struct {
+		name     string
+		runId    string
+		key      string
+		expected string
+	}{
+		{
+			name:     "with run identifier and nested key",
+			runId:    "2023-06-15T12-00-00Z",
+			key:      "containerlogs/kube-system_coredns",
+			expected: "2023-06-15T12-00-00Z/node-1/containerlogs/kube-system_coredns",
+		},
+		{
+			name:     "without run identifier",
+			runId:    "",
+			key:      "node-1.zip",
+			expected: "node-1/node-1.zip",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := NewGCSExporter(&utils.RuntimeInfo{HostNodeName: "node-1"}, "periscope", tt.runId)
+			actual := exporter.getObjectName(tt.key)
+			if actual != tt.expected {
+				t.Errorf("unexpected object name: expected %s, found %s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestGCSExporterBucketNotFound(t *testing.T) {
+	_, server := newFakeGCSServer(t, "periscope")
+	exporter := newTestGCSExporter(&utils.RuntimeInfo{HostNodeName: "node-1"}, "missing", server)
+
+	err := exporter.ExportReader("dns", bytes.NewReader([]byte("content")))
+	if err == nil || !strings.Contains(err.Error(), "bucket missing does not exist") {
+		t.Errorf("expected a clear error for the missing bucket, found %v", err)
+	}
+}
+
+func TestGCSExporterExport(t *testing.T) {
+	fake, server := newFakeGCSServer(t, "periscope")
+	fake.objects["run-1/node-1/existing"] = []byte("earlier run")
+
+	runtimeInfo := &utils.RuntimeInfo{HostNodeName: "node-1", ExportExisting: utils.ExportExistingSkip}
+	exporter := newTestGCSExporter(runtimeInfo, "periscope", server)
+	exporter.chunkSize = 256 * 1024
+
+	producer := &testDataProducer{
+		name: "test",
+		data: map[string]interfaces.DataValue{
+			"dns":      utils.NewStringDataValue("nameserver 10.0.0.10"),
+			"existing": utils.NewStringDataValue("this run"),
+		},
+	}
+	if err := exporter.Export(producer); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	// Content larger than the chunk size is uploaded in a resumable session, in which failed chunks are retried.
+	large := bytes.Repeat([]byte("0123456789"), 60*1024)
+	if err := exporter.ExportStream("node-1.zip", bytes.NewReader(large)); err != nil {
+		t.Fatalf("ExportStream() error = %v", err)
+	}
+
+	expected := map[string][]byte{
+		"run-1/node-1/dns":        []byte("nameserver 10.0.0.10"),
+		"run-1/node-1/existing":   []byte("earlier run"),
+		"run-1/node-1/node-1.zip": large,
+	}
+	if len(fake.objects) != len(expected) {
+		t.Errorf("unexpected objects %v", fake.objects)
+	}
+	for name, content := range expected {
+		if !bytes.Equal(fake.objects[name], content) {
+			t.Errorf("unexpected content of %s (%d bytes)", name, len(fake.objects[name]))
+		}
+	}
+	if fake.failures != 1 {
+		t.Errorf("expected one failed chunk, found %d", fake.failures)
+	}
+}
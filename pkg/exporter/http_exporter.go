@@ -0,0 +1,175 @@
+package exporter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+// ErrHTTPExportUnauthorized is returned when the collection endpoint rejects our credentials. This is not retried,
+// since every subsequent upload would fail in the same way.
+var ErrHTTPExportUnauthorized = errors.New("collection endpoint rejected credentials")
+
+const (
+	httpExportMaxAttempts = 3
+	httpExportRetryDelay  = 2 * time.Second
+)
+
+// Metadata headers sent with each upload, so that the intake service can identify the source of the data.
+const (
+	httpExportNameHeader         = "X-Periscope-Name"
+	httpExportNodeHeader         = "X-Periscope-Node"
+	httpExportRunIdHeader        = "X-Periscope-Run-Id"
+	httpExportCreationTimeHeader = "X-Periscope-Creation-Time"
+)
+
+// HTTPExporter defines an exporter which POSTs data to a collection endpoint
+type HTTPExporter struct {
+	runtimeInfo  *utils.RuntimeInfo
+	client       *http.Client
+	clientErr    error
+	creationTime time.Time
+	retryDelay   time.Duration
+}
+
+func NewHTTPExporter(runtimeInfo *utils.RuntimeInfo, creationTime time.Time) *HTTPExporter {
+	client := &http.Client{Timeout: runtimeInfo.HTTPExportTimeout}
+
+	// Any problem with the TLS files is reported by each export, rather than failing the whole run.
+	tlsConfig, err := getHTTPExportTLSConfig(runtimeInfo)
+	if err == nil && tlsConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		client.Transport = transport
+	}
+
+	return &HTTPExporter{
+		runtimeInfo:  runtimeInfo,
+		client:       client,
+		clientErr:    err,
+		creationTime: creationTime,
+		retryDelay:   httpExportRetryDelay,
+	}
+}
+
+// Export implements the interface method
+func (exporter *HTTPExporter) Export(producer interfaces.DataProducer) error {
+	if exporter.runtimeInfo.HTTPExportArchive {
+		return exportArchive(exporter, ArchiveFormatTarGz, producer)
+	}
+
+	data := producer.GetData()
+	for _, key := range utils.SortedKeys(data) {
+		value := data[key]
+		log.Printf("\tPost file: %s (of size %d bytes)", key, value.GetLength())
+
+		if err := exporter.post(key, "application/octet-stream", value.GetReader); err != nil {
+			return fmt.Errorf("post file %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+func (exporter *HTTPExporter) ExportReader(name string, reader io.ReadSeeker) error {
+	log.Printf("Posting the file with name: %s\n", name)
+
+	// The reader is rewound for each attempt, so that the full content is sent on retry.
+	getBody := func() (io.ReadCloser, error) {
+		if _, err := reader.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return io.NopCloser(reader), nil
+	}
+
+	return exporter.post(name, contentTypeForName(name), getBody)
+}
+
+func contentTypeForName(name string) string {
+	switch {
+	case strings.HasSuffix(name, ".tar.gz"):
+		return "application/gzip"
+	case strings.HasSuffix(name, ".zip"):
+		return "application/zip"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+func (exporter *HTTPExporter) post(name, contentType string, getBody func() (io.ReadCloser, error)) error {
+	if exporter.clientErr != nil {
+		return fmt.Errorf("configure TLS for collection endpoint: %w", exporter.clientErr)
+	}
+
+	var err error
+	for attempt := 1; attempt <= httpExportMaxAttempts; attempt++ {
+		var retryable bool
+		retryable, err = exporter.tryPost(name, contentType, getBody)
+		if err == nil || !retryable {
+			return err
+		}
+
+		if attempt < httpExportMaxAttempts {
+			log.Printf("Post of %s failed (attempt %d of %d), retrying: %v", name, attempt, httpExportMaxAttempts, err)
+			time.Sleep(exporter.retryDelay * time.Duration(attempt))
+		}
+	}
+
+	return err
+}
+
+// tryPost makes a single upload attempt, returning whether a failure may succeed if retried.
+func (exporter *HTTPExporter) tryPost(name, contentType string, getBody func() (io.ReadCloser, error)) (bool, error) {
+	body, err := getBody()
+	if err != nil {
+		return false, fmt.Errorf("read content: %w", err)
+	}
+	defer body.Close()
+
+	req, err := http.NewRequest(http.MethodPost, exporter.runtimeInfo.HTTPExportURL, body)
+	if err != nil {
+		return false, fmt.Errorf("create request: %w", err)
+	}
+
+	for header, value := range exporter.runtimeInfo.HTTPExportHeaders {
+		req.Header.Set(header, value)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set(httpExportNameHeader, name)
+	req.Header.Set(httpExportNodeHeader, exporter.runtimeInfo.HostNodeName)
+	req.Header.Set(httpExportRunIdHeader, exporter.runtimeInfo.ExportRunId)
+	req.Header.Set(httpExportCreationTimeHeader, exporter.creationTime.UTC().Format(time.RFC3339))
+	if exporter.runtimeInfo.HTTPExportToken != "" {
+		req.Header.Set("Authorization", "Bearer "+exporter.runtimeInfo.HTTPExportToken)
+	}
+
+	resp, err := exporter.client.Do(req)
+	if errors.Is(err, ErrHTTPExportCertificatePin) {
+		log.Printf("Error: %v, check the configured pinned certificates", err)
+		return false, err
+	}
+	if err != nil {
+		// Includes timeouts, which are worth retrying.
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		log.Printf("Error: collection endpoint returned %s, check the configured HTTP export token", resp.Status)
+		return false, fmt.Errorf("%w: %s", ErrHTTPExportUnauthorized, resp.Status)
+	case resp.StatusCode >= 500:
+		return true, fmt.Errorf("collection endpoint returned %s", resp.Status)
+	case resp.StatusCode >= 300:
+		return false, fmt.Errorf("collection endpoint returned %s", resp.Status)
+	}
+
+	return false, nil
+}
@@ -0,0 +1,260 @@
+package exporter
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+type testDataProducer struct {
+	name string
+	data map[string]interfaces.DataValue
+}
+
+func (p *testDataProducer) GetName() string {
+	return p.name
+}
+
+func (p *testDataProducer) GetData() map[string]interfaces.DataValue {
+	return p.data
+}
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// newTestServer returns a server which responds with each of the given status codes in turn (repeating the last),
+// recording the requests it receives.
+func newTestServer(t *testing.T, statusCodes ...int) (*httptest.Server, func() []receivedRequest) {
+	var mu sync.Mutex
+	requests := []receivedRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("error reading request body: %v", err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, receivedRequest{header: r.Header, body: body})
+		statusCode := statusCodes[len(statusCodes)-1]
+		if len(requests) <= len(statusCodes) {
+			statusCode = statusCodes[len(requests)-1]
+		}
+		w.WriteHeader(statusCode)
+	}))
+
+	return server, func() []receivedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+}
+
+func newTestHTTPExporter(url string, archive bool) *HTTPExporter {
+	runtimeInfo := &utils.RuntimeInfo{
+		RunId:             "run-1",
+		ExportRunId:       "run-1",
+		HostNodeName:      "node-1",
+		HTTPExportURL:     url,
+		HTTPExportToken:   "secret-token",
+		HTTPExportHeaders: map[string]string{"X-Team": "platform"},
+		HTTPExportTimeout: 5 * time.Second,
+		HTTPExportArchive: archive,
+	}
+
+	exporter := NewHTTPExporter(runtimeInfo, time.Date(2023, 6, 15, 12, 0, 0, 0, time.UTC))
+	exporter.retryDelay = time.Millisecond
+	return exporter
+}
+
+func TestHTTPExporterExport(t *testing.T) {
+	server, getRequests := newTestServer(t, http.StatusOK)
+	defer server.Close()
+
+	producer := &testDataProducer{
+		name: "test",
+		data: map[string]interfaces.DataValue{"key1": utils.NewStringDataValue("value1")},
+	}
+
+	exporter := newTestHTTPExporter(server.URL, false)
+	if err := exporter.Export(producer); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	requests := getRequests()
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, found %d", len(requests))
+	}
+
+	expectedHeaders := map[string]string{
+		"Authorization":             "Bearer secret-token",
+		"X-Team":                    "platform",
+		"X-Periscope-Name":          "key1",
+		"X-Periscope-Node":          "node-1",
+		"X-Periscope-Run-Id":        "run-1",
+		"X-Periscope-Creation-Time": "2023-06-15T12:00:00Z",
+	}
+	for header, expected := range expectedHeaders {
+		if actual := requests[0].header.Get(header); actual != expected {
+			t.Errorf("unexpected %s header: expected %s, found %s", header, expected, actual)
+		}
+	}
+
+	if string(requests[0].body) != "value1" {
+		t.Errorf("unexpected body: %s", requests[0].body)
+	}
+}
+
+func TestHTTPExporterExportArchive(t *testing.T) {
+	server, getRequests := newTestServer(t, http.StatusOK)
+	defer server.Close()
+
+	producer := &testDataProducer{
+		name: "test",
+		data: map[string]interfaces.DataValue{
+			"key1": utils.NewStringDataValue("value1"),
+			"key2": utils.NewStringDataValue("value2"),
+		},
+	}
+
+	exporter := newTestHTTPExporter(server.URL, true)
+	if err := exporter.Export(producer); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	requests := getRequests()
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, found %d", len(requests))
+	}
+	if name := requests[0].header.Get("X-Periscope-Name"); name != "test.tar.gz" {
+		t.Errorf("unexpected name %s", name)
+	}
+
+	gz, err := gzip.NewReader(strings.NewReader(string(requests[0].body)))
+	if err != nil {
+		t.Fatalf("error reading gzip content: %v", err)
+	}
+
+	entries := map[string]string{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("error reading tar content: %v", err)
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("error reading tar entry: %v", err)
+		}
+		entries[header.Name] = string(content)
+	}
+
+	if entries["key1"] != "value1" || entries["key2"] != "value2" || len(entries) != 2 {
+		t.Errorf("unexpected archive entries: %v", entries)
+	}
+}
+
+func TestHTTPExporterExportReaderStatus(t *testing.T) {
+	tests := []struct {
+		name         string
+		statusCodes  []int
+		wantErr      bool
+		wantAuthErr  bool
+		wantRequests int
+	}{
+		{
+			name:         "success",
+			statusCodes:  []int{http.StatusCreated},
+			wantErr:      false,
+			wantRequests: 1,
+		},
+		{
+			name:         "retry on server error",
+			statusCodes:  []int{http.StatusServiceUnavailable, http.StatusInternalServerError, http.StatusOK},
+			wantErr:      false,
+			wantRequests: 3,
+		},
+		{
+			name:         "persistent server error",
+			statusCodes:  []int{http.StatusBadGateway},
+			wantErr:      true,
+			wantRequests: httpExportMaxAttempts,
+		},
+		{
+			name:         "unauthorized",
+			statusCodes:  []int{http.StatusUnauthorized},
+			wantErr:      true,
+			wantAuthErr:  true,
+			wantRequests: 1,
+		},
+		{
+			name:         "forbidden",
+			statusCodes:  []int{http.StatusForbidden},
+			wantErr:      true,
+			wantAuthErr:  true,
+			wantRequests: 1,
+		},
+		{
+			name:         "bad request",
+			statusCodes:  []int{http.StatusBadRequest},
+			wantErr:      true,
+			wantRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, getRequests := newTestServer(t, tt.statusCodes...)
+			defer server.Close()
+
+			exporter := newTestHTTPExporter(server.URL, false)
+			err := exporter.ExportReader("node-1.zip", strings.NewReader("content"))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ExportReader() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrHTTPExportUnauthorized) != tt.wantAuthErr {
+				t.Errorf("expected unauthorized error %v, found %v", tt.wantAuthErr, err)
+			}
+
+			requests := getRequests()
+			if len(requests) != tt.wantRequests {
+				t.Fatalf("expected %d requests, found %d", tt.wantRequests, len(requests))
+			}
+
+			// Every attempt should send the full content.
+			for _, request := range requests {
+				if string(request.body) != "content" {
+					t.Errorf("unexpected body: %s", request.body)
+				}
+			}
+		})
+	}
+}
+
+func TestHTTPExporterTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	exporter := newTestHTTPExporter(server.URL, false)
+	exporter.client.Timeout = 20 * time.Millisecond
+	if err := exporter.ExportReader("node-1.zip", strings.NewReader("content")); err == nil {
+		t.Errorf("expected timeout error")
+	}
+}
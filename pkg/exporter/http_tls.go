@@ -0,0 +1,76 @@
+package exporter
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+// ErrHTTPExportCertificatePin is returned when the collection endpoint presents a certificate which doesn't match
+// any pinned fingerprint. This is not retried, since the endpoint would present the same certificate again.
+var ErrHTTPExportCertificatePin = errors.New("collection endpoint certificate does not match any pinned fingerprint")
+
+// getHTTPExportTLSConfig returns the TLS configuration for requests to the collection endpoint, or nil if the
+// defaults are used. A configured CA bundle is trusted in addition to the system roots.
+func getHTTPExportTLSConfig(runtimeInfo *utils.RuntimeInfo) (*tls.Config, error) {
+	if len(runtimeInfo.HTTPExportCAFile) == 0 && len(runtimeInfo.HTTPExportClientCert) == 0 && len(runtimeInfo.HTTPExportPinnedCerts) == 0 {
+		return nil, nil
+	}
+
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if len(runtimeInfo.HTTPExportCAFile) > 0 {
+		bundle, err := os.ReadFile(runtimeInfo.HTTPExportCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA bundle: %w", err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(bundle) {
+			return nil, fmt.Errorf("no PEM certificates found in CA bundle %s", runtimeInfo.HTTPExportCAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if len(runtimeInfo.HTTPExportClientCert) > 0 {
+		cert, err := tls.LoadX509KeyPair(runtimeInfo.HTTPExportClientCert, runtimeInfo.HTTPExportClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if len(runtimeInfo.HTTPExportPinnedCerts) > 0 {
+		pins := runtimeInfo.HTTPExportPinnedCerts
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			return verifyPinnedCertificate(state, pins)
+		}
+	}
+
+	return config, nil
+}
+
+// verifyPinnedCertificate checks the SHA-256 fingerprint of the server's leaf certificate against the pinned
+// fingerprints. This runs after the usual chain verification, so a pinned certificate must also be trusted.
+func verifyPinnedCertificate(state tls.ConnectionState, pins []string) error {
+	if len(state.PeerCertificates) == 0 {
+		return fmt.Errorf("%w: no certificate presented", ErrHTTPExportCertificatePin)
+	}
+
+	sum := sha256.Sum256(state.PeerCertificates[0].Raw)
+	fingerprint := hex.EncodeToString(sum[:])
+	if utils.Contains(pins, fingerprint) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s presented certificate with SHA-256 %s", ErrHTTPExportCertificatePin, state.ServerName, fingerprint)
+}
@@ -0,0 +1,213 @@
+package exporter
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testCertificate struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// newTestCertificate creates a certificate signed by the parent, or a self-signed CA if the parent is nil.
+func newTestCertificate(t *testing.T, commonName string, parent *testCertificate) *testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("error generating key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("error creating certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("error parsing certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("error marshalling key: %v", err)
+	}
+
+	return &testCertificate{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func (c *testCertificate) fingerprint() string {
+	sum := sha256.Sum256(c.cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+func writeTestFile(t *testing.T, dir, name string, content []byte) string {
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		t.Fatalf("error writing %s: %v", path, err)
+	}
+	return path
+}
+
+func TestHTTPExporterTLS(t *testing.T) {
+	ca := newTestCertificate(t, "periscope-test-ca", nil)
+	serverCert := newTestCertificate(t, "intake", ca)
+	clientCert := newTestCertificate(t, "periscope", ca)
+	otherCA := newTestCertificate(t, "other-ca", nil)
+
+	dir := t.TempDir()
+	caFile := writeTestFile(t, dir, "ca.pem", ca.certPEM)
+	otherCAFile := writeTestFile(t, dir, "other-ca.pem", otherCA.certPEM)
+	clientCertFile := writeTestFile(t, dir, "tls.crt", clientCert.certPEM)
+	clientKeyFile := writeTestFile(t, dir, "tls.key", clientCert.keyPEM)
+	invalidFile := writeTestFile(t, dir, "invalid.pem", []byte("not a certificate"))
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+
+	tests := []struct {
+		name              string
+		requireClientCert bool
+		caFile            string
+		clientCert        string
+		clientKey         string
+		pinnedCerts       []string
+		wantErr           bool
+		wantPinErr        bool
+		wantRequests      int
+	}{
+		{
+			name:         "no CA bundle",
+			wantErr:      true,
+			wantRequests: 0,
+		},
+		{
+			name:         "custom CA",
+			caFile:       caFile,
+			wantErr:      false,
+			wantRequests: 1,
+		},
+		{
+			name:         "other CA",
+			caFile:       otherCAFile,
+			wantErr:      true,
+			wantRequests: 0,
+		},
+		{
+			name:         "invalid CA bundle",
+			caFile:       invalidFile,
+			wantErr:      true,
+			wantRequests: 0,
+		},
+		{
+			name:              "client certificate",
+			requireClientCert: true,
+			caFile:            caFile,
+			clientCert:        clientCertFile,
+			clientKey:         clientKeyFile,
+			wantErr:           false,
+			wantRequests:      1,
+		},
+		{
+			name:              "missing client certificate",
+			requireClientCert: true,
+			caFile:            caFile,
+			wantErr:           true,
+			wantRequests:      0,
+		},
+		{
+			name:         "matching pin",
+			caFile:       caFile,
+			pinnedCerts:  []string{otherCA.fingerprint(), serverCert.fingerprint()},
+			wantErr:      false,
+			wantRequests: 1,
+		},
+		{
+			name:         "pin mismatch",
+			caFile:       caFile,
+			pinnedCerts:  []string{ca.fingerprint()},
+			wantErr:      true,
+			wantPinErr:   true,
+			wantRequests: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+			}))
+			server.TLS = &tls.Config{
+				Certificates: []tls.Certificate{{Certificate: [][]byte{serverCert.cert.Raw}, PrivateKey: serverCert.key}},
+			}
+			if tt.requireClientCert {
+				server.TLS.ClientAuth = tls.RequireAndVerifyClientCert
+				server.TLS.ClientCAs = clientCAs
+			}
+			server.StartTLS()
+			defer server.Close()
+
+			// The TLS configuration is loaded by the constructor, so the exporter is recreated with the TLS files.
+			runtimeInfo := newTestHTTPExporter(server.URL, false).runtimeInfo
+			runtimeInfo.HTTPExportCAFile = tt.caFile
+			runtimeInfo.HTTPExportClientCert = tt.clientCert
+			runtimeInfo.HTTPExportClientKey = tt.clientKey
+			runtimeInfo.HTTPExportPinnedCerts = tt.pinnedCerts
+
+			exporter := NewHTTPExporter(runtimeInfo, time.Now())
+			exporter.retryDelay = time.Millisecond
+
+			err := exporter.ExportReader("node-1.zip", strings.NewReader("content"))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ExportReader() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrHTTPExportCertificatePin) != tt.wantPinErr {
+				t.Errorf("expected pin error %v, found %v", tt.wantPinErr, err)
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("expected %d requests, found %d", tt.wantRequests, requests)
+			}
+		})
+	}
+}
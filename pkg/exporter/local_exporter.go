@@ -0,0 +1,103 @@
+package exporter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+// LocalExporter defines an exporter which writes data to a directory on the local file system,
+// using the same <run ID>/<node>/<key> layout as the Azure Blob exporter. The run ID is that of EXPORT_RUN_ID, so
+// that a run can add to the output of an earlier one, and existing files are kept if EXPORT_EXISTING is skip.
+type LocalExporter struct {
+	runtimeInfo *utils.RuntimeInfo
+	directory   string
+}
+
+func NewLocalExporter(runtimeInfo *utils.RuntimeInfo, directory string) *LocalExporter {
+	return &LocalExporter{
+		runtimeInfo: runtimeInfo,
+		directory:   directory,
+	}
+}
+
+// Export implements the interface method
+func (exporter *LocalExporter) Export(producer interfaces.DataProducer) error {
+	data := producer.GetData()
+	for _, key := range utils.SortedKeys(data) {
+		value := data[key]
+		log.Printf("\tWrite file: %s (of size %d bytes)", key, value.GetLength())
+
+		err := func() error {
+			reader, err := value.GetReader()
+			if err != nil {
+				return err
+			}
+
+			defer reader.Close()
+
+			return exporter.write(key, reader)
+		}()
+
+		if err != nil {
+			return fmt.Errorf("write file %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+func (exporter *LocalExporter) ExportReader(name string, reader io.ReadSeeker) error {
+	log.Printf("Writing the file with name: %s\n", name)
+	return exporter.write(name, reader)
+}
+
+// ExportStream implements the interface method
+func (exporter *LocalExporter) ExportStream(name string, reader io.Reader) error {
+	log.Printf("Writing the stream with name: %s\n", name)
+	return exporter.write(name, reader)
+}
+
+func (exporter *LocalExporter) write(name string, reader io.Reader) error {
+	// Keys with '/' separators are written to subdirectories, and can't escape the output directory.
+	relativePath, err := utils.GetDataKeyPath(name)
+	if err != nil {
+		return err
+	}
+
+	root := filepath.Join(exporter.directory, exporter.runtimeInfo.ExportRunId, exporter.runtimeInfo.HostNodeName)
+	filePath := filepath.Join(root, relativePath)
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if exporter.runtimeInfo.ExportExisting == utils.ExportExistingSkip {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	}
+
+	file, err := os.OpenFile(filePath, flags, 0666)
+	if errors.Is(err, fs.ErrExist) {
+		log.Printf("Skipping %s, which already exists", name)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	if _, err := io.Copy(file, reader); err != nil {
+		return err
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,122 @@
+package exporter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+func TestLocalExporter(t *testing.T) {
+	directory := t.TempDir()
+	runtimeInfo := &utils.RuntimeInfo{
+		RunId:        "run1",
+		ExportRunId:  "run1",
+		HostNodeName: "node1",
+	}
+
+	exporter := NewLocalExporter(runtimeInfo, directory)
+
+	producer := &testDataProducer{
+		name: "collector1",
+		data: map[string]interfaces.DataValue{
+			"key1":            utils.NewStringDataValue("value1"),
+			"datapath/nested": utils.NewStringDataValue("value2"),
+			utils.JoinDataKey("journal", "/var/log/syslog"): utils.NewStringDataValue("value3"),
+		},
+	}
+
+	if err := exporter.Export(producer); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if err := exporter.ExportReader("node1.zip", strings.NewReader("zip content")); err != nil {
+		t.Fatalf("ExportReader() error = %v", err)
+	}
+	if err := exporter.ExportStream("logs/streamed", strings.NewReader("streamed content")); err != nil {
+		t.Fatalf("ExportStream() error = %v", err)
+	}
+
+	expected := map[string]string{
+		"key1":                          "value1",
+		"datapath/nested":               "value2",
+		"journal/%2Fvar%2Flog%2Fsyslog": "value3",
+		"node1.zip":                     "zip content",
+		"logs/streamed":                 "streamed content",
+	}
+	for name, want := range expected {
+		content, err := os.ReadFile(filepath.Join(directory, "run1", "node1", name))
+		if err != nil {
+			t.Errorf("error reading %s: %v", name, err)
+			continue
+		}
+		if string(content) != want {
+			t.Errorf("unexpected content for %s: expected '%s', found '%s'", name, want, content)
+		}
+	}
+
+	for _, name := range []string{"../../escaped", "datapath/../../escaped", `..\escaped`, "datapath//empty", "/absolute"} {
+		if err := exporter.ExportReader(name, strings.NewReader("content")); err == nil {
+			t.Errorf("expected error for invalid file name %s", name)
+		}
+	}
+}
+
+func TestLocalExporterExisting(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		want     string
+	}{
+		{
+			name:     "existing files overwritten",
+			existing: utils.ExportExistingOverwrite,
+			want:     "new content",
+		},
+		{
+			name:     "existing files skipped",
+			existing: utils.ExportExistingSkip,
+			want:     "original content",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			directory := t.TempDir()
+			runtimeInfo := &utils.RuntimeInfo{
+				RunId:          "run2",
+				ExportRunId:    "run1",
+				ExportExisting: tt.existing,
+				HostNodeName:   "node1",
+			}
+
+			existingPath := filepath.Join(directory, "run1", "node1", "key1")
+			if err := os.MkdirAll(filepath.Dir(existingPath), 0755); err != nil {
+				t.Fatalf("error creating run directory: %v", err)
+			}
+			if err := os.WriteFile(existingPath, []byte("original content"), 0644); err != nil {
+				t.Fatalf("error writing existing file: %v", err)
+			}
+
+			exporter := NewLocalExporter(runtimeInfo, directory)
+			if err := exporter.ExportReader("key1", strings.NewReader("new content")); err != nil {
+				t.Fatalf("ExportReader() error = %v", err)
+			}
+			if err := exporter.ExportReader("key2", strings.NewReader("added content")); err != nil {
+				t.Fatalf("ExportReader() error = %v", err)
+			}
+
+			content, err := os.ReadFile(existingPath)
+			if err != nil || string(content) != tt.want {
+				t.Errorf("unexpected existing file content '%s' (error %v), expected '%s'", content, err, tt.want)
+			}
+
+			content, err = os.ReadFile(filepath.Join(directory, "run1", "node1", "key2"))
+			if err != nil || string(content) != "added content" {
+				t.Errorf("unexpected added file content '%s' (error %v)", content, err)
+			}
+		})
+	}
+}
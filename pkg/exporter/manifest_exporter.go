@@ -0,0 +1,216 @@
+package exporter
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+const manifestFileName = "manifest.json"
+
+type Manifest struct {
+	RunId string          `json:"runId"`
+	Node  string          `json:"node"`
+	Files []ManifestEntry `json:"files"`
+}
+
+type ManifestEntry struct {
+	Key    string `json:"key"`
+	SHA256 string `json:"sha256"`
+	Length int64  `json:"length"`
+}
+
+// ManifestExporter wraps an Exporter, recording the SHA-256 checksum and length of everything exported through it,
+// so that downloads can be verified against the manifest. Checksums are computed as the wrapped exporter reads the
+// data, rather than reading it a second time.
+type ManifestExporter struct {
+	exporter    interfaces.Exporter
+	runtimeInfo *utils.RuntimeInfo
+	lock        sync.Mutex
+	entries     map[string]ManifestEntry
+}
+
+func NewManifestExporter(exporter interfaces.Exporter, runtimeInfo *utils.RuntimeInfo) *ManifestExporter {
+	return &ManifestExporter{
+		exporter:    exporter,
+		runtimeInfo: runtimeInfo,
+		entries:     map[string]ManifestEntry{},
+	}
+}
+
+// Export implements the interface method
+func (exporter *ManifestExporter) Export(producer interfaces.DataProducer) error {
+	return exporter.exporter.Export(&checksumDataProducer{producer: producer, manifest: exporter})
+}
+
+func (exporter *ManifestExporter) ExportReader(name string, reader io.ReadSeeker) error {
+	checksumReader := &checksumReadSeeker{reader: reader, hash: sha256.New()}
+	if err := exporter.exporter.ExportReader(name, checksumReader); err != nil {
+		return err
+	}
+
+	// If the exporter didn't read the content sequentially to the end, we don't know its checksum.
+	if checksumReader.complete && !checksumReader.invalid {
+		exporter.record(name, checksumReader.hash, checksumReader.length)
+	}
+
+	return nil
+}
+
+// ExportStream implements the interface method. The checksum is recorded if the content is read to the end.
+func (exporter *ManifestExporter) ExportStream(name string, reader io.Reader) error {
+	checksumReader := &checksumReadCloser{reader: io.NopCloser(reader), hash: sha256.New(), onComplete: func(h hash.Hash, length int64) {
+		exporter.record(name, h, length)
+	}}
+
+	return exportStream(exporter.exporter, name, checksumReader)
+}
+
+// ExportManifest exports the manifest of all the data exported so far.
+func (exporter *ManifestExporter) ExportManifest() error {
+	exporter.lock.Lock()
+	manifest := Manifest{
+		RunId: exporter.runtimeInfo.RunId,
+		Node:  exporter.runtimeInfo.HostNodeName,
+		Files: make([]ManifestEntry, 0, len(exporter.entries)),
+	}
+	for _, entry := range exporter.entries {
+		manifest.Files = append(manifest.Files, entry)
+	}
+	exporter.lock.Unlock()
+
+	sort.Slice(manifest.Files, func(i, j int) bool {
+		return manifest.Files[i].Key < manifest.Files[j].Key
+	})
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("marshall manifest to json: %w", err)
+	}
+
+	return exporter.exporter.ExportReader(manifestFileName, bytes.NewReader(data))
+}
+
+func (exporter *ManifestExporter) record(key string, h hash.Hash, length int64) {
+	exporter.lock.Lock()
+	defer exporter.lock.Unlock()
+
+	exporter.entries[key] = ManifestEntry{
+		Key:    key,
+		SHA256: hex.EncodeToString(h.Sum(nil)),
+		Length: length,
+	}
+}
+
+// checksumDataProducer wraps each value of a DataProducer so that it is recorded in the manifest when read.
+type checksumDataProducer struct {
+	producer interfaces.DataProducer
+	manifest *ManifestExporter
+}
+
+func (p *checksumDataProducer) GetName() string {
+	return p.producer.GetName()
+}
+
+func (p *checksumDataProducer) GetData() map[string]interfaces.DataValue {
+	data := p.producer.GetData()
+	result := make(map[string]interfaces.DataValue, len(data))
+	for key, value := range data {
+		result[key] = &checksumDataValue{key: key, value: value, manifest: p.manifest}
+	}
+
+	return result
+}
+
+type checksumDataValue struct {
+	key      string
+	value    interfaces.DataValue
+	manifest *ManifestExporter
+}
+
+func (v *checksumDataValue) GetLength() int64 {
+	return v.value.GetLength()
+}
+
+func (v *checksumDataValue) GetReader() (io.ReadCloser, error) {
+	reader, err := v.value.GetReader()
+	if err != nil {
+		return nil, err
+	}
+
+	return &checksumReadCloser{reader: reader, hash: sha256.New(), onComplete: func(h hash.Hash, length int64) {
+		v.manifest.record(v.key, h, length)
+	}}, nil
+}
+
+// checksumReadCloser hashes content as it is read, recording the result once the end of the content is reached.
+// A value may be read more than once (e.g. on retry), in which case the last complete read is recorded.
+type checksumReadCloser struct {
+	reader     io.ReadCloser
+	hash       hash.Hash
+	length     int64
+	complete   bool
+	onComplete func(h hash.Hash, length int64)
+}
+
+func (r *checksumReadCloser) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.hash.Write(p[:n])
+	r.length += int64(n)
+	if err == io.EOF && !r.complete {
+		r.complete = true
+		r.onComplete(r.hash, r.length)
+	}
+	return n, err
+}
+
+func (r *checksumReadCloser) Close() error {
+	return r.reader.Close()
+}
+
+// checksumReadSeeker hashes content as it is read. Rewinding to the start (e.g. to retry an upload) restarts the
+// checksum, but any other seek means the checksum can no longer be known.
+type checksumReadSeeker struct {
+	reader   io.ReadSeeker
+	hash     hash.Hash
+	length   int64
+	complete bool
+	invalid  bool
+}
+
+func (r *checksumReadSeeker) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.hash.Write(p[:n])
+	r.length += int64(n)
+	if err == io.EOF {
+		r.complete = true
+	}
+	return n, err
+}
+
+func (r *checksumReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	position, err := r.reader.Seek(offset, whence)
+	if err != nil {
+		return position, err
+	}
+
+	if position == 0 {
+		r.hash.Reset()
+		r.length = 0
+		r.complete = false
+		r.invalid = false
+	} else if position != r.length {
+		r.invalid = true
+	}
+
+	return position, nil
+}
@@ -0,0 +1,122 @@
+package exporter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+// seekingExporter reads part of the content, then seeks elsewhere, so the checksum of what it exported can't be known.
+type seekingExporter struct {
+	fakeTargetExporter
+}
+
+func (e *seekingExporter) ExportReader(name string, reader io.ReadSeeker) error {
+	if _, err := reader.Seek(2, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := io.ReadAll(reader)
+	return err
+}
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestManifestExporter(t *testing.T) {
+	directory := t.TempDir()
+	runtimeInfo := &utils.RuntimeInfo{
+		RunId:        "run1",
+		ExportRunId:  "run1",
+		HostNodeName: "node1",
+	}
+
+	exporter := NewManifestExporter(NewLocalExporter(runtimeInfo, directory), runtimeInfo)
+
+	producer := &testDataProducer{
+		name: "collector1",
+		data: map[string]interfaces.DataValue{
+			"key1":            utils.NewStringDataValue("value1"),
+			"datapath/nested": utils.NewStringDataValue("a longer value"),
+		},
+	}
+
+	if err := exporter.Export(producer); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if err := exporter.ExportReader("node1.zip", strings.NewReader("zip content")); err != nil {
+		t.Fatalf("ExportReader() error = %v", err)
+	}
+	if err := exporter.ExportManifest(); err != nil {
+		t.Fatalf("ExportManifest() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(directory, "run1", "node1", manifestFileName))
+	if err != nil {
+		t.Fatalf("error reading manifest: %v", err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		t.Fatalf("error parsing manifest: %v", err)
+	}
+
+	expected := Manifest{
+		RunId: "run1",
+		Node:  "node1",
+		Files: []ManifestEntry{
+			{Key: "datapath/nested", SHA256: sha256Hex("a longer value"), Length: 14},
+			{Key: "key1", SHA256: sha256Hex("value1"), Length: 6},
+			{Key: "node1.zip", SHA256: sha256Hex("zip content"), Length: 11},
+		},
+	}
+	if !reflect.DeepEqual(manifest, expected) {
+		t.Errorf("unexpected manifest:\nexpected %+v\nfound    %+v", expected, manifest)
+	}
+}
+
+func TestManifestExporterRetriedReader(t *testing.T) {
+	target := &fakeTargetExporter{readers: map[string]string{}}
+	exporter := NewManifestExporter(target, &utils.RuntimeInfo{})
+
+	// A partial read followed by a rewind, as when an upload is retried, still gives the checksum of the whole content.
+	reader := &checksumReadSeeker{reader: strings.NewReader("retried content"), hash: sha256.New()}
+	if _, err := reader.Read(make([]byte, 4)); err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("Seek() error = %v", err)
+	}
+	if _, err := io.ReadAll(reader); err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if !reader.complete || reader.invalid || reader.length != 15 || hex.EncodeToString(reader.hash.Sum(nil)) != sha256Hex("retried content") {
+		t.Errorf("unexpected checksum state after rewind: %+v", reader)
+	}
+
+	// Content that isn't read sequentially is left out of the manifest.
+	seeking := NewManifestExporter(&seekingExporter{}, &utils.RuntimeInfo{})
+	if err := seeking.ExportReader("partial", strings.NewReader("skipped content")); err != nil {
+		t.Fatalf("ExportReader() error = %v", err)
+	}
+	if len(seeking.entries) != 0 {
+		t.Errorf("expected no manifest entries, found %v", seeking.entries)
+	}
+
+	if err := exporter.ExportReader("whole", strings.NewReader("whole content")); err != nil {
+		t.Fatalf("ExportReader() error = %v", err)
+	}
+	if entry := exporter.entries["whole"]; entry.SHA256 != sha256Hex("whole content") || target.readers["whole"] != "whole content" {
+		t.Errorf("unexpected manifest entry %+v", entry)
+	}
+}
@@ -0,0 +1,66 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/hashicorp/go-multierror"
+)
+
+// MultiExporter defines an exporter which exports the same data to several destinations
+type MultiExporter struct {
+	exporters []interfaces.Exporter
+}
+
+func NewMultiExporter(exporters ...interfaces.Exporter) *MultiExporter {
+	return &MultiExporter{
+		exporters: exporters,
+	}
+}
+
+// Export implements the interface method. Every exporter is attempted, even if an earlier one fails.
+func (exporter *MultiExporter) Export(producer interfaces.DataProducer) error {
+	var errs error
+	for i, e := range exporter.exporters {
+		if err := e.Export(producer); err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("exporter %d (%T): %w", i, e, err))
+		}
+	}
+
+	return errs
+}
+
+// ExportReader implements the interface method. The reader is rewound to its starting position for each exporter.
+func (exporter *MultiExporter) ExportReader(name string, reader io.ReadSeeker) error {
+	start, err := reader.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("get position of %s: %w", name, err)
+	}
+
+	var errs error
+	for i, e := range exporter.exporters {
+		if _, err := reader.Seek(start, io.SeekStart); err != nil {
+			return multierror.Append(errs, fmt.Errorf("rewind %s: %w", name, err))
+		}
+
+		if err := e.ExportReader(name, reader); err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("exporter %d (%T): %w", i, e, err))
+		}
+	}
+
+	return errs
+}
+
+// ExportStream implements the interface method. A stream can only be read once, so unless there is a single exporter
+// it is spooled to a temporary file which is then exported to each.
+func (exporter *MultiExporter) ExportStream(name string, reader io.Reader) error {
+	if len(exporter.exporters) == 1 {
+		if err := exportStream(exporter.exporters[0], name, reader); err != nil {
+			return fmt.Errorf("exporter 0 (%T): %w", exporter.exporters[0], err)
+		}
+		return nil
+	}
+
+	return spoolStream(name, reader, exporter.ExportReader)
+}
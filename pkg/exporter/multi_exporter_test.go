@@ -0,0 +1,78 @@
+package exporter
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+type fakeTargetExporter struct {
+	err       error
+	producers []string
+	readers   map[string]string
+}
+
+func (e *fakeTargetExporter) Export(producer interfaces.DataProducer) error {
+	e.producers = append(e.producers, producer.GetName())
+	return e.err
+}
+
+func (e *fakeTargetExporter) ExportReader(name string, reader io.ReadSeeker) error {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	e.readers[name] = string(content)
+	return e.err
+}
+
+func TestMultiExporter(t *testing.T) {
+	failing := &fakeTargetExporter{err: errors.New("destination unavailable"), readers: map[string]string{}}
+	first := &fakeTargetExporter{readers: map[string]string{}}
+	second := &fakeTargetExporter{readers: map[string]string{}}
+
+	exporter := NewMultiExporter(first, failing, second)
+
+	producer := &testDataProducer{
+		name: "collector1",
+		data: map[string]interfaces.DataValue{"key1": utils.NewStringDataValue("value1")},
+	}
+
+	err := exporter.Export(producer)
+	if err == nil || !strings.Contains(err.Error(), "destination unavailable") {
+		t.Errorf("expected aggregated error from failing exporter, found %v", err)
+	}
+
+	err = exporter.ExportReader("node1.zip", strings.NewReader("zip content"))
+	if err == nil || !strings.Contains(err.Error(), "destination unavailable") {
+		t.Errorf("expected aggregated error from failing exporter, found %v", err)
+	}
+
+	// The failing exporter must not prevent the others receiving the data.
+	for i, target := range []*fakeTargetExporter{first, failing, second} {
+		if !equalStrings(target.producers, []string{"collector1"}) {
+			t.Errorf("exporter %d: unexpected producers %v", i, target.producers)
+		}
+		if target.readers["node1.zip"] != "zip content" {
+			t.Errorf("exporter %d: unexpected reader content '%s'", i, target.readers["node1.zip"])
+		}
+	}
+}
+
+func TestMultiExporterNoErrors(t *testing.T) {
+	first := &fakeTargetExporter{readers: map[string]string{}}
+	second := &fakeTargetExporter{readers: map[string]string{}}
+
+	exporter := NewMultiExporter(first, second)
+
+	if err := exporter.Export(&testDataProducer{name: "collector1"}); err != nil {
+		t.Errorf("Export() error = %v", err)
+	}
+	if err := exporter.ExportReader("node1.zip", strings.NewReader("zip content")); err != nil {
+		t.Errorf("ExportReader() error = %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+package exporter
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+const procMountInfoPath = "/proc/self/mountinfo"
+
+// PVCExporter defines an exporter which writes data to a PersistentVolumeClaim mounted into the Periscope pod,
+// using the same <run ID>/<node>/<key> layout as the local exporter. Unlike a local directory, the volume may be
+// missing or mounted read-only on some nodes, so the mount is checked before the first write.
+type PVCExporter struct {
+	runtimeInfo   *utils.RuntimeInfo
+	path          string
+	mountInfoPath string
+	local         *LocalExporter
+	checkOnce     sync.Once
+	checkErr      error
+}
+
+func NewPVCExporter(runtimeInfo *utils.RuntimeInfo, path string) *PVCExporter {
+	return &PVCExporter{
+		runtimeInfo:   runtimeInfo,
+		path:          path,
+		mountInfoPath: procMountInfoPath,
+		local:         NewLocalExporter(runtimeInfo, path),
+	}
+}
+
+// Export implements the interface method
+func (exporter *PVCExporter) Export(producer interfaces.DataProducer) error {
+	if err := exporter.checkMount(); err != nil {
+		return err
+	}
+
+	return exporter.local.Export(producer)
+}
+
+func (exporter *PVCExporter) ExportReader(name string, reader io.ReadSeeker) error {
+	if err := exporter.checkMount(); err != nil {
+		return err
+	}
+
+	return exporter.local.ExportReader(name, reader)
+}
+
+// ExportStream implements the interface method
+func (exporter *PVCExporter) ExportStream(name string, reader io.Reader) error {
+	if err := exporter.checkMount(); err != nil {
+		return err
+	}
+
+	return exporter.local.ExportStream(name, reader)
+}
+
+// checkMount verifies that the PVC is mounted and writable, and creates the run and node directories on it.
+// The result is remembered, so that an unusable volume is reported consistently for every export.
+func (exporter *PVCExporter) checkMount() error {
+	exporter.checkOnce.Do(func() {
+		exporter.checkErr = exporter.verifyMount()
+	})
+
+	return exporter.checkErr
+}
+
+func (exporter *PVCExporter) verifyMount() error {
+	node := exporter.runtimeInfo.HostNodeName
+
+	info, err := os.Stat(exporter.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("PVC path %s does not exist on node %s: is the volume mounted?", exporter.path, node)
+		}
+		return fmt.Errorf("check PVC path %s: %w", exporter.path, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("PVC path %s on node %s is not a directory", exporter.path, node)
+	}
+
+	// Without procfs (e.g. on Windows) the mount can't be inspected, and only the write check below applies.
+	options, mounted, err := getMountOptions(exporter.mountInfoPath, exporter.path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("read mounts: %w", err)
+	}
+	if err == nil {
+		// Writing to an unmounted path would succeed, but the data would be lost with the container.
+		if !mounted {
+			return fmt.Errorf("PVC path %s is not a mount point on node %s: is the volume mounted?", exporter.path, node)
+		}
+		if utils.Contains(options, "ro") {
+			return fmt.Errorf("PVC at %s is mounted read-only on node %s", exporter.path, node)
+		}
+	}
+
+	root := filepath.Join(exporter.path, exporter.runtimeInfo.ExportRunId, node)
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return describeWriteError(exporter.path, node, err)
+	}
+
+	file, err := os.CreateTemp(root, ".write-check-*")
+	if err != nil {
+		return describeWriteError(exporter.path, node, err)
+	}
+	file.Close()
+
+	return os.Remove(file.Name())
+}
+
+func describeWriteError(path, node string, err error) error {
+	if errors.Is(err, syscall.EROFS) {
+		return fmt.Errorf("PVC at %s is mounted read-only on node %s: %w", path, node, err)
+	}
+
+	return fmt.Errorf("PVC at %s is not writable on node %s: %w", path, node, err)
+}
+
+// getMountOptions looks up the per-mount options of the mount point at the given path in a mountinfo file. See:
+// https://man7.org/linux/man-pages/man5/proc.5.html (/proc/pid/mountinfo)
+func getMountOptions(mountInfoPath, path string) ([]string, bool, error) {
+	file, err := os.Open(mountInfoPath)
+	if err != nil {
+		return nil, false, err
+	}
+	defer file.Close()
+
+	path = filepath.Clean(path)
+
+	// Later entries are mounted over earlier ones, so the last match is the one in effect.
+	var options []string
+	mounted := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// e.g. "1045 1030 0:52 / /output rw,relatime - nfs4 server:/share rw,vers=4.1"
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 {
+			continue
+		}
+
+		// Spaces and other special characters in the mount point are octal-escaped (e.g. "\040").
+		if filepath.Clean(unescapeMountPoint(fields[4])) == path {
+			options = strings.Split(fields[5], ",")
+			mounted = true
+		}
+	}
+
+	return options, mounted, scanner.Err()
+}
+
+func unescapeMountPoint(mountPoint string) string {
+	return strings.NewReplacer(`\040`, " ", `\011`, "\t", `\012`, "\n", `\134`, `\`).Replace(mountPoint)
+}
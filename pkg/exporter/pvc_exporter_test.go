@@ -0,0 +1,147 @@
+package exporter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+func TestPVCExporter(t *testing.T) {
+	runtimeInfo := &utils.RuntimeInfo{
+		RunId:        "run1",
+		ExportRunId:  "run1",
+		HostNodeName: "node1",
+	}
+
+	producer := &testDataProducer{
+		name: "collector1",
+		data: map[string]interfaces.DataValue{
+			"key1": utils.NewStringDataValue("value1"),
+		},
+	}
+
+	tests := []struct {
+		name       string
+		mountInfo  func(path string) string
+		createPath bool
+		wantErr    string
+	}{
+		{
+			name: "mounted read-write",
+			mountInfo: func(path string) string {
+				return fmt.Sprintf("22 1 8:1 / / rw,relatime - ext4 /dev/sda1 rw\n1045 22 0:52 / %s rw,relatime - nfs4 server:/share rw\n", path)
+			},
+			createPath: true,
+		},
+		{
+			name: "mounted read-only",
+			mountInfo: func(path string) string {
+				return fmt.Sprintf("22 1 8:1 / / rw,relatime - ext4 /dev/sda1 rw\n1045 22 0:52 / %s ro,relatime - nfs4 server:/share rw\n", path)
+			},
+			createPath: true,
+			wantErr:    "mounted read-only on node node1",
+		},
+		{
+			name: "remounted read-only over read-write",
+			mountInfo: func(path string) string {
+				return fmt.Sprintf("1045 22 0:52 / %s rw,relatime - nfs4 server:/share rw\n1046 22 0:53 / %s ro,relatime - nfs4 server:/share rw\n", path, path)
+			},
+			createPath: true,
+			wantErr:    "mounted read-only on node node1",
+		},
+		{
+			name: "not a mount point",
+			mountInfo: func(path string) string {
+				return "22 1 8:1 / / rw,relatime - ext4 /dev/sda1 rw\n"
+			},
+			createPath: true,
+			wantErr:    "is not a mount point on node node1",
+		},
+		{
+			name: "path missing",
+			mountInfo: func(path string) string {
+				return ""
+			},
+			createPath: false,
+			wantErr:    "does not exist on node node1",
+		},
+		{
+			name:       "mounts unavailable",
+			mountInfo:  nil,
+			createPath: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			directory := t.TempDir()
+			path := filepath.Join(directory, "pvc")
+			if tt.createPath {
+				if err := os.Mkdir(path, 0755); err != nil {
+					t.Fatalf("error creating PVC directory: %v", err)
+				}
+			}
+
+			exporter := NewPVCExporter(runtimeInfo, path)
+			exporter.mountInfoPath = filepath.Join(directory, "mountinfo")
+			if tt.mountInfo != nil {
+				if err := os.WriteFile(exporter.mountInfoPath, []byte(tt.mountInfo(path)), 0644); err != nil {
+					t.Fatalf("error writing mountinfo: %v", err)
+				}
+			}
+
+			err := exporter.Export(producer)
+			readerErr := exporter.ExportReader("node1.zip", strings.NewReader("zip content"))
+			if len(tt.wantErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Export() error = %v, want error containing '%s'", err, tt.wantErr)
+				}
+				if readerErr == nil || readerErr.Error() != err.Error() {
+					t.Errorf("ExportReader() error = %v, want %v", readerErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Export() error = %v", err)
+			}
+			if readerErr != nil {
+				t.Fatalf("ExportReader() error = %v", readerErr)
+			}
+
+			root := filepath.Join(path, "run1", "node1")
+			entries, err := os.ReadDir(root)
+			if err != nil {
+				t.Fatalf("error reading %s: %v", root, err)
+			}
+			names := []string{}
+			for _, entry := range entries {
+				names = append(names, entry.Name())
+			}
+			if !equalStrings(names, []string{"key1", "node1.zip"}) {
+				t.Errorf("unexpected files %v", names)
+			}
+		})
+	}
+}
+
+func TestGetMountOptions(t *testing.T) {
+	mountInfo := filepath.Join(t.TempDir(), "mountinfo")
+	content := "1045 22 0:52 / /mnt/my\\040diagnostics rw,noatime - nfs4 server:/share rw\n"
+	if err := os.WriteFile(mountInfo, []byte(content), 0644); err != nil {
+		t.Fatalf("error writing mountinfo: %v", err)
+	}
+
+	options, mounted, err := getMountOptions(mountInfo, "/mnt/my diagnostics/")
+	if err != nil {
+		t.Fatalf("getMountOptions() error = %v", err)
+	}
+	if !mounted || !equalStrings(options, []string{"rw", "noatime"}) {
+		t.Errorf("unexpected mount: %t %v", mounted, options)
+	}
+}
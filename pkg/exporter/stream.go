@@ -0,0 +1,44 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+)
+
+// exportStream exports content of unknown length, in a single pass if the exporter supports it. Otherwise the content
+// is spooled to a temporary file, so that the exporter can rewind it (e.g. to retry) without holding it in memory.
+func exportStream(exporter interfaces.Exporter, name string, reader io.Reader) error {
+	if streamExporter, ok := exporter.(interfaces.StreamExporter); ok {
+		return streamExporter.ExportStream(name, reader)
+	}
+
+	return spoolStream(name, reader, exporter.ExportReader)
+}
+
+// spoolStream copies the content to a temporary file, and passes the file, rewound to the start, to the export function.
+func spoolStream(name string, reader io.Reader, export func(name string, reader io.ReadSeeker) error) error {
+	file, err := os.CreateTemp("", "periscope-stream-*")
+	if err != nil {
+		return fmt.Errorf("create temp file for %s: %w", name, err)
+	}
+	defer func() {
+		file.Close()
+		if err := os.Remove(file.Name()); err != nil {
+			log.Printf("Could not remove temp file %s: %v", file.Name(), err)
+		}
+	}()
+
+	if _, err := io.Copy(file, reader); err != nil {
+		return fmt.Errorf("write temp file for %s: %w", name, err)
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("rewind temp file for %s: %w", name, err)
+	}
+
+	return export(name, file)
+}
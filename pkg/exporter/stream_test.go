@@ -0,0 +1,59 @@
+package exporter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+func TestExportStream(t *testing.T) {
+	first := &fakeTargetExporter{readers: map[string]string{}}
+	second := &fakeTargetExporter{readers: map[string]string{}}
+
+	// Exporters which can't stream get the content from a temporary file.
+	if err := exportStream(first, "single", strings.NewReader("single content")); err != nil {
+		t.Fatalf("exportStream() error = %v", err)
+	}
+	if first.readers["single"] != "single content" {
+		t.Errorf("unexpected content '%s'", first.readers["single"])
+	}
+
+	// A stream can only be read once, so it is spooled before being exported to several exporters.
+	var multi interfaces.StreamExporter = NewMultiExporter(first, second)
+	if err := multi.ExportStream("multi", strings.NewReader("multi content")); err != nil {
+		t.Fatalf("ExportStream() error = %v", err)
+	}
+	for i, target := range []*fakeTargetExporter{first, second} {
+		if target.readers["multi"] != "multi content" {
+			t.Errorf("exporter %d: unexpected content '%s'", i, target.readers["multi"])
+		}
+	}
+}
+
+func TestManifestExporterStream(t *testing.T) {
+	directory := t.TempDir()
+	runtimeInfo := &utils.RuntimeInfo{
+		RunId:        "run1",
+		ExportRunId:  "run1",
+		HostNodeName: "node1",
+	}
+
+	exporter := NewManifestExporter(NewLocalExporter(runtimeInfo, directory), runtimeInfo)
+	if err := exporter.ExportStream("logs", strings.NewReader("streamed content")); err != nil {
+		t.Fatalf("ExportStream() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(directory, "run1", "node1", "logs"))
+	if err != nil || string(content) != "streamed content" {
+		t.Errorf("unexpected content '%s' (error %v)", content, err)
+	}
+
+	entry, ok := exporter.entries["logs"]
+	if !ok || entry.SHA256 != sha256Hex("streamed content") || entry.Length != 16 {
+		t.Errorf("unexpected manifest entry %+v", entry)
+	}
+}
@@ -2,21 +2,35 @@ package exporter
 
 import (
 	"archive/zip"
-	"bytes"
 	"io"
 	"log"
+	"sort"
 
 	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
 )
 
-func Zip(data []interfaces.DataProducer) (*bytes.Buffer, error) {
-	buffer := new(bytes.Buffer)
-	z := zip.NewWriter(buffer)
-	defer z.Close()
+// Zip writes the data of all the producers to the writer as a zip archive, with entries named by producer and key.
+func Zip(w io.Writer, data []interfaces.DataProducer) error {
+	z := zip.NewWriter(w)
+
+	// Producers complete in any order, so they're sorted to give the archive a deterministic layout.
+	producers := make([]interfaces.DataProducer, len(data))
+	copy(producers, data)
+	sort.SliceStable(producers, func(i, j int) bool {
+		return producers[i].GetName() < producers[j].GetName()
+	})
+
+	for _, prd := range producers {
+		values := prd.GetData()
+		for _, name := range utils.SortedKeys(values) {
+			value := values[name]
+			key := prd.GetName() + utils.DataKeySeparator + name
+			if _, err := utils.SplitDataKey(key); err != nil {
+				log.Printf("Error creating zip entry: %v", err)
+				continue
+			}
 
-	for _, prd := range data {
-		for name, value := range prd.GetData() {
-			key := prd.GetName() + "/" + name
 			dataf, err := z.Create(key)
 			if err != nil {
 				// If there's an error creating one value, log the error and continue.
@@ -46,7 +60,5 @@ func Zip(data []interfaces.DataProducer) (*bytes.Buffer, error) {
 		}
 	}
 
-	z.Flush()
-
-	return buffer, nil
+	return z.Close()
 }
@@ -0,0 +1,59 @@
+package exporter
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/utils"
+)
+
+func TestZipOrdering(t *testing.T) {
+	producers := []interfaces.DataProducer{
+		&testDataProducer{name: "kubeobjects", data: utils.ToDataValueMap(map[string]string{
+			"kube-system_service": "services",
+			"kube-system_pod":     "pods",
+		})},
+		&testDataProducer{name: "dns", data: utils.ToDataValueMap(map[string]string{
+			"virtualmachine": "vm",
+			"kubelet":        "kubelet",
+			"coredns":        "coredns",
+		})},
+	}
+
+	expectedNames := []string{
+		"dns/coredns",
+		"dns/kubelet",
+		"dns/virtualmachine",
+		"kubeobjects/kube-system_pod",
+		"kubeobjects/kube-system_service",
+	}
+
+	// Map iteration order is random, so repeat to make an accidentally matching order unlikely.
+	for i := 0; i < 10; i++ {
+		buffer := new(bytes.Buffer)
+		if err := Zip(buffer, producers); err != nil {
+			t.Fatalf("Zip() error = %v", err)
+		}
+
+		content := buffer.Bytes()
+		zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+		if err != nil {
+			t.Fatalf("error reading zip content: %v", err)
+		}
+
+		names := []string{}
+		for _, file := range zr.File {
+			names = append(names, file.Name)
+		}
+
+		if !equalStrings(names, expectedNames) {
+			t.Fatalf("unexpected zip entry order: expected %v, found %v", expectedNames, names)
+		}
+	}
+
+	if producers[0].GetName() != "kubeobjects" {
+		t.Errorf("expected the producers passed to Zip to be left in their original order")
+	}
+}
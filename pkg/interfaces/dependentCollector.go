@@ -0,0 +1,10 @@
+package interfaces
+
+// DependentCollector is implemented by collectors which use the output of other collectors, named as dependencies
+// when they are registered. Before Collect is called, SetDependencyData is called with the data of each dependency
+// which collected successfully. Dependencies which are unsupported, fail or time out are not passed in.
+type DependentCollector interface {
+	Collector
+
+	SetDependencyData(name string, data map[string]DataValue)
+}
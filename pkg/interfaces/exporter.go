@@ -1,6 +1,15 @@
 package interfaces
 
+import "io"
+
 // Exporter defines interface for an exporter
 type Exporter interface {
 	Export(DataProducer) error
+	ExportReader(name string, reader io.ReadSeeker) error
+}
+
+// StreamExporter is an Exporter which can export content in a single pass, without knowing its length in advance.
+type StreamExporter interface {
+	Exporter
+	ExportStream(name string, reader io.Reader) error
 }
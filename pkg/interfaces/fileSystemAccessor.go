@@ -7,4 +7,11 @@ type FileSystemAccessor interface {
 	FileExists(filePath string) (bool, error)
 	GetFileSize(filePath string) (int64, error)
 	ListFiles(directoryPath string) ([]string, error)
+	// ListDirectory returns the names of the entries of a directory, without descending into subdirectories.
+	ListDirectory(directoryPath string) ([]string, error)
+	// ReadLink returns the target of a symbolic link.
+	ReadLink(linkPath string) (string, error)
+	// GetDirectoryUsage returns the total size of the files under a directory, visiting at most maxEntries entries,
+	// and whether all of them were visited.
+	GetDirectoryUsage(directoryPath string, maxEntries int) (int64, bool, error)
 }
@@ -0,0 +1,17 @@
+package interfaces
+
+import "io"
+
+// DataStreamHandler consumes the content of a data key as it is produced. The reader is only valid until the
+// handler returns, and the handler may return without reading it (e.g. if the key is excluded from export).
+type DataStreamHandler func(key string, reader io.Reader) error
+
+// StreamingCollector is a Collector which can hand its data to the orchestrator as it is produced, so that large
+// output can be exported with bounded memory rather than held until collection is complete.
+type StreamingCollector interface {
+	Collector
+
+	// CollectStreams is called instead of Collect when the data can be exported as it is produced. Data passed to
+	// the handler is not returned by GetData.
+	CollectStreams(handler DataStreamHandler) error
+}
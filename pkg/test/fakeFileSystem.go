@@ -3,6 +3,7 @@ package test
 import (
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -11,6 +12,7 @@ import (
 // access the file system.
 type FakeFileSystem struct {
 	lookup     map[string]string
+	links      map[string]string
 	errorFiles map[string]error
 	lock       sync.RWMutex
 }
@@ -20,6 +22,7 @@ type FakeFileSystem struct {
 func NewFakeFileSystem(lookup map[string]string) *FakeFileSystem {
 	return &FakeFileSystem{
 		lookup:     lookup,
+		links:      map[string]string{},
 		errorFiles: map[string]error{},
 		lock:       sync.RWMutex{},
 	}
@@ -85,6 +88,88 @@ func (ffs *FakeFileSystem) ListFiles(directoryPath string) ([]string, error) {
 	return files, nil
 }
 
+// GetDirectoryUsage implements the FileSystemAccessor interface. Only files count as entries, since the fake file
+// system has no directories.
+func (ffs *FakeFileSystem) GetDirectoryUsage(directoryPath string, maxEntries int) (int64, bool, error) {
+	ffs.lock.RLock()
+	defer ffs.lock.RUnlock()
+
+	if err := ffs.getError(directoryPath); err != nil {
+		return 0, false, err
+	}
+
+	paths := []string{}
+	for path := range ffs.lookup {
+		if strings.HasPrefix(path, directoryPath+"/") {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	var size int64
+	for i, path := range paths {
+		if i == maxEntries {
+			return size, false, nil
+		}
+		size += int64(len(ffs.lookup[path]))
+	}
+	return size, true, nil
+}
+
+// ListDirectory implements the FileSystemAccessor interface. The fake file system has no directories, so a directory
+// exists only if there are files or links under it.
+func (ffs *FakeFileSystem) ListDirectory(directoryPath string) ([]string, error) {
+	ffs.lock.RLock()
+	defer ffs.lock.RUnlock()
+
+	if err := ffs.getError(directoryPath); err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for _, paths := range []map[string]string{ffs.lookup, ffs.links} {
+		for path := range paths {
+			if strings.HasPrefix(path, directoryPath+"/") {
+				name, _, _ := strings.Cut(strings.TrimPrefix(path, directoryPath+"/"), "/")
+				names[name] = true
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("directory not found: %s", directoryPath)
+	}
+
+	result := make([]string, 0, len(names))
+	for name := range names {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// ReadLink implements the FileSystemAccessor interface
+func (ffs *FakeFileSystem) ReadLink(path string) (string, error) {
+	ffs.lock.RLock()
+	defer ffs.lock.RUnlock()
+
+	if err := ffs.getError(path); err != nil {
+		return "", err
+	}
+	target, ok := ffs.links[path]
+	if !ok {
+		return "", fmt.Errorf("link not found: %s", path)
+	}
+	return target, nil
+}
+
+// AddOrUpdateLink adds a symbolic link, which is listed in its directory but can't be read as a file.
+func (ffs *FakeFileSystem) AddOrUpdateLink(path, target string) {
+	ffs.lock.Lock()
+	defer ffs.lock.Unlock()
+
+	ffs.links[path] = target
+}
+
 func (ffs *FakeFileSystem) SetFileAccessError(path string, err error) {
 	ffs.lock.Lock()
 	defer ffs.lock.Unlock()
@@ -31,7 +31,11 @@ namespace: %s
 resources:
 - cluster-role.yaml
 - cluster-role-binding.yaml
+- role.yaml
+- role-binding.yaml
 - service-account.yaml
+transformers:
+- kube-system-rbac
 EOF
 }`, saNamespace),
 		"kubectl apply -k /deployment/base",
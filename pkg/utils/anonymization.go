@@ -0,0 +1,301 @@
+package utils
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// The kinds of name which are anonymized, used as the prefix of their anonymized form.
+const (
+	AnonymizedNode      = "node"
+	AnonymizedNamespace = "namespace"
+	AnonymizedPod       = "pod"
+)
+
+// The number of hex digits of the hash in an anonymized name. Collisions are unlikely among the names of a cluster.
+const anonymizedHashLength = 12
+
+// The namespaces which are the same in every cluster, so their names, and those of their pods, reveal nothing.
+var unanonymizedNamespaces = []string{metav1.NamespaceDefault, metav1.NamespaceSystem, metav1.NamespacePublic, corev1.NamespaceNodeLease}
+
+// Anonymizer replaces the names of nodes, namespaces and pods with a hash of the name, keyed with a salt. The same
+// salt gives the same anonymized names on every node and in every collector, so data can still be correlated.
+//
+// Names are matched as whole words, delimited by anything other than letters, digits and hyphens, and regardless
+// of case. They are not replaced where they are used as a JSON object key or a label key (e.g. `"app":` or `app=`),
+// since names which are also common words (e.g. a namespace named "app") would otherwise corrupt field names and
+// labels. Names used as keys of JSON maps are left unchanged for the same reason.
+type Anonymizer struct {
+	salt  []byte
+	lock  sync.RWMutex
+	names map[string]string
+	// mapping holds the original name of each anonymized name, for those who may reverse the anonymization.
+	mapping map[string]string
+}
+
+// NewAnonymizer creates an Anonymizer, with no names to anonymize until they are added.
+func NewAnonymizer(salt []byte) *Anonymizer {
+	return &Anonymizer{
+		salt:    salt,
+		names:   map[string]string{},
+		mapping: map[string]string{},
+	}
+}
+
+// AddName adds a name of the given kind to be anonymized. A name which has already been added keeps its first kind.
+func (a *Anonymizer) AddName(kind, name string) {
+	key := strings.ToLower(name)
+	if len(key) == 0 {
+		return
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if _, ok := a.names[key]; ok {
+		return
+	}
+
+	mac := hmac.New(sha256.New, a.salt)
+	mac.Write([]byte(kind + "/" + key))
+	anonymized := fmt.Sprintf("%s-%s", kind, hex.EncodeToString(mac.Sum(nil))[:anonymizedHashLength])
+
+	a.names[key] = anonymized
+	a.mapping[anonymized] = name
+}
+
+// GetMapping returns the original name of each anonymized name.
+func (a *Anonymizer) GetMapping() map[string]string {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	mapping := make(map[string]string, len(a.mapping))
+	for anonymized, name := range a.mapping {
+		mapping[anonymized] = name
+	}
+	return mapping
+}
+
+func (a *Anonymizer) Anonymize(content string) string {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	var result strings.Builder
+	written := 0
+	for start := 0; start < len(content); {
+		if !isNameByte(content[start]) {
+			start++
+			continue
+		}
+
+		end := start
+		for end < len(content) && isNameByte(content[end]) {
+			end++
+		}
+
+		if anonymized, ok := a.names[strings.ToLower(content[start:end])]; ok && !isKey(content, start, end) {
+			result.WriteString(content[written:start])
+			result.WriteString(anonymized)
+			written = end
+		}
+		start = end
+	}
+
+	if written == 0 {
+		return content
+	}
+	result.WriteString(content[written:])
+	return result.String()
+}
+
+func isNameByte(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9') || b == '-'
+}
+
+// isKey returns whether the word at content[start:end] is a JSON object key (quoted, and followed by a colon) or a
+// label key (followed by '=', as in selectors and `kubectl describe` output).
+func isKey(content string, start, end int) bool {
+	if end < len(content) && content[end] == '=' {
+		return true
+	}
+
+	if start == 0 || content[start-1] != '"' || end >= len(content) || content[end] != '"' {
+		return false
+	}
+	rest := strings.TrimLeft(content[end+1:], " \t")
+	return strings.HasPrefix(rest, ":")
+}
+
+// AddClusterNames adds the names of the nodes, namespaces and pods of the cluster to be anonymized, other than those
+// of namespaces which exist in every cluster. Pods created after this are not anonymized.
+func AddClusterNames(ctx context.Context, clientset kubernetes.Interface, anonymizer *Anonymizer) error {
+	err := ListAllPages(metav1.ListOptions{}, func(listOptions metav1.ListOptions) (string, error) {
+		nodes, err := clientset.CoreV1().Nodes().List(ctx, listOptions)
+		if err != nil {
+			return "", err
+		}
+		for _, node := range nodes.Items {
+			anonymizer.AddName(AnonymizedNode, node.Name)
+		}
+
+		return nodes.Continue, nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list nodes: %w", err)
+	}
+
+	err = ListAllPages(metav1.ListOptions{}, func(listOptions metav1.ListOptions) (string, error) {
+		namespaces, err := clientset.CoreV1().Namespaces().List(ctx, listOptions)
+		if err != nil {
+			return "", err
+		}
+		for _, namespace := range namespaces.Items {
+			if !Contains(unanonymizedNamespaces, namespace.Name) {
+				anonymizer.AddName(AnonymizedNamespace, namespace.Name)
+			}
+		}
+
+		return namespaces.Continue, nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list namespaces: %w", err)
+	}
+
+	err = ListAllPages(metav1.ListOptions{}, func(listOptions metav1.ListOptions) (string, error) {
+		pods, err := clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, listOptions)
+		if err != nil {
+			return "", err
+		}
+		for _, pod := range pods.Items {
+			if !Contains(unanonymizedNamespaces, pod.Namespace) {
+				anonymizer.AddName(AnonymizedPod, pod.Name)
+			}
+		}
+
+		return pods.Continue, nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list pods: %w", err)
+	}
+
+	return nil
+}
+
+// AnonymizingDataValue wraps a DataValue, anonymizing names in its content. Names don't span lines, so the content is
+// anonymized a line at a time as it is read, rather than being read into memory.
+type AnonymizingDataValue struct {
+	value      interfaces.DataValue
+	anonymizer *Anonymizer
+	once       sync.Once
+	length     int64
+}
+
+func NewAnonymizingDataValue(value interfaces.DataValue, anonymizer *Anonymizer) *AnonymizingDataValue {
+	return &AnonymizingDataValue{
+		value:      value,
+		anonymizer: anonymizer,
+	}
+}
+
+// GetLength reads through the anonymized content (once) to find its length, since anonymized names may differ in
+// length from the originals.
+func (v *AnonymizingDataValue) GetLength() int64 {
+	v.once.Do(func() {
+		reader, err := v.GetReader()
+		if err != nil {
+			// The error will be surfaced by GetReader.
+			return
+		}
+		defer reader.Close()
+
+		v.length, _ = io.Copy(io.Discard, reader)
+	})
+	return v.length
+}
+
+func (v *AnonymizingDataValue) GetReader() (io.ReadCloser, error) {
+	reader, err := v.value.GetReader()
+	if err != nil {
+		return nil, err
+	}
+
+	return &anonymizingReadCloser{
+		source:     bufio.NewReader(reader),
+		closer:     reader,
+		anonymizer: v.anonymizer,
+	}, nil
+}
+
+// anonymizingReadCloser anonymizes the content of the source a line at a time.
+type anonymizingReadCloser struct {
+	source     *bufio.Reader
+	closer     io.Closer
+	anonymizer *Anonymizer
+	pending    string
+	err        error
+}
+
+func (r *anonymizingReadCloser) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		var line string
+		line, r.err = r.source.ReadString('\n')
+		r.pending = r.anonymizer.Anonymize(line)
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+func (r *anonymizingReadCloser) Close() error {
+	return r.closer.Close()
+}
+
+// AnonymizingDataProducer wraps a DataProducer, anonymizing names in the key and content of each of its data values.
+type AnonymizingDataProducer struct {
+	producer   interfaces.DataProducer
+	anonymizer *Anonymizer
+}
+
+// NewAnonymizingDataProducer creates a DataProducer whose keys and values have names anonymized.
+// A nil anonymizer means the data is returned unchanged.
+func NewAnonymizingDataProducer(producer interfaces.DataProducer, anonymizer *Anonymizer) *AnonymizingDataProducer {
+	return &AnonymizingDataProducer{
+		producer:   producer,
+		anonymizer: anonymizer,
+	}
+}
+
+func (p *AnonymizingDataProducer) GetName() string {
+	return p.producer.GetName()
+}
+
+func (p *AnonymizingDataProducer) GetData() map[string]interfaces.DataValue {
+	data := p.producer.GetData()
+	if p.anonymizer == nil {
+		return data
+	}
+
+	result := make(map[string]interfaces.DataValue, len(data))
+	for key, value := range data {
+		result[p.anonymizer.Anonymize(key)] = NewAnonymizingDataValue(value, p.anonymizer)
+	}
+
+	return result
+}
@@ -0,0 +1,208 @@
+package utils
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAnonymizerAnonymize(t *testing.T) {
+	anonymizer := NewAnonymizer([]byte("salt"))
+	anonymizer.AddName(AnonymizedNode, "aks-nodepool1-12345678-vmss000000")
+	anonymizer.AddName(AnonymizedNamespace, "payments")
+	anonymizer.AddName(AnonymizedPod, "api-7d9f8b6c5-x2x4z")
+	anonymizer.AddName(AnonymizedPod, "payments")
+	anonymizer.AddName(AnonymizedNamespace, "app")
+
+	node := anonymizer.Anonymize("aks-nodepool1-12345678-vmss000000")
+	namespace := anonymizer.Anonymize("payments")
+	pod := anonymizer.Anonymize("api-7d9f8b6c5-x2x4z")
+	app := anonymizer.Anonymize("app")
+	if !strings.HasPrefix(node, "node-") || !strings.HasPrefix(namespace, "namespace-") || !strings.HasPrefix(pod, "pod-") {
+		t.Fatalf("unexpected anonymized names %s, %s, %s", node, namespace, pod)
+	}
+	if len(node) != len("node-")+anonymizedHashLength {
+		t.Errorf("unexpected anonymized node name %s", node)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "log directory",
+			content: "/var/log/pods/payments_api-7d9f8b6c5-x2x4z_0b3c/api/0.log",
+			want:    "/var/log/pods/" + namespace + "_" + pod + "_0b3c/api/0.log",
+		},
+		{
+			name:    "json",
+			content: `{"node":"AKS-NODEPOOL1-12345678-VMSS000000","namespace":"payments"}`,
+			want:    `{"node":"` + node + `","namespace":"` + namespace + `"}`,
+		},
+		{
+			name:    "part of a longer name unchanged",
+			content: "payments-db aks-nodepool1-12345678-vmss0000001 payments.svc",
+			want:    "payments-db aks-nodepool1-12345678-vmss0000001 " + namespace + ".svc",
+		},
+		{
+			name:    "json keys unchanged",
+			content: `{"labels": {"app" : "web"}, "namespace": "app", "key": "\"app\""}`,
+			want:    `{"labels": {"app" : "web"}, "namespace": "` + app + `", "key": "\"` + app + `\""}`,
+		},
+		{
+			name:    "label keys unchanged",
+			content: "Labels: app=web\nNamespace: app\nSelector: tier=app",
+			want:    "Labels: app=web\nNamespace: " + app + "\nSelector: tier=" + app,
+		},
+		{
+			name:    "no names",
+			content: "kube-system coredns",
+			want:    "kube-system coredns",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := anonymizer.Anonymize(tt.content); got != tt.want {
+				t.Errorf("unexpected content.\nExpected '%s'\nFound '%s'", tt.want, got)
+			}
+		})
+	}
+
+	// The same salt gives the same names, so data from each node can be correlated.
+	other := NewAnonymizer([]byte("salt"))
+	other.AddName(AnonymizedNamespace, "payments")
+	if other.Anonymize("payments") != namespace {
+		t.Errorf("expected the same anonymized name, found %s and %s", namespace, other.Anonymize("payments"))
+	}
+
+	mapping := anonymizer.GetMapping()
+	if len(mapping) != 4 || mapping[node] != "aks-nodepool1-12345678-vmss000000" || mapping[namespace] != "payments" {
+		t.Errorf("unexpected mapping %v", mapping)
+	}
+}
+
+func TestAddClusterNames(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "coredns-1", Namespace: "kube-system"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "cart-1", Namespace: "shop"}},
+	)
+
+	anonymizer := NewAnonymizer([]byte("salt"))
+	if err := AddClusterNames(context.Background(), clientset, anonymizer); err != nil {
+		t.Fatalf("AddClusterNames() error = %v", err)
+	}
+
+	got := anonymizer.Anonymize("node-a kube-system coredns-1 shop cart-1")
+	fields := strings.Fields(got)
+	if !strings.HasPrefix(fields[0], "node-") || fields[0] == "node-a" || fields[1] != "kube-system" || fields[2] != "coredns-1" ||
+		!strings.HasPrefix(fields[3], "namespace-") || !strings.HasPrefix(fields[4], "pod-") {
+		t.Errorf("unexpected content %s", got)
+	}
+}
+
+func TestAnonymizingDataProducer(t *testing.T) {
+	anonymizer := NewAnonymizer([]byte("salt"))
+	anonymizer.AddName(AnonymizedNamespace, "shop")
+	namespace := anonymizer.Anonymize("shop")
+
+	producer := &testDataProducer{
+		data: map[string]interfaces.DataValue{
+			"logs/shop/cart": NewStringDataValue("namespace: shop"),
+		},
+	}
+
+	tests := []struct {
+		name       string
+		anonymizer *Anonymizer
+		wantKey    string
+		want       string
+	}{
+		{
+			name:       "no anonymizer",
+			anonymizer: nil,
+			wantKey:    "logs/shop/cart",
+			want:       "namespace: shop",
+		},
+		{
+			name:       "anonymizer",
+			anonymizer: anonymizer,
+			wantKey:    "logs/" + namespace + "/cart",
+			want:       "namespace: " + namespace,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anonymizing := NewAnonymizingDataProducer(producer, tt.anonymizer)
+			if anonymizing.GetName() != producer.GetName() {
+				t.Errorf("unexpected name %s", anonymizing.GetName())
+			}
+
+			value, ok := anonymizing.GetData()[tt.wantKey]
+			if !ok {
+				t.Fatalf("expected key %s in %v", tt.wantKey, anonymizing.GetData())
+			}
+			content, err := GetContent(func() (io.ReadCloser, error) { return value.GetReader() })
+			if err != nil {
+				t.Fatalf("error reading value: %v", err)
+			}
+			if content != tt.want {
+				t.Errorf("unexpected content.\nExpected '%s'\nFound '%s'", tt.want, content)
+			}
+			if value.GetLength() != int64(len(tt.want)) {
+				t.Errorf("unexpected length: expected %d, found %d", len(tt.want), value.GetLength())
+			}
+		})
+	}
+}
+
+func TestAnonymizingDataValue(t *testing.T) {
+	anonymizer := NewAnonymizer([]byte("salt"))
+	anonymizer.AddName(AnonymizedNamespace, "shop")
+	namespace := anonymizer.Anonymize("shop")
+
+	content := "namespace: shop\n\nshop/cart started\nno trailing newline: shop"
+	want := "namespace: " + namespace + "\n\n" + namespace + "/cart started\nno trailing newline: " + namespace
+
+	value := NewAnonymizingDataValue(NewStringDataValue(content), anonymizer)
+	if value.GetLength() != int64(len(want)) {
+		t.Errorf("unexpected length: expected %d, found %d", len(want), value.GetLength())
+	}
+
+	// Read a byte at a time, so that anonymized lines are returned across several reads.
+	reader, err := value.GetReader()
+	if err != nil {
+		t.Fatalf("error getting reader: %v", err)
+	}
+	defer reader.Close()
+
+	actual, err := io.ReadAll(iotest.OneByteReader(reader))
+	if err != nil {
+		t.Fatalf("error reading value: %v", err)
+	}
+	if string(actual) != want {
+		t.Errorf("unexpected content.\nExpected '%s'\nFound '%s'", want, string(actual))
+	}
+
+	// A size limit over the anonymized content truncates it as it is read.
+	limited := NewSizeLimitedDataValue(NewAnonymizingDataValue(NewStringDataValue(content), anonymizer), 5)
+	limitedContent, err := GetContent(limited.GetReader)
+	if err != nil {
+		t.Fatalf("error reading limited value: %v", err)
+	}
+	if wantLimited := "names" + getTruncationMarker(5); limitedContent != wantLimited {
+		t.Errorf("unexpected limited content.\nExpected '%s'\nFound '%s'", wantLimited, limitedContent)
+	}
+}
@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"math/rand"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// ApplyAPIRateLimit sets a single client-side rate limiter on the config, which is shared by every client created from
+// it. Without this, each clientset gets its own limiter, so the total request rate grows with the number of collectors.
+func ApplyAPIRateLimit(config *rest.Config, runtimeInfo *RuntimeInfo) {
+	config.QPS = runtimeInfo.CollectorAPIQPS
+	config.Burst = runtimeInfo.CollectorAPIBurst
+	config.RateLimiter = flowcontrol.NewTokenBucketRateLimiter(config.QPS, config.Burst)
+}
+
+// GetStartJitter returns a random delay in [0, maxJitter), so that the pods of the daemonset don't all query the API
+// server at the same moment when a run starts.
+func GetStartJitter(maxJitter time.Duration) time.Duration {
+	if maxJitter <= 0 {
+		return 0
+	}
+
+	// The global source is not randomly seeded on older Go versions, which would give every node the same delay.
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return time.Duration(random.Int63n(int64(maxJitter)))
+}
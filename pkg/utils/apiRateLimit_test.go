@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestApplyAPIRateLimit(t *testing.T) {
+	config := &rest.Config{}
+	ApplyAPIRateLimit(config, &RuntimeInfo{CollectorAPIQPS: 2.5, CollectorAPIBurst: 5})
+
+	if config.QPS != 2.5 || config.Burst != 5 {
+		t.Errorf("unexpected limits: %v QPS, burst %d", config.QPS, config.Burst)
+	}
+	if config.RateLimiter == nil {
+		t.Fatalf("expected a rate limiter")
+	}
+	if config.RateLimiter.QPS() != 2.5 {
+		t.Errorf("unexpected rate limiter QPS %v", config.RateLimiter.QPS())
+	}
+
+	// Copies of the config, as made by each client, must share the same limiter.
+	if rest.CopyConfig(config).RateLimiter != config.RateLimiter {
+		t.Errorf("expected copied config to share the rate limiter")
+	}
+}
+
+func TestGetStartJitter(t *testing.T) {
+	if jitter := GetStartJitter(0); jitter != 0 {
+		t.Errorf("expected no jitter, found %s", jitter)
+	}
+
+	maxJitter := 10 * time.Second
+	for i := 0; i < 100; i++ {
+		jitter := GetStartJitter(maxJitter)
+		if jitter < 0 || jitter >= maxJitter {
+			t.Fatalf("jitter %s out of range [0, %s)", jitter, maxJitter)
+		}
+	}
+}
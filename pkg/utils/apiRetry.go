@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+)
+
+// apiRetryBackoff spaces out the attempts of an API call, for about 3.5s in total before the last one.
+var apiRetryBackoff = wait.Backoff{
+	Steps:    4,
+	Duration: 500 * time.Millisecond,
+	Factor:   2.0,
+	Jitter:   0.1,
+}
+
+// RetryAPICall calls fn until it succeeds, returns an error which isn't transient, or runs out of attempts, returning
+// the last error. Results are assigned by fn to variables of the caller, as with retry.RetryOnConflict, e.g.
+//
+//	var pods *corev1.PodList
+//	err := utils.RetryAPICall(func() (err error) {
+//		pods, err = clientset.CoreV1().Pods(namespace).List(ctx, listOptions)
+//		return err
+//	})
+func RetryAPICall(fn func() error) error {
+	return retry.OnError(apiRetryBackoff, IsTransientAPIError, fn)
+}
+
+// APIListPageSize is the number of items requested per list call, so that very large clusters are not listed in one
+// response.
+const APIListPageSize = int64(500)
+
+// ListAllPages lists every page of a resource, calling list with the list options of each page in turn. The list
+// function handles the items of its page, and returns the continue token of the list, or an empty token to stop
+// listing. Each page is retried as with RetryAPICall, e.g.
+//
+//	err := utils.ListAllPages(metav1.ListOptions{}, func(listOptions metav1.ListOptions) (string, error) {
+//		pods, err := clientset.CoreV1().Pods(namespace).List(ctx, listOptions)
+//		if err != nil {
+//			return "", err
+//		}
+//		...
+//		return pods.Continue, nil
+//	})
+func ListAllPages(listOptions metav1.ListOptions, list func(metav1.ListOptions) (string, error)) error {
+	listOptions.Limit = APIListPageSize
+	for {
+		var continueToken string
+		err := RetryAPICall(func() (err error) {
+			continueToken, err = list(listOptions)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(continueToken) == 0 {
+			return nil
+		}
+		listOptions.Continue = continueToken
+	}
+}
+
+// IsTransientAPIError returns whether an API call that failed with the error may succeed if made again: a conflict,
+// the API server being overloaded or unavailable, or a network timeout or dropped connection.
+func IsTransientAPIError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if apierrors.IsConflict(err) ||
+		apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		apierrors.IsServiceUnavailable(err) ||
+		apierrors.IsInternalError(err) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	// IsProbableEOF only recognizes EOF errors which aren't wrapped.
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	return utilnet.IsConnectionReset(err) || utilnet.IsConnectionRefused(err) || utilnet.IsProbableEOF(err)
+}
@@ -0,0 +1,147 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestRetryAPICall(t *testing.T) {
+	// Retry without waiting, so that the test is fast.
+	defaultBackoff := apiRetryBackoff
+	apiRetryBackoff = wait.Backoff{Steps: 4, Duration: time.Millisecond}
+	defer func() { apiRetryBackoff = defaultBackoff }()
+
+	podsResource := schema.GroupResource{Resource: "pods"}
+
+	tests := []struct {
+		name      string
+		failures  int
+		err       error
+		wantCalls int
+		wantErr   bool
+	}{
+		{
+			name:      "success",
+			failures:  0,
+			wantCalls: 1,
+		},
+		{
+			name:      "unavailable then success",
+			failures:  2,
+			err:       apierrors.NewServiceUnavailable("etcd leader changed"),
+			wantCalls: 3,
+		},
+		{
+			name:      "throttled then success",
+			failures:  1,
+			err:       apierrors.NewTooManyRequests("slow down", 1),
+			wantCalls: 2,
+		},
+		{
+			name:      "dropped connection then success",
+			failures:  1,
+			err:       fmt.Errorf("list pods: %w", io.ErrUnexpectedEOF),
+			wantCalls: 2,
+		},
+		{
+			name:      "attempts exhausted",
+			failures:  10,
+			err:       apierrors.NewTimeoutError("request timed out", 1),
+			wantCalls: 4,
+			wantErr:   true,
+		},
+		{
+			name:      "not transient",
+			failures:  10,
+			err:       apierrors.NewForbidden(podsResource, "", errors.New("denied")),
+			wantCalls: 1,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: "default"}})
+
+			calls := 0
+			clientset.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				calls++
+				if calls <= tt.failures {
+					return true, nil, tt.err
+				}
+				return false, nil, nil
+			})
+
+			var pods *corev1.PodList
+			err := RetryAPICall(func() (err error) {
+				pods, err = clientset.CoreV1().Pods("default").List(context.Background(), metav1.ListOptions{})
+				return err
+			})
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RetryAPICall() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("expected %d calls, found %d", tt.wantCalls, calls)
+			}
+			if !tt.wantErr && len(pods.Items) != 1 {
+				t.Errorf("expected 1 pod, found %d", len(pods.Items))
+			}
+		})
+	}
+}
+
+func TestListAllPages(t *testing.T) {
+	defaultBackoff := apiRetryBackoff
+	apiRetryBackoff = wait.Backoff{Steps: 4, Duration: time.Millisecond}
+	defer func() { apiRetryBackoff = defaultBackoff }()
+
+	// Three pages, where the first attempt at the second page fails.
+	pages := map[string][]string{"": {"a", "b"}, "page-2": {"c", "d"}, "page-3": {"e"}}
+	next := map[string]string{"": "page-2", "page-2": "page-3", "page-3": ""}
+	failed := false
+
+	items := []string{}
+	err := ListAllPages(metav1.ListOptions{LabelSelector: "app=web"}, func(listOptions metav1.ListOptions) (string, error) {
+		if listOptions.Limit != APIListPageSize || listOptions.LabelSelector != "app=web" {
+			t.Errorf("unexpected list options %+v", listOptions)
+		}
+		if listOptions.Continue == "page-2" && !failed {
+			failed = true
+			return "", apierrors.NewServiceUnavailable("etcd leader changed")
+		}
+		items = append(items, pages[listOptions.Continue]...)
+		return next[listOptions.Continue], nil
+	})
+
+	if err != nil {
+		t.Fatalf("ListAllPages() error = %v", err)
+	}
+	if strings.Join(items, ",") != "a,b,c,d,e" {
+		t.Errorf("unexpected items %v", items)
+	}
+
+	podsResource := schema.GroupResource{Resource: "pods"}
+	calls := 0
+	err = ListAllPages(metav1.ListOptions{}, func(listOptions metav1.ListOptions) (string, error) {
+		calls++
+		return "", apierrors.NewForbidden(podsResource, "", errors.New("denied"))
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("expected a single failed call, found %d calls with error %v", calls, err)
+	}
+}
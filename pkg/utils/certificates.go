@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"time"
+)
+
+// ServingCertificateFetcher returns the certificate chain served at a host:port address. It allows collectors
+// to substitute a fake implementation of FetchServingCertificates for testing.
+type ServingCertificateFetcher func(address string) ([]*x509.Certificate, error)
+
+const servingCertificateTimeout = 5 * time.Second
+
+// FetchServingCertificates performs a TLS handshake with the address and returns the certificates it presents.
+// The chain is not verified, since the purpose is to inspect it, including when it is invalid or expired.
+func FetchServingCertificates(address string) ([]*x509.Certificate, error) {
+	dialer := &net.Dialer{Timeout: servingCertificateTimeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", address, &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		return nil, fmt.Errorf("TLS handshake with %s: %w", address, err)
+	}
+	defer conn.Close()
+
+	return conn.ConnectionState().PeerCertificates, nil
+}
+
+// ParsePEMCertificates parses all the certificates in PEM-encoded data, ignoring any other blocks such as private keys.
+func ParsePEMCertificates(data []byte) ([]*x509.Certificate, error) {
+	certificates := []*x509.Certificate{}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse certificate: %w", err)
+		}
+		certificates = append(certificates, certificate)
+	}
+
+	return certificates, nil
+}
@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchServingCertificates(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	certificates, err := FetchServingCertificates(strings.TrimPrefix(server.URL, "https://"))
+	if err != nil {
+		t.Fatalf("FetchServingCertificates() error = %v", err)
+	}
+
+	expected := server.Certificate()
+	if len(certificates) == 0 || !certificates[0].Equal(expected) {
+		t.Errorf("expected the server certificate %s, found %v", expected.Subject, certificates)
+	}
+}
+
+func TestParsePEMCertificates(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: []byte("key")})
+
+	certificates, err := ParsePEMCertificates(append(append(keyPEM, certPEM...), certPEM...))
+	if err != nil {
+		t.Fatalf("ParsePEMCertificates() error = %v", err)
+	}
+	if len(certificates) != 2 {
+		t.Errorf("expected 2 certificates, found %d", len(certificates))
+	}
+
+	if _, err := ParsePEMCertificates(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("invalid")})); err == nil {
+		t.Errorf("expected error for invalid certificate")
+	}
+}
@@ -0,0 +1,159 @@
+package utils
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+// ClockReading is the time of a remote clock, and the local time at which it was read.
+type ClockReading struct {
+	RemoteTime time.Time
+	LocalTime  time.Time
+	RoundTrip  time.Duration
+	// Uncertainty bounds the error of the offset between the clocks, from the round trip and the resolution of the
+	// remote time.
+	Uncertainty time.Duration
+}
+
+// Offset returns how far the remote clock is ahead of the local one.
+func (reading *ClockReading) Offset() time.Duration {
+	return reading.RemoteTime.Sub(reading.LocalTime)
+}
+
+// ClockReader reads the time of a remote clock. It allows collectors to substitute a fake implementation for testing.
+type ClockReader func() (*ClockReading, error)
+
+const clockReadTimeout = 5 * time.Second
+
+// NewAPIServerClockReader returns a ClockReader which reads the time of the API server from the Date header of its
+// response to a version request.
+func NewAPIServerClockReader(config *rest.Config) ClockReader {
+	return func() (*ClockReading, error) {
+		client, err := rest.HTTPClientFor(config)
+		if err != nil {
+			return nil, fmt.Errorf("create HTTP client: %w", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), clockReadTimeout)
+		defer cancel()
+
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(config.Host, "/")+"/version", nil)
+		if err != nil {
+			return nil, fmt.Errorf("create request: %w", err)
+		}
+
+		sent := time.Now()
+		response, err := client.Do(request)
+		if err != nil {
+			return nil, fmt.Errorf("request API server version: %w", err)
+		}
+		received := time.Now()
+		response.Body.Close()
+
+		date, err := http.ParseTime(response.Header.Get("Date"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid Date header '%s': %w", response.Header.Get("Date"), err)
+		}
+
+		// The Date header is truncated to the second, so the server's time was somewhere in the following second.
+		roundTrip := received.Sub(sent)
+		return &ClockReading{
+			RemoteTime:  date.Add(500 * time.Millisecond),
+			LocalTime:   sent.Add(roundTrip / 2),
+			RoundTrip:   roundTrip,
+			Uncertainty: 500*time.Millisecond + roundTrip/2,
+		}, nil
+	}
+}
+
+// The NTP epoch is 1900-01-01, 70 years (including 17 leap days) before the Unix epoch.
+const ntpEpochOffset = 2208988800
+
+// NewNTPClockReader returns a ClockReader which reads the time of an NTP server (host or host:port) with a single
+// SNTP request, as described in RFC 4330.
+func NewNTPClockReader(server string) ClockReader {
+	return func() (*ClockReading, error) {
+		address := server
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			address = net.JoinHostPort(server, "123")
+		}
+
+		conn, err := net.DialTimeout("udp", address, clockReadTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("connect to NTP server %s: %w", server, err)
+		}
+		defer conn.Close()
+
+		if err := conn.SetDeadline(time.Now().Add(clockReadTimeout)); err != nil {
+			return nil, fmt.Errorf("set deadline for %s: %w", server, err)
+		}
+
+		// A client request: no leap indicator, version 4, mode 3.
+		request := make([]byte, 48)
+		request[0] = 0x23
+
+		sent := time.Now()
+		if _, err := conn.Write(request); err != nil {
+			return nil, fmt.Errorf("send NTP request to %s: %w", server, err)
+		}
+
+		response := make([]byte, 48)
+		n, err := conn.Read(response)
+		if err != nil {
+			return nil, fmt.Errorf("read NTP response from %s: %w", server, err)
+		}
+		received := time.Now()
+
+		return parseNTPResponse(response[:n], sent, received)
+	}
+}
+
+// parseNTPResponse calculates the offset of the server's clock from the times the request was sent and received by
+// each side, which cancels out the network delay if it is symmetric.
+func parseNTPResponse(response []byte, sent, received time.Time) (*ClockReading, error) {
+	if len(response) < 48 {
+		return nil, fmt.Errorf("NTP response too short: %d bytes", len(response))
+	}
+	if mode := response[0] & 0x07; mode != 4 {
+		return nil, fmt.Errorf("unexpected NTP response mode %d", mode)
+	}
+	// A stratum of 0 is a "kiss-of-death" response, e.g. asking the client to send fewer requests.
+	if stratum := response[1]; stratum == 0 {
+		return nil, fmt.Errorf("NTP server refused request: %s", strings.TrimRight(string(response[12:16]), "\x00"))
+	}
+
+	serverReceived := getNTPTime(response[32:40])
+	serverSent := getNTPTime(response[40:48])
+	if serverSent.IsZero() {
+		return nil, errors.New("NTP response has no transmit time")
+	}
+
+	offset := (serverReceived.Sub(sent) + serverSent.Sub(received)) / 2
+	roundTrip := received.Sub(sent) - serverSent.Sub(serverReceived)
+	return &ClockReading{
+		RemoteTime:  received.Add(offset),
+		LocalTime:   received,
+		RoundTrip:   roundTrip,
+		Uncertainty: roundTrip / 2,
+	}, nil
+}
+
+// getNTPTime converts an NTP timestamp (seconds since 1900, and a binary fraction of a second) to a time.
+func getNTPTime(timestamp []byte) time.Time {
+	seconds := binary.BigEndian.Uint32(timestamp[0:4])
+	fraction := binary.BigEndian.Uint32(timestamp[4:8])
+	if seconds == 0 && fraction == 0 {
+		return time.Time{}
+	}
+
+	nanoseconds := (int64(fraction) * int64(time.Second)) >> 32
+	return time.Unix(int64(seconds)-ntpEpochOffset, nanoseconds)
+}
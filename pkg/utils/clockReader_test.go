@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"encoding/binary"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+func putNTPTime(timestamp []byte, t time.Time) {
+	binary.BigEndian.PutUint32(timestamp[0:4], uint32(t.Unix()+ntpEpochOffset))
+	binary.BigEndian.PutUint32(timestamp[4:8], uint32((int64(t.Nanosecond())<<32)/int64(time.Second)))
+}
+
+func newNTPResponse(stratum byte, serverReceived, serverSent time.Time) []byte {
+	response := make([]byte, 48)
+	response[0] = 0x24
+	response[1] = stratum
+	copy(response[12:16], "RATE")
+	putNTPTime(response[32:40], serverReceived)
+	putNTPTime(response[40:48], serverSent)
+	return response
+}
+
+func TestParseNTPResponse(t *testing.T) {
+	sent := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	received := sent.Add(100 * time.Millisecond)
+
+	// The server's clock is 3s ahead, and it took 20ms to respond, leaving 80ms of network delay.
+	serverReceived := sent.Add(3*time.Second + 40*time.Millisecond)
+	serverSent := serverReceived.Add(20 * time.Millisecond)
+
+	reading, err := parseNTPResponse(newNTPResponse(2, serverReceived, serverSent), sent, received)
+	if err != nil {
+		t.Fatalf("parseNTPResponse() error = %v", err)
+	}
+	if offset := reading.Offset().Round(time.Millisecond); offset != 3*time.Second {
+		t.Errorf("expected offset 3s, found %s", offset)
+	}
+	if roundTrip := reading.RoundTrip.Round(time.Millisecond); roundTrip != 80*time.Millisecond {
+		t.Errorf("expected round trip 80ms, found %s", roundTrip)
+	}
+
+	if _, err := parseNTPResponse(newNTPResponse(0, serverReceived, serverSent), sent, received); err == nil {
+		t.Errorf("expected error for kiss-of-death response")
+	}
+	if _, err := parseNTPResponse(make([]byte, 12), sent, received); err == nil {
+		t.Errorf("expected error for short response")
+	}
+}
+
+func TestNTPClockReader(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer conn.Close()
+
+	go func() {
+		request := make([]byte, 48)
+		_, address, err := conn.ReadFrom(request)
+		if err != nil {
+			return
+		}
+		now := time.Now().Add(-2 * time.Second)
+		conn.WriteTo(newNTPResponse(1, now, now), address)
+	}()
+
+	reading, err := NewNTPClockReader(conn.LocalAddr().String())()
+	if err != nil {
+		t.Fatalf("read NTP clock: %v", err)
+	}
+	if offset := reading.Offset(); offset > -1900*time.Millisecond || offset < -2100*time.Millisecond {
+		t.Errorf("expected offset of about -2s, found %s", offset)
+	}
+}
+
+func TestAPIServerClockReader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/version" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Date", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+		w.Write([]byte(`{"gitVersion":"v1.29.2"}`))
+	}))
+	defer server.Close()
+
+	reading, err := NewAPIServerClockReader(&rest.Config{Host: server.URL})()
+	if err != nil {
+		t.Fatalf("read API server clock: %v", err)
+	}
+
+	// The header is truncated to the second, which the uncertainty allows for.
+	if offset := reading.Offset(); offset < time.Minute-reading.Uncertainty || offset > time.Minute+reading.Uncertainty {
+		t.Errorf("expected offset of 1m (+/- %s), found %s", reading.Uncertainty, offset)
+	}
+}
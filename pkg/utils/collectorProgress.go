@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RunningCollector is a collector which has started collecting and not yet completed.
+type RunningCollector struct {
+	Name    string
+	Elapsed time.Duration
+}
+
+// CollectorProgress tracks when each running collector started, so that a long run can report which collectors it
+// is still waiting for. It is safe for concurrent use.
+type CollectorProgress struct {
+	lock    sync.Mutex
+	started map[string]time.Time
+	now     func() time.Time
+}
+
+func NewCollectorProgress() *CollectorProgress {
+	return &CollectorProgress{
+		started: map[string]time.Time{},
+		now:     time.Now,
+	}
+}
+
+// Start records that the named collector has started collecting.
+func (progress *CollectorProgress) Start(name string) {
+	progress.lock.Lock()
+	defer progress.lock.Unlock()
+
+	progress.started[name] = progress.now()
+}
+
+// Complete records that the named collector is no longer collecting, whether or not it succeeded.
+func (progress *CollectorProgress) Complete(name string) {
+	progress.lock.Lock()
+	defer progress.lock.Unlock()
+
+	delete(progress.started, name)
+}
+
+// GetRunning returns the running collectors, longest-running first.
+func (progress *CollectorProgress) GetRunning() []RunningCollector {
+	progress.lock.Lock()
+	defer progress.lock.Unlock()
+
+	now := progress.now()
+	running := make([]RunningCollector, 0, len(progress.started))
+	for name, started := range progress.started {
+		running = append(running, RunningCollector{Name: name, Elapsed: now.Sub(started)})
+	}
+
+	sort.Slice(running, func(i, j int) bool {
+		if running[i].Elapsed != running[j].Elapsed {
+			return running[i].Elapsed > running[j].Elapsed
+		}
+		return running[i].Name < running[j].Name
+	})
+
+	return running
+}
+
+// GetHeartbeat returns a log line describing the running collectors.
+func (progress *CollectorProgress) GetHeartbeat() string {
+	running := progress.GetRunning()
+	if len(running) == 0 {
+		return "Heartbeat: no collectors running"
+	}
+
+	descriptions := make([]string, len(running))
+	for i, collector := range running {
+		descriptions[i] = fmt.Sprintf("%s (%s)", collector.Name, collector.Elapsed.Round(time.Second))
+	}
+
+	return fmt.Sprintf("Heartbeat: %d collector(s) running: %s", len(running), strings.Join(descriptions, ", "))
+}
+
+// LogHeartbeats logs the running collectors at every interval, until the done channel is closed. A zero interval
+// disables heartbeats.
+func (progress *CollectorProgress) LogHeartbeats(interval time.Duration, done <-chan struct{}) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			log.Print(progress.GetHeartbeat())
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCollectorProgress(t *testing.T) {
+	now := time.Date(2023, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	progress := NewCollectorProgress()
+	progress.now = func() time.Time { return now }
+
+	if heartbeat := progress.GetHeartbeat(); heartbeat != "Heartbeat: no collectors running" {
+		t.Errorf("unexpected heartbeat with no collectors: %s", heartbeat)
+	}
+
+	progress.Start("kubeobjects")
+	now = now.Add(90 * time.Second)
+	progress.Start("nodelogs")
+	progress.Start("dns")
+	now = now.Add(1500 * time.Millisecond)
+	progress.Start("helm")
+	progress.Complete("helm")
+
+	wantRunning := []RunningCollector{
+		{Name: "kubeobjects", Elapsed: 91500 * time.Millisecond},
+		{Name: "dns", Elapsed: 1500 * time.Millisecond},
+		{Name: "nodelogs", Elapsed: 1500 * time.Millisecond},
+	}
+	if running := progress.GetRunning(); !reflect.DeepEqual(running, wantRunning) {
+		t.Errorf("expected running collectors %v, found %v", wantRunning, running)
+	}
+
+	const wantHeartbeat = "Heartbeat: 3 collector(s) running: kubeobjects (1m32s), dns (2s), nodelogs (2s)"
+	if heartbeat := progress.GetHeartbeat(); heartbeat != wantHeartbeat {
+		t.Errorf("unexpected heartbeat.\nExpected '%s'\nFound '%s'", wantHeartbeat, heartbeat)
+	}
+}
+
+func TestCollectorProgressLogHeartbeats(t *testing.T) {
+	progress := NewCollectorProgress()
+
+	// A zero interval returns immediately, rather than blocking until done.
+	progress.LogHeartbeats(0, nil)
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		progress.LogHeartbeats(time.Millisecond, done)
+		close(stopped)
+	}()
+
+	time.Sleep(5 * time.Millisecond)
+	close(done)
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Errorf("LogHeartbeats did not return after done was closed")
+	}
+}
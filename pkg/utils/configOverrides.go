@@ -0,0 +1,144 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ConfigOverridesName is the name of the optional ConfigMap, in Periscope's own namespace, whose values take
+// precedence over the mounted diagnostic-config. It is read at the start of each run, so changes apply to the next
+// run without redeploying Periscope.
+const ConfigOverridesName = "diagnostic-overrides"
+
+// CollectorAnnotationPrefix prefixes the annotations of the overrides ConfigMap which opt a collector in ("true") or
+// out ("false"), e.g. "collectors.aks-periscope.azure.com/iptables".
+const CollectorAnnotationPrefix = "collectors.aks-periscope.azure.com/"
+
+// ConfigOverrides holds the values read from the overrides ConfigMap.
+type ConfigOverrides struct {
+	// Values replace the config values of the same keys.
+	Values map[ConfigKey]string
+	// EnabledCollectors are added to COLLECTOR_LIST, and DisabledCollectors are not run even if selected.
+	EnabledCollectors  []string
+	DisabledCollectors []string
+}
+
+// GetConfigOverrides reads the overrides ConfigMap from the namespace Periscope runs in, returning nil if it doesn't
+// exist.
+func GetConfigOverrides(fs interfaces.FileSystemAccessor, filePaths *KnownFilePaths, clientset kubernetes.Interface) (*ConfigOverrides, error) {
+	namespace, err := readFileContent(fs, filePaths.ServiceAccountNamespace, true, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to determine namespace: %w", err)
+	}
+	namespace = strings.TrimSpace(namespace)
+
+	configMap, err := clientset.CoreV1().ConfigMaps(namespace).Get(context.Background(), ConfigOverridesName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to get configmap %s/%s: %w", namespace, ConfigOverridesName, err)
+	}
+
+	overrides := &ConfigOverrides{
+		Values:             map[ConfigKey]string{},
+		EnabledCollectors:  []string{},
+		DisabledCollectors: []string{},
+	}
+
+	for key, value := range configMap.Data {
+		// The run ID is only read from the mounted config, which is watched to start each run.
+		if ConfigKey(key) == RunIdKey {
+			return nil, fmt.Errorf("invalid configmap %s: %s can't be overridden", ConfigOverridesName, RunIdKey)
+		}
+		overrides.Values[ConfigKey(key)] = value
+	}
+
+	for annotation, value := range configMap.Annotations {
+		if !strings.HasPrefix(annotation, CollectorAnnotationPrefix) {
+			continue
+		}
+		name := strings.TrimPrefix(annotation, CollectorAnnotationPrefix)
+
+		enabled, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil || len(name) == 0 {
+			return nil, fmt.Errorf("invalid configmap %s annotation %s value '%s': expected a collector name and true or false", ConfigOverridesName, annotation, value)
+		}
+		if enabled {
+			overrides.EnabledCollectors = append(overrides.EnabledCollectors, name)
+		} else {
+			overrides.DisabledCollectors = append(overrides.DisabledCollectors, name)
+		}
+	}
+
+	// Annotations are unordered, so collectors are added to COLLECTOR_LIST in a consistent order.
+	sort.Strings(overrides.EnabledCollectors)
+	sort.Strings(overrides.DisabledCollectors)
+
+	return overrides, nil
+}
+
+// Apply adds the collectors opted in or out by annotation to the runtime info, whose config values were read
+// through the file system returned by GetFileSystem.
+func (overrides *ConfigOverrides) Apply(runtimeInfo *RuntimeInfo) {
+	if overrides == nil {
+		return
+	}
+
+	for _, name := range overrides.EnabledCollectors {
+		if !Contains(runtimeInfo.CollectorList, name) {
+			runtimeInfo.CollectorList = append(runtimeInfo.CollectorList, name)
+		}
+	}
+	runtimeInfo.DisabledCollectors = append(runtimeInfo.DisabledCollectors, overrides.DisabledCollectors...)
+}
+
+// GetFileSystem returns a file system in which the overridden config values replace the mounted config files.
+func (overrides *ConfigOverrides) GetFileSystem(fs interfaces.FileSystemAccessor, filePaths *KnownFilePaths) interfaces.FileSystemAccessor {
+	if overrides == nil || len(overrides.Values) == 0 {
+		return fs
+	}
+
+	files := map[string]string{}
+	for key, value := range overrides.Values {
+		files[filePaths.GetConfigPath(key)] = value
+	}
+
+	return &overriddenFileSystem{FileSystemAccessor: fs, files: files}
+}
+
+// overriddenFileSystem serves the content of some files from memory, and everything else from the file system.
+type overriddenFileSystem struct {
+	interfaces.FileSystemAccessor
+	files map[string]string
+}
+
+func (fs *overriddenFileSystem) GetFileReader(filePath string) (io.ReadCloser, error) {
+	if content, ok := fs.files[filePath]; ok {
+		return io.NopCloser(strings.NewReader(content)), nil
+	}
+	return fs.FileSystemAccessor.GetFileReader(filePath)
+}
+
+func (fs *overriddenFileSystem) FileExists(filePath string) (bool, error) {
+	if _, ok := fs.files[filePath]; ok {
+		return true, nil
+	}
+	return fs.FileSystemAccessor.FileExists(filePath)
+}
+
+func (fs *overriddenFileSystem) GetFileSize(filePath string) (int64, error) {
+	if content, ok := fs.files[filePath]; ok {
+		return int64(len(content)), nil
+	}
+	return fs.FileSystemAccessor.GetFileSize(filePath)
+}
@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetConfigOverrides(t *testing.T) {
+	filePaths := &KnownFilePaths{
+		Config:                  "/config",
+		Secret:                  "/secret",
+		NodeLogsList:            "/config/" + string(NodeLogsLinuxKey),
+		ServiceAccountNamespace: "/serviceaccount/namespace",
+	}
+
+	newConfigMap := func(namespace string, data, annotations map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: ConfigOverridesName, Namespace: namespace, Annotations: annotations},
+			Data:       data,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		configMap *corev1.ConfigMap
+		wantErr   bool
+		validate  func(*testing.T, *RuntimeInfo)
+	}{
+		{
+			name:      "no overrides",
+			configMap: nil,
+			validate: func(t *testing.T, runtimeInfo *RuntimeInfo) {
+				if !reflect.DeepEqual(runtimeInfo.CollectorList, []string{"dns", "systemlogs"}) || len(runtimeInfo.DisabledCollectors) != 0 {
+					t.Errorf("unexpected collectors %v (disabled %v)", runtimeInfo.CollectorList, runtimeInfo.DisabledCollectors)
+				}
+				if !reflect.DeepEqual(runtimeInfo.ContainerLogsNamespaces, []string{"kube-system"}) {
+					t.Errorf("unexpected container logs namespaces %v", runtimeInfo.ContainerLogsNamespaces)
+				}
+			},
+		},
+		{
+			name:      "overrides in another namespace",
+			configMap: newConfigMap("default", map[string]string{string(ContainerLogsListKey): "default"}, nil),
+			validate: func(t *testing.T, runtimeInfo *RuntimeInfo) {
+				if !reflect.DeepEqual(runtimeInfo.ContainerLogsNamespaces, []string{"kube-system"}) {
+					t.Errorf("unexpected container logs namespaces %v", runtimeInfo.ContainerLogsNamespaces)
+				}
+			},
+		},
+		{
+			name: "overrides take precedence",
+			configMap: newConfigMap("aks-periscope",
+				map[string]string{
+					string(CollectorListKey):     "dns kubeobjects",
+					string(ContainerLogsListKey): "default app",
+				},
+				map[string]string{
+					CollectorAnnotationPrefix + "iptables":   "true",
+					CollectorAnnotationPrefix + "dns":        "true",
+					CollectorAnnotationPrefix + "systemlogs": "false",
+					"unrelated.example.com/annotation":       "value",
+				}),
+			validate: func(t *testing.T, runtimeInfo *RuntimeInfo) {
+				if !reflect.DeepEqual(runtimeInfo.CollectorList, []string{"dns", "kubeobjects", "iptables"}) {
+					t.Errorf("unexpected collectors %v", runtimeInfo.CollectorList)
+				}
+				if !reflect.DeepEqual(runtimeInfo.DisabledCollectors, []string{"systemlogs"}) {
+					t.Errorf("unexpected disabled collectors %v", runtimeInfo.DisabledCollectors)
+				}
+				if !reflect.DeepEqual(runtimeInfo.ContainerLogsNamespaces, []string{"default", "app"}) {
+					t.Errorf("unexpected container logs namespaces %v", runtimeInfo.ContainerLogsNamespaces)
+				}
+
+				// Values which aren't overridden are still read from the mounted config.
+				if !reflect.DeepEqual(runtimeInfo.KubernetesObjects, []string{"kube-system/pod"}) {
+					t.Errorf("unexpected kubernetes objects %v", runtimeInfo.KubernetesObjects)
+				}
+			},
+		},
+		{
+			name:      "run ID overridden",
+			configMap: newConfigMap("aks-periscope", map[string]string{string(RunIdKey): "run-2"}, nil),
+			wantErr:   true,
+		},
+		{
+			name:      "invalid annotation value",
+			configMap: newConfigMap("aks-periscope", nil, map[string]string{CollectorAnnotationPrefix + "dns": "yes please"}),
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOST_NODE_NAME", "node-1")
+
+			fs := test.NewFakeFileSystem(map[string]string{
+				filePaths.ServiceAccountNamespace:             "aks-periscope\n",
+				filePaths.GetConfigPath(RunIdKey):             "run-1",
+				filePaths.GetConfigPath(CollectorListKey):     "dns systemlogs",
+				filePaths.GetConfigPath(ContainerLogsListKey): "kube-system",
+				filePaths.GetConfigPath(KubeObjectsListKey):   "kube-system/pod",
+			})
+
+			clientset := fake.NewSimpleClientset()
+			if tt.configMap != nil {
+				clientset = fake.NewSimpleClientset(tt.configMap)
+			}
+
+			overrides, err := GetConfigOverrides(fs, filePaths, clientset)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetConfigOverrides() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			runtimeInfo, err := GetRuntimeInfo(overrides.GetFileSystem(fs, filePaths), filePaths)
+			if err != nil {
+				t.Fatalf("GetRuntimeInfo() error = %v", err)
+			}
+			overrides.Apply(runtimeInfo)
+			tt.validate(t, runtimeInfo)
+		})
+	}
+}
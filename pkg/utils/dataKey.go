@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Data keys are '/'-separated paths: each segment but the last names a directory, which the file system and archive
+// exporters create, and the Azure Blob exporter uses the key as a virtual path. Segments must not be empty, "." or
+// "..", and must not contain '\', so that a key can't escape the directory it is written to on any OS.
+//
+// A collector which needs a segment that may itself contain '/' (e.g. a file path or a namespaced name) should build
+// the key with JoinDataKey, which percent-encodes '%', '/' and '\' in each segment ("%25", "%2F" and "%5C"), and
+// encodes segments made only of dots ("." becomes "%2E"). The escaped segment is used as the file name unchanged.
+const DataKeySeparator = "/"
+
+var dataKeySegmentEscaper = strings.NewReplacer("%", "%25", "/", "%2F", `\`, "%5C")
+
+// EscapeDataKeySegment escapes a value for use as a single segment of a data key.
+func EscapeDataKeySegment(segment string) string {
+	if strings.Trim(segment, ".") == "" && len(segment) > 0 {
+		return strings.Repeat("%2E", len(segment))
+	}
+
+	return dataKeySegmentEscaper.Replace(segment)
+}
+
+// JoinDataKey builds a data key from segments, escaping each so that it maps to exactly one path component.
+func JoinDataKey(segments ...string) string {
+	escaped := make([]string, len(segments))
+	for i, segment := range segments {
+		escaped[i] = EscapeDataKeySegment(segment)
+	}
+
+	return strings.Join(escaped, DataKeySeparator)
+}
+
+// SplitDataKey returns the path segments of a data key, or an error if it is not a valid key.
+func SplitDataKey(key string) ([]string, error) {
+	segments := strings.Split(key, DataKeySeparator)
+	for _, segment := range segments {
+		switch {
+		case segment == "":
+			return nil, fmt.Errorf("invalid key %q: empty path segment", key)
+		case segment == "." || segment == "..":
+			return nil, fmt.Errorf("invalid key %q: relative path segment %q", key, segment)
+		case strings.Contains(segment, `\`):
+			return nil, fmt.Errorf(`invalid key %q: segment %q contains '\'`, key, segment)
+		}
+	}
+
+	return segments, nil
+}
+
+// GetDataKeyPath returns the relative file path of a data key, using the OS path separator.
+func GetDataKeyPath(key string) (string, error) {
+	segments, err := SplitDataKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(segments...), nil
+}
@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestJoinDataKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		segments []string
+		want     string
+	}{
+		{
+			name:     "plain segments",
+			segments: []string{"kube-system", "coredns-12345", "coredns"},
+			want:     "kube-system/coredns-12345/coredns",
+		},
+		{
+			name:     "file path",
+			segments: []string{"nodelogs", "/var/log/syslog"},
+			want:     "nodelogs/%2Fvar%2Flog%2Fsyslog",
+		},
+		{
+			name:     "percent and backslash",
+			segments: []string{`C:\AzureData\50%.log`},
+			want:     "C:%5CAzureData%5C50%25.log",
+		},
+		{
+			name:     "dot segments",
+			segments: []string{".", "..", "...", ".hidden"},
+			want:     "%2E/%2E%2E/%2E%2E%2E/.hidden",
+		},
+		{
+			name:     "already escaped",
+			segments: []string{"%2F"},
+			want:     "%252F",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := JoinDataKey(tt.segments...)
+			if key != tt.want {
+				t.Errorf("JoinDataKey() = %s, want %s", key, tt.want)
+			}
+
+			segments, err := SplitDataKey(key)
+			if err != nil {
+				t.Fatalf("SplitDataKey() error = %v", err)
+			}
+			if len(segments) != len(tt.segments) {
+				t.Errorf("expected %d segments, found %v", len(tt.segments), segments)
+			}
+		})
+	}
+}
+
+func TestGetDataKeyPath(t *testing.T) {
+	tests := []struct {
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{key: "key", want: "key"},
+		{key: "dir/sub dir/key.json", want: filepath.Join("dir", "sub dir", "key.json")},
+		{key: "%2Fvar%2Flog%2Fsyslog", want: "%2Fvar%2Flog%2Fsyslog"},
+		{key: "..hidden/key..", want: filepath.Join("..hidden", "key..")},
+		{key: "", wantErr: true},
+		{key: "/absolute", wantErr: true},
+		{key: "trailing/", wantErr: true},
+		{key: "double//separator", wantErr: true},
+		{key: "../escaped", wantErr: true},
+		{key: "dir/./key", wantErr: true},
+		{key: "dir/../../escaped", wantErr: true},
+		{key: `..\escaped`, wantErr: true},
+		{key: `dir\key`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		path, err := GetDataKeyPath(tt.key)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("GetDataKeyPath(%q) error = %v, wantErr %v", tt.key, err, tt.wantErr)
+			continue
+		}
+		if path != tt.want {
+			t.Errorf("GetDataKeyPath(%q) = %q, want %q", tt.key, path, tt.want)
+		}
+	}
+}
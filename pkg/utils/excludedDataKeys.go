@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"log"
+	"path"
+	"sync"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+)
+
+// KeyExcluder decides which data keys are dropped from export, by matching them against glob patterns (as
+// configured by DIAGNOSTIC_EXCLUDE_KEYS). A pattern matches either the key itself (e.g. "kubeobjects/*") or the key
+// prefixed by the producer name (e.g. "helm/*"), so that a key can be excluded from one collector only.
+type KeyExcluder struct {
+	patterns []string
+	lock     sync.Mutex
+	logged   map[string]bool
+}
+
+// NewKeyExcluder creates a KeyExcluder. Patterns must be valid for path.Match. With no patterns, nothing is excluded.
+func NewKeyExcluder(patterns []string) *KeyExcluder {
+	return &KeyExcluder{
+		patterns: patterns,
+		logged:   map[string]bool{},
+	}
+}
+
+// IsExcluded reports whether a key of the named producer is excluded, logging each excluded key once.
+func (e *KeyExcluder) IsExcluded(producerName, key string) bool {
+	if e == nil {
+		return false
+	}
+
+	for _, pattern := range e.patterns {
+		matchesKey, _ := path.Match(pattern, key)
+		matchesQualifiedKey, _ := path.Match(pattern, producerName+"/"+key)
+		if matchesKey || matchesQualifiedKey {
+			e.logExcluded(producerName, key, pattern)
+			return true
+		}
+	}
+
+	return false
+}
+
+// logExcluded logs the first exclusion of each key, since the data of a producer is read more than once (e.g. for the
+// zip archive as well as the individual export).
+func (e *KeyExcluder) logExcluded(producerName, key, pattern string) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	qualifiedKey := producerName + "/" + key
+	if !e.logged[qualifiedKey] {
+		e.logged[qualifiedKey] = true
+		log.Printf("Excluding %s from export: matches DIAGNOSTIC_EXCLUDE_KEYS pattern '%s'", qualifiedKey, pattern)
+	}
+}
+
+// ExcludingDataProducer wraps a DataProducer, omitting any of its data values whose keys are excluded.
+type ExcludingDataProducer struct {
+	producer interfaces.DataProducer
+	excluder *KeyExcluder
+}
+
+// NewExcludingDataProducer creates a DataProducer without the excluded keys. A nil excluder excludes nothing.
+func NewExcludingDataProducer(producer interfaces.DataProducer, excluder *KeyExcluder) *ExcludingDataProducer {
+	return &ExcludingDataProducer{
+		producer: producer,
+		excluder: excluder,
+	}
+}
+
+func (p *ExcludingDataProducer) GetName() string {
+	return p.producer.GetName()
+}
+
+func (p *ExcludingDataProducer) GetData() map[string]interfaces.DataValue {
+	data := p.producer.GetData()
+	if p.excluder == nil || len(p.excluder.patterns) == 0 {
+		return data
+	}
+
+	result := make(map[string]interfaces.DataValue, len(data))
+	for key, value := range data {
+		if !p.excluder.IsExcluded(p.producer.GetName(), key) {
+			result[key] = value
+		}
+	}
+
+	return result
+}
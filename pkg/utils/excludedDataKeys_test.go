@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+)
+
+func TestExcludingDataProducer(t *testing.T) {
+	producer := &testDataProducer{
+		data: map[string]interfaces.DataValue{
+			"kubeobjects/pods":      NewStringDataValue("pods"),
+			"kubeobjects/services":  NewStringDataValue("services"),
+			"journal/kubelet.log":   NewStringDataValue("kubelet"),
+			"journal/containerd":    NewStringDataValue("containerd"),
+			"iptables":              NewStringDataValue("rules"),
+			"nested/deeper/key.log": NewStringDataValue("deeper"),
+		},
+	}
+
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string
+	}{
+		{
+			name:     "no patterns",
+			patterns: []string{},
+			want:     []string{"iptables", "journal/containerd", "journal/kubelet.log", "kubeobjects/pods", "kubeobjects/services", "nested/deeper/key.log"},
+		},
+		{
+			name:     "key patterns",
+			patterns: []string{"kubeobjects/*", "journal/*.log"},
+			want:     []string{"iptables", "journal/containerd", "nested/deeper/key.log"},
+		},
+		{
+			name:     "producer name prefix",
+			patterns: []string{"test/iptables", "other/journal/*"},
+			want:     []string{"journal/containerd", "journal/kubelet.log", "kubeobjects/pods", "kubeobjects/services", "nested/deeper/key.log"},
+		},
+		{
+			name:     "wildcards don't cross separators",
+			patterns: []string{"*.log"},
+			want:     []string{"iptables", "journal/containerd", "journal/kubelet.log", "kubeobjects/pods", "kubeobjects/services", "nested/deeper/key.log"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			excluding := NewExcludingDataProducer(producer, NewKeyExcluder(tt.patterns))
+			if excluding.GetName() != producer.GetName() {
+				t.Errorf("unexpected name %s", excluding.GetName())
+			}
+
+			keys := SortedKeys(excluding.GetData())
+			if !reflect.DeepEqual(keys, tt.want) {
+				t.Errorf("expected keys %v, found %v", tt.want, keys)
+			}
+		})
+	}
+
+	if len(NewExcludingDataProducer(producer, nil).GetData()) != len(producer.data) {
+		t.Errorf("expected nil excluder to exclude nothing")
+	}
+}
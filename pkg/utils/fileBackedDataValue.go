@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/hashicorp/go-multierror"
+)
+
+// FileBackedDataValue holds its content in a temporary file, so that collectors producing large output don't need
+// to keep it in memory until it has been exported. The file must be removed by calling Remove once all exports of
+// the value are complete.
+type FileBackedDataValue struct {
+	filePath string
+	length   int64
+}
+
+// NewFileBackedDataValue copies the content of the reader to a new temporary file.
+func NewFileBackedDataValue(reader io.Reader) (*FileBackedDataValue, error) {
+	return NewFileBackedDataValueFromWriter(func(w io.Writer) error {
+		_, err := io.Copy(w, reader)
+		return err
+	})
+}
+
+// NewFileBackedDataValueFromWriter creates a new temporary file and passes it to the write function to be populated,
+// for content (e.g. command output) that is produced by writing rather than read from a reader.
+func NewFileBackedDataValueFromWriter(write func(io.Writer) error) (*FileBackedDataValue, error) {
+	file, err := os.CreateTemp("", "periscope-value-*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp file: %w", err)
+	}
+	defer file.Close()
+
+	counter := &countingWriter{writer: file}
+	if err := write(counter); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, fmt.Errorf("write temp file %s: %w", file.Name(), err)
+	}
+
+	return &FileBackedDataValue{
+		filePath: file.Name(),
+		length:   counter.count,
+	}, nil
+}
+
+func (v *FileBackedDataValue) GetLength() int64 {
+	return v.length
+}
+
+// GetReader opens the temporary file afresh, so that the value can be read by more than one exporter.
+func (v *FileBackedDataValue) GetReader() (io.ReadCloser, error) {
+	return os.Open(v.filePath)
+}
+
+// Remove deletes the temporary file. The value cannot be read afterwards.
+func (v *FileBackedDataValue) Remove() error {
+	if err := os.Remove(v.filePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// RemoveFileBackedData removes the temporary files of any file-backed values of the producer. The producer must be
+// the one that created the values, rather than a wrapper (e.g. for redaction) which replaces them.
+func RemoveFileBackedData(producer interfaces.DataProducer) error {
+	var errs error
+	for key, value := range producer.GetData() {
+		if fileValue, ok := value.(*FileBackedDataValue); ok {
+			if err := fileValue.Remove(); err != nil {
+				errs = multierror.Append(errs, fmt.Errorf("remove %s: %w", key, err))
+			}
+		}
+	}
+
+	return errs
+}
+
+// countingWriter counts the bytes written through it, since the write function doesn't report a length.
+type countingWriter struct {
+	writer io.Writer
+	count  int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.count += int64(n)
+	return n, err
+}
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+)
+
+func TestFileBackedDataValue(t *testing.T) {
+	const content = "line 1\nline 2\n"
+
+	value, err := NewFileBackedDataValue(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("error creating value: %v", err)
+	}
+
+	if value.GetLength() != int64(len(content)) {
+		t.Errorf("unexpected length: expected %d, found %d", len(content), value.GetLength())
+	}
+
+	// The value should be readable more than once, e.g. by the per-collector export and the final zip.
+	for i := 0; i < 2; i++ {
+		reader, err := value.GetReader()
+		if err != nil {
+			t.Fatalf("error getting reader: %v", err)
+		}
+
+		actual, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			t.Fatalf("error reading value: %v", err)
+		}
+		if string(actual) != content {
+			t.Errorf("unexpected content: expected %q, found %q", content, string(actual))
+		}
+	}
+
+	producer := &testDataProducer{
+		data: map[string]interfaces.DataValue{
+			"file":   value,
+			"string": NewStringDataValue(content),
+		},
+	}
+	if err := RemoveFileBackedData(producer); err != nil {
+		t.Fatalf("error removing file-backed data: %v", err)
+	}
+
+	if _, err := os.Stat(value.filePath); !os.IsNotExist(err) {
+		t.Errorf("expected temp file %s to be removed, found error %v", value.filePath, err)
+	}
+
+	if _, err := value.GetReader(); err == nil {
+		t.Errorf("expected error reading removed value")
+	}
+
+	// Removing again is not an error.
+	if err := value.Remove(); err != nil {
+		t.Errorf("unexpected error removing value twice: %v", err)
+	}
+}
+
+func TestFileBackedDataValueFromWriter(t *testing.T) {
+	const content = "partial output"
+
+	value, err := NewFileBackedDataValueFromWriter(func(w io.Writer) error {
+		_, err := io.WriteString(w, content)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("error creating value: %v", err)
+	}
+	defer value.Remove()
+
+	if value.GetLength() != int64(len(content)) {
+		t.Errorf("unexpected length: expected %d, found %d", len(content), value.GetLength())
+	}
+
+	// A failed write shouldn't leave its partial output behind.
+	var tempFile string
+	_, err = NewFileBackedDataValueFromWriter(func(w io.Writer) error {
+		tempFile = w.(*countingWriter).writer.(*os.File).Name()
+		io.WriteString(w, content)
+		return errors.New("command failed")
+	})
+	if err == nil {
+		t.Fatalf("expected error from failed write")
+	}
+	if _, err := os.Stat(tempFile); !os.IsNotExist(err) {
+		t.Errorf("expected temp file %s to be removed, found error %v", tempFile, err)
+	}
+}
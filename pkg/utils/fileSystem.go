@@ -53,3 +53,64 @@ func (fs *FileSystem) ListFiles(directoryPath string) ([]string, error) {
 
 	return paths, nil
 }
+
+func (fs *FileSystem) ListDirectory(directoryPath string) ([]string, error) {
+	entries, err := os.ReadDir(directoryPath)
+	if err != nil {
+		return nil, fmt.Errorf("error listing directory %s: %w", directoryPath, err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	return names, nil
+}
+
+func (fs *FileSystem) ReadLink(linkPath string) (string, error) {
+	target, err := os.Readlink(linkPath)
+	if err != nil {
+		return "", fmt.Errorf("error reading link %s: %w", linkPath, err)
+	}
+
+	return target, nil
+}
+
+// errEntryLimitReached stops a directory walk once the entry limit has been reached.
+var errEntryLimitReached = errors.New("entry limit reached")
+
+func (fs *FileSystem) GetDirectoryUsage(directoryPath string, maxEntries int) (int64, bool, error) {
+	var size int64
+	entries := 0
+	sizeAdder := func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		entries++
+		if entries > maxEntries {
+			return errEntryLimitReached
+		}
+
+		// Symbolic links aren't followed, so a link to a large directory isn't counted.
+		if entry.Type().IsRegular() {
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	}
+
+	err := filepath.WalkDir(directoryPath, sizeAdder)
+	if errors.Is(err, errEntryLimitReached) {
+		return size, false, nil
+	}
+	if err != nil {
+		return size, false, fmt.Errorf("error getting usage of %s: %w", directoryPath, err)
+	}
+
+	return size, true, nil
+}
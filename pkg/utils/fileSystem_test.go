@@ -95,3 +95,69 @@ func TestFileExistsForMissingFile(t *testing.T) {
 		t.Errorf("file does not exist but FileExists returned true")
 	}
 }
+
+func TestGetDirectoryUsage(t *testing.T) {
+	directory := t.TempDir()
+	for name, content := range map[string]string{"a": "12345", "sub/b": "123", "sub/c": "1"} {
+		filePath := path.Join(directory, name)
+		if err := os.MkdirAll(path.Dir(filePath), 0755); err != nil {
+			t.Fatalf("unable to create directory: %v", err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatalf("unable to write %s: %v", filePath, err)
+		}
+	}
+
+	fs := NewFileSystem()
+	size, complete, err := fs.GetDirectoryUsage(directory, 100)
+	if err != nil {
+		t.Fatalf("error getting usage of %s: %v", directory, err)
+	}
+	if size != 9 || !complete {
+		t.Errorf("expected complete usage of 9 bytes, found %d (complete: %v)", size, complete)
+	}
+
+	// The root, the file "a" and the sub-directory are visited before the limit is reached.
+	size, complete, err = fs.GetDirectoryUsage(directory, 3)
+	if err != nil {
+		t.Fatalf("error getting usage of %s: %v", directory, err)
+	}
+	if size != 5 || complete {
+		t.Errorf("expected incomplete usage of 5 bytes, found %d (complete: %v)", size, complete)
+	}
+
+	if _, _, err := fs.GetDirectoryUsage(path.Join(directory, "missing"), 100); err == nil {
+		t.Errorf("expected error getting usage of missing directory")
+	}
+}
+
+func TestListDirectoryAndReadLink(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(path.Join(dir, "subdir"), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path.Join(dir, "subdir", "nested"), []byte("content"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := os.Symlink("/dev/null", path.Join(dir, "link")); err != nil {
+		t.Fatalf("failed to create link: %v", err)
+	}
+
+	fs := NewFileSystem()
+	names, err := fs.ListDirectory(dir)
+	if err != nil {
+		t.Fatalf("error listing %s: %v", dir, err)
+	}
+	if len(names) != 2 || names[0] != "link" || names[1] != "subdir" {
+		t.Errorf("unexpected entries %v", names)
+	}
+
+	target, err := fs.ReadLink(path.Join(dir, "link"))
+	if err != nil || target != "/dev/null" {
+		t.Errorf("unexpected link target '%s' (error %v)", target, err)
+	}
+
+	if _, err := fs.ListDirectory(path.Join(dir, uuid.New().String())); err == nil {
+		t.Errorf("no error listing missing directory")
+	}
+}
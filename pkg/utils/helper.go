@@ -85,6 +85,10 @@ func GetStorageEndpointSuffix(knownFilePaths *KnownFilePaths) string {
 	return PublicAzureStorageEndpointSuffix
 }
 
+// HostCommandRunner runs a command on the host system and returns its output. It allows collectors
+// to substitute a fake implementation of RunCommandOnHost for testing.
+type HostCommandRunner func(command string, arg ...string) (string, error)
+
 // RunCommandOnHost runs a command on host system
 func RunCommandOnHost(command string, arg ...string) (string, error) {
 	args := []string{"--target", "1", "--mount", "--uts", "--ipc", "--net", "--pid"}
@@ -95,12 +99,36 @@ func RunCommandOnHost(command string, arg ...string) (string, error) {
 	cmd := exec.Command("nsenter", args...)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
-		return "", fmt.Errorf("fail to run command on host: %+v", err)
+		// Include the output, since it usually explains the failure (e.g. "Operation not permitted").
+		return "", fmt.Errorf("fail to run command on host: %+v: %s", err, strings.TrimSpace(string(out)))
 	}
 
 	return string(out), nil
 }
 
+// HostCommandStreamer runs a command on the host system, writing its standard output to the writer as it is produced.
+// It allows collectors to substitute a fake implementation of StreamCommandOnHost for testing.
+type HostCommandStreamer func(stdout io.Writer, command string, arg ...string) error
+
+// StreamCommandOnHost runs a command on host system, writing its standard output to the writer. Unlike
+// RunCommandOnHost, standard error is kept out of the output, and is only used to explain a failure.
+func StreamCommandOnHost(stdout io.Writer, command string, arg ...string) error {
+	args := []string{"--target", "1", "--mount", "--uts", "--ipc", "--net", "--pid"}
+	args = append(args, "--")
+	args = append(args, command)
+	args = append(args, arg...)
+
+	var stderr strings.Builder
+	cmd := exec.Command("nsenter", args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("fail to run command on host: %+v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
+
 // Tries to issue an HTTP GET request up to maxRetries times
 func GetUrlWithRetries(url string, maxRetries int) ([]byte, error) {
 	retry := 1
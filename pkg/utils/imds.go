@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// IMDSEndpoint is the well-known, non-routable address of the Azure Instance Metadata Service.
+// https://learn.microsoft.com/en-us/azure/virtual-machines/instance-metadata-service
+const IMDSEndpoint = "http://169.254.169.254"
+
+// ErrIMDSUnreachable is returned when no response could be obtained from IMDS at all,
+// which is expected when not running on an Azure VM.
+var ErrIMDSUnreachable = errors.New("IMDS endpoint unreachable")
+
+// GetIMDSContent issues a GET request for the specified path (including query string) to IMDS.
+// All IMDS requests must include the 'Metadata: true' header, and must not go through a proxy.
+func GetIMDSContent(client *http.Client, endpoint, path string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, endpoint+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating IMDS request for %s: %w", path, err)
+	}
+	req.Header.Set("Metadata", "true")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIMDSUnreachable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response from IMDS for %s: %s", path, resp.Status)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading IMDS response for %s: %w", path, err)
+	}
+
+	return content, nil
+}
+
+// NewIMDSClient creates an HTTP client suitable for querying IMDS, which bypasses any configured proxy.
+func NewIMDSClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{Proxy: nil},
+	}
+}
@@ -0,0 +1,185 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// JSONSchema is the subset of JSON Schema used to declare the shape of collector output: type, properties, required,
+// additionalProperties, items and enum. Other keywords are ignored.
+type JSONSchema struct {
+	Type                 jsonSchemaTypes        `json:"type,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Enum                 []interface{}          `json:"enum,omitempty"`
+
+	// forbidden is set for a schema of false, as used in "additionalProperties": false.
+	forbidden bool
+}
+
+// jsonSchemaTypes holds the value of the type keyword, which is either a single type name or a list of them.
+type jsonSchemaTypes []string
+
+func (types *jsonSchemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*types = []string{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return fmt.Errorf("type must be a string or an array of strings: %w", err)
+	}
+	*types = multiple
+	return nil
+}
+
+func (schema *JSONSchema) UnmarshalJSON(data []byte) error {
+	var boolean bool
+	if err := json.Unmarshal(data, &boolean); err == nil {
+		*schema = JSONSchema{forbidden: !boolean}
+		return nil
+	}
+
+	// The alias has no UnmarshalJSON method, so this doesn't recurse.
+	type schemaAlias JSONSchema
+	var alias schemaAlias
+	if err := json.Unmarshal(data, &alias); err != nil {
+		return err
+	}
+	*schema = JSONSchema(alias)
+	return nil
+}
+
+// ParseJSONSchema parses a schema document.
+func ParseJSONSchema(data []byte) (*JSONSchema, error) {
+	schema := &JSONSchema{}
+	if err := json.Unmarshal(data, schema); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+	return schema, nil
+}
+
+// Validate checks a JSON document against the schema, returning every mismatch found, each with the path of the
+// value which doesn't match (e.g. "$[0].events[2].count").
+func (schema *JSONSchema) Validate(document []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	return schema.validate("$", value, nil)
+}
+
+func (schema *JSONSchema) validate(path string, value interface{}, errs error) error {
+	if schema.forbidden {
+		return multierror.Append(errs, fmt.Errorf("%s: not allowed", path))
+	}
+
+	if len(schema.Type) > 0 && !schema.matchesType(value) {
+		return multierror.Append(errs, fmt.Errorf("%s: expected %s, found %s", path, strings.Join(schema.Type, " or "), getJSONType(value)))
+	}
+
+	if len(schema.Enum) > 0 && !schema.matchesEnum(value) {
+		errs = multierror.Append(errs, fmt.Errorf("%s: value %v is not one of the allowed values", path, value))
+	}
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for _, name := range schema.Required {
+			if _, ok := typed[name]; !ok {
+				errs = multierror.Append(errs, fmt.Errorf("%s: missing required property '%s'", path, name))
+			}
+		}
+
+		// Properties are checked in order, so that the errors are reported consistently.
+		names := make([]string, 0, len(typed))
+		for name := range typed {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			propertySchema, ok := schema.Properties[name]
+			if !ok {
+				propertySchema = schema.AdditionalProperties
+			}
+			if propertySchema != nil {
+				errs = propertySchema.validate(path+"."+name, typed[name], errs)
+			}
+		}
+	case []interface{}:
+		if schema.Items != nil {
+			for i, item := range typed {
+				errs = schema.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, errs)
+			}
+		}
+	}
+
+	return errs
+}
+
+func (schema *JSONSchema) matchesType(value interface{}) bool {
+	valueType := getJSONType(value)
+	for _, schemaType := range schema.Type {
+		if schemaType == valueType {
+			return true
+		}
+
+		// Every integer is also a number.
+		if schemaType == "number" && valueType == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+func (schema *JSONSchema) matchesEnum(value interface{}) bool {
+	for _, allowed := range schema.Enum {
+		// Enum values are decoded without UseNumber, so numbers are compared by their float value.
+		if number, ok := value.(json.Number); ok {
+			if f, err := number.Float64(); err == nil && reflect.DeepEqual(f, allowed) {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(value, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// getJSONType returns the JSON Schema type name of a value decoded with UseNumber.
+func getJSONType(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := typed.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
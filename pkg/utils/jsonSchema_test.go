@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+const testJSONSchema = `{
+	"type": "array",
+	"items": {
+		"type": "object",
+		"required": ["name", "count", "kind"],
+		"additionalProperties": false,
+		"properties": {
+			"name": {"type": "string"},
+			"count": {"type": "integer"},
+			"ratio": {"type": "number"},
+			"kind": {"enum": ["webhook", "quota"]},
+			"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+			"lines": {"type": ["array", "null"], "items": {"type": "string"}}
+		}
+	}
+}`
+
+func TestJSONSchemaValidate(t *testing.T) {
+	schema, err := ParseJSONSchema([]byte(testJSONSchema))
+	if err != nil {
+		t.Fatalf("ParseJSONSchema() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		document   string
+		wantErrors []string
+	}{
+		{
+			name:       "conforming",
+			document:   `[{"name":"a","count":1,"ratio":0.5,"kind":"webhook","labels":{"app":"x"},"lines":["l1"]},{"name":"b","count":0,"ratio":2,"kind":"quota","lines":null}]`,
+			wantErrors: nil,
+		},
+		{
+			name:       "empty array",
+			document:   `[]`,
+			wantErrors: nil,
+		},
+		{
+			name:       "wrong root type",
+			document:   `{"name":"a"}`,
+			wantErrors: []string{"$: expected array, found object"},
+		},
+		{
+			name:     "non-conforming items",
+			document: `[{"name":1,"count":1.5,"kind":"other","extra":true},{"count":2,"kind":"quota","labels":{"app":3},"lines":[1]}]`,
+			wantErrors: []string{
+				"$[0].count: expected integer, found number",
+				"$[0].extra: not allowed",
+				"$[0].kind: value other is not one of the allowed values",
+				"$[0].name: expected string, found integer",
+				"$[1]: missing required property 'name'",
+				"$[1].labels.app: expected string, found integer",
+				"$[1].lines[0]: expected string, found integer",
+			},
+		},
+		{
+			name:       "invalid JSON",
+			document:   `[{"name":`,
+			wantErrors: []string{"invalid JSON"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema.Validate([]byte(tt.document))
+			if len(tt.wantErrors) == 0 {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected errors %v", tt.wantErrors)
+			}
+			for _, want := range tt.wantErrors {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("expected error to contain %q, found: %v", want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestParseJSONSchemaInvalid(t *testing.T) {
+	for _, schema := range []string{`{"type": 1}`, `{"properties": []}`, `not json`} {
+		if _, err := ParseJSONSchema([]byte(schema)); err == nil {
+			t.Errorf("expected error parsing schema %s", schema)
+		}
+	}
+}
@@ -14,40 +14,116 @@ type KnownFilePaths struct {
 	AzureStackCertHost      string
 	AzureStackCertContainer string
 	NodeLogsList            string
+	Proc                    string
+	AppArmorEnabled         string
+	AppArmorProfiles        string
+	SELinuxEnforce          string
+	SeccompProfiles         string
+	Cgroup                  string
+	HugePages               string
+	SystemdRuntime          string
+	JournaldRuntime         string
+	AzureCNIState           string
+	AzureCNIIPAMState       string
+	AzureCNILog             string
+	KubeletCertificates     string
+	KubeletPods             string
+	PodLogs                 string
+	KubernetesCertificates  string
+	NvidiaControlDevice     string
 	Config                  string
 	Secret                  string
+	ServiceAccountNamespace string
 }
 
 type ConfigKey string
 type SecretKey string
 
 const (
-	CollectorListKey     ConfigKey = "COLLECTOR_LIST"
-	ContainerLogsListKey ConfigKey = "DIAGNOSTIC_CONTAINERLOGS_LIST"
-	KubeObjectsListKey   ConfigKey = "DIAGNOSTIC_KUBEOBJECTS_LIST"
-	NodeLogsLinuxKey     ConfigKey = "DIAGNOSTIC_NODELOGS_LIST_LINUX"
-	NodeLogsWindowsKey   ConfigKey = "DIAGNOSTIC_NODELOGS_LIST_WINDOWS"
-	RunIdKey             ConfigKey = "DIAGNOSTIC_RUN_ID"
+	AnonymizeNamesKey          ConfigKey = "DIAGNOSTIC_ANONYMIZE_NAMES"
+	AnonymizeSaltFileKey       ConfigKey = "DIAGNOSTIC_ANONYMIZE_SALT_FILE"
+	AzureBlobBufferSizeKey     ConfigKey = "AZURE_BLOB_UPLOAD_BUFFER_SIZE"
+	AzureBlobMaxBuffersKey     ConfigKey = "AZURE_BLOB_UPLOAD_MAX_BUFFERS"
+	CollectorListKey           ConfigKey = "COLLECTOR_LIST"
+	CollectorConcurrencyKey    ConfigKey = "COLLECTOR_CONCURRENCY"
+	CollectorTimeoutKey        ConfigKey = "COLLECTOR_TIMEOUT"
+	CollectorMaxBytesKey       ConfigKey = "COLLECTOR_MAX_BYTES"
+	CollectorAPIQPSKey         ConfigKey = "COLLECTOR_API_QPS"
+	CollectorAPIBurstKey       ConfigKey = "COLLECTOR_API_BURST"
+	CollectorStartJitterKey    ConfigKey = "COLLECTOR_START_JITTER"
+	CollectorHeartbeatKey      ConfigKey = "COLLECTOR_HEARTBEAT_INTERVAL"
+	ClockSkewNTPServerKey      ConfigKey = "DIAGNOSTIC_CLOCK_SKEW_NTP_SERVER"
+	ClockSkewThresholdKey      ConfigKey = "DIAGNOSTIC_CLOCK_SKEW_THRESHOLD"
+	ContainerLogsListKey       ConfigKey = "DIAGNOSTIC_CONTAINERLOGS_LIST"
+	ContainerLogsSinceKey      ConfigKey = "DIAGNOSTIC_CONTAINERLOGS_SINCE"
+	ContainerLogsTailLinesKey  ConfigKey = "DIAGNOSTIC_CONTAINERLOGS_TAIL_LINES"
+	DeprecatedResourcesKey     ConfigKey = "DIAGNOSTIC_DEPRECATED_RESOURCES"
+	DmesgSinceKey              ConfigKey = "DIAGNOSTIC_DMESG_SINCE"
+	EventTimelineNamespaceKey  ConfigKey = "DIAGNOSTIC_EVENT_TIMELINE_NAMESPACE"
+	EventTimelineSelectorKey   ConfigKey = "DIAGNOSTIC_EVENT_TIMELINE_SELECTOR"
+	EventTimelineWindowKey     ConfigKey = "DIAGNOSTIC_EVENT_TIMELINE_WINDOW"
+	ExcludeKeysKey             ConfigKey = "DIAGNOSTIC_EXCLUDE_KEYS"
+	ExportArchiveKey           ConfigKey = "EXPORT_ARCHIVE"
+	ExportEncryptionKeyFileKey ConfigKey = "EXPORT_ENCRYPTION_KEY_FILE"
+	ExportExistingKey          ConfigKey = "EXPORT_EXISTING"
+	ExportRunIdKey             ConfigKey = "EXPORT_RUN_ID"
+	ExportTargetsKey           ConfigKey = "EXPORT_TARGETS"
+	GCSExportBucketKey         ConfigKey = "GCS_EXPORT_BUCKET"
+	GCSExportCredentialsKey    ConfigKey = "GCS_EXPORT_CREDENTIALS_FILE"
+	HelmReleaseValuesKey       ConfigKey = "DIAGNOSTIC_HELM_RELEASE_VALUES"
+	HTTPExportArchiveKey       ConfigKey = "HTTP_EXPORT_ARCHIVE"
+	HTTPExportCAFileKey        ConfigKey = "HTTP_EXPORT_CA_FILE"
+	HTTPExportClientCertKey    ConfigKey = "HTTP_EXPORT_CLIENT_CERT_FILE"
+	HTTPExportClientKeyKey     ConfigKey = "HTTP_EXPORT_CLIENT_KEY_FILE"
+	HTTPExportHeadersKey       ConfigKey = "HTTP_EXPORT_HEADERS"
+	HTTPExportPinnedCertsKey   ConfigKey = "HTTP_EXPORT_PINNED_SHA256"
+	HTTPExportTimeoutKey       ConfigKey = "HTTP_EXPORT_TIMEOUT"
+	KubeObjectsListKey         ConfigKey = "DIAGNOSTIC_KUBEOBJECTS_LIST"
+	LocalExportPathKey         ConfigKey = "LOCAL_EXPORT_PATH"
+	MTUProbeTargetKey          ConfigKey = "DIAGNOSTIC_MTU_PROBE_TARGET"
+	NodeLogsJournalSinceKey    ConfigKey = "DIAGNOSTIC_NODELOGS_JOURNAL_SINCE"
+	NodeLogsLinuxKey           ConfigKey = "DIAGNOSTIC_NODELOGS_LIST_LINUX"
+	NodeLogsWindowsKey         ConfigKey = "DIAGNOSTIC_NODELOGS_LIST_WINDOWS"
+	OutputSchemaValidationKey  ConfigKey = "DIAGNOSTIC_OUTPUT_SCHEMA_VALIDATION"
+	PVCPathKey                 ConfigKey = "DIAGNOSTIC_PVC_PATH"
+	RBACChecksKey              ConfigKey = "DIAGNOSTIC_RBAC_CHECKS"
+	RedactPatternsKey          ConfigKey = "DIAGNOSTIC_REDACT_PATTERNS"
+	RedactSecretsKey           ConfigKey = "DIAGNOSTIC_REDACT_SECRETS"
+	RunIdKey                   ConfigKey = "DIAGNOSTIC_RUN_ID"
+	ScheduledEventsWindowKey   ConfigKey = "DIAGNOSTIC_SCHEDULED_EVENTS_WINDOW"
+	SelfProfilesKey            ConfigKey = "DIAGNOSTIC_SELF_PROFILES"
+	SysctlKeysKey              ConfigKey = "DIAGNOSTIC_SYSCTL_KEYS"
+	SystemComponentsKey        ConfigKey = "DIAGNOSTIC_SYSTEM_COMPONENTS"
+	SystemComponentLogLinesKey ConfigKey = "DIAGNOSTIC_SYSTEM_COMPONENT_LOG_LINES"
+	SystemdUnitsKey            ConfigKey = "DIAGNOSTIC_SYSTEMD_UNITS"
+	TargetNodeKey              ConfigKey = "DIAGNOSTIC_TARGET_NODE"
+	ValidateCompletenessKey    ConfigKey = "DIAGNOSTIC_VALIDATE_COMPLETENESS"
 )
 
 const (
-	AccountNameKey   SecretKey = "AZURE_BLOB_ACCOUNT_NAME"
-	SasTokenKey      SecretKey = "AZURE_BLOB_SAS_KEY"
-	ContainerNameKey SecretKey = "AZURE_BLOB_CONTAINER_NAME"
-	SasTokenTypeKey  SecretKey = "AZURE_STORAGE_SAS_KEY_TYPE"
+	AccountNameKey     SecretKey = "AZURE_BLOB_ACCOUNT_NAME"
+	SasTokenKey        SecretKey = "AZURE_BLOB_SAS_KEY"
+	ContainerNameKey   SecretKey = "AZURE_BLOB_CONTAINER_NAME"
+	SasTokenTypeKey    SecretKey = "AZURE_STORAGE_SAS_KEY_TYPE"
+	HTTPExportURLKey   SecretKey = "HTTP_EXPORT_URL"
+	HTTPExportTokenKey SecretKey = "HTTP_EXPORT_TOKEN"
 )
 
+// The namespace of the pod, as mounted with its service account token.
+const serviceAccountNamespacePath = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
 // GetKnownFilePaths get known file paths
 func GetKnownFilePaths(osIdentifier OSIdentifier) (*KnownFilePaths, error) {
 	switch osIdentifier {
 	case Windows:
 		return &KnownFilePaths{
-			AzureJson:           "/k/azure.json",
-			AzureStackCloudJson: "/k/azurestackcloud.json",
-			WindowsLogsOutput:   "/k/periscope-diagnostic-output",
-			NodeLogsList:        "/config/" + string(NodeLogsWindowsKey),
-			Config:              "/config",
-			Secret:              "/secret",
+			AzureJson:               "/k/azure.json",
+			AzureStackCloudJson:     "/k/azurestackcloud.json",
+			WindowsLogsOutput:       "/k/periscope-diagnostic-output",
+			NodeLogsList:            "/config/" + string(NodeLogsWindowsKey),
+			Config:                  "/config",
+			Secret:                  "/secret",
+			ServiceAccountNamespace: serviceAccountNamespacePath,
 		}, nil
 	case Linux:
 		// Since Azure Stack Hub does not support multiple node pools, we assume we don't need to worry about this for Windows
@@ -60,8 +136,26 @@ func GetKnownFilePaths(osIdentifier OSIdentifier) (*KnownFilePaths, error) {
 			AzureStackCertHost:      "/etchostlogs/ssl/certs/azsCertificate.pem",
 			AzureStackCertContainer: "/etc/ssl/certs/azsCertificate.pem",
 			NodeLogsList:            "/config/" + string(NodeLogsLinuxKey),
+			Proc:                    "/proc",
+			AppArmorEnabled:         "/sys/module/apparmor/parameters/enabled",
+			AppArmorProfiles:        "/proc/1/root/sys/kernel/security/apparmor/profiles",
+			SELinuxEnforce:          "/sys/fs/selinux/enforce",
+			SeccompProfiles:         "/proc/1/root/var/lib/kubelet/seccomp",
+			Cgroup:                  "/proc/1/root/sys/fs/cgroup",
+			HugePages:               "/sys/kernel/mm/hugepages",
+			SystemdRuntime:          "/proc/1/root/run/systemd/system",
+			JournaldRuntime:         "/proc/1/root/run/systemd/journal",
+			AzureCNIState:           "/proc/1/root/var/run/azure-vnet.json",
+			AzureCNIIPAMState:       "/proc/1/root/var/run/azure-vnet-ipam.json",
+			AzureCNILog:             "/var/log/azure-vnet.log",
+			KubeletCertificates:     "/proc/1/root/var/lib/kubelet/pki",
+			KubeletPods:             "/proc/1/root/var/lib/kubelet/pods",
+			PodLogs:                 "/var/log/pods",
+			KubernetesCertificates:  "/etchostlogs/kubernetes/certs",
+			NvidiaControlDevice:     "/proc/1/root/dev/nvidiactl",
 			Config:                  "/config",
 			Secret:                  "/secret",
+			ServiceAccountNamespace: serviceAccountNamespacePath,
 		}, nil
 	default:
 		return nil, fmt.Errorf("unexpected OS: %s", osIdentifier)
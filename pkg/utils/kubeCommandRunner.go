@@ -160,16 +160,22 @@ func (runner *KubeCommandRunner) GetUnstructuredItem(gvr *schema.GroupVersionRes
 // PrintAsJson takes the Unstructured representation or one or more resources and serializes them as formatted JSON.
 // If the input is a List type, it replaces the Kind/APIVersion with a generic 'List' Kind (as kubectl does).
 func (runner *KubeCommandRunner) PrintAsJson(obj runtime.Unstructured) (string, error) {
-	return runner.serialize(obj, &printers.JSONPrinter{})
+	return PrintUnstructuredAsJson(obj)
+}
+
+// PrintUnstructuredAsJson serializes resources in the same way as PrintAsJson. It doesn't require a
+// KubeCommandRunner, so can be used with other clients.
+func PrintUnstructuredAsJson(obj runtime.Unstructured) (string, error) {
+	return serialize(obj, &printers.JSONPrinter{})
 }
 
 // PrintAsYaml takes the Unstructured representation or one or more resources and serializes them as formatted YAML.
 // If the input is a List type, it replaces the Kind/APIVersion with a generic 'List' Kind (as kubectl does).
 func (runner *KubeCommandRunner) PrintAsYaml(obj runtime.Unstructured) (string, error) {
-	return runner.serialize(obj, &printers.YAMLPrinter{})
+	return serialize(obj, &printers.YAMLPrinter{})
 }
 
-func (runner *KubeCommandRunner) serialize(obj runtime.Unstructured, printer printers.ResourcePrinter) (string, error) {
+func serialize(obj runtime.Unstructured, printer printers.ResourcePrinter) (string, error) {
 	list, isList := obj.(*unstructured.UnstructuredList)
 	if isList {
 		list.SetKind("List")
@@ -277,6 +283,12 @@ func (runner *KubeCommandRunner) GetGVRForCRD(crdName string) (*schema.GroupVers
 
 // GetGVRFromCRD takes a CRD in Unstructured form and returns the GroupVersionResource for its resources.
 func (runner *KubeCommandRunner) GetGVRFromCRD(crd *unstructured.Unstructured) (*schema.GroupVersionResource, error) {
+	return GetStorageGVRFromCRD(crd)
+}
+
+// GetStorageGVRFromCRD takes a CRD in Unstructured form and returns the GroupVersionResource for its resources,
+// using the 'storage' version. It doesn't require a KubeCommandRunner, so can be used with other clients.
+func GetStorageGVRFromCRD(crd *unstructured.Unstructured) (*schema.GroupVersionResource, error) {
 	// The name of a CRD is of the form 'resource.group', so that gives us 2/3 of the GVR.
 	name := crd.GetName()
 	groupResource := schema.ParseGroupResource(name)
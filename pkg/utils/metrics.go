@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// MetricsScraper returns metrics in the Prometheus text exposition format.
+type MetricsScraper func() (string, error)
+
+// NewAPIServerMetricsScraper returns a MetricsScraper which reads the metrics exposed by the API server.
+func NewAPIServerMetricsScraper(clientset kubernetes.Interface) MetricsScraper {
+	return func() (string, error) {
+		data, err := clientset.CoreV1().RESTClient().Get().AbsPath("/metrics").DoRaw(context.Background())
+		if err != nil {
+			return "", fmt.Errorf("error reading API server metrics: %w", err)
+		}
+
+		return string(data), nil
+	}
+}
+
+// PodMetricsScraper returns the metrics exposed on a port of a pod, in the Prometheus text exposition format.
+type PodMetricsScraper func(namespace, pod, port string) (string, error)
+
+// NewPodMetricsScraper returns a PodMetricsScraper which reads the metrics of a pod through the API server proxy,
+// so that they can be read from nodes which can't reach the pod network directly.
+func NewPodMetricsScraper(clientset kubernetes.Interface) PodMetricsScraper {
+	return func(namespace, pod, port string) (string, error) {
+		data, err := clientset.CoreV1().Pods(namespace).ProxyGet("http", pod, port, "/metrics", nil).DoRaw(context.Background())
+		if err != nil {
+			return "", fmt.Errorf("error reading metrics of pod %s/%s: %w", namespace, pod, err)
+		}
+
+		return string(data), nil
+	}
+}
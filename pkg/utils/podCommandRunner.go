@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// podCommandTimeout bounds each command, so that a hung process in the container doesn't hold up collection.
+const podCommandTimeout = 60 * time.Second
+
+// PodCommandRunner runs a command in a container of a pod, returning its standard output.
+type PodCommandRunner func(namespace, pod, container string, command ...string) (string, error)
+
+// NewPodCommandRunner returns a PodCommandRunner which runs commands through the exec subresource of the API server,
+// as 'kubectl exec' does.
+func NewPodCommandRunner(config *rest.Config, clientset kubernetes.Interface) PodCommandRunner {
+	return func(namespace, pod, container string, command ...string) (string, error) {
+		request := clientset.CoreV1().RESTClient().Post().
+			Resource("pods").
+			Namespace(namespace).
+			Name(pod).
+			SubResource("exec").
+			VersionedParams(&corev1.PodExecOptions{
+				Container: container,
+				Command:   command,
+				Stdout:    true,
+				Stderr:    true,
+			}, scheme.ParameterCodec)
+
+		executor, err := remotecommand.NewSPDYExecutor(config, "POST", request.URL())
+		if err != nil {
+			return "", fmt.Errorf("error creating executor for pod %s/%s: %w", namespace, pod, err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), podCommandTimeout)
+		defer cancel()
+
+		var stdout, stderr bytes.Buffer
+		err = executor.StreamWithContext(ctx, remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr})
+		if err != nil {
+			return "", fmt.Errorf("error running %s in pod %s/%s: %w (stderr: %s)", strings.Join(command, " "), namespace, pod, err, strings.TrimSpace(stderr.String()))
+		}
+
+		return stdout.String(), nil
+	}
+}
@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+)
+
+const redactionMarker = "[REDACTED]"
+
+// Built-in patterns for secrets which are commonly found in manifests and logs.
+var defaultRedactionPatterns = []*regexp.Regexp{
+	// PEM-encoded private keys (RSA, EC, OpenSSH, PKCS#8, encrypted...)
+	regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`),
+	// JSON Web Tokens (header and payload are base64url-encoded JSON objects)
+	regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+	// Bearer tokens in HTTP headers
+	regexp.MustCompile(`(?i)\bBearer\s+[A-Za-z0-9._~+/-]+=*`),
+	// Keys in Azure Storage, Service Bus and Event Hubs connection strings
+	regexp.MustCompile(`(?i)\b(AccountKey|SharedAccessKey|SharedAccessSignature)=[^;"'\s]+`),
+	// Signatures in SAS URLs
+	regexp.MustCompile(`(?i)\bsig=[A-Za-z0-9%/+=]+`),
+	// Long base64 strings, such as the values of Secrets. The minimum length avoids matching hex digests and IDs.
+	regexp.MustCompile(`[A-Za-z0-9+/]{100,}={0,2}`),
+}
+
+// Redactor replaces content matching secret patterns with a redaction marker.
+type Redactor struct {
+	patterns []*regexp.Regexp
+}
+
+// NewRedactor creates a Redactor using the built-in patterns along with any additional patterns.
+func NewRedactor(additionalPatterns []*regexp.Regexp) *Redactor {
+	patterns := make([]*regexp.Regexp, 0, len(defaultRedactionPatterns)+len(additionalPatterns))
+	patterns = append(patterns, defaultRedactionPatterns...)
+	patterns = append(patterns, additionalPatterns...)
+	return &Redactor{
+		patterns: patterns,
+	}
+}
+
+func (r *Redactor) Redact(content string) string {
+	for _, pattern := range r.patterns {
+		content = pattern.ReplaceAllLiteralString(content, redactionMarker)
+	}
+	return content
+}
+
+// RedactingDataValue wraps a DataValue, redacting secrets from its content.
+// Patterns may span lines, so the whole content is read and redacted (once) when first accessed.
+type RedactingDataValue struct {
+	value    interfaces.DataValue
+	redactor *Redactor
+	once     sync.Once
+	content  string
+	err      error
+}
+
+func NewRedactingDataValue(value interfaces.DataValue, redactor *Redactor) *RedactingDataValue {
+	return &RedactingDataValue{
+		value:    value,
+		redactor: redactor,
+	}
+}
+
+func (v *RedactingDataValue) GetLength() int64 {
+	content, err := v.getContent()
+	if err != nil {
+		// The error will be surfaced by GetReader.
+		return 0
+	}
+	return int64(len(content))
+}
+
+func (v *RedactingDataValue) GetReader() (io.ReadCloser, error) {
+	content, err := v.getContent()
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(strings.NewReader(content)), nil
+}
+
+func (v *RedactingDataValue) getContent() (string, error) {
+	v.once.Do(func() {
+		var content string
+		content, v.err = GetContent(v.value.GetReader)
+		v.content = v.redactor.Redact(content)
+	})
+	return v.content, v.err
+}
+
+// RedactingDataProducer wraps a DataProducer, redacting secrets from each of its data values.
+type RedactingDataProducer struct {
+	producer interfaces.DataProducer
+	redactor *Redactor
+}
+
+// NewRedactingDataProducer creates a DataProducer whose values have secrets redacted.
+// A nil redactor means the values are returned unchanged.
+func NewRedactingDataProducer(producer interfaces.DataProducer, redactor *Redactor) *RedactingDataProducer {
+	return &RedactingDataProducer{
+		producer: producer,
+		redactor: redactor,
+	}
+}
+
+func (p *RedactingDataProducer) GetName() string {
+	return p.producer.GetName()
+}
+
+func (p *RedactingDataProducer) GetData() map[string]interfaces.DataValue {
+	data := p.producer.GetData()
+	if p.redactor == nil {
+		return data
+	}
+
+	result := make(map[string]interfaces.DataValue, len(data))
+	for key, value := range data {
+		result[key] = NewRedactingDataValue(value, p.redactor)
+	}
+
+	return result
+}
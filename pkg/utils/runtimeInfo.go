@@ -1,14 +1,22 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Azure/aks-periscope/pkg/interfaces"
 	"github.com/hashicorp/go-multierror"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 type Feature string
@@ -17,6 +25,126 @@ const (
 	WindowsHpc Feature = "WINHPC"
 )
 
+const defaultHTTPExportTimeout = 60 * time.Second
+
+const defaultLocalExportPath = "/var/log/aks-periscope"
+
+var defaultSystemdUnits = []string{"kubelet", "containerd", "walinuxagent"}
+
+// The sysctls reported individually by default, and checked for values known to cause failures.
+var defaultSysctlKeys = []string{
+	"net.ipv4.ip_forward",
+	"net.bridge.bridge-nf-call-iptables",
+	"net.netfilter.nf_conntrack_max",
+	"net.netfilter.nf_conntrack_count",
+	"fs.inotify.max_user_watches",
+	"fs.inotify.max_user_instances",
+	"fs.file-nr",
+	"kernel.pid_max",
+	"vm.max_map_count",
+	"net.core.somaxconn",
+}
+
+// The kube-system workloads whose logs are collected by default, as kind/name.
+var defaultSystemComponents = []string{"deployment/coredns", "deployment/metrics-server", "deployment/konnectivity-agent", "daemonset/azure-ip-masq-agent"}
+
+const defaultSystemComponentLogLines = 500
+
+// The deprecated APIs which are listed to find out whether the API server warns about them, as
+// <group>/<version>/<resource> (or <version>/<resource> for the core group). APIs which are no longer served are
+// reported as such.
+var defaultDeprecatedResources = []string{
+	"v1/componentstatuses",
+	"batch/v1beta1/cronjobs",
+	"policy/v1beta1/poddisruptionbudgets",
+	"policy/v1beta1/podsecuritypolicies",
+	"autoscaling/v2beta1/horizontalpodautoscalers",
+	"autoscaling/v2beta2/horizontalpodautoscalers",
+	"discovery.k8s.io/v1beta1/endpointslices",
+	"storage.k8s.io/v1beta1/csistoragecapacities",
+	"flowcontrol.apiserver.k8s.io/v1beta2/flowschemas",
+	"flowcontrol.apiserver.k8s.io/v1beta2/prioritylevelconfigurations",
+	"flowcontrol.apiserver.k8s.io/v1beta3/flowschemas",
+	"flowcontrol.apiserver.k8s.io/v1beta3/prioritylevelconfigurations",
+}
+
+const defaultContainerLogsTailLines = 100
+
+const defaultEventTimelineWindow = time.Hour
+
+// Journal units in the node logs list are read as far back as this by default, since a node's whole journal can be
+// very large.
+const defaultNodeLogsJournalSince = 24 * time.Hour
+
+const defaultCollectorHeartbeat = 30 * time.Second
+
+const defaultClockSkewThreshold = time.Second
+
+// Uploads to Azure Blob storage are read into this many buffers of this size, which are uploaded as blocks in
+// parallel, so the memory used is their product. A blob can have at most 50,000 blocks, so the buffer size also
+// limits the size of a blob (200 GiB by default).
+const (
+	defaultAzureBlobBufferSize = 4 * 1024 * 1024
+	defaultAzureBlobMaxBuffers = 4
+
+	minAzureBlobBufferSize = 1024 * 1024
+	maxAzureBlobBufferSize = 100 * 1024 * 1024
+	maxAzureBlobMaxBuffers = 32
+)
+
+// The client-side rate limit shared by all API clients. Every node runs its own Periscope pod, so the load on the
+// API server scales with the size of the cluster.
+const (
+	defaultCollectorAPIQPS   = 10
+	defaultCollectorAPIBurst = 20
+)
+
+// Destinations for exported data, as specified in EXPORT_TARGETS.
+const (
+	ExportTargetAzureBlob = "azureblob"
+	ExportTargetGCS       = "gcs"
+	ExportTargetHTTP      = "http"
+	ExportTargetLocal     = "local"
+	ExportTargetPVC       = "pvc"
+)
+
+// Handling of data which already exists at the export destination, as specified in EXPORT_EXISTING.
+const (
+	ExportExistingOverwrite = "overwrite"
+	ExportExistingSkip      = "skip"
+)
+
+// Handling of collector output which doesn't match its declared schema, as specified in DIAGNOSTIC_OUTPUT_SCHEMA_VALIDATION.
+const (
+	OutputSchemaValidationOff  = "off"
+	OutputSchemaValidationWarn = "warn"
+	OutputSchemaValidationFail = "fail"
+)
+
+// Risk categories summarized by the RBAC security collector, as specified in DIAGNOSTIC_RBAC_CHECKS.
+const (
+	RBACCheckClusterAdmin   = "cluster-admin"
+	RBACCheckHostNamespaces = "host-namespaces"
+	RBACCheckPrivileged     = "privileged"
+	RBACCheckSecretsAccess  = "secrets-access"
+)
+
+func getKnownRBACChecks() []string {
+	return []string{RBACCheckClusterAdmin, RBACCheckHostNamespaces, RBACCheckPrivileged, RBACCheckSecretsAccess}
+}
+
+func getKnownExportTargets() []string {
+	return []string{ExportTargetAzureBlob, ExportTargetGCS, ExportTargetHTTP, ExportTargetLocal, ExportTargetPVC}
+}
+
+func getKnownExportExisting() []string {
+	return []string{ExportExistingOverwrite, ExportExistingSkip}
+}
+
+func getKnownOutputSchemaValidation() []string {
+	return []string{OutputSchemaValidationOff, OutputSchemaValidationWarn, OutputSchemaValidationFail}
+}
+
 func getKnownFeatures() []Feature {
 	return []Feature{WindowsHpc}
 }
@@ -24,14 +152,70 @@ func getKnownFeatures() []Feature {
 type RuntimeInfo struct {
 	RunId                   string
 	HostNodeName            string
+	TargetNode              string
 	CollectorList           []string
+	DisabledCollectors      []string
+	CollectorMaxBytes       int64
+	CollectorConcurrency    int
+	CollectorTimeout        time.Duration
+	CollectorAPIQPS         float32
+	CollectorAPIBurst       int
+	CollectorStartJitter    time.Duration
+	CollectorHeartbeat      time.Duration
+	ClockSkewThreshold      time.Duration
+	ClockSkewNTPServer      string
 	KubernetesObjects       []string
 	NodeLogs                []string
+	NodeLogsJournalSince    time.Duration
 	ContainerLogsNamespaces []string
+	ContainerLogsTailLines  int64
+	ContainerLogsSince      time.Duration
+	DeprecatedResources     []schema.GroupVersionResource
+	DmesgSince              time.Duration
+	EventTimelineNamespace  string
+	EventTimelineSelector   string
+	EventTimelineWindow     time.Duration
+	SystemdUnits            []string
+	SysctlKeys              []string
+	SystemComponents        []string
+	SystemComponentLogLines int64
+	MTUProbeTarget          string
+	RBACChecks              []string
+	ScheduledEventsWindow   time.Duration
+	ExcludeKeys             []string
+	ExportArchive           bool
+	ExportEncryptionKeyFile string
+	ExportRunId             string
+	ExportExisting          string
+	ExportTargets           []string
+	LocalExportPath         string
+	PVCPath                 string
+	GCSExportBucket         string
+	GCSExportCredentials    string
+	HelmReleaseValues       bool
+	SelfProfiles            bool
+	RedactSecrets           bool
+	RedactPatterns          []*regexp.Regexp
+	AnonymizeNames          bool
+	AnonymizeSaltFile       string
+	ValidateCompleteness    bool
+	OutputSchemaValidation  string
 	StorageAccountName      string
 	StorageSasKey           string
 	StorageContainerName    string
 	StorageSasKeyType       string
+	StorageIdentity         *StorageIdentity
+	AzureBlobBufferSize     int
+	AzureBlobMaxBuffers     int
+	HTTPExportURL           string
+	HTTPExportToken         string
+	HTTPExportHeaders       map[string]string
+	HTTPExportTimeout       time.Duration
+	HTTPExportArchive       bool
+	HTTPExportCAFile        string
+	HTTPExportClientCert    string
+	HTTPExportClientKey     string
+	HTTPExportPinnedCerts   []string
 	Features                map[Feature]bool
 }
 
@@ -41,16 +225,70 @@ func GetRuntimeInfo(fs interfaces.FileSystemAccessor, filePaths *KnownFilePaths)
 
 	// Config
 	runId, errs := readFileContent(fs, filePaths.GetConfigPath(RunIdKey), true, errs)
+	targetNode, errs := readFileContent(fs, filePaths.GetConfigPath(TargetNodeKey), false, errs)
 	collectorList, errs := readFileContent(fs, filePaths.GetConfigPath(CollectorListKey), false, errs)
+	collectorMaxBytes, errs := readFileContent(fs, filePaths.GetConfigPath(CollectorMaxBytesKey), false, errs)
+	collectorConcurrency, errs := readFileContent(fs, filePaths.GetConfigPath(CollectorConcurrencyKey), false, errs)
+	collectorTimeout, errs := readFileContent(fs, filePaths.GetConfigPath(CollectorTimeoutKey), false, errs)
+	collectorAPIQPS, errs := readFileContent(fs, filePaths.GetConfigPath(CollectorAPIQPSKey), false, errs)
+	collectorAPIBurst, errs := readFileContent(fs, filePaths.GetConfigPath(CollectorAPIBurstKey), false, errs)
+	collectorStartJitter, errs := readFileContent(fs, filePaths.GetConfigPath(CollectorStartJitterKey), false, errs)
+	collectorHeartbeat, errs := readFileContent(fs, filePaths.GetConfigPath(CollectorHeartbeatKey), false, errs)
+	clockSkewThreshold, errs := readFileContent(fs, filePaths.GetConfigPath(ClockSkewThresholdKey), false, errs)
+	clockSkewNTPServer, errs := readFileContent(fs, filePaths.GetConfigPath(ClockSkewNTPServerKey), false, errs)
 	kubernetesObjects, errs := readFileContent(fs, filePaths.GetConfigPath(KubeObjectsListKey), false, errs)
 	nodeLogs, errs := readFileContent(fs, filePaths.NodeLogsList, false, errs)
+	nodeLogsJournalSince, errs := readFileContent(fs, filePaths.GetConfigPath(NodeLogsJournalSinceKey), false, errs)
 	containerLogsNamespaces, errs := readFileContent(fs, filePaths.GetConfigPath(ContainerLogsListKey), false, errs)
+	containerLogsTailLines, errs := readFileContent(fs, filePaths.GetConfigPath(ContainerLogsTailLinesKey), false, errs)
+	containerLogsSince, errs := readFileContent(fs, filePaths.GetConfigPath(ContainerLogsSinceKey), false, errs)
+	deprecatedResources, errs := readFileContent(fs, filePaths.GetConfigPath(DeprecatedResourcesKey), false, errs)
+	dmesgSince, errs := readFileContent(fs, filePaths.GetConfigPath(DmesgSinceKey), false, errs)
+	eventTimelineNamespace, errs := readFileContent(fs, filePaths.GetConfigPath(EventTimelineNamespaceKey), false, errs)
+	eventTimelineSelector, errs := readFileContent(fs, filePaths.GetConfigPath(EventTimelineSelectorKey), false, errs)
+	eventTimelineWindow, errs := readFileContent(fs, filePaths.GetConfigPath(EventTimelineWindowKey), false, errs)
+	systemdUnits, errs := readFileContent(fs, filePaths.GetConfigPath(SystemdUnitsKey), false, errs)
+	sysctlKeys, errs := readFileContent(fs, filePaths.GetConfigPath(SysctlKeysKey), false, errs)
+	systemComponents, errs := readFileContent(fs, filePaths.GetConfigPath(SystemComponentsKey), false, errs)
+	systemComponentLogLines, errs := readFileContent(fs, filePaths.GetConfigPath(SystemComponentLogLinesKey), false, errs)
+	mtuProbeTarget, errs := readFileContent(fs, filePaths.GetConfigPath(MTUProbeTargetKey), false, errs)
+	rbacChecks, errs := readFileContent(fs, filePaths.GetConfigPath(RBACChecksKey), false, errs)
+	scheduledEventsWindow, errs := readFileContent(fs, filePaths.GetConfigPath(ScheduledEventsWindowKey), false, errs)
+	excludeKeys, errs := readFileContent(fs, filePaths.GetConfigPath(ExcludeKeysKey), false, errs)
+	exportArchive, errs := readFileContent(fs, filePaths.GetConfigPath(ExportArchiveKey), false, errs)
+	exportTargets, errs := readFileContent(fs, filePaths.GetConfigPath(ExportTargetsKey), false, errs)
+	exportRunId, errs := readFileContent(fs, filePaths.GetConfigPath(ExportRunIdKey), false, errs)
+	exportEncryptionKeyFile, errs := readFileContent(fs, filePaths.GetConfigPath(ExportEncryptionKeyFileKey), false, errs)
+	exportExisting, errs := readFileContent(fs, filePaths.GetConfigPath(ExportExistingKey), false, errs)
+	localExportPath, errs := readFileContent(fs, filePaths.GetConfigPath(LocalExportPathKey), false, errs)
+	pvcPath, errs := readFileContent(fs, filePaths.GetConfigPath(PVCPathKey), false, errs)
+	gcsExportBucket, errs := readFileContent(fs, filePaths.GetConfigPath(GCSExportBucketKey), false, errs)
+	gcsExportCredentialsFile, errs := readFileContent(fs, filePaths.GetConfigPath(GCSExportCredentialsKey), false, errs)
+	helmReleaseValues, errs := readFileContent(fs, filePaths.GetConfigPath(HelmReleaseValuesKey), false, errs)
+	selfProfiles, errs := readFileContent(fs, filePaths.GetConfigPath(SelfProfilesKey), false, errs)
+	redactSecrets, errs := readFileContent(fs, filePaths.GetConfigPath(RedactSecretsKey), false, errs)
+	redactPatterns, errs := readFileContent(fs, filePaths.GetConfigPath(RedactPatternsKey), false, errs)
+	anonymizeNames, errs := readFileContent(fs, filePaths.GetConfigPath(AnonymizeNamesKey), false, errs)
+	anonymizeSaltFile, errs := readFileContent(fs, filePaths.GetConfigPath(AnonymizeSaltFileKey), false, errs)
+	validateCompleteness, errs := readFileContent(fs, filePaths.GetConfigPath(ValidateCompletenessKey), false, errs)
+	outputSchemaValidation, errs := readFileContent(fs, filePaths.GetConfigPath(OutputSchemaValidationKey), false, errs)
+	azureBlobBufferSize, errs := readFileContent(fs, filePaths.GetConfigPath(AzureBlobBufferSizeKey), false, errs)
+	azureBlobMaxBuffers, errs := readFileContent(fs, filePaths.GetConfigPath(AzureBlobMaxBuffersKey), false, errs)
+	httpExportArchive, errs := readFileContent(fs, filePaths.GetConfigPath(HTTPExportArchiveKey), false, errs)
+	httpExportHeaders, errs := readFileContent(fs, filePaths.GetConfigPath(HTTPExportHeadersKey), false, errs)
+	httpExportTimeout, errs := readFileContent(fs, filePaths.GetConfigPath(HTTPExportTimeoutKey), false, errs)
+	httpExportCAFile, errs := readFileContent(fs, filePaths.GetConfigPath(HTTPExportCAFileKey), false, errs)
+	httpExportClientCert, errs := readFileContent(fs, filePaths.GetConfigPath(HTTPExportClientCertKey), false, errs)
+	httpExportClientKey, errs := readFileContent(fs, filePaths.GetConfigPath(HTTPExportClientKeyKey), false, errs)
+	httpExportPinnedCerts, errs := readFileContent(fs, filePaths.GetConfigPath(HTTPExportPinnedCertsKey), false, errs)
 
 	// Secret
 	storageAccountName, errs := readFileContent(fs, filePaths.GetSecretPath(AccountNameKey), false, errs)
 	storageSasKey, errs := readFileContent(fs, filePaths.GetSecretPath(SasTokenKey), false, errs)
 	storageContainerName, errs := readFileContent(fs, filePaths.GetSecretPath(ContainerNameKey), false, errs)
 	storageSasKeyType, errs := readFileContent(fs, filePaths.GetSecretPath(SasTokenTypeKey), false, errs)
+	httpExportURL, errs := readFileContent(fs, filePaths.GetSecretPath(HTTPExportURLKey), false, errs)
+	httpExportToken, errs := readFileContent(fs, filePaths.GetSecretPath(HTTPExportTokenKey), false, errs)
 
 	// We can't use `os.Hostname` for this, because this gives us the _container_ hostname (i.e. the pod name, by default).
 	// An earlier approach was to `cat /etc/hostname` but that will not work for Windows containers.
@@ -61,6 +299,12 @@ func GetRuntimeInfo(fs interfaces.FileSystemAccessor, filePaths *KnownFilePaths)
 		errs = multierror.Append(errs, errors.New("variable HOST_NODE_NAME value not set for container"))
 	}
 
+	// An identity for storage access is configured through the environment, as injected by Azure Workload Identity.
+	storageIdentity := GetStorageIdentity()
+	if storageIdentity != nil && len(storageIdentity.FederatedTokenFile) > 0 && len(storageIdentity.TenantID) == 0 {
+		errs = multierror.Append(errs, fmt.Errorf("variable %s is set but %s is not", AzureFederatedTokenFileEnvVar, AzureTenantIDEnvVar))
+	}
+
 	features := map[Feature]bool{}
 	for _, feature := range getKnownFeatures() {
 		featureFilePath := filePaths.GetFeaturePath(feature)
@@ -71,6 +315,223 @@ func GetRuntimeInfo(fs interfaces.FileSystemAccessor, filePaths *KnownFilePaths)
 		}
 	}
 
+	// Validate all typed values up front, so that every malformed value is reported together.
+	maxBytes, errs := parseInt64(CollectorMaxBytesKey, collectorMaxBytes, 0, errs)
+	if maxBytes < 0 {
+		errs = multierror.Append(errs, fmt.Errorf("invalid %s value '%s': must not be negative", CollectorMaxBytesKey, collectorMaxBytes))
+	}
+	concurrency, errs := parseInt64(CollectorConcurrencyKey, collectorConcurrency, 0, errs)
+	if concurrency < 0 {
+		errs = multierror.Append(errs, fmt.Errorf("invalid %s value '%s': must not be negative", CollectorConcurrencyKey, collectorConcurrency))
+	}
+	collectorTimeoutDuration, errs := parseDuration(CollectorTimeoutKey, collectorTimeout, 0, errs)
+	apiQPS, errs := parseFloat64(CollectorAPIQPSKey, collectorAPIQPS, defaultCollectorAPIQPS, errs)
+	if apiQPS <= 0 {
+		errs = multierror.Append(errs, fmt.Errorf("invalid %s value '%s': must be positive", CollectorAPIQPSKey, collectorAPIQPS))
+	}
+	apiBurst, errs := parseInt64(CollectorAPIBurstKey, collectorAPIBurst, defaultCollectorAPIBurst, errs)
+	if apiBurst <= 0 {
+		errs = multierror.Append(errs, fmt.Errorf("invalid %s value '%s': must be positive", CollectorAPIBurstKey, collectorAPIBurst))
+	}
+	startJitter, errs := parseDuration(CollectorStartJitterKey, collectorStartJitter, 0, errs)
+	heartbeat, errs := parseDuration(CollectorHeartbeatKey, collectorHeartbeat, defaultCollectorHeartbeat, errs)
+	clockSkewThresholdDuration, errs := parseDuration(ClockSkewThresholdKey, clockSkewThreshold, defaultClockSkewThreshold, errs)
+	if clockSkewThresholdDuration == 0 {
+		errs = multierror.Append(errs, fmt.Errorf("invalid %s value '%s': must be positive", ClockSkewThresholdKey, clockSkewThreshold))
+	}
+	dmesgSinceDuration, errs := parseDuration(DmesgSinceKey, dmesgSince, 0, errs)
+	journalSinceDuration, errs := parseDuration(NodeLogsJournalSinceKey, nodeLogsJournalSince, defaultNodeLogsJournalSince, errs)
+	eventTimelineWindowDuration, errs := parseDuration(EventTimelineWindowKey, eventTimelineWindow, defaultEventTimelineWindow, errs)
+	if eventTimelineWindowDuration == 0 {
+		errs = multierror.Append(errs, fmt.Errorf("invalid %s value '%s': must be positive", EventTimelineWindowKey, eventTimelineWindow))
+	}
+	eventTimelineSelector = strings.TrimSpace(eventTimelineSelector)
+	if _, err := labels.Parse(eventTimelineSelector); err != nil {
+		errs = multierror.Append(errs, fmt.Errorf("invalid %s value '%s': %w", EventTimelineSelectorKey, eventTimelineSelector, err))
+	}
+	logsTailLines, errs := parseInt64(ContainerLogsTailLinesKey, containerLogsTailLines, defaultContainerLogsTailLines, errs)
+	if logsTailLines <= 0 {
+		errs = multierror.Append(errs, fmt.Errorf("invalid %s value '%s': must be positive", ContainerLogsTailLinesKey, containerLogsTailLines))
+	}
+	logsSinceDuration, errs := parseDuration(ContainerLogsSinceKey, containerLogsSince, 0, errs)
+	componentLogLines, errs := parseInt64(SystemComponentLogLinesKey, systemComponentLogLines, defaultSystemComponentLogLines, errs)
+	if componentLogLines <= 0 {
+		errs = multierror.Append(errs, fmt.Errorf("invalid %s value '%s': must be positive", SystemComponentLogLinesKey, systemComponentLogLines))
+	}
+	scheduledEventsWindowDuration, errs := parseDuration(ScheduledEventsWindowKey, scheduledEventsWindow, 0, errs)
+	if collectorTimeoutDuration > 0 && scheduledEventsWindowDuration >= collectorTimeoutDuration {
+		errs = multierror.Append(errs, fmt.Errorf("invalid %s value '%s': must be less than %s", ScheduledEventsWindowKey, scheduledEventsWindow, CollectorTimeoutKey))
+	}
+	shouldExportArchive, errs := parseBool(ExportArchiveKey, exportArchive, false, errs)
+	includeHelmReleaseValues, errs := parseBool(HelmReleaseValuesKey, helmReleaseValues, false, errs)
+	includeSelfProfiles, errs := parseBool(SelfProfilesKey, selfProfiles, false, errs)
+	shouldRedactSecrets, errs := parseBool(RedactSecretsKey, redactSecrets, false, errs)
+	shouldAnonymizeNames, errs := parseBool(AnonymizeNamesKey, anonymizeNames, false, errs)
+	shouldValidateCompleteness, errs := parseBool(ValidateCompletenessKey, validateCompleteness, false, errs)
+	blobBufferSize, errs := parseInt64(AzureBlobBufferSizeKey, azureBlobBufferSize, defaultAzureBlobBufferSize, errs)
+	if blobBufferSize < minAzureBlobBufferSize || blobBufferSize > maxAzureBlobBufferSize {
+		errs = multierror.Append(errs, fmt.Errorf("invalid %s value '%s': must be between %d and %d bytes", AzureBlobBufferSizeKey, azureBlobBufferSize, minAzureBlobBufferSize, maxAzureBlobBufferSize))
+	}
+	blobMaxBuffers, errs := parseInt64(AzureBlobMaxBuffersKey, azureBlobMaxBuffers, defaultAzureBlobMaxBuffers, errs)
+	if blobMaxBuffers < 1 || blobMaxBuffers > maxAzureBlobMaxBuffers {
+		errs = multierror.Append(errs, fmt.Errorf("invalid %s value '%s': must be between 1 and %d", AzureBlobMaxBuffersKey, azureBlobMaxBuffers, maxAzureBlobMaxBuffers))
+	}
+	includeHTTPExportArchive, errs := parseBool(HTTPExportArchiveKey, httpExportArchive, false, errs)
+	timeout, errs := parseDuration(HTTPExportTimeoutKey, httpExportTimeout, defaultHTTPExportTimeout, errs)
+	if timeout == 0 {
+		errs = multierror.Append(errs, fmt.Errorf("invalid %s value '%s': must be positive", HTTPExportTimeoutKey, httpExportTimeout))
+	}
+
+	// Additional redaction patterns are space-separated regular expressions (use \s to match whitespace).
+	patterns := []*regexp.Regexp{}
+	for _, pattern := range strings.Fields(redactPatterns) {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("invalid %s entry '%s': %w", RedactPatternsKey, pattern, err))
+			continue
+		}
+		patterns = append(patterns, re)
+	}
+
+	// Excluded keys are space-separated glob patterns, validated here so that a typo doesn't silently export data.
+	excludePatterns := strings.Fields(excludeKeys)
+	for _, pattern := range excludePatterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("invalid %s entry '%s': %w", ExcludeKeysKey, pattern, err))
+		}
+	}
+
+	// Without explicit targets, data is exported to the HTTP endpoint if configured, then to a mounted PVC if
+	// configured, then to a GCS bucket if configured, and otherwise to Azure Blob storage.
+	pvcPath = strings.TrimSpace(pvcPath)
+	gcsExportBucket = strings.TrimSpace(gcsExportBucket)
+	targets := strings.Fields(exportTargets)
+	for _, target := range targets {
+		if !Contains(getKnownExportTargets(), target) {
+			errs = multierror.Append(errs, fmt.Errorf("invalid %s entry '%s': expected one of %s", ExportTargetsKey, target, strings.Join(getKnownExportTargets(), ", ")))
+		}
+	}
+	if len(targets) == 0 {
+		if len(strings.TrimSpace(httpExportURL)) > 0 {
+			targets = []string{ExportTargetHTTP}
+		} else if len(pvcPath) > 0 {
+			targets = []string{ExportTargetPVC}
+		} else if len(gcsExportBucket) > 0 {
+			targets = []string{ExportTargetGCS}
+		} else {
+			targets = []string{ExportTargetAzureBlob}
+		}
+	}
+	if Contains(targets, ExportTargetHTTP) && len(strings.TrimSpace(httpExportURL)) == 0 {
+		errs = multierror.Append(errs, fmt.Errorf("%s includes '%s' but %s is not set", ExportTargetsKey, ExportTargetHTTP, HTTPExportURLKey))
+	}
+	if Contains(targets, ExportTargetPVC) && len(pvcPath) == 0 {
+		errs = multierror.Append(errs, fmt.Errorf("%s includes '%s' but %s is not set", ExportTargetsKey, ExportTargetPVC, PVCPathKey))
+	}
+	if Contains(targets, ExportTargetGCS) && len(gcsExportBucket) == 0 {
+		errs = multierror.Append(errs, fmt.Errorf("%s includes '%s' but %s is not set", ExportTargetsKey, ExportTargetGCS, GCSExportBucketKey))
+	}
+
+	// Output is added to that of an earlier run if its identifier is given, and otherwise exported under this run.
+	exportRunId = strings.TrimSpace(exportRunId)
+	if len(exportRunId) == 0 {
+		exportRunId = runId
+	}
+	exportExisting = strings.TrimSpace(exportExisting)
+	if len(exportExisting) == 0 {
+		exportExisting = ExportExistingOverwrite
+	} else if !Contains(getKnownExportExisting(), exportExisting) {
+		errs = multierror.Append(errs, fmt.Errorf("invalid %s value '%s': expected one of %s", ExportExistingKey, exportExisting, strings.Join(getKnownExportExisting(), ", ")))
+	}
+
+	// Collector output is only checked against its schema when asked, since it means parsing all of it again.
+	outputSchemaValidation = strings.TrimSpace(outputSchemaValidation)
+	if len(outputSchemaValidation) == 0 {
+		outputSchemaValidation = OutputSchemaValidationOff
+	} else if !Contains(getKnownOutputSchemaValidation(), outputSchemaValidation) {
+		errs = multierror.Append(errs, fmt.Errorf("invalid %s value '%s': expected one of %s", OutputSchemaValidationKey, outputSchemaValidation, strings.Join(getKnownOutputSchemaValidation(), ", ")))
+	}
+
+	units := strings.Fields(systemdUnits)
+	if len(units) == 0 {
+		units = defaultSystemdUnits
+	}
+
+	sysctls := strings.Fields(sysctlKeys)
+	if len(sysctls) == 0 {
+		sysctls = defaultSysctlKeys
+	}
+
+	components := strings.Fields(systemComponents)
+	if len(components) == 0 {
+		components = defaultSystemComponents
+	}
+	for _, component := range components {
+		kind, name, ok := strings.Cut(component, "/")
+		if !ok || len(name) == 0 || (kind != "deployment" && kind != "daemonset") {
+			errs = multierror.Append(errs, fmt.Errorf("invalid %s entry '%s': expected deployment/<name> or daemonset/<name>", SystemComponentsKey, component))
+		}
+	}
+
+	deprecatedResourceEntries := strings.Fields(deprecatedResources)
+	if len(deprecatedResourceEntries) == 0 {
+		deprecatedResourceEntries = defaultDeprecatedResources
+	}
+	deprecatedGVRs := []schema.GroupVersionResource{}
+	for _, entry := range deprecatedResourceEntries {
+		gvr, err := parseGroupVersionResource(entry)
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("invalid %s entry '%s': %w", DeprecatedResourcesKey, entry, err))
+			continue
+		}
+		deprecatedGVRs = append(deprecatedGVRs, gvr)
+	}
+
+	checks := strings.Fields(rbacChecks)
+	if len(checks) == 0 {
+		checks = getKnownRBACChecks()
+	}
+	for _, check := range checks {
+		if !Contains(getKnownRBACChecks(), check) {
+			errs = multierror.Append(errs, fmt.Errorf("invalid %s entry '%s': expected one of %s", RBACChecksKey, check, strings.Join(getKnownRBACChecks(), ", ")))
+		}
+	}
+
+	localExportPath = strings.TrimSpace(localExportPath)
+	if len(localExportPath) == 0 {
+		localExportPath = defaultLocalExportPath
+	}
+
+	// Headers are space-separated Name=Value pairs.
+	headers := map[string]string{}
+	for _, header := range strings.Fields(httpExportHeaders) {
+		name, value, ok := strings.Cut(header, "=")
+		if !ok || len(name) == 0 {
+			errs = multierror.Append(errs, fmt.Errorf("invalid %s entry '%s': expected Name=Value", HTTPExportHeadersKey, header))
+			continue
+		}
+		headers[name] = value
+	}
+
+	// A client certificate for mutual TLS is only usable with its key.
+	httpExportClientCert = strings.TrimSpace(httpExportClientCert)
+	httpExportClientKey = strings.TrimSpace(httpExportClientKey)
+	if (len(httpExportClientCert) == 0) != (len(httpExportClientKey) == 0) {
+		errs = multierror.Append(errs, fmt.Errorf("%s and %s must be set together", HTTPExportClientCertKey, HTTPExportClientKeyKey))
+	}
+
+	// Pinned certificates are space-separated SHA-256 fingerprints, in hex with or without colons (as output by
+	// `openssl x509 -noout -fingerprint -sha256`).
+	pinnedCerts := []string{}
+	for _, pin := range strings.Fields(httpExportPinnedCerts) {
+		fingerprint := strings.ToLower(strings.ReplaceAll(pin, ":", ""))
+		if decoded, err := hex.DecodeString(fingerprint); err != nil || len(decoded) != sha256.Size {
+			errs = multierror.Append(errs, fmt.Errorf("invalid %s entry '%s': expected a hex SHA-256 fingerprint", HTTPExportPinnedCertsKey, pin))
+			continue
+		}
+		pinnedCerts = append(pinnedCerts, fingerprint)
+	}
+
 	if errs != nil {
 		return nil, errs
 	}
@@ -78,14 +539,69 @@ func GetRuntimeInfo(fs interfaces.FileSystemAccessor, filePaths *KnownFilePaths)
 	return &RuntimeInfo{
 		RunId:                   runId,
 		HostNodeName:            hostName,
+		TargetNode:              strings.TrimSpace(targetNode),
 		CollectorList:           strings.Fields(collectorList),
+		CollectorMaxBytes:       maxBytes,
+		CollectorConcurrency:    int(concurrency),
+		CollectorTimeout:        collectorTimeoutDuration,
+		CollectorAPIQPS:         float32(apiQPS),
+		CollectorAPIBurst:       int(apiBurst),
+		CollectorStartJitter:    startJitter,
+		CollectorHeartbeat:      heartbeat,
+		ClockSkewThreshold:      clockSkewThresholdDuration,
+		ClockSkewNTPServer:      strings.TrimSpace(clockSkewNTPServer),
 		KubernetesObjects:       strings.Fields(kubernetesObjects),
 		NodeLogs:                strings.Fields(nodeLogs),
+		NodeLogsJournalSince:    journalSinceDuration,
 		ContainerLogsNamespaces: strings.Fields(containerLogsNamespaces),
+		ContainerLogsTailLines:  logsTailLines,
+		ContainerLogsSince:      logsSinceDuration,
+		DeprecatedResources:     deprecatedGVRs,
+		DmesgSince:              dmesgSinceDuration,
+		EventTimelineNamespace:  strings.TrimSpace(eventTimelineNamespace),
+		EventTimelineSelector:   eventTimelineSelector,
+		EventTimelineWindow:     eventTimelineWindowDuration,
+		SystemdUnits:            units,
+		SysctlKeys:              sysctls,
+		SystemComponents:        components,
+		SystemComponentLogLines: componentLogLines,
+		MTUProbeTarget:          strings.TrimSpace(mtuProbeTarget),
+		RBACChecks:              checks,
+		ScheduledEventsWindow:   scheduledEventsWindowDuration,
+		ExcludeKeys:             excludePatterns,
+		ExportArchive:           shouldExportArchive,
+		ExportEncryptionKeyFile: strings.TrimSpace(exportEncryptionKeyFile),
+		ExportRunId:             exportRunId,
+		ExportExisting:          exportExisting,
+		ExportTargets:           targets,
+		LocalExportPath:         localExportPath,
+		PVCPath:                 pvcPath,
+		GCSExportBucket:         gcsExportBucket,
+		GCSExportCredentials:    strings.TrimSpace(gcsExportCredentialsFile),
+		HelmReleaseValues:       includeHelmReleaseValues,
+		SelfProfiles:            includeSelfProfiles,
+		RedactSecrets:           shouldRedactSecrets,
+		RedactPatterns:          patterns,
+		AnonymizeNames:          shouldAnonymizeNames,
+		AnonymizeSaltFile:       strings.TrimSpace(anonymizeSaltFile),
+		ValidateCompleteness:    shouldValidateCompleteness,
+		OutputSchemaValidation:  outputSchemaValidation,
 		StorageAccountName:      storageAccountName,
 		StorageSasKey:           storageSasKey,
 		StorageContainerName:    storageContainerName,
 		StorageSasKeyType:       storageSasKeyType,
+		StorageIdentity:         storageIdentity,
+		AzureBlobBufferSize:     int(blobBufferSize),
+		AzureBlobMaxBuffers:     int(blobMaxBuffers),
+		HTTPExportURL:           strings.TrimSpace(httpExportURL),
+		HTTPExportToken:         strings.TrimSpace(httpExportToken),
+		HTTPExportHeaders:       headers,
+		HTTPExportTimeout:       timeout,
+		HTTPExportArchive:       includeHTTPExportArchive,
+		HTTPExportCAFile:        strings.TrimSpace(httpExportCAFile),
+		HTTPExportClientCert:    httpExportClientCert,
+		HTTPExportClientKey:     httpExportClientKey,
+		HTTPExportPinnedCerts:   pinnedCerts,
 		Features:                features,
 	}, nil
 }
@@ -113,7 +629,85 @@ func readFileContent(fs interfaces.FileSystemAccessor, filePath string, mandator
 	return value, readErrors
 }
 
+// parseGroupVersionResource parses a resource as <group>/<version>/<resource>, or <version>/<resource> for the core
+// group.
+func parseGroupVersionResource(entry string) (schema.GroupVersionResource, error) {
+	parts := strings.Split(entry, "/")
+	if len(parts) == 2 {
+		parts = append([]string{""}, parts...)
+	}
+	if len(parts) != 3 || (len(parts[0]) == 0 && strings.Count(entry, "/") == 2) || len(parts[1]) == 0 || len(parts[2]) == 0 {
+		return schema.GroupVersionResource{}, errors.New("expected <group>/<version>/<resource> or <version>/<resource>")
+	}
+
+	return schema.GroupVersionResource{Group: parts[0], Version: parts[1], Resource: parts[2]}, nil
+}
+
+// IsTargetNode returns whether data is collected on this node: either no target node is configured, or this is it.
+func (runtimeInfo *RuntimeInfo) IsTargetNode() bool {
+	return len(runtimeInfo.TargetNode) == 0 || strings.EqualFold(runtimeInfo.TargetNode, runtimeInfo.HostNodeName)
+}
+
 func (runtimeInfo *RuntimeInfo) HasFeature(feature Feature) bool {
 	_, ok := runtimeInfo.Features[feature]
 	return ok
 }
+
+// parseBool parses an optional boolean config value, returning the default if it is empty.
+func parseBool(key ConfigKey, value string, defaultValue bool, parseErrors error) (bool, error) {
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		return defaultValue, parseErrors
+	}
+
+	result, err := strconv.ParseBool(value)
+	if err != nil {
+		return defaultValue, multierror.Append(parseErrors, fmt.Errorf("invalid %s value '%s': %w", key, value, err))
+	}
+	return result, parseErrors
+}
+
+// parseInt64 parses an optional integer config value, returning the default if it is empty.
+func parseInt64(key ConfigKey, value string, defaultValue int64, parseErrors error) (int64, error) {
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		return defaultValue, parseErrors
+	}
+
+	result, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return defaultValue, multierror.Append(parseErrors, fmt.Errorf("invalid %s value '%s': %w", key, value, err))
+	}
+	return result, parseErrors
+}
+
+// parseFloat64 parses an optional decimal config value, returning the default if it is empty.
+func parseFloat64(key ConfigKey, value string, defaultValue float64, parseErrors error) (float64, error) {
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		return defaultValue, parseErrors
+	}
+
+	result, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return defaultValue, multierror.Append(parseErrors, fmt.Errorf("invalid %s value '%s': %w", key, value, err))
+	}
+	return result, parseErrors
+}
+
+// parseDuration parses an optional, non-negative duration config value (e.g. "90s"), returning the default if it is empty.
+func parseDuration(key ConfigKey, value string, defaultValue time.Duration, parseErrors error) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		return defaultValue, parseErrors
+	}
+
+	result, err := time.ParseDuration(value)
+	if err != nil {
+		return defaultValue, multierror.Append(parseErrors, fmt.Errorf("invalid %s value '%s': %w", key, value, err))
+	}
+	if result < 0 {
+		return defaultValue, multierror.Append(parseErrors, fmt.Errorf("invalid %s value '%s': must not be negative", key, value))
+	}
+	return result, parseErrors
+}
@@ -0,0 +1,420 @@
+package utils
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-periscope/pkg/test"
+	"github.com/hashicorp/go-multierror"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestGetRuntimeInfo(t *testing.T) {
+	filePaths := &KnownFilePaths{
+		Config:       "/config",
+		Secret:       "/secret",
+		NodeLogsList: "/config/" + string(NodeLogsLinuxKey),
+	}
+
+	tests := []struct {
+		name          string
+		hostNodeName  string
+		config        map[ConfigKey]string
+		wantErrCount  int
+		wantErrsMatch []string
+		validate      func(*testing.T, *RuntimeInfo)
+	}{
+		{
+			name:         "defaults",
+			hostNodeName: "node-1",
+			config:       map[ConfigKey]string{},
+			wantErrCount: 0,
+			validate: func(t *testing.T, runtimeInfo *RuntimeInfo) {
+				if runtimeInfo.CollectorConcurrency != 0 || runtimeInfo.CollectorTimeout != 0 || runtimeInfo.CollectorMaxBytes != 0 {
+					t.Errorf("unexpected collector limits: %+v", runtimeInfo)
+				}
+				if runtimeInfo.HTTPExportTimeout != defaultHTTPExportTimeout {
+					t.Errorf("unexpected HTTP export timeout %s", runtimeInfo.HTTPExportTimeout)
+				}
+				if runtimeInfo.EventTimelineNamespace != "" || runtimeInfo.EventTimelineWindow != defaultEventTimelineWindow {
+					t.Errorf("unexpected event timeline settings %q %s", runtimeInfo.EventTimelineNamespace, runtimeInfo.EventTimelineWindow)
+				}
+				if strings.Join(runtimeInfo.ExportTargets, " ") != ExportTargetAzureBlob || runtimeInfo.LocalExportPath != defaultLocalExportPath {
+					t.Errorf("unexpected export targets %v (%s)", runtimeInfo.ExportTargets, runtimeInfo.LocalExportPath)
+				}
+				if runtimeInfo.ExportRunId != "run-1" || runtimeInfo.ExportExisting != ExportExistingOverwrite {
+					t.Errorf("unexpected export run %q (%s existing)", runtimeInfo.ExportRunId, runtimeInfo.ExportExisting)
+				}
+				if strings.Join(runtimeInfo.SystemdUnits, " ") != "kubelet containerd walinuxagent" {
+					t.Errorf("unexpected systemd units %v", runtimeInfo.SystemdUnits)
+				}
+				if len(runtimeInfo.SysctlKeys) != len(defaultSysctlKeys) {
+					t.Errorf("unexpected sysctl keys %v", runtimeInfo.SysctlKeys)
+				}
+				if len(runtimeInfo.SystemComponents) != 4 || runtimeInfo.SystemComponentLogLines != defaultSystemComponentLogLines {
+					t.Errorf("unexpected system components %v (%d lines)", runtimeInfo.SystemComponents, runtimeInfo.SystemComponentLogLines)
+				}
+				if runtimeInfo.NodeLogsJournalSince != defaultNodeLogsJournalSince {
+					t.Errorf("unexpected node journal window %s", runtimeInfo.NodeLogsJournalSince)
+				}
+				if runtimeInfo.ContainerLogsTailLines != defaultContainerLogsTailLines || runtimeInfo.ContainerLogsSince != 0 {
+					t.Errorf("unexpected container log limits: %d lines, since %s", runtimeInfo.ContainerLogsTailLines, runtimeInfo.ContainerLogsSince)
+				}
+				if len(runtimeInfo.RBACChecks) != 4 {
+					t.Errorf("unexpected RBAC checks %v", runtimeInfo.RBACChecks)
+				}
+				if len(runtimeInfo.DeprecatedResources) != len(defaultDeprecatedResources) {
+					t.Errorf("unexpected deprecated resources %v", runtimeInfo.DeprecatedResources)
+				}
+				if runtimeInfo.AzureBlobBufferSize != defaultAzureBlobBufferSize || runtimeInfo.AzureBlobMaxBuffers != defaultAzureBlobMaxBuffers {
+					t.Errorf("unexpected blob upload buffers %d x %d", runtimeInfo.AzureBlobMaxBuffers, runtimeInfo.AzureBlobBufferSize)
+				}
+				if runtimeInfo.ClockSkewThreshold != defaultClockSkewThreshold || runtimeInfo.ClockSkewNTPServer != "" {
+					t.Errorf("unexpected clock skew settings %s %q", runtimeInfo.ClockSkewThreshold, runtimeInfo.ClockSkewNTPServer)
+				}
+				if runtimeInfo.OutputSchemaValidation != OutputSchemaValidationOff {
+					t.Errorf("unexpected output schema validation %q", runtimeInfo.OutputSchemaValidation)
+				}
+				if runtimeInfo.CollectorAPIQPS != defaultCollectorAPIQPS || runtimeInfo.CollectorAPIBurst != defaultCollectorAPIBurst || runtimeInfo.CollectorStartJitter != 0 || runtimeInfo.CollectorHeartbeat != defaultCollectorHeartbeat {
+					t.Errorf("unexpected API limits: %v QPS, burst %d, jitter %s, heartbeat %s", runtimeInfo.CollectorAPIQPS, runtimeInfo.CollectorAPIBurst, runtimeInfo.CollectorStartJitter, runtimeInfo.CollectorHeartbeat)
+				}
+			},
+		},
+		{
+			name:         "typed values",
+			hostNodeName: "node-1",
+			config: map[ConfigKey]string{
+				CollectorConcurrencyKey:    "4\n",
+				CollectorTimeoutKey:        "5m",
+				CollectorMaxBytesKey:       "1024",
+				CollectorAPIQPSKey:         "2.5",
+				CollectorAPIBurstKey:       "5",
+				CollectorStartJitterKey:    "30s",
+				CollectorHeartbeatKey:      "0s",
+				ClockSkewThresholdKey:      "250ms",
+				AzureBlobBufferSizeKey:     "16777216\n",
+				AzureBlobMaxBuffersKey:     "8",
+				ClockSkewNTPServerKey:      "time.windows.com\n",
+				ContainerLogsTailLinesKey:  "2000",
+				ContainerLogsSinceKey:      "15m",
+				DmesgSinceKey:              "30m",
+				EventTimelineNamespaceKey:  "app\n",
+				EventTimelineSelectorKey:   "app=web,tier in (frontend)",
+				EventTimelineWindowKey:     "6h",
+				ExcludeKeysKey:             "kubeobjects/* *.log",
+				ExportArchiveKey:           "true",
+				ExportEncryptionKeyFileKey: "/keys/periscope.pem\n",
+				ExportExistingKey:          "skip",
+				ExportRunIdKey:             "run-0\n",
+				ExportTargetsKey:           "azureblob local",
+				LocalExportPathKey:         "/output",
+				HTTPExportTimeoutKey:       "10s",
+				HTTPExportCAFileKey:        "/certs/ca.pem\n",
+				HTTPExportClientCertKey:    "/certs/tls.crt",
+				HTTPExportClientKeyKey:     "/certs/tls.key",
+				HTTPExportPinnedCertsKey:   "AB:" + strings.Repeat("00", 31) + " " + strings.Repeat("ff", 32),
+				RedactSecretsKey:           "true",
+				AnonymizeNamesKey:          "true",
+				SelfProfilesKey:            "true",
+				AnonymizeSaltFileKey:       "/secrets/salt\n",
+				RedactPatternsKey:          `password=\S+ token:\s*\w+`,
+				SystemdUnitsKey:            "kubelet docker",
+				SysctlKeysKey:              "net.ipv4.ip_forward vm.swappiness\n",
+				SystemComponentsKey:        "deployment/coredns daemonset/kube-proxy",
+				SystemComponentLogLinesKey: "100",
+				MTUProbeTargetKey:          "10.0.0.1\n",
+				NodeLogsJournalSinceKey:    "2h",
+				OutputSchemaValidationKey:  "fail\n",
+				ScheduledEventsWindowKey:   "2m",
+				RBACChecksKey:              "privileged secrets-access",
+				DeprecatedResourcesKey:     "batch/v1beta1/cronjobs v1/componentstatuses",
+				TargetNodeKey:              " node-1\n",
+			},
+			wantErrCount: 0,
+			validate: func(t *testing.T, runtimeInfo *RuntimeInfo) {
+				if runtimeInfo.CollectorConcurrency != 4 {
+					t.Errorf("unexpected concurrency %d", runtimeInfo.CollectorConcurrency)
+				}
+				if runtimeInfo.CollectorTimeout != 5*time.Minute {
+					t.Errorf("unexpected timeout %s", runtimeInfo.CollectorTimeout)
+				}
+				if runtimeInfo.CollectorMaxBytes != 1024 {
+					t.Errorf("unexpected max bytes %d", runtimeInfo.CollectorMaxBytes)
+				}
+				if runtimeInfo.CollectorAPIQPS != 2.5 || runtimeInfo.CollectorAPIBurst != 5 || runtimeInfo.CollectorStartJitter != 30*time.Second || runtimeInfo.CollectorHeartbeat != 0 {
+					t.Errorf("unexpected API limits: %v QPS, burst %d, jitter %s, heartbeat %s", runtimeInfo.CollectorAPIQPS, runtimeInfo.CollectorAPIBurst, runtimeInfo.CollectorStartJitter, runtimeInfo.CollectorHeartbeat)
+				}
+				if runtimeInfo.AzureBlobBufferSize != 16*1024*1024 || runtimeInfo.AzureBlobMaxBuffers != 8 {
+					t.Errorf("unexpected blob upload buffers %d x %d", runtimeInfo.AzureBlobMaxBuffers, runtimeInfo.AzureBlobBufferSize)
+				}
+				if runtimeInfo.ClockSkewThreshold != 250*time.Millisecond || runtimeInfo.ClockSkewNTPServer != "time.windows.com" {
+					t.Errorf("unexpected clock skew settings %s %q", runtimeInfo.ClockSkewThreshold, runtimeInfo.ClockSkewNTPServer)
+				}
+				if runtimeInfo.ContainerLogsTailLines != 2000 || runtimeInfo.ContainerLogsSince != 15*time.Minute {
+					t.Errorf("unexpected container log limits: %d lines, since %s", runtimeInfo.ContainerLogsTailLines, runtimeInfo.ContainerLogsSince)
+				}
+				if runtimeInfo.DmesgSince != 30*time.Minute {
+					t.Errorf("unexpected dmesg window %s", runtimeInfo.DmesgSince)
+				}
+				if runtimeInfo.NodeLogsJournalSince != 2*time.Hour {
+					t.Errorf("unexpected node journal window %s", runtimeInfo.NodeLogsJournalSince)
+				}
+				if runtimeInfo.EventTimelineNamespace != "app" || runtimeInfo.EventTimelineSelector != "app=web,tier in (frontend)" || runtimeInfo.EventTimelineWindow != 6*time.Hour {
+					t.Errorf("unexpected event timeline settings %q %q %s", runtimeInfo.EventTimelineNamespace, runtimeInfo.EventTimelineSelector, runtimeInfo.EventTimelineWindow)
+				}
+				if strings.Join(runtimeInfo.ExcludeKeys, " ") != "kubeobjects/* *.log" {
+					t.Errorf("unexpected excluded keys %v", runtimeInfo.ExcludeKeys)
+				}
+				if !runtimeInfo.ExportArchive {
+					t.Errorf("expected archive export")
+				}
+				if strings.Join(runtimeInfo.ExportTargets, " ") != "azureblob local" || runtimeInfo.LocalExportPath != "/output" {
+					t.Errorf("unexpected export targets %v (%s)", runtimeInfo.ExportTargets, runtimeInfo.LocalExportPath)
+				}
+				if runtimeInfo.RunId != "run-1" || runtimeInfo.ExportRunId != "run-0" || runtimeInfo.ExportExisting != ExportExistingSkip {
+					t.Errorf("unexpected export run %q for run %q (%s existing)", runtimeInfo.ExportRunId, runtimeInfo.RunId, runtimeInfo.ExportExisting)
+				}
+				if runtimeInfo.ExportEncryptionKeyFile != "/keys/periscope.pem" {
+					t.Errorf("unexpected export encryption key file %q", runtimeInfo.ExportEncryptionKeyFile)
+				}
+				if runtimeInfo.HTTPExportTimeout != 10*time.Second {
+					t.Errorf("unexpected HTTP export timeout %s", runtimeInfo.HTTPExportTimeout)
+				}
+				if runtimeInfo.HTTPExportCAFile != "/certs/ca.pem" || runtimeInfo.HTTPExportClientCert != "/certs/tls.crt" || runtimeInfo.HTTPExportClientKey != "/certs/tls.key" {
+					t.Errorf("unexpected HTTP export TLS files %q %q %q", runtimeInfo.HTTPExportCAFile, runtimeInfo.HTTPExportClientCert, runtimeInfo.HTTPExportClientKey)
+				}
+				if strings.Join(runtimeInfo.HTTPExportPinnedCerts, " ") != "ab"+strings.Repeat("00", 31)+" "+strings.Repeat("ff", 32) {
+					t.Errorf("unexpected pinned certificates %v", runtimeInfo.HTTPExportPinnedCerts)
+				}
+				if !runtimeInfo.RedactSecrets || len(runtimeInfo.RedactPatterns) != 2 {
+					t.Errorf("unexpected redaction settings: %t %v", runtimeInfo.RedactSecrets, runtimeInfo.RedactPatterns)
+				}
+				if strings.Join(runtimeInfo.SystemdUnits, " ") != "kubelet docker" {
+					t.Errorf("unexpected systemd units %v", runtimeInfo.SystemdUnits)
+				}
+				if strings.Join(runtimeInfo.SysctlKeys, " ") != "net.ipv4.ip_forward vm.swappiness" {
+					t.Errorf("unexpected sysctl keys %v", runtimeInfo.SysctlKeys)
+				}
+				if strings.Join(runtimeInfo.SystemComponents, " ") != "deployment/coredns daemonset/kube-proxy" || runtimeInfo.SystemComponentLogLines != 100 {
+					t.Errorf("unexpected system components %v (%d lines)", runtimeInfo.SystemComponents, runtimeInfo.SystemComponentLogLines)
+				}
+				if runtimeInfo.MTUProbeTarget != "10.0.0.1" {
+					t.Errorf("unexpected MTU probe target %q", runtimeInfo.MTUProbeTarget)
+				}
+				if runtimeInfo.OutputSchemaValidation != OutputSchemaValidationFail {
+					t.Errorf("unexpected output schema validation %q", runtimeInfo.OutputSchemaValidation)
+				}
+				if runtimeInfo.ScheduledEventsWindow != 2*time.Minute {
+					t.Errorf("unexpected scheduled events window %s", runtimeInfo.ScheduledEventsWindow)
+				}
+				if strings.Join(runtimeInfo.RBACChecks, " ") != "privileged secrets-access" {
+					t.Errorf("unexpected RBAC checks %v", runtimeInfo.RBACChecks)
+				}
+				if !runtimeInfo.SelfProfiles {
+					t.Errorf("expected self profiles")
+				}
+				if !runtimeInfo.AnonymizeNames || runtimeInfo.AnonymizeSaltFile != "/secrets/salt" {
+					t.Errorf("unexpected anonymization settings %t %q", runtimeInfo.AnonymizeNames, runtimeInfo.AnonymizeSaltFile)
+				}
+				expectedDeprecatedResources := []schema.GroupVersionResource{
+					{Group: "batch", Version: "v1beta1", Resource: "cronjobs"},
+					{Version: "v1", Resource: "componentstatuses"},
+				}
+				if !reflect.DeepEqual(runtimeInfo.DeprecatedResources, expectedDeprecatedResources) {
+					t.Errorf("unexpected deprecated resources %v", runtimeInfo.DeprecatedResources)
+				}
+				if runtimeInfo.TargetNode != "node-1" || !runtimeInfo.IsTargetNode() {
+					t.Errorf("unexpected target node %q", runtimeInfo.TargetNode)
+				}
+			},
+		},
+		{
+			name:         "PVC export by default",
+			hostNodeName: "node-1",
+			config: map[ConfigKey]string{
+				PVCPathKey: "/mnt/diagnostics\n",
+			},
+			wantErrCount: 0,
+			validate: func(t *testing.T, runtimeInfo *RuntimeInfo) {
+				if strings.Join(runtimeInfo.ExportTargets, " ") != ExportTargetPVC || runtimeInfo.PVCPath != "/mnt/diagnostics" {
+					t.Errorf("unexpected export targets %v (%s)", runtimeInfo.ExportTargets, runtimeInfo.PVCPath)
+				}
+			},
+		},
+		{
+			name:         "GCS export by default",
+			hostNodeName: "node-1",
+			config: map[ConfigKey]string{
+				GCSExportBucketKey:      "periscope-diagnostics\n",
+				GCSExportCredentialsKey: "/secrets/gcs/key.json",
+			},
+			wantErrCount: 0,
+			validate: func(t *testing.T, runtimeInfo *RuntimeInfo) {
+				if strings.Join(runtimeInfo.ExportTargets, " ") != ExportTargetGCS || runtimeInfo.GCSExportBucket != "periscope-diagnostics" {
+					t.Errorf("unexpected export targets %v (%s)", runtimeInfo.ExportTargets, runtimeInfo.GCSExportBucket)
+				}
+				if runtimeInfo.GCSExportCredentials != "/secrets/gcs/key.json" {
+					t.Errorf("unexpected GCS credentials file %q", runtimeInfo.GCSExportCredentials)
+				}
+			},
+		},
+		{
+			name:         "negative max bytes",
+			hostNodeName: "node-1",
+			config: map[ConfigKey]string{
+				CollectorMaxBytesKey: "-1048576",
+			},
+			wantErrCount:  1,
+			wantErrsMatch: []string{string(CollectorMaxBytesKey)},
+		},
+		{
+			name:         "all malformed values reported",
+			hostNodeName: "",
+			config: map[ConfigKey]string{
+				CollectorConcurrencyKey:    "-1",
+				CollectorTimeoutKey:        "five minutes",
+				CollectorMaxBytesKey:       "1MB",
+				CollectorAPIQPSKey:         "0",
+				CollectorAPIBurstKey:       "lots",
+				CollectorStartJitterKey:    "-10s",
+				CollectorHeartbeatKey:      "often",
+				ClockSkewThresholdKey:      "0s",
+				AzureBlobBufferSizeKey:     "1024",
+				AzureBlobMaxBuffersKey:     "0",
+				ContainerLogsTailLinesKey:  "-5",
+				ContainerLogsSinceKey:      "yesterday",
+				NodeLogsJournalSinceKey:    "-1h",
+				HelmReleaseValuesKey:       "maybe",
+				EventTimelineSelectorKey:   "app in (web",
+				EventTimelineWindowKey:     "0s",
+				HTTPExportTimeoutKey:       "0s",
+				HTTPExportClientCertKey:    "/certs/tls.crt",
+				HTTPExportPinnedCertsKey:   "abcd",
+				RedactPatternsKey:          "valid invalid(",
+				ExportTargetsKey:           "http ftp pvc gcs",
+				ExportExistingKey:          "append",
+				OutputSchemaValidationKey:  "strict",
+				SystemComponentsKey:        "deployment/coredns statefulset/etcd",
+				SystemComponentLogLinesKey: "0",
+				ScheduledEventsWindowKey:   "-1m",
+				RBACChecksKey:              "privileged root",
+				ExcludeKeysKey:             "logs/* [unclosed",
+				DeprecatedResourcesKey:     "batch/v1beta1/cronjobs cronjobs",
+				AnonymizeNamesKey:          "sometimes",
+				SelfProfilesKey:            "on",
+			},
+			wantErrCount: 35,
+			wantErrsMatch: []string{
+				"HOST_NODE_NAME",
+				string(CollectorConcurrencyKey),
+				string(CollectorTimeoutKey),
+				string(CollectorMaxBytesKey),
+				string(CollectorAPIQPSKey),
+				string(CollectorAPIBurstKey),
+				string(CollectorStartJitterKey),
+				string(CollectorHeartbeatKey),
+				string(ClockSkewThresholdKey),
+				string(AzureBlobBufferSizeKey),
+				string(AzureBlobMaxBuffersKey),
+				string(ContainerLogsTailLinesKey),
+				string(ContainerLogsSinceKey),
+				string(HelmReleaseValuesKey),
+				string(EventTimelineSelectorKey),
+				string(EventTimelineWindowKey),
+				string(HTTPExportTimeoutKey),
+				string(HTTPExportClientKeyKey),
+				"'abcd'",
+				string(RedactPatternsKey),
+				"'ftp'",
+				"HTTP_EXPORT_URL is not set",
+				"DIAGNOSTIC_PVC_PATH is not set",
+				"GCS_EXPORT_BUCKET is not set",
+				string(ExportExistingKey),
+				string(OutputSchemaValidationKey),
+				"'statefulset/etcd'",
+				string(SystemComponentLogLinesKey),
+				string(ScheduledEventsWindowKey),
+				"'root'",
+				"'[unclosed'",
+				"'cronjobs'",
+				string(AnonymizeNamesKey),
+				string(SelfProfilesKey),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOST_NODE_NAME", tt.hostNodeName)
+
+			files := map[string]string{filePaths.GetConfigPath(RunIdKey): "run-1"}
+			for key, value := range tt.config {
+				files[filePaths.GetConfigPath(key)] = value
+			}
+
+			runtimeInfo, err := GetRuntimeInfo(test.NewFakeFileSystem(files), filePaths)
+			if tt.wantErrCount == 0 {
+				if err != nil {
+					t.Fatalf("GetRuntimeInfo() error = %v", err)
+				}
+				tt.validate(t, runtimeInfo)
+				return
+			}
+
+			var merr *multierror.Error
+			if !errors.As(err, &merr) {
+				t.Fatalf("expected aggregated error, found %v", err)
+			}
+			if len(merr.Errors) != tt.wantErrCount {
+				t.Errorf("expected %d errors, found %d: %v", tt.wantErrCount, len(merr.Errors), err)
+			}
+			for _, match := range tt.wantErrsMatch {
+				if !strings.Contains(err.Error(), match) {
+					t.Errorf("expected error mentioning %s, found %v", match, err)
+				}
+			}
+		})
+	}
+}
+
+func TestRuntimeInfoIsTargetNode(t *testing.T) {
+	tests := []struct {
+		name       string
+		targetNode string
+		want       bool
+	}{
+		{
+			name:       "no target",
+			targetNode: "",
+			want:       true,
+		},
+		{
+			name:       "this node",
+			targetNode: "aks-nodepool1-12345678-vmss000000",
+			want:       true,
+		},
+		{
+			name:       "this node in different case",
+			targetNode: "AKS-NodePool1-12345678-VMSS000000",
+			want:       true,
+		},
+		{
+			name:       "another node",
+			targetNode: "aks-nodepool1-12345678-vmss000001",
+			want:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		runtimeInfo := &RuntimeInfo{
+			HostNodeName: "aks-nodepool1-12345678-vmss000000",
+			TargetNode:   tt.targetNode,
+		}
+		if got := runtimeInfo.IsTargetNode(); got != tt.want {
+			t.Errorf("%s: IsTargetNode() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+)
+
+// SizeLimitedDataValue wraps a DataValue, truncating its content to a maximum number of bytes and
+// appending a marker line if the content was truncated.
+type SizeLimitedDataValue struct {
+	value    interfaces.DataValue
+	maxBytes int64
+}
+
+func NewSizeLimitedDataValue(value interfaces.DataValue, maxBytes int64) *SizeLimitedDataValue {
+	return &SizeLimitedDataValue{
+		value:    value,
+		maxBytes: maxBytes,
+	}
+}
+
+func (v *SizeLimitedDataValue) GetLength() int64 {
+	length := v.value.GetLength()
+	if length <= v.maxBytes {
+		return length
+	}
+
+	return v.maxBytes + int64(len(v.getTruncationMarker()))
+}
+
+func (v *SizeLimitedDataValue) GetReader() (io.ReadCloser, error) {
+	reader, err := v.value.GetReader()
+	if err != nil {
+		return nil, err
+	}
+
+	// Don't rely on the reported length to determine whether to truncate, since the underlying
+	// data (e.g. a log file) may have grown since the length was determined.
+	return &sizeLimitedReadCloser{
+		Reader: limitReader(reader, v.maxBytes),
+		closer: reader,
+	}, nil
+}
+
+func (v *SizeLimitedDataValue) getTruncationMarker() string {
+	return getTruncationMarker(v.maxBytes)
+}
+
+// NewSizeLimitedReader truncates the content of the reader to a maximum number of bytes, appending a marker line if
+// the content was truncated. This applies the same limit as SizeLimitedDataValue to content of unknown length (e.g.
+// streamed data). A maxBytes value of zero or less means no limit is applied.
+func NewSizeLimitedReader(reader io.Reader, maxBytes int64) io.Reader {
+	if maxBytes <= 0 {
+		return reader
+	}
+
+	return limitReader(reader, maxBytes)
+}
+
+func limitReader(reader io.Reader, maxBytes int64) io.Reader {
+	return io.MultiReader(io.LimitReader(reader, maxBytes), &truncationMarkerReader{source: reader, marker: getTruncationMarker(maxBytes)})
+}
+
+func getTruncationMarker(maxBytes int64) string {
+	return fmt.Sprintf("\n[truncated: exceeded %d bytes]\n", maxBytes)
+}
+
+type sizeLimitedReadCloser struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (r *sizeLimitedReadCloser) Close() error {
+	return r.closer.Close()
+}
+
+// truncationMarkerReader produces the truncation marker only if there is remaining content in the source.
+type truncationMarkerReader struct {
+	source io.Reader
+	marker string
+	reader io.Reader
+}
+
+func (r *truncationMarkerReader) Read(p []byte) (int, error) {
+	if r.reader == nil {
+		r.reader = strings.NewReader("")
+		n, _ := r.source.Read(make([]byte, 1))
+		if n > 0 {
+			r.reader = strings.NewReader(r.marker)
+		}
+	}
+
+	return r.reader.Read(p)
+}
+
+// SizeLimitedDataProducer wraps a DataProducer, applying a size limit to each of its data values.
+type SizeLimitedDataProducer struct {
+	producer interfaces.DataProducer
+	maxBytes int64
+}
+
+// NewSizeLimitedDataProducer creates a DataProducer whose values are truncated at maxBytes.
+// A maxBytes value of zero or less means no limit is applied.
+func NewSizeLimitedDataProducer(producer interfaces.DataProducer, maxBytes int64) *SizeLimitedDataProducer {
+	return &SizeLimitedDataProducer{
+		producer: producer,
+		maxBytes: maxBytes,
+	}
+}
+
+func (p *SizeLimitedDataProducer) GetName() string {
+	return p.producer.GetName()
+}
+
+func (p *SizeLimitedDataProducer) GetData() map[string]interfaces.DataValue {
+	data := p.producer.GetData()
+	if p.maxBytes <= 0 {
+		return data
+	}
+
+	result := make(map[string]interfaces.DataValue, len(data))
+	for key, value := range data {
+		result[key] = NewSizeLimitedDataValue(value, p.maxBytes)
+	}
+
+	return result
+}
@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+	"github.com/Azure/aks-periscope/pkg/test"
+)
+
+type testDataProducer struct {
+	data map[string]interfaces.DataValue
+}
+
+func (p *testDataProducer) GetName() string {
+	return "test"
+}
+
+func (p *testDataProducer) GetData() map[string]interfaces.DataValue {
+	return p.data
+}
+
+func TestSizeLimitedDataValue(t *testing.T) {
+	const filePath = "/var/log/large.log"
+	fs := test.NewFakeFileSystem(map[string]string{filePath: "0123456789abcdef"})
+
+	tests := []struct {
+		name        string
+		value       interfaces.DataValue
+		maxBytes    int64
+		wantContent string
+	}{
+		{
+			name:        "string under limit",
+			value:       NewStringDataValue("0123456789"),
+			maxBytes:    16,
+			wantContent: "0123456789",
+		},
+		{
+			name:        "string at limit",
+			value:       NewStringDataValue("0123456789"),
+			maxBytes:    10,
+			wantContent: "0123456789",
+		},
+		{
+			name:        "string over limit",
+			value:       NewStringDataValue("0123456789"),
+			maxBytes:    4,
+			wantContent: "0123\n[truncated: exceeded 4 bytes]\n",
+		},
+		{
+			name:        "file over limit",
+			value:       NewFilePathDataValue(fs, filePath, 16),
+			maxBytes:    8,
+			wantContent: "01234567\n[truncated: exceeded 8 bytes]\n",
+		},
+		{
+			name:        "file larger than reported length",
+			value:       NewFilePathDataValue(fs, filePath, 4),
+			maxBytes:    8,
+			wantContent: "01234567\n[truncated: exceeded 8 bytes]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value := NewSizeLimitedDataValue(tt.value, tt.maxBytes)
+			content, err := GetContent(func() (io.ReadCloser, error) { return value.GetReader() })
+			if err != nil {
+				t.Fatalf("error reading value: %v", err)
+			}
+
+			if content != tt.wantContent {
+				t.Errorf("unexpected content.\nExpected '%s'\nFound '%s'", tt.wantContent, content)
+			}
+		})
+	}
+}
+
+func TestSizeLimitedDataProducer(t *testing.T) {
+	producer := &testDataProducer{
+		data: map[string]interfaces.DataValue{
+			"small": NewStringDataValue("abc"),
+			"large": NewStringDataValue("abcdefghij"),
+		},
+	}
+
+	tests := []struct {
+		name     string
+		maxBytes int64
+		want     map[string]string
+	}{
+		{
+			name:     "no limit",
+			maxBytes: 0,
+			want: map[string]string{
+				"small": "abc",
+				"large": "abcdefghij",
+			},
+		},
+		{
+			name:     "limit",
+			maxBytes: 5,
+			want: map[string]string{
+				"small": "abc",
+				"large": "abcde\n[truncated: exceeded 5 bytes]\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limited := NewSizeLimitedDataProducer(producer, tt.maxBytes)
+			if limited.GetName() != producer.GetName() {
+				t.Errorf("unexpected name %s", limited.GetName())
+			}
+
+			data := limited.GetData()
+			for key, expected := range tt.want {
+				value, ok := data[key]
+				if !ok {
+					t.Fatalf("missing key %s", key)
+				}
+
+				content, err := GetContent(func() (io.ReadCloser, error) { return value.GetReader() })
+				if err != nil {
+					t.Fatalf("error reading %s: %v", key, err)
+				}
+				if content != expected {
+					t.Errorf("unexpected content for %s.\nExpected '%s'\nFound '%s'", key, expected, content)
+				}
+				if value.GetLength() != int64(len(expected)) {
+					t.Errorf("unexpected length for %s: expected %d, found %d", key, len(expected), value.GetLength())
+				}
+			}
+		})
+	}
+}
+
+func TestSizeLimitedReader(t *testing.T) {
+	content, _ := io.ReadAll(NewSizeLimitedReader(strings.NewReader("0123456789"), 4))
+	if string(content) != "0123\n[truncated: exceeded 4 bytes]\n" {
+		t.Errorf("unexpected content '%s'", content)
+	}
+
+	content, _ = io.ReadAll(NewSizeLimitedReader(strings.NewReader("0123456789"), 0))
+	if string(content) != "0123456789" {
+		t.Errorf("unexpected content '%s'", content)
+	}
+}
@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Environment variables configuring an identity for Azure storage access. These are the variables injected by the
+// Azure Workload Identity webhook, and AZURE_CLIENT_ID alone selects a user-assigned managed identity of the node.
+// https://azure.github.io/azure-workload-identity/docs/quick-start.html
+const (
+	AzureClientIDEnvVar           = "AZURE_CLIENT_ID"
+	AzureTenantIDEnvVar           = "AZURE_TENANT_ID"
+	AzureFederatedTokenFileEnvVar = "AZURE_FEDERATED_TOKEN_FILE"
+	AzureAuthorityHostEnvVar      = "AZURE_AUTHORITY_HOST"
+)
+
+const defaultAzureAuthorityHost = "https://login.microsoftonline.com/"
+
+// storageResource identifies Azure storage as the audience of an access token.
+const storageResource = "https://storage.azure.com/"
+
+const storageTokenTimeout = 30 * time.Second
+
+// AccessTokenSource acquires an OAuth access token.
+type AccessTokenSource func() (string, error)
+
+// StorageIdentity holds the identity configured for Azure storage access.
+type StorageIdentity struct {
+	ClientID           string
+	TenantID           string
+	FederatedTokenFile string
+	AuthorityHost      string
+}
+
+// GetStorageIdentity reads the storage identity from the environment, returning nil if none is configured,
+// in which case the SAS token should be used.
+func GetStorageIdentity() *StorageIdentity {
+	clientID := strings.TrimSpace(os.Getenv(AzureClientIDEnvVar))
+	if len(clientID) == 0 {
+		return nil
+	}
+
+	authorityHost := strings.TrimSpace(os.Getenv(AzureAuthorityHostEnvVar))
+	if len(authorityHost) == 0 {
+		authorityHost = defaultAzureAuthorityHost
+	}
+
+	return &StorageIdentity{
+		ClientID:           clientID,
+		TenantID:           strings.TrimSpace(os.Getenv(AzureTenantIDEnvVar)),
+		FederatedTokenFile: strings.TrimSpace(os.Getenv(AzureFederatedTokenFileEnvVar)),
+		AuthorityHost:      authorityHost,
+	}
+}
+
+// GetTokenSource returns a source of storage access tokens for the identity: a workload identity if a federated
+// token file is configured, and otherwise a user-assigned managed identity, whose tokens are obtained from IMDS.
+func (identity *StorageIdentity) GetTokenSource() AccessTokenSource {
+	client := &http.Client{Timeout: storageTokenTimeout}
+	if len(identity.FederatedTokenFile) > 0 {
+		return func() (string, error) {
+			return getWorkloadIdentityToken(client, identity)
+		}
+	}
+
+	imdsClient := NewIMDSClient(storageTokenTimeout)
+	return func() (string, error) {
+		return getManagedIdentityToken(imdsClient, IMDSEndpoint, identity.ClientID)
+	}
+}
+
+type accessTokenResponse struct {
+	AccessToken string `json:"access_token"`
+}
+
+// getWorkloadIdentityToken exchanges the federated service account token for an access token. See:
+// https://learn.microsoft.com/en-us/entra/identity-platform/v2-oauth2-client-creds-grant-flow#third-case-access-token-request-with-a-federated-credential
+func getWorkloadIdentityToken(client *http.Client, identity *StorageIdentity) (string, error) {
+	assertion, err := os.ReadFile(identity.FederatedTokenFile)
+	if err != nil {
+		return "", fmt.Errorf("read federated token file: %w", err)
+	}
+
+	form := url.Values{
+		"client_id":             {identity.ClientID},
+		"grant_type":            {"client_credentials"},
+		"scope":                 {storageResource + ".default"},
+		"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+		"client_assertion":      {strings.TrimSpace(string(assertion))},
+	}
+
+	tokenURL := fmt.Sprintf("%s/%s/oauth2/v2.0/token", strings.TrimSuffix(identity.AuthorityHost, "/"), identity.TenantID)
+	resp, err := client.PostForm(tokenURL, form)
+	if err != nil {
+		return "", fmt.Errorf("request token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return readAccessToken(resp)
+}
+
+// getManagedIdentityToken requests a token for a user-assigned managed identity from IMDS. See:
+// https://learn.microsoft.com/en-us/entra/identity/managed-identities-azure-resources/how-to-use-vm-token#get-a-token-using-http
+func getManagedIdentityToken(client *http.Client, endpoint, clientID string) (string, error) {
+	query := url.Values{
+		"api-version": {"2018-02-01"},
+		"resource":    {storageResource},
+		"client_id":   {clientID},
+	}
+
+	content, err := GetIMDSContent(client, endpoint, "/metadata/identity/oauth2/token?"+query.Encode())
+	if err != nil {
+		return "", err
+	}
+
+	return parseAccessToken(content)
+}
+
+func readAccessToken(resp *http.Response) (string, error) {
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("read token response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected token response: %s: %s", resp.Status, string(content))
+	}
+
+	return parseAccessToken(content)
+}
+
+func parseAccessToken(content []byte) (string, error) {
+	var response accessTokenResponse
+	if err := json.Unmarshal(content, &response); err != nil {
+		return "", fmt.Errorf("unmarshal token response: %w", err)
+	}
+
+	if len(response.AccessToken) == 0 {
+		return "", fmt.Errorf("token response has no access token")
+	}
+
+	return response.AccessToken, nil
+}
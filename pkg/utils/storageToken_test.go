@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetStorageIdentity(t *testing.T) {
+	t.Setenv(AzureClientIDEnvVar, "")
+	if identity := GetStorageIdentity(); identity != nil {
+		t.Errorf("expected no identity without %s, found %+v", AzureClientIDEnvVar, identity)
+	}
+
+	t.Setenv(AzureClientIDEnvVar, "client-1")
+	t.Setenv(AzureTenantIDEnvVar, "tenant-1")
+	t.Setenv(AzureFederatedTokenFileEnvVar, "/var/run/secrets/token")
+	t.Setenv(AzureAuthorityHostEnvVar, "")
+	identity := GetStorageIdentity()
+	expected := StorageIdentity{ClientID: "client-1", TenantID: "tenant-1", FederatedTokenFile: "/var/run/secrets/token", AuthorityHost: defaultAzureAuthorityHost}
+	if identity == nil || *identity != expected {
+		t.Errorf("expected identity %+v, found %+v", expected, identity)
+	}
+}
+
+func TestGetWorkloadIdentityToken(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("federated-token\n"), 0600); err != nil {
+		t.Fatalf("unable to write token file: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/tenant-1/oauth2/v2.0/token" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.FormValue("client_id") != "client-1" || r.FormValue("client_assertion") != "federated-token" || r.FormValue("scope") != "https://storage.azure.com/.default" {
+			http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"token_type":"Bearer","expires_in":3599,"access_token":"access-token"}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		clientID  string
+		wantToken string
+		wantErr   bool
+	}{
+		{
+			name:      "token exchanged",
+			clientID:  "client-1",
+			wantToken: "access-token",
+			wantErr:   false,
+		},
+		{
+			name:     "token rejected",
+			clientID: "client-2",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity := &StorageIdentity{ClientID: tt.clientID, TenantID: "tenant-1", FederatedTokenFile: tokenFile, AuthorityHost: server.URL + "/"}
+			token, err := getWorkloadIdentityToken(server.Client(), identity)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getWorkloadIdentityToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if token != tt.wantToken {
+				t.Errorf("expected token %q, found %q", tt.wantToken, token)
+			}
+		})
+	}
+}
+
+func TestGetManagedIdentityToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.Header.Get("Metadata") != "true" || r.URL.Path != "/metadata/identity/oauth2/token" || query.Get("resource") != "https://storage.azure.com/" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		if query.Get("client_id") != "client-1" {
+			http.Error(w, `{"error":"invalid_request","error_description":"Identity not found"}`, http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"access_token":"access-token","expires_on":"1686830400","resource":"https://storage.azure.com/","token_type":"Bearer"}`))
+	}))
+	defer server.Close()
+
+	token, err := getManagedIdentityToken(server.Client(), server.URL, "client-1")
+	if err != nil {
+		t.Fatalf("getManagedIdentityToken() error = %v", err)
+	}
+	if token != "access-token" {
+		t.Errorf("expected token %q, found %q", "access-token", token)
+	}
+
+	if _, err := getManagedIdentityToken(server.Client(), server.URL, "client-2"); err == nil {
+		t.Errorf("expected error for unknown identity")
+	}
+}
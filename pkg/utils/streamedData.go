@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/Azure/aks-periscope/pkg/interfaces"
+)
+
+var errStreamedContentNotRetained = errors.New("content of streamed data is not retained")
+
+// NewStringDataStreamHandler adapts a StreamingCollector to the map-based Collector interface, by reading the
+// content of each stream into the data map. Streams are expected to be handled one at a time.
+func NewStringDataStreamHandler(data map[string]string) interfaces.DataStreamHandler {
+	return func(key string, reader io.Reader) error {
+		var content strings.Builder
+		if _, err := io.Copy(&content, reader); err != nil {
+			return fmt.Errorf("read %s: %w", key, err)
+		}
+
+		data[key] = content.String()
+		return nil
+	}
+}
+
+// StreamedDataProducer records the keys and lengths of data which a StreamingCollector passed straight to an
+// exporter, so that it can be accounted for (e.g. in the completeness report) once it is no longer available.
+// The values report their length, but their content cannot be read.
+type StreamedDataProducer struct {
+	name    string
+	lock    sync.Mutex
+	lengths map[string]int64
+}
+
+func NewStreamedDataProducer(name string) *StreamedDataProducer {
+	return &StreamedDataProducer{
+		name:    name,
+		lengths: map[string]int64{},
+	}
+}
+
+// CountReader wraps the reader of a streamed key, recording the length of the content as it is read.
+func (p *StreamedDataProducer) CountReader(key string, reader io.Reader) io.Reader {
+	p.record(key, 0)
+	return &countingReader{reader: reader, onRead: func(n int) { p.record(key, int64(n)) }}
+}
+
+func (p *StreamedDataProducer) record(key string, n int64) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.lengths[key] += n
+}
+
+func (p *StreamedDataProducer) GetName() string {
+	return p.name
+}
+
+func (p *StreamedDataProducer) GetData() map[string]interfaces.DataValue {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	result := make(map[string]interfaces.DataValue, len(p.lengths))
+	for key, length := range p.lengths {
+		result[key] = &streamedDataValue{length: length}
+	}
+
+	return result
+}
+
+type streamedDataValue struct {
+	length int64
+}
+
+func (v *streamedDataValue) GetLength() int64 {
+	return v.length
+}
+
+func (v *streamedDataValue) GetReader() (io.ReadCloser, error) {
+	return nil, errStreamedContentNotRetained
+}
+
+type countingReader struct {
+	reader io.Reader
+	onRead func(n int)
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.onRead(n)
+	return n, err
+}
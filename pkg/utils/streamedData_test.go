@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestStringDataStreamHandler(t *testing.T) {
+	data := map[string]string{}
+	handler := NewStringDataStreamHandler(data)
+
+	if err := handler("a", strings.NewReader("first")); err != nil {
+		t.Fatalf("handler error = %v", err)
+	}
+	if err := handler("b", strings.NewReader("")); err != nil {
+		t.Fatalf("handler error = %v", err)
+	}
+	if data["a"] != "first" || len(data) != 2 {
+		t.Errorf("unexpected data %v", data)
+	}
+
+	reader, writer := io.Pipe()
+	writer.CloseWithError(errors.New("broken"))
+	if err := handler("c", reader); err == nil {
+		t.Errorf("expected error for failed stream")
+	}
+}
+
+func TestStreamedDataProducer(t *testing.T) {
+	producer := NewStreamedDataProducer("test")
+	if producer.GetName() != "test" {
+		t.Errorf("unexpected name %s", producer.GetName())
+	}
+
+	if _, err := io.Copy(io.Discard, producer.CountReader("logs", strings.NewReader("0123456789"))); err != nil {
+		t.Fatalf("error reading stream: %v", err)
+	}
+	producer.CountReader("empty", strings.NewReader(""))
+
+	data := producer.GetData()
+	if len(data) != 2 || data["logs"].GetLength() != 10 || data["empty"].GetLength() != 0 {
+		t.Errorf("unexpected data %v", data)
+	}
+
+	if _, err := data["logs"].GetReader(); err == nil {
+		t.Errorf("expected error reading streamed content")
+	}
+}
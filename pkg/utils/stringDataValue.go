@@ -2,6 +2,7 @@ package utils
 
 import (
 	"io"
+	"sort"
 	"strings"
 
 	"github.com/Azure/aks-periscope/pkg/interfaces"
@@ -33,3 +34,15 @@ func ToDataValueMap(data map[string]string) map[string]interfaces.DataValue {
 
 	return result
 }
+
+// SortedKeys returns the keys of the data in sorted order, so that data can be exported deterministically.
+func SortedKeys(data map[string]interfaces.DataValue) []string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+// TCPConnectionProber opens a TCP connection to a host:port address within the timeout, and returns how long it
+// took. It allows collectors to substitute a fake implementation of ProbeTCPConnection for testing.
+type TCPConnectionProber func(address string, timeout time.Duration) (time.Duration, error)
+
+// ProbeTCPConnection opens a TCP connection to the address and closes it immediately, without sending any data.
+func ProbeTCPConnection(address string, timeout time.Duration) (time.Duration, error) {
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return 0, fmt.Errorf("TCP connection to %s: %w", address, err)
+	}
+
+	latency := time.Since(start)
+	conn.Close()
+
+	return latency, nil
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestProbeTCPConnection(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+	defer listener.Close()
+
+	// Reserve a port and release it, so that nothing is listening on it.
+	closedListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+	closedAddress := closedListener.Addr().String()
+	closedListener.Close()
+
+	tests := []struct {
+		name    string
+		address string
+		wantErr bool
+	}{
+		{
+			name:    "listening",
+			address: listener.Addr().String(),
+			wantErr: false,
+		},
+		{
+			name:    "nothing listening",
+			address: closedAddress,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ProbeTCPConnection(tt.address, time.Second)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ProbeTCPConnection() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
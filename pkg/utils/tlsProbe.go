@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+)
+
+// TLSProbeResult records how far a connection to a TLS endpoint got, and how long each stage took.
+type TLSProbeResult struct {
+	TCPConnected     bool
+	TCPLatency       time.Duration
+	TLSHandshake     bool
+	HandshakeLatency time.Duration
+}
+
+// TLSEndpointProber connects to a host:port address and performs a TLS handshake. It allows collectors
+// to substitute a fake implementation of ProbeTLSEndpoint for testing.
+type TLSEndpointProber func(address string) (*TLSProbeResult, error)
+
+const tlsProbeTimeout = 5 * time.Second
+
+// ProbeTLSEndpoint connects to the address and performs a TLS handshake, returning the result of each stage even if
+// a later one fails. As for FetchServingCertificates, the certificate chain is not verified.
+func ProbeTLSEndpoint(address string) (*TLSProbeResult, error) {
+	result := &TLSProbeResult{}
+
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", address, tlsProbeTimeout)
+	if err != nil {
+		return result, fmt.Errorf("TCP connection to %s: %w", address, err)
+	}
+	defer conn.Close()
+
+	result.TCPConnected = true
+	result.TCPLatency = time.Since(start)
+
+	if err := conn.SetDeadline(time.Now().Add(tlsProbeTimeout)); err != nil {
+		return result, fmt.Errorf("set deadline for %s: %w", address, err)
+	}
+
+	start = time.Now()
+	if err := tls.Client(conn, &tls.Config{InsecureSkipVerify: true}).Handshake(); err != nil {
+		return result, fmt.Errorf("TLS handshake with %s: %w", address, err)
+	}
+
+	result.TLSHandshake = true
+	result.HandshakeLatency = time.Since(start)
+
+	return result, nil
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProbeTLSEndpoint(t *testing.T) {
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer tlsServer.Close()
+
+	plainServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer plainServer.Close()
+
+	// Nothing can listen on port 0 (binding it assigns an ephemeral port instead), so connections to it always fail.
+	const closedAddress = "127.0.0.1:0"
+
+	tests := []struct {
+		name             string
+		address          string
+		wantTCPConnected bool
+		wantTLSHandshake bool
+		wantErr          bool
+	}{
+		{
+			name:             "TLS server",
+			address:          strings.TrimPrefix(tlsServer.URL, "https://"),
+			wantTCPConnected: true,
+			wantTLSHandshake: true,
+			wantErr:          false,
+		},
+		{
+			name:             "plain HTTP server",
+			address:          strings.TrimPrefix(plainServer.URL, "http://"),
+			wantTCPConnected: true,
+			wantTLSHandshake: false,
+			wantErr:          true,
+		},
+		{
+			name:             "nothing listening",
+			address:          closedAddress,
+			wantTCPConnected: false,
+			wantTLSHandshake: false,
+			wantErr:          true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ProbeTLSEndpoint(tt.address)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ProbeTLSEndpoint() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if result.TCPConnected != tt.wantTCPConnected {
+				t.Errorf("expected TCPConnected %v, found %v", tt.wantTCPConnected, result.TCPConnected)
+			}
+			if result.TLSHandshake != tt.wantTLSHandshake {
+				t.Errorf("expected TLSHandshake %v, found %v", tt.wantTLSHandshake, result.TLSHandshake)
+			}
+		})
+	}
+}